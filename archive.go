@@ -0,0 +1,168 @@
+package vaultstore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// ErrArchivePasswordMissing is returned by ArchiveExpired and ArchiveRead
+// when NewStoreOptions.ArchivePassword is not set
+var ErrArchivePasswordMissing = errors.New("archive password is not configured")
+
+// archiveMaxLineLength is the longest encrypted batch ArchiveRead accepts
+const archiveMaxLineLength = 1 << 30
+
+// ArchiveExpired exports the expired tokens to an encrypted archive and then
+// removes them from the store, keeping the hot table small while satisfying
+// retention requirements. Soft deleted tokens are not archived.
+//
+// The archive holds one line per batch of up to 1000 records, each encrypted with
+// NewStoreOptions.ArchivePassword. Record values stay encrypted with their own password.
+// A batch is removed right after it was written, so w should be durable storage.
+// Restore an archive with ArchiveRead and ApplyChanges.
+//
+// Parameters:
+// - ctx: The context
+// - w: The writer receiving the archive
+//
+// Returns:
+// - count: The number of archived and removed tokens
+// - err: An error if something went wrong
+func (store *storeImplementation) ArchiveExpired(ctx context.Context, w io.Writer) (count int64, err error) {
+	ctx, span := store.traceStart(ctx, "ArchiveExpired")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "ArchiveExpired", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if store.archivePassword == "" {
+		return 0, ErrArchivePasswordMissing
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	lastID := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		var gormRecords []gormVaultRecord
+		err := store.recordQueryFilter(store.vaultDB(ctx), RecordQuery()).
+			Where(COLUMN_EXPIRES_AT+" < ?", now).
+			Where(COLUMN_ID+" > ?", lastID).
+			Order(COLUMN_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&gormRecords).Error
+		if err != nil {
+			return count, err
+		}
+
+		if len(gormRecords) == 0 {
+			return count, nil
+		}
+		lastID = gormRecords[len(gormRecords)-1].ID
+
+		if err := store.valueChunksResolve(ctx, gormRecords); err != nil {
+			return count, err
+		}
+
+		changes := []Change{}
+		for i := range gormRecords {
+			if !isRecordExpired(store.recordFromGorm(&gormRecords[i])) {
+				continue
+			}
+			changes = append(changes, changeFromGorm(&gormRecords[i]))
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		batchJSON, err := json.Marshal(changes)
+		if err != nil {
+			return count, err
+		}
+
+		encrypted, err := encode(ctx, string(batchJSON), store.archivePassword, store.cryptoConfig.withoutEnvelope())
+		if err != nil {
+			return count, fmt.Errorf("failed to encrypt archive batch: %w", err)
+		}
+
+		if _, err := io.WriteString(w, encrypted+"\n"); err != nil {
+			return count, fmt.Errorf("failed to write archive batch: %w", err)
+		}
+
+		for _, change := range changes {
+			if err := store.RecordDeleteByID(ctx, change.ID); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+}
+
+// ArchiveRead decrypts an archive written by ArchiveExpired and hands each
+// batch of records to fn, e.g. to restore them with ApplyChanges
+//
+// Parameters:
+// - ctx: The context
+// - r: The reader of the archive
+// - fn: The callback receiving each batch
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) ArchiveRead(ctx context.Context, r io.Reader, fn func(batch ChangeBatch) error) error {
+	ctx, span := store.traceStart(ctx, "ArchiveRead")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "ArchiveRead", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if store.archivePassword == "" {
+		return ErrArchivePasswordMissing
+	}
+
+	if fn == nil {
+		return errors.New("callback is nil")
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), archiveMaxLineLength)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		decrypted, err := decode(ctx, scanner.Text(), store.archivePassword, store.cryptoConfig)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt archive batch: %w", err)
+		}
+
+		var changes []Change
+		if err := json.Unmarshal([]byte(decrypted), &changes); err != nil {
+			return err
+		}
+
+		if err := fn(ChangeBatch{Changes: changes}); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,101 @@
+package vaultstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Store_ArchiveExpired(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:       "vault_archive",
+		VaultMetaTableName:   "vault_meta",
+		DB:                   db,
+		AutomigrateEnabled:   true,
+		ExpiresAtPastAllowed: true,
+		ArchivePassword:      "archive_password_that_is_long_enough_32chars",
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	expiredToken, err := store.TokenCreate(ctx, "expired secret", password, 20, TokenCreateOptions{
+		ExpiresAt: time.Now().UTC().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	activeToken, err := store.TokenCreate(ctx, "active secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var archive bytes.Buffer
+	count, err := store.ArchiveExpired(ctx, &archive)
+	if err != nil {
+		t.Fatalf("ArchiveExpired: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected [1] archived token received [%v]", count)
+	}
+
+	// The archive is encrypted
+	if strings.Contains(archive.String(), expiredToken) {
+		t.Fatal("Expected the archive not to contain the token in plain text")
+	}
+
+	exists, err := store.TokenExists(ctx, expiredToken)
+	if err != nil || exists {
+		t.Fatalf("Expected the expired token to be removed received [%v] [%v]", exists, err)
+	}
+
+	exists, err = store.TokenExists(ctx, activeToken)
+	if err != nil || !exists {
+		t.Fatalf("Expected the active token to be kept received [%v] [%v]", exists, err)
+	}
+
+	// Restore the archive
+	restored := 0
+	err = store.ArchiveRead(ctx, &archive, func(batch ChangeBatch) error {
+		restored += len(batch.Changes)
+		_, err := store.ApplyChanges(ctx, batch)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ArchiveRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if restored != 1 {
+		t.Fatalf("Expected [1] restored token received [%v]", restored)
+	}
+
+	exists, err = store.TokenExists(ctx, expiredToken)
+	if err != nil || !exists {
+		t.Fatalf("Expected the expired token to be restored received [%v] [%v]", exists, err)
+	}
+}
+
+func Test_Store_ArchiveExpired_PasswordMissing(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = store.ArchiveExpired(context.Background(), &bytes.Buffer{})
+	if !errors.Is(err, ErrArchivePasswordMissing) {
+		t.Fatalf("Expected [ErrArchivePasswordMissing] received [%v]", err)
+	}
+}
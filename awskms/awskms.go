@@ -0,0 +1,92 @@
+package awskms
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/dracory/vaultstore"
+)
+
+// ErrKeyIDRequired is returned by WrapKey and UnwrapKey when the provider has no KMS key ID
+var ErrKeyIDRequired = errors.New("awskms: key id is required")
+
+// Client is the part of the KMS client used by the key provider, satisfied by *kms.Client
+type Client interface {
+	Encrypt(ctx context.Context, params *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KeyProvider wraps and unwraps data keys with a KMS key
+type KeyProvider struct {
+	client            Client
+	keyID             string
+	encryptionContext map[string]string
+}
+
+var _ vaultstore.KeyProvider = (*KeyProvider)(nil)
+
+// New creates a key provider using the KMS key
+//
+// Parameters:
+// - client: The KMS client, e.g. kms.NewFromConfig(cfg)
+// - keyID: The key ID, ARN, alias name or alias ARN of the KMS key
+//
+// Returns:
+// - *KeyProvider: The key provider
+func New(client Client, keyID string) *KeyProvider {
+	return &KeyProvider{
+		client: client,
+		keyID:  keyID,
+	}
+}
+
+// WithEncryptionContext returns a copy of the provider binding the data keys to the
+// encryption context, e.g. {"service": "billing"}. Unwrapping requires the same context,
+// and KMS records it in CloudTrail.
+func (provider *KeyProvider) WithEncryptionContext(encryptionContext map[string]string) *KeyProvider {
+	providerCopy := *provider
+	providerCopy.encryptionContext = map[string]string{}
+	for key, value := range encryptionContext {
+		providerCopy.encryptionContext[key] = value
+	}
+	return &providerCopy
+}
+
+// WrapKey encrypts the data key with the KMS key
+func (provider *KeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	if provider.keyID == "" {
+		return nil, ErrKeyIDRequired
+	}
+
+	output, err := provider.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:             aws.String(provider.keyID),
+		Plaintext:         dataKey,
+		EncryptionContext: provider.encryptionContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.CiphertextBlob, nil
+}
+
+// UnwrapKey decrypts a data key wrapped by WrapKey. The key ID is passed to KMS,
+// so data keys wrapped by another KMS key are refused, as EnvelopeRewrap expects.
+func (provider *KeyProvider) UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	if provider.keyID == "" {
+		return nil, ErrKeyIDRequired
+	}
+
+	output, err := provider.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(provider.keyID),
+		CiphertextBlob:    wrappedKey,
+		EncryptionContext: provider.encryptionContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Plaintext, nil
+}
@@ -0,0 +1,65 @@
+package awskms
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// fakeClient "encrypts" by prefixing the key ID, enough to check what is sent to KMS
+type fakeClient struct {
+	encryptionContext map[string]string
+}
+
+func (client *fakeClient) Encrypt(ctx context.Context, params *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	client.encryptionContext = params.EncryptionContext
+	return &kms.EncryptOutput{
+		CiphertextBlob: append([]byte(aws.ToString(params.KeyId)+":"), params.Plaintext...),
+	}, nil
+}
+
+func (client *fakeClient) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	prefix := []byte(aws.ToString(params.KeyId) + ":")
+	if !bytes.HasPrefix(params.CiphertextBlob, prefix) {
+		return nil, errors.New("IncorrectKeyException")
+	}
+	return &kms.DecryptOutput{Plaintext: bytes.TrimPrefix(params.CiphertextBlob, prefix)}, nil
+}
+
+func Test_KeyProvider(t *testing.T) {
+	client := &fakeClient{}
+	provider := New(client, "alias/vaultstore").WithEncryptionContext(map[string]string{"service": "billing"})
+
+	ctx := context.Background()
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+
+	wrappedKey, err := provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		t.Fatalf("WrapKey: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if client.encryptionContext["service"] != "billing" {
+		t.Fatalf("Expected the encryption context to be sent received [%v]", client.encryptionContext)
+	}
+
+	unwrappedKey, err := provider.UnwrapKey(ctx, wrappedKey)
+	if err != nil {
+		t.Fatalf("UnwrapKey: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !bytes.Equal(unwrappedKey, dataKey) {
+		t.Fatal("Expected the unwrapped key to be the data key")
+	}
+
+	if _, err := New(client, "alias/other").UnwrapKey(ctx, wrappedKey); err == nil {
+		t.Fatal("Expected a data key wrapped by another KMS key to be refused")
+	}
+
+	if _, err := New(client, "").WrapKey(ctx, dataKey); !errors.Is(err, ErrKeyIDRequired) {
+		t.Fatalf("Expected ErrKeyIDRequired received [%v]", err)
+	}
+}
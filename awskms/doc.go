@@ -0,0 +1,18 @@
+// Package awskms provides a vaultstore.KeyProvider wrapping the envelope encryption
+// data keys with AWS KMS, so the master key never leaves KMS.
+//
+// The package is a module of its own, so the AWS SDK is not a dependency of the
+// vault store:
+//
+//	go get github.com/dracory/vaultstore/awskms
+//
+// Usage:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	provider := awskms.New(kms.NewFromConfig(cfg), "alias/vaultstore")
+//
+//	store, err := vaultstore.NewStore(vaultstore.NewStoreOptions{
+//		...
+//		KeyProvider: provider,
+//	})
+package awskms
@@ -0,0 +1,11 @@
+module github.com/dracory/vaultstore/awskms
+
+go 1.26
+
+require (
+	github.com/dracory/vaultstore v0.0.0-00010101000000-000000000000
+	github.com/aws/aws-sdk-go-v2 v1.43.7
+	github.com/aws/aws-sdk-go-v2/service/kms v1.55.5
+)
+
+replace github.com/dracory/vaultstore => ../
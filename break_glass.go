@@ -0,0 +1,323 @@
+package vaultstore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"gorm.io/gorm"
+)
+
+// Vault settings of the break-glass access
+const (
+	// VAULT_SETTING_KEY_BREAK_GLASS holds the threshold and the hash of the shared secret
+	VAULT_SETTING_KEY_BREAK_GLASS = "break_glass"
+	// VAULT_SETTING_KEY_BREAK_GLASS_GRANT holds the active grant
+	VAULT_SETTING_KEY_BREAK_GLASS_GRANT = "break_glass_grant"
+)
+
+// BREAK_GLASS_MAX_DURATION is the longest a break-glass grant can last
+const BREAK_GLASS_MAX_DURATION = 24 * time.Hour
+
+// breakGlassSecretLength is the length in bytes of the secret split into admin shares
+const breakGlassSecretLength = 32
+
+var (
+	// ErrBreakGlassRequired is returned when reading a high-security token without an active break-glass grant
+	ErrBreakGlassRequired = errors.New("token requires an active break-glass grant")
+	// ErrBreakGlassNotConfigured is returned by BreakGlassGrant before BreakGlassSetup
+	ErrBreakGlassNotConfigured = errors.New("break-glass access is not configured")
+	// ErrBreakGlassSharesInvalid is returned when the admin shares do not recover the secret
+	ErrBreakGlassSharesInvalid = errors.New("break-glass admin shares are invalid")
+)
+
+// breakGlassConfig is persisted in the vault settings. The secret itself is never stored.
+type breakGlassConfig struct {
+	Threshold  int    `json:"threshold"`
+	SecretHash string `json:"secret_hash"`
+}
+
+// breakGlassGrant is the active grant persisted in the vault settings
+type breakGlassGrant struct {
+	GrantedAt string `json:"granted_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// BreakGlassSetup creates a new break-glass secret and splits it into admin shares,
+// any threshold of which can later be combined with BreakGlassGrant. Only the hash of the
+// secret is stored, hand each share to a different admin. Shares of a previous setup stop working.
+//
+// Parameters:
+// - ctx: The context
+// - shares: The number of admin shares to create (n, max 255)
+// - threshold: The number of shares required for a grant (k, at least 2)
+//
+// Returns:
+// - adminShares: The hex encoded admin shares
+// - err: An error if something went wrong
+func (store *storeImplementation) BreakGlassSetup(ctx context.Context, shares int, threshold int) ([]string, error) {
+	ctx, span := store.traceStart(ctx, "BreakGlassSetup")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "BreakGlassSetup", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	secret := make([]byte, breakGlassSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	split, err := shamirSplit(secret, shares, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(secret)
+	config, err := json.Marshal(breakGlassConfig{
+		Threshold:  threshold,
+		SecretHash: hex.EncodeToString(hash[:]),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.SetVaultSetting(ctx, VAULT_SETTING_KEY_BREAK_GLASS, string(config)); err != nil {
+		return nil, err
+	}
+
+	adminShares := make([]string, len(split))
+	for i, share := range split {
+		adminShares[i] = hex.EncodeToString(share)
+	}
+
+	return adminShares, nil
+}
+
+// BreakGlassGrant temporarily enables reading the high-security tokens (see TokenBreakGlassRequire)
+// given at least threshold admin shares. The grant expires automatically after the duration.
+// The grant and every read under it are emitted as events, to be written to the audit trail.
+//
+// Parameters:
+// - ctx: The context
+// - adminShares: The admin shares returned by BreakGlassSetup
+// - duration: How long the grant lasts (max BREAK_GLASS_MAX_DURATION)
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) BreakGlassGrant(ctx context.Context, adminShares []string, duration time.Duration) error {
+	ctx, span := store.traceStart(ctx, "BreakGlassGrant")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "BreakGlassGrant", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if duration <= 0 || duration > BREAK_GLASS_MAX_DURATION {
+		return errors.New("break-glass duration must be between 0 and " + BREAK_GLASS_MAX_DURATION.String())
+	}
+
+	configJSON, err := store.GetVaultSetting(ctx, VAULT_SETTING_KEY_BREAK_GLASS)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrBreakGlassNotConfigured
+	}
+	if err != nil {
+		return err
+	}
+
+	var config breakGlassConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return err
+	}
+
+	if len(adminShares) < config.Threshold {
+		store.emitEvent(ctx, EVENT_TYPE_BREAK_GLASS_DENIED, "", map[string]string{"shares": strconv.Itoa(len(adminShares))})
+		return ErrBreakGlassSharesInvalid
+	}
+
+	shares := make([][]byte, len(adminShares))
+	for i, adminShare := range adminShares {
+		shares[i], err = hex.DecodeString(adminShare)
+		if err != nil {
+			return ErrBreakGlassSharesInvalid
+		}
+	}
+
+	secret, err := shamirCombine(shares)
+	if err != nil {
+		return ErrBreakGlassSharesInvalid
+	}
+
+	hash := sha256.Sum256(secret)
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(hash[:])), []byte(config.SecretHash)) != 1 {
+		store.emitEvent(ctx, EVENT_TYPE_BREAK_GLASS_DENIED, "", map[string]string{"shares": strconv.Itoa(len(adminShares))})
+		return ErrBreakGlassSharesInvalid
+	}
+
+	now := carbon.Now(carbon.UTC)
+	grant := breakGlassGrant{
+		GrantedAt: now.ToDateTimeString(carbon.UTC),
+		ExpiresAt: now.AddSeconds(int(duration.Seconds())).ToDateTimeString(carbon.UTC),
+	}
+
+	grantJSON, err := json.Marshal(grant)
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetVaultSetting(ctx, VAULT_SETTING_KEY_BREAK_GLASS_GRANT, string(grantJSON)); err != nil {
+		return err
+	}
+
+	store.emitEvent(ctx, EVENT_TYPE_BREAK_GLASS_GRANTED, "", map[string]string{
+		"shares":     strconv.Itoa(len(adminShares)),
+		"expires_at": grant.ExpiresAt,
+	})
+
+	return nil
+}
+
+// BreakGlassRevoke ends the active break-glass grant before it expires
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) BreakGlassRevoke(ctx context.Context) error {
+	ctx, span := store.traceStart(ctx, "BreakGlassRevoke")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "BreakGlassRevoke", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	err := store.metaDelete(ctx, OBJECT_TYPE_VAULT_SETTINGS, VAULT_SETTINGS_ID, VAULT_SETTING_KEY_BREAK_GLASS_GRANT)
+	if err != nil {
+		return err
+	}
+
+	store.emitEvent(ctx, EVENT_TYPE_BREAK_GLASS_REVOKED, "", nil)
+	return nil
+}
+
+// TokenBreakGlassRequire designates a token as high-security, so reading it
+// requires an active break-glass grant, or removes the designation. Removing it
+// requires an active grant as well, returning ErrBreakGlassRequired otherwise.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token
+// - required: Whether reads require a break-glass grant
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenBreakGlassRequire(ctx context.Context, token string, required bool) error {
+	ctx, span := store.traceStart(ctx, "TokenBreakGlassRequire")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenBreakGlassRequire", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if token == "" {
+		return errors.New("token is empty")
+	}
+
+	entry, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if entry == nil {
+		return ErrTokenNotFound
+	}
+
+	if !required {
+		if err := store.tokenBreakGlassCheck(ctx, entry); err != nil {
+			return err
+		}
+
+		return store.metaDelete(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(entry.GetID()), META_KEY_BREAK_GLASS)
+	}
+
+	return store.metaSet(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(entry.GetID()), META_KEY_BREAK_GLASS, "1")
+}
+
+// breakGlassActiveGrant returns the unexpired grant, or nil if there is none
+func (store *storeImplementation) breakGlassActiveGrant(ctx context.Context) (*breakGlassGrant, error) {
+	grantJSON, err := store.GetVaultSetting(ctx, VAULT_SETTING_KEY_BREAK_GLASS_GRANT)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var grant breakGlassGrant
+	if err := json.Unmarshal([]byte(grantJSON), &grant); err != nil {
+		return nil, err
+	}
+
+	if !carbon.Parse(grant.ExpiresAt, carbon.UTC).Gt(carbon.Now(carbon.UTC)) {
+		return nil, nil
+	}
+
+	return &grant, nil
+}
+
+// tokenBreakGlassCheck returns ErrBreakGlassRequired if the record is high-security and
+// no grant is active. Reads under a grant are emitted as break-glass access events.
+func (store *storeImplementation) tokenBreakGlassCheck(ctx context.Context, record RecordInterface) error {
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_BREAK_GLASS)
+	if err != nil {
+		return err
+	}
+
+	return store.tokenBreakGlassMetaCheck(ctx, record, meta)
+}
+
+// tokenBreakGlassMetaCheck returns ErrBreakGlassRequired if the break-glass meta row
+// of the record is set and no grant is active
+func (store *storeImplementation) tokenBreakGlassMetaCheck(ctx context.Context, record RecordInterface, meta *gormVaultMeta) error {
+	if meta == nil {
+		return nil
+	}
+
+	return store.breakGlassAccess(ctx, []RecordInterface{record})
+}
+
+// breakGlassAccess checks the grant for reading the high-security records and emits an access event for each
+func (store *storeImplementation) breakGlassAccess(ctx context.Context, records []RecordInterface) error {
+	grant, err := store.breakGlassActiveGrant(ctx)
+	if err != nil {
+		return err
+	}
+
+	if grant == nil {
+		for _, record := range records {
+			store.emitEvent(ctx, EVENT_TYPE_BREAK_GLASS_DENIED, record.GetToken(), nil)
+		}
+		return ErrBreakGlassRequired
+	}
+
+	for _, record := range records {
+		store.emitEvent(ctx, EVENT_TYPE_BREAK_GLASS_ACCESS, record.GetToken(), map[string]string{
+			"granted_at": grant.GrantedAt,
+			"expires_at": grant.ExpiresAt,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Store_BreakGlassGrant(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var mu sync.Mutex
+	events := []Event{}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_break_glass",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		EventHooks: []EventHook{func(_ context.Context, event Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "root credentials", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenBreakGlassRequire(ctx, token, true)
+	if err != nil {
+		t.Fatalf("TokenBreakGlassRequire: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Not configured yet
+	err = store.BreakGlassGrant(ctx, []string{"01aa", "02bb"}, time.Hour)
+	if !errors.Is(err, ErrBreakGlassNotConfigured) {
+		t.Fatalf("Expected [ErrBreakGlassNotConfigured] received [%v]", err)
+	}
+
+	shares, err := store.BreakGlassSetup(ctx, 5, 3)
+	if err != nil {
+		t.Fatalf("BreakGlassSetup: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = store.TokenRead(ctx, token, password)
+	if !errors.Is(err, ErrBreakGlassRequired) {
+		t.Fatalf("Expected [ErrBreakGlassRequired] received [%v]", err)
+	}
+
+	_, err = store.TokensRead(ctx, []string{token}, password)
+	if !errors.Is(err, ErrBreakGlassRequired) {
+		t.Fatalf("TokensRead: Expected [ErrBreakGlassRequired] received [%v]", err)
+	}
+
+	// Below the threshold
+	err = store.BreakGlassGrant(ctx, shares[:2], time.Hour)
+	if !errors.Is(err, ErrBreakGlassSharesInvalid) {
+		t.Fatalf("Expected [ErrBreakGlassSharesInvalid] received [%v]", err)
+	}
+
+	err = store.BreakGlassGrant(ctx, []string{shares[4], shares[1], shares[2]}, time.Hour)
+	if err != nil {
+		t.Fatalf("BreakGlassGrant: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "root credentials" {
+		t.Fatalf("Expected the value received [%v]", value)
+	}
+
+	err = store.BreakGlassRevoke(ctx)
+	if err != nil {
+		t.Fatalf("BreakGlassRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = store.TokenRead(ctx, token, password)
+	if !errors.Is(err, ErrBreakGlassRequired) {
+		t.Fatalf("Expected [ErrBreakGlassRequired] after revoke received [%v]", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	counts := map[EventType]int{}
+	for _, event := range events {
+		counts[event.Type]++
+	}
+
+	if counts[EVENT_TYPE_BREAK_GLASS_GRANTED] != 1 || counts[EVENT_TYPE_BREAK_GLASS_ACCESS] != 1 || counts[EVENT_TYPE_BREAK_GLASS_REVOKED] != 1 {
+		t.Fatalf("Expected one grant, access and revoke event received [%v]", counts)
+	}
+
+	if counts[EVENT_TYPE_BREAK_GLASS_DENIED] != 4 {
+		t.Fatalf("Expected four denied events received [%v]", counts)
+	}
+}
+
+func Test_Store_BreakGlassGrant_Expires(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenBreakGlassRequire(ctx, token, true); err != nil {
+		t.Fatalf("TokenBreakGlassRequire: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	shares, err := store.BreakGlassSetup(ctx, 2, 2)
+	if err != nil {
+		t.Fatalf("BreakGlassSetup: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.BreakGlassGrant(ctx, shares, time.Second); err != nil {
+		t.Fatalf("BreakGlassGrant: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	time.Sleep(2 * time.Second)
+
+	_, err = store.TokenRead(ctx, token, password)
+	if !errors.Is(err, ErrBreakGlassRequired) {
+		t.Fatalf("Expected [ErrBreakGlassRequired] after expiry received [%v]", err)
+	}
+
+	// Removing the designation requires a grant too
+	err = store.TokenBreakGlassRequire(ctx, token, false)
+	if !errors.Is(err, ErrBreakGlassRequired) {
+		t.Fatalf("Expected [ErrBreakGlassRequired] removing the designation received [%v]", err)
+	}
+
+	if err := store.BreakGlassGrant(ctx, shares, time.Hour); err != nil {
+		t.Fatalf("BreakGlassGrant: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenBreakGlassRequire(ctx, token, false); err != nil {
+		t.Fatalf("TokenBreakGlassRequire: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.BreakGlassRevoke(ctx); err != nil {
+		t.Fatalf("BreakGlassRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Removing the designation makes the token readable again
+
+	if _, err := store.TokenRead(ctx, token, password); err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+}
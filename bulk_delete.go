@@ -0,0 +1,106 @@
+package vaultstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"gorm.io/gorm"
+)
+
+// DELETE_BATCH_SIZE_DEFAULT is the default number of records removed per statement by bulk deletes
+const DELETE_BATCH_SIZE_DEFAULT = 5000
+
+// deleteBatchSizeContextKey overrides the delete batch size of the store for a context
+type deleteBatchSizeContextKey struct{}
+
+// contextWithDeleteBatchSize returns the context whose bulk deletes use the batch size
+func contextWithDeleteBatchSize(ctx context.Context, batchSize int) context.Context {
+	return contextWithValue(ctx, deleteBatchSizeContextKey{}, batchSize)
+}
+
+// recordsDeleteBatched permanently deletes the records selected by filter, together with
+// their chunks and metadata, in batches of deleteBatchSize separated by deleteBatchPause.
+// Bounded batches keep the locks short and let replicas catch up between statements.
+func (store *storeImplementation) recordsDeleteBatched(ctx context.Context, filter func(db *gorm.DB) *gorm.DB) (count int64, err error) {
+	return store.recordsBatched(ctx, filter, func(recordIDs []string) (int64, error) {
+		result := store.vaultDB(ctx).
+			Where(COLUMN_ID+" IN ?", recordIDs).
+			Delete(&gormVaultRecord{})
+		if result.Error != nil {
+			return 0, result.Error
+		}
+
+		if err := store.valueChunksDelete(ctx, recordIDs); err != nil {
+			return result.RowsAffected, err
+		}
+
+		return result.RowsAffected, store.recordMetaDelete(ctx, recordIDs)
+	})
+}
+
+// recordsSoftDeleteBatched soft deletes the records selected by filter, in batches like recordsDeleteBatched
+func (store *storeImplementation) recordsSoftDeleteBatched(ctx context.Context, filter func(db *gorm.DB) *gorm.DB) (count int64, err error) {
+	return store.recordsBatched(ctx, filter, func(recordIDs []string) (int64, error) {
+		now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+		result := store.vaultDB(ctx).
+			Where(COLUMN_ID+" IN ?", recordIDs).
+			Updates(map[string]interface{}{
+				COLUMN_SOFT_DELETED_AT: now,
+				COLUMN_UPDATED_AT:      store.timestampValue(now),
+			})
+
+		return result.RowsAffected, result.Error
+	})
+}
+
+// recordsBatched selects the IDs of the records matching filter a batch at a time and
+// passes them to apply, until no record matches. apply must stop the records from
+// matching the filter, or the loop would not end.
+func (store *storeImplementation) recordsBatched(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, apply func(recordIDs []string) (int64, error)) (count int64, err error) {
+	batchSize := store.deleteBatchSize
+	if size, ok := ctx.Value(deleteBatchSizeContextKey{}).(int); ok && size > 0 {
+		batchSize = size
+	}
+	if batchSize <= 0 {
+		batchSize = DELETE_BATCH_SIZE_DEFAULT
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		var recordIDs []string
+		err := filter(store.vaultDB(ctx)).
+			Order(COLUMN_ID+" ASC").
+			Limit(batchSize).
+			Pluck(COLUMN_ID, &recordIDs).Error
+		if err != nil {
+			return count, err
+		}
+
+		if len(recordIDs) == 0 {
+			return count, nil
+		}
+
+		affected, err := apply(recordIDs)
+		count += affected
+		if err != nil {
+			return count, err
+		}
+
+		if len(recordIDs) < batchSize {
+			return count, nil
+		}
+
+		if store.deleteBatchPause > 0 {
+			select {
+			case <-ctx.Done():
+				return count, ctx.Err()
+			case <-time.After(store.deleteBatchPause):
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_Store_TokensExpiredDelete_Batched(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:       "vault_bulk_delete",
+		VaultMetaTableName:   "vault_meta",
+		DB:                   db,
+		AutomigrateEnabled:   true,
+		ExpiresAtPastAllowed: true,
+		DeleteBatchSize:      2,
+		DeleteBatchPause:     time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	for range 5 {
+		_, err := store.TokenCreate(ctx, "expired", password, 20, TokenCreateOptions{
+			ExpiresAt: time.Now().UTC().Add(-time.Second),
+		})
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	validToken, err := store.TokenCreate(ctx, "valid", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	count, err := store.TokensExpiredSoftDelete(ctx)
+	if err != nil {
+		t.Fatalf("TokensExpiredSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 5 {
+		t.Fatalf("Expected 5 soft deleted tokens received [%v]", count)
+	}
+
+	for range 3 {
+		_, err := store.TokenCreate(ctx, "expired", password, 20, TokenCreateOptions{
+			ExpiresAt: time.Now().UTC().Add(-time.Second),
+		})
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	count, err = store.TokensExpiredDelete(ctx)
+	if err != nil {
+		t.Fatalf("TokensExpiredDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected 3 deleted tokens received [%v]", count)
+	}
+
+	exists, err := store.TokenExists(ctx, validToken)
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !exists {
+		t.Fatal("Expected the valid token to be kept")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := store.TokensExpiredDelete(cancelled); err == nil {
+		t.Fatal("Expected a cancelled context to stop the delete")
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dracory/vaultstore"
+
+	_ "github.com/glebarez/sqlite"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Environment variables of the command
+const (
+	ENV_DRIVER       = "VAULTSTORE_DRIVER"
+	ENV_DSN          = "VAULTSTORE_DSN"
+	ENV_PASSWORD     = "VAULTSTORE_PASSWORD"
+	ENV_NEW_PASSWORD = "VAULTSTORE_NEW_PASSWORD"
+	// ENV_EXPORT_PASSWORD encrypts the dumps of export and import
+	ENV_EXPORT_PASSWORD = "VAULTSTORE_EXPORT_PASSWORD"
+)
+
+// TOKEN_LENGTH_DEFAULT is the length of the created tokens unless set with -length
+const TOKEN_LENGTH_DEFAULT = 32
+
+// errUsage is returned for invalid arguments, after the usage was printed
+var errUsage = errors.New("invalid arguments, see -h")
+
+// config holds the global flags
+type config struct {
+	driver      string
+	dsn         string
+	vaultTable  string
+	metaTable   string
+	pepperFile  string
+	automigrate bool
+}
+
+// command runs a command with its arguments
+type command func(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error
+
+// commands are the commands by name
+var commands = map[string]command{
+	"create":          commandCreate,
+	"read":            commandRead,
+	"update":          commandUpdate,
+	"delete":          commandDelete,
+	"rekey":           commandRekey,
+	"expired-cleanup": commandExpiredCleanup,
+	"export":          commandExport,
+	"import":          commandImport,
+	"version":         commandVersion,
+}
+
+// run parses the global flags, opens the store and runs the command
+func run(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	cfg := config{}
+
+	flags := flag.NewFlagSet("vaultstore", flag.ContinueOnError)
+	flags.StringVar(&cfg.driver, "driver", getenv(ENV_DRIVER), "The database driver: sqlite, postgres or mysql (env "+ENV_DRIVER+")")
+	flags.StringVar(&cfg.dsn, "dsn", getenv(ENV_DSN), "The connection string of the database (env "+ENV_DSN+")")
+	flags.StringVar(&cfg.vaultTable, "vault-table", "vault", "The name of the vault table")
+	flags.StringVar(&cfg.metaTable, "meta-table", "vault_meta", "The name of the vault meta table")
+	flags.StringVar(&cfg.pepperFile, "pepper-file", "", "The key file of the store pepper, if the store uses one")
+	flags.BoolVar(&cfg.automigrate, "automigrate", false, "Create or migrate the vault tables before running the command")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: vaultstore [flags] <command> [arguments]")
+		fmt.Fprintln(flags.Output(), "Commands: create, read, update, delete, rekey, expired-cleanup, export, import, version")
+		fmt.Fprintln(flags.Output(), "Flags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	store, db, err := storeOpen(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return cmd(ctx, store, flags.Args()[1:], getenv, stdin, stdout)
+}
+
+// storeOpen opens the database and the store
+func storeOpen(cfg config) (vaultstore.StoreInterface, *sql.DB, error) {
+	var db *sql.DB
+	var err error
+
+	switch cfg.driver {
+	case "", "sqlite":
+		cfg.driver = "sqlite"
+		if cfg.dsn == "" {
+			return nil, nil, errors.New("the dsn of the database is required")
+		}
+		db, err = sql.Open("sqlite", cfg.dsn)
+	case "postgres":
+		db, err = sql.Open("pgx", cfg.dsn)
+	case "mysql":
+		db, err = sql.Open("mysql", cfg.dsn)
+	default:
+		return nil, nil, fmt.Errorf("unsupported driver %q, use sqlite, postgres or mysql", cfg.driver)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := vaultstore.NewStore(vaultstore.NewStoreOptions{
+		VaultTableName:     cfg.vaultTable,
+		VaultMetaTableName: cfg.metaTable,
+		DB:                 db,
+		DbDriverName:       cfg.driver,
+		AutomigrateEnabled: cfg.automigrate,
+		PepperFilePath:     cfg.pepperFile,
+	})
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return store, db, nil
+}
+
+// commandCreate creates a token holding the value read from stdin and prints the token
+func commandCreate(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	length := flags.Int("length", TOKEN_LENGTH_DEFAULT, "The length of the token")
+	expiresIn := flags.Duration("expires-in", 0, "The time after which the token expires, never if 0")
+	contentType := flags.String("content-type", "", "The content type of the value")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	password, err := passwordFromEnv(getenv, ENV_PASSWORD)
+	if err != nil {
+		return err
+	}
+
+	value, err := valueRead(stdin)
+	if err != nil {
+		return err
+	}
+
+	options := vaultstore.TokenCreateOptions{ContentType: *contentType}
+	if *expiresIn > 0 {
+		options.ExpiresAt = time.Now().Add(*expiresIn)
+	}
+
+	token, err := store.TokenCreate(ctx, value, password, *length, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(stdout, token)
+	return err
+}
+
+// commandRead prints the value of the token
+func commandRead(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	token, err := tokenArg(args)
+	if err != nil {
+		return err
+	}
+
+	password, err := passwordFromEnv(getenv, ENV_PASSWORD)
+	if err != nil {
+		return err
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(stdout, value)
+	return err
+}
+
+// commandUpdate replaces the value of the token with the value read from stdin
+func commandUpdate(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	token, err := tokenArg(args)
+	if err != nil {
+		return err
+	}
+
+	password, err := passwordFromEnv(getenv, ENV_PASSWORD)
+	if err != nil {
+		return err
+	}
+
+	value, err := valueRead(stdin)
+	if err != nil {
+		return err
+	}
+
+	return store.TokenUpdate(ctx, token, value, password)
+}
+
+// commandDelete deletes the token
+func commandDelete(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	token, err := tokenArg(args)
+	if err != nil {
+		return err
+	}
+
+	return store.TokenDelete(ctx, token)
+}
+
+// commandRekey encrypts the tokens of the password with the new password
+func commandRekey(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	password, err := passwordFromEnv(getenv, ENV_PASSWORD)
+	if err != nil {
+		return err
+	}
+
+	newPassword, err := passwordFromEnv(getenv, ENV_NEW_PASSWORD)
+	if err != nil {
+		return err
+	}
+
+	count, err := store.TokensChangePassword(ctx, password, newPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "rekeyed %d tokens\n", count)
+	return err
+}
+
+// commandExpiredCleanup deletes the expired tokens, or soft deletes them with -soft
+func commandExpiredCleanup(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("expired-cleanup", flag.ContinueOnError)
+	soft := flags.Bool("soft", false, "Soft delete the expired tokens instead of deleting them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var count int64
+	var err error
+	if *soft {
+		count, err = store.TokensExpiredSoftDelete(ctx)
+	} else {
+		count, err = store.TokensExpiredDelete(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "deleted %d expired tokens\n", count)
+	return err
+}
+
+// commandExport writes an encrypted dump of the records and meta to stdout.
+// The values of the tokens stay encrypted with their own passwords.
+func commandExport(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	password, err := passwordFromEnv(getenv, ENV_EXPORT_PASSWORD)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.Export(ctx, stdout, vaultstore.ExportOptions{Password: password})
+	return err
+}
+
+// commandImport restores the dump written by export, read from stdin
+func commandImport(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	password, err := passwordFromEnv(getenv, ENV_EXPORT_PASSWORD)
+	if err != nil {
+		return err
+	}
+
+	result, err := store.Import(ctx, stdin, vaultstore.ImportOptions{Password: password})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "imported %d records, %d unchanged, %d conflicts\n", result.Records, result.Unchanged, len(result.Conflicts))
+	return err
+}
+
+// commandVersion prints the vault version and the version supported by the command
+func commandVersion(ctx context.Context, store vaultstore.StoreInterface, args []string, getenv func(string) string, stdin io.Reader, stdout io.Writer) error {
+	version, err := store.GetVaultVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version == "" {
+		version = "not set"
+	}
+
+	_, err = fmt.Fprintf(stdout, "vault version: %s\nsupported version: %s\n", version, vaultstore.VAULT_VERSION_CURRENT)
+	return err
+}
+
+// tokenArg returns the token, the only argument of the command
+func tokenArg(args []string) (string, error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", errors.New("the token is required")
+	}
+
+	return args[0], nil
+}
+
+// passwordFromEnv returns the password of the environment variable
+func passwordFromEnv(getenv func(string) string, name string) (string, error) {
+	password := getenv(name)
+	if password == "" {
+		return "", fmt.Errorf("the %s environment variable is required", name)
+	}
+
+	return password, nil
+}
+
+// valueRead reads the value from stdin, without the trailing newline of a terminal or echo
+func valueRead(stdin io.Reader) (string, error) {
+	value, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(string(value), "\n"), "\r"), nil
+}
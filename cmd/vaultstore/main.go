@@ -0,0 +1,51 @@
+// Command vaultstore runs the routine operations on a vault store, against any
+// supported database.
+//
+// Usage:
+//
+//	vaultstore [flags] <command> [arguments]
+//
+// Commands:
+//
+//	create [-length 32] [-expires-in 24h] [-content-type t]
+//	                    creates a token holding the value read from stdin, prints the token
+//	read <token>        prints the value of the token
+//	update <token>      replaces the value of the token with the value read from stdin
+//	delete <token>      deletes the token
+//	rekey               encrypts the tokens of VAULTSTORE_PASSWORD with VAULTSTORE_NEW_PASSWORD
+//	expired-cleanup [-soft]
+//	                    deletes the expired tokens, or soft deletes them
+//	export              writes an encrypted dump of the records and meta to stdout
+//	import              restores the dump written by export, read from stdin
+//	version             prints the vault version and the version supported by the command
+//
+// The database is configured with the -driver (sqlite, postgres or mysql) and -dsn flags,
+// or the VAULTSTORE_DRIVER and VAULTSTORE_DSN environment variables. The passwords are
+// read from the VAULTSTORE_PASSWORD and VAULTSTORE_NEW_PASSWORD environment variables,
+// and the password of the dumps from VAULTSTORE_EXPORT_PASSWORD, never from the
+// arguments, which the other users of the machine can see.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "vaultstore:", err)
+		stop()
+		os.Exit(1)
+	}
+}
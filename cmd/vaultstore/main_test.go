@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testEnv returns the environment of the command for a SQLite database in a temporary directory
+func testEnv(t *testing.T) map[string]string {
+	return map[string]string{
+		ENV_DRIVER:          "sqlite",
+		ENV_DSN:             filepath.Join(t.TempDir(), "vault.db") + "?parseTime=true",
+		ENV_PASSWORD:        "password_1234567890",
+		ENV_NEW_PASSWORD:    "password_new_1234567",
+		ENV_EXPORT_PASSWORD: "password_export_1234",
+	}
+}
+
+// testRun runs the command and returns its output
+func testRun(t *testing.T, env map[string]string, stdin string, args ...string) (string, error) {
+	getenv := func(name string) string { return env[name] }
+
+	var stdout bytes.Buffer
+	err := run(context.Background(), append([]string{"-automigrate"}, args...), getenv, strings.NewReader(stdin), &stdout)
+	return strings.TrimSpace(stdout.String()), err
+}
+
+func Test_Run_TokenCommands(t *testing.T) {
+	env := testEnv(t)
+
+	token, err := testRun(t, env, "secret\n", "create", "-length", "20")
+	if err != nil {
+		t.Fatalf("create: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(token) != 20 {
+		t.Fatalf("create: Expected a token of 20 characters received [%v]", token)
+	}
+
+	value, err := testRun(t, env, "", "read", token)
+	if err != nil {
+		t.Fatalf("read: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret" {
+		t.Fatalf("read: Expected [secret] received [%v]", value)
+	}
+
+	if _, err := testRun(t, env, "updated", "update", token); err != nil {
+		t.Fatalf("update: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	output, err := testRun(t, env, "", "rekey")
+	if err != nil {
+		t.Fatalf("rekey: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if output != "rekeyed 1 tokens" {
+		t.Fatalf("rekey: Expected [rekeyed 1 tokens] received [%v]", output)
+	}
+
+	env[ENV_PASSWORD] = env[ENV_NEW_PASSWORD]
+
+	value, err = testRun(t, env, "", "read", token)
+	if err != nil {
+		t.Fatalf("read: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "updated" {
+		t.Fatalf("read: Expected [updated] received [%v]", value)
+	}
+
+	if _, err := testRun(t, env, "", "delete", token); err != nil {
+		t.Fatalf("delete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := testRun(t, env, "", "read", token); err == nil {
+		t.Fatal("read: Expected an error for a deleted token")
+	}
+}
+
+func Test_Run_ExportImport(t *testing.T) {
+	source := testEnv(t)
+	target := testEnv(t)
+
+	token, err := testRun(t, source, "secret", "create")
+	if err != nil {
+		t.Fatalf("create: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	export, err := testRun(t, source, "", "export")
+	if err != nil {
+		t.Fatalf("export: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if strings.Contains(export, "secret") {
+		t.Fatal("export: Expected the values to stay encrypted")
+	}
+
+	output, err := testRun(t, target, export, "import")
+	if err != nil {
+		t.Fatalf("import: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if output != "imported 1 records, 0 unchanged, 0 conflicts" {
+		t.Fatalf("import: Expected 1 imported record received [%v]", output)
+	}
+
+	value, err := testRun(t, target, "", "read", token)
+	if err != nil {
+		t.Fatalf("read: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret" {
+		t.Fatalf("read: Expected [secret] received [%v]", value)
+	}
+}
+
+func Test_Run_Errors(t *testing.T) {
+	env := testEnv(t)
+
+	if _, err := testRun(t, env, "", "unknown"); err == nil {
+		t.Fatal("Expected an error for an unknown command")
+	}
+
+	if _, err := testRun(t, env, "", "read"); err == nil {
+		t.Fatal("Expected an error for a missing token")
+	}
+
+	delete(env, ENV_PASSWORD)
+	if _, err := testRun(t, env, "secret", "create"); err == nil {
+		t.Fatal("Expected an error for a missing password")
+	}
+
+	output, err := testRun(t, env, "", "version")
+	if err != nil {
+		t.Fatalf("version: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.Contains(output, "supported version") {
+		t.Fatalf("version: Expected the supported version received [%v]", output)
+	}
+}
@@ -35,16 +35,30 @@ const (
 
 // Object type constants for vault_meta table
 const (
+	OBJECT_TYPE_IDEMPOTENCY_KEY   = "idempotency_key"
 	OBJECT_TYPE_PASSWORD_IDENTITY = "password_identity"
 	OBJECT_TYPE_RECORD            = "record"
+	OBJECT_TYPE_RECORD_CHUNK      = "record_chunk"
+	OBJECT_TYPE_RECORD_TAG        = "record_tag"
+	OBJECT_TYPE_RECORD_VERSION    = "record_version"
+	OBJECT_TYPE_TOKEN_ALIAS       = "token_alias"
 	OBJECT_TYPE_VAULT_SETTINGS    = "vault"
 )
 
 // Meta key constants
 const (
-	META_KEY_HASH        = "hash"
-	META_KEY_PASSWORD_ID = "password_id"
-	META_KEY_VERSION     = "version"
+	META_KEY_BREAK_GLASS     = "break_glass"
+	META_KEY_CHECKOUT        = "checkout"
+	META_KEY_CONTENT_TYPE    = "content_type"
+	META_KEY_HASH            = "hash"
+	META_KEY_PASSWORD_ID     = "password_id"
+	META_KEY_QUARANTINE      = "quarantine"
+	META_KEY_READS_REMAINING = "reads_remaining"
+	META_KEY_RECORD_ID       = "record_id"
+	META_KEY_REVOCATION      = "revocation"
+	META_KEY_STREAM          = "stream"
+	META_KEY_TOKEN           = "token"
+	META_KEY_VERSION         = "version"
 )
 
 // Password identity ID prefix
@@ -71,12 +85,29 @@ const (
 	VAULT_SETTINGS_ID = "settings"
 )
 
+// Vault format versions, persisted in the vault settings under META_KEY_VERSION.
+// Stores refuse to start on a vault written by a newer format version.
+const (
+	VAULT_VERSION_CURRENT       = "1.1"
+	VAULT_VERSION_MIN_SUPPORTED = "1.0"
+)
+
 // Encryption version constants for versioned encryption
 const (
 	ENCRYPTION_VERSION_V1 = "v1"
 	ENCRYPTION_VERSION_V2 = "v2"
+	ENCRYPTION_VERSION_V3 = "v3"
 	ENCRYPTION_PREFIX_V1  = ENCRYPTION_VERSION_V1 + ":"
 	ENCRYPTION_PREFIX_V2  = ENCRYPTION_VERSION_V2 + ":"
+	ENCRYPTION_PREFIX_V3  = ENCRYPTION_VERSION_V3 + ":"
+)
+
+// Ciphers selectable with CryptoConfig.Cipher
+const (
+	// CIPHER_AES_GCM encrypts in the v2 format, the default
+	CIPHER_AES_GCM = "aes-gcm"
+	// CIPHER_XCHACHA20_POLY1305 encrypts in the v3 format, for platforms without AES hardware acceleration
+	CIPHER_XCHACHA20_POLY1305 = "xchacha20-poly1305"
 )
 
 // v2 encryption parameters (AES-GCM + Argon2id)
@@ -102,6 +133,23 @@ type CryptoConfig struct {
 	SaltSize  int // in bytes
 	NonceSize int // in bytes
 	TagSize   int // in bytes
+
+	// Cipher selects the format of new values, CIPHER_AES_GCM (v2, default) or
+	// CIPHER_XCHACHA20_POLY1305 (v3). Values of both formats are always readable.
+	// Envelope encryption always uses AES-GCM.
+	Cipher string
+
+	// kdfStats measures the key derivations of the store owning the config
+	kdfStats *kdfStats
+
+	// metrics receives the metrics of the store owning the config (nil = discarded)
+	metrics MetricsCollector
+
+	// envelope holds the key providers of envelope encryption (nil = disabled)
+	envelope *envelopeKeys
+
+	// pepper is mixed into the passwords before the key derivation (nil = disabled)
+	pepper []byte
 }
 
 // DefaultCryptoConfig returns secure default cryptographic parameters
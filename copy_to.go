@@ -0,0 +1,241 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrCopyDestinationMissing is returned by CopyTo without a destination store
+	ErrCopyDestinationMissing = errors.New("copy destination store is required")
+	// ErrCopyMetaUnsupported is returned by CopyTo for records with metadata when the
+	// destination is not a store created by NewStore, the metadata could not be copied
+	ErrCopyMetaUnsupported = errors.New("copy destination store does not support metadata")
+)
+
+// CopyOptions configures CopyTo
+type CopyOptions struct {
+	// Rekey re-encrypts the values of OldPassword with NewPassword while copying.
+	// The values of other passwords are copied unchanged.
+	Rekey       bool
+	OldPassword string
+	NewPassword string
+
+	// Cursor resumes an interrupted copy, pass the cursor of the last progress report
+	Cursor string
+
+	// Progress, if set, is called after each copied batch
+	Progress func(progress CopyResult)
+}
+
+// CopyResult is the outcome of CopyTo, and its progress after each batch
+type CopyResult struct {
+	// Copied is the number of records created or updated in the destination
+	Copied int
+	// Unchanged is the number of records already present in the destination
+	Unchanged int
+	// Rekeyed is the number of values re-encrypted with the new password
+	Rekeyed int
+	// Meta is the number of meta rows of the records created or updated in the destination
+	Meta int
+	// Conflicts lists the records that were skipped, see ApplyChanges
+	Conflicts []SyncConflict
+	// Cursor is the ID of the last copied record, passed as CopyOptions.Cursor to resume
+	Cursor string
+}
+
+// CopyTo streams all records, soft deleted ones included, into another store,
+// e.g. to move a vault from SQLite to Postgres. Records are copied in batches of
+// 1000, ordered by ID, and applied with dest.ApplyChanges. After each batch
+// opts.Progress receives the counts and the cursor to resume from.
+//
+// With opts.Rekey the values of opts.OldPassword are re-encrypted with
+// opts.NewPassword on the fly, the source store is left unchanged. As the
+// re-encrypted values differ on each run, resume a rekeying copy from its
+// cursor, copying it again reports the records as conflicts.
+// The stores must share the crypto settings (pepper, envelope key provider).
+//
+// The metadata of the records (revocation, quarantine, read limits, tags, aliases,
+// chunks, versions, etc.) is copied with them, the metadata of conflicting records
+// is skipped. Records with metadata are refused with ErrCopyMetaUnsupported if
+// dest was not created by NewStore. The aliases are scoped to the vault table,
+// they are copied if dest has the same vault table name. Other metadata, e.g.
+// the vault settings, is not copied, use Export and Import to move it too.
+//
+// Parameters:
+// - ctx: The context
+// - dest: The store receiving the records
+// - opts: The copy options
+//
+// Returns:
+// - result: The number of copied, unchanged and rekeyed records, the conflicts and the cursor
+// - err: ErrCopyMetaUnsupported, or an error if something went wrong, the result holds the cursor to resume from
+func (store *storeImplementation) CopyTo(ctx context.Context, dest StoreInterface, opts CopyOptions) (CopyResult, error) {
+	ctx, span := store.traceStart(ctx, "CopyTo")
+	defer span.End()
+
+	result := CopyResult{Conflicts: []SyncConflict{}, Cursor: opts.Cursor}
+
+	if err := store.operationAllow(ctx, "CopyTo", ""); err != nil {
+		return result, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if dest == nil {
+		return result, ErrCopyDestinationMissing
+	}
+
+	// The meta rows are written with the internal methods of the destination
+	destStore, _ := dest.(*storeImplementation)
+
+	if opts.Rekey {
+		if err := store.validatePassword(opts.OldPassword); err != nil {
+			return result, err
+		}
+		if err := store.validatePassword(opts.NewPassword); err != nil {
+			return result, err
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		var gormRecords []gormVaultRecord
+		err := store.vaultDB(ctx).
+			Where(COLUMN_ID+" > ?", result.Cursor).
+			Order(COLUMN_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&gormRecords).Error
+		if err != nil {
+			return result, err
+		}
+
+		if len(gormRecords) == 0 {
+			copied, err := store.copyAliasMeta(ctx, destStore)
+			result.Meta += copied
+			return result, err
+		}
+
+		if err := store.valueChunksResolve(ctx, gormRecords); err != nil {
+			return result, err
+		}
+
+		metas, err := store.recordsMetaFind(ctx, gormRecords)
+		if err != nil {
+			return result, err
+		}
+
+		if len(metas) > 0 && destStore == nil {
+			return result, ErrCopyMetaUnsupported
+		}
+
+		batch := ChangeBatch{Changes: make([]Change, 0, len(gormRecords))}
+		rekeyed := 0
+		for i := range gormRecords {
+			change := changeFromGorm(&gormRecords[i])
+
+			if opts.Rekey {
+				// The values of other passwords are not decrypt failures
+				decryptedValue, err := decodeValue(ctx, change.Value, opts.OldPassword, store.cryptoConfig)
+				if err == nil {
+					change.Value, err = encode(ctx, decryptedValue, opts.NewPassword, store.cryptoConfig)
+					if err != nil {
+						return result, fmt.Errorf("failed to encode value for record %s: %w", change.ID, err)
+					}
+					rekeyed++
+				}
+			}
+
+			batch.Changes = append(batch.Changes, change)
+		}
+
+		applied, err := dest.ApplyChanges(ctx, batch)
+		if err != nil {
+			return result, err
+		}
+
+		// The meta of the conflicting records is not copied
+		conflictObjectIDs := map[string]bool{}
+		for _, conflict := range applied.Conflicts {
+			conflictObjectIDs[recordMetaObjectID(conflict.ID)] = true
+		}
+
+		copiedMetas := []gormVaultMeta{}
+		for _, meta := range metas {
+			if conflictObjectIDs[meta.ObjectID] {
+				continue
+			}
+
+			if err := destStore.metaSet(ctx, meta.ObjectType, meta.ObjectID, meta.Key, meta.Value); err != nil {
+				return result, err
+			}
+			copiedMetas = append(copiedMetas, meta)
+			result.Meta++
+		}
+
+		// The chunks of the streamed tokens are copied still encrypted with their stream keys
+		err = store.streamChunksEach(ctx, copiedMetas, func(rows []gormVaultChunk) error {
+			return destStore.streamChunksImport(ctx, rows)
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.Copied += applied.Applied
+		result.Unchanged += applied.Unchanged
+		result.Rekeyed += rekeyed
+		result.Conflicts = append(result.Conflicts, applied.Conflicts...)
+		result.Cursor = gormRecords[len(gormRecords)-1].ID
+
+		if opts.Progress != nil {
+			opts.Progress(result)
+		}
+	}
+}
+
+// copyAliasMeta copies the token aliases to the destination store if it has the same
+// vault table name, as the alias object IDs are scoped to it. Aliases of tokens missing
+// from the destination are released when resolved.
+func (store *storeImplementation) copyAliasMeta(ctx context.Context, destStore *storeImplementation) (int, error) {
+	if destStore == nil || destStore.vaultTableName != store.vaultTableName {
+		return 0, nil
+	}
+
+	copied := 0
+	var lastMetaID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return copied, err
+		}
+
+		var metas []gormVaultMeta
+		err := store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" = ?", OBJECT_TYPE_TOKEN_ALIAS).
+			Where(COLUMN_ID+" > ?", lastMetaID).
+			Order(COLUMN_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&metas).Error
+		if err != nil {
+			return copied, err
+		}
+
+		if len(metas) == 0 {
+			return copied, nil
+		}
+		lastMetaID = metas[len(metas)-1].ID
+
+		for i := range metas {
+			if err := store.metaValueDecrypt(&metas[i]); err != nil {
+				return copied, err
+			}
+
+			if err := destStore.metaSet(ctx, metas[i].ObjectType, metas[i].ObjectID, metas[i].Key, metas[i].Value); err != nil {
+				return copied, err
+			}
+			copied++
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package vaultstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"testing"
+)
+
+// initCopyStore creates a store with its own tables on the database
+func initCopyStore(t *testing.T, db *sql.DB, name string) StoreInterface {
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_" + name,
+		VaultMetaTableName: "vault_" + name + "_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	return store
+}
+
+func Test_Store_CopyTo(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	source := initCopyStore(t, db, "copy_source")
+	dest := initCopyStore(t, db, "copy_dest")
+
+	ctx := context.Background()
+	oldPassword := "old_password_that_is_long_enough_for_security_32chars"
+	newPassword := "new_password_that_is_long_enough_for_security_32chars"
+	otherPassword := "other_password_that_is_long_enough_for_security_32chars"
+
+	tokens := []string{}
+	for _, value := range []string{"one", "two", "three"} {
+		token, err := source.TokenCreate(ctx, value, oldPassword, 20)
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+		tokens = append(tokens, token)
+	}
+
+	otherToken, err := source.TokenCreate(ctx, "other", otherPassword, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	progressCalls := 0
+	result, err := source.CopyTo(ctx, dest, CopyOptions{
+		Rekey:       true,
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+		Progress: func(progress CopyResult) {
+			progressCalls++
+		},
+	})
+	if err != nil {
+		t.Fatalf("CopyTo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if result.Copied != 4 || result.Rekeyed != 3 || len(result.Conflicts) != 0 {
+		t.Fatalf("Expected [4] copied and [3] rekeyed records received [%v] [%v] [%v]", result.Copied, result.Rekeyed, result.Conflicts)
+	}
+
+	if progressCalls != 1 || result.Cursor == "" {
+		t.Fatalf("Expected [1] progress report and a cursor received [%v] [%v]", progressCalls, result.Cursor)
+	}
+
+	value, err := dest.TokenRead(ctx, tokens[0], newPassword)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "one" {
+		t.Fatalf("Expected [one] received [%v]", value)
+	}
+
+	value, err = dest.TokenRead(ctx, otherToken, otherPassword)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "other" {
+		t.Fatalf("Expected [other] received [%v]", value)
+	}
+
+	// The source keeps the old password
+	if _, err := source.TokenRead(ctx, tokens[0], oldPassword); err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Resuming from the cursor copies nothing more
+	result, err = source.CopyTo(ctx, dest, CopyOptions{Cursor: result.Cursor})
+	if err != nil {
+		t.Fatalf("CopyTo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if result.Copied != 0 || result.Unchanged != 0 {
+		t.Fatalf("Expected nothing copied received [%v] [%v]", result.Copied, result.Unchanged)
+	}
+}
+
+func Test_Store_CopyToResume(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	source := initCopyStore(t, db, "resume_source")
+	dest := initCopyStore(t, db, "resume_dest")
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	for _, value := range []string{"one", "two", "three"} {
+		if _, err := source.TokenCreate(ctx, value, password, 20); err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	records, err := source.RecordList(ctx, RecordQuery())
+	if err != nil {
+		t.Fatalf("RecordList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].GetID() < records[j].GetID() })
+
+	// Resume after the first record, as if the copy was interrupted
+	result, err := source.CopyTo(ctx, dest, CopyOptions{Cursor: records[0].GetID()})
+	if err != nil {
+		t.Fatalf("CopyTo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if result.Copied != 2 {
+		t.Fatalf("Expected [2] copied records received [%v]", result.Copied)
+	}
+
+	exists, err := dest.TokenExists(ctx, records[0].GetToken())
+	if err != nil || exists {
+		t.Fatalf("Expected the first record not to be copied received [%v] [%v]", exists, err)
+	}
+
+	if _, err := source.CopyTo(ctx, nil, CopyOptions{}); !errors.Is(err, ErrCopyDestinationMissing) {
+		t.Fatalf("Expected [ErrCopyDestinationMissing] received [%v]", err)
+	}
+}
+
+func Test_Store_CopyToMeta(t *testing.T) {
+	sourceDB, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	destDB, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The same table names on both databases, so the aliases are copied too
+	source := initCopyStore(t, sourceDB, "copy_meta")
+	dest := initCopyStore(t, destDB, "copy_meta")
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	revokedToken, err := source.TokenCreate(ctx, "revoked", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := source.TokenRevoke(ctx, revokedToken, "leaked"); err != nil {
+		t.Fatalf("TokenRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	token, err := source.TokenCreate(ctx, "aliased", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := source.TokenAliasAdd(ctx, token, "prod/api-key"); err != nil {
+		t.Fatalf("TokenAliasAdd: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	result, err := source.CopyTo(ctx, dest, CopyOptions{})
+	if err != nil {
+		t.Fatalf("CopyTo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if result.Copied != 2 || result.Meta == 0 {
+		t.Fatalf("Expected [2] copied records with meta received [%v] [%v]", result.Copied, result.Meta)
+	}
+
+	// The revoked token stays revoked in the destination
+	if _, err := dest.TokenRead(ctx, revokedToken, password); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("Expected [ErrTokenRevoked] received [%v]", err)
+	}
+
+	resolved, err := dest.TokenAliasResolve(ctx, "prod/api-key")
+	if err != nil {
+		t.Fatalf("TokenAliasResolve: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if resolved != token {
+		t.Fatalf("Expected [%v] received [%v]", token, resolved)
+	}
+}
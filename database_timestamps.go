@@ -0,0 +1,115 @@
+package vaultstore
+
+import (
+	"context"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// databaseNow returns the SQL expression for the current UTC time of the database
+func (store *storeImplementation) databaseNow() clause.Expr {
+	switch store.gormDB.Dialector.Name() {
+	case DIALECT_MYSQL:
+		return gorm.Expr("UTC_TIMESTAMP()")
+	case DIALECT_POSTGRES:
+		return gorm.Expr("(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')")
+	case DIALECT_SQLSERVER:
+		return gorm.Expr("CAST(SYSUTCDATETIME() AS DATETIME2(0))")
+	default:
+		// SQLite's CURRENT_TIMESTAMP is always UTC
+		return gorm.Expr("CURRENT_TIMESTAMP")
+	}
+}
+
+// timestampValue returns the value for a created_at/updated_at column,
+// the database clock if DatabaseTimestamps is enabled, otherwise the given application time
+func (store *storeImplementation) timestampValue(appNow string) interface{} {
+	if store.databaseTimestamps {
+		return store.databaseNow()
+	}
+	return appNow
+}
+
+// recordInsertValue returns the value to pass to GORM's Create for the record.
+// A map is used for database timestamps, custom columns and the namespace,
+// which the struct cannot hold.
+func (store *storeImplementation) recordInsertValue(ctx context.Context, record *gormVaultRecord) interface{} {
+	if !store.databaseTimestamps && len(store.recordExtraColumns) == 0 && !store.namespacesEnabled {
+		return record
+	}
+
+	values := map[string]interface{}{
+		COLUMN_ID:              record.ID,
+		COLUMN_VAULT_TOKEN:     record.Token,
+		COLUMN_VAULT_VALUE:     record.Value,
+		COLUMN_CREATED_AT:      store.timestampValue(record.CreatedAt),
+		COLUMN_UPDATED_AT:      store.timestampValue(record.UpdatedAt),
+		COLUMN_EXPIRES_AT:      record.ExpiresAt,
+		COLUMN_SOFT_DELETED_AT: record.SoftDeletedAt,
+	}
+
+	for column, value := range record.Extra {
+		values[column] = value
+	}
+
+	if store.namespacesEnabled {
+		// An invalid namespace is attached to the session by vaultDB, failing the insert
+		values[COLUMN_NAMESPACE], _ = store.namespaceFromContext(ctx)
+	}
+
+	return values
+}
+
+// recordInsertValues returns the value to pass to GORM's CreateInBatches for the records
+func (store *storeImplementation) recordInsertValues(ctx context.Context, records []*gormVaultRecord) interface{} {
+	if !store.databaseTimestamps && len(store.recordExtraColumns) == 0 && !store.namespacesEnabled {
+		return records
+	}
+
+	values := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		values[i] = store.recordInsertValue(ctx, record).(map[string]interface{})
+	}
+	return values
+}
+
+// recordTimestampsLoad reads the created_at/updated_at values set by the database
+// back into the records, so the getters return the stored timestamps
+func (store *storeImplementation) recordTimestampsLoad(ctx context.Context, records []RecordInterface) error {
+	if !store.databaseTimestamps {
+		return nil
+	}
+
+	for _, batch := range lo.Chunk(records, maxRecordsInMemory) {
+		recordIDs := lo.Map(batch, func(record RecordInterface, _ int) string {
+			return record.GetID()
+		})
+
+		var rows []gormVaultRecord
+		err := store.vaultDB(ctx).
+			Select(COLUMN_ID, COLUMN_CREATED_AT, COLUMN_UPDATED_AT).
+			Where(COLUMN_ID+" IN ?", recordIDs).
+			Find(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		rowsByID := lo.KeyBy(rows, func(row gormVaultRecord) string {
+			return row.ID
+		})
+
+		for _, record := range batch {
+			row, ok := rowsByID[record.GetID()]
+			if !ok {
+				continue
+			}
+			record.SetCreatedAt(carbon.Parse(row.CreatedAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+			record.SetUpdatedAt(carbon.Parse(row.UpdatedAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		}
+	}
+
+	return nil
+}
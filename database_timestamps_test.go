@@ -0,0 +1,62 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func Test_Store_DatabaseTimestamps(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_database_timestamps",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		DatabaseTimestamps: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	record := NewRecord().SetToken("test_token_db_time").SetValue("test_value")
+
+	err = store.RecordCreate(ctx, record)
+	if err != nil {
+		t.Fatalf("RecordCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	createdAt := carbon.Parse(record.GetCreatedAt(), carbon.UTC)
+	if createdAt.IsZero() {
+		t.Fatalf("Expected [created_at] to be set received [%v]", record.GetCreatedAt())
+	}
+
+	if createdAt.DiffAbsInSeconds(carbon.Now(carbon.UTC)) > 60 {
+		t.Fatalf("Expected [created_at] to be the current UTC time received [%v]", record.GetCreatedAt())
+	}
+
+	record.SetValue("updated_value")
+	err = store.RecordUpdate(ctx, record)
+	if err != nil {
+		t.Fatalf("RecordUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	found, err := store.RecordFindByToken(ctx, "test_token_db_time")
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if found == nil {
+		t.Fatal("Expected record to be found")
+	}
+
+	if carbon.Parse(found.GetUpdatedAt(), carbon.UTC).IsZero() {
+		t.Fatalf("Expected [updated_at] to be set received [%v]", found.GetUpdatedAt())
+	}
+}
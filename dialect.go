@@ -0,0 +1,161 @@
+package vaultstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Dialect names of the supported databases, as returned by the Name method of the GORM dialectors
+const (
+	DIALECT_SQLITE    = "sqlite"
+	DIALECT_MYSQL     = "mysql"
+	DIALECT_POSTGRES  = "postgres"
+	DIALECT_SQLSERVER = "sqlserver"
+)
+
+// ErrDialectorRequired is returned by NewStore for SQL Server connections without a Dialector option
+var ErrDialectorRequired = errors.New("vault store: SQL Server requires the Dialector option, e.g. sqlserver.New(sqlserver.Config{Conn: db})")
+
+// dialectName returns the dialect of a driver or database type name,
+// or an empty string if the name is not one of a supported database
+func dialectName(name string) string {
+	switch strings.ToLower(name) {
+	case "sqlite", "sqlite3":
+		return DIALECT_SQLITE
+	case "mysql":
+		return DIALECT_MYSQL
+	case "postgres", "postgresql", "pgx":
+		return DIALECT_POSTGRES
+	case "sqlserver", "mssql":
+		return DIALECT_SQLSERVER
+	default:
+		return ""
+	}
+}
+
+// dialectorNew returns the GORM dialector for the connection. The driver name wins when it names
+// a supported database, otherwise the database type is detected from the connection.
+func dialectorNew(driverName string, databaseType string, db *sql.DB) (gorm.Dialector, error) {
+	dialect := dialectName(driverName)
+	if dialect == "" {
+		dialect = dialectName(databaseType)
+	}
+
+	switch dialect {
+	case DIALECT_SQLITE:
+		return sqlite.New(sqlite.Config{Conn: db}), nil
+	case DIALECT_MYSQL:
+		return mysql.New(mysql.Config{Conn: db}), nil
+	case DIALECT_POSTGRES:
+		return postgres.New(postgres.Config{Conn: db}), nil
+	case DIALECT_SQLSERVER:
+		// The SQL Server driver is not a dependency of the store, see NewStoreOptions.Dialector
+		return nil, ErrDialectorRequired
+	default:
+		return nil, fmt.Errorf("unsupported database connection: %s", databaseType)
+	}
+}
+
+// gormVaultRecordPostgres is the migration model of the vault records for PostgreSQL,
+// which has no longtext and datetime column types. Reads and writes use gormVaultRecord.
+type gormVaultRecordPostgres struct {
+	ID            string `gorm:"primaryKey;size:40;column:id;not null"`
+	Token         string `gorm:"uniqueIndex;size:40;column:vault_token;not null"`
+	Value         string `gorm:"type:text;column:vault_value;not null"`
+	CreatedAt     string `gorm:"type:timestamp(0);column:created_at;not null"`
+	UpdatedAt     string `gorm:"type:timestamp(0);column:updated_at;not null"`
+	ExpiresAt     string `gorm:"type:timestamp(0);column:expires_at;not null"`
+	SoftDeletedAt string `gorm:"type:timestamp(0);column:soft_deleted_at;not null"`
+}
+
+// TableName returns the table name for the GORM model
+func (gormVaultRecordPostgres) TableName() string {
+	return "" // Will be set dynamically via store.vaultTableName
+}
+
+// gormVaultRecordSQLServer is the migration model of the vault records for SQL Server
+type gormVaultRecordSQLServer struct {
+	ID            string `gorm:"primaryKey;size:40;column:id;not null"`
+	Token         string `gorm:"uniqueIndex;size:40;column:vault_token;not null"`
+	Value         string `gorm:"type:nvarchar(max);column:vault_value;not null"`
+	CreatedAt     string `gorm:"type:datetime2(0);column:created_at;not null"`
+	UpdatedAt     string `gorm:"type:datetime2(0);column:updated_at;not null"`
+	ExpiresAt     string `gorm:"type:datetime2(0);column:expires_at;not null"`
+	SoftDeletedAt string `gorm:"type:datetime2(0);column:soft_deleted_at;not null"`
+}
+
+// TableName returns the table name for the GORM model
+func (gormVaultRecordSQLServer) TableName() string {
+	return "" // Will be set dynamically via store.vaultTableName
+}
+
+// gormVaultChunkText is the migration model of the value chunks for the databases
+// without a longtext column type (PostgreSQL, SQL Server)
+type gormVaultChunkText struct {
+	ID        uint   `gorm:"primaryKey;column:id"`
+	RecordID  string `gorm:"index;size:40;column:record_id;not null"`
+	ValueHash string `gorm:"size:64;column:value_hash;not null"`
+	Sequence  int    `gorm:"column:sequence;not null"`
+	Data      string `gorm:"column:chunk_data;not null"` // unsized strings are text / nvarchar(max)
+}
+
+// TableName returns the table name for the GORM model
+func (gormVaultChunkText) TableName() string {
+	return "" // Will be set dynamically via the vault table name
+}
+
+// vaultRecordMigrationModel returns the model migrating the vault table with the column types of the dialect
+func (store *storeImplementation) vaultRecordMigrationModel() interface{} {
+	switch store.gormDB.Dialector.Name() {
+	case DIALECT_POSTGRES:
+		return &gormVaultRecordPostgres{}
+	case DIALECT_SQLSERVER:
+		return &gormVaultRecordSQLServer{}
+	default:
+		return &gormVaultRecord{}
+	}
+}
+
+// valueChunkMigrationModel returns the model migrating the value chunk table with the column types of the dialect
+func (store *storeImplementation) valueChunkMigrationModel() interface{} {
+	switch store.gormDB.Dialector.Name() {
+	case DIALECT_POSTGRES, DIALECT_SQLSERVER:
+		return &gormVaultChunkText{}
+	default:
+		return &gormVaultChunk{}
+	}
+}
+
+// metaRecordIDExpr returns the SQL expression extracting the record ID from the object ID of
+// record metadata. SQL Server has no SUBSTR, and its SUBSTRING requires the length.
+func (store *storeImplementation) metaRecordIDExpr() string {
+	if store.gormDB.Dialector.Name() == DIALECT_SQLSERVER {
+		return "SUBSTRING(" + COLUMN_OBJECT_ID + ", " + strconv.Itoa(len(RECORD_META_ID_PREFIX)+1) + ", 64)"
+	}
+	return "SUBSTR(" + COLUMN_OBJECT_ID + ", " + strconv.Itoa(len(RECORD_META_ID_PREFIX)+1) + ")"
+}
+
+// lengthExpr returns the SQL expression of the length of a text column, SQL Server has no LENGTH.
+// The stored values are base64 or hex encoded, so characters and bytes count the same.
+func (store *storeImplementation) lengthExpr(column string) string {
+	if store.gormDB.Dialector.Name() == DIALECT_SQLSERVER {
+		return "LEN(" + column + ")"
+	}
+	return "LENGTH(" + column + ")"
+}
+
+// addColumnKeyword returns the ALTER TABLE clause adding a column, SQL Server has no COLUMN keyword
+func (store *storeImplementation) addColumnKeyword() string {
+	if store.gormDB.Dialector.Name() == DIALECT_SQLSERVER {
+		return "ADD"
+	}
+	return "ADD COLUMN"
+}
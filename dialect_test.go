@@ -0,0 +1,81 @@
+package vaultstore
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_DialectName(t *testing.T) {
+	cases := map[string]string{
+		"sqlite":        DIALECT_SQLITE,
+		"sqlite3":       DIALECT_SQLITE,
+		"mysql":         DIALECT_MYSQL,
+		"postgres":      DIALECT_POSTGRES,
+		"PostgreSQL":    DIALECT_POSTGRES,
+		"pgx":           DIALECT_POSTGRES,
+		"mssql":         DIALECT_SQLSERVER,
+		"sqlserver":     DIALECT_SQLSERVER,
+		"custom_driver": "",
+		"":              "",
+	}
+
+	for name, expected := range cases {
+		if dialect := dialectName(name); dialect != expected {
+			t.Fatalf("%s: Expected [%v] received [%v]", name, expected, dialect)
+		}
+	}
+}
+
+func Test_DialectorNew(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The driver name wins over the detected database type
+	dialector, err := dialectorNew("postgres", "sqlite", db)
+	if err != nil {
+		t.Fatalf("dialectorNew: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if dialector.Name() != DIALECT_POSTGRES {
+		t.Fatalf("Expected [%v] received [%v]", DIALECT_POSTGRES, dialector.Name())
+	}
+
+	// Unknown driver names fall back to the detected database type
+	dialector, err = dialectorNew("custom_driver", "sqlite", db)
+	if err != nil {
+		t.Fatalf("dialectorNew: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if dialector.Name() != DIALECT_SQLITE {
+		t.Fatalf("Expected [%v] received [%v]", DIALECT_SQLITE, dialector.Name())
+	}
+
+	if _, err := dialectorNew("", "mssql", db); !errors.Is(err, ErrDialectorRequired) {
+		t.Fatalf("Expected ErrDialectorRequired received [%v]", err)
+	}
+
+	if _, err := dialectorNew("", "oracle", db); err == nil {
+		t.Fatal("Expected an error for an unsupported database")
+	}
+}
+
+func Test_Store_MigrationModels(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	implementation := store.(*storeImplementation)
+
+	if _, ok := implementation.vaultRecordMigrationModel().(*gormVaultRecord); !ok {
+		t.Fatalf("Expected the SQLite vault table to use gormVaultRecord received [%T]", implementation.vaultRecordMigrationModel())
+	}
+
+	if _, ok := implementation.valueChunkMigrationModel().(*gormVaultChunk); !ok {
+		t.Fatalf("Expected the SQLite chunk table to use gormVaultChunk received [%T]", implementation.valueChunkMigrationModel())
+	}
+
+	if implementation.addColumnKeyword() != "ADD COLUMN" {
+		t.Fatalf("Expected [ADD COLUMN] received [%v]", implementation.addColumnKeyword())
+	}
+}
@@ -1,25 +1,81 @@
 package vaultstore
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	cryptorand "crypto/rand"
+	"context"
 	"errors"
-	"fmt"
-	"io"
 	"math/rand/v2"
 	"strconv"
 	"strings"
+	"time"
 
-	"golang.org/x/crypto/argon2"
+	"github.com/dracory/vaultstore/vaultcrypt"
 )
 
-func decode(value string, password string, config *CryptoConfig) (string, error) {
+// ErrDecryptionFailed is returned when a value cannot be decrypted,
+// typically because the password is wrong
+var ErrDecryptionFailed = vaultcrypt.ErrDecryptionFailed
+
+// Params returns the parameters of the config for the vaultcrypt package,
+// e.g. to hand them to producers encrypting values offline
+func (config *CryptoConfig) Params() vaultcrypt.Params {
+	return vaultcrypt.Params{
+		Iterations:  config.Iterations,
+		Memory:      config.Memory,
+		Parallelism: config.Parallelism,
+		KeyLength:   config.KeyLength,
+		SaltSize:    config.SaltSize,
+		NonceSize:   config.NonceSize,
+		TagSize:     config.TagSize,
+		Cipher:      config.Cipher,
+	}
+}
+
+// params returns the parameters of the config with the pepper of the store, which
+// Params leaves out so that it is not handed to producers by mistake
+func (config *CryptoConfig) params() vaultcrypt.Params {
+	params := config.Params()
+	params.Pepper = config.pepper
+	return params
+}
+
+// decode decrypts a value of any encryption version, recording the metrics of the store.
+// The context is passed to the KeyProvider unwrapping the data keys of envelope values.
+func decode(ctx context.Context, value string, password string, config *CryptoConfig) (string, error) {
+	start := time.Now()
+	decoded, err := decodeValue(ctx, value, password, config)
+	config.metricsDecrypt(time.Since(start), err)
+
+	return decoded, err
+}
+
+// decodeValue decrypts a value, selecting the encryption version by its prefix
+func decodeValue(ctx context.Context, value string, password string, config *CryptoConfig) (string, error) {
 	// Check for v2 encryption prefix (AES-GCM)
 	if strings.HasPrefix(value, ENCRYPTION_PREFIX_V2) {
 		return decodeV2(value, password, config)
 	}
 
+	// v3 encryption prefix (XChaCha20-Poly1305)
+	if strings.HasPrefix(value, ENCRYPTION_PREFIX_V3) {
+		return decodeV3(value, password, config)
+	}
+
+	// v2 or v3 encryption with a peppered password
+	if strings.HasPrefix(value, ENCRYPTION_PREFIX_PEPPER) {
+		if config == nil {
+			return "", ErrPepperMissing
+		}
+		return decodePassword(value, password, config, vaultcrypt.Decode)
+	}
+
+	// Envelope encryption (data key wrapped by the key provider)
+	if strings.HasPrefix(value, ENCRYPTION_PREFIX_ENVELOPE) {
+		if config == nil {
+			return "", ErrKeyProviderMissing
+		}
+		return decodeEnvelope(ctx, value, password, config)
+	}
+
 	// Legacy v1 decryption (XOR-based)
 	return decodeV1(value, password)
 }
@@ -37,7 +93,7 @@ func decodeV1(value string, password string) (string, error) {
 	}
 
 	if !isBase64(first) {
-		return "", errors.New("decryption failed")
+		return "", ErrDecryptionFailed
 	}
 
 	v4, err := base64Decode(first)
@@ -49,7 +105,7 @@ func decodeV1(value string, password string) (string, error) {
 	parts := strings.Split(string(v4), "_")
 
 	if len(parts) < 2 {
-		return "", errors.New("decryption failed")
+		return "", ErrDecryptionFailed
 	}
 
 	upTo, err := strconv.Atoi(parts[0])
@@ -70,55 +126,37 @@ func decodeV1(value string, password string) (string, error) {
 	return string(v2), nil
 }
 
-// decodeV2 handles AES-GCM decryption with Argon2id key derivation
+// decodeV2 handles AES-GCM decryption with Argon2id key derivation, see vaultcrypt.DecodeV2
 func decodeV2(value string, password string, config *CryptoConfig) (string, error) {
 	// Use defaults if config is nil
 	if config == nil {
 		config = DefaultCryptoConfig()
 	}
 
-	// Remove the v2: prefix
-	encodedData := strings.TrimPrefix(value, ENCRYPTION_PREFIX_V2)
-
-	// Decode base64
-	data, err := base64Decode(encodedData)
-	if err != nil {
-		return "", errors.New("base64 decode: " + err.Error())
-	}
+	return decodePassword(value, password, config, vaultcrypt.DecodeV2)
+}
 
-	// Check minimum length (salt + nonce + tag)
-	minLength := config.SaltSize + config.NonceSize + config.TagSize
-	if len(data) < minLength {
-		return "", errors.New("invalid ciphertext length")
+// decodeV3 handles XChaCha20-Poly1305 decryption with Argon2id key derivation, see vaultcrypt.DecodeV3
+func decodeV3(value string, password string, config *CryptoConfig) (string, error) {
+	// Use defaults if config is nil
+	if config == nil {
+		config = DefaultCryptoConfig()
 	}
 
-	// Extract salt, nonce, and ciphertext
-	salt := data[:config.SaltSize]
-	nonce := data[config.SaltSize : config.SaltSize+config.NonceSize]
-	ciphertext := data[config.SaltSize+config.NonceSize:]
-
-	// Derive key using Argon2id
-	key := deriveKeyArgon2id(password, salt, config)
-
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", errors.New("aes cipher: " + err.Error())
-	}
+	return decodePassword(value, password, config, vaultcrypt.DecodeV3)
+}
 
-	// Create GCM
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", errors.New("gcm: " + err.Error())
-	}
+// decodePassword decrypts a value with a key derived from the password, measuring the key derivation
+func decodePassword(value string, password string, config *CryptoConfig, decodeFn func(string, string, vaultcrypt.Params) (string, error)) (string, error) {
+	start := time.Now()
+	plaintext, err := decodeFn(value, password, config.params())
 
-	// Decrypt
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", errors.New("decryption failed: " + err.Error())
+	// Malformed values fail before the key derivation, they are not measured
+	if err == nil || errors.Is(err, ErrDecryptionFailed) {
+		config.kdfRecord(KDF_OPERATION_DECRYPT, time.Since(start))
 	}
 
-	return string(plaintext), nil
+	return plaintext, err
 }
 
 // encode encrypts a value using the current encryption version (v2 - AES-GCM with Argon2id)
@@ -129,56 +167,73 @@ func decodeV2(value string, password string, config *CryptoConfig) (string, erro
 // Encryption versions:
 //   - v1 (deprecated): XOR encryption with MD5/SHA1 key derivation (insecure, for decryption only)
 //   - v2 (current): AES-GCM with Argon2id key derivation (secure, used for all new data)
-func encode(value string, password string, config *CryptoConfig) (string, error) {
+//   - v3: XChaCha20-Poly1305 with Argon2id key derivation, used instead of v2 with CIPHER_XCHACHA20_POLY1305
+//   - pep: v2 or v3 with the password mixed with the store pepper, used when a pepper is configured
+//   - env: AES-GCM with a random data key wrapped by the KeyProvider, used when envelope encryption is enabled
+func encode(ctx context.Context, value string, password string, config *CryptoConfig) (string, error) {
+	start := time.Now()
+	encoded, err := encodeValue(ctx, value, password, config)
+	config.metricsEncrypt(time.Since(start), err)
+
+	return encoded, err
+}
+
+// encodeValue encrypts a value with the encryption version selected by the config
+func encodeValue(ctx context.Context, value string, password string, config *CryptoConfig) (string, error) {
 	// Use defaults if config is nil
 	if config == nil {
 		config = DefaultCryptoConfig()
 	}
+	if config.envelope != nil {
+		return encodeEnvelope(ctx, value, password, config)
+	}
+	if len(config.pepper) > 0 {
+		return encodePassword(value, password, config, vaultcrypt.Encode)
+	}
+	if config.Cipher == CIPHER_XCHACHA20_POLY1305 {
+		return encodeV3(value, password, config)
+	}
 	return encodeV2(value, password, config)
 }
 
-// encodeV2 encrypts using AES-GCM with Argon2id key derivation
+// withoutEnvelope returns a copy of the config encrypting with a key derived from the
+// password only, for data read without the store such as archives
+func (config *CryptoConfig) withoutEnvelope() *CryptoConfig {
+	configCopy := *config
+	configCopy.envelope = nil
+	return &configCopy
+}
+
+// encodeV2 encrypts using AES-GCM with Argon2id key derivation, see vaultcrypt.EncodeV2
 func encodeV2(value string, password string, config *CryptoConfig) (string, error) {
 	// Use defaults if config is nil
 	if config == nil {
 		config = DefaultCryptoConfig()
 	}
 
-	// Generate random salt
-	salt := make([]byte, config.SaltSize)
-	if _, err := io.ReadFull(cryptorand.Reader, salt); err != nil {
-		return "", fmt.Errorf("failed to generate salt: %w", err)
-	}
-
-	// Derive key using Argon2id
-	key := deriveKeyArgon2id(password, salt, config)
+	return encodePassword(value, password, config, vaultcrypt.EncodeV2)
+}
 
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+// encodeV3 encrypts using XChaCha20-Poly1305 with Argon2id key derivation, see vaultcrypt.EncodeV3
+func encodeV3(value string, password string, config *CryptoConfig) (string, error) {
+	// Use defaults if config is nil
+	if config == nil {
+		config = DefaultCryptoConfig()
 	}
 
-	// Create GCM
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
-	}
+	return encodePassword(value, password, config, vaultcrypt.EncodeV3)
+}
 
-	// Generate nonce
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(cryptorand.Reader, nonce); err != nil {
-		return "", fmt.Errorf("failed to generate nonce: %w", err)
+// encodePassword encrypts a value with a key derived from the password, measuring the key derivation
+func encodePassword(value string, password string, config *CryptoConfig, encodeFn func(string, string, vaultcrypt.Params) (string, error)) (string, error) {
+	start := time.Now()
+	encoded, err := encodeFn(value, password, config.params())
+	if err != nil {
+		return "", err
 	}
+	config.kdfRecord(KDF_OPERATION_ENCRYPT, time.Since(start))
 
-	// Encrypt
-	ciphertext := gcm.Seal(nonce, nonce, []byte(value), nil)
-
-	// Combine salt + ciphertext (which includes nonce + tag)
-	combined := append(salt, ciphertext...)
-
-	// Encode and add prefix
-	return ENCRYPTION_PREFIX_V2 + base64Encode(combined), nil
+	return encoded, nil
 }
 
 // deriveKeyArgon2id derives a key using Argon2id
@@ -187,11 +242,7 @@ func deriveKeyArgon2id(password string, salt []byte, config *CryptoConfig) []byt
 	if config == nil {
 		config = DefaultCryptoConfig()
 	}
-	return argon2.IDKey([]byte(password), salt,
-		uint32(config.Iterations),
-		uint32(config.Memory),
-		uint8(config.Parallelism),
-		uint32(config.KeyLength))
+	return vaultcrypt.DeriveKey(password, salt, config.Params())
 }
 
 // strongifyPassword Performs multiple calculations
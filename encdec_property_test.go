@@ -0,0 +1,146 @@
+package vaultstore
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode/utf8"
+)
+
+// propertyCryptoConfigs are the crypto configs the roundtrip properties are checked against.
+// The high security config is slow to derive keys with, so it is skipped in short mode.
+func propertyCryptoConfigs() map[string]*CryptoConfig {
+	configs := map[string]*CryptoConfig{
+		"default":     DefaultCryptoConfig(),
+		"lightweight": LightweightCryptoConfig(),
+	}
+
+	if !testing.Short() {
+		configs["high_security"] = HighSecurityCryptoConfig()
+	}
+
+	return configs
+}
+
+// randomUnicodeString generates strings mixing ASCII, multi-byte runes,
+// combining marks, emoji and the separators used by the v1 format
+type randomUnicodeString string
+
+func (randomUnicodeString) Generate(r *rand.Rand, size int) reflect.Value {
+	pools := []string{
+		"abcXYZ019 _:=+/",
+		"äöüßéñçø",
+		"密码中文日本語한국어",
+		"\u0301\u0308\u200d\ufeff",
+		"🔐🚀👩‍💻",
+		"\x00\t\r\n",
+	}
+
+	var builder strings.Builder
+	length := r.Intn(size*4 + 1)
+	for i := 0; i < length; i++ {
+		runes := []rune(pools[r.Intn(len(pools))])
+		builder.WriteRune(runes[r.Intn(len(runes))])
+	}
+	return reflect.ValueOf(randomUnicodeString(builder.String()))
+}
+
+func Test_EncodeDecode_Property_ArbitraryBytes(t *testing.T) {
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	for name, config := range propertyCryptoConfigs() {
+		roundtrip := func(value []byte) bool {
+			encoded, err := encode(context.Background(), string(value), password, config)
+			if err != nil {
+				return false
+			}
+			decoded, err := decode(context.Background(), encoded, password, config)
+			return err == nil && decoded == string(value)
+		}
+
+		if err := quick.Check(roundtrip, &quick.Config{MaxCount: 5}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+}
+
+func Test_EncodeDecode_Property_Unicode(t *testing.T) {
+	password := "pässwörd_密码_that_is_long_enough_🔐"
+
+	for name, config := range propertyCryptoConfigs() {
+		roundtrip := func(value randomUnicodeString) bool {
+			encoded, err := encode(context.Background(), string(value), password, config)
+			if err != nil {
+				return false
+			}
+			decoded, err := decode(context.Background(), encoded, password, config)
+			return err == nil && decoded == string(value) && utf8.ValidString(decoded) == utf8.ValidString(string(value))
+		}
+
+		if err := quick.Check(roundtrip, &quick.Config{MaxCount: 5}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+}
+
+func Test_EncodeDecode_Property_WrongPasswordFails(t *testing.T) {
+	config := LightweightCryptoConfig()
+
+	property := func(value []byte, suffix byte) bool {
+		password := "test_password_that_is_long_enough_for_security_32chars"
+		encoded, err := encode(context.Background(), string(value), password, config)
+		if err != nil {
+			return false
+		}
+		_, err = decode(context.Background(), encoded, password+string(rune('a'+suffix%26)), config)
+		return err != nil
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 5}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_DecodeV1_Property_LegacyRoundtrip(t *testing.T) {
+	roundtrip := func(value randomUnicodeString, password string) bool {
+		decoded, err := decode(context.Background(), encodeV1(string(value), password), password, nil)
+		return err == nil && decoded == string(value)
+	}
+
+	if err := quick.Check(roundtrip, &quick.Config{MaxCount: 50}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_EncodeDecode_Property_LargeValues(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-megabyte roundtrips in short mode")
+	}
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	r := rand.New(rand.NewSource(1))
+
+	for name, config := range propertyCryptoConfigs() {
+		for _, size := range []int{64 * 1024, 1024 * 1024, 4 * 1024 * 1024} {
+			value := make([]byte, size)
+			r.Read(value)
+
+			encoded, err := encode(context.Background(), string(value), password, config)
+			if err != nil {
+				t.Fatalf("%s/%d: Expected [err] to be nil received [%v]", name, size, err.Error())
+			}
+
+			decoded, err := decode(context.Background(), encoded, password, config)
+			if err != nil {
+				t.Fatalf("%s/%d: Expected [err] to be nil received [%v]", name, size, err.Error())
+			}
+
+			if decoded != string(value) {
+				t.Fatalf("%s/%d: Expected roundtrip identity", name, size)
+			}
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package vaultstore
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func Test_xorEncrypt(t *testing.T) {
 	str := xorEncrypt("input", "key")
@@ -43,12 +46,12 @@ func Test_xorEncryptDecryptLargeInput(t *testing.T) {
 func Test_decode(t *testing.T) {
 	test_val := "test_value"
 	test_pass := "test_password"
-	encoded_str, err := encode(test_val, test_pass, nil)
+	encoded_str, err := encode(context.Background(), test_val, test_pass, nil)
 	if err != nil {
 		t.Fatalf("encode() failed: %v", err)
 	}
 
-	str, err := decode(encoded_str, test_pass, nil)
+	str, err := decode(context.Background(), encoded_str, test_pass, nil)
 	if err != nil {
 		t.Fatalf("decode Failure [%v]", err.Error())
 	}
@@ -60,12 +63,12 @@ func Test_decode(t *testing.T) {
 func Test_encode(t *testing.T) {
 	test_val := "test_value"
 	test_pass := "test_password"
-	encoded_str, err := encode(test_val, test_pass, nil)
+	encoded_str, err := encode(context.Background(), test_val, test_pass, nil)
 	if err != nil {
 		t.Fatalf("encode() failed: %v", err)
 	}
 
-	str, err := decode(encoded_str, test_pass, nil)
+	str, err := decode(context.Background(), encoded_str, test_pass, nil)
 	if err != nil {
 		t.Fatalf("encode Failure [%v]", err.Error())
 	}
@@ -1,6 +1,7 @@
 package vaultstore
 
 import (
+	"context"
 	"strconv"
 	"strings"
 	"testing"
@@ -115,7 +116,7 @@ func Test_encode_UsesV2(t *testing.T) {
 	value := "test_value"
 	password := "test_password"
 
-	encoded, err := encode(value, password, nil)
+	encoded, err := encode(context.Background(), value, password, nil)
 	if err != nil {
 		t.Fatalf("encode() failed: %v", err)
 	}
@@ -133,7 +134,7 @@ func Test_decode_HandlesV2(t *testing.T) {
 	if err != nil {
 		t.Fatalf("encodeV2 failed: %v", err)
 	}
-	decoded, err := decode(encoded, password, nil)
+	decoded, err := decode(context.Background(), encoded, password, nil)
 	if err != nil {
 		t.Fatalf("decode() failed for v2: %v", err)
 	}
@@ -152,7 +153,7 @@ func Test_decode_BackwardCompatibilityV1(t *testing.T) {
 	legacyEncoded := encodeV1(value, password)
 
 	// decode() should handle v1 data
-	decoded, err := decode(legacyEncoded, password, nil)
+	decoded, err := decode(context.Background(), legacyEncoded, password, nil)
 	if err != nil {
 		t.Fatalf("decode() failed for v1 legacy data: %v", err)
 	}
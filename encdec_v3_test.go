@@ -0,0 +1,135 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Test v3 encryption/decryption roundtrip
+func Test_encodeV3_decodeV3_Roundtrip(t *testing.T) {
+	config := LightweightCryptoConfig()
+	config.Cipher = CIPHER_XCHACHA20_POLY1305
+
+	for _, value := range []string{"test_value", "", createRandomBlock(10000), "Hello, 世界! 🌍"} {
+		encoded, err := encode(context.Background(), value, "password", config)
+		if err != nil {
+			t.Fatalf("encode failed: %v", err)
+		}
+		if !strings.HasPrefix(encoded, ENCRYPTION_PREFIX_V3) {
+			t.Fatalf("Expected v3: prefix, got: %s", encoded[:10])
+		}
+
+		decoded, err := decode(context.Background(), encoded, "password", config)
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+
+		if decoded != value {
+			t.Fatalf("Roundtrip failed: expected %q, got %q", value, decoded)
+		}
+	}
+}
+
+func Test_decodeV3_WrongPassword(t *testing.T) {
+	config := LightweightCryptoConfig()
+	config.Cipher = CIPHER_XCHACHA20_POLY1305
+
+	encoded, err := encode(context.Background(), "secret", "password", config)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	if _, err := decode(context.Background(), encoded, "wrong_password", config); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", err)
+	}
+
+	if _, err := decode(context.Background(), ENCRYPTION_PREFIX_V3+base64Encode([]byte("short")), "password", config); err == nil {
+		t.Fatal("Expected an error for a too short value")
+	}
+}
+
+// The cipher only selects the format of new values, both formats stay readable
+func Test_decode_HandlesV2AndV3(t *testing.T) {
+	config := LightweightCryptoConfig()
+
+	v2, err := encode(context.Background(), "secret", "password", config)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	config.Cipher = CIPHER_XCHACHA20_POLY1305
+	v3, err := encode(context.Background(), "secret", "password", config)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	for _, encoded := range []string{v2, v3} {
+		decoded, err := decode(context.Background(), encoded, "password", config)
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		if decoded != "secret" {
+			t.Fatalf("Expected [secret] received [%v]", decoded)
+		}
+	}
+}
+
+func Test_Store_CipherXChaCha20Poly1305(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	config := LightweightCryptoConfig()
+	config.Cipher = CIPHER_XCHACHA20_POLY1305
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_v3",
+		VaultMetaTableName: "vault_meta_v3",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		CryptoConfig:       config,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.HasPrefix(record.GetValue(), ENCRYPTION_PREFIX_V3) {
+		t.Fatalf("Expected a v3 value received [%v]", record.GetValue()[:10])
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret" {
+		t.Fatalf("Expected [secret] received [%v]", value)
+	}
+
+	config.Cipher = "rot13"
+	_, err = NewStore(NewStoreOptions{
+		VaultTableName:     "vault_v3",
+		VaultMetaTableName: "vault_meta_v3",
+		DB:                 db,
+		CryptoConfig:       config,
+	})
+	if err == nil {
+		t.Fatal("Expected an error for an unsupported cipher")
+	}
+}
@@ -0,0 +1,460 @@
+package vaultstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	cryptorand "crypto/rand"
+
+	"github.com/dracory/vaultstore/vaultcrypt"
+	"github.com/samber/lo"
+)
+
+// ENCRYPTION_PREFIX_ENVELOPE marks values encrypted with a data key wrapped by the KeyProvider.
+// The full value is "env:<base64 wrapped data key>:<base64 salt + nonce + ciphertext>".
+const ENCRYPTION_PREFIX_ENVELOPE = "env:"
+
+// envelopeDataKeySize is the size in bytes of the random data key of each value
+const envelopeDataKeySize = 32
+
+var (
+	// ErrMasterKeyInvalid is returned by NewMasterKeyProvider for a master key that is not 32 bytes
+	ErrMasterKeyInvalid = errors.New("master key must be 32 bytes")
+	// ErrKeyProviderMissing is returned when reading an envelope encrypted value,
+	// or rewrapping, without a KeyProvider configured
+	ErrKeyProviderMissing = errors.New("value is envelope encrypted but no key provider is configured")
+)
+
+// KeyProvider wraps and unwraps the data keys of envelope encryption, e.g. with a master key
+// held in memory (see NewMasterKeyProvider) or with a key management service such as
+// AWS KMS (see the awskms package), keeping the key material out of the application
+type KeyProvider interface {
+	// WrapKey encrypts a data key
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key wrapped by WrapKey
+	UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// masterKeyProvider wraps data keys with AES-256-GCM under a master key held in memory
+type masterKeyProvider struct {
+	aead cipher.AEAD
+}
+
+var _ KeyProvider = (*masterKeyProvider)(nil)
+
+// NewMasterKeyProvider returns a KeyProvider wrapping the data keys with AES-256-GCM
+// under the master key
+//
+// Parameters:
+// - masterKey: The 32 bytes master key, e.g. from a secret manager
+//
+// Returns:
+// - KeyProvider: The key provider
+// - err: ErrMasterKeyInvalid if the master key is not 32 bytes
+func NewMasterKeyProvider(masterKey []byte) (KeyProvider, error) {
+	if len(masterKey) != 32 {
+		return nil, ErrMasterKeyInvalid
+	}
+
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &masterKeyProvider{aead: aead}, nil
+}
+
+// WrapKey encrypts the data key under the master key
+func (provider *masterKeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	nonce := make([]byte, provider.aead.NonceSize())
+	if _, err := io.ReadFull(cryptorand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return provider.aead.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+// UnwrapKey decrypts a data key wrapped under the master key
+func (provider *masterKeyProvider) UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	nonceSize := provider.aead.NonceSize()
+	if len(wrappedKey) < nonceSize {
+		return nil, ErrDecryptionFailed
+	}
+
+	dataKey, err := provider.aead.Open(nil, wrappedKey[:nonceSize], wrappedKey[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDecryptionFailed, err.Error())
+	}
+
+	return dataKey, nil
+}
+
+// envelopeKeyProviders are the key providers of a store
+type envelopeKeyProviders struct {
+	// current wraps the data keys of new values
+	current KeyProvider
+	// previous unwraps the data keys not yet rewrapped with the current provider (optional)
+	previous KeyProvider
+}
+
+// envelopeKeys holds the key providers of a store, swapped by EnvelopeRewrap
+type envelopeKeys struct {
+	providers atomic.Pointer[envelopeKeyProviders]
+}
+
+// newEnvelopeKeys returns the key holder, or nil if envelope encryption is disabled
+func newEnvelopeKeys(current KeyProvider, previous KeyProvider) *envelopeKeys {
+	if current == nil {
+		return nil
+	}
+
+	keys := &envelopeKeys{}
+	keys.providers.Store(&envelopeKeyProviders{current: current, previous: previous})
+	return keys
+}
+
+// unwrap decrypts the data key with the current provider, falling back to the previous one
+func (keys *envelopeKeys) unwrap(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	providers := keys.providers.Load()
+
+	dataKey, err := providers.current.UnwrapKey(ctx, wrappedKey)
+	if err != nil && providers.previous != nil {
+		return providers.previous.UnwrapKey(ctx, wrappedKey)
+	}
+
+	return dataKey, err
+}
+
+// envelopeValueKey derives the key of a value from its data key and a key derived
+// from the password with Argon2id and the salt of the value, so the password is still
+// required to read the value and guessing it costs a key derivation per attempt even
+// with the data key. With a pepper the password is peppered first, as for the values
+// encrypted without envelope.
+func envelopeValueKey(dataKey []byte, password string, salt []byte, config *CryptoConfig, operation string) ([]byte, error) {
+	params := config.params()
+	if len(params.Pepper) > 0 {
+		password = vaultcrypt.PepperPassword(password, params.Pepper)
+	}
+
+	start := time.Now()
+	passwordKey := vaultcrypt.DeriveKey(password, salt, params)
+	config.kdfRecord(operation, time.Since(start))
+
+	secret := make([]byte, 0, len(dataKey)+len(passwordKey))
+	secret = append(secret, dataKey...)
+	secret = append(secret, passwordKey...)
+
+	return hkdf.Key(sha256.New, secret, salt, "vaultstore envelope value", 32)
+}
+
+// envelopeAEAD returns the AES-256-GCM cipher of a value
+func envelopeAEAD(dataKey []byte, password string, salt []byte, config *CryptoConfig, operation string) (cipher.AEAD, error) {
+	key, err := envelopeValueKey(dataKey, password, salt, config, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encodeEnvelope encrypts the value with a new random data key, wrapped by the current key
+// provider. The ciphertext holds the salt of the password key, the nonce and the sealed value.
+func encodeEnvelope(ctx context.Context, value string, password string, config *CryptoConfig) (string, error) {
+	dataKey := make([]byte, envelopeDataKeySize)
+	if _, err := io.ReadFull(cryptorand.Reader, dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	wrappedKey, err := config.envelope.providers.Load().current.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	salt := make([]byte, config.SaltSize)
+	if _, err := io.ReadFull(cryptorand.Reader, salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := envelopeAEAD(dataKey, password, salt, config, KDF_OPERATION_ENCRYPT)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(cryptorand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ciphertext := append(salt, nonce...)
+	ciphertext = gcm.Seal(ciphertext, nonce, []byte(value), nil)
+
+	return ENCRYPTION_PREFIX_ENVELOPE + base64Encode(wrappedKey) + ":" + base64Encode(ciphertext), nil
+}
+
+// decodeEnvelope decrypts a value encrypted by encodeEnvelope
+func decodeEnvelope(ctx context.Context, value string, password string, config *CryptoConfig) (string, error) {
+	keys := config.envelope
+	if keys == nil {
+		return "", ErrKeyProviderMissing
+	}
+
+	wrappedKey, ciphertext, err := envelopeParse(value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ciphertext) < config.SaltSize {
+		return "", errors.New("invalid ciphertext length")
+	}
+	salt, ciphertext := ciphertext[:config.SaltSize], ciphertext[config.SaltSize:]
+
+	dataKey, err := keys.unwrap(ctx, wrappedKey)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := envelopeAEAD(dataKey, password, salt, config, KDF_OPERATION_DECRYPT)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return "", errors.New("invalid ciphertext length")
+	}
+
+	plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrDecryptionFailed, err.Error())
+	}
+
+	return string(plaintext), nil
+}
+
+// envelopeParse splits an envelope encrypted value into its wrapped data key and ciphertext
+func envelopeParse(value string) (wrappedKey []byte, ciphertext []byte, err error) {
+	encodedKey, encodedCiphertext, ok := strings.Cut(strings.TrimPrefix(value, ENCRYPTION_PREFIX_ENVELOPE), ":")
+	if !ok {
+		return nil, nil, errors.New("invalid envelope value")
+	}
+
+	wrappedKey, err = base64Decode(encodedKey)
+	if err != nil {
+		return nil, nil, errors.New("base64 decode: " + err.Error())
+	}
+
+	ciphertext, err = base64Decode(encodedCiphertext)
+	if err != nil {
+		return nil, nil, errors.New("base64 decode: " + err.Error())
+	}
+
+	return wrappedKey, ciphertext, nil
+}
+
+// envelopeRewrap wraps the data key of the value with the provider, the ciphertext is kept.
+// Returns false if the data key is already wrapped by the provider.
+func envelopeRewrap(ctx context.Context, value string, keys *envelopeKeys, provider KeyProvider) (string, bool, error) {
+	wrappedKey, ciphertext, err := envelopeParse(value)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Already rewrapped, e.g. by an interrupted run
+	if _, err := provider.UnwrapKey(ctx, wrappedKey); err == nil {
+		return value, false, nil
+	}
+
+	providers := keys.providers.Load()
+	if providers.previous == nil {
+		return "", false, ErrKeyProviderMissing
+	}
+
+	dataKey, err := providers.previous.UnwrapKey(ctx, wrappedKey)
+	if err != nil {
+		return "", false, err
+	}
+
+	rewrappedKey, err := provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return ENCRYPTION_PREFIX_ENVELOPE + base64Encode(rewrappedKey) + ":" + base64Encode(ciphertext), true, nil
+}
+
+// EnvelopeRewrap rotates the key provider (master key) of envelope encryption: the data keys
+// of all envelope encrypted values are rewrapped with the new provider, without decrypting
+// the values. New values use the new provider right away, and values not yet rewrapped stay
+// readable with the previous one. If the rewrap is interrupted, restart the store with the new
+// KeyProvider and the old one as KeyProviderPrevious, and run it again.
+//
+// The records of all namespaces of the vault table of the context are rewrapped, and the meta
+// values of all tables. Run it again with the WithTableSuffix context of each suffixed table:
+// the previous provider is kept for reading until the store is restarted without it.
+//
+// Parameters:
+// - ctx: The context
+// - provider: The new key provider
+//
+// Returns:
+// - count: The number of rewrapped values (records and meta values such as token versions)
+// - err: ErrKeyProviderMissing if envelope encryption is disabled, or an error if something went wrong
+func (store *storeImplementation) EnvelopeRewrap(ctx context.Context, provider KeyProvider) (int64, error) {
+	ctx, span := store.traceStart(ctx, "EnvelopeRewrap")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "EnvelopeRewrap", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	keys := store.cryptoConfig.envelope
+	if keys == nil || provider == nil {
+		return 0, ErrKeyProviderMissing
+	}
+
+	current := keys.providers.Load()
+	if current.current != provider {
+		keys.providers.Store(&envelopeKeyProviders{current: provider, previous: current.current})
+	}
+
+	// The records of the other namespaces are wrapped by the same provider
+	ctx = withNamespacesAll(ctx)
+
+	count, err := store.envelopeRewrapRecords(ctx, keys, provider)
+	if err != nil {
+		return count, err
+	}
+
+	metaCount, err := store.envelopeRewrapMeta(ctx, keys, provider)
+	count += metaCount
+	if err != nil {
+		return count, err
+	}
+
+	// The previous provider stays, the records of other suffixed tables may still use it
+	return count, nil
+}
+
+// envelopeRewrapRecords rewraps the data keys of the record values, soft deleted included
+func (store *storeImplementation) envelopeRewrapRecords(ctx context.Context, keys *envelopeKeys, provider KeyProvider) (int64, error) {
+	count := int64(0)
+	lastID := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		var gormRecords []gormVaultRecord
+		err := store.vaultDB(ctx).
+			Select(COLUMN_ID, COLUMN_VAULT_VALUE).
+			Where(COLUMN_ID+" > ?", lastID).
+			Order(COLUMN_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&gormRecords).Error
+		if err != nil {
+			return count, err
+		}
+
+		if len(gormRecords) == 0 {
+			return count, nil
+		}
+		lastID = gormRecords[len(gormRecords)-1].ID
+
+		if err := store.valueChunksResolve(ctx, gormRecords); err != nil {
+			return count, err
+		}
+
+		envelopeRecords := lo.Filter(gormRecords, func(gormRecord gormVaultRecord, _ int) bool {
+			return strings.HasPrefix(gormRecord.Value, ENCRYPTION_PREFIX_ENVELOPE)
+		})
+
+		for _, gormRecord := range envelopeRecords {
+			rewrapped, changed, err := envelopeRewrap(ctx, gormRecord.Value, keys, provider)
+			if err != nil {
+				return count, fmt.Errorf("record %s: %w", gormRecord.ID, err)
+			}
+
+			if !changed {
+				continue
+			}
+
+			// A concurrent writer replacing the value also replaces its data key
+			swapped, err := store.recordValueSwap(ctx, gormRecord.ID, gormRecord.Value, rewrapped)
+			if err != nil {
+				return count, err
+			}
+
+			if swapped {
+				count++
+			}
+		}
+	}
+}
+
+// envelopeRewrapMeta rewraps the data keys of the values kept in the meta table,
+// such as token versions and appended chunks
+func (store *storeImplementation) envelopeRewrapMeta(ctx context.Context, keys *envelopeKeys, provider KeyProvider) (int64, error) {
+	count := int64(0)
+	lastID := uint(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		var metas []gormVaultMeta
+		err := store.metaDB(ctx).
+			Where("id > ? AND "+COLUMN_META_VALUE+" LIKE ?", lastID, ENCRYPTION_PREFIX_ENVELOPE+"%").
+			Order("id ASC").
+			Limit(maxRecordsInMemory).
+			Find(&metas).Error
+		if err != nil {
+			return count, err
+		}
+
+		if len(metas) == 0 {
+			return count, nil
+		}
+		lastID = metas[len(metas)-1].ID
+
+		for _, meta := range metas {
+			rewrapped, changed, err := envelopeRewrap(ctx, meta.Value, keys, provider)
+			if err != nil {
+				return count, fmt.Errorf("meta %d: %w", meta.ID, err)
+			}
+
+			if !changed {
+				continue
+			}
+
+			result := store.metaDB(ctx).
+				Where("id = ? AND "+COLUMN_META_VALUE+" = ?", meta.ID, meta.Value).
+				Update(COLUMN_META_VALUE, rewrapped)
+			if result.Error != nil {
+				return count, result.Error
+			}
+
+			count += result.RowsAffected
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package vaultstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_NewMasterKeyProvider(t *testing.T) {
+	if _, err := NewMasterKeyProvider([]byte("short")); !errors.Is(err, ErrMasterKeyInvalid) {
+		t.Fatalf("Expected ErrMasterKeyInvalid received [%v]", err)
+	}
+
+	provider, err := NewMasterKeyProvider(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatalf("NewMasterKeyProvider: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	dataKey := bytes.Repeat([]byte("d"), 32)
+
+	wrappedKey, err := provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		t.Fatalf("WrapKey: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if bytes.Contains(wrappedKey, dataKey) {
+		t.Fatal("Expected the data key to be encrypted")
+	}
+
+	unwrappedKey, err := provider.UnwrapKey(ctx, wrappedKey)
+	if err != nil {
+		t.Fatalf("UnwrapKey: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !bytes.Equal(unwrappedKey, dataKey) {
+		t.Fatal("Expected the unwrapped key to be the data key")
+	}
+
+	other, _ := NewMasterKeyProvider(bytes.Repeat([]byte("o"), 32))
+	if _, err := other.UnwrapKey(ctx, wrappedKey); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected ErrDecryptionFailed received [%v]", err)
+	}
+}
+
+func Test_Store_Envelope(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	oldMasterKey := bytes.Repeat([]byte("a"), 32)
+	newMasterKey := bytes.Repeat([]byte("b"), 32)
+
+	newStore := func(options NewStoreOptions) StoreInterface {
+		options.VaultTableName = "vault_envelope"
+		options.VaultMetaTableName = "vault_meta"
+		options.DB = db
+		options.AutomigrateEnabled = true
+
+		store, err := NewStore(options)
+		if err != nil {
+			t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+		}
+		return store
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	store := newStore(NewStoreOptions{MasterKey: oldMasterKey})
+
+	token, err := store.TokenCreate(ctx, "envelope value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.HasPrefix(record.GetValue(), ENCRYPTION_PREFIX_ENVELOPE) {
+		t.Fatalf("Expected an envelope encrypted value received [%v]", record.GetValue())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "envelope value" {
+		t.Fatalf("Expected [envelope value] received [%v]", value)
+	}
+
+	if _, err := store.TokenRead(ctx, token, password+"x"); err == nil {
+		t.Fatal("Expected the password to still be required")
+	}
+
+	// Without the key provider the value cannot be read
+	plainStore := newStore(NewStoreOptions{})
+	if _, err := plainStore.TokenRead(ctx, token, password); !errors.Is(err, ErrKeyProviderMissing) {
+		t.Fatalf("Expected ErrKeyProviderMissing received [%v]", err)
+	}
+
+	// Rotation rewraps the data key, the ciphertext is kept
+	newProvider, _ := NewMasterKeyProvider(newMasterKey)
+	count, err := store.EnvelopeRewrap(ctx, newProvider)
+	if err != nil {
+		t.Fatalf("EnvelopeRewrap: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected 1 rewrapped value received [%v]", count)
+	}
+
+	rewrapped, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, oldCiphertext, _ := envelopeParse(record.GetValue())
+	_, newCiphertext, _ := envelopeParse(rewrapped.GetValue())
+	if !bytes.Equal(oldCiphertext, newCiphertext) {
+		t.Fatal("Expected the ciphertext to be kept")
+	}
+
+	// Rewrapping again is a no-op
+	count, err = store.EnvelopeRewrap(ctx, newProvider)
+	if err != nil || count != 0 {
+		t.Fatalf("Expected no rewrapped value received [%v] [%v]", count, err)
+	}
+
+	rotatedStore := newStore(NewStoreOptions{KeyProvider: newProvider})
+	value, err = rotatedStore.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "envelope value" {
+		t.Fatalf("Expected [envelope value] received [%v]", value)
+	}
+
+	oldStore := newStore(NewStoreOptions{MasterKey: oldMasterKey})
+	if _, err := oldStore.TokenRead(ctx, token, password); err == nil {
+		t.Fatal("Expected the old master key to no longer unwrap the data key")
+	}
+}
+
+func Test_Store_EnvelopeRewrapScope(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_envelope",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		MasterKey:          bytes.Repeat([]byte("a"), 32),
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.AutoMigrateTableSuffix("_tenant"); err != nil {
+		t.Fatalf("AutoMigrateTableSuffix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	namespaceCtx := WithNamespace(ctx, "tenant")
+	suffixCtx := WithTableSuffix(ctx, "_tenant")
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	namespaceToken, err := store.TokenCreate(namespaceCtx, "namespace value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	suffixToken, err := store.TokenCreate(suffixCtx, "suffix value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	newProvider, _ := NewMasterKeyProvider(bytes.Repeat([]byte("b"), 32))
+
+	// The records of every namespace of the table are rewrapped
+	count, err := store.EnvelopeRewrap(ctx, newProvider)
+	if err != nil || count != 1 {
+		t.Fatalf("EnvelopeRewrap: Expected 1 rewrapped value received [%v] [%v]", count, err)
+	}
+
+	if value, err := store.TokenRead(namespaceCtx, namespaceToken, password); err != nil || value != "namespace value" {
+		t.Fatalf("TokenRead: Expected [namespace value] received [%v] [%v]", value, err)
+	}
+
+	// The suffixed table is not rewrapped yet, its records stay readable
+	if value, err := store.TokenRead(suffixCtx, suffixToken, password); err != nil || value != "suffix value" {
+		t.Fatalf("TokenRead: Expected [suffix value] received [%v] [%v]", value, err)
+	}
+
+	count, err = store.EnvelopeRewrap(suffixCtx, newProvider)
+	if err != nil || count != 1 {
+		t.Fatalf("EnvelopeRewrap: Expected 1 rewrapped value received [%v] [%v]", count, err)
+	}
+}
+
+func Test_Store_EnvelopePepper(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	masterKey := bytes.Repeat([]byte("a"), 32)
+
+	newStore := func(pepper []byte) StoreInterface {
+		store, err := NewStore(NewStoreOptions{
+			VaultTableName:     "vault_envelope_pepper",
+			VaultMetaTableName: "vault_meta",
+			DB:                 db,
+			AutomigrateEnabled: true,
+			MasterKey:          masterKey,
+			Pepper:             pepper,
+		})
+		if err != nil {
+			t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+		}
+		return store
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	store := newStore(bytes.Repeat([]byte("p"), 32))
+
+	token, err := store.TokenCreate(ctx, "peppered value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "peppered value" {
+		t.Fatalf("Expected [peppered value] received [%v]", value)
+	}
+
+	// The master key and password alone do not decrypt the value
+	if _, err := newStore(nil).TokenRead(ctx, token, password); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", err)
+	}
+}
+
+// contextKeyProvider records the request ID of the contexts it is called with
+type contextKeyProvider struct {
+	KeyProvider
+	requestIDs []any
+}
+
+type requestIDContextKey struct{}
+
+func (provider *contextKeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	provider.requestIDs = append(provider.requestIDs, ctx.Value(requestIDContextKey{}))
+	return provider.KeyProvider.WrapKey(ctx, dataKey)
+}
+
+func (provider *contextKeyProvider) UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	provider.requestIDs = append(provider.requestIDs, ctx.Value(requestIDContextKey{}))
+	return provider.KeyProvider.UnwrapKey(ctx, wrappedKey)
+}
+
+func Test_Store_EnvelopeContext(t *testing.T) {
+	masterKeyProvider, err := NewMasterKeyProvider(bytes.Repeat([]byte("a"), 32))
+	if err != nil {
+		t.Fatalf("NewMasterKeyProvider: Expected [err] to be nil received [%v]", err.Error())
+	}
+	provider := &contextKeyProvider{KeyProvider: masterKeyProvider}
+
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_envelope_context",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		KeyProvider:        provider,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.WithValue(context.Background(), requestIDContextKey{}, "request-1")
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "envelope value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenRead(ctx, token, "wrong_password_that_is_long_enough_for_security_32"); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", err)
+	}
+
+	// The key provider receives the context of the caller, e.g. for KMS deadlines and tracing
+	if len(provider.requestIDs) != 2 || provider.requestIDs[0] != "request-1" || provider.requestIDs[1] != "request-1" {
+		t.Fatalf("Expected the key provider to receive the caller context received %v", provider.requestIDs)
+	}
+}
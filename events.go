@@ -0,0 +1,66 @@
+package vaultstore
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies the kind of event emitted by the store
+type EventType string
+
+// Event types emitted to the registered event hooks
+const (
+	EVENT_TYPE_QUOTA_RECORD_COUNT      EventType = "quota.record_count"
+	EVENT_TYPE_QUOTA_STORAGE_BYTES     EventType = "quota.storage_bytes"
+	EVENT_TYPE_QUOTA_EXPIRED_UNCLEANED EventType = "quota.expired_uncleaned"
+
+	EVENT_TYPE_BREAK_GLASS_GRANTED EventType = "break_glass.granted"
+	EVENT_TYPE_BREAK_GLASS_DENIED  EventType = "break_glass.denied"
+	EVENT_TYPE_BREAK_GLASS_REVOKED EventType = "break_glass.revoked"
+	EVENT_TYPE_BREAK_GLASS_ACCESS  EventType = "break_glass.access"
+
+	EVENT_TYPE_TOKEN_CHECKED_OUT EventType = "token.checked_out"
+	EVENT_TYPE_TOKEN_CHECKED_IN  EventType = "token.checked_in"
+
+	EVENT_TYPE_SCHEDULER_JOB_FAILED EventType = "scheduler.job_failed"
+)
+
+// Event describes something that happened inside the store.
+// Events never carry secret values or passwords.
+type Event struct {
+	// Type is the kind of event
+	Type EventType
+	// Token is the token the event relates to (empty for store-wide events)
+	Token string
+	// TokenRedacted is the redacted identifier of Token, safe to log (see Redact)
+	TokenRedacted string
+	// Details holds additional non-sensitive information about the event
+	Details map[string]string
+	// OccurredAt is the UTC time the event was emitted
+	OccurredAt time.Time
+}
+
+// EventHook receives events emitted by the store.
+// Hooks are called synchronously, so they should return quickly.
+type EventHook func(ctx context.Context, event Event)
+
+// emitEvent sends the event to all registered hooks
+func (store *storeImplementation) emitEvent(ctx context.Context, eventType EventType, token string, details map[string]string) {
+	if len(store.eventHooks) == 0 {
+		return
+	}
+
+	event := Event{
+		Type:          eventType,
+		Token:         token,
+		TokenRedacted: store.redactor.Redact(token),
+		Details:       details,
+		OccurredAt:    time.Now().UTC(),
+	}
+
+	for _, hook := range store.eventHooks {
+		if hook != nil {
+			hook(ctx, event)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Command sessionstore serves the example session store, see the sessions package.
+//
+// Usage:
+//
+//	VAULTSTORE_PASSWORD=... go run ./examples/cmd/sessionstore -addr :8080
+//
+// The database is SQLite in memory unless configured with VAULTSTORE_DRIVER and VAULTSTORE_DSN.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dracory/vaultstore"
+	"github.com/dracory/vaultstore/examples/internal/exampledb"
+	"github.com/dracory/vaultstore/examples/sessions"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "The address to listen on")
+	idleTimeout := flag.Duration("idle-timeout", 30*time.Minute, "The time after which an unused session expires")
+	flag.Parse()
+
+	password := os.Getenv("VAULTSTORE_PASSWORD")
+	if password == "" {
+		log.Fatal("VAULTSTORE_PASSWORD is required")
+	}
+
+	db, driverName, err := exampledb.OpenFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err := vaultstore.NewStore(vaultstore.NewStoreOptions{
+		VaultTableName:     "session_vault",
+		VaultMetaTableName: "session_vault_meta",
+		DB:                 db,
+		DbDriverName:       driverName,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	service := sessions.NewService(store, password, *idleTimeout)
+
+	// Delete the expired sessions every minute
+	go func() {
+		for range time.Tick(time.Minute) {
+			if _, err := service.ExpiredDelete(context.Background()); err != nil {
+				log.Println("deleting expired sessions:", err)
+			}
+		}
+	}()
+
+	log.Println("listening on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, service.Handler()))
+}
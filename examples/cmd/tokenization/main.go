@@ -0,0 +1,58 @@
+// Command tokenization serves the example tokenization service, see the tokenization package.
+//
+// Usage:
+//
+//	VAULTSTORE_PASSWORD=... go run ./examples/cmd/tokenization -addr :8081
+//
+// The callers are checkout (tokenizer), payments (detokenizer) and ops (admin), named
+// by the X-Caller header. The database is SQLite in memory unless configured with
+// VAULTSTORE_DRIVER and VAULTSTORE_DSN.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/dracory/vaultstore"
+	"github.com/dracory/vaultstore/examples/internal/exampledb"
+	"github.com/dracory/vaultstore/examples/tokenization"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "The address to listen on")
+	flag.Parse()
+
+	password := os.Getenv("VAULTSTORE_PASSWORD")
+	if password == "" {
+		log.Fatal("VAULTSTORE_PASSWORD is required")
+	}
+
+	db, driverName, err := exampledb.OpenFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err := vaultstore.NewStore(vaultstore.NewStoreOptions{
+		VaultTableName:     "tokenization_vault",
+		VaultMetaTableName: "tokenization_vault_meta",
+		DB:                 db,
+		DbDriverName:       driverName,
+		AutomigrateEnabled: true,
+		OperationGuard: tokenization.Guard(map[string]string{
+			"checkout": tokenization.ROLE_TOKENIZER,
+			"payments": tokenization.ROLE_DETOKENIZER,
+			"ops":      tokenization.ROLE_ADMIN,
+		}),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	service := tokenization.NewService(store, password)
+
+	log.Println("listening on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, service.Handler()))
+}
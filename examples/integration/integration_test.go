@@ -0,0 +1,259 @@
+//go:build integration
+
+// Package integration runs the examples against real databases, started with
+// examples/docker-compose.yml, see "task test:integration".
+package integration
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dracory/vaultstore"
+	"github.com/dracory/vaultstore/examples/internal/exampledb"
+	"github.com/dracory/vaultstore/examples/sessions"
+	"github.com/dracory/vaultstore/examples/tokenization"
+)
+
+const password = "integration_password_long_enough_32chars"
+
+// databases are the databases to test, by driver, with the environment variable of their DSN
+var databases = map[string]string{
+	"postgres": "VAULTSTORE_TEST_POSTGRES_DSN",
+	"mysql":    "VAULTSTORE_TEST_MYSQL_DSN",
+}
+
+// forEachDatabase runs the test against each database whose DSN is set
+func forEachDatabase(t *testing.T, test func(t *testing.T, db *sql.DB, driverName string)) {
+	for driver, env := range databases {
+		t.Run(driver, func(t *testing.T) {
+			dsn := os.Getenv(env)
+			if dsn == "" {
+				t.Skipf("%s is not set", env)
+			}
+
+			db, driverName, err := exampledb.Open(driver, dsn)
+			if err != nil {
+				t.Fatalf("Open: Expected [err] to be nil received [%v]", err.Error())
+			}
+			t.Cleanup(func() { _ = db.Close() })
+
+			test(t, db, driverName)
+		})
+	}
+}
+
+// newStore creates a store on tables unique to the test, dropped at its end
+func newStore(t *testing.T, db *sql.DB, driverName string, guard vaultstore.OperationGuard) vaultstore.StoreInterface {
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	vaultTable := "it_vault_" + suffix
+	metaTable := "it_vault_meta_" + suffix
+
+	t.Cleanup(func() {
+		_, _ = db.Exec("DROP TABLE IF EXISTS " + vaultTable)
+		_, _ = db.Exec("DROP TABLE IF EXISTS " + metaTable)
+	})
+
+	store, err := vaultstore.NewStore(vaultstore.NewStoreOptions{
+		VaultTableName:     vaultTable,
+		VaultMetaTableName: metaTable,
+		DB:                 db,
+		DbDriverName:       driverName,
+		AutomigrateEnabled: true,
+		OperationGuard:     guard,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	return store
+}
+
+func TestSessions(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, driverName string) {
+		ctx := context.Background()
+		service := sessions.NewService(newStore(t, db, driverName, nil), password, 2*time.Second)
+
+		sessionID, err := service.Create(ctx, map[string]string{"user_id": "42"})
+		if err != nil {
+			t.Fatalf("Create: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		values, err := service.Get(ctx, sessionID)
+		if err != nil {
+			t.Fatalf("Get: Expected [err] to be nil received [%v]", err.Error())
+		}
+		if values["user_id"] != "42" {
+			t.Fatalf("Get: Expected user_id [42] received [%v]", values["user_id"])
+		}
+
+		// The session expires once idle for longer than the timeout
+		time.Sleep(3 * time.Second)
+
+		if _, err := service.Get(ctx, sessionID); !errors.Is(err, sessions.ErrSessionNotFound) {
+			t.Fatalf("Get: Expected [ErrSessionNotFound] received [%v]", err)
+		}
+
+		count, err := service.ExpiredDelete(ctx)
+		if err != nil {
+			t.Fatalf("ExpiredDelete: Expected [err] to be nil received [%v]", err.Error())
+		}
+		if count != 1 {
+			t.Fatalf("ExpiredDelete: Expected [1] received [%v]", count)
+		}
+	})
+}
+
+func TestSessionsHTTP(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, driverName string) {
+		service := sessions.NewService(newStore(t, db, driverName, nil), password, time.Minute)
+		server := httptest.NewServer(service.Handler())
+		defer server.Close()
+
+		var created map[string]string
+		response := doJSON(t, http.MethodPost, server.URL+"/sessions", "", map[string]string{"user_id": "42"}, &created)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("POST /sessions: Expected [201] received [%v]", response.StatusCode)
+		}
+
+		var values map[string]string
+		response = doJSON(t, http.MethodGet, server.URL+"/sessions/"+created["session_id"], "", nil, &values)
+		if response.StatusCode != http.StatusOK || values["user_id"] != "42" {
+			t.Fatalf("GET /sessions: Expected [200] with user_id [42] received [%v] %v", response.StatusCode, values)
+		}
+
+		response = doJSON(t, http.MethodDelete, server.URL+"/sessions/"+created["session_id"], "", nil, nil)
+		if response.StatusCode != http.StatusNoContent {
+			t.Fatalf("DELETE /sessions: Expected [204] received [%v]", response.StatusCode)
+		}
+
+		response = doJSON(t, http.MethodGet, server.URL+"/sessions/"+created["session_id"], "", nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Fatalf("GET /sessions: Expected [404] received [%v]", response.StatusCode)
+		}
+	})
+}
+
+func TestTokenization(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, driverName string) {
+		guard := tokenization.Guard(map[string]string{
+			"checkout": tokenization.ROLE_TOKENIZER,
+			"payments": tokenization.ROLE_DETOKENIZER,
+			"ops":      tokenization.ROLE_ADMIN,
+		})
+		service := tokenization.NewService(newStore(t, db, driverName, guard), password)
+
+		checkout := tokenization.WithCaller(context.Background(), "checkout")
+		payments := tokenization.WithCaller(context.Background(), "payments")
+		ops := tokenization.WithCaller(context.Background(), "ops")
+
+		tokens := []string{}
+		for i := 0; i < 3; i++ {
+			token, err := service.Tokenize(checkout, fmt.Sprintf("4111-1111-1111-111%d", i))
+			if err != nil {
+				t.Fatalf("Tokenize: Expected [err] to be nil received [%v]", err.Error())
+			}
+			tokens = append(tokens, token)
+		}
+
+		// Identity: only the callers whose role allows it read the values
+		if _, err := service.Detokenize(checkout, tokens[0]); !errors.Is(err, vaultstore.ErrOperationDenied) {
+			t.Fatalf("Detokenize: Expected [ErrOperationDenied] for the tokenizer received [%v]", err)
+		}
+
+		unknown := tokenization.WithCaller(context.Background(), "unknown")
+		if _, err := service.Tokenize(unknown, "value"); !errors.Is(err, vaultstore.ErrOperationDenied) {
+			t.Fatalf("Tokenize: Expected [ErrOperationDenied] for an unknown caller received [%v]", err)
+		}
+
+		// Rekey: the values stay readable with the new password
+		if _, err := service.Rekey(payments, "rotated_password_long_enough_32_chars"); !errors.Is(err, vaultstore.ErrOperationDenied) {
+			t.Fatalf("Rekey: Expected [ErrOperationDenied] for the detokenizer received [%v]", err)
+		}
+
+		count, err := service.Rekey(ops, "rotated_password_long_enough_32_chars")
+		if err != nil {
+			t.Fatalf("Rekey: Expected [err] to be nil received [%v]", err.Error())
+		}
+		if count != len(tokens) {
+			t.Fatalf("Rekey: Expected [%d] received [%v]", len(tokens), count)
+		}
+
+		for i, token := range tokens {
+			value, err := service.Detokenize(payments, token)
+			if err != nil {
+				t.Fatalf("Detokenize: Expected [err] to be nil received [%v]", err.Error())
+			}
+			if expected := fmt.Sprintf("4111-1111-1111-111%d", i); value != expected {
+				t.Fatalf("Detokenize: Expected [%s] received [%v]", expected, value)
+			}
+		}
+	})
+}
+
+func TestTokenizationHTTP(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, driverName string) {
+		guard := tokenization.Guard(map[string]string{
+			"checkout": tokenization.ROLE_TOKENIZER,
+			"payments": tokenization.ROLE_DETOKENIZER,
+		})
+		service := tokenization.NewService(newStore(t, db, driverName, guard), password)
+		server := httptest.NewServer(service.Handler())
+		defer server.Close()
+
+		var tokenized map[string]string
+		response := doJSON(t, http.MethodPost, server.URL+"/tokenize", "checkout", map[string]string{"value": "4111-1111-1111-1111"}, &tokenized)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("POST /tokenize: Expected [201] received [%v]", response.StatusCode)
+		}
+
+		response = doJSON(t, http.MethodPost, server.URL+"/detokenize", "checkout", map[string]string{"token": tokenized["token"]}, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Fatalf("POST /detokenize: Expected [403] for the tokenizer received [%v]", response.StatusCode)
+		}
+
+		var detokenized map[string]string
+		response = doJSON(t, http.MethodPost, server.URL+"/detokenize", "payments", map[string]string{"token": tokenized["token"]}, &detokenized)
+		if response.StatusCode != http.StatusOK || detokenized["value"] != "4111-1111-1111-1111" {
+			t.Fatalf("POST /detokenize: Expected [200] with the value received [%v] %v", response.StatusCode, detokenized)
+		}
+	})
+}
+
+// doJSON sends the request with the JSON body, if any, and decodes the JSON response into out, if any
+func doJSON(t *testing.T, method string, url string, caller string, body any, out any) *http.Response {
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatalf("Encode: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	request, err := http.NewRequest(method, url, &reader)
+	if err != nil {
+		t.Fatalf("NewRequest: Expected [err] to be nil received [%v]", err.Error())
+	}
+	request.Header.Set(tokenization.HEADER_CALLER, caller)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Do: Expected [err] to be nil received [%v]", err.Error())
+	}
+	defer response.Body.Close()
+
+	if out != nil && response.StatusCode < 300 {
+		if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+			t.Fatalf("Decode: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	return response
+}
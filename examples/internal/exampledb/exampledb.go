@@ -0,0 +1,55 @@
+// Package exampledb opens the database of the examples, SQLite in memory unless
+// configured with the environment variables
+package exampledb
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "github.com/glebarez/sqlite"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// ENV_DRIVER is the environment variable naming the database: sqlite, postgres or mysql
+const ENV_DRIVER = "VAULTSTORE_DRIVER"
+
+// ENV_DSN is the environment variable holding the connection string of the database
+const ENV_DSN = "VAULTSTORE_DSN"
+
+// Open opens the database. The driver is sqlite (default), postgres or mysql,
+// an empty DSN opens SQLite in memory.
+//
+// Returns:
+// - db: The database
+// - driverName: The driver name to set in vaultstore.NewStoreOptions.DbDriverName
+// - err: An error if something went wrong
+func Open(driver string, dsn string) (db *sql.DB, driverName string, err error) {
+	switch driver {
+	case "", "sqlite":
+		if dsn == "" {
+			dsn = ":memory:?parseTime=true"
+		}
+		db, err = sql.Open("sqlite", dsn)
+		if err != nil {
+			return nil, "", err
+		}
+		// Each connection to an in memory database is a new, empty database
+		db.SetMaxOpenConns(1)
+		return db, "sqlite", nil
+	case "postgres":
+		db, err = sql.Open("pgx", dsn)
+		return db, "postgres", err
+	case "mysql":
+		db, err = sql.Open("mysql", dsn)
+		return db, "mysql", err
+	default:
+		return nil, "", fmt.Errorf("unsupported driver %q, use sqlite, postgres or mysql", driver)
+	}
+}
+
+// OpenFromEnv opens the database configured with ENV_DRIVER and ENV_DSN
+func OpenFromEnv() (db *sql.DB, driverName string, err error) {
+	return Open(os.Getenv(ENV_DRIVER), os.Getenv(ENV_DSN))
+}
@@ -0,0 +1,128 @@
+// Package sessions is an example session store built on vaultstore. Each session is
+// a map token expiring after the idle timeout, renewed on every read.
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dracory/vaultstore"
+)
+
+// ErrSessionNotFound is returned for a session that does not exist or has expired
+var ErrSessionNotFound = errors.New("session not found")
+
+// Service stores the sessions
+type Service struct {
+	store       vaultstore.StoreInterface
+	password    string
+	idleTimeout time.Duration
+}
+
+// NewService returns the session service
+//
+// Parameters:
+// - store: The vault store holding the sessions
+// - password: The password encrypting the sessions
+// - idleTimeout: The time after which an unused session expires
+func NewService(store vaultstore.StoreInterface, password string, idleTimeout time.Duration) *Service {
+	return &Service{
+		store:       store,
+		password:    password,
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Create creates a session holding the values and returns its ID
+func (service *Service) Create(ctx context.Context, values map[string]string) (string, error) {
+	return service.store.TokenCreateMap(ctx, values, service.password, vaultstore.TokenCreateOptions{
+		ExpiresAt: time.Now().Add(service.idleTimeout),
+	})
+}
+
+// Get returns the values of a session and extends its expiration
+func (service *Service) Get(ctx context.Context, sessionID string) (map[string]string, error) {
+	values, err := service.store.TokenReadMap(ctx, sessionID, service.password)
+	if errors.Is(err, vaultstore.ErrTokenNotFound) || errors.Is(err, vaultstore.ErrTokenExpired) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.store.TokenRenew(ctx, sessionID, time.Now().Add(service.idleTimeout)); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Delete deletes a session, e.g. on logout
+func (service *Service) Delete(ctx context.Context, sessionID string) error {
+	return service.store.TokenDelete(ctx, sessionID)
+}
+
+// ExpiredDelete deletes the expired sessions, run it periodically or enable the
+// maintenance scheduler of the store instead
+func (service *Service) ExpiredDelete(ctx context.Context) (int64, error) {
+	return service.store.TokensExpiredDelete(ctx)
+}
+
+// Handler returns the HTTP API of the service:
+// - POST /sessions creates a session from a JSON object of strings
+// - GET /sessions/{id} returns the values of a session
+// - DELETE /sessions/{id} deletes a session
+func (service *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /sessions", func(w http.ResponseWriter, r *http.Request) {
+		var values map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
+			http.Error(w, "invalid JSON object", http.StatusBadRequest)
+			return
+		}
+
+		sessionID, err := service.Create(r.Context(), values)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, map[string]string{"session_id": sessionID})
+	})
+
+	mux.HandleFunc("GET /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		values, err := service.Get(r.Context(), r.PathValue("id"))
+		if errors.Is(err, ErrSessionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, values)
+	})
+
+	mux.HandleFunc("DELETE /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := service.Delete(r.Context(), r.PathValue("id")); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+// writeJSON writes the value as a JSON response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,205 @@
+// Package tokenization is an example tokenization service built on vaultstore. It
+// replaces sensitive values, e.g. card numbers, with tokens, and only the callers
+// allowed by the operation guard of the store can turn the tokens back into values.
+package tokenization
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/dracory/vaultstore"
+)
+
+// TOKEN_LENGTH is the length of the generated tokens
+const TOKEN_LENGTH = 32
+
+// Roles of the callers
+const (
+	// ROLE_TOKENIZER can create tokens only, e.g. the checkout service
+	ROLE_TOKENIZER = "tokenizer"
+	// ROLE_DETOKENIZER can also read the values, e.g. the payment processor
+	ROLE_DETOKENIZER = "detokenizer"
+	// ROLE_ADMIN can run every operation, e.g. changing the password
+	ROLE_ADMIN = "admin"
+)
+
+// HEADER_CALLER is the request header naming the caller. It is trusted as is, a real
+// deployment authenticates the caller first, e.g. with the vaulthttp middleware.
+const HEADER_CALLER = "X-Caller"
+
+// ErrTokenNotFound is returned when detokenizing an unknown token
+var ErrTokenNotFound = errors.New("token not found")
+
+// roleOperations are the store operations allowed to each role
+var roleOperations = map[string][]string{
+	ROLE_TOKENIZER:   {"TokenCreate"},
+	ROLE_DETOKENIZER: {"TokenCreate", "TokenRead"},
+}
+
+// callerContextKey is the context key of the caller
+type callerContextKey struct{}
+
+// WithCaller returns the context of the requests of a caller
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// Guard returns the operation guard of the store, allowing the operations by the role
+// of the caller in the context. Set it as vaultstore.NewStoreOptions.OperationGuard.
+//
+// Parameters:
+// - callerRoles: The role of each caller
+func Guard(callerRoles map[string]string) vaultstore.OperationGuard {
+	return vaultstore.OperationGuardFunc(func(ctx context.Context, operation string, token string) error {
+		caller, _ := ctx.Value(callerContextKey{}).(string)
+		role, ok := callerRoles[caller]
+		if !ok {
+			return vaultstore.ErrOperationDenied
+		}
+
+		if role == ROLE_ADMIN {
+			return nil
+		}
+
+		for _, allowed := range roleOperations[role] {
+			if allowed == operation {
+				return nil
+			}
+		}
+
+		return vaultstore.ErrOperationDenied
+	})
+}
+
+// Service tokenizes values
+type Service struct {
+	store vaultstore.StoreInterface
+
+	// mu guards the password, changed by Rekey
+	mu       sync.RWMutex
+	password string
+}
+
+// NewService returns the tokenization service
+//
+// Parameters:
+// - store: The vault store, created with the Guard of the callers
+// - password: The password encrypting the values
+func NewService(store vaultstore.StoreInterface, password string) *Service {
+	return &Service{
+		store:    store,
+		password: password,
+	}
+}
+
+// Tokenize stores the value and returns its token
+func (service *Service) Tokenize(ctx context.Context, value string) (string, error) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	return service.store.TokenCreate(ctx, value, service.password, TOKEN_LENGTH)
+}
+
+// Detokenize returns the value of a token
+func (service *Service) Detokenize(ctx context.Context, token string) (string, error) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	value, err := service.store.TokenRead(ctx, token, service.password)
+	if errors.Is(err, vaultstore.ErrTokenNotFound) {
+		return "", ErrTokenNotFound
+	}
+
+	return value, err
+}
+
+// Rekey re-encrypts every value with the new password, e.g. on a scheduled rotation.
+// Tokenizing and detokenizing wait until it is done.
+//
+// Returns:
+// - count: The number of re-encrypted values
+// - err: An error if something went wrong, the old password is kept
+func (service *Service) Rekey(ctx context.Context, newPassword string) (int, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	count, err := service.store.TokensChangePassword(ctx, service.password, newPassword)
+	if err != nil {
+		return count, err
+	}
+
+	service.password = newPassword
+	return count, nil
+}
+
+// Handler returns the HTTP API of the service, the caller named by HEADER_CALLER:
+// - POST /tokenize turns {"value": "..."} into {"token": "..."}
+// - POST /detokenize turns {"token": "..."} into {"value": "..."}
+func (service *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /tokenize", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Value string `json:"value"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "invalid JSON object", http.StatusBadRequest)
+			return
+		}
+
+		token, err := service.Tokenize(callerContext(r), request.Value)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, map[string]string{"token": token})
+	})
+
+	mux.HandleFunc("POST /detokenize", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "invalid JSON object", http.StatusBadRequest)
+			return
+		}
+
+		value, err := service.Detokenize(callerContext(r), request.Token)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"value": value})
+	})
+
+	return mux
+}
+
+// callerContext returns the context of the request with its caller
+func callerContext(r *http.Request) context.Context {
+	return WithCaller(r.Context(), r.Header.Get(HEADER_CALLER))
+}
+
+// writeError writes the error with its HTTP status
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, vaultstore.ErrOperationDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrTokenNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeJSON writes the value as a JSON response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,41 @@
+package tokenization
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dracory/vaultstore"
+)
+
+func TestGuard(t *testing.T) {
+	guard := Guard(map[string]string{
+		"checkout": ROLE_TOKENIZER,
+		"payments": ROLE_DETOKENIZER,
+		"ops":      ROLE_ADMIN,
+	})
+
+	cases := []struct {
+		caller    string
+		operation string
+		allowed   bool
+	}{
+		{"checkout", "TokenCreate", true},
+		{"checkout", "TokenRead", false},
+		{"payments", "TokenRead", true},
+		{"payments", "TokensChangePassword", false},
+		{"ops", "TokensChangePassword", true},
+		{"unknown", "TokenCreate", false},
+		{"", "TokenCreate", false},
+	}
+
+	for _, c := range cases {
+		err := guard.Allow(WithCaller(context.Background(), c.caller), c.operation, "")
+		if c.allowed && err != nil {
+			t.Fatalf("Allow(%s, %s): Expected [err] to be nil received [%v]", c.caller, c.operation, err.Error())
+		}
+		if !c.allowed && !errors.Is(err, vaultstore.ErrOperationDenied) {
+			t.Fatalf("Allow(%s, %s): Expected [ErrOperationDenied] received [%v]", c.caller, c.operation, err)
+		}
+	}
+}
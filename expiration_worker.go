@@ -0,0 +1,145 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ExpirationWorkerMode selects how the expiration worker removes the expired tokens
+type ExpirationWorkerMode string
+
+// Modes of the expiration worker
+const (
+	// EXPIRATION_WORKER_MODE_SOFT_DELETE soft deletes the expired tokens, see TokensExpiredSoftDelete
+	EXPIRATION_WORKER_MODE_SOFT_DELETE ExpirationWorkerMode = "soft_delete"
+	// EXPIRATION_WORKER_MODE_HARD_DELETE permanently deletes the expired tokens, see TokensExpiredDelete
+	EXPIRATION_WORKER_MODE_HARD_DELETE ExpirationWorkerMode = "hard_delete"
+)
+
+// EXPIRATION_WORKER_INTERVAL_DEFAULT is the default time between the runs of the expiration worker
+const EXPIRATION_WORKER_INTERVAL_DEFAULT = time.Minute
+
+// ErrExpirationWorkerModeInvalid is returned for a mode other than the EXPIRATION_WORKER_MODE_* ones
+var ErrExpirationWorkerModeInvalid = errors.New("expiration worker mode must be soft_delete or hard_delete")
+
+// ExpirationWorkerOptions configures the expiration worker
+type ExpirationWorkerOptions struct {
+	// Interval is the time between runs (0 = use default 1 minute)
+	Interval time.Duration
+
+	// Mode selects soft or hard deletion (empty = EXPIRATION_WORKER_MODE_SOFT_DELETE)
+	Mode ExpirationWorkerMode
+
+	// BatchSize is the number of tokens removed per statement (0 = use NewStoreOptions.DeleteBatchSize)
+	BatchSize int
+
+	// OnRun is called after each successful run with the number of removed tokens (optional)
+	OnRun func(count int64)
+
+	// OnError is called with the error of each failed run (optional). The worker keeps running.
+	OnError func(err error)
+}
+
+// ExpirationWorker removes the expired tokens on an interval, see StartExpirationWorker
+type ExpirationWorker struct {
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// StartExpirationWorker removes the expired tokens in the background, once right away
+// then on every interval, until the context is done or Stop is called. It is a
+// lighter alternative to the expired cleanup job of the Scheduler, for intervals
+// shorter than a minute, and like it skips its runs while FEATURE_JANITOR is disabled.
+//
+//	worker, err := store.StartExpirationWorker(ctx, vaultstore.ExpirationWorkerOptions{
+//	    Interval: 30 * time.Second,
+//	    Mode:     vaultstore.EXPIRATION_WORKER_MODE_HARD_DELETE,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer worker.Stop()
+//
+// Parameters:
+// - ctx: The context, the worker stops when it is done
+// - options: The interval, mode, batch size and callbacks
+//
+// Returns:
+// - worker: The running worker
+// - err: ErrExpirationWorkerModeInvalid, or an error if something went wrong
+func (store *storeImplementation) StartExpirationWorker(ctx context.Context, options ExpirationWorkerOptions) (*ExpirationWorker, error) {
+	ctx, span := store.traceStart(ctx, "StartExpirationWorker")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "StartExpirationWorker", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	var cleanup SchedulerJob
+	switch options.Mode {
+	case "", EXPIRATION_WORKER_MODE_SOFT_DELETE:
+		cleanup = store.TokensExpiredSoftDelete
+	case EXPIRATION_WORKER_MODE_HARD_DELETE:
+		cleanup = store.TokensExpiredDelete
+	default:
+		return nil, ErrExpirationWorkerModeInvalid
+	}
+	cleanup = store.featureGatedJob(FEATURE_JANITOR, cleanup)
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = EXPIRATION_WORKER_INTERVAL_DEFAULT
+	}
+
+	if options.BatchSize > 0 {
+		ctx = contextWithDeleteBatchSize(ctx, options.BatchSize)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	worker := &ExpirationWorker{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(worker.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			count, err := cleanup(ctx)
+			switch {
+			case err != nil && ctx.Err() == nil:
+				store.logError(ctx, "expiration worker run failed", "mode", options.Mode, "error", err.Error())
+				if options.OnError != nil {
+					options.OnError(err)
+				}
+			case err == nil:
+				store.logJanitorRun(ctx, "expiration worker run finished", count, "mode", options.Mode)
+				if options.OnRun != nil {
+					options.OnRun(count)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return worker, nil
+}
+
+// Stop stops the worker and waits for a run in progress to return, its context canceled.
+// It is safe to call several times.
+func (worker *ExpirationWorker) Stop() {
+	worker.stopOnce.Do(worker.cancel)
+	<-worker.done
+}
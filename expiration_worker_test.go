@@ -0,0 +1,86 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Store_StartExpirationWorker(t *testing.T) {
+	store, err := initStoreExpiresAtPastAllowed()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	expired, err := store.TokenCreate(ctx, "expired", password, 20, TokenCreateOptions{
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	valid, err := store.TokenCreate(ctx, "valid", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	counts := make(chan int64, 10)
+	worker, err := store.StartExpirationWorker(ctx, ExpirationWorkerOptions{
+		Interval:  10 * time.Millisecond,
+		Mode:      EXPIRATION_WORKER_MODE_HARD_DELETE,
+		BatchSize: 1,
+		OnRun: func(count int64) {
+			select {
+			case counts <- count:
+			default:
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("StartExpirationWorker: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	select {
+	case count := <-counts:
+		if count != 1 {
+			t.Fatalf("OnRun: Expected [1] received [%v]", count)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnRun: Expected a run within 5 seconds")
+	}
+
+	worker.Stop()
+	worker.Stop()
+
+	exists, err := store.TokenExists(ctx, expired)
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if exists {
+		t.Fatal("TokenExists: Expected the expired token to be deleted")
+	}
+
+	exists, err = store.TokenExists(ctx, valid)
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if !exists {
+		t.Fatal("TokenExists: Expected the valid token to be kept")
+	}
+}
+
+func Test_Store_StartExpirationWorker_ModeInvalid(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = store.StartExpirationWorker(context.Background(), ExpirationWorkerOptions{Mode: "archive"})
+	if !errors.Is(err, ErrExpirationWorkerModeInvalid) {
+		t.Fatalf("StartExpirationWorker: Expected [ErrExpirationWorkerModeInvalid] received [%v]", err)
+	}
+}
@@ -0,0 +1,419 @@
+package vaultstore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// EXPORT_FORMAT identifies the dumps written by Export
+const EXPORT_FORMAT = "vaultstore-export"
+
+// EXPORT_FORMAT_VERSION is the version of the dump format written by Export
+const EXPORT_FORMAT_VERSION = 1
+
+var (
+	// ErrExportPasswordMissing is returned by Export and Import without a password
+	ErrExportPasswordMissing = errors.New("export password is required")
+	// ErrExportFormatUnsupported is returned by Import for data not written by Export,
+	// or written in a newer format
+	ErrExportFormatUnsupported = errors.New("export format is not supported")
+	// ErrExportIncomplete is returned by Import when the dump ends before its last batch
+	ErrExportIncomplete = errors.New("export is incomplete")
+)
+
+// ExportOptions configures Export
+type ExportOptions struct {
+	// Password encrypts the dump. It is unrelated to the passwords of the tokens.
+	Password string
+}
+
+// ExportResult is the outcome of Export
+type ExportResult struct {
+	// Records is the number of exported records, soft deleted ones included
+	Records int64
+	// Meta is the number of exported meta rows
+	Meta int64
+}
+
+// ImportOptions configures Import
+type ImportOptions struct {
+	// Password decrypts the dump, the one given to Export
+	Password string
+}
+
+// ImportResult is the outcome of Import
+type ImportResult struct {
+	// Records is the number of created or updated records
+	Records int
+	// Unchanged is the number of records already present
+	Unchanged int
+	// Conflicts lists the records that were skipped, see ApplyChanges
+	Conflicts []SyncConflict
+	// Meta is the number of created or updated meta rows
+	Meta int
+}
+
+// exportHeader is the first line of a dump, in plain JSON, so the format
+// is recognized before decrypting
+type exportHeader struct {
+	Format        string `json:"format"`
+	FormatVersion int    `json:"format_version"`
+	VaultVersion  string `json:"vault_version"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// exportMeta is a meta row as exported. Sensitive values are decrypted,
+// the importing store encrypts them with its own meta encryption key.
+type exportMeta struct {
+	ObjectType string `json:"object_type"`
+	ObjectID   string `json:"object_id"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+}
+
+// exportChunk is an exported chunk of a streamed token, still encrypted with its stream key
+type exportChunk struct {
+	RecordID  string `json:"record_id"`
+	ValueHash string `json:"value_hash"`
+	Sequence  int    `json:"sequence"`
+	Data      string `json:"data"`
+}
+
+// exportBatch is an encrypted line of a dump. The last batch is empty with End set,
+// so a truncated dump is detected.
+type exportBatch struct {
+	Records []Change      `json:"records,omitempty"`
+	Meta    []exportMeta  `json:"meta,omitempty"`
+	Chunks  []exportChunk `json:"chunks,omitempty"`
+	End     bool          `json:"end,omitempty"`
+}
+
+// Export writes a portable dump of the vault (records and meta) for backups and
+// migrations to another database, restored with Import. The dump holds the records
+// of the vault table (and namespace) of the context with their meta, and the vault
+// settings. The meta of other tables and the token aliases, scoped to the vault
+// table, are not exported.
+//
+// The dump starts with a plain JSON header holding the format and vault versions,
+// followed by one line per batch of up to 1000 records or meta rows, each encrypted
+// with opts.Password. Record values stay encrypted with their own password.
+// The meta values encrypted with the meta encryption key are decrypted before
+// being encrypted in the dump, so the meta encryption key is not needed to import.
+//
+// Parameters:
+// - ctx: The context
+// - w: The writer receiving the dump
+// - opts: The export options
+//
+// Returns:
+// - result: The number of exported records and meta rows
+// - err: An error if something went wrong
+func (store *storeImplementation) Export(ctx context.Context, w io.Writer, opts ExportOptions) (result ExportResult, err error) {
+	ctx, span := store.traceStart(ctx, "Export")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "Export", ""); err != nil {
+		return result, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if opts.Password == "" {
+		return result, ErrExportPasswordMissing
+	}
+
+	version, err := store.GetVaultVersion(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	headerJSON, err := json.Marshal(exportHeader{
+		Format:        EXPORT_FORMAT,
+		FormatVersion: EXPORT_FORMAT_VERSION,
+		VaultVersion:  version,
+		CreatedAt:     carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	writer := bufio.NewWriter(w)
+	if _, err := writer.Write(append(headerJSON, '\n')); err != nil {
+		return result, fmt.Errorf("failed to write export header: %w", err)
+	}
+
+	lastID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		var gormRecords []gormVaultRecord
+		err := store.vaultDB(ctx).
+			Where(COLUMN_ID+" > ?", lastID).
+			Order(COLUMN_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&gormRecords).Error
+		if err != nil {
+			return result, err
+		}
+
+		if len(gormRecords) == 0 {
+			break
+		}
+		lastID = gormRecords[len(gormRecords)-1].ID
+
+		if err := store.valueChunksResolve(ctx, gormRecords); err != nil {
+			return result, err
+		}
+
+		// The meta of the records is written with them, so Import skips it for conflicts
+		metas, err := store.recordsMetaFind(ctx, gormRecords)
+		if err != nil {
+			return result, err
+		}
+
+		batch := exportBatch{
+			Records: make([]Change, 0, len(gormRecords)),
+			Meta:    make([]exportMeta, 0, len(metas)),
+		}
+		for i := range gormRecords {
+			batch.Records = append(batch.Records, changeFromGorm(&gormRecords[i]))
+		}
+		for i := range metas {
+			batch.Meta = append(batch.Meta, exportMetaFromGorm(metas[i]))
+		}
+
+		if err := store.exportBatchWrite(ctx, writer, batch, opts.Password); err != nil {
+			return result, err
+		}
+		result.Records += int64(len(batch.Records))
+		result.Meta += int64(len(batch.Meta))
+
+		// The chunks of the streamed tokens follow their records, a page per batch
+		err = store.streamChunksEach(ctx, metas, func(rows []gormVaultChunk) error {
+			chunks := make([]exportChunk, len(rows))
+			for i, row := range rows {
+				chunks[i] = exportChunk{RecordID: row.RecordID, ValueHash: row.ValueHash, Sequence: row.Sequence, Data: row.Data}
+			}
+			return store.exportBatchWrite(ctx, writer, exportBatch{Chunks: chunks}, opts.Password)
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// The vault settings, the importing store keeps its own vault version and token filter state
+	var settings []gormVaultMeta
+	err = store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" NOT IN ?", OBJECT_TYPE_VAULT_SETTINGS, []string{META_KEY_VERSION, VAULT_SETTING_KEY_TOKENS_BACKFILLED}).
+		Order(COLUMN_ID + " ASC").
+		Find(&settings).Error
+	if err != nil {
+		return result, err
+	}
+
+	if len(settings) > 0 {
+		batch := exportBatch{Meta: make([]exportMeta, 0, len(settings))}
+		for i := range settings {
+			if err := store.metaValueDecrypt(&settings[i]); err != nil {
+				return result, err
+			}
+			batch.Meta = append(batch.Meta, exportMetaFromGorm(settings[i]))
+		}
+
+		if err := store.exportBatchWrite(ctx, writer, batch, opts.Password); err != nil {
+			return result, err
+		}
+		result.Meta += int64(len(batch.Meta))
+	}
+
+	if err := store.exportBatchWrite(ctx, writer, exportBatch{End: true}, opts.Password); err != nil {
+		return result, err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return result, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return result, nil
+}
+
+// exportMetaFromGorm returns the meta row as exported, its value already decrypted
+func exportMetaFromGorm(meta gormVaultMeta) exportMeta {
+	return exportMeta{
+		ObjectType: meta.ObjectType,
+		ObjectID:   meta.ObjectID,
+		Key:        meta.Key,
+		Value:      meta.Value,
+	}
+}
+
+// exportBatchWrite encrypts the batch with the password and writes it as a line
+func (store *storeImplementation) exportBatchWrite(ctx context.Context, w io.Writer, batch exportBatch, password string) error {
+	batchJSON, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := encode(ctx, string(batchJSON), password, store.cryptoConfig.withoutEnvelope())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt export batch: %w", err)
+	}
+
+	if _, err := io.WriteString(w, encrypted+"\n"); err != nil {
+		return fmt.Errorf("failed to write export batch: %w", err)
+	}
+
+	return nil
+}
+
+// Import restores a dump written by Export, into the same or another database.
+// Records are applied as with ApplyChanges: existing records are updated only
+// if the dump is newer, and conflicting records are skipped along with their meta.
+// Meta rows are created or replaced, sensitive values are encrypted with the
+// meta encryption key of the store.
+//
+// Dumps of a vault version newer than the one supported by the store are refused.
+//
+// Parameters:
+// - ctx: The context
+// - r: The reader of the dump
+// - opts: The import options
+//
+// Returns:
+// - result: The number of imported records and meta rows, and the conflicts
+// - err: An error if something went wrong
+func (store *storeImplementation) Import(ctx context.Context, r io.Reader, opts ImportOptions) (ImportResult, error) {
+	ctx, span := store.traceStart(ctx, "Import")
+	defer span.End()
+
+	result := ImportResult{Conflicts: []SyncConflict{}}
+
+	if err := store.operationAllow(ctx, "Import", ""); err != nil {
+		return result, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if opts.Password == "" {
+		return result, ErrExportPasswordMissing
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), archiveMaxLineLength)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return result, err
+		}
+		return result, fmt.Errorf("%w: missing header", ErrExportFormatUnsupported)
+	}
+
+	var header exportHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Format != EXPORT_FORMAT {
+		return result, fmt.Errorf("%w: invalid header", ErrExportFormatUnsupported)
+	}
+
+	if header.FormatVersion < 1 || header.FormatVersion > EXPORT_FORMAT_VERSION {
+		return result, fmt.Errorf("%w: format version %d", ErrExportFormatUnsupported, header.FormatVersion)
+	}
+
+	if header.VaultVersion != "" {
+		newer, err := compareVaultVersions(header.VaultVersion, VAULT_VERSION_CURRENT)
+		if err != nil {
+			return result, err
+		}
+
+		if newer > 0 {
+			return result, fmt.Errorf("%w: export vault version %s is newer than %s", ErrVaultVersionUnsupported, header.VaultVersion, VAULT_VERSION_CURRENT)
+		}
+	}
+
+	// The meta of the conflicting records is not imported
+	conflictObjectIDs := map[string]bool{}
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		decrypted, err := decode(ctx, scanner.Text(), opts.Password, store.cryptoConfig)
+		if err != nil {
+			return result, fmt.Errorf("failed to decrypt export batch: %w", err)
+		}
+
+		var batch exportBatch
+		if err := json.Unmarshal([]byte(decrypted), &batch); err != nil {
+			return result, err
+		}
+
+		if batch.End {
+			return result, nil
+		}
+
+		appliedCount := 0
+		for _, change := range batch.Records {
+			applied, conflict, err := store.applyChange(ctx, change)
+			if err != nil {
+				return result, err
+			}
+
+			switch {
+			case conflict != nil:
+				result.Conflicts = append(result.Conflicts, *conflict)
+				conflictObjectIDs[recordMetaObjectID(change.ID)] = true
+			case applied:
+				appliedCount++
+			default:
+				result.Unchanged++
+			}
+		}
+		result.Records += appliedCount
+
+		// The imported records keep their timestamps, the other instances reload their token filters
+		if appliedCount > 0 {
+			if err := store.tokenBloomFilterBackfilled(ctx); err != nil {
+				return result, err
+			}
+		}
+
+		for _, meta := range batch.Meta {
+			if slices.Contains(recordMetaObjectTypes, meta.ObjectType) && conflictObjectIDs[meta.ObjectID] {
+				continue
+			}
+
+			if err := store.metaSet(ctx, meta.ObjectType, meta.ObjectID, meta.Key, meta.Value); err != nil {
+				return result, err
+			}
+			result.Meta++
+		}
+
+		rows := []gormVaultChunk{}
+		for _, chunk := range batch.Chunks {
+			if conflictObjectIDs[recordMetaObjectID(chunk.RecordID)] {
+				continue
+			}
+			rows = append(rows, gormVaultChunk{RecordID: chunk.RecordID, ValueHash: chunk.ValueHash, Sequence: chunk.Sequence, Data: chunk.Data})
+		}
+
+		if err := store.streamChunksImport(ctx, rows); err != nil {
+			return result, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+
+	return result, ErrExportIncomplete
+}
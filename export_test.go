@@ -0,0 +1,201 @@
+package vaultstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Store_ExportImport(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	source, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_export_source",
+		VaultMetaTableName: "vault_export_source_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		MetaEncryptionKey:  "meta_encryption_key_that_is_long_enough_32chars",
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	target, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_export_target",
+		VaultMetaTableName: "vault_export_target_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	exportPassword := "export_password_that_is_long_enough_32chars"
+
+	token, err := source.TokenCreate(ctx, "secret", password, 20, TokenCreateOptions{ContentType: "text/plain"})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := source.SetVaultSetting(ctx, "region", "eu-west-1"); err != nil {
+		t.Fatalf("SetVaultSetting: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var dump bytes.Buffer
+	exported, err := source.Export(ctx, &dump, ExportOptions{Password: exportPassword})
+	if err != nil {
+		t.Fatalf("Export: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if exported.Records != 1 {
+		t.Fatalf("Expected [1] exported record received [%v]", exported.Records)
+	}
+
+	if strings.Contains(dump.String(), token) || strings.Contains(dump.String(), "eu-west-1") {
+		t.Fatal("Expected the dump not to contain the token or settings in plain text")
+	}
+
+	if !strings.HasPrefix(dump.String(), `{"format":"`+EXPORT_FORMAT+`"`) {
+		t.Fatalf("Expected the dump to start with the header received [%v]", dump.String()[:40])
+	}
+
+	imported, err := target.Import(ctx, bytes.NewReader(dump.Bytes()), ImportOptions{Password: exportPassword})
+	if err != nil {
+		t.Fatalf("Import: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if imported.Records != 1 || len(imported.Conflicts) != 0 {
+		t.Fatalf("Expected [1] imported record and no conflicts received [%v] [%v]", imported.Records, imported.Conflicts)
+	}
+
+	if imported.Meta != int(exported.Meta) {
+		t.Fatalf("Expected [%v] imported meta rows received [%v]", exported.Meta, imported.Meta)
+	}
+
+	value, info, err := target.TokenReadWithInfo(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret" || info.ContentType != "text/plain" {
+		t.Fatalf("Expected [secret] [text/plain] received [%v] [%v]", value, info.ContentType)
+	}
+
+	region, err := target.GetVaultSetting(ctx, "region")
+	if err != nil {
+		t.Fatalf("GetVaultSetting: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if region != "eu-west-1" {
+		t.Fatalf("Expected [eu-west-1] received [%v]", region)
+	}
+
+	// Importing again changes nothing
+	imported, err = target.Import(ctx, bytes.NewReader(dump.Bytes()), ImportOptions{Password: exportPassword})
+	if err != nil {
+		t.Fatalf("Import: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if imported.Records != 0 || imported.Unchanged != 1 {
+		t.Fatalf("Expected [1] unchanged record received [%v] [%v]", imported.Records, imported.Unchanged)
+	}
+}
+
+func Test_Store_ExportTableScope(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.AutoMigrateTableSuffix("tenantA"); err != nil {
+		t.Fatalf("AutoMigrateTableSuffix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	ctxA := WithTableSuffix(ctx, "tenantA")
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	exportPassword := "export_password_that_is_long_enough_32chars"
+
+	if _, err := store.TokenCreate(ctx, "secret", password, 20, TokenCreateOptions{ContentType: "text/plain"}); err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	tokenA, err := store.TokenCreate(ctxA, "secret a", password, 20, TokenCreateOptions{ContentType: "application/json"})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenRevoke(ctxA, tokenA, "tenant a revocation"); err != nil {
+		t.Fatalf("TokenRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var dump bytes.Buffer
+	exported, err := store.Export(ctx, &dump, ExportOptions{Password: exportPassword})
+	if err != nil {
+		t.Fatalf("Export: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Only the content type of the record of the default table is exported
+	if exported.Records != 1 || exported.Meta != 1 {
+		t.Fatalf("Expected [1] record and [1] meta row received [%v] [%v]", exported.Records, exported.Meta)
+	}
+
+	dump.Reset()
+	exported, err = store.Export(ctxA, &dump, ExportOptions{Password: exportPassword})
+	if err != nil {
+		t.Fatalf("Export: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if exported.Records != 1 || exported.Meta != 2 {
+		t.Fatalf("Expected [1] record and [2] meta rows received [%v] [%v]", exported.Records, exported.Meta)
+	}
+}
+
+func Test_Store_ImportErrors(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	exportPassword := "export_password_that_is_long_enough_32chars"
+
+	if _, err := store.Export(ctx, &bytes.Buffer{}, ExportOptions{}); !errors.Is(err, ErrExportPasswordMissing) {
+		t.Fatalf("Expected [ErrExportPasswordMissing] received [%v]", err)
+	}
+
+	if _, err := store.TokenCreate(ctx, "secret", "test_password_that_is_long_enough_for_security_32chars", 20); err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var dump bytes.Buffer
+	if _, err := store.Export(ctx, &dump, ExportOptions{Password: exportPassword}); err != nil {
+		t.Fatalf("Export: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.Import(ctx, strings.NewReader("not a dump\n"), ImportOptions{Password: exportPassword}); !errors.Is(err, ErrExportFormatUnsupported) {
+		t.Fatalf("Expected [ErrExportFormatUnsupported] received [%v]", err)
+	}
+
+	newer := `{"format":"` + EXPORT_FORMAT + `","format_version":1,"vault_version":"99.0"}` + "\n"
+	if _, err := store.Import(ctx, strings.NewReader(newer), ImportOptions{Password: exportPassword}); !errors.Is(err, ErrVaultVersionUnsupported) {
+		t.Fatalf("Expected [ErrVaultVersionUnsupported] received [%v]", err)
+	}
+
+	if _, err := store.Import(ctx, bytes.NewReader(dump.Bytes()), ImportOptions{Password: "wrong_password_that_is_long_enough_32chars"}); err == nil {
+		t.Fatal("Expected an error for a wrong password")
+	}
+
+	lines := strings.Split(strings.TrimSpace(dump.String()), "\n")
+	truncated := strings.Join(lines[:len(lines)-1], "\n")
+	if _, err := store.Import(ctx, strings.NewReader(truncated), ImportOptions{Password: exportPassword}); !errors.Is(err, ErrExportIncomplete) {
+		t.Fatalf("Expected [ErrExportIncomplete] received [%v]", err)
+	}
+}
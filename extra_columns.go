@@ -0,0 +1,110 @@
+package vaultstore
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"gorm.io/gorm/clause"
+)
+
+// extraColumnNamePattern restricts custom column names to plain lowercase identifiers
+var extraColumnNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// extraColumnTypePattern restricts custom column types to plain SQL types, e.g. VARCHAR(64) or BIGINT
+var extraColumnTypePattern = regexp.MustCompile(`^[A-Za-z]+( ?\([0-9, ]+\))?( [A-Za-z]+)*$`)
+
+// EXTRA_COLUMN_DEFAULT_TYPE is the SQL type of a ColumnSpec without a type
+const EXTRA_COLUMN_DEFAULT_TYPE = "VARCHAR(255)"
+
+// ColumnSpec describes a custom column of the vault table, see NewStoreOptions.ExtraColumns
+type ColumnSpec struct {
+	// Name is the column name, a lowercase identifier such as "owner_id"
+	Name string
+	// Type is the SQL type of the column (default: VARCHAR(255)).
+	// The column is nullable, records without the value read it as an empty string.
+	Type string
+	// Index creates an index on the column, for RecordQuery().SetExtraEquals
+	Index bool
+}
+
+// validateExtraColumnName returns an error if the name cannot be used as a custom column
+func validateExtraColumnName(name string) error {
+	if !extraColumnNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid extra column name %q", name)
+	}
+
+	if slices.Contains(recordBaseColumns, name) || name == COLUMN_NAMESPACE {
+		return fmt.Errorf("extra column %q is a vault column", name)
+	}
+
+	return nil
+}
+
+// validateColumnSpecs checks the custom columns before the store is created
+func validateColumnSpecs(specs []ColumnSpec) error {
+	seen := map[string]bool{}
+
+	for _, spec := range specs {
+		if err := validateExtraColumnName(spec.Name); err != nil {
+			return err
+		}
+
+		if spec.Type != "" && !extraColumnTypePattern.MatchString(spec.Type) {
+			return fmt.Errorf("invalid type %q of extra column %q", spec.Type, spec.Name)
+		}
+
+		if seen[spec.Name] {
+			return errors.New("duplicate extra column " + spec.Name)
+		}
+		seen[spec.Name] = true
+	}
+
+	return nil
+}
+
+// extraColumnsMigrate adds the missing custom columns, and their indexes, to the vault table
+func (store *storeImplementation) extraColumnsMigrate(tableName string) error {
+	migrator := store.gormDB.Migrator()
+
+	for _, spec := range store.extraColumns {
+		columnType := spec.Type
+		if columnType == "" {
+			columnType = EXTRA_COLUMN_DEFAULT_TYPE
+		}
+
+		if !migrator.HasColumn(tableName, spec.Name) {
+			// The type is validated by validateColumnSpecs, it cannot be a placeholder
+			err := store.gormDB.Exec(
+				"ALTER TABLE ? "+store.addColumnKeyword()+" ? "+columnType,
+				clause.Table{Name: tableName},
+				clause.Column{Name: spec.Name},
+			).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if !spec.Index {
+			continue
+		}
+
+		indexName := "idx_" + tableName + "_" + spec.Name
+		if migrator.HasIndex(tableName, indexName) {
+			continue
+		}
+
+		err := store.gormDB.Exec(
+			"CREATE INDEX ? ON ? (?)",
+			clause.Column{Name: indexName},
+			clause.Table{Name: tableName},
+			clause.Column{Name: spec.Name},
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_Store_ExtraColumns(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_extra_columns",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		ExtraColumns: []ColumnSpec{
+			{Name: "owner_id", Type: "VARCHAR(40)", Index: true},
+			{Name: "app_id"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !store.gormDB.Migrator().HasColumn("vault_extra_columns", "owner_id") {
+		t.Fatal("Expected the owner_id column to be created")
+	}
+
+	if !store.gormDB.Migrator().HasIndex("vault_extra_columns", "idx_vault_extra_columns_owner_id") {
+		t.Fatal("Expected the owner_id index to be created")
+	}
+
+	ctx := context.Background()
+
+	for i, owner := range []string{"user_1", "user_2", "user_1"} {
+		record := store.newRecord()
+		record.SetToken("tk_extra_column_" + string(rune('a'+i)))
+		record.SetValue("value")
+		record.SetExtra("owner_id", owner)
+		record.SetExtra("app_id", "app_1")
+
+		if err := store.RecordCreate(ctx, record); err != nil {
+			t.Fatalf("RecordCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	records, err := store.RecordList(ctx, RecordQuery().
+		SetExtraEquals("owner_id", "user_1").
+		SetExtraEquals("app_id", "app_1"))
+	if err != nil {
+		t.Fatalf("RecordList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records received [%v]", len(records))
+	}
+
+	if records[0].GetExtra("owner_id") != "user_1" || records[0].GetExtra("app_id") != "app_1" {
+		t.Fatalf("Expected the extra columns to be read back received [%v]", records[0].Data())
+	}
+
+	records[0].SetExtra("owner_id", "user_3")
+	if err := store.RecordUpdate(ctx, records[0]); err != nil {
+		t.Fatalf("RecordUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	count, err := store.RecordCount(ctx, RecordQuery().SetExtraEquals("owner_id", "user_3"))
+	if err != nil {
+		t.Fatalf("RecordCount: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected 1 record received [%v]", count)
+	}
+
+	_, err = store.RecordList(ctx, RecordQuery().SetExtraEquals("owner_id; DROP TABLE", "x"))
+	if err == nil {
+		t.Fatal("Expected an invalid column name to be rejected")
+	}
+}
+
+func Test_Store_ExtraColumns_Invalid(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	invalid := [][]ColumnSpec{
+		{{Name: "vault_token"}},
+		{{Name: "Owner"}},
+		{{Name: "owner_id", Type: "TEXT; DROP TABLE vault"}},
+		{{Name: "owner_id"}, {Name: "owner_id"}},
+	}
+
+	for _, columns := range invalid {
+		_, err := NewStore(NewStoreOptions{
+			VaultTableName:     "vault_extra_columns_invalid",
+			VaultMetaTableName: "vault_meta",
+			DB:                 db,
+			ExtraColumns:       columns,
+		})
+		if err == nil {
+			t.Fatalf("Expected the columns %v to be rejected", columns)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// VAULT_SETTING_KEY_FEATURE_PREFIX prefixes the vault setting keys of the feature flags
+const VAULT_SETTING_KEY_FEATURE_PREFIX = "feature_"
+
+// Features of the store gated by a feature flag, enabled unless disabled
+const (
+	// FEATURE_JANITOR runs the built-in maintenance jobs of the Scheduler. Disabled, the
+	// jobs are skipped on every deployment sharing the vault, e.g. during an incident.
+	FEATURE_JANITOR = "janitor"
+)
+
+// featureDefaults are the states of the store features whose flag was never set.
+// The application flags are disabled until enabled.
+var featureDefaults = map[string]bool{
+	FEATURE_JANITOR: true,
+}
+
+// ErrFeatureNameInvalid is returned for a feature name with unsupported characters
+var ErrFeatureNameInvalid = errors.New("feature name must contain only lowercase letters, digits, dots, dashes and underscores (max 42 chars)")
+
+// featureNameRegex keeps the setting keys of the flags within the meta key column (50 chars)
+var featureNameRegex = regexp.MustCompile(`^[a-z0-9_.-]{1,42}$`)
+
+// FeatureEnable enables a feature flag, persisted in the vault settings so that it
+// applies to every store sharing the vault. Use it for the FEATURE_* flags of the
+// store and for application flags alike.
+//
+// Parameters:
+// - ctx: The context
+// - name: The feature name, e.g. FEATURE_JANITOR or "new_checkout"
+//
+// Returns:
+// - err: ErrFeatureNameInvalid, or an error if something went wrong
+func (store *storeImplementation) FeatureEnable(ctx context.Context, name string) error {
+	ctx, span := store.traceStart(ctx, "FeatureEnable")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "FeatureEnable", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	return store.featureSet(ctx, name, true)
+}
+
+// FeatureDisable disables a feature flag, see FeatureEnable
+//
+// Parameters:
+// - ctx: The context
+// - name: The feature name
+//
+// Returns:
+// - err: ErrFeatureNameInvalid, or an error if something went wrong
+func (store *storeImplementation) FeatureDisable(ctx context.Context, name string) error {
+	ctx, span := store.traceStart(ctx, "FeatureDisable")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "FeatureDisable", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	return store.featureSet(ctx, name, false)
+}
+
+// FeatureIsEnabled reports whether a feature flag is enabled. Flags never set are
+// disabled, except the FEATURE_* flags of the store, enabled by default.
+//
+// Parameters:
+// - ctx: The context
+// - name: The feature name
+//
+// Returns:
+// - enabled: Whether the feature is enabled
+// - err: ErrFeatureNameInvalid, or an error if something went wrong
+func (store *storeImplementation) FeatureIsEnabled(ctx context.Context, name string) (bool, error) {
+	ctx, span := store.traceStart(ctx, "FeatureIsEnabled")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "FeatureIsEnabled", ""); err != nil {
+		return false, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if !featureNameRegex.MatchString(name) {
+		return false, ErrFeatureNameInvalid
+	}
+
+	meta, err := store.metaFind(ctx, OBJECT_TYPE_VAULT_SETTINGS, VAULT_SETTINGS_ID, VAULT_SETTING_KEY_FEATURE_PREFIX+name)
+	if err != nil {
+		return false, err
+	}
+
+	if meta == nil {
+		return featureDefaults[name], nil
+	}
+
+	enabled, err := strconv.ParseBool(meta.Value)
+	if err != nil {
+		return false, err
+	}
+
+	return enabled, nil
+}
+
+// featureSet persists the state of a feature flag
+func (store *storeImplementation) featureSet(ctx context.Context, name string, enabled bool) error {
+	if !featureNameRegex.MatchString(name) {
+		return ErrFeatureNameInvalid
+	}
+
+	return store.SetVaultSetting(ctx, VAULT_SETTING_KEY_FEATURE_PREFIX+name, strconv.FormatBool(enabled))
+}
+
+// featureGatedJob returns the job skipping its runs, with a count of 0, while the feature is disabled
+func (store *storeImplementation) featureGatedJob(name string, job SchedulerJob) SchedulerJob {
+	return func(ctx context.Context) (int64, error) {
+		enabled, err := store.FeatureIsEnabled(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+
+		if !enabled {
+			return 0, nil
+		}
+
+		return job(ctx)
+	}
+}
@@ -0,0 +1,103 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Store_Features(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+
+	// Store features are enabled, application flags disabled until set
+	enabled, err := store.FeatureIsEnabled(ctx, FEATURE_JANITOR)
+	if err != nil {
+		t.Fatalf("FeatureIsEnabled: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if !enabled {
+		t.Fatal("Expected the janitor to be enabled by default")
+	}
+
+	enabled, err = store.FeatureIsEnabled(ctx, "new_checkout")
+	if err != nil {
+		t.Fatalf("FeatureIsEnabled: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if enabled {
+		t.Fatal("Expected an application flag to be disabled by default")
+	}
+
+	if err := store.FeatureEnable(ctx, "new_checkout"); err != nil {
+		t.Fatalf("FeatureEnable: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	enabled, err = store.FeatureIsEnabled(ctx, "new_checkout")
+	if err != nil || !enabled {
+		t.Fatalf("Expected the flag to be enabled received [%v] [%v]", enabled, err)
+	}
+
+	if err := store.FeatureDisable(ctx, "new_checkout"); err != nil {
+		t.Fatalf("FeatureDisable: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	enabled, err = store.FeatureIsEnabled(ctx, "new_checkout")
+	if err != nil || enabled {
+		t.Fatalf("Expected the flag to be disabled received [%v] [%v]", enabled, err)
+	}
+
+	for _, name := range []string{"", "New Checkout", strings.Repeat("a", 43)} {
+		if err := store.FeatureEnable(ctx, name); !errors.Is(err, ErrFeatureNameInvalid) {
+			t.Fatalf("Expected [ErrFeatureNameInvalid] for [%v] received [%v]", name, err)
+		}
+	}
+}
+
+func Test_Store_Features_JanitorGatesBuiltinJobs(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	if err := store.TokenCreateCustom(ctx, "expiring", "value", password, TokenCreateOptions{ExpiresAt: time.Now().Add(time.Second)}); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+	time.Sleep(2 * time.Second)
+
+	scheduler, err := store.Scheduler()
+	if err != nil {
+		t.Fatalf("Scheduler: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.FeatureDisable(ctx, FEATURE_JANITOR); err != nil {
+		t.Fatalf("FeatureDisable: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	run, err := scheduler.RunNow(ctx, SCHEDULER_JOB_EXPIRED_CLEANUP)
+	if err != nil {
+		t.Fatalf("RunNow: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if run.Count != 0 {
+		t.Fatalf("Expected the disabled job to be skipped received [%d]", run.Count)
+	}
+
+	if err := store.FeatureEnable(ctx, FEATURE_JANITOR); err != nil {
+		t.Fatalf("FeatureEnable: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	run, err = scheduler.RunNow(ctx, SCHEDULER_JOB_EXPIRED_CLEANUP)
+	if err != nil {
+		t.Fatalf("RunNow: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if run.Count != 1 {
+		t.Fatalf("Expected [1] expired token soft deleted received [%d]", run.Count)
+	}
+}
@@ -0,0 +1,63 @@
+// Package fixtures ships a golden corpus of ciphertexts produced by each
+// encryption version of the vault store, with the passwords and plaintexts
+// that produced them. Downstream tests and future format changes assert that
+// every entry still decrypts to its plaintext.
+//
+// The corpus is append-only: new versions and parameters get new entries,
+// existing entries are never regenerated.
+package fixtures
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+//go:embed golden_corpus.json
+var goldenCorpus []byte
+
+// CryptoParams are the key derivation and cipher parameters a v2 ciphertext
+// was produced with, mirroring vaultstore.CryptoConfig
+type CryptoParams struct {
+	Iterations  int `json:"iterations"`
+	Memory      int `json:"memory"`
+	Parallelism int `json:"parallelism"`
+	KeyLength   int `json:"key_length"`
+	SaltSize    int `json:"salt_size"`
+	NonceSize   int `json:"nonce_size"`
+	TagSize     int `json:"tag_size"`
+}
+
+// Fixture is a ciphertext of the golden corpus
+type Fixture struct {
+	Name       string        `json:"name"`
+	Version    string        `json:"version"` // Encryption version, e.g. "v1" or "v2"
+	Password   string        `json:"password"`
+	Plaintext  string        `json:"plaintext"`
+	Ciphertext string        `json:"ciphertext"`
+	Crypto     *CryptoParams `json:"crypto,omitempty"` // Nil for v1
+}
+
+// Load returns all fixtures of the golden corpus
+func Load() ([]Fixture, error) {
+	fixtures := []Fixture{}
+	if err := json.Unmarshal(goldenCorpus, &fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// LoadVersion returns the fixtures of the given encryption version
+func LoadVersion(version string) ([]Fixture, error) {
+	fixtures, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []Fixture{}
+	for _, fixture := range fixtures {
+		if fixture.Version == version {
+			filtered = append(filtered, fixture)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,48 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dracory/vaultstore/fixtures"
+)
+
+func Test_GoldenCorpus_Decode(t *testing.T) {
+	corpus, err := fixtures.Load()
+	if err != nil {
+		t.Fatalf("fixtures.Load: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	versions := map[string]int{}
+	for _, fixture := range corpus {
+		var config *CryptoConfig
+		if fixture.Crypto != nil {
+			config = &CryptoConfig{
+				Iterations:  fixture.Crypto.Iterations,
+				Memory:      fixture.Crypto.Memory,
+				Parallelism: fixture.Crypto.Parallelism,
+				KeyLength:   fixture.Crypto.KeyLength,
+				SaltSize:    fixture.Crypto.SaltSize,
+				NonceSize:   fixture.Crypto.NonceSize,
+				TagSize:     fixture.Crypto.TagSize,
+			}
+		}
+
+		decoded, err := decode(context.Background(), fixture.Ciphertext, fixture.Password, config)
+		if err != nil {
+			t.Fatalf("%s: Expected [err] to be nil received [%v]", fixture.Name, err.Error())
+		}
+
+		if decoded != fixture.Plaintext {
+			t.Fatalf("%s: Expected [%s] received [%s]", fixture.Name, fixture.Plaintext, decoded)
+		}
+
+		versions[fixture.Version]++
+	}
+
+	for _, version := range []string{ENCRYPTION_VERSION_V1, ENCRYPTION_VERSION_V2} {
+		if versions[version] == 0 {
+			t.Fatalf("Expected fixtures for encryption version [%s]", version)
+		}
+	}
+}
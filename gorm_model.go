@@ -1,6 +1,10 @@
 package vaultstore
 
-import "github.com/dromara/carbon/v2"
+import (
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+)
 
 // gormVaultRecord is the internal GORM model for vault records
 // This struct is used internally for database operations only
@@ -12,6 +16,9 @@ type gormVaultRecord struct {
 	UpdatedAt     string `gorm:"type:datetime;column:updated_at;not null"`
 	ExpiresAt     string `gorm:"type:datetime;column:expires_at;not null"`
 	SoftDeletedAt string `gorm:"type:datetime;column:soft_deleted_at;not null"`
+
+	// Extra holds the custom columns of records created by a RecordFactory
+	Extra map[string]string `gorm:"-"`
 }
 
 // TableName returns the table name for the GORM model
@@ -21,26 +28,7 @@ func (gormVaultRecord) TableName() string {
 
 // toRecordInterface converts a GORM record to a RecordInterface
 func (g *gormVaultRecord) toRecordInterface() RecordInterface {
-	// Set defaults for empty datetime fields to ensure NOT NULL constraint compliance
-	createdAt := g.CreatedAt
-	if createdAt == "" {
-		createdAt = carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
-	}
-
-	updatedAt := g.UpdatedAt
-	if updatedAt == "" {
-		updatedAt = carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
-	}
-
-	expiresAt := g.ExpiresAt
-	if expiresAt == "" {
-		expiresAt = MAX_DATETIME
-	}
-
-	softDeletedAt := g.SoftDeletedAt
-	if softDeletedAt == "" {
-		softDeletedAt = MAX_DATETIME
-	}
+	createdAt, updatedAt, expiresAt, softDeletedAt := g.datetimes()
 
 	data := map[string]string{
 		COLUMN_ID:              g.ID,
@@ -51,9 +39,74 @@ func (g *gormVaultRecord) toRecordInterface() RecordInterface {
 		COLUMN_EXPIRES_AT:      expiresAt,
 		COLUMN_SOFT_DELETED_AT: softDeletedAt,
 	}
+	for column, value := range g.Extra {
+		data[column] = value
+	}
 	return NewRecordFromExistingData(data)
 }
 
+// toFactoryRecord converts a GORM record to a record of the factory, including the custom columns
+func (g *gormVaultRecord) toFactoryRecord(factory RecordFactory) RecordInterface {
+	createdAt, updatedAt, expiresAt, softDeletedAt := g.datetimes()
+
+	data := make(map[string]string, len(g.Extra)+7)
+	for column, value := range g.Extra {
+		data[column] = value
+	}
+
+	data[COLUMN_ID] = g.ID
+	data[COLUMN_VAULT_TOKEN] = g.Token
+	data[COLUMN_VAULT_VALUE] = g.Value
+	data[COLUMN_CREATED_AT] = createdAt
+	data[COLUMN_UPDATED_AT] = updatedAt
+	data[COLUMN_EXPIRES_AT] = expiresAt
+	data[COLUMN_SOFT_DELETED_AT] = softDeletedAt
+
+	return factory.NewRecordFromExistingData(data)
+}
+
+// toFastRecord converts a GORM record to a struct-backed RecordInterface
+func (g *gormVaultRecord) toFastRecord() RecordInterface {
+	createdAt, updatedAt, expiresAt, softDeletedAt := g.datetimes()
+
+	return &fastRecordImplementation{
+		id:            g.ID,
+		token:         g.Token,
+		value:         g.Value,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+		expiresAt:     expiresAt,
+		softDeletedAt: softDeletedAt,
+		extra:         g.Extra,
+	}
+}
+
+// datetimes returns the datetime fields of the record with defaults for empty values
+func (g *gormVaultRecord) datetimes() (createdAt, updatedAt, expiresAt, softDeletedAt string) {
+	// Set defaults for empty datetime fields to ensure NOT NULL constraint compliance
+	createdAt = g.CreatedAt
+	if createdAt == "" {
+		createdAt = carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	}
+
+	updatedAt = g.UpdatedAt
+	if updatedAt == "" {
+		updatedAt = carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	}
+
+	return createdAt, updatedAt, internMaxDatetime(g.ExpiresAt), internMaxDatetime(g.SoftDeletedAt)
+}
+
+// internMaxDatetime returns the MAX_DATETIME constant for empty and "never" datetimes.
+// Most records never expire, so sharing the constant saves an allocation per
+// record and lets expiry checks skip date parsing.
+func internMaxDatetime(datetime string) string {
+	if datetime == "" || datetime == MAX_DATETIME || strings.HasPrefix(datetime, "9999-12-31") {
+		return MAX_DATETIME
+	}
+	return datetime
+}
+
 // fromRecordInterface creates a GORM record from a RecordInterface
 func fromRecordInterface(r RecordInterface) *gormVaultRecord {
 	return &gormVaultRecord{
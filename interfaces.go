@@ -2,6 +2,7 @@ package vaultstore
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -44,6 +45,11 @@ type RecordInterface interface {
 	SetUpdatedAt(updatedAt string) RecordInterface
 	// SetValue sets the record value
 	SetValue(value string) RecordInterface
+
+	// GetExtra returns the value of a custom column, see NewStoreOptions.ExtraColumns
+	GetExtra(key string) string
+	// SetExtra sets the value of a custom column
+	SetExtra(key string, value string) RecordInterface
 }
 
 // MetaInterface defines the methods that a VaultMeta must implement.
@@ -161,10 +167,51 @@ type RecordQueryInterface interface {
 	GetSoftDeletedInclude() bool
 	// SetSoftDeletedInclude sets the soft deleted include flag
 	SetSoftDeletedInclude(softDeletedInclude bool) RecordQueryInterface
+
+	// IsSoftDeletedOnlySet returns true if soft deleted only is set
+	IsSoftDeletedOnlySet() bool
+	// GetSoftDeletedOnly returns the soft deleted only flag
+	GetSoftDeletedOnly() bool
+	// SetSoftDeletedOnly limits the query to soft deleted records
+	SetSoftDeletedOnly(softDeletedOnly bool) RecordQueryInterface
+
+	// IsCreatedAtBeforeSet returns true if created at before filter is set
+	IsCreatedAtBeforeSet() bool
+	// GetCreatedAtBefore returns the created at before filter
+	GetCreatedAtBefore() string
+	// SetCreatedAtBefore filters records created before the datetime (YYYY-MM-DD HH:MM:SS, UTC)
+	SetCreatedAtBefore(createdAtBefore string) RecordQueryInterface
+
+	// IsExtraEqualsSet returns true if a custom column filter is set
+	IsExtraEqualsSet() bool
+	// GetExtraEquals returns the custom column filters
+	GetExtraEquals() map[string]string
+	// SetExtraEquals filters records whose custom column equals the value, can be repeated
+	SetExtraEquals(key string, value string) RecordQueryInterface
+
+	// IsMetaInSet returns true if a token meta filter is set
+	IsMetaInSet() bool
+	// GetMetaIn returns the token meta filters, the accepted values by key
+	GetMetaIn() map[string][]string
+	// SetMetaEquals filters records tagged with the key and value (see TokenMetaSet), can be repeated
+	SetMetaEquals(key string, value string) RecordQueryInterface
+	// SetMetaIn filters records tagged with the key and one of the values, can be repeated
+	SetMetaIn(key string, values []string) RecordQueryInterface
+
+	// IsExpiredExcludeSet returns true if expired exclude is set
+	IsExpiredExcludeSet() bool
+	// GetExpiredExclude returns the expired exclude flag
+	GetExpiredExclude() bool
+	// SetExpiredExclude excludes the expired records
+	SetExpiredExclude(expiredExclude bool) RecordQueryInterface
+
+	// Clone returns a copy of the query, changing the copy leaves the query unchanged
+	Clone() RecordQueryInterface
 }
 
 // StoreInterface defines the main interface for vault store operations.
-// It provides methods for record management, token operations, and vault configuration.
+// It is the union of the segregated store interfaces, consumers and mocks
+// can depend on only the part they use.
 //
 // The store supports:
 // - Record CRUD operations with soft delete support
@@ -172,22 +219,106 @@ type RecordQueryInterface interface {
 // - Bulk token operations for improved performance
 // - Vault settings and metadata management
 type StoreInterface interface {
+	MaintenanceInterface
+	RecordStoreInterface
+	SettingsStoreInterface
+	TokenStoreInterface
+}
+
+// MaintenanceInterface defines the schema, configuration and housekeeping operations of the store
+type MaintenanceInterface interface {
 	// AutoMigrate automatically migrates the database schema
 	AutoMigrate() error
+	// MigrationRollbackScript returns the SQL script restoring the tables backed up before the last migration
+	MigrationRollbackScript(ctx context.Context) (string, error)
+	// MetaEncryptionMigrate encrypts the existing sensitive meta values with the meta encryption key
+	MetaEncryptionMigrate(ctx context.Context) (count int64, err error)
+	// ArchiveExpired exports the expired tokens to an encrypted archive, then removes them
+	ArchiveExpired(ctx context.Context, w io.Writer) (count int64, err error)
+	// ArchiveRead decrypts an archive written by ArchiveExpired, batch by batch
+	ArchiveRead(ctx context.Context, r io.Reader, fn func(batch ChangeBatch) error) error
+	// Export writes a portable encrypted dump of the records and meta, restored with Import
+	Export(ctx context.Context, w io.Writer, opts ExportOptions) (ExportResult, error)
+	// Import restores a dump written by Export, skipping conflicting records
+	Import(ctx context.Context, r io.Reader, opts ImportOptions) (ImportResult, error)
+	// CopyTo streams all records into another store, optionally re-encrypting them with a new password
+	CopyTo(ctx context.Context, dest StoreInterface, opts CopyOptions) (CopyResult, error)
+	// BreakGlassSetup splits a new break-glass secret into n admin shares with a k threshold
+	BreakGlassSetup(ctx context.Context, shares int, threshold int) ([]string, error)
+	// BreakGlassGrant enables reading high-security tokens for a duration, given k admin shares
+	BreakGlassGrant(ctx context.Context, adminShares []string, duration time.Duration) error
+	// BreakGlassRevoke ends the active break-glass grant
+	BreakGlassRevoke(ctx context.Context) error
+	// AutoMigrateTableSuffix migrates the vault table for a suffix used with WithTableSuffix
+	AutoMigrateTableSuffix(suffix string) error
 	// EnableDebug enables or disables debug mode
 	EnableDebug(debug bool)
-
+	// Reconfigure changes runtime settings (debug, logger, cache sizes, intervals) while the store is in use
+	Reconfigure(opts ReconfigureOptions) error
 	// GetDbDriverName returns the database driver name
 	GetDbDriverName() string
 	// GetVaultTableName returns the vault table name
 	GetVaultTableName() string
 	// GetMetaTableName returns the meta table name
 	GetMetaTableName() string
+	// TokensExpiredSoftDelete soft deletes all expired tokens
+	TokensExpiredSoftDelete(ctx context.Context) (count int64, err error)
+	// TokensConsumedDelete permanently deletes the consumed and expired limited-use tokens
+	TokensConsumedDelete(ctx context.Context) (count int64, err error)
+	// LimitedUseStats counts the limited-use tokens by state
+	LimitedUseStats(ctx context.Context) (LimitedUseStats, error)
+	// TokensExpiredDelete permanently deletes all expired tokens
+	TokensExpiredDelete(ctx context.Context) (count int64, err error)
+	// TokenChangePassword changes the password of a single token
+	TokenChangePassword(ctx context.Context, token string, oldPassword, newPassword string) error
+	// TokensChangePassword changes the password for all tokens
+	TokensChangePassword(ctx context.Context, oldPassword, newPassword string) (int, error)
+	// QuotaCheck measures usage against the configured quota thresholds and emits quota events
+	QuotaCheck(ctx context.Context) (QuotaReport, error)
+	// ValueChunksGarbageCollect deletes value chunks no longer referenced by a record
+	ValueChunksGarbageCollect(ctx context.Context) (int64, error)
+	// LoadPolicy reads, persists and enforces a JSON policy document
+	LoadPolicy(ctx context.Context, r io.Reader) error
+	// PolicyReload loads the policy persisted in the vault settings
+	PolicyReload(ctx context.Context) error
+	// GetPolicy returns the enforced policy, or nil if none was loaded
+	GetPolicy() *Policy
+	// ChangesSince returns the records changed after the cursor, for replicating the store
+	ChangesSince(ctx context.Context, cursor string) (ChangeBatch, error)
+	// ApplyChanges applies changes of another store, skipping conflicting ones
+	ApplyChanges(ctx context.Context, batch ChangeBatch) (ApplyResult, error)
+	// NormalizeTimestamps rewrites the record timestamps written in other formats to UTC YYYY-MM-DD HH:MM:SS
+	NormalizeTimestamps(ctx context.Context) (TimestampsNormalizeReport, error)
+	// KDFStats returns the count and timings of the key derivations per operation type
+	KDFStats() map[string]KDFStat
+	// Redact returns the redacted identifier of a token, safe to log
+	Redact(token string) string
+	// Preflight verifies the tables, indexes, vault version and crypto settings, intended to run at boot
+	Preflight(ctx context.Context) (PreflightReport, error)
+	// MigrateEncryptionV1ToV2 re-encrypts the legacy v1 values of a password with the current format
+	MigrateEncryptionV1ToV2(ctx context.Context, password string, opts MigrateOptions) (count int, err error)
+	// EnvelopeRewrap rotates the envelope encryption key provider by rewrapping the data keys
+	EnvelopeRewrap(ctx context.Context, provider KeyProvider) (int64, error)
+	// Scheduler returns a scheduler running the built-in maintenance jobs and custom jobs on cron schedules
+	Scheduler(options ...SchedulerOptions) (*Scheduler, error)
+	// StartExpirationWorker removes the expired tokens on an interval until the context is done or the worker is stopped
+	StartExpirationWorker(ctx context.Context, options ExpirationWorkerOptions) (*ExpirationWorker, error)
+	// VerifyRestore samples records and checks they can be read and decrypted
+	VerifyRestore(ctx context.Context, samplePercent float64, password string) (VerifyRestoreReport, error)
+}
 
+// RecordStoreInterface defines the low level operations on the (encrypted) vault records
+type RecordStoreInterface interface {
 	// RecordCount returns the count of records matching the query
 	RecordCount(ctx context.Context, query RecordQueryInterface) (int64, error)
+	// RecordCountEstimate returns an estimate of the number of rows from the database statistics
+	RecordCountEstimate(ctx context.Context) (int64, error)
+	// RecordSizeHistogram reports the distribution of the ciphertext sizes of the records, without decrypting them
+	RecordSizeHistogram(ctx context.Context) (RecordSizeReport, error)
 	// RecordCreate creates a new record
 	RecordCreate(ctx context.Context, record RecordInterface) error
+	// RecordCreateMany creates multiple records using multi-row INSERT statements
+	RecordCreateMany(ctx context.Context, records []RecordInterface) error
 	// RecordDeleteByID deletes a record by its ID
 	RecordDeleteByID(ctx context.Context, recordID string) error
 	// RecordDeleteByToken deletes a record by its token
@@ -196,55 +327,167 @@ type StoreInterface interface {
 	RecordFindByID(ctx context.Context, recordID string) (RecordInterface, error)
 	// RecordFindByToken finds a record by its token
 	RecordFindByToken(ctx context.Context, token string) (RecordInterface, error)
+	// RecordImportCiphertext stores a value encrypted offline under the token, without the plaintext or password
+	RecordImportCiphertext(ctx context.Context, token string, ciphertext string, options ...TokenCreateOptions) error
 	// RecordList returns a list of records matching the query
 	RecordList(ctx context.Context, query RecordQueryInterface) ([]RecordInterface, error)
+	// RecordListStream calls the function for each record matching the query, one row at a time
+	RecordListStream(ctx context.Context, query RecordQueryInterface, fn func(RecordInterface) error) error
 	// RecordSoftDelete soft deletes a record
 	RecordSoftDelete(ctx context.Context, record RecordInterface) error
 	// RecordSoftDeleteByID soft deletes a record by its ID
 	RecordSoftDeleteByID(ctx context.Context, recordID string) error
 	// RecordSoftDeleteByToken soft deletes a record by its token
 	RecordSoftDeleteByToken(ctx context.Context, token string) error
+	// RecordRestore undoes the soft delete of a record
+	RecordRestore(ctx context.Context, record RecordInterface) error
+	// RecordRestoreByID undoes the soft delete of a record by its ID
+	RecordRestoreByID(ctx context.Context, recordID string) error
+	// RecordsSoftDeletedPurge permanently deletes the records soft deleted longer ago than the retention window
+	RecordsSoftDeletedPurge(ctx context.Context, olderThan time.Duration) (count int64, err error)
 	// RecordUpdate updates an existing record
 	RecordUpdate(ctx context.Context, record RecordInterface) error
+	// RecordUpdateByToken updates the columns of a record by token with a single UPDATE
+	RecordUpdateByToken(ctx context.Context, token string, updates map[string]string) error
+}
+
+// SettingsStoreInterface defines the vault settings operations
+type SettingsStoreInterface interface {
+	// GetVaultSetting gets a vault setting value
+	GetVaultSetting(ctx context.Context, key string) (string, error)
+	// SetVaultSetting sets a vault setting value
+	SetVaultSetting(ctx context.Context, key, value string) error
+	// GetVaultVersion gets the persisted vault format version
+	GetVaultVersion(ctx context.Context) (string, error)
+	// SetVaultVersion sets the persisted vault format version
+	SetVaultVersion(ctx context.Context, version string) error
+	// FeatureEnable enables a feature flag persisted in the vault settings
+	FeatureEnable(ctx context.Context, name string) error
+	// FeatureDisable disables a feature flag persisted in the vault settings
+	FeatureDisable(ctx context.Context, name string) error
+	// FeatureIsEnabled reports whether a feature flag is enabled
+	FeatureIsEnabled(ctx context.Context, name string) (bool, error)
+}
 
+// TokenStoreInterface defines the token operations, encrypting and decrypting values with a password
+type TokenStoreInterface interface {
 	// TokenCreate creates a new token and returns the token string
 	TokenCreate(ctx context.Context, value string, password string, tokenLength int, options ...TokenCreateOptions) (token string, err error)
+	// TokenCreateBytes creates a new token holding a binary value
+	TokenCreateBytes(ctx context.Context, value []byte, password string, tokenLength int, options ...TokenCreateOptions) (token string, err error)
+	// TokenCreateBatch creates a token for each value in a single transaction
+	TokenCreateBatch(ctx context.Context, values []string, password string, tokenLength int, options ...TokenCreateOptions) ([]string, error)
 	// TokenCreateCustom creates a new token with a custom token string
 	TokenCreateCustom(ctx context.Context, token string, value string, password string, options ...TokenCreateOptions) (err error)
+	// TokenCreateCustomBytes creates a new token with a custom token string holding a binary value
+	TokenCreateCustomBytes(ctx context.Context, token string, value []byte, password string, options ...TokenCreateOptions) error
+	// TokenAliasAdd attaches a human-friendly alias, e.g. "prod/stripe/api-key", to a token
+	TokenAliasAdd(ctx context.Context, token string, alias string) error
+	// TokenAliasRemove detaches an alias from its token
+	TokenAliasRemove(ctx context.Context, alias string) error
+	// TokenAliasResolve returns the token an alias points to
+	TokenAliasResolve(ctx context.Context, alias string) (string, error)
+	// TokenAppend appends an encrypted chunk to a token without rewriting its value
+	TokenAppend(ctx context.Context, token string, chunk string, password string) error
+	// TokenCreateFromReader creates a token from a reader, encrypting it chunk by chunk, for large values
+	TokenCreateFromReader(ctx context.Context, r io.Reader, password string, tokenLength int, options ...TokenCreateOptions) (string, error)
+	// TokenCompareAndSwap updates the value of a token only if it currently holds the expected value
+	TokenCompareAndSwap(ctx context.Context, token string, expectedValue string, newValue string, password string) error
 	// TokenDelete deletes a token
 	TokenDelete(ctx context.Context, token string) error
 	// TokenExists checks if a token exists
 	TokenExists(ctx context.Context, token string) (bool, error)
+	// TokenList lists the tokens matching the options a page at a time, without their values
+	TokenList(ctx context.Context, options TokenQueryOptions) ([]TokenListItem, error)
+	// TokenListByPrefix lists the children of a path-style token prefix, e.g. "app/prod/"
+	TokenListByPrefix(ctx context.Context, prefix string) ([]string, error)
+	// TokenMetaSet tags a token with a key/value pair, e.g. owner or environment
+	TokenMetaSet(ctx context.Context, token string, key string, value string) error
+	// TokenMetaGet returns the value of a tag of a token
+	TokenMetaGet(ctx context.Context, token string, key string) (string, error)
+	// TokenMetaList returns all the tags of a token
+	TokenMetaList(ctx context.Context, token string) (map[string]string, error)
+	// TokenMetaDelete removes a tag of a token
+	TokenMetaDelete(ctx context.Context, token string, key string) error
+	// ReadThrough reads a token, caching the value and sharing concurrent reads of the same token
+	ReadThrough(ctx context.Context, token string, password string) (string, error)
 	// TokenRead reads the value of a token
 	TokenRead(ctx context.Context, token string, password string) (string, error)
+	// TokenReadBytes reads the value of a token as bytes
+	TokenReadBytes(ctx context.Context, token string, password string) ([]byte, error)
+	// TokenReadAndDelete reads the value of a token and permanently deletes it in one transaction, for one-time secrets
+	TokenReadAndDelete(ctx context.Context, token string, password string) (string, error)
+	// TokenReadAll reads the initial value of a token followed by all appended chunks
+	TokenReadAll(ctx context.Context, token string, password string) ([]string, error)
+	// TokenReadWithInfo reads a token value together with its info, such as the content type
+	TokenReadWithInfo(ctx context.Context, token string, password string) (string, TokenInfo, error)
+	// TokenReadToWriter writes the value of a token to a writer, decrypting streamed tokens chunk by chunk
+	TokenReadToWriter(ctx context.Context, token string, password string, w io.Writer) error
+	// TokenCreateMap creates a token holding several fields, encrypted as a single JSON document
+	TokenCreateMap(ctx context.Context, values map[string]string, password string, options ...TokenCreateOptions) (string, error)
+	// TokenReadMap reads all the fields of a map token
+	TokenReadMap(ctx context.Context, token string, password string) (map[string]string, error)
+	// TokenReadKey reads a single field of a map token
+	TokenReadKey(ctx context.Context, token string, field string, password string) (string, error)
+	// TokenPatchKey sets a single field of a map token, keeping the other fields
+	TokenPatchKey(ctx context.Context, token string, field string, value string, password string) error
+	// TokenReadMasked reads a map or JSON token with the values of the given fields masked
+	TokenReadMasked(ctx context.Context, token string, password string, maskFields []string) (string, error)
+	// TokenCheckout grants the holder exclusive access to a token for the ttl
+	TokenCheckout(ctx context.Context, token string, holder string, ttl time.Duration) (TokenLease, error)
+	// TokenCheckin releases the checkout of a token by the holder
+	TokenCheckin(ctx context.Context, token string, holder string) error
+	// TokenClone copies the decrypted value of a token into a new token
+	TokenClone(ctx context.Context, srcToken string, password string, opts TokenCloneOptions) (string, error)
 	// TokenRenew renews a token with a new expiration time
 	TokenRenew(ctx context.Context, token string, expiresAt time.Time) error
-	// TokensExpiredSoftDelete soft deletes all expired tokens
-	TokensExpiredSoftDelete(ctx context.Context) (count int64, err error)
-	// TokensExpiredDelete permanently deletes all expired tokens
-	TokensExpiredDelete(ctx context.Context) (count int64, err error)
+	// TokenRestore undoes the soft delete of a token
+	TokenRestore(ctx context.Context, token string) error
+	// TokenRevoke revokes a token, keeping the record for audit; reads return ErrTokenRevoked
+	TokenRevoke(ctx context.Context, token string, reason string) error
+	// TokenUnrevoke removes the revocation of a token
+	TokenUnrevoke(ctx context.Context, token string) error
+	// RevokedList returns the revoked tokens with their reasons
+	RevokedList(ctx context.Context) ([]TokenRevocation, error)
+	// TokenBreakGlassRequire designates a token as high-security, readable only under a break-glass grant
+	TokenBreakGlassRequire(ctx context.Context, token string, required bool) error
+	// TokenVersions returns the version numbers of a token, the last one is the current value
+	TokenVersions(ctx context.Context, token string) ([]int, error)
+	// TokenDiff compares two versions of a token value, listing the changed paths of JSON values
+	TokenDiff(ctx context.Context, token string, versionA int, versionB int, password string) (TokenDiffResult, error)
+	// TokenQuarantine isolates a token for investigation; reads return ErrTokenQuarantined
+	TokenQuarantine(ctx context.Context, token string, reason string) error
+	// QuarantinedList returns the quarantined tokens with their reasons
+	QuarantinedList(ctx context.Context) ([]QuarantinedToken, error)
+	// TokenRepair replaces the ciphertext of a quarantined token and lifts the quarantine
+	TokenRepair(ctx context.Context, token string, newCiphertext string) error
 	// TokenSoftDelete soft deletes a token
 	TokenSoftDelete(ctx context.Context, token string) error
 	// TokenUpdate updates the value of a token
 	TokenUpdate(ctx context.Context, token string, value string, password string) error
+	// TokenUpdateBytes updates the value of a token with a binary value
+	TokenUpdateBytes(ctx context.Context, token string, value []byte, password string) error
 	// TokenUpsert updates or creates a token for a given value
 	TokenUpsert(ctx context.Context, existingToken string, value string, password string) (newToken string, err error)
-
+	// TokensCountByPrefix counts the tokens starting with the prefix
+	TokensCountByPrefix(ctx context.Context, prefix string) (int64, error)
+	// TokensDeleteByPrefix deletes the tokens starting with the prefix, only counting them unless confirmed
+	TokensDeleteByPrefix(ctx context.Context, prefix string, options ...TokensDeleteByPrefixOptions) (int64, error)
+	// TokensFindByMeta returns the tokens tagged with the key and value
+	TokensFindByMeta(ctx context.Context, key string, value string) ([]string, error)
+	// TokensExpireWhere sets the expiration of every token matching the query in a single UPDATE
+	TokensExpireWhere(ctx context.Context, query RecordQueryInterface, expiresAt time.Time) (count int64, err error)
 	// TokensRead reads multiple tokens at once with a single database query
 	// This is more efficient than calling TokenRead multiple times
 	TokensRead(ctx context.Context, tokens []string, password string) (map[string]string, error)
-
-	// Token-based password management
-	// TokensChangePassword changes the password for all tokens
-	TokensChangePassword(ctx context.Context, oldPassword, newPassword string) (int, error)
-
+	// TokensReadBytes reads the values of multiple tokens as bytes
+	TokensReadBytes(ctx context.Context, tokens []string, password string) (map[string][]byte, error)
+	// TokensReadFunc reads multiple tokens and streams each decrypted value to the callback
+	// instead of building the full result map
+	TokensReadFunc(ctx context.Context, tokens []string, password string, fn func(token string, value string) error) error
+	// TokensReadWithInfo reads multiple tokens at once, returning the creation and expiration time with each value
+	TokensReadWithInfo(ctx context.Context, tokens []string, password string) (map[string]TokenValueInfo, error)
 	// TokensReadToResolvedMap accepts a map of key token pairs and returns a map of key value pairs
 	// This is a convenience method that combines TokensRead and MapValues
 	TokensReadToResolvedMap(ctx context.Context, keyTokenMap map[string]string, password string) (map[string]string, error)
-
-	// Vault settings
-	// GetVaultSetting gets a vault setting value
-	GetVaultSetting(ctx context.Context, key string) (string, error)
-	// SetVaultSetting sets a vault setting value
-	SetVaultSetting(ctx context.Context, key, value string) error
 }
@@ -0,0 +1,86 @@
+package vaultstore
+
+import (
+	"sync"
+	"time"
+)
+
+// KDF operation types, see KDFStats
+const (
+	KDF_OPERATION_ENCRYPT = "encrypt"
+	KDF_OPERATION_DECRYPT = "decrypt"
+)
+
+// KDFStat summarizes the Argon2id key derivations of an operation type
+type KDFStat struct {
+	// Count is the number of key derivations
+	Count int64
+	// Total is the time spent deriving keys
+	Total time.Duration
+	// Average is the mean derivation time
+	Average time.Duration
+	// Max is the slowest derivation
+	Max time.Duration
+}
+
+// kdfStats collects the key derivation timings of a store
+type kdfStats struct {
+	mu          sync.Mutex
+	stats       map[string]KDFStat
+	observeFunc func(operation string, duration time.Duration)
+}
+
+// newKDFStats creates the key derivation timings, forwarded to observeFunc if set
+func newKDFStats(observeFunc func(operation string, duration time.Duration)) *kdfStats {
+	return &kdfStats{
+		stats:       map[string]KDFStat{},
+		observeFunc: observeFunc,
+	}
+}
+
+// kdfRecord records a key derivation, configs not owned by a store are not measured
+func (config *CryptoConfig) kdfRecord(operation string, duration time.Duration) {
+	if config == nil || config.kdfStats == nil {
+		return
+	}
+
+	s := config.kdfStats
+
+	s.mu.Lock()
+	stat := s.stats[operation]
+	stat.Count++
+	stat.Total += duration
+	stat.Average = stat.Total / time.Duration(stat.Count)
+	stat.Max = max(stat.Max, duration)
+	s.stats[operation] = stat
+	s.mu.Unlock()
+
+	if s.observeFunc != nil {
+		s.observeFunc(operation, duration)
+	}
+
+	config.metricsCollector().KDFDurationObserve(operation, duration)
+}
+
+// KDFStats returns the count and timings of the Argon2id key derivations since the store
+// was created, keyed by operation type (KDF_OPERATION_ENCRYPT, KDF_OPERATION_DECRYPT).
+// An average close to the latency budget means the CryptoConfig is too slow.
+//
+// Returns:
+// - map[string]KDFStat: The statistics per operation type
+func (store *storeImplementation) KDFStats() map[string]KDFStat {
+	s := store.cryptoConfig.kdfStats
+	if s == nil {
+		return map[string]KDFStat{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]KDFStat, len(s.stats))
+	for operation, stat := range s.stats {
+		stats[operation] = stat
+	}
+
+	return stats
+}
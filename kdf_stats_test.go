@@ -0,0 +1,73 @@
+package vaultstore
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Store_KDFStats(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var observed atomic.Int64
+	config := DefaultCryptoConfig()
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_kdf_stats",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		CryptoConfig:       config,
+		KDFObserveFunc: func(operation string, duration time.Duration) {
+			observed.Add(1)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(store.KDFStats()) != 0 {
+		t.Fatalf("Expected no statistics received [%v]", store.KDFStats())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for range 2 {
+		if _, err := store.TokenRead(ctx, token, password); err != nil {
+			t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	stats := store.KDFStats()
+
+	if stats[KDF_OPERATION_ENCRYPT].Count != 1 {
+		t.Fatalf("Expected 1 encryption received [%v]", stats[KDF_OPERATION_ENCRYPT].Count)
+	}
+
+	decrypt := stats[KDF_OPERATION_DECRYPT]
+	if decrypt.Count != 2 {
+		t.Fatalf("Expected 2 decryptions received [%v]", decrypt.Count)
+	}
+
+	if decrypt.Average <= 0 || decrypt.Average > decrypt.Max || decrypt.Total < decrypt.Max {
+		t.Fatalf("Expected consistent timings received [%+v]", decrypt)
+	}
+
+	if observed.Load() != 3 {
+		t.Fatalf("Expected 3 observed derivations received [%v]", observed.Load())
+	}
+
+	if config.kdfStats != nil {
+		t.Fatal("Expected the caller's CryptoConfig to be left unchanged")
+	}
+}
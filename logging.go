@@ -0,0 +1,188 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// LOG_REDACTED replaces the secrets in the log attributes
+const LOG_REDACTED = "[redacted]"
+
+// logKeysSecret are the log attribute keys whose value is never written
+var logKeysSecret = map[string]bool{
+	"value":        true,
+	"values":       true,
+	"password":     true,
+	"old_password": true,
+	"new_password": true,
+	"plaintext":    true,
+}
+
+// logKeysToken are the log attribute keys whose tokens are written redacted, see Redactor
+var logKeysToken = map[string]bool{
+	"token":  true,
+	"tokens": true,
+}
+
+// logEnabled reports whether the store logs at the level: at NewStoreOptions.LogLevel and
+// above, and at debug when debug is enabled
+func (store *storeImplementation) logEnabled(level slog.Level) bool {
+	if store.logger.Load() == nil {
+		return false
+	}
+
+	minLevel := store.logLevel.Level()
+	if store.debugEnabled.Load() {
+		minLevel = min(minLevel, slog.LevelDebug)
+	}
+
+	return level >= minLevel
+}
+
+// log writes a message to the logger, if any, with the secrets of the attributes redacted
+func (store *storeImplementation) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if !store.logEnabled(level) {
+		return
+	}
+
+	store.logger.Load().Log(ctx, level, msg, store.logRedact(args)...)
+}
+
+// logDebug writes a debug message, see log
+func (store *storeImplementation) logDebug(ctx context.Context, msg string, args ...any) {
+	store.log(ctx, slog.LevelDebug, msg, args...)
+}
+
+// logInfo writes an info message, see log
+func (store *storeImplementation) logInfo(ctx context.Context, msg string, args ...any) {
+	store.log(ctx, slog.LevelInfo, msg, args...)
+}
+
+// logError writes an error message, see log
+func (store *storeImplementation) logError(ctx context.Context, msg string, args ...any) {
+	store.log(ctx, slog.LevelError, msg, args...)
+}
+
+// logJanitorRun writes the run of a maintenance job, at info level when it removed or
+// changed records and at debug level otherwise, so that idle runs do not flood the logs
+func (store *storeImplementation) logJanitorRun(ctx context.Context, msg string, count int64, args ...any) {
+	level := slog.LevelDebug
+	if count > 0 {
+		level = slog.LevelInfo
+	}
+
+	store.log(ctx, level, msg, append([]any{"count", count}, args...)...)
+}
+
+// logRedact returns the log arguments, key-value pairs or slog.Attr, with the values of
+// the secret keys replaced and the tokens redacted. It is the guarantee that a message
+// of the store never holds a value or a password, whatever its call site passes.
+func (store *storeImplementation) logRedact(args []any) []any {
+	redacted := make([]any, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case slog.Attr:
+			redacted = append(redacted, slog.Any(arg.Key, store.logRedactValue(arg.Key, arg.Value.Any())))
+		case string:
+			if i+1 == len(args) {
+				redacted = append(redacted, arg)
+				continue
+			}
+			redacted = append(redacted, arg, store.logRedactValue(arg, args[i+1]))
+			i++
+		default:
+			redacted = append(redacted, arg)
+		}
+	}
+
+	return redacted
+}
+
+// logRedactValue returns the value of a log attribute, redacted if its key is sensitive
+func (store *storeImplementation) logRedactValue(key string, value any) any {
+	if logKeysSecret[key] {
+		return LOG_REDACTED
+	}
+
+	if !logKeysToken[key] {
+		return value
+	}
+
+	switch tokens := value.(type) {
+	case string:
+		return store.redactor.Redact(tokens)
+	case []string:
+		redacted := make([]string, len(tokens))
+		for i, token := range tokens {
+			redacted[i] = store.redactor.Redact(token)
+		}
+		return redacted
+	default:
+		return store.redactor.Redact(fmt.Sprint(value))
+	}
+}
+
+// gormLogger writes the GORM output to the logger of the store: the queries at debug level,
+// without their parameters, and the GORM warnings and errors
+type gormLogger struct {
+	store *storeImplementation
+}
+
+var _ logger.Interface = (*gormLogger)(nil)
+var _ gorm.ParamsFilter = (*gormLogger)(nil)
+
+// LogMode is ignored, the level is the one of the store
+func (gormLogger *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return gormLogger
+}
+
+// Info writes a GORM message at debug level
+func (gormLogger *gormLogger) Info(ctx context.Context, msg string, data ...any) {
+	gormLogger.write(ctx, slog.LevelDebug, msg, data...)
+}
+
+// Warn writes a GORM warning
+func (gormLogger *gormLogger) Warn(ctx context.Context, msg string, data ...any) {
+	gormLogger.write(ctx, slog.LevelWarn, msg, data...)
+}
+
+// Error writes a GORM error
+func (gormLogger *gormLogger) Error(ctx context.Context, msg string, data ...any) {
+	gormLogger.write(ctx, slog.LevelError, msg, data...)
+}
+
+// Trace writes a query at debug level. The errors of the database are not written, they
+// can hold tokens, and are returned by the store method anyway.
+func (gormLogger *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	store := gormLogger.store
+	if store == nil || !store.logEnabled(slog.LevelDebug) {
+		return
+	}
+
+	sql, rowsAffected := fc()
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+
+	store.logDebug(ctx, "query", "sql", sql, "rows", rowsAffected, "duration", time.Since(begin), "failed", failed)
+}
+
+// ParamsFilter drops the parameters of the queries, which hold tokens and ciphertexts,
+// so the logged SQL keeps its placeholders
+func (gormLogger *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
+
+// write writes a GORM message at the level
+func (gormLogger *gormLogger) write(ctx context.Context, level slog.Level, msg string, data ...any) {
+	if gormLogger.store == nil {
+		return
+	}
+
+	gormLogger.store.log(ctx, level, "gorm: "+fmt.Sprintf(msg, data...))
+}
@@ -0,0 +1,109 @@
+package vaultstore
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func Test_Store_logRedact(t *testing.T) {
+	store := &storeImplementation{}
+
+	var buffer bytes.Buffer
+	store.logger.Store(slog.New(slog.NewTextHandler(&buffer, nil)))
+
+	store.logInfo(context.Background(), "message",
+		"token", "tk_secret_token",
+		"tokens", []string{"tk_other_token"},
+		"password", "secret_password",
+		slog.String("value", "secret_value"),
+		"count", 3,
+	)
+
+	output := buffer.String()
+	for _, secret := range []string{"tk_secret_token", "tk_other_token", "secret_password", "secret_value"} {
+		if strings.Contains(output, secret) {
+			t.Fatalf("Expected [%s] to be redacted received [%v]", secret, output)
+		}
+	}
+
+	if !strings.Contains(output, Redact("tk_secret_token")) {
+		t.Fatalf("Expected the redacted token received [%v]", output)
+	}
+	if !strings.Contains(output, "count=3") {
+		t.Fatalf("Expected the other attributes as is received [%v]", output)
+	}
+}
+
+func Test_Store_logEnabled(t *testing.T) {
+	store := &storeImplementation{}
+
+	if store.logEnabled(slog.LevelError) {
+		t.Fatal("Expected no logs without a logger")
+	}
+
+	store.logger.Store(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	// Info by default, debug with debug enabled
+	if !store.logEnabled(slog.LevelInfo) || store.logEnabled(slog.LevelDebug) {
+		t.Fatal("Expected the info logs only by default")
+	}
+
+	store.debugEnabled.Store(true)
+	if !store.logEnabled(slog.LevelDebug) {
+		t.Fatal("Expected the debug logs with debug enabled")
+	}
+
+	store.debugEnabled.Store(false)
+	store.logLevel.Set(slog.LevelError)
+	if store.logEnabled(slog.LevelInfo) || !store.logEnabled(slog.LevelError) {
+		t.Fatal("Expected the error logs only at the error level")
+	}
+}
+
+func Test_Store_Logger_Queries(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var buffer bytes.Buffer
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_logged",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		DebugEnabled:       true,
+		Logger:             slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "logged_secret_value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenRead(ctx, token, password); err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	output := buffer.String()
+	if !strings.Contains(output, "msg=query") || !strings.Contains(output, "tables migrated") {
+		t.Fatalf("Expected the queries and the migration to be logged received [%v]", output)
+	}
+
+	// The query parameters, holding the token and the ciphertext, are not logged
+	for _, secret := range []string{token, "logged_secret_value", password, ENCRYPTION_PREFIX_V2} {
+		if strings.Contains(output, secret) {
+			t.Fatalf("Expected [%s] not to be logged received [%v]", secret, output)
+		}
+	}
+}
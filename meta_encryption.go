@@ -0,0 +1,169 @@
+package vaultstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// META_ENCRYPTION_PREFIX marks meta values encrypted with the store master key
+const META_ENCRYPTION_PREFIX = "m1:"
+
+// metaEncryptionKeyMinLength is the minimum length of NewStoreOptions.MetaEncryptionKey
+const metaEncryptionKeyMinLength = 32
+
+var (
+	// ErrMetaEncryptionKeyTooShort is returned by NewStore for a master key shorter than 32 characters
+	ErrMetaEncryptionKeyTooShort = errors.New("meta encryption key must be at least 32 characters")
+	// ErrMetaEncryptionKeyMissing is returned when reading an encrypted meta value without the master key
+	ErrMetaEncryptionKeyMissing = errors.New("meta value is encrypted but no meta encryption key is configured")
+)
+
+// metaEncryptedObjectTypes are the meta object types holding sensitive values,
+// encrypted when a meta encryption key is configured
+var metaEncryptedObjectTypes = []string{
+	OBJECT_TYPE_IDEMPOTENCY_KEY,
+	OBJECT_TYPE_PASSWORD_IDENTITY,
+	OBJECT_TYPE_TOKEN_ALIAS,
+	OBJECT_TYPE_VAULT_SETTINGS,
+}
+
+// newMetaAEAD derives the AES-256-GCM cipher of the meta values from the master key
+func newMetaAEAD(masterKey string) (cipher.AEAD, error) {
+	if len(masterKey) < metaEncryptionKeyMinLength {
+		return nil, ErrMetaEncryptionKeyTooShort
+	}
+
+	key, err := hkdf.Key(sha256.New, []byte(masterKey), nil, "vaultstore meta value", 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// isMetaEncrypted returns true if values of the object type and key are encrypted.
+// The vault version stays readable, so older binaries can still refuse the vault.
+func isMetaEncrypted(objectType, key string) bool {
+	if objectType == OBJECT_TYPE_VAULT_SETTINGS && key == META_KEY_VERSION {
+		return false
+	}
+
+	for _, encryptedObjectType := range metaEncryptedObjectTypes {
+		if objectType == encryptedObjectType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// metaValueEncrypt encrypts the value if the object type and key are sensitive
+// and a meta encryption key is configured
+func (store *storeImplementation) metaValueEncrypt(objectType, key, value string) (string, error) {
+	if store.metaAEAD == nil || !isMetaEncrypted(objectType, key) {
+		return value, nil
+	}
+
+	nonce := make([]byte, store.metaAEAD.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	// The object type and key are authenticated, so values cannot be swapped between rows
+	sealed := store.metaAEAD.Seal(nonce, nonce, []byte(value), []byte(objectType+"|"+key))
+	return META_ENCRYPTION_PREFIX + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// metaValueDecrypt decrypts the meta value in place. Values written before
+// the key was configured are returned as is.
+func (store *storeImplementation) metaValueDecrypt(meta *gormVaultMeta) error {
+	if !strings.HasPrefix(meta.Value, META_ENCRYPTION_PREFIX) {
+		return nil
+	}
+
+	if store.metaAEAD == nil {
+		return ErrMetaEncryptionKeyMissing
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(meta.Value, META_ENCRYPTION_PREFIX))
+	if err != nil || len(sealed) < store.metaAEAD.NonceSize() {
+		return fmt.Errorf("%w: malformed meta value", ErrDecryptionFailed)
+	}
+
+	nonceSize := store.metaAEAD.NonceSize()
+	plaintext, err := store.metaAEAD.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(meta.ObjectType+"|"+meta.Key))
+	if err != nil {
+		return fmt.Errorf("%w: meta value", ErrDecryptionFailed)
+	}
+
+	meta.Value = string(plaintext)
+	return nil
+}
+
+// MetaEncryptionMigrate encrypts the existing plaintext values of the sensitive meta
+// rows (vault settings, password identities, idempotency keys, token aliases) with the meta encryption key.
+// Run it once after configuring NewStoreOptions.MetaEncryptionKey, it is safe to run again.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - count: The number of encrypted rows
+// - err: An error if something went wrong
+func (store *storeImplementation) MetaEncryptionMigrate(ctx context.Context) (count int64, err error) {
+	ctx, span := store.traceStart(ctx, "MetaEncryptionMigrate")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "MetaEncryptionMigrate", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if store.metaAEAD == nil {
+		return 0, ErrMetaEncryptionKeyMissing
+	}
+
+	var metas []gormVaultMeta
+	err = store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" IN ?", metaEncryptedObjectTypes).
+		Where(COLUMN_META_VALUE+" NOT LIKE ?", META_ENCRYPTION_PREFIX+"%").
+		Find(&metas).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range metas {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		if !isMetaEncrypted(metas[i].ObjectType, metas[i].Key) {
+			continue
+		}
+
+		metas[i].Value, err = store.metaValueEncrypt(metas[i].ObjectType, metas[i].Key, metas[i].Value)
+		if err != nil {
+			return count, err
+		}
+
+		if err := store.metaDB(ctx).Save(&metas[i]).Error; err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
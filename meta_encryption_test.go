@@ -0,0 +1,117 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Store_MetaEncryption(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The stores below share the in memory database
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	masterKey := "meta_master_key_that_is_long_enough_32chars"
+
+	plainStore, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_meta_encryption",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := plainStore.SetVaultSetting(ctx, "old_setting", "written before encryption"); err != nil {
+		t.Fatalf("SetVaultSetting: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_meta_encryption",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		MetaEncryptionKey:  masterKey,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.SetVaultSetting(ctx, "new_setting", "sensitive"); err != nil {
+		t.Fatalf("SetVaultSetting: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	rawValue := func(key string) string {
+		var meta gormVaultMeta
+		err := store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_VAULT_SETTINGS, key).
+			First(&meta).Error
+		if err != nil {
+			t.Fatalf("Expected [err] to be nil received [%v]", err.Error())
+		}
+		return meta.Value
+	}
+
+	if !strings.HasPrefix(rawValue("new_setting"), META_ENCRYPTION_PREFIX) {
+		t.Fatalf("Expected the setting to be stored encrypted received [%v]", rawValue("new_setting"))
+	}
+
+	if rawValue(META_KEY_VERSION) != VAULT_VERSION_CURRENT {
+		t.Fatalf("Expected the vault version to stay readable received [%v]", rawValue(META_KEY_VERSION))
+	}
+
+	// Reads are transparent, plaintext rows stay readable
+	value, err := store.GetVaultSetting(ctx, "new_setting")
+	if err != nil || value != "sensitive" {
+		t.Fatalf("Expected [sensitive] received [%v] [%v]", value, err)
+	}
+
+	value, err = store.GetVaultSetting(ctx, "old_setting")
+	if err != nil || value != "written before encryption" {
+		t.Fatalf("Expected the plaintext setting received [%v] [%v]", value, err)
+	}
+
+	count, err := store.MetaEncryptionMigrate(ctx)
+	if err != nil {
+		t.Fatalf("MetaEncryptionMigrate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 || !strings.HasPrefix(rawValue("old_setting"), META_ENCRYPTION_PREFIX) {
+		t.Fatalf("Expected the plaintext setting to be migrated received [%v] [%v]", count, rawValue("old_setting"))
+	}
+
+	value, err = store.GetVaultSetting(ctx, "old_setting")
+	if err != nil || value != "written before encryption" {
+		t.Fatalf("Expected the migrated setting received [%v] [%v]", value, err)
+	}
+
+	// Without the key, encrypted values cannot be read
+	_, err = plainStore.GetVaultSetting(ctx, "new_setting")
+	if !errors.Is(err, ErrMetaEncryptionKeyMissing) {
+		t.Fatalf("Expected [ErrMetaEncryptionKeyMissing] received [%v]", err)
+	}
+}
+
+func Test_Store_MetaEncryptionKeyTooShort(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = NewStore(NewStoreOptions{
+		VaultTableName:     "vault_meta_encryption",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		MetaEncryptionKey:  "short",
+	})
+	if !errors.Is(err, ErrMetaEncryptionKeyTooShort) {
+		t.Fatalf("Expected [ErrMetaEncryptionKeyTooShort] received [%v]", err)
+	}
+}
@@ -0,0 +1,81 @@
+package vaultstore
+
+import (
+	"time"
+)
+
+// MetricsCollector receives the metrics of the store, see NewStoreOptions.MetricsCollector.
+// The methods are called synchronously, from concurrent operations, so they should be safe
+// for concurrent use and return quickly. The vaultprometheus package adapts it to Prometheus.
+type MetricsCollector interface {
+	// ReadsInc counts a value read, i.e. decrypted
+	ReadsInc()
+	// WritesInc counts a value written, i.e. encrypted
+	WritesInc()
+	// DecryptFailuresInc counts a value that failed to decrypt, e.g. read with a wrong password
+	DecryptFailuresInc()
+	// EncryptDurationObserve receives the time taken to encrypt a value, key derivation included
+	EncryptDurationObserve(duration time.Duration)
+	// DecryptDurationObserve receives the time taken to decrypt a value, key derivation included
+	DecryptDurationObserve(duration time.Duration)
+	// KDFDurationObserve receives the time taken by an Argon2id key derivation, by operation
+	// type (KDF_OPERATION_ENCRYPT, KDF_OPERATION_DECRYPT)
+	KDFDurationObserve(operation string, duration time.Duration)
+}
+
+// NoopMetricsCollector discards the metrics, the default of the store. It can be embedded
+// to implement only some of the MetricsCollector methods.
+type NoopMetricsCollector struct{}
+
+var _ MetricsCollector = NoopMetricsCollector{}
+
+// ReadsInc does nothing
+func (NoopMetricsCollector) ReadsInc() {}
+
+// WritesInc does nothing
+func (NoopMetricsCollector) WritesInc() {}
+
+// DecryptFailuresInc does nothing
+func (NoopMetricsCollector) DecryptFailuresInc() {}
+
+// EncryptDurationObserve does nothing
+func (NoopMetricsCollector) EncryptDurationObserve(duration time.Duration) {}
+
+// DecryptDurationObserve does nothing
+func (NoopMetricsCollector) DecryptDurationObserve(duration time.Duration) {}
+
+// KDFDurationObserve does nothing
+func (NoopMetricsCollector) KDFDurationObserve(operation string, duration time.Duration) {}
+
+// metricsCollector returns the metrics collector of the store owning the config, configs
+// not owned by a store are not measured
+func (config *CryptoConfig) metricsCollector() MetricsCollector {
+	if config == nil || config.metrics == nil {
+		return NoopMetricsCollector{}
+	}
+
+	return config.metrics
+}
+
+// metricsEncrypt records the encryption of a value
+func (config *CryptoConfig) metricsEncrypt(duration time.Duration, err error) {
+	if err != nil {
+		return
+	}
+
+	metrics := config.metricsCollector()
+	metrics.WritesInc()
+	metrics.EncryptDurationObserve(duration)
+}
+
+// metricsDecrypt records the decryption of a value
+func (config *CryptoConfig) metricsDecrypt(duration time.Duration, err error) {
+	metrics := config.metricsCollector()
+	if err != nil {
+		metrics.DecryptFailuresInc()
+		return
+	}
+
+	metrics.ReadsInc()
+	metrics.DecryptDurationObserve(duration)
+}
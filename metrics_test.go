@@ -0,0 +1,146 @@
+package vaultstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMetricsCollector counts the calls of each metric
+type recordingMetricsCollector struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (collector *recordingMetricsCollector) inc(name string) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if collector.counts == nil {
+		collector.counts = map[string]int{}
+	}
+	collector.counts[name]++
+}
+
+func (collector *recordingMetricsCollector) count(name string) int {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	return collector.counts[name]
+}
+
+func (collector *recordingMetricsCollector) ReadsInc()           { collector.inc("reads") }
+func (collector *recordingMetricsCollector) WritesInc()          { collector.inc("writes") }
+func (collector *recordingMetricsCollector) DecryptFailuresInc() { collector.inc("decrypt_failures") }
+
+func (collector *recordingMetricsCollector) EncryptDurationObserve(duration time.Duration) {
+	collector.inc("encrypt_duration")
+}
+
+func (collector *recordingMetricsCollector) DecryptDurationObserve(duration time.Duration) {
+	collector.inc("decrypt_duration")
+}
+
+func (collector *recordingMetricsCollector) KDFDurationObserve(operation string, duration time.Duration) {
+	collector.inc("kdf_duration_" + operation)
+}
+
+func Test_Metrics_EncodeDecode(t *testing.T) {
+	collector := &recordingMetricsCollector{}
+
+	config := DefaultCryptoConfig()
+	config.Iterations = 1
+	config.Memory = 1024
+	config.kdfStats = newKDFStats(nil)
+	config.metrics = collector
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	encoded, err := encode(context.Background(), "value", password, config)
+	if err != nil {
+		t.Fatalf("encode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := decode(context.Background(), encoded, password, config); err != nil {
+		t.Fatalf("decode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := decode(context.Background(), encoded, "wrong_password_that_is_long_enough_32chars", config); err == nil {
+		t.Fatal("decode: Expected an error for the wrong password")
+	}
+
+	expected := map[string]int{
+		"writes":               1,
+		"encrypt_duration":     1,
+		"reads":                1,
+		"decrypt_duration":     1,
+		"decrypt_failures":     1,
+		"kdf_duration_encrypt": 1,
+		"kdf_duration_decrypt": 2,
+	}
+	for name, count := range expected {
+		if collector.count(name) != count {
+			t.Fatalf("%s: Expected [%d] received [%d]", name, count, collector.count(name))
+		}
+	}
+
+	// Configs not owned by a store are not measured
+	if _, err := encode(context.Background(), "value", password, nil); err != nil {
+		t.Fatalf("encode: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if collector.count("writes") != 1 {
+		t.Fatalf("Expected [1] write received [%d]", collector.count("writes"))
+	}
+}
+
+func Test_Store_MetricsCollector(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	collector := &recordingMetricsCollector{}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_metrics",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		MetricsCollector:   collector,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenRead(ctx, token, password); err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenRead(ctx, token, "wrong_password_that_is_long_enough_32chars"); err == nil {
+		t.Fatal("TokenRead: Expected an error for the wrong password")
+	}
+
+	if collector.count("writes") != 1 || collector.count("reads") != 1 || collector.count("decrypt_failures") != 1 {
+		t.Fatalf("Expected [1] write, read and decrypt failure received [%v]", collector.counts)
+	}
+
+	// Rekeying skips the values of other passwords without counting decrypt failures
+	if _, err := store.TokenCreate(ctx, "other", "other_password_that_is_long_enough_32chars", 20); err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if _, err := store.TokensChangePassword(ctx, password, "new_password_that_is_long_enough_for_32chars"); err != nil {
+		t.Fatalf("TokensChangePassword: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if collector.count("decrypt_failures") != 1 {
+		t.Fatalf("Expected [1] decrypt failure received [%d]", collector.count("decrypt_failures"))
+	}
+}
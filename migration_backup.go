@@ -0,0 +1,103 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VAULT_SETTING_KEY_MIGRATION_ROLLBACK is the vault setting holding the SQL script
+// restoring the tables backed up before the last schema migration
+const VAULT_SETTING_KEY_MIGRATION_ROLLBACK = "migration_rollback"
+
+// migrationBackupTableName returns the name of the snapshot of the table taken
+// before migrating from the given vault version
+func migrationBackupTableName(tableName string, version string) string {
+	if version == "" {
+		return tableName + "_backup_unversioned"
+	}
+	return tableName + "_backup_v" + strings.ReplaceAll(version, ".", "_")
+}
+
+// migrationBackup snapshots the existing store tables before a schema migration
+// from an older vault version, and records the rollback script in the vault settings.
+// One snapshot is kept per version, so repeated starts do not pile up backups.
+func (store *storeImplementation) migrationBackup(ctx context.Context, version string) error {
+	if version == VAULT_VERSION_CURRENT {
+		return nil
+	}
+
+	tableNames := []string{store.vaultTableName, store.vaultMetaTableName}
+	if store.isValueChunkingEnabled() {
+		tableNames = append(tableNames, store.vaultTableName+VALUE_CHUNK_TABLE_SUFFIX)
+	}
+
+	migrator := store.gormDB.WithContext(ctx).Migrator()
+	statements := []string{}
+
+	for _, tableName := range tableNames {
+		if !migrator.HasTable(tableName) {
+			continue
+		}
+
+		backupTableName := migrationBackupTableName(tableName, version)
+		if !migrator.HasTable(backupTableName) {
+			err := store.gormDB.WithContext(ctx).Exec(
+				"CREATE TABLE ? AS SELECT * FROM ?",
+				clause.Table{Name: backupTableName},
+				clause.Table{Name: tableName},
+			).Error
+			if err != nil {
+				return err
+			}
+
+			store.logInfo(ctx, "migration backup created", "table", tableName, "backup_table", backupTableName)
+		}
+
+		statements = append(statements,
+			store.gormDB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Exec("DROP TABLE ?", clause.Table{Name: tableName})
+			}),
+			store.gormDB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Exec("CREATE TABLE ? AS SELECT * FROM ?", clause.Table{Name: tableName}, clause.Table{Name: backupTableName})
+			}),
+		)
+	}
+
+	if len(statements) == 0 || !migrator.HasTable(store.vaultMetaTableName) {
+		return nil
+	}
+
+	script := strings.Join(statements, ";\n") + ";"
+	return store.SetVaultSetting(ctx, VAULT_SETTING_KEY_MIGRATION_ROLLBACK, script)
+}
+
+// MigrationRollbackScript returns the SQL script restoring the tables backed up
+// before the last schema migration, or an empty string if there is none.
+// Indexes are not part of the snapshots, run AutoMigrate of the matching library
+// version after the script to recreate them.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - script: The rollback SQL script
+// - err: An error if something went wrong
+func (store *storeImplementation) MigrationRollbackScript(ctx context.Context) (string, error) {
+	ctx, span := store.traceStart(ctx, "MigrationRollbackScript")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "MigrationRollbackScript", ""); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	script, err := store.GetVaultSetting(ctx, VAULT_SETTING_KEY_MIGRATION_ROLLBACK)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return script, err
+}
@@ -0,0 +1,75 @@
+package vaultstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func Test_Store_MigrationBackup(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	options := NewStoreOptions{
+		VaultTableName:     "vault_backup",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	}
+
+	store, err := NewStore(options)
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	if _, err := store.TokenCreate(ctx, "test_value", "test_password_that_is_long_enough_for_security_32chars", 20); err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Vaults at the current version are migrated without a backup
+	options.MigrationBackupEnabled = true
+	if _, err := NewStore(options); err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	script, err := store.MigrationRollbackScript(ctx)
+	if err != nil {
+		t.Fatalf("MigrationRollbackScript: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if script != "" {
+		t.Fatalf("Expected no rollback script received [%s]", script)
+	}
+
+	// Vaults of an older version are backed up before migrating
+	if err := store.SetVaultVersion(ctx, VAULT_VERSION_MIN_SUPPORTED); err != nil {
+		t.Fatalf("SetVaultVersion: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := NewStore(options); err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	backupTableName := migrationBackupTableName("vault_backup", VAULT_VERSION_MIN_SUPPORTED)
+	if !store.gormDB.Migrator().HasTable(backupTableName) {
+		t.Fatalf("Expected backup table [%s] to exist", backupTableName)
+	}
+
+	var count int64
+	if err := store.gormDB.Table(backupTableName).Count(&count).Error; err != nil {
+		t.Fatalf("Count: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if count != 1 {
+		t.Fatalf("Expected [1] backed up record received [%d]", count)
+	}
+
+	script, err = store.MigrationRollbackScript(ctx)
+	if err != nil {
+		t.Fatalf("MigrationRollbackScript: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if !strings.Contains(script, backupTableName) {
+		t.Fatalf("Expected rollback script to restore [%s] received [%s]", backupTableName, script)
+	}
+}
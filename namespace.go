@@ -0,0 +1,183 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// COLUMN_NAMESPACE is the column of the vault table holding the namespace of the record,
+// created by AutoMigrate when NewStoreOptions.NamespacesEnabled is set
+const COLUMN_NAMESPACE = "namespace"
+
+// NAMESPACE_COLUMN_TYPE is the SQL type of the namespace column
+const NAMESPACE_COLUMN_TYPE = "VARCHAR(64) NOT NULL DEFAULT ''"
+
+var (
+	// ErrNamespaceInvalid is returned when a namespace contains unsupported characters
+	ErrNamespaceInvalid = errors.New("namespace must contain only letters, digits, dots, dashes and underscores (max 64 chars)")
+	// ErrNamespacesDisabled is returned when a namespace is set on the context of a
+	// store created without NewStoreOptions.NamespacesEnabled
+	ErrNamespacesDisabled = errors.New("namespaces are not enabled, see NewStoreOptions.NamespacesEnabled")
+)
+
+// namespaceContextKey is the context key for the per-request namespace
+type namespaceContextKey struct{}
+
+// namespacesAllContextKey marks the contexts of the operations covering every namespace
+type namespacesAllContextKey struct{}
+
+// namespaceRegex limits namespaces to identifiers such as tenant IDs or slugs
+var namespaceRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,64}$`)
+
+// WithNamespace returns a context that scopes the store operations to the
+// records of the namespace, e.g. a tenant. Records are created in the namespace,
+// and records of other namespaces are not found, listed, counted or changed.
+//
+// Requires NewStoreOptions.NamespacesEnabled. Without a namespace on the context
+// the operations use the default namespace, the empty string.
+// Vault settings and metadata remain shared, as with WithTableSuffix.
+//
+// Example:
+//
+//	ctx := vaultstore.WithNamespace(r.Context(), tenantID)
+//	token, err := store.TokenCreate(ctx, "value", password, 20) // visible to the tenant only
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return contextWithValue(ctx, namespaceContextKey{}, namespace)
+}
+
+// NamespaceFromContext returns the namespace set via WithNamespace, if any
+func NamespaceFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	namespace, ok := ctx.Value(namespaceContextKey{}).(string)
+	return namespace, ok
+}
+
+// IsNamespaceValid checks that the namespace can be stored in the namespace column
+func IsNamespaceValid(namespace string) bool {
+	return namespaceRegex.MatchString(namespace)
+}
+
+// withNamespacesAll returns a context whose vault table sessions are not scoped to a
+// namespace, for the maintenance operations: the cleanup of expired, consumed and soft
+// deleted tokens, and of the value chunks shared by the namespaces
+func withNamespacesAll(ctx context.Context) context.Context {
+	return contextWithValue(ctx, namespacesAllContextKey{}, true)
+}
+
+// namespacesAll reports whether the context covers every namespace, see withNamespacesAll
+func namespacesAll(ctx context.Context) bool {
+	all, _ := ctx.Value(namespacesAllContextKey{}).(bool)
+	return all
+}
+
+// namespaceFromContext resolves the namespace of the context, the default
+// namespace if none is set
+func (store *storeImplementation) namespaceFromContext(ctx context.Context) (string, error) {
+	namespace, ok := NamespaceFromContext(ctx)
+	if !ok {
+		return "", nil
+	}
+
+	if !store.namespacesEnabled {
+		return "", ErrNamespacesDisabled
+	}
+
+	if !IsNamespaceValid(namespace) {
+		return "", ErrNamespaceInvalid
+	}
+
+	return namespace, nil
+}
+
+// namespaceScope restricts the vault table session to the namespace of the context.
+// An invalid namespace is attached as an error, so the chained operation fails.
+func (store *storeImplementation) namespaceScope(ctx context.Context, db *gorm.DB) *gorm.DB {
+	namespace, err := store.namespaceFromContext(ctx)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+
+	if !store.namespacesEnabled || namespacesAll(ctx) {
+		return db
+	}
+
+	return db.Where(COLUMN_NAMESPACE+" = ?", namespace)
+}
+
+// namespaceColumnMigrate adds the namespace column to the vault table, and scopes the
+// unique token index to the namespace, so tenants can hold the same custom tokens
+func (store *storeImplementation) namespaceColumnMigrate(tableName string) error {
+	if !store.namespacesEnabled {
+		return nil
+	}
+
+	migrator := store.gormDB.Migrator()
+
+	if !migrator.HasColumn(tableName, COLUMN_NAMESPACE) {
+		err := store.gormDB.Exec(
+			"ALTER TABLE ? "+store.addColumnKeyword()+" ? "+NAMESPACE_COLUMN_TYPE,
+			clause.Table{Name: tableName},
+			clause.Column{Name: COLUMN_NAMESPACE},
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	namespaced, err := store.tokenIndexNamespaced(tableName)
+	if err != nil || namespaced {
+		return err
+	}
+
+	// The namespaced index keeps the name of the token index created by AutoMigrate,
+	// so AutoMigrate does not create the unique index on the token alone again
+	indexName := tokenIndexName(tableName)
+	return store.gormDB.Transaction(func(tx *gorm.DB) error {
+		if tx.Migrator().HasIndex(tableName, indexName) {
+			if err := tx.Migrator().DropIndex(tableName, indexName); err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(
+			"CREATE UNIQUE INDEX ? ON ? (?, ?)",
+			clause.Column{Name: indexName},
+			clause.Table{Name: tableName},
+			clause.Column{Name: COLUMN_NAMESPACE},
+			clause.Column{Name: COLUMN_VAULT_TOKEN},
+		).Error
+	})
+}
+
+// tokenIndexNamespaced reports whether the unique token index of the vault table
+// covers the namespace and token columns
+func (store *storeImplementation) tokenIndexNamespaced(tableName string) (bool, error) {
+	indexes, err := store.gormDB.Migrator().GetIndexes(tableName)
+	if err != nil {
+		return false, err
+	}
+
+	indexName := tokenIndexName(tableName)
+	for _, index := range indexes {
+		if index.Name() != indexName {
+			continue
+		}
+
+		columns := index.Columns()
+		return len(columns) == 2 && columns[0] == COLUMN_NAMESPACE && columns[1] == COLUMN_VAULT_TOKEN, nil
+	}
+
+	return false, nil
+}
+
+// tokenIndexName returns the name of the unique token index, as created by AutoMigrate
+func tokenIndexName(tableName string) string {
+	return "idx_" + tableName + "_" + COLUMN_VAULT_TOKEN
+}
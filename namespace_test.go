@@ -0,0 +1,265 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func initNamespacesStore(t *testing.T) StoreInterface {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_namespaces",
+		VaultMetaTableName: "vault_namespaces_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		NamespacesEnabled:  true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	return store
+}
+
+func Test_Store_Namespaces(t *testing.T) {
+	store := initNamespacesStore(t)
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	tenantA := WithNamespace(context.Background(), "tenant-a")
+	tenantB := WithNamespace(context.Background(), "tenant-b")
+
+	token, err := store.TokenCreate(tenantA, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(tenantA, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret" {
+		t.Fatalf("Expected [secret] received [%v]", value)
+	}
+
+	for name, ctx := range map[string]context.Context{"tenant-b": tenantB, "default": context.Background()} {
+		exists, err := store.TokenExists(ctx, token)
+		if err != nil {
+			t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if exists {
+			t.Fatalf("Expected the token not to exist in the [%v] namespace", name)
+		}
+
+		if _, err := store.TokenRead(ctx, token, password); !errors.Is(err, ErrTokenNotFound) {
+			t.Fatalf("Expected [ErrTokenNotFound] in the [%v] namespace received [%v]", name, err)
+		}
+
+		count, err := store.RecordCount(ctx, RecordQuery())
+		if err != nil {
+			t.Fatalf("RecordCount: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if count != 0 {
+			t.Fatalf("Expected [0] records in the [%v] namespace received [%v]", name, count)
+		}
+	}
+
+	// Deleting from another namespace leaves the token in place
+	_ = store.TokenDelete(tenantB, token)
+
+	exists, err := store.TokenExists(tenantA, token)
+	if err != nil || !exists {
+		t.Fatalf("Expected the token to still exist received [%v] [%v]", exists, err)
+	}
+}
+
+func Test_Store_NamespacesIdempotencyKey(t *testing.T) {
+	store := initNamespacesStore(t)
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	options := TokenCreateOptions{IdempotencyKey: "order-1"}
+
+	tokenA, err := store.TokenCreate(WithNamespace(context.Background(), "tenant-a"), "a", password, 20, options)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	tokenB, err := store.TokenCreate(WithNamespace(context.Background(), "tenant-b"), "b", password, 20, options)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if tokenA == tokenB {
+		t.Fatal("Expected the idempotency keys to be scoped to the namespace")
+	}
+}
+
+func Test_Store_NamespacesErrors(t *testing.T) {
+	store := initNamespacesStore(t)
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	_, err := store.TokenCreate(WithNamespace(context.Background(), "tenant a"), "secret", password, 20)
+	if !errors.Is(err, ErrNamespaceInvalid) {
+		t.Fatalf("Expected [ErrNamespaceInvalid] received [%v]", err)
+	}
+
+	disabled, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = disabled.TokenCreate(WithNamespace(context.Background(), "tenant-a"), "secret", password, 20)
+	if !errors.Is(err, ErrNamespacesDisabled) {
+		t.Fatalf("Expected [ErrNamespacesDisabled] received [%v]", err)
+	}
+}
+
+func Test_Store_NamespacesValueChunksGarbageCollect(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:      "vault_namespaces_chunked",
+		VaultMetaTableName:  "vault_namespaces_chunked_meta",
+		DB:                  db,
+		AutomigrateEnabled:  true,
+		NamespacesEnabled:   true,
+		ValueChunkThreshold: 64,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	largeValue := strings.Repeat("large value ", 100)
+	tenantA := WithNamespace(context.Background(), "tenant-a")
+	tenantB := WithNamespace(context.Background(), "tenant-b")
+
+	tokenA, err := store.TokenCreate(tenantA, largeValue, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	tokenB, err := store.TokenCreate(tenantB, largeValue, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for _, ctx := range []context.Context{context.Background(), tenantA} {
+		deleted, err := store.ValueChunksGarbageCollect(ctx)
+		if err != nil {
+			t.Fatalf("ValueChunksGarbageCollect: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if deleted != 0 {
+			t.Fatalf("Expected no chunks of other namespaces to be collected received [%v]", deleted)
+		}
+	}
+
+	for token, ctx := range map[string]context.Context{tokenA: tenantA, tokenB: tenantB} {
+		value, err := store.TokenRead(ctx, token, password)
+		if err != nil {
+			t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if value != largeValue {
+			t.Fatal("Expected the large value to be kept")
+		}
+	}
+}
+
+func Test_Store_NamespacesCustomToken(t *testing.T) {
+	store := initNamespacesStore(t)
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	tenantA := WithNamespace(context.Background(), "tenant-a")
+	tenantB := WithNamespace(context.Background(), "tenant-b")
+
+	for ctx, value := range map[context.Context]string{tenantA: "a", tenantB: "b"} {
+		if err := store.TokenCreateCustom(ctx, "app/prod/db/password", value, password); err != nil {
+			t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	if err := store.TokenCreateCustom(tenantA, "app/prod/db/password", "a", password); !errors.Is(err, ErrTokenAlreadyExists) {
+		t.Fatalf("Expected [ErrTokenAlreadyExists] received [%v]", err)
+	}
+
+	value, err := store.TokenRead(tenantB, "app/prod/db/password", password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "b" {
+		t.Fatalf("Expected [b] received [%v]", value)
+	}
+
+	check := store.(*storeImplementation).preflightIndexes(context.Background())
+	if !check.Passed {
+		t.Fatalf("Expected the namespaced token index received [%v]", check.Details)
+	}
+}
+
+func Test_Store_NamespacesMaintenance(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:       "vault_namespaces_maintenance",
+		VaultMetaTableName:   "vault_namespaces_maintenance_meta",
+		DB:                   db,
+		AutomigrateEnabled:   true,
+		NamespacesEnabled:    true,
+		ExpiresAtPastAllowed: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	tenantA := WithNamespace(context.Background(), "tenant-a")
+
+	token, err := store.TokenCreate(tenantA, "expired", password, 20, TokenCreateOptions{
+		ExpiresAt: time.Now().UTC().Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	scheduler, err := store.Scheduler()
+	if err != nil {
+		t.Fatalf("Scheduler: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	run, err := scheduler.RunNow(context.Background(), SCHEDULER_JOB_EXPIRED_CLEANUP)
+	if err != nil {
+		t.Fatalf("RunNow: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if run.Count != 1 {
+		t.Fatalf("Expected the expired token of the tenant to be cleaned up received [%v]", run.Count)
+	}
+
+	records, err := store.RecordList(tenantA, RecordQuery().SetToken(token).SetSoftDeletedInclude(true))
+	if err != nil {
+		t.Fatalf("RecordList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(records) != 1 || !isRecordSoftDeleted(records[0]) {
+		t.Fatal("Expected the expired token to be soft deleted")
+	}
+}
@@ -0,0 +1,110 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrOperationDenied can be returned by an OperationGuard refusing an operation
+var ErrOperationDenied = errors.New("operation denied")
+
+// OperationGuard authorizes the operations of the store, see NewStoreOptions.OperationGuard.
+// It is the single choke point of an embedder's authorization, e.g. checking the
+// caller set in the context against the token prefix, without wrapping the store.
+type OperationGuard interface {
+	// Allow returns nil to let the operation run, or the error returned by the store
+	// method, e.g. ErrOperationDenied.
+	//
+	// The operation is the name of the store method, e.g. "TokenRead". The token is the
+	// one the operation applies to, or empty for operations on no single known token,
+	// such as TokenCreate or the maintenance methods. Operations on several tokens,
+	// such as TokensRead, are allowed once per token.
+	//
+	// Only the method called by the embedder is checked, not the store methods it uses
+	// itself, e.g. RecordFindByToken for TokenRead. The jobs run by the Scheduler and
+	// the methods without a context, which configure the store, are not checked.
+	Allow(ctx context.Context, operation string, token string) error
+}
+
+// operationAllowedContextKey marks a context whose operation was allowed by the guard
+type operationAllowedContextKey struct{}
+
+// OperationGuardFunc adapts a function to the OperationGuard interface
+type OperationGuardFunc func(ctx context.Context, operation string, token string) error
+
+// Allow calls the function
+func (fn OperationGuardFunc) Allow(ctx context.Context, operation string, token string) error {
+	return fn(ctx, operation, token)
+}
+
+// operationAllowed reports whether the operation needs no check, because the store
+// has no guard or the context is the one of an operation already allowed
+func (store *storeImplementation) operationAllowed(ctx context.Context) bool {
+	if store.operationGuard == nil {
+		return true
+	}
+
+	allowed, _ := ctx.Value(operationAllowedContextKey{}).(bool)
+	return allowed
+}
+
+// operationAllowedContext returns the context of an allowed operation, passed on to the
+// store methods it calls so that they are not checked again
+func (store *storeImplementation) operationAllowedContext(ctx context.Context) context.Context {
+	if store.operationAllowed(ctx) {
+		return ctx
+	}
+
+	return contextWithValue(ctx, operationAllowedContextKey{}, true)
+}
+
+// operationAllow asks the operation guard of the store, if any, to allow the operation
+func (store *storeImplementation) operationAllow(ctx context.Context, operation string, token string) error {
+	if store.operationAllowed(ctx) {
+		return nil
+	}
+
+	err := store.operationGuard.Allow(ctx, operation, token)
+	if err != nil {
+		traceError(ctx, "operation denied")
+	}
+
+	return err
+}
+
+// operationAllowTokens asks the operation guard to allow the operation on each token
+func (store *storeImplementation) operationAllowTokens(ctx context.Context, operation string, tokens []string) error {
+	if store.operationAllowed(ctx) {
+		return nil
+	}
+
+	for _, token := range tokens {
+		if err := store.operationGuard.Allow(ctx, operation, token); err != nil {
+			traceError(ctx, "operation denied")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// operationAllowRecords asks the operation guard to allow the operation on the token of each record
+func (store *storeImplementation) operationAllowRecords(ctx context.Context, operation string, records ...RecordInterface) error {
+	if store.operationAllowed(ctx) {
+		return nil
+	}
+
+	for _, record := range records {
+		token := ""
+		if record != nil {
+			token = record.GetToken()
+		}
+
+		if err := store.operationGuard.Allow(ctx, operation, token); err != nil {
+			traceError(ctx, "operation denied")
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// recordingGuard records the checked operations, denying the reads of one token
+type recordingGuard struct {
+	mu          sync.Mutex
+	checks      []string
+	deniedToken string
+}
+
+func (guard *recordingGuard) Allow(ctx context.Context, operation string, token string) error {
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	guard.checks = append(guard.checks, operation+":"+token)
+
+	if token == guard.deniedToken && operation != "TokenCreateCustom" {
+		return ErrOperationDenied
+	}
+
+	return nil
+}
+
+func (guard *recordingGuard) reset() []string {
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	checks := guard.checks
+	guard.checks = nil
+	return checks
+}
+
+func Test_Store_OperationGuard(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	guard := &recordingGuard{deniedToken: "denied"}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_guarded",
+		VaultMetaTableName: "vault_meta_guarded",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		OperationGuard:     guard,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	for _, token := range []string{"allowed", "denied"} {
+		if err := store.TokenCreateCustom(ctx, token, "value", password); err != nil {
+			t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+	guard.reset()
+
+	// Only the called method is checked, not the store methods it uses
+	value, err := store.TokenRead(ctx, "allowed", password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != "value" {
+		t.Fatalf("Expected [value] received [%v]", value)
+	}
+
+	if checks := guard.reset(); !slices.Equal(checks, []string{"TokenRead:allowed"}) {
+		t.Fatalf("Expected [TokenRead:allowed] received [%v]", checks)
+	}
+
+	if _, err := store.TokenRead(ctx, "denied", password); !errors.Is(err, ErrOperationDenied) {
+		t.Fatalf("Expected [ErrOperationDenied] received [%v]", err)
+	}
+
+	if err := store.TokenDelete(ctx, "denied"); !errors.Is(err, ErrOperationDenied) {
+		t.Fatalf("Expected [ErrOperationDenied] received [%v]", err)
+	}
+
+	exists, err := store.TokenExists(ctx, "denied")
+	if !errors.Is(err, ErrOperationDenied) || exists {
+		t.Fatalf("Expected [ErrOperationDenied] received [%v] [%v]", exists, err)
+	}
+	guard.reset()
+
+	// Operations on several tokens are checked per token
+	if _, err := store.TokensRead(ctx, []string{"allowed", "denied"}, password); !errors.Is(err, ErrOperationDenied) {
+		t.Fatalf("Expected [ErrOperationDenied] received [%v]", err)
+	}
+
+	if checks := guard.reset(); !slices.Equal(checks, []string{"TokensRead:allowed", "TokensRead:denied"}) {
+		t.Fatalf("Expected a check per token received [%v]", checks)
+	}
+
+	// Operations on no single token are checked without one
+	if _, err := store.TokensExpiredSoftDelete(ctx); err != nil {
+		t.Fatalf("TokensExpiredSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if checks := guard.reset(); !slices.Equal(checks, []string{"TokensExpiredSoftDelete:"}) {
+		t.Fatalf("Expected [TokensExpiredSoftDelete:] received [%v]", checks)
+	}
+
+	// The scheduler jobs are not checked
+	scheduler, err := store.Scheduler()
+	if err != nil {
+		t.Fatalf("Scheduler: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := scheduler.RunNow(ctx, SCHEDULER_JOB_EXPIRED_CLEANUP); err != nil {
+		t.Fatalf("RunNow: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if checks := guard.reset(); len(checks) != 0 {
+		t.Fatalf("Expected no check for the scheduler jobs received [%v]", checks)
+	}
+}
+
+func Test_OperationGuardFunc(t *testing.T) {
+	var guard OperationGuard = OperationGuardFunc(func(ctx context.Context, operation string, token string) error {
+		if operation == "TokenDelete" {
+			return ErrOperationDenied
+		}
+		return nil
+	})
+
+	if err := guard.Allow(context.Background(), "TokenRead", "token"); err != nil {
+		t.Fatalf("Allow: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := guard.Allow(context.Background(), "TokenDelete", "token"); !errors.Is(err, ErrOperationDenied) {
+		t.Fatalf("Expected [ErrOperationDenied] received [%v]", err)
+	}
+}
@@ -0,0 +1,51 @@
+package vaultstore
+
+import (
+	"errors"
+	"os"
+
+	"github.com/dracory/vaultstore/vaultcrypt"
+)
+
+// ENCRYPTION_PREFIX_PEPPER marks values encrypted with the password mixed with the store pepper.
+// The full value is "pep:" followed by a v2 or v3 value, see vaultcrypt.PepperPassword.
+const ENCRYPTION_PREFIX_PEPPER = vaultcrypt.PREFIX_PEPPER
+
+// PEPPER_MIN_SIZE is the minimum size in bytes of the store pepper
+const PEPPER_MIN_SIZE = 32
+
+var (
+	// ErrPepperInvalid is returned by NewStore for a pepper shorter than PEPPER_MIN_SIZE
+	ErrPepperInvalid = errors.New("pepper must be at least 32 bytes")
+	// ErrPepperMissing is returned when reading a value encrypted with a pepper on a store without it
+	ErrPepperMissing = vaultcrypt.ErrPepperMissing
+)
+
+// pepperLoad returns the pepper of the options, read from the key file if set,
+// or nil if the store has no pepper
+func pepperLoad(opts NewStoreOptions) ([]byte, error) {
+	pepper := opts.Pepper
+
+	if opts.PepperFilePath != "" {
+		if len(opts.Pepper) > 0 {
+			return nil, errors.New("vault store: Pepper and PepperFilePath are mutually exclusive")
+		}
+
+		var err error
+		pepper, err = os.ReadFile(opts.PepperFilePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(pepper) == 0 {
+		return nil, nil
+	}
+
+	if len(pepper) < PEPPER_MIN_SIZE {
+		return nil, ErrPepperInvalid
+	}
+
+	// Copied, the caller may reuse the slice
+	return append([]byte{}, pepper...), nil
+}
@@ -0,0 +1,151 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_encode_Pepper(t *testing.T) {
+	config := LightweightCryptoConfig()
+	config.pepper = []byte(strings.Repeat("p", PEPPER_MIN_SIZE))
+
+	encoded, err := encode(context.Background(), "secret", "password", config)
+	if err != nil {
+		t.Fatalf("encode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.HasPrefix(encoded, ENCRYPTION_PREFIX_PEPPER+ENCRYPTION_PREFIX_V2) {
+		t.Fatalf("Expected the [pep:v2:] prefix received [%v]", encoded[:10])
+	}
+
+	decoded, err := decode(context.Background(), encoded, "password", config)
+	if err != nil {
+		t.Fatalf("decode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if decoded != "secret" {
+		t.Fatalf("Expected [secret] received [%v]", decoded)
+	}
+
+	// A copy of the value without the pepper is not enough to read it
+	if _, err := decode(context.Background(), encoded, "password", LightweightCryptoConfig()); !errors.Is(err, ErrPepperMissing) {
+		t.Fatalf("Expected [ErrPepperMissing] received [%v]", err)
+	}
+
+	// The pepper is not handed out with the parameters
+	if config.Params().Pepper != nil {
+		t.Fatal("Expected Params without the pepper")
+	}
+}
+
+func Test_pepperLoad(t *testing.T) {
+	pepper, err := pepperLoad(NewStoreOptions{})
+	if err != nil || pepper != nil {
+		t.Fatalf("Expected no pepper received [%v] [%v]", pepper, err)
+	}
+
+	if _, err := pepperLoad(NewStoreOptions{Pepper: []byte("short")}); !errors.Is(err, ErrPepperInvalid) {
+		t.Fatalf("Expected [ErrPepperInvalid] received [%v]", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pepper.key")
+	if err := os.WriteFile(path, []byte(strings.Repeat("k", 48)), 0o600); err != nil {
+		t.Fatalf("WriteFile: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	pepper, err = pepperLoad(NewStoreOptions{PepperFilePath: path})
+	if err != nil {
+		t.Fatalf("pepperLoad: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if string(pepper) != strings.Repeat("k", 48) {
+		t.Fatalf("Expected the pepper of the key file received [%v]", string(pepper))
+	}
+
+	if _, err := pepperLoad(NewStoreOptions{Pepper: pepper, PepperFilePath: path}); err == nil {
+		t.Fatal("Expected an error for both Pepper and PepperFilePath")
+	}
+
+	if _, err := pepperLoad(NewStoreOptions{PepperFilePath: filepath.Join(t.TempDir(), "missing.key")}); err == nil {
+		t.Fatal("Expected an error for a missing key file")
+	}
+}
+
+func Test_Store_Pepper(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// A value written before the pepper was configured
+	unpeppered, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_pepper",
+		VaultMetaTableName: "vault_meta_pepper",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		CryptoConfig:       LightweightCryptoConfig(),
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := unpeppered.TokenCreateCustom(ctx, "before", "old value", password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_pepper",
+		VaultMetaTableName: "vault_meta_pepper",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		CryptoConfig:       LightweightCryptoConfig(),
+		Pepper:             []byte(strings.Repeat("p", PEPPER_MIN_SIZE)),
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenCreateCustom(ctx, "after", "new value", password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.RecordFindByToken(ctx, "after")
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if record == nil || !strings.HasPrefix(record.GetValue(), ENCRYPTION_PREFIX_PEPPER) {
+		t.Fatalf("Expected a peppered value received [%v]", record)
+	}
+
+	for token, expected := range map[string]string{"before": "old value", "after": "new value"} {
+		value, err := store.TokenRead(ctx, token, password)
+		if err != nil {
+			t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+		}
+		if value != expected {
+			t.Fatalf("Expected [%v] received [%v]", expected, value)
+		}
+	}
+
+	if _, err := unpeppered.TokenRead(ctx, "after", password); !errors.Is(err, ErrPepperMissing) {
+		t.Fatalf("Expected [ErrPepperMissing] received [%v]", err)
+	}
+
+	_, err = NewStore(NewStoreOptions{
+		VaultTableName:     "vault_pepper",
+		VaultMetaTableName: "vault_meta_pepper",
+		DB:                 db,
+		Pepper:             []byte("short"),
+	})
+	if !errors.Is(err, ErrPepperInvalid) {
+		t.Fatalf("Expected [ErrPepperInvalid] received [%v]", err)
+	}
+}
@@ -0,0 +1,243 @@
+package vaultstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VAULT_SETTING_KEY_POLICY is the vault setting holding the policy loaded with LoadPolicy
+const VAULT_SETTING_KEY_POLICY = "policy"
+
+// ErrPolicyInvalid is returned when a policy document cannot be parsed or is inconsistent
+var ErrPolicyInvalid = errors.New("policy is invalid")
+
+// ErrExpiresAtExceedsRetention is returned when a token expiration is beyond the retention policy
+var ErrExpiresAtExceedsRetention = errors.New("token expiration exceeds the retention policy")
+
+// Policy is the declarative policy document of a store, loaded with LoadPolicy.
+// Sections left out keep the behaviour configured via NewStoreOptions.
+type Policy struct {
+	// Password replaces the Password* options of the store
+	Password *PasswordPolicy `json:"password,omitempty"`
+	// Retention limits how long tokens are kept
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+	// Quota replaces the QuotaThresholds option of the store
+	Quota *QuotaThresholds `json:"quota,omitempty"`
+}
+
+// PasswordPolicy defines the requirements for the passwords encrypting token values
+type PasswordPolicy struct {
+	AllowEmpty       bool `json:"allow_empty"`
+	MinLength        int  `json:"min_length"` // 0 = use default 16
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireNumbers   bool `json:"require_numbers"`
+	RequireSymbols   bool `json:"require_symbols"`
+}
+
+// RetentionPolicy limits the lifetime of newly created and renewed tokens.
+// Tokens without an expiration get the default TTL, or the max TTL if no default is set.
+type RetentionPolicy struct {
+	// DefaultTTLSeconds is the lifetime of tokens created without an expiration (0 = max TTL, or never expire)
+	DefaultTTLSeconds int64 `json:"default_ttl_seconds"`
+	// MaxTTLSeconds is the maximum lifetime of a token (0 = unlimited)
+	MaxTTLSeconds int64 `json:"max_ttl_seconds"`
+}
+
+// Validate checks the policy is consistent
+func (policy Policy) Validate() error {
+	if policy.Password != nil && policy.Password.MinLength < 0 {
+		return fmt.Errorf("%w: password min_length cannot be negative", ErrPolicyInvalid)
+	}
+
+	if policy.Retention != nil {
+		retention := policy.Retention
+		if retention.DefaultTTLSeconds < 0 || retention.MaxTTLSeconds < 0 {
+			return fmt.Errorf("%w: retention ttl cannot be negative", ErrPolicyInvalid)
+		}
+		if retention.MaxTTLSeconds > 0 && retention.DefaultTTLSeconds > retention.MaxTTLSeconds {
+			return fmt.Errorf("%w: retention default_ttl_seconds exceeds max_ttl_seconds", ErrPolicyInvalid)
+		}
+	}
+
+	if policy.Quota != nil {
+		quota := policy.Quota
+		if quota.MaxRecordCount < 0 || quota.MaxStorageBytes < 0 || quota.MaxExpiredUncleaned < 0 {
+			return fmt.Errorf("%w: quota thresholds cannot be negative", ErrPolicyInvalid)
+		}
+	}
+
+	return nil
+}
+
+// LoadPolicy reads a JSON policy document, persists it in the vault settings
+// and enforces it from then on. Unknown fields are rejected.
+//
+// Example:
+//
+//	{"password": {"min_length": 20}, "retention": {"max_ttl_seconds": 2592000}}
+//
+// Parameters:
+// - ctx: The context
+// - r: The reader of the JSON policy document
+//
+// Returns:
+// - err: An error if the policy is invalid or could not be persisted
+func (store *storeImplementation) LoadPolicy(ctx context.Context, r io.Reader) error {
+	ctx, span := store.traceStart(ctx, "LoadPolicy")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "LoadPolicy", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var policy Policy
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		return fmt.Errorf("%w: %s", ErrPolicyInvalid, err.Error())
+	}
+
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetVaultSetting(ctx, VAULT_SETTING_KEY_POLICY, string(value)); err != nil {
+		return err
+	}
+
+	store.policy.Store(&policy)
+	return nil
+}
+
+// PolicyReload loads the policy persisted in the vault settings, e.g. after another
+// instance called LoadPolicy. Stores created with AutomigrateEnabled reload it on creation.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - err: An error if the persisted policy could not be read
+func (store *storeImplementation) PolicyReload(ctx context.Context) error {
+	ctx, span := store.traceStart(ctx, "PolicyReload")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "PolicyReload", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	value, err := store.GetVaultSetting(ctx, VAULT_SETTING_KEY_POLICY)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		store.policy.Store(nil)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var policy Policy
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		return fmt.Errorf("%w: %s", ErrPolicyInvalid, err.Error())
+	}
+
+	store.policy.Store(&policy)
+	return nil
+}
+
+// GetPolicy returns the enforced policy, or nil if none was loaded
+func (store *storeImplementation) GetPolicy() *Policy {
+	return store.policy.Load()
+}
+
+// passwordPolicy returns the password requirements of the policy, or of the store options
+func (store *storeImplementation) passwordPolicy() PasswordPolicy {
+	if policy := store.policy.Load(); policy != nil && policy.Password != nil {
+		return *policy.Password
+	}
+
+	return PasswordPolicy{
+		AllowEmpty:       store.passwordAllowEmpty,
+		MinLength:        store.passwordMinLength,
+		RequireLowercase: store.passwordRequireLowercase,
+		RequireUppercase: store.passwordRequireUppercase,
+		RequireNumbers:   store.passwordRequireNumbers,
+		RequireSymbols:   store.passwordRequireSymbols,
+	}
+}
+
+// quotaThresholdsCurrent returns the quota thresholds of the policy, or of the store options
+func (store *storeImplementation) quotaThresholdsCurrent() QuotaThresholds {
+	if policy := store.policy.Load(); policy != nil && policy.Quota != nil {
+		return *policy.Quota
+	}
+
+	return store.quotaThresholds
+}
+
+// retentionExpiresAt applies the retention policy to the expiration of a new or
+// renewed token, returning the default expiration for a zero value
+func (store *storeImplementation) retentionExpiresAt(expiresAt time.Time) (time.Time, error) {
+	policy := store.policy.Load()
+	if policy == nil || policy.Retention == nil {
+		return expiresAt, nil
+	}
+
+	now := time.Now().UTC()
+	retention := policy.Retention
+
+	if expiresAt.IsZero() && retention.DefaultTTLSeconds > 0 {
+		expiresAt = now.Add(time.Duration(retention.DefaultTTLSeconds) * time.Second)
+	}
+
+	if retention.MaxTTLSeconds <= 0 {
+		return expiresAt, nil
+	}
+
+	maxExpiresAt := now.Add(time.Duration(retention.MaxTTLSeconds) * time.Second)
+	if expiresAt.IsZero() {
+		return maxExpiresAt, nil
+	}
+
+	if expiresAt.After(maxExpiresAt) {
+		return time.Time{}, ErrExpiresAtExceedsRetention
+	}
+
+	return expiresAt, nil
+}
+
+// tokenCreateOptionsWithRetention returns the create options with the retention policy applied
+func (store *storeImplementation) tokenCreateOptionsWithRetention(options []TokenCreateOptions) ([]TokenCreateOptions, error) {
+	option := TokenCreateOptions{}
+	if len(options) > 0 {
+		option = options[0]
+	}
+
+	expiresAt, err := store.retentionExpiresAt(option.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Equal(option.ExpiresAt) {
+		return options, nil
+	}
+
+	option.ExpiresAt = expiresAt
+	return []TokenCreateOptions{option}, nil
+}
@@ -0,0 +1,96 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func Test_Store_LoadPolicy(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_policy",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+
+	err = store.LoadPolicy(ctx, strings.NewReader(`{"password": {"min_length": 40}, "retention": {"max_ttl_seconds": 3600}}`))
+	if err != nil {
+		t.Fatalf("LoadPolicy: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The password policy replaces the store options
+	_, err = store.TokenCreate(ctx, "test_value", "test_password_that_is_long_enough", 20)
+	if !errors.Is(err, ErrPasswordInvalid) {
+		t.Fatalf("Expected [ErrPasswordInvalid] received [%v]", err)
+	}
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// Tokens without an expiration get the max TTL
+	token, err := store.TokenCreate(ctx, "test_value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, info, err := store.TokenReadWithInfo(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+	expiresAt := carbon.Parse(info.ExpiresAt, carbon.UTC)
+	if expiresAt.IsZero() || expiresAt.StdTime().After(time.Now().Add(time.Hour+time.Minute)) {
+		t.Fatalf("Expected expiration within the max TTL received [%v]", info.ExpiresAt)
+	}
+
+	// Expirations beyond the max TTL are rejected
+	_, err = store.TokenCreate(ctx, "test_value", password, 20, TokenCreateOptions{ExpiresAt: time.Now().Add(48 * time.Hour)})
+	if !errors.Is(err, ErrExpiresAtExceedsRetention) {
+		t.Fatalf("Expected [ErrExpiresAtExceedsRetention] received [%v]", err)
+	}
+
+	err = store.TokenRenew(ctx, token, time.Now().Add(48*time.Hour))
+	if !errors.Is(err, ErrExpiresAtExceedsRetention) {
+		t.Fatalf("Expected [ErrExpiresAtExceedsRetention] received [%v]", err)
+	}
+
+	// The policy is persisted and reloaded by new stores
+	reloaded, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_policy",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	policy := reloaded.GetPolicy()
+	if policy == nil || policy.Password == nil || policy.Password.MinLength != 40 {
+		t.Fatalf("Expected reloaded policy with min_length 40 received %+v", policy)
+	}
+
+	// Unknown fields and inconsistent policies are rejected
+	err = store.LoadPolicy(ctx, strings.NewReader(`{"unknown": true}`))
+	if !errors.Is(err, ErrPolicyInvalid) {
+		t.Fatalf("Expected [ErrPolicyInvalid] received [%v]", err)
+	}
+
+	err = store.LoadPolicy(ctx, strings.NewReader(`{"retention": {"default_ttl_seconds": 7200, "max_ttl_seconds": 3600}}`))
+	if !errors.Is(err, ErrPolicyInvalid) {
+		t.Fatalf("Expected [ErrPolicyInvalid] received [%v]", err)
+	}
+}
@@ -0,0 +1,131 @@
+package vaultstore
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// QuotaThresholds defines soft limits for the vault table.
+// Exceeding a threshold does not block writes, it emits a quota event.
+// A zero value disables the respective check.
+type QuotaThresholds struct {
+	// MaxRecordCount is the number of active (non soft-deleted) records
+	MaxRecordCount int64 `json:"max_record_count"`
+	// MaxStorageBytes is the total size of the stored (encrypted) values
+	MaxStorageBytes int64 `json:"max_storage_bytes"`
+	// MaxExpiredUncleaned is the number of expired records not yet cleaned up
+	MaxExpiredUncleaned int64 `json:"max_expired_uncleaned"`
+}
+
+// QuotaReport holds the measured usage and the thresholds that were exceeded
+type QuotaReport struct {
+	RecordCount      int64
+	StorageBytes     int64
+	ExpiredUncleaned int64
+	Exceeded         []EventType
+}
+
+// QuotaCheck measures the vault table usage against the configured QuotaThresholds
+// and emits a quota event to the event hooks for each exceeded threshold
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - report: The measured usage and exceeded thresholds
+// - err: An error if something went wrong
+func (store *storeImplementation) QuotaCheck(ctx context.Context) (report QuotaReport, err error) {
+	ctx, span := store.traceStart(ctx, "QuotaCheck")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "QuotaCheck", ""); err != nil {
+		return QuotaReport{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	report.RecordCount, err = store.RecordCount(ctx, RecordQuery())
+	if err != nil {
+		return report, err
+	}
+
+	err = store.vaultDB(ctx).
+		Select("COALESCE(SUM(" + store.lengthExpr(COLUMN_VAULT_VALUE) + "), 0)").
+		Scan(&report.StorageBytes).Error
+	if err != nil {
+		return report, err
+	}
+
+	if store.isValueChunkingEnabled() {
+		var chunkBytes int64
+		err = store.valueChunkDB(ctx).
+			Select("COALESCE(SUM(" + store.lengthExpr("chunk_data") + "), 0)").
+			Scan(&chunkBytes).Error
+		if err != nil {
+			return report, err
+		}
+		report.StorageBytes += chunkBytes
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	err = store.vaultDB(ctx).
+		Where(COLUMN_EXPIRES_AT+" < ?", now).
+		Where(COLUMN_SOFT_DELETED_AT+" > ?", now).
+		Count(&report.ExpiredUncleaned).Error
+	if err != nil {
+		return report, err
+	}
+
+	thresholds := store.quotaThresholdsCurrent()
+	checks := []struct {
+		eventType EventType
+		value     int64
+		threshold int64
+	}{
+		{EVENT_TYPE_QUOTA_RECORD_COUNT, report.RecordCount, thresholds.MaxRecordCount},
+		{EVENT_TYPE_QUOTA_STORAGE_BYTES, report.StorageBytes, thresholds.MaxStorageBytes},
+		{EVENT_TYPE_QUOTA_EXPIRED_UNCLEANED, report.ExpiredUncleaned, thresholds.MaxExpiredUncleaned},
+	}
+
+	for _, check := range checks {
+		if check.threshold <= 0 || check.value <= check.threshold {
+			continue
+		}
+
+		report.Exceeded = append(report.Exceeded, check.eventType)
+		store.emitEvent(ctx, check.eventType, "", map[string]string{
+			"value":     strconv.FormatInt(check.value, 10),
+			"threshold": strconv.FormatInt(check.threshold, 10),
+		})
+	}
+
+	return report, nil
+}
+
+// quotaCheckAfterWrite runs QuotaCheck at most once per QuotaCheckInterval.
+// Errors are ignored, as quota alarms are best effort and must not fail writes.
+func (store *storeImplementation) quotaCheckAfterWrite(ctx context.Context) {
+	interval := store.quotaCheckInterval.Load()
+	if interval <= 0 {
+		return
+	}
+
+	now := time.Now().UnixNano()
+	last := store.quotaLastCheckedAt.Load()
+	if now-last < interval {
+		return
+	}
+
+	// Only one caller wins the slot for this interval
+	if !store.quotaLastCheckedAt.CompareAndSwap(last, now) {
+		return
+	}
+
+	_, _ = store.QuotaCheck(ctx)
+}
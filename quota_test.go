@@ -0,0 +1,148 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_Store_QuotaCheck(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	events := []Event{}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:       "vault_quota",
+		VaultMetaTableName:   "vault_meta",
+		DB:                   db,
+		AutomigrateEnabled:   true,
+		ExpiresAtPastAllowed: true,
+		EventHooks: []EventHook{func(ctx context.Context, event Event) {
+			events = append(events, event)
+		}},
+		QuotaThresholds: QuotaThresholds{
+			MaxRecordCount:  1,
+			MaxStorageBytes: 1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	_, err = store.TokenCreate(ctx, "value1", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = store.TokenCreate(ctx, "value2", password, 20, TokenCreateOptions{
+		ExpiresAt: time.Now().UTC().Add(-1 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(events) != 0 {
+		t.Fatalf("Expected no events without automatic checks received [%d]", len(events))
+	}
+
+	report, err := store.QuotaCheck(ctx)
+	if err != nil {
+		t.Fatalf("QuotaCheck: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if report.RecordCount != 2 {
+		t.Fatalf("Expected [2] records received [%d]", report.RecordCount)
+	}
+
+	if report.StorageBytes <= 0 {
+		t.Fatalf("Expected storage bytes to be positive received [%d]", report.StorageBytes)
+	}
+
+	if report.ExpiredUncleaned != 1 {
+		t.Fatalf("Expected [1] expired record received [%d]", report.ExpiredUncleaned)
+	}
+
+	// MaxExpiredUncleaned is not configured, so only two thresholds are exceeded
+	if len(report.Exceeded) != 2 {
+		t.Fatalf("Expected [2] exceeded thresholds received [%v]", report.Exceeded)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected [2] events received [%d]", len(events))
+	}
+
+	if events[0].Type != EVENT_TYPE_QUOTA_RECORD_COUNT {
+		t.Fatalf("Expected [%s] received [%s]", EVENT_TYPE_QUOTA_RECORD_COUNT, events[0].Type)
+	}
+
+	if events[0].Details["value"] != "2" || events[0].Details["threshold"] != "1" {
+		t.Fatalf("Unexpected event details [%v]", events[0].Details)
+	}
+
+	if events[1].Type != EVENT_TYPE_QUOTA_STORAGE_BYTES {
+		t.Fatalf("Expected [%s] received [%s]", EVENT_TYPE_QUOTA_STORAGE_BYTES, events[1].Type)
+	}
+}
+
+func Test_Store_QuotaCheckAfterWrite(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	events := []Event{}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_quota_auto",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		EventHooks: []EventHook{func(ctx context.Context, event Event) {
+			events = append(events, event)
+		}},
+		QuotaThresholds: QuotaThresholds{
+			MaxRecordCount: 1,
+		},
+		QuotaCheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// The first write runs the check, the threshold is not exceeded yet
+	_, err = store.TokenCreate(ctx, "value1", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The second write is inside the interval, so no check runs
+	_, err = store.TokenCreate(ctx, "value2", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(events) != 0 {
+		t.Fatalf("Expected no events inside the check interval received [%d]", len(events))
+	}
+
+	// Let the interval elapse
+	store.quotaLastCheckedAt.Store(0)
+
+	_, err = store.TokenCreate(ctx, "value3", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(events) != 1 || events[0].Type != EVENT_TYPE_QUOTA_RECORD_COUNT {
+		t.Fatalf("Expected one record count event received [%v]", events)
+	}
+}
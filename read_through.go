@@ -0,0 +1,241 @@
+package vaultstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// READ_THROUGH_CACHE_DEFAULT_SIZE is the default number of values kept by ReadThrough
+const READ_THROUGH_CACHE_DEFAULT_SIZE = 10000
+
+// READ_THROUGH_CACHE_DEFAULT_TTL is the default time a value is kept by ReadThrough
+const READ_THROUGH_CACHE_DEFAULT_TTL = time.Minute
+
+// errReadThroughBypass makes ReadThrough read a limited-use token with TokenRead instead
+var errReadThroughBypass = errors.New("read through bypassed")
+
+// readThroughEntry is a decrypted value, valid as long as the ciphertext is unchanged
+type readThroughEntry struct {
+	ciphertext string
+	value      string
+	expiresAt  time.Time
+}
+
+// readThroughCall is an in-flight ReadThrough, shared by the concurrent callers
+type readThroughCall struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// readThroughCache holds the decrypted values and in-flight reads of ReadThrough
+type readThroughCache struct {
+	mu       sync.Mutex
+	entries  map[string]readThroughEntry
+	inFlight map[string]*readThroughCall
+	size     int
+	ttl      time.Duration
+}
+
+// newReadThroughCache creates the cache, zero size and ttl use the defaults
+func newReadThroughCache(size int, ttl time.Duration) *readThroughCache {
+	if size <= 0 {
+		size = READ_THROUGH_CACHE_DEFAULT_SIZE
+	}
+
+	if ttl <= 0 {
+		ttl = READ_THROUGH_CACHE_DEFAULT_TTL
+	}
+
+	return &readThroughCache{
+		entries:  map[string]readThroughEntry{},
+		inFlight: map[string]*readThroughCall{},
+		size:     size,
+		ttl:      ttl,
+	}
+}
+
+// readThroughKey identifies a token read with a password, without keeping the password
+func readThroughKey(token string, password string) string {
+	sum := sha256.Sum256([]byte(token + "\x00" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the cached value if the ciphertext is unchanged and the entry is fresh
+func (c *readThroughCache) get(key string, ciphertext string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || entry.ciphertext != ciphertext || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+
+	return entry.value, true
+}
+
+// set caches a decrypted value, evicting the expired entries (or any entry) when full
+func (c *readThroughCache) set(key string, ciphertext string, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+
+		for k := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = readThroughEntry{
+		ciphertext: ciphertext,
+		value:      value,
+		expiresAt:  time.Now().Add(c.ttl),
+	}
+}
+
+// resize changes the size and ttl, zero values are left unchanged. Entries above
+// the new size are evicted, the entries already cached keep their expiry.
+func (c *readThroughCache) resize(size int, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if size > 0 {
+		c.size = size
+	}
+
+	if ttl > 0 {
+		c.ttl = ttl
+	}
+
+	for k := range c.entries {
+		if len(c.entries) <= c.size {
+			break
+		}
+		delete(c.entries, k)
+	}
+}
+
+// do runs fn once for concurrent calls with the same key, the others wait for its result
+// until their context is done. A waiter whose call failed on the context of the calling
+// goroutine runs fn again with its own.
+func (c *readThroughCache) do(ctx context.Context, key string, fn func() (string, error)) (string, error) {
+	c.mu.Lock()
+	if call, ok := c.inFlight[key]; ok {
+		c.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			return c.do(ctx, key, fn)
+		}
+
+		return call.value, call.err
+	}
+
+	call := &readThroughCall{done: make(chan struct{})}
+	c.inFlight[key] = call
+	c.mu.Unlock()
+
+	call.value, call.err = fn()
+
+	c.mu.Lock()
+	delete(c.inFlight, key)
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.value, call.err
+}
+
+// ReadThrough reads a token like TokenRead, caching the decrypted value in memory.
+// Concurrent reads of the same cold token share a single read, so the key derivation
+// runs exactly once, e.g. when validating a session hit by many requests at once.
+//
+// The record is still read from the database on every call, so expired, revoked
+// and updated tokens are never served from the cache. Concurrent callers share the
+// result of the first one, waiting for it until their own context is done.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read
+// - password: The password to use for decryption
+//
+// Returns:
+// - value: The value of the token
+// - err: An error if something went wrong
+func (store *storeImplementation) ReadThrough(ctx context.Context, token string, password string) (string, error) {
+	ctx, span := store.traceStart(ctx, "ReadThrough")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "ReadThrough", token); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	// The table and namespace are part of the key, so a tenant never shares the read of another
+	tableName, err := store.vaultTableNameFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	namespace, err := store.namespaceFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	key := readThroughKey(tableName+"\x00"+namespace+"\x00"+token, password)
+
+	value, err := store.readThroughCache.do(ctx, key, func() (string, error) {
+		entry, metas, err := store.tokenReadableRecord(ctx, token)
+		if err != nil {
+			return "", err
+		}
+
+		// Limited-use tokens are read with TokenRead, counting the read
+		if metas[META_KEY_READS_REMAINING] != nil {
+			return "", errReadThroughBypass
+		}
+
+		if value, ok := store.readThroughCache.get(key, entry.GetValue()); ok {
+			return value, nil
+		}
+
+		decoded, err := decode(ctx, entry.GetValue(), password, store.cryptoConfig)
+		if err != nil {
+			return "", err
+		}
+
+		if err := store.valueValidate(ctx, entry, decoded); err != nil {
+			return "", err
+		}
+
+		if tokenStreamed(metas[META_KEY_STREAM], decoded) {
+			return "", ErrTokenStreamed
+		}
+
+		store.readThroughCache.set(key, entry.GetValue(), decoded)
+
+		return decoded, nil
+	})
+
+	// Each read of a limited-use token is counted, it is never cached nor shared
+	if errors.Is(err, errReadThroughBypass) {
+		return store.TokenRead(ctx, token, password)
+	}
+
+	return value, err
+}
@@ -0,0 +1,182 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func Test_Store_ReadThrough(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+	db.SetMaxOpenConns(1)
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_read_through",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "session", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := store.ReadThrough(ctx, token, password)
+			if err == nil && value != "session" {
+				t.Errorf("Expected [session] received [%v]", value)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("ReadThrough: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	if count := store.KDFStats()[KDF_OPERATION_DECRYPT].Count; count != 1 {
+		t.Fatalf("Expected the value to be decrypted once received [%v]", count)
+	}
+
+	// Updated values are not served from the cache
+	if err := store.TokenUpdate(ctx, token, "session_updated", password); err != nil {
+		t.Fatalf("TokenUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.ReadThrough(ctx, token, password)
+	if err != nil {
+		t.Fatalf("ReadThrough: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "session_updated" {
+		t.Fatalf("Expected [session_updated] received [%v]", value)
+	}
+
+	// The password is part of the cache key
+	if _, err := store.ReadThrough(ctx, token, "wrong_password_that_is_long_enough_for_security"); err == nil {
+		t.Fatal("Expected a wrong password to fail")
+	}
+
+	// Deleted tokens are not served from the cache
+	if err := store.TokenDelete(ctx, token); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.ReadThrough(ctx, token, password); err == nil {
+		t.Fatal("Expected a deleted token to fail")
+	}
+}
+
+func Test_readThroughCache(t *testing.T) {
+	cache := newReadThroughCache(2, 0)
+
+	cache.set("a", "ciphertext_a", "value_a")
+	cache.set("b", "ciphertext_b", "value_b")
+	cache.set("c", "ciphertext_c", "value_c")
+
+	if len(cache.entries) != 2 {
+		t.Fatalf("Expected 2 entries received [%v]", len(cache.entries))
+	}
+
+	if value, ok := cache.get("c", "ciphertext_c"); !ok || value != "value_c" {
+		t.Fatalf("Expected [value_c] received [%v]", value)
+	}
+
+	if _, ok := cache.get("c", "ciphertext_changed"); ok {
+		t.Fatal("Expected a changed ciphertext to miss")
+	}
+}
+
+func Test_readThroughCache_WaiterContext(t *testing.T) {
+	cache := newReadThroughCache(2, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		_, _ = cache.do(context.Background(), "a", func() (string, error) {
+			close(started)
+			<-release
+			return "", context.Canceled
+		})
+	}()
+	<-started
+
+	// A waiter stops waiting when its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := cache.do(ctx, "a", func() (string, error) { return "value_a", nil }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected [context.Canceled] received [%v]", err)
+	}
+
+	// A waiter does not share the context error of the first call
+	result := make(chan string, 1)
+	go func() {
+		value, _ := cache.do(context.Background(), "a", func() (string, error) { return "value_a", nil })
+		result <- value
+	}()
+
+	close(release)
+	<-done
+
+	if value := <-result; value != "value_a" {
+		t.Fatalf("Expected [value_a] received [%v]", value)
+	}
+}
+
+func Test_Store_ReadThrough_TableSuffix(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for _, suffix := range []string{"tenantA", "tenantB"} {
+		if err := store.AutoMigrateTableSuffix(suffix); err != nil {
+			t.Fatalf("AutoMigrateTableSuffix: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// The same token and password in two tenant tables
+	for _, suffix := range []string{"tenantA", "tenantB"} {
+		if err := store.TokenCreateCustom(WithTableSuffix(ctx, suffix), "tk_shared_token", suffix+"_value", password); err != nil {
+			t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	for _, suffix := range []string{"tenantA", "tenantB", "tenantA"} {
+		value, err := store.ReadThrough(WithTableSuffix(ctx, suffix), "tk_shared_token", password)
+		if err != nil {
+			t.Fatalf("ReadThrough: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if value != suffix+"_value" {
+			t.Fatalf("Expected [%v] received [%v]", suffix+"_value", value)
+		}
+	}
+}
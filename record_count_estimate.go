@@ -0,0 +1,68 @@
+package vaultstore
+
+import (
+	"context"
+	"database/sql"
+)
+
+// RecordCountEstimate returns an estimate of the number of rows in the vault table,
+// read from the database statistics instead of scanning the table
+//
+// The estimate includes soft deleted and expired records. PostgreSQL reads
+// pg_class.reltuples, MySQL reads information_schema.TABLES. Other databases,
+// and tables without statistics yet, fall back to an exact count.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - count: The estimated number of rows
+// - err: An error if something went wrong
+func (store *storeImplementation) RecordCountEstimate(ctx context.Context) (int64, error) {
+	ctx, span := store.traceStart(ctx, "RecordCountEstimate")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordCountEstimate", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
+	tableName, err := store.vaultTableNameFromContext(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	var estimate sql.NullInt64
+
+	switch store.gormDB.Dialector.Name() {
+	case DIALECT_POSTGRES:
+		err = store.gormDBFromContext(ctx).
+			Raw("SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(?)", tableName).
+			Scan(&estimate).Error
+	case DIALECT_MYSQL:
+		err = store.gormDBFromContext(ctx).
+			Raw("SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", tableName).
+			Scan(&estimate).Error
+	}
+
+	if err != nil {
+		return -1, err
+	}
+
+	// PostgreSQL reports -1 for tables that were never analyzed
+	if estimate.Valid && estimate.Int64 >= 0 {
+		return estimate.Int64, nil
+	}
+
+	var count int64
+	err = store.vaultDB(ctx).Count(&count).Error
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,36 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_Store_RecordCountEstimate(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := store.TokenCreate(ctx, "test_value", "test_password_that_is_long_enough_for_security_32chars", 20)
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	// SQLite has no table statistics, so the estimate is the exact count
+	count, err := store.RecordCountEstimate(ctx)
+	if err != nil {
+		t.Fatalf("RecordCountEstimate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected count [3] received [%d]", count)
+	}
+
+	_, err = store.RecordCountEstimate(WithTableSuffix(ctx, "invalid suffix!"))
+	if err == nil {
+		t.Fatal("Expected error for invalid table suffix")
+	}
+}
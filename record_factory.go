@@ -0,0 +1,122 @@
+package vaultstore
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecordFactory creates the records returned by a store, see NewStoreOptions.RecordFactory.
+// Keys of Data() beyond the vault columns are persisted as custom columns, e.g. owner_id.
+type RecordFactory interface {
+	// NewRecord creates a new record with default values, as NewRecord does
+	NewRecord() RecordInterface
+	// NewRecordFromExistingData creates a record from a stored row, as NewRecordFromExistingData does
+	NewRecordFromExistingData(data map[string]string) RecordInterface
+}
+
+// recordBaseColumns are the columns of the vault table mapped by gormVaultRecord
+var recordBaseColumns = []string{
+	COLUMN_ID,
+	COLUMN_VAULT_TOKEN,
+	COLUMN_VAULT_VALUE,
+	COLUMN_CREATED_AT,
+	COLUMN_UPDATED_AT,
+	COLUMN_EXPIRES_AT,
+	COLUMN_SOFT_DELETED_AT,
+}
+
+// recordFactoryExtraColumns returns the custom columns of the records created by the factory
+func recordFactoryExtraColumns(factory RecordFactory) []string {
+	columns := []string{}
+	for column := range factory.NewRecord().Data() {
+		if !slices.Contains(recordBaseColumns, column) {
+			columns = append(columns, column)
+		}
+	}
+	slices.Sort(columns)
+	return columns
+}
+
+// gormRecordFromRecord creates a GORM record from a RecordInterface, including the custom columns
+func (store *storeImplementation) gormRecordFromRecord(record RecordInterface) *gormVaultRecord {
+	gormRecord := fromRecordInterface(record)
+
+	if len(store.recordExtraColumns) == 0 {
+		return gormRecord
+	}
+
+	data := record.Data()
+	gormRecord.Extra = map[string]string{}
+	for _, column := range store.recordExtraColumns {
+		if value, ok := data[column]; ok {
+			gormRecord.Extra[column] = value
+		}
+	}
+
+	return gormRecord
+}
+
+// recordsFind runs the query into GORM records. With custom columns the rows are
+// scanned as maps, as gormVaultRecord only maps the vault columns.
+func (store *storeImplementation) recordsFind(db *gorm.DB) ([]gormVaultRecord, error) {
+	var gormRecords []gormVaultRecord
+
+	if len(store.recordExtraColumns) == 0 {
+		err := db.Find(&gormRecords).Error
+		return gormRecords, err
+	}
+
+	var rows []map[string]interface{}
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	gormRecords = make([]gormVaultRecord, len(rows))
+	for i, row := range rows {
+		gormRecords[i] = store.gormRecordFromRow(row)
+	}
+
+	return gormRecords, nil
+}
+
+// gormRecordFromRow converts a row scanned as a map to a GORM record
+func (store *storeImplementation) gormRecordFromRow(row map[string]interface{}) gormVaultRecord {
+	gormRecord := gormVaultRecord{
+		ID:            rowString(row[COLUMN_ID]),
+		Token:         rowString(row[COLUMN_VAULT_TOKEN]),
+		Value:         rowString(row[COLUMN_VAULT_VALUE]),
+		CreatedAt:     rowString(row[COLUMN_CREATED_AT]),
+		UpdatedAt:     rowString(row[COLUMN_UPDATED_AT]),
+		ExpiresAt:     rowString(row[COLUMN_EXPIRES_AT]),
+		SoftDeletedAt: rowString(row[COLUMN_SOFT_DELETED_AT]),
+		Extra:         map[string]string{},
+	}
+
+	for _, column := range store.recordExtraColumns {
+		if value, ok := row[column]; ok {
+			gormRecord.Extra[column] = rowString(value)
+		}
+	}
+
+	return gormRecord
+}
+
+// rowString converts a value scanned by the database driver to a string,
+// the same way it is converted when scanned into a string field
+func rowString(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case []byte:
+		return string(typed)
+	case time.Time:
+		return typed.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
@@ -0,0 +1,76 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+)
+
+// ownerRecordFactory creates map-backed records with an owner_id custom column
+type ownerRecordFactory struct{}
+
+func (ownerRecordFactory) NewRecord() RecordInterface {
+	record := NewRecord().(*recordImplementation)
+	record.Set("owner_id", "")
+	return record
+}
+
+func (ownerRecordFactory) NewRecordFromExistingData(data map[string]string) RecordInterface {
+	return NewRecordFromExistingData(data)
+}
+
+func Test_Store_RecordFactory(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_record_factory",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		RecordFactory:      ownerRecordFactory{},
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.gormDB.Exec("ALTER TABLE vault_record_factory ADD COLUMN owner_id VARCHAR(40) NOT NULL DEFAULT ''").Error
+	if err != nil {
+		t.Fatalf("Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+
+	record := store.newRecord()
+	record.SetToken("tk_factory_record_1")
+	record.SetValue("value")
+	record.(*recordImplementation).Set("owner_id", "user_1")
+
+	if err := store.RecordCreate(ctx, record); err != nil {
+		t.Fatalf("RecordCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	found, err := store.RecordFindByID(ctx, record.GetID())
+	if err != nil {
+		t.Fatalf("RecordFindByID: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if found == nil || found.Data()["owner_id"] != "user_1" {
+		t.Fatalf("Expected the custom column to be read back received [%v]", found)
+	}
+
+	found.(*recordImplementation).Set("owner_id", "user_2")
+	if err := store.RecordUpdate(ctx, found); err != nil {
+		t.Fatalf("RecordUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	found, err = store.RecordFindByToken(ctx, "tk_factory_record_1")
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if found.Data()["owner_id"] != "user_2" || found.GetValue() != "value" {
+		t.Fatalf("Expected the updated custom column received [%v]", found.Data())
+	}
+}
@@ -0,0 +1,197 @@
+package vaultstore
+
+import (
+	"github.com/dracory/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// Change flags of the fast record columns
+const (
+	fastRecordChangedID uint8 = 1 << iota
+	fastRecordChangedToken
+	fastRecordChangedValue
+	fastRecordChangedCreatedAt
+	fastRecordChangedUpdatedAt
+	fastRecordChangedExpiresAt
+	fastRecordChangedSoftDeletedAt
+)
+
+// == CLASS ==================================================================
+
+// fastRecordImplementation is a struct-backed RecordInterface implementation.
+// Unlike the map-backed recordImplementation, it needs a single allocation
+// per record, which matters for latency-sensitive workloads.
+type fastRecordImplementation struct {
+	id            string
+	token         string
+	value         string
+	createdAt     string
+	updatedAt     string
+	expiresAt     string
+	softDeletedAt string
+	changed       uint8
+
+	// Custom columns, see NewStoreOptions.ExtraColumns
+	extra        map[string]string
+	extraChanged map[string]bool
+}
+
+var _ RecordInterface = (*fastRecordImplementation)(nil) // verify it extends the interface
+
+// == CONSTRUCTORS ===========================================================
+
+// NewFastRecord creates a new struct-backed record, see NewStoreOptions.FastRecordsEnabled
+func NewFastRecord() RecordInterface {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	return (&fastRecordImplementation{}).
+		SetID(uid.HumanUid()).
+		SetCreatedAt(now).
+		SetUpdatedAt(now).
+		SetExpiresAt(MAX_DATETIME).
+		SetSoftDeletedAt(MAX_DATETIME)
+}
+
+// == METHODS ================================================================
+
+func (v *fastRecordImplementation) Data() map[string]string {
+	if len(v.extra) > 0 {
+		data := v.baseData()
+		for key, value := range v.extra {
+			data[key] = value
+		}
+		return data
+	}
+
+	return v.baseData()
+}
+
+// baseData returns the vault columns of the record
+func (v *fastRecordImplementation) baseData() map[string]string {
+	return map[string]string{
+		COLUMN_ID:              v.id,
+		COLUMN_VAULT_TOKEN:     v.token,
+		COLUMN_VAULT_VALUE:     v.value,
+		COLUMN_CREATED_AT:      v.createdAt,
+		COLUMN_UPDATED_AT:      v.updatedAt,
+		COLUMN_EXPIRES_AT:      v.expiresAt,
+		COLUMN_SOFT_DELETED_AT: v.softDeletedAt,
+	}
+}
+
+func (v *fastRecordImplementation) DataChanged() map[string]string {
+	changed := map[string]string{}
+
+	if v.changed&fastRecordChangedID != 0 {
+		changed[COLUMN_ID] = v.id
+	}
+	if v.changed&fastRecordChangedToken != 0 {
+		changed[COLUMN_VAULT_TOKEN] = v.token
+	}
+	if v.changed&fastRecordChangedValue != 0 {
+		changed[COLUMN_VAULT_VALUE] = v.value
+	}
+	if v.changed&fastRecordChangedCreatedAt != 0 {
+		changed[COLUMN_CREATED_AT] = v.createdAt
+	}
+	if v.changed&fastRecordChangedUpdatedAt != 0 {
+		changed[COLUMN_UPDATED_AT] = v.updatedAt
+	}
+	if v.changed&fastRecordChangedExpiresAt != 0 {
+		changed[COLUMN_EXPIRES_AT] = v.expiresAt
+	}
+	if v.changed&fastRecordChangedSoftDeletedAt != 0 {
+		changed[COLUMN_SOFT_DELETED_AT] = v.softDeletedAt
+	}
+	for key := range v.extraChanged {
+		changed[key] = v.extra[key]
+	}
+
+	return changed
+}
+
+// == SETTERS AND GETTERS ====================================================
+
+func (v *fastRecordImplementation) GetCreatedAt() string {
+	return v.createdAt
+}
+
+func (v *fastRecordImplementation) SetCreatedAt(createdAt string) RecordInterface {
+	v.createdAt = createdAt
+	v.changed |= fastRecordChangedCreatedAt
+	return v
+}
+
+func (v *fastRecordImplementation) GetExpiresAt() string {
+	return v.expiresAt
+}
+
+func (v *fastRecordImplementation) SetExpiresAt(expiresAt string) RecordInterface {
+	v.expiresAt = expiresAt
+	v.changed |= fastRecordChangedExpiresAt
+	return v
+}
+
+func (v *fastRecordImplementation) GetSoftDeletedAt() string {
+	return v.softDeletedAt
+}
+
+func (v *fastRecordImplementation) SetSoftDeletedAt(softDeletedAt string) RecordInterface {
+	v.softDeletedAt = softDeletedAt
+	v.changed |= fastRecordChangedSoftDeletedAt
+	return v
+}
+
+func (v *fastRecordImplementation) GetID() string {
+	return v.id
+}
+
+func (v *fastRecordImplementation) SetID(id string) RecordInterface {
+	v.id = id
+	v.changed |= fastRecordChangedID
+	return v
+}
+
+func (v *fastRecordImplementation) GetToken() string {
+	return v.token
+}
+
+func (v *fastRecordImplementation) SetToken(token string) RecordInterface {
+	v.token = token
+	v.changed |= fastRecordChangedToken
+	return v
+}
+
+func (v *fastRecordImplementation) GetUpdatedAt() string {
+	return v.updatedAt
+}
+
+func (v *fastRecordImplementation) SetUpdatedAt(updatedAt string) RecordInterface {
+	v.updatedAt = updatedAt
+	v.changed |= fastRecordChangedUpdatedAt
+	return v
+}
+
+func (v *fastRecordImplementation) GetValue() string {
+	return v.value
+}
+
+func (v *fastRecordImplementation) SetValue(value string) RecordInterface {
+	v.value = value
+	v.changed |= fastRecordChangedValue
+	return v
+}
+
+func (v *fastRecordImplementation) GetExtra(key string) string {
+	return v.extra[key]
+}
+
+func (v *fastRecordImplementation) SetExtra(key string, value string) RecordInterface {
+	if v.extra == nil {
+		v.extra = map[string]string{}
+		v.extraChanged = map[string]bool{}
+	}
+	v.extra[key] = value
+	v.extraChanged[key] = true
+	return v
+}
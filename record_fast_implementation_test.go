@@ -0,0 +1,78 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_FastRecord_DataChanged(t *testing.T) {
+	record := (&fastRecordImplementation{}).SetToken("token").SetValue("value")
+
+	changed := record.DataChanged()
+	if len(changed) != 2 {
+		t.Fatalf("Expected [2] changed columns received [%v]", changed)
+	}
+
+	if changed[COLUMN_VAULT_TOKEN] != "token" || changed[COLUMN_VAULT_VALUE] != "value" {
+		t.Fatalf("Expected token and value to be changed received [%v]", changed)
+	}
+
+	// Records read from the database start unchanged
+	fromGorm := (&gormVaultRecord{ID: "id", Token: "token"}).toFastRecord()
+	if len(fromGorm.DataChanged()) != 0 {
+		t.Fatalf("Expected no changed columns received [%v]", fromGorm.DataChanged())
+	}
+
+	if fromGorm.GetExpiresAt() != MAX_DATETIME {
+		t.Fatalf("Expected [%v] received [%v]", MAX_DATETIME, fromGorm.GetExpiresAt())
+	}
+}
+
+func Test_Store_FastRecordsEnabled(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_fast_records",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		FastRecordsEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "fast_value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, ok := record.(*fastRecordImplementation); !ok {
+		t.Fatalf("Expected a fast record received [%T]", record)
+	}
+
+	err = store.TokenUpdate(ctx, token, "updated_fast_value", password)
+	if err != nil {
+		t.Fatalf("TokenUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "updated_fast_value" {
+		t.Fatalf("Expected [updated_fast_value] received [%v]", value)
+	}
+}
@@ -97,3 +97,12 @@ func (v *recordImplementation) SetValue(value string) RecordInterface {
 	v.Set(COLUMN_VAULT_VALUE, value)
 	return v
 }
+
+func (v *recordImplementation) GetExtra(key string) string {
+	return v.Get(key)
+}
+
+func (v *recordImplementation) SetExtra(key string, value string) RecordInterface {
+	v.Set(key, value)
+	return v
+}
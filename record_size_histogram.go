@@ -0,0 +1,233 @@
+package vaultstore
+
+import (
+	"context"
+	"sort"
+)
+
+// recordSizeHistogramBounds are the upper bounds in bytes of the buckets of RecordSizeHistogram,
+// the last bucket holding the larger values
+var recordSizeHistogramBounds = []int64{256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+// RECORD_SIZE_LARGEST_COUNT is the number of largest records listed by RecordSizeHistogram
+const RECORD_SIZE_LARGEST_COUNT = 10
+
+// RecordSizeBucket counts the records whose ciphertext size is at most the upper bound
+// and larger than the upper bound of the previous bucket
+type RecordSizeBucket struct {
+	// UpperBound is the largest size in bytes of the bucket, 0 for the last, unbounded bucket
+	UpperBound int64
+	Count      int64
+}
+
+// RecordSize is the ciphertext size of the value of a token
+type RecordSize struct {
+	Token string
+	Size  int64
+}
+
+// RecordSizeReport is the distribution of the ciphertext sizes of the records
+type RecordSizeReport struct {
+	Buckets    []RecordSizeBucket
+	Count      int64
+	TotalBytes int64
+	MaxBytes   int64
+	// Largest lists the largest records, largest first
+	Largest []RecordSize
+}
+
+// recordSizeRow is a record ID with the size of its stored value
+type recordSizeRow struct {
+	ID     string `gorm:"column:id"`
+	Token  string `gorm:"column:vault_token"`
+	Size   int64  `gorm:"column:value_size"`
+	Marker string `gorm:"column:chunk_marker"`
+}
+
+// RecordSizeHistogram reports the distribution of the ciphertext sizes of the records,
+// soft deleted ones excluded, without decrypting them. Use it to find values misused
+// as file storage, or to choose a value size limit. The ciphertext is larger than
+// the plaintext by the encoding and the salt, nonce and tag of the encryption.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - report: The size buckets, totals and largest records
+// - err: An error if something went wrong
+func (store *storeImplementation) RecordSizeHistogram(ctx context.Context) (report RecordSizeReport, err error) {
+	ctx, span := store.traceStart(ctx, "RecordSizeHistogram")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordSizeHistogram", ""); err != nil {
+		return RecordSizeReport{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	report.Buckets = make([]RecordSizeBucket, len(recordSizeHistogramBounds)+1)
+	for i, bound := range recordSizeHistogramBounds {
+		report.Buckets[i].UpperBound = bound
+	}
+
+	lastID := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		rows, err := store.recordSizeRows(ctx, lastID)
+		if err != nil {
+			return report, err
+		}
+
+		if len(rows) == 0 {
+			return report, nil
+		}
+		lastID = rows[len(rows)-1].ID
+
+		for _, row := range rows {
+			report.Count++
+			report.TotalBytes += row.Size
+			report.MaxBytes = max(report.MaxBytes, row.Size)
+
+			bucket := sort.Search(len(recordSizeHistogramBounds), func(i int) bool {
+				return row.Size <= recordSizeHistogramBounds[i]
+			})
+			report.Buckets[bucket].Count++
+
+			report.Largest = recordSizeLargestAdd(report.Largest, RecordSize{Token: row.Token, Size: row.Size})
+		}
+	}
+}
+
+// recordSizeRows returns the next batch of records after the ID with their ciphertext sizes
+func (store *storeImplementation) recordSizeRows(ctx context.Context, afterID string) ([]recordSizeRow, error) {
+	var rows []recordSizeRow
+	err := store.recordQueryFilter(store.vaultDB(ctx), RecordQuery()).
+		Select(store.recordSizeColumns(), CHUNKED_VALUE_PREFIX+"%").
+		Where(COLUMN_ID+" > ?", afterID).
+		Order(COLUMN_ID + " ASC").
+		Limit(maxRecordsInMemory).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.recordSizeRowsChunksResolve(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// recordSizeColumns returns the selected columns of a recordSizeRow, the value itself
+// only for chunk markers. The placeholder is the LIKE pattern of the markers.
+func (store *storeImplementation) recordSizeColumns() string {
+	return COLUMN_ID + ", " + COLUMN_VAULT_TOKEN + ", " +
+		store.lengthExpr(COLUMN_VAULT_VALUE) + " AS value_size, " +
+		"CASE WHEN " + COLUMN_VAULT_VALUE + " LIKE ? THEN " + COLUMN_VAULT_VALUE + " ELSE '' END AS chunk_marker"
+}
+
+// recordValueSizes returns the ciphertext sizes of the values of the records, by record ID
+func (store *storeImplementation) recordValueSizes(ctx context.Context, recordIDs []string) (map[string]int64, error) {
+	sizes := map[string]int64{}
+	if len(recordIDs) == 0 {
+		return sizes, nil
+	}
+
+	var rows []recordSizeRow
+	err := store.vaultDB(ctx).
+		Select(store.recordSizeColumns(), CHUNKED_VALUE_PREFIX+"%").
+		Where(COLUMN_ID+" IN ?", recordIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.recordSizeRowsChunksResolve(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		sizes[row.ID] = row.Size
+	}
+
+	return sizes, nil
+}
+
+// recordSizeRowsChunksResolve replaces the size of the chunk markers with the size of
+// the chunked ciphertexts. Only the chunks of the current ciphertext are counted,
+// those of a replaced value may not be garbage collected yet.
+func (store *storeImplementation) recordSizeRowsChunksResolve(ctx context.Context, rows []recordSizeRow) error {
+	hashes := map[string]string{}
+	for _, row := range rows {
+		if row.Marker == "" {
+			continue
+		}
+
+		_, hash, err := parseChunkedValue(row.Marker)
+		if err != nil {
+			return err
+		}
+		hashes[row.ID] = hash
+	}
+
+	if len(hashes) == 0 || !store.isValueChunkingEnabled() {
+		return nil
+	}
+
+	recordIDs := make([]string, 0, len(hashes))
+	for recordID := range hashes {
+		recordIDs = append(recordIDs, recordID)
+	}
+
+	var chunkSizes []struct {
+		RecordID  string `gorm:"column:record_id"`
+		ValueHash string `gorm:"column:value_hash"`
+		Size      int64  `gorm:"column:value_size"`
+	}
+	err := store.valueChunkDB(ctx).
+		Select("record_id, value_hash, SUM("+store.lengthExpr("chunk_data")+") AS value_size").
+		Where("record_id IN ?", recordIDs).
+		Group("record_id, value_hash").
+		Scan(&chunkSizes).Error
+	if err != nil {
+		return err
+	}
+
+	sizes := map[string]int64{}
+	for _, chunkSize := range chunkSizes {
+		if hashes[chunkSize.RecordID] == chunkSize.ValueHash {
+			sizes[chunkSize.RecordID] = chunkSize.Size
+		}
+	}
+
+	for i := range rows {
+		if size, ok := sizes[rows[i].ID]; ok {
+			rows[i].Size = size
+		}
+	}
+
+	return nil
+}
+
+// recordSizeLargestAdd adds the record to the largest records if it is one of them
+func recordSizeLargestAdd(largest []RecordSize, record RecordSize) []RecordSize {
+	if len(largest) == RECORD_SIZE_LARGEST_COUNT && record.Size <= largest[len(largest)-1].Size {
+		return largest
+	}
+
+	i := sort.Search(len(largest), func(i int) bool {
+		return largest[i].Size < record.Size
+	})
+	largest = append(largest, RecordSize{})
+	copy(largest[i+1:], largest[i:])
+	largest[i] = record
+
+	if len(largest) > RECORD_SIZE_LARGEST_COUNT {
+		largest = largest[:RECORD_SIZE_LARGEST_COUNT]
+	}
+
+	return largest
+}
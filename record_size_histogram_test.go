@@ -0,0 +1,106 @@
+package vaultstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func Test_Store_RecordSizeHistogram(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:      "vault_sizes",
+		VaultMetaTableName:  "vault_meta",
+		DB:                  db,
+		AutomigrateEnabled:  true,
+		ValueChunkThreshold: 1024,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	if err := store.TokenCreateCustom(ctx, "small", "value", password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Chunked, the size is the one of the chunks and not of the marker
+	if err := store.TokenCreateCustom(ctx, "large", strings.Repeat("x", 10000), password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenCreateCustom(ctx, "deleted", strings.Repeat("x", 100000), password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenSoftDelete(ctx, "deleted"); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	report, err := store.RecordSizeHistogram(ctx)
+	if err != nil {
+		t.Fatalf("RecordSizeHistogram: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if report.Count != 2 {
+		t.Fatalf("Expected [2] records received [%d]", report.Count)
+	}
+
+	if len(report.Largest) != 2 || report.Largest[0].Token != "large" || report.Largest[1].Token != "small" {
+		t.Fatalf("Expected the largest records [large small] received [%+v]", report.Largest)
+	}
+
+	if report.MaxBytes != report.Largest[0].Size || report.MaxBytes <= 10000 || report.MaxBytes > 16384 {
+		t.Fatalf("Expected the ciphertext size of the large value received [%d]", report.MaxBytes)
+	}
+
+	if report.TotalBytes != report.Largest[0].Size+report.Largest[1].Size {
+		t.Fatalf("Expected [%d] total bytes received [%d]", report.Largest[0].Size+report.Largest[1].Size, report.TotalBytes)
+	}
+
+	if report.Buckets[0].UpperBound != 256 || report.Buckets[0].Count != 1 || report.Buckets[3].Count != 1 {
+		t.Fatalf("Expected one record of at most 256 and 16384 bytes received [%+v]", report.Buckets)
+	}
+
+	if last := report.Buckets[len(report.Buckets)-1]; last.UpperBound != 0 || last.Count != 0 {
+		t.Fatalf("Expected an empty unbounded bucket received [%+v]", last)
+	}
+
+	items, err := store.TokenList(ctx, TokenQueryOptions{})
+	if err != nil {
+		t.Fatalf("TokenList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for _, item := range items {
+		if item.Token == "large" && item.ValueSize != report.MaxBytes {
+			t.Fatalf("Expected the value size [%d] received [%d]", report.MaxBytes, item.ValueSize)
+		}
+	}
+}
+
+func Test_RecordSizeLargestAdd(t *testing.T) {
+	largest := []RecordSize{}
+	for size := int64(1); size <= RECORD_SIZE_LARGEST_COUNT+5; size++ {
+		largest = recordSizeLargestAdd(largest, RecordSize{Token: "t", Size: (size * 7) % 20})
+	}
+
+	if len(largest) != RECORD_SIZE_LARGEST_COUNT {
+		t.Fatalf("Expected [%d] records received [%d]", RECORD_SIZE_LARGEST_COUNT, len(largest))
+	}
+
+	for i := 1; i < len(largest); i++ {
+		if largest[i-1].Size < largest[i].Size {
+			t.Fatalf("Expected the records largest first received [%+v]", largest)
+		}
+	}
+
+	if largest[0].Size != 18 {
+		t.Fatalf("Expected the largest size [18] received [%d]", largest[0].Size)
+	}
+}
@@ -0,0 +1,101 @@
+package vaultstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// REDACT_HASH_LENGTH_DEFAULT is the number of hex characters of the hash kept by Redact
+const REDACT_HASH_LENGTH_DEFAULT = 12
+
+// Redactor turns tokens and values into stable, non-sensitive identifiers,
+// so logs, audit trails and webhooks can refer to vault objects without leaking them.
+//
+// The zero value is ready to use. Set Key to make the identifiers a keyed hash
+// (HMAC-SHA256), so they cannot be confirmed by hashing guessed tokens or values.
+type Redactor struct {
+	// Key is the optional HMAC key (empty = plain SHA-256)
+	Key []byte
+	// Length is the number of hex characters kept (0 = REDACT_HASH_LENGTH_DEFAULT, max 64)
+	Length int
+}
+
+// Redact returns the stable short identifier of a token, e.g. "tk_3f9a0c1b2d4e".
+// The same token always gives the same identifier for the same Redactor.
+//
+// Parameters:
+// - token: the token to redact
+//
+// Returns:
+// - string: the redacted identifier (empty for an empty token)
+func (redactor Redactor) Redact(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	return "tk_" + redactor.hash(token)
+}
+
+// RedactValue returns a fenced placeholder for a secret value, e.g.
+// "[redacted len=12 sha=3f9a0c1b2d4e]". The length and the short hash let
+// two values be compared in logs without revealing them.
+//
+// Parameters:
+// - value: the value to redact
+//
+// Returns:
+// - string: the fenced placeholder
+func (redactor Redactor) RedactValue(value string) string {
+	return "[redacted len=" + strconv.Itoa(len(value)) + " sha=" + redactor.hash(value) + "]"
+}
+
+// hash returns the truncated hex hash of the text
+func (redactor Redactor) hash(text string) string {
+	var sum []byte
+	if len(redactor.Key) > 0 {
+		mac := hmac.New(sha256.New, redactor.Key)
+		mac.Write([]byte(text))
+		sum = mac.Sum(nil)
+	} else {
+		digest := sha256.Sum256([]byte(text))
+		sum = digest[:]
+	}
+
+	encoded := hex.EncodeToString(sum)
+
+	length := redactor.Length
+	if length <= 0 {
+		length = REDACT_HASH_LENGTH_DEFAULT
+	}
+	if length > len(encoded) {
+		length = len(encoded)
+	}
+
+	return encoded[:length]
+}
+
+// Redact returns the stable short identifier of a token using the default Redactor,
+// see Redactor.Redact
+func Redact(token string) string {
+	return Redactor{}.Redact(token)
+}
+
+// RedactValue returns a fenced placeholder for a secret value using the default
+// Redactor, see Redactor.RedactValue
+func RedactValue(value string) string {
+	return Redactor{}.RedactValue(value)
+}
+
+// Redact returns the redacted identifier of a token using the store's Redactor,
+// the same identifier set on the events as Event.TokenRedacted
+//
+// Parameters:
+// - token: the token to redact
+//
+// Returns:
+// - string: the redacted identifier
+func (store *storeImplementation) Redact(token string) string {
+	return store.redactor.Redact(token)
+}
@@ -0,0 +1,103 @@
+package vaultstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Redact(t *testing.T) {
+	token := "tk_abcdefghijklmnopqrstuvwxyz"
+
+	redacted := Redact(token)
+	if redacted != Redact(token) {
+		t.Fatalf("Expected a stable identifier received [%v] and [%v]", redacted, Redact(token))
+	}
+
+	if !strings.HasPrefix(redacted, "tk_") || len(redacted) != 3+REDACT_HASH_LENGTH_DEFAULT {
+		t.Fatalf("Expected [tk_] followed by %d hex chars received [%v]", REDACT_HASH_LENGTH_DEFAULT, redacted)
+	}
+
+	if strings.Contains(redacted, "abcdef") {
+		t.Fatalf("Expected the token not to be revealed received [%v]", redacted)
+	}
+
+	if Redact("") != "" {
+		t.Fatalf("Expected an empty identifier for an empty token received [%v]", Redact(""))
+	}
+
+	if Redact(token) == Redact(token+"x") {
+		t.Fatal("Expected different tokens to give different identifiers")
+	}
+
+	keyed := Redactor{Key: []byte("key"), Length: 20}.Redact(token)
+	if keyed == redacted || len(keyed) != 3+20 {
+		t.Fatalf("Expected a keyed identifier of 20 hex chars received [%v]", keyed)
+	}
+
+	if (Redactor{Length: 100}).Redact(token) != "tk_"+strToSHA256Hash(token) {
+		t.Fatal("Expected the length to be capped to the full hash")
+	}
+}
+
+func Test_RedactValue(t *testing.T) {
+	value := "secret value"
+
+	redacted := RedactValue(value)
+	if redacted != "[redacted len=12 sha="+strToSHA256Hash(value)[:REDACT_HASH_LENGTH_DEFAULT]+"]" {
+		t.Fatalf("Unexpected placeholder [%v]", redacted)
+	}
+
+	if strings.Contains(redacted, value) {
+		t.Fatalf("Expected the value not to be revealed received [%v]", redacted)
+	}
+}
+
+func Test_Store_Redact_Events(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	redactor := Redactor{Key: []byte("redaction key")}
+	var events []Event
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_redact",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		Redactor:           redactor,
+		EventHooks: []EventHook{func(ctx context.Context, event Event) {
+			events = append(events, event)
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if store.Redact(token) != redactor.Redact(token) {
+		t.Fatalf("Expected the store to use its Redactor received [%v]", store.Redact(token))
+	}
+
+	if _, err := store.TokenCheckout(ctx, token, "alice", time.Minute); err != nil {
+		t.Fatalf("TokenCheckout: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event received [%v]", len(events))
+	}
+
+	if events[0].TokenRedacted != redactor.Redact(token) {
+		t.Fatalf("Expected [%v] received [%v]", redactor.Redact(token), events[0].TokenRedacted)
+	}
+}
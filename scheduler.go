@@ -0,0 +1,456 @@
+package vaultstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"gorm.io/gorm"
+)
+
+// Built-in scheduler job names
+const (
+	SCHEDULER_JOB_EXPIRED_CLEANUP  = "expired_cleanup"
+	SCHEDULER_JOB_CONSUMED_CLEANUP = "consumed_cleanup"
+	SCHEDULER_JOB_RETENTION_PURGE  = "retention_purge"
+	SCHEDULER_JOB_IDENTITY_GC      = "identity_gc"
+	SCHEDULER_JOB_META_PRUNE       = "meta_prune"
+	SCHEDULER_JOB_INTEGRITY_SAMPLE = "integrity_sample"
+)
+
+// Default schedules of the built-in jobs
+const (
+	SCHEDULER_EXPIRED_CLEANUP_SCHEDULE_DEFAULT  = "*/15 * * * *"
+	SCHEDULER_CONSUMED_CLEANUP_SCHEDULE_DEFAULT = "*/5 * * * *"
+	SCHEDULER_RETENTION_PURGE_SCHEDULE_DEFAULT  = "0 3 * * *"
+	SCHEDULER_IDENTITY_GC_SCHEDULE_DEFAULT      = "30 3 * * *"
+	SCHEDULER_META_PRUNE_SCHEDULE_DEFAULT       = "0 4 * * *"
+	SCHEDULER_INTEGRITY_SAMPLE_SCHEDULE_DEFAULT = "0 * * * *"
+)
+
+// SCHEDULER_RETENTION_PERIOD_DEFAULT is how long soft deleted records are kept before the retention purge
+const SCHEDULER_RETENTION_PERIOD_DEFAULT = 30 * 24 * time.Hour
+
+// SCHEDULER_INTEGRITY_SAMPLE_PERCENT_DEFAULT is the percentage of records verified by each integrity sample
+const SCHEDULER_INTEGRITY_SAMPLE_PERCENT_DEFAULT = 1.0
+
+// schedulerLastRunSettingPrefix prefixes the vault setting keys holding the last run of each job
+const schedulerLastRunSettingPrefix = "scheduler_last_run_"
+
+var (
+	// ErrSchedulerJobExists is returned when registering a job under a name already in use
+	ErrSchedulerJobExists = errors.New("scheduler job already registered")
+	// ErrSchedulerJobNotFound is returned for a job name that is not registered
+	ErrSchedulerJobNotFound = errors.New("scheduler job not found")
+	// ErrSchedulerRunning is returned when starting a scheduler that is already running
+	ErrSchedulerRunning = errors.New("scheduler is already running")
+	// ErrIntegritySampleFailed is returned by the integrity sample job when sampled records fail verification
+	ErrIntegritySampleFailed = errors.New("integrity sample found records failing verification")
+)
+
+// SchedulerJob is the function run by a scheduled job.
+// It returns the number of items processed, recorded in the last run info.
+type SchedulerJob func(ctx context.Context) (count int64, err error)
+
+// SchedulerJobRun describes a run of a scheduled job, persisted in the vault settings
+type SchedulerJobRun struct {
+	// StartedAt is the UTC time the run started, as YYYY-MM-DD HH:MM:SS
+	StartedAt string `json:"started_at"`
+	// FinishedAt is the UTC time the run finished, as YYYY-MM-DD HH:MM:SS
+	FinishedAt string `json:"finished_at"`
+	// Count is the number of items processed by the run
+	Count int64 `json:"count"`
+	// Error is the error the run failed with, empty on success
+	Error string `json:"error,omitempty"`
+}
+
+// SchedulerOptions configures the built-in jobs of the scheduler.
+// Empty schedules use the defaults, a built-in job can be removed with Unregister.
+type SchedulerOptions struct {
+	// ExpiredCleanupSchedule is the schedule soft deleting the expired tokens
+	ExpiredCleanupSchedule string
+
+	// ConsumedCleanupSchedule is the schedule permanently deleting the consumed and expired limited-use tokens
+	ConsumedCleanupSchedule string
+
+	// RetentionPurgeSchedule is the schedule permanently deleting the soft deleted records older than RetentionPeriod
+	RetentionPurgeSchedule string
+
+	// RetentionPeriod is how long soft deleted records are kept, defaults to 30 days
+	RetentionPeriod time.Duration
+
+	// IdentityGCSchedule is the schedule deleting the password identities no record refers to
+	IdentityGCSchedule string
+
+	// MetaPruneSchedule is the schedule deleting the metadata of records that no longer exist
+	MetaPruneSchedule string
+
+	// TableSuffixes lists the suffixes used with WithTableSuffix. The meta table is
+	// shared by the suffixed vault tables, so the meta pruning must know all of them,
+	// or it deletes the metadata of the records of the unlisted ones.
+	TableSuffixes []string
+
+	// IntegritySampleSchedule is the schedule verifying a sample of the records
+	IntegritySampleSchedule string
+
+	// IntegritySamplePercent is the percentage of records verified by each sample, defaults to 1
+	IntegritySamplePercent float64
+
+	// IntegritySamplePassword is the password the sampled records are verified with.
+	// The integrity sample job is only registered when it is set.
+	IntegritySamplePassword string
+}
+
+// Scheduler runs the maintenance jobs of a store on cron schedules, evaluated in UTC.
+//
+// The last run of each job is persisted in the vault settings. When several
+// instances share the database, an instance skips a run another instance already
+// started for the same activation. This is best effort and not a lock, so jobs
+// must stay safe to run concurrently, as the built-in jobs are.
+type Scheduler struct {
+	store *storeImplementation
+
+	mu     sync.Mutex
+	jobs   map[string]*schedulerEntry
+	cancel context.CancelFunc
+
+	// wake interrupts the wait for the next activation when the jobs change
+	wake chan struct{}
+
+	// wg tracks the scheduling loop and the running jobs
+	wg sync.WaitGroup
+}
+
+// schedulerEntry is a registered job and its next activation
+type schedulerEntry struct {
+	schedule cronSchedule
+	job      SchedulerJob
+	next     time.Time
+	running  bool
+}
+
+// Scheduler returns a new scheduler with the built-in maintenance jobs registered:
+// expired cleanup, retention purge, identity GC, meta pruning and, when a
+// password is configured, integrity sampling. Start it to run the jobs:
+//
+//	scheduler, err := store.Scheduler()
+//	if err != nil {
+//	    return err
+//	}
+//	if err := scheduler.Start(ctx); err != nil {
+//	    return err
+//	}
+//	defer scheduler.Stop()
+//
+// Parameters:
+// - options: The schedules and settings of the built-in jobs (optional)
+//
+// Returns:
+// - scheduler: The scheduler, not started
+// - err: ErrCronExpressionInvalid if a schedule of the options is invalid
+func (store *storeImplementation) Scheduler(options ...SchedulerOptions) (*Scheduler, error) {
+	opts := SchedulerOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	scheduler := &Scheduler{
+		store: store,
+		jobs:  map[string]*schedulerEntry{},
+		wake:  make(chan struct{}, 1),
+	}
+
+	for _, builtin := range store.schedulerBuiltinJobs(opts) {
+		if err := scheduler.Register(builtin.name, builtin.schedule, builtin.job); err != nil {
+			return nil, err
+		}
+	}
+
+	return scheduler, nil
+}
+
+// Register adds a job run on the cron schedule, e.g. "*/5 * * * *" or "@daily"
+//
+// Parameters:
+// - name: The unique name of the job, used for its last run info
+// - schedule: The cron expression (minute, hour, day of month, month, day of week)
+// - job: The function to run
+//
+// Returns:
+// - err: ErrCronExpressionInvalid, ErrSchedulerJobExists, or nil
+func (scheduler *Scheduler) Register(name string, schedule string, job SchedulerJob) error {
+	if name == "" {
+		return errors.New("job name is empty")
+	}
+
+	if job == nil {
+		return errors.New("job is nil")
+	}
+
+	parsed, err := cronParse(schedule)
+	if err != nil {
+		return err
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	if _, exists := scheduler.jobs[name]; exists {
+		return ErrSchedulerJobExists
+	}
+
+	scheduler.jobs[name] = &schedulerEntry{
+		schedule: parsed,
+		job:      job,
+		next:     parsed.next(time.Now()),
+	}
+	scheduler.wakeUp()
+
+	return nil
+}
+
+// Unregister removes a job. A run in progress is not interrupted.
+//
+// Parameters:
+// - name: The name of the job
+//
+// Returns:
+// - err: ErrSchedulerJobNotFound, or nil
+func (scheduler *Scheduler) Unregister(name string) error {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	if _, exists := scheduler.jobs[name]; !exists {
+		return ErrSchedulerJobNotFound
+	}
+
+	delete(scheduler.jobs, name)
+	scheduler.wakeUp()
+
+	return nil
+}
+
+// Jobs returns the names of the registered jobs, sorted
+func (scheduler *Scheduler) Jobs() []string {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	names := make([]string, 0, len(scheduler.jobs))
+	for name := range scheduler.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Start runs the jobs in the background until Stop is called or the context is done.
+// A job still running at its next activation is not started again.
+//
+// Parameters:
+// - ctx: The context, passed to the jobs
+//
+// Returns:
+// - err: ErrSchedulerRunning if the scheduler was already started
+func (scheduler *Scheduler) Start(ctx context.Context) error {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	if scheduler.cancel != nil {
+		return ErrSchedulerRunning
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	scheduler.cancel = cancel
+
+	scheduler.wg.Add(1)
+	go scheduler.loop(ctx)
+
+	return nil
+}
+
+// Stop stops the scheduler and waits for the running jobs to return.
+// The jobs see their context canceled.
+func (scheduler *Scheduler) Stop() {
+	scheduler.mu.Lock()
+	if scheduler.cancel != nil {
+		scheduler.cancel()
+		scheduler.cancel = nil
+	}
+	scheduler.mu.Unlock()
+
+	scheduler.wg.Wait()
+}
+
+// RunNow runs a job immediately, outside of its schedule, and records its last run
+//
+// Parameters:
+// - ctx: The context
+// - name: The name of the job
+//
+// Returns:
+// - run: The run info
+// - err: ErrSchedulerJobNotFound, or the error of the job
+func (scheduler *Scheduler) RunNow(ctx context.Context, name string) (SchedulerJobRun, error) {
+	scheduler.mu.Lock()
+	entry, exists := scheduler.jobs[name]
+	scheduler.mu.Unlock()
+
+	if !exists {
+		return SchedulerJobRun{}, ErrSchedulerJobNotFound
+	}
+
+	return scheduler.run(ctx, name, entry.job)
+}
+
+// LastRun returns the last run of a job, as persisted in the vault settings.
+// It is shared by all the schedulers using the same vault.
+//
+// Parameters:
+// - ctx: The context
+// - name: The name of the job
+//
+// Returns:
+// - run: The last run info, with an empty StartedAt if the job never ran
+// - err: An error if something went wrong
+func (scheduler *Scheduler) LastRun(ctx context.Context, name string) (SchedulerJobRun, error) {
+	ctx = scheduler.store.operationAllowedContext(ctx)
+
+	value, err := scheduler.store.GetVaultSetting(ctx, schedulerLastRunSettingPrefix+name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return SchedulerJobRun{}, nil
+	}
+	if err != nil {
+		return SchedulerJobRun{}, err
+	}
+
+	run := SchedulerJobRun{}
+	if err := json.Unmarshal([]byte(value), &run); err != nil {
+		return SchedulerJobRun{}, err
+	}
+
+	return run, nil
+}
+
+// wakeUp interrupts the wait of the loop, the caller holds the lock
+func (scheduler *Scheduler) wakeUp() {
+	select {
+	case scheduler.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop starts the due jobs, then waits for the next activation
+func (scheduler *Scheduler) loop(ctx context.Context) {
+	defer scheduler.wg.Done()
+
+	for {
+		wait := scheduler.dispatch(ctx, time.Now().UTC())
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-scheduler.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatch starts the jobs due at now and returns the time until the next activation
+func (scheduler *Scheduler) dispatch(ctx context.Context, now time.Time) time.Duration {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return time.Hour
+	}
+
+	wait := time.Hour
+
+	for name, entry := range scheduler.jobs {
+		if entry.next.IsZero() {
+			continue
+		}
+
+		if !entry.next.After(now) {
+			due := entry.next
+			entry.next = entry.schedule.next(now)
+
+			if !entry.running {
+				entry.running = true
+				scheduler.wg.Add(1)
+				go scheduler.runDue(ctx, name, entry, due)
+			}
+		}
+
+		if !entry.next.IsZero() && entry.next.Sub(now) < wait {
+			wait = entry.next.Sub(now)
+		}
+	}
+
+	return wait
+}
+
+// runDue runs a job for the activation due, unless another scheduler sharing
+// the vault already started it
+func (scheduler *Scheduler) runDue(ctx context.Context, name string, entry *schedulerEntry, due time.Time) {
+	defer scheduler.wg.Done()
+	defer func() {
+		scheduler.mu.Lock()
+		entry.running = false
+		scheduler.mu.Unlock()
+	}()
+
+	lastRun, err := scheduler.LastRun(ctx, name)
+	if err == nil && lastRun.StartedAt >= carbon.CreateFromStdTime(due, carbon.UTC).ToDateTimeString(carbon.UTC) {
+		scheduler.store.logDebug(ctx, "scheduler job already run by another instance", "job", name)
+		return
+	}
+
+	_, _ = scheduler.run(ctx, name, entry.job)
+}
+
+// run runs a job, then records its last run and emits an event if it failed
+func (scheduler *Scheduler) run(ctx context.Context, name string, job SchedulerJob) (SchedulerJobRun, error) {
+	store := scheduler.store
+
+	// The jobs were authorized with the scheduler, they are not checked by the operation guard
+	ctx = store.operationAllowedContext(ctx)
+
+	run := SchedulerJobRun{StartedAt: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)}
+	count, err := job(ctx)
+	run.FinishedAt = carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	run.Count = count
+
+	if err != nil {
+		run.Error = err.Error()
+		store.emitEvent(ctx, EVENT_TYPE_SCHEDULER_JOB_FAILED, "", map[string]string{
+			"job":   name,
+			"error": run.Error,
+		})
+	}
+
+	if err != nil {
+		store.logError(ctx, "scheduler job failed", "job", name, "error", run.Error)
+	} else {
+		store.logJanitorRun(ctx, "scheduler job finished", count, "job", name)
+	}
+
+	// The run is recorded even when the scheduler is stopped during the job
+	value, marshalErr := json.Marshal(run)
+	if marshalErr == nil {
+		marshalErr = store.SetVaultSetting(context.WithoutCancel(ctx), schedulerLastRunSettingPrefix+name, string(value))
+	}
+	if err == nil {
+		err = marshalErr
+	}
+
+	return run, err
+}
@@ -0,0 +1,208 @@
+package vaultstore
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCronExpressionInvalid is returned when a job schedule is not a valid cron expression
+var ErrCronExpressionInvalid = errors.New("invalid cron expression")
+
+// cronSearchLimit bounds the search of the next activation, so an expression
+// that never matches (e.g. 30 February) does not loop forever
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// cronDescriptors are the supported shorthands for common schedules
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule is a parsed five field cron expression (minute, hour, day of
+// month, month, day of week), evaluated in UTC. Each field is a bit set of
+// the values it matches.
+type cronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+
+	// dayOfMonthAny and dayOfWeekAny record a "*" day field. When both day
+	// fields are restricted, a day matching either of them matches.
+	dayOfMonthAny bool
+	dayOfWeekAny  bool
+}
+
+// cronField describes the range of values of a cron field
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var (
+	cronFieldMinute     = cronField{name: "minute", min: 0, max: 59}
+	cronFieldHour       = cronField{name: "hour", min: 0, max: 23}
+	cronFieldDayOfMonth = cronField{name: "day of month", min: 1, max: 31}
+	cronFieldMonth      = cronField{name: "month", min: 1, max: 12}
+	cronFieldDayOfWeek  = cronField{name: "day of week", min: 0, max: 7}
+)
+
+// cronParse parses a cron expression with the five standard fields, or one of
+// the @yearly, @monthly, @weekly, @daily and @hourly shorthands.
+// Each field accepts "*", values, ranges ("1-5"), steps ("*/15", "0-30/10")
+// and comma separated lists of those. A day of week of 7 is Sunday, like 0.
+func cronParse(expression string) (cronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expression)]; ok {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return cronSchedule{}, fmt.Errorf("%w: expected 5 fields, got %d in %q", ErrCronExpressionInvalid, len(fields), expression)
+	}
+
+	schedule := cronSchedule{
+		dayOfMonthAny: fields[2] == "*" || fields[2] == "?",
+		dayOfWeekAny:  fields[4] == "*" || fields[4] == "?",
+	}
+
+	var err error
+	if schedule.minute, err = cronParseField(fields[0], cronFieldMinute); err != nil {
+		return cronSchedule{}, err
+	}
+	if schedule.hour, err = cronParseField(fields[1], cronFieldHour); err != nil {
+		return cronSchedule{}, err
+	}
+	if schedule.dayOfMonth, err = cronParseField(fields[2], cronFieldDayOfMonth); err != nil {
+		return cronSchedule{}, err
+	}
+	if schedule.month, err = cronParseField(fields[3], cronFieldMonth); err != nil {
+		return cronSchedule{}, err
+	}
+	if schedule.dayOfWeek, err = cronParseField(fields[4], cronFieldDayOfWeek); err != nil {
+		return cronSchedule{}, err
+	}
+
+	// Sunday can be written as 7
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+
+	return schedule, nil
+}
+
+// cronParseField parses a comma separated list of values, ranges and steps into a bit set
+func cronParseField(value string, field cronField) (uint64, error) {
+	bits := uint64(0)
+
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("%w: invalid step %q in %s field", ErrCronExpressionInvalid, stepPart, field.name)
+			}
+			step = parsed
+		}
+
+		start, end := field.min, field.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = cronParseValue(from, field); err != nil {
+				return 0, err
+			}
+			if end, err = cronParseValue(to, field); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("%w: range %q out of order in %s field", ErrCronExpressionInvalid, rangePart, field.name)
+			}
+		default:
+			var err error
+			if start, err = cronParseValue(rangePart, field); err != nil {
+				return 0, err
+			}
+			// "5/10" runs from 5 to the end of the range, "5" only at 5
+			if !hasStep {
+				end = start
+			}
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+// cronParseValue parses a single value of a field, checking its range
+func cronParseValue(value string, field cronField) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < field.min || parsed > field.max {
+		return 0, fmt.Errorf("%w: invalid value %q in %s field", ErrCronExpressionInvalid, value, field.name)
+	}
+	return parsed, nil
+}
+
+// next returns the first activation strictly after t, in UTC, or the zero time
+// if the schedule never matches
+func (schedule cronSchedule) next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if schedule.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !schedule.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if schedule.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if schedule.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches checks the day of month and day of week fields, a day matching
+// either one when both are restricted, as in the traditional cron
+func (schedule cronSchedule) dayMatches(t time.Time) bool {
+	dayOfMonth := schedule.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := schedule.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if schedule.dayOfMonthAny || schedule.dayOfWeekAny {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
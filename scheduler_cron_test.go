@@ -0,0 +1,76 @@
+package vaultstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_CronParse_Next(t *testing.T) {
+	from := time.Date(2026, 10, 16, 10, 7, 30, 0, time.UTC) // a Friday
+
+	tests := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 10, 16, 10, 15, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2026, 10, 16, 10, 8, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * 1-5", time.Date(2026, 10, 16, 13, 30, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		// Both day fields restricted, either one matches: the 20th or a Monday
+		{"0 0 20 * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		schedule, err := cronParse(test.expression)
+		if err != nil {
+			t.Fatalf("cronParse(%q): Expected [err] to be nil received [%v]", test.expression, err)
+		}
+
+		next := schedule.next(from)
+		if !next.Equal(test.expected) {
+			t.Fatalf("cronParse(%q): Expected next [%v] received [%v]", test.expression, test.expected, next)
+		}
+	}
+
+	// An expression that never matches has no next activation
+	schedule, err := cronParse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("cronParse: Expected [err] to be nil received [%v]", err)
+	}
+
+	if !schedule.next(from).IsZero() {
+		t.Fatalf("Expected no next activation received [%v]", schedule.next(from))
+	}
+}
+
+func Test_CronParse_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 5m",
+	}
+
+	for _, expression := range invalid {
+		_, err := cronParse(expression)
+		if !errors.Is(err, ErrCronExpressionInvalid) {
+			t.Fatalf("cronParse(%q): Expected [ErrCronExpressionInvalid] received [%v]", expression, err)
+		}
+	}
+}
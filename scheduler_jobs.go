@@ -0,0 +1,208 @@
+package vaultstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// recordMetaObjectTypes are the meta object types whose object ID refers to a record
+var recordMetaObjectTypes = []string{
+	OBJECT_TYPE_RECORD,
+	OBJECT_TYPE_RECORD_CHUNK,
+	OBJECT_TYPE_RECORD_TAG,
+	OBJECT_TYPE_RECORD_VERSION,
+}
+
+// schedulerBuiltinJob is a built-in job with its configured schedule
+type schedulerBuiltinJob struct {
+	name     string
+	schedule string
+	job      SchedulerJob
+}
+
+// schedulerBuiltinJobs returns the built-in jobs configured by the options
+func (store *storeImplementation) schedulerBuiltinJobs(opts SchedulerOptions) []schedulerBuiltinJob {
+	retentionPeriod := lo.Ternary(opts.RetentionPeriod > 0, opts.RetentionPeriod, SCHEDULER_RETENTION_PERIOD_DEFAULT)
+
+	jobs := []schedulerBuiltinJob{
+		{
+			name:     SCHEDULER_JOB_EXPIRED_CLEANUP,
+			schedule: lo.CoalesceOrEmpty(opts.ExpiredCleanupSchedule, SCHEDULER_EXPIRED_CLEANUP_SCHEDULE_DEFAULT),
+			job:      store.TokensExpiredSoftDelete,
+		},
+		{
+			name:     SCHEDULER_JOB_CONSUMED_CLEANUP,
+			schedule: lo.CoalesceOrEmpty(opts.ConsumedCleanupSchedule, SCHEDULER_CONSUMED_CLEANUP_SCHEDULE_DEFAULT),
+			job:      store.TokensConsumedDelete,
+		},
+		{
+			name:     SCHEDULER_JOB_RETENTION_PURGE,
+			schedule: lo.CoalesceOrEmpty(opts.RetentionPurgeSchedule, SCHEDULER_RETENTION_PURGE_SCHEDULE_DEFAULT),
+			job: func(ctx context.Context) (int64, error) {
+				return store.RecordsSoftDeletedPurge(ctx, retentionPeriod)
+			},
+		},
+		{
+			name:     SCHEDULER_JOB_IDENTITY_GC,
+			schedule: lo.CoalesceOrEmpty(opts.IdentityGCSchedule, SCHEDULER_IDENTITY_GC_SCHEDULE_DEFAULT),
+			job:      store.passwordIdentitiesGarbageCollect,
+		},
+		{
+			name:     SCHEDULER_JOB_META_PRUNE,
+			schedule: lo.CoalesceOrEmpty(opts.MetaPruneSchedule, SCHEDULER_META_PRUNE_SCHEDULE_DEFAULT),
+			job: func(ctx context.Context) (int64, error) {
+				return store.recordMetaOrphansDelete(ctx, opts.TableSuffixes)
+			},
+		},
+	}
+
+	if opts.IntegritySamplePassword != "" {
+		samplePercent := lo.Ternary(opts.IntegritySamplePercent > 0, opts.IntegritySamplePercent, SCHEDULER_INTEGRITY_SAMPLE_PERCENT_DEFAULT)
+
+		jobs = append(jobs, schedulerBuiltinJob{
+			name:     SCHEDULER_JOB_INTEGRITY_SAMPLE,
+			schedule: lo.CoalesceOrEmpty(opts.IntegritySampleSchedule, SCHEDULER_INTEGRITY_SAMPLE_SCHEDULE_DEFAULT),
+			job: func(ctx context.Context) (int64, error) {
+				return store.integritySample(withNamespacesAll(ctx), samplePercent, opts.IntegritySamplePassword)
+			},
+		})
+	}
+
+	for i := range jobs {
+		jobs[i].job = store.featureGatedJob(FEATURE_JANITOR, jobs[i].job)
+	}
+
+	return jobs
+}
+
+// passwordIdentitiesGarbageCollect deletes the password identities no record refers to.
+// The identities are few, one per password, so they are compared in memory
+// (MySQL cannot delete from a table selected in a subquery).
+func (store *storeImplementation) passwordIdentitiesGarbageCollect(ctx context.Context) (int64, error) {
+	var identityIDs []string
+	err := store.metaDB(ctx).
+		Distinct(COLUMN_OBJECT_ID).
+		Where(COLUMN_OBJECT_TYPE+" = ?", OBJECT_TYPE_PASSWORD_IDENTITY).
+		Pluck(COLUMN_OBJECT_ID, &identityIDs).Error
+	if err != nil || len(identityIDs) == 0 {
+		return 0, err
+	}
+
+	var referencedIDs []string
+	err = store.metaDB(ctx).
+		Distinct(COLUMN_META_VALUE).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_RECORD, META_KEY_PASSWORD_ID).
+		Pluck(COLUMN_META_VALUE, &referencedIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	count := int64(0)
+	for _, batch := range lo.Chunk(lo.Without(identityIDs, referencedIDs...), maxRecordsInMemory) {
+		result := store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" IN ?", OBJECT_TYPE_PASSWORD_IDENTITY, batch).
+			Delete(&gormVaultMeta{})
+		if result.Error != nil {
+			return count, result.Error
+		}
+		count += result.RowsAffected
+	}
+
+	return count, nil
+}
+
+// recordMetaOrphansDelete deletes the metadata, appended chunks and versions of
+// records that no longer exist, e.g. removed directly in the database.
+// Soft deleted records keep their metadata. The meta table is shared by the
+// suffixed vault tables, a record is looked up in all of them.
+func (store *storeImplementation) recordMetaOrphansDelete(ctx context.Context, tableSuffixes []string) (int64, error) {
+	tableNames := []string{store.vaultTableName}
+	for _, suffix := range tableSuffixes {
+		if !IsTableSuffixValid(suffix) {
+			return 0, ErrTableSuffixInvalid
+		}
+		tableNames = append(tableNames, suffixedTableName(store.vaultTableName, suffix))
+	}
+
+	count := int64(0)
+	lastObjectID := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		var objectIDs []string
+		err := store.metaDB(ctx).
+			Distinct(COLUMN_OBJECT_ID).
+			Where(COLUMN_OBJECT_TYPE+" IN ?", recordMetaObjectTypes).
+			Where(COLUMN_OBJECT_ID+" > ?", lastObjectID).
+			Order(COLUMN_OBJECT_ID+" ASC").
+			Limit(maxRecordsInMemory).
+			Pluck(COLUMN_OBJECT_ID, &objectIDs).Error
+		if err != nil {
+			return count, err
+		}
+
+		if len(objectIDs) == 0 {
+			return count, nil
+		}
+		lastObjectID = objectIDs[len(objectIDs)-1]
+
+		recordIDs := lo.Map(objectIDs, func(objectID string, _ int) string {
+			return strings.TrimPrefix(objectID, RECORD_META_ID_PREFIX)
+		})
+
+		orphanIDs := recordIDs
+		for _, tableName := range tableNames {
+			var existingIDs []string
+			err = store.gormDBFromContext(ctx).
+				Table(tableName).
+				Where(COLUMN_ID+" IN ?", orphanIDs).
+				Pluck(COLUMN_ID, &existingIDs).Error
+			if err != nil {
+				return count, err
+			}
+
+			orphanIDs = lo.Without(orphanIDs, existingIDs...)
+			if len(orphanIDs) == 0 {
+				break
+			}
+		}
+
+		if len(orphanIDs) == 0 {
+			continue
+		}
+
+		orphanObjectIDs := lo.Map(orphanIDs, func(recordID string, _ int) string {
+			return recordMetaObjectID(recordID)
+		})
+
+		result := store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" IN ?", recordMetaObjectTypes).
+			Where(COLUMN_OBJECT_ID+" IN ?", orphanObjectIDs).
+			Delete(&gormVaultMeta{})
+		if result.Error != nil {
+			return count, result.Error
+		}
+		count += result.RowsAffected
+	}
+}
+
+// integritySample verifies a sample of the records with VerifyRestore,
+// returning the number of records sampled
+func (store *storeImplementation) integritySample(ctx context.Context, samplePercent float64, password string) (int64, error) {
+	report, err := store.VerifyRestore(ctx, samplePercent, password)
+	if err != nil {
+		return int64(report.Sampled), err
+	}
+
+	if !report.Passed() {
+		return int64(report.Sampled), fmt.Errorf("%w: %d of %d sampled records", ErrIntegritySampleFailed, len(report.Failures), report.Sampled)
+	}
+
+	return int64(report.Sampled), nil
+}
@@ -0,0 +1,256 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Store_Scheduler(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+
+	scheduler, err := store.Scheduler()
+	if err != nil {
+		t.Fatalf("Scheduler: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	expected := []string{SCHEDULER_JOB_CONSUMED_CLEANUP, SCHEDULER_JOB_EXPIRED_CLEANUP, SCHEDULER_JOB_IDENTITY_GC, SCHEDULER_JOB_META_PRUNE, SCHEDULER_JOB_RETENTION_PURGE}
+	if !reflect.DeepEqual(scheduler.Jobs(), expected) {
+		t.Fatalf("Expected jobs [%v] received [%v]", expected, scheduler.Jobs())
+	}
+
+	// The integrity sample needs a password
+	scheduler, err = store.Scheduler(SchedulerOptions{IntegritySamplePassword: "test_password_that_is_long_enough_for_security_32chars"})
+	if err != nil {
+		t.Fatalf("Scheduler: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(scheduler.Jobs()) != 5 {
+		t.Fatalf("Expected 5 jobs received [%v]", scheduler.Jobs())
+	}
+
+	_, err = store.Scheduler(SchedulerOptions{MetaPruneSchedule: "not a schedule"})
+	if !errors.Is(err, ErrCronExpressionInvalid) {
+		t.Fatalf("Expected [ErrCronExpressionInvalid] received [%v]", err)
+	}
+
+	err = scheduler.Register(SCHEDULER_JOB_META_PRUNE, "@daily", func(ctx context.Context) (int64, error) { return 0, nil })
+	if !errors.Is(err, ErrSchedulerJobExists) {
+		t.Fatalf("Expected [ErrSchedulerJobExists] received [%v]", err)
+	}
+
+	if err := scheduler.Unregister(SCHEDULER_JOB_META_PRUNE); err != nil {
+		t.Fatalf("Unregister: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := scheduler.RunNow(ctx, SCHEDULER_JOB_META_PRUNE); !errors.Is(err, ErrSchedulerJobNotFound) {
+		t.Fatalf("Expected [ErrSchedulerJobNotFound] received [%v]", err)
+	}
+
+	// A custom job run now records its last run
+	err = scheduler.Register("custom", "@hourly", func(ctx context.Context) (int64, error) {
+		return 7, nil
+	})
+	if err != nil {
+		t.Fatalf("Register: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	lastRun, err := scheduler.LastRun(ctx, "custom")
+	if err != nil {
+		t.Fatalf("LastRun: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if lastRun.StartedAt != "" {
+		t.Fatalf("Expected no last run received [%+v]", lastRun)
+	}
+
+	run, err := scheduler.RunNow(ctx, "custom")
+	if err != nil {
+		t.Fatalf("RunNow: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	lastRun, err = scheduler.LastRun(ctx, "custom")
+	if err != nil {
+		t.Fatalf("LastRun: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if lastRun != run || lastRun.Count != 7 || lastRun.StartedAt == "" {
+		t.Fatalf("Expected last run [%+v] received [%+v]", run, lastRun)
+	}
+
+	// A failing job records its error
+	jobErr := errors.New("job failed")
+	err = scheduler.Register("failing", "@hourly", func(ctx context.Context) (int64, error) {
+		return 0, jobErr
+	})
+	if err != nil {
+		t.Fatalf("Register: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := scheduler.RunNow(ctx, "failing"); !errors.Is(err, jobErr) {
+		t.Fatalf("Expected [%v] received [%v]", jobErr, err)
+	}
+
+	lastRun, err = scheduler.LastRun(ctx, "failing")
+	if err != nil {
+		t.Fatalf("LastRun: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if lastRun.Error != jobErr.Error() {
+		t.Fatalf("Expected error [%v] received [%v]", jobErr, lastRun.Error)
+	}
+}
+
+func Test_Store_Scheduler_StartStop(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	scheduler, err := store.Scheduler()
+	if err != nil {
+		t.Fatalf("Scheduler: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	runs := atomic.Int32{}
+	err = scheduler.Register("counter", "* * * * *", func(ctx context.Context) (int64, error) {
+		runs.Add(1)
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatalf("Register: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := scheduler.Start(ctx); err != nil {
+		t.Fatalf("Start: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := scheduler.Start(ctx); !errors.Is(err, ErrSchedulerRunning) {
+		t.Fatalf("Expected [ErrSchedulerRunning] received [%v]", err)
+	}
+
+	// Dispatch as if a minute had passed, instead of waiting for it
+	scheduler.dispatch(ctx, time.Now().UTC().Add(time.Minute))
+	scheduler.Stop()
+
+	if runs.Load() != 1 {
+		t.Fatalf("Expected 1 run received [%v]", runs.Load())
+	}
+
+	// An activation started before the last run, as by another instance, is skipped
+	scheduler.jobs["counter"].next = time.Now().UTC().Truncate(time.Minute).Add(-time.Minute)
+	scheduler.dispatch(ctx, time.Now().UTC())
+	scheduler.wg.Wait()
+
+	if runs.Load() != 1 {
+		t.Fatalf("Expected the run to be skipped received [%v] runs", runs.Load())
+	}
+}
+
+func Test_Store_Scheduler_BuiltinJobs(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	implementation := store.(*storeImplementation)
+
+	scheduler, err := store.Scheduler(SchedulerOptions{RetentionPeriod: time.Hour})
+	if err != nil {
+		t.Fatalf("Scheduler: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Retention purge: only records soft deleted before the retention period are removed
+	oldToken, err := store.TokenCreate(ctx, "old", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	recentToken, err := store.TokenCreate(ctx, "recent", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenSoftDelete(ctx, recentToken); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = implementation.vaultDB(ctx).
+		Where(COLUMN_VAULT_TOKEN+" = ?", oldToken).
+		Update(COLUMN_SOFT_DELETED_AT, time.Now().UTC().Add(-2*time.Hour).Format(time.DateTime)).Error
+	if err != nil {
+		t.Fatalf("Update: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	run, err := scheduler.RunNow(ctx, SCHEDULER_JOB_RETENTION_PURGE)
+	if err != nil {
+		t.Fatalf("RunNow: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if run.Count != 1 {
+		t.Fatalf("Expected 1 purged record received [%v]", run.Count)
+	}
+
+	// Meta pruning: the metadata of a record removed directly is deleted
+	token, err := store.TokenCreate(ctx, "value", password, 20, TokenCreateOptions{ContentType: CONTENT_TYPE_TEXT})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = implementation.vaultDB(ctx).
+		Where(COLUMN_VAULT_TOKEN+" = ?", token).
+		Delete(&gormVaultRecord{}).Error
+	if err != nil {
+		t.Fatalf("Delete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	kept, err := store.TokenCreate(ctx, "kept", password, 20, TokenCreateOptions{ContentType: CONTENT_TYPE_TEXT})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	run, err = scheduler.RunNow(ctx, SCHEDULER_JOB_META_PRUNE)
+	if err != nil {
+		t.Fatalf("RunNow: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if run.Count != 1 {
+		t.Fatalf("Expected 1 pruned meta received [%v]", run.Count)
+	}
+
+	_, info, err := store.TokenReadWithInfo(ctx, kept, password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if info.ContentType != CONTENT_TYPE_TEXT {
+		t.Fatalf("Expected the meta of existing records to be kept received [%v]", info.ContentType)
+	}
+
+	// Identity GC: identities no record refers to are deleted
+	if err := implementation.metaCreate(ctx, OBJECT_TYPE_PASSWORD_IDENTITY, PASSWORD_ID_PREFIX+"unused", META_KEY_HASH, "hash"); err != nil {
+		t.Fatalf("metaCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	run, err = scheduler.RunNow(ctx, SCHEDULER_JOB_IDENTITY_GC)
+	if err != nil {
+		t.Fatalf("RunNow: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if run.Count != 1 {
+		t.Fatalf("Expected 1 deleted identity received [%v]", run.Count)
+	}
+}
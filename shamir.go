@@ -0,0 +1,117 @@
+package vaultstore
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// Shamir's secret sharing over GF(256), used for the k-of-n break-glass admin shares.
+// Each share is the x coordinate (1..255) followed by one y byte per secret byte.
+
+// gf256Exp and gf256Log are the exponent and logarithm tables of GF(256) for generator 3
+var gf256Exp, gf256Log = gf256Tables()
+
+func gf256Tables() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+
+		// Multiply by the generator 3 (x * 2 + x), reducing by the AES polynomial
+		doubled := x << 1
+		if x&0x80 != 0 {
+			doubled ^= 0x1b
+		}
+		x ^= doubled
+	}
+	return exp, log
+}
+
+func gf256Mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256Exp[int(gf256Log[a])+int(gf256Log[b])]
+}
+
+func gf256Div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gf256Exp[(int(gf256Log[a])+255-int(gf256Log[b]))%255]
+}
+
+// shamirSplit splits the secret into n shares, any threshold of which recover it
+func shamirSplit(secret []byte, n, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("secret is empty")
+	}
+	if threshold < 2 || threshold > n || n > 255 {
+		return nil, errors.New("invalid share count or threshold")
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, threshold)
+	for b, secretByte := range secret {
+		// Random polynomial of degree threshold-1 with the secret byte as constant term
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		coefficients[0] = secretByte
+
+		for _, share := range shares {
+			x := share[0]
+			var y byte
+			for c := threshold - 1; c >= 0; c-- {
+				y = gf256Mul(y, x) ^ coefficients[c]
+			}
+			share[b+1] = y
+		}
+	}
+
+	return shares, nil
+}
+
+// shamirCombine recovers the secret from the shares, by Lagrange interpolation at x = 0.
+// With fewer shares than the threshold the result is a random value, not an error.
+func shamirCombine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least two shares are required")
+	}
+
+	length := len(shares[0])
+	seen := map[byte]bool{}
+	for _, share := range shares {
+		if len(share) != length || length < 2 || share[0] == 0 {
+			return nil, errors.New("malformed share")
+		}
+		if seen[share[0]] {
+			return nil, errors.New("duplicate share")
+		}
+		seen[share[0]] = true
+	}
+
+	secret := make([]byte, length-1)
+	for i, share := range shares {
+		// Lagrange basis polynomial of the share evaluated at 0
+		basis := byte(1)
+		for j, other := range shares {
+			if i == j {
+				continue
+			}
+			basis = gf256Mul(basis, gf256Div(other[0], other[0]^share[0]))
+		}
+
+		for b := range secret {
+			secret[b] ^= gf256Mul(share[b+1], basis)
+		}
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,50 @@
+package vaultstore
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_Shamir_SplitCombine(t *testing.T) {
+	secret := []byte("break glass secret")
+
+	shares, err := shamirSplit(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("shamirSplit: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(shares) != 5 {
+		t.Fatalf("Expected 5 shares received [%v]", len(shares))
+	}
+
+	// Any 3 of the 5 shares recover the secret
+	for _, subset := range [][]int{{0, 1, 2}, {0, 2, 4}, {1, 3, 4}, {4, 3, 2, 1}} {
+		selected := [][]byte{}
+		for _, i := range subset {
+			selected = append(selected, shares[i])
+		}
+
+		recovered, err := shamirCombine(selected)
+		if err != nil {
+			t.Fatalf("shamirCombine: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if !bytes.Equal(recovered, secret) {
+			t.Fatalf("Expected the secret to be recovered from shares %v received [%x]", subset, recovered)
+		}
+	}
+
+	// Two shares are not enough
+	recovered, err := shamirCombine([][]byte{shares[0], shares[1]})
+	if err != nil {
+		t.Fatalf("shamirCombine: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if bytes.Equal(recovered, secret) {
+		t.Fatal("Expected two shares not to recover the secret")
+	}
+
+	if _, err := shamirCombine([][]byte{shares[0], shares[0]}); err == nil {
+		t.Fatal("Expected an error for duplicate shares")
+	}
+}
@@ -0,0 +1,115 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// errDecryptionFailed is returned when one or more records in a batch cannot be decrypted
+var errDecryptionFailed = errors.New("decryption failed for one or more tokens")
+
+// defaultMaxDecryptWorkers caps the default worker count, as every Argon2id
+// derivation allocates CryptoConfig.Memory (64MB by default)
+const defaultMaxDecryptWorkers = 4
+
+// getDecryptWorkers returns the configured number of parallel decrypt workers
+// Returns min(NumCPU, 4) if not configured (default)
+func (store *storeImplementation) getDecryptWorkers() int {
+	if store.decryptWorkers > 0 {
+		return store.decryptWorkers
+	}
+	return min(runtime.NumCPU(), defaultMaxDecryptWorkers)
+}
+
+// decodedRecord holds the outcome of decrypting a single record
+type decodedRecord struct {
+	token string
+	value string
+	err   error
+}
+
+// decodeRecords decrypts the records with a bounded worker pool and hands each
+// value to fn. fn is only ever called from the calling goroutine.
+func (store *storeImplementation) decodeRecords(ctx context.Context, records []RecordInterface, password string, fn func(token string, value string) error) error {
+	workers := min(store.getDecryptWorkers(), len(records))
+
+	// Sequential path: nothing to gain from goroutines
+	if workers <= 1 {
+		for _, record := range records {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			decoded, err := decode(ctx, record.GetValue(), password, store.cryptoConfig)
+			if err != nil {
+				return errDecryptionFailed
+			}
+
+			if err := fn(record.GetToken(), decoded); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan RecordInterface)
+	results := make(chan decodedRecord, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range jobs {
+				decoded, err := decode(ctx, record.GetValue(), password, store.cryptoConfig)
+				select {
+				case results <- decodedRecord{token: record.GetToken(), value: decoded, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, record := range records {
+			select {
+			case jobs <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	received := 0
+	for result := range results {
+		if result.err != nil {
+			return errDecryptionFailed
+		}
+
+		if err := fn(result.token, result.value); err != nil {
+			return err
+		}
+		received++
+	}
+
+	// Workers stopped early because the parent context was cancelled
+	if received < len(records) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_Store_TokensRead_ParallelDecrypt(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_parallel_decrypt",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		DecryptWorkers:     3,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if store.getDecryptWorkers() != 3 {
+		t.Fatalf("Expected [3] decrypt workers received [%v]", store.getDecryptWorkers())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	expected := map[string]string{}
+	tokens := []string{}
+	for _, value := range []string{"value1", "value2", "value3", "value4", "value5"} {
+		token, err := store.TokenCreate(ctx, value, password, 20)
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+		expected[token] = value
+		tokens = append(tokens, token)
+	}
+
+	values, err := store.TokensRead(ctx, tokens, password)
+	if err != nil {
+		t.Fatalf("TokensRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(values) != len(expected) {
+		t.Fatalf("Expected [%d] values received [%d]", len(expected), len(values))
+	}
+
+	for token, value := range expected {
+		if values[token] != value {
+			t.Fatalf("Expected [%s] for token [%s] received [%s]", value, token, values[token])
+		}
+	}
+
+	_, err = store.TokensRead(ctx, tokens, "wrong_password_that_is_long_enough_for_security")
+	if err == nil {
+		t.Fatal("Expected error when decrypting with the wrong password")
+	}
+}
+
+func Test_Store_TokensReadFunc(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token1, err := store.TokenCreate(ctx, "value1", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	token2, err := store.TokenCreate(ctx, "value2", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	streamed := map[string]string{}
+	err = store.TokensReadFunc(ctx, []string{token1, token2}, password, func(token string, value string) error {
+		streamed[token] = value
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("TokensReadFunc: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if streamed[token1] != "value1" || streamed[token2] != "value2" {
+		t.Fatalf("Unexpected streamed values [%v]", streamed)
+	}
+
+	// Callback errors stop the read and are returned as is
+	errStop := errors.New("stop")
+	calls := 0
+	err = store.TokensReadFunc(ctx, []string{token1, token2}, password, func(token string, value string) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("Expected callback error received [%v]", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected callback to be called once received [%d]", calls)
+	}
+
+	if err := store.TokensReadFunc(ctx, []string{token1}, password, nil); err == nil {
+		t.Fatal("Expected error for nil callback")
+	}
+}
@@ -2,12 +2,17 @@ package vaultstore
 
 import (
 	"context"
+	"crypto/cipher"
 
 	"database/sql"
+	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"github.com/dracory/database"
 	"github.com/dromara/carbon/v2"
 	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -19,7 +24,7 @@ type storeImplementation struct {
 	gormDB                   *gorm.DB
 	dbDriverName             string
 	automigrateEnabled       bool
-	debugEnabled             bool
+	debugEnabled             atomic.Bool
 	cryptoConfig             *CryptoConfig
 	parallelThreshold        int  // Configurable threshold for parallel processing (0 = use default)
 	passwordAllowEmpty       bool // Allow empty passwords (default: false)
@@ -28,45 +33,179 @@ type storeImplementation struct {
 	passwordRequireUppercase bool // Require at least one uppercase letter (default: false)
 	passwordRequireNumbers   bool // Require at least one number (default: false)
 	passwordRequireSymbols   bool // Require at least one symbol (default: false)
+	decryptWorkers           int  // Parallel decrypt workers for batch reads (0 = use default)
+
+	// tokenBloomFilter is the negative cache for token existence (nil = disabled)
+	tokenBloomFilter *tokenBloomFilter
+
+	// eventHooks receive store events (quota alarms, ...)
+	eventHooks []EventHook
+
+	// operationGuard authorizes the operations (nil = all allowed)
+	operationGuard OperationGuard
+
+	// tracer starts the spans of the store methods (no-op without a TracerProvider)
+	tracer trace.Tracer
+
+	// Soft quota alarms
+	quotaThresholds    QuotaThresholds
+	quotaCheckInterval atomic.Int64 // nanoseconds, 0 = disabled
+	quotaLastCheckedAt atomic.Int64 // unix nanoseconds
+
+	// valueChunkThreshold is the ciphertext length above which values are chunked (0 = disabled)
+	valueChunkThreshold int
+
+	// databaseTimestamps uses the database clock for created_at/updated_at
+	databaseTimestamps bool
+
+	// fastRecordsEnabled returns struct-backed records from reads
+	fastRecordsEnabled bool
+
+	// recordIDFunc generates record IDs (nil = uid.HumanUid)
+	recordIDFunc func() string
+
+	// policy is the policy loaded with LoadPolicy (nil = use the options)
+	policy atomic.Pointer[Policy]
+
+	// versionAutoUpgrade stamps older supported vaults with the current version
+	versionAutoUpgrade bool
+
+	// migrationBackupEnabled snapshots the tables before migrating an older vault version
+	migrationBackupEnabled bool
+
+	// Validation of decrypted values (nil = disabled)
+	valueValidateFunc      func(token string, value string) error
+	valueQuarantineEnabled bool
+
+	// tokenVersioningEnabled keeps the previous values of updated tokens
+	tokenVersioningEnabled bool
+
+	// archivePassword encrypts the archives of ArchiveExpired
+	archivePassword string
+
+	// metaAEAD encrypts the sensitive meta values (nil = disabled)
+	metaAEAD cipher.AEAD
+
+	// recordFactory creates the records (nil = NewRecord or NewFastRecord)
+	recordFactory RecordFactory
+	// recordExtraColumns are the custom columns read and written with the records
+	recordExtraColumns []string
+	// extraColumns are the custom columns created by AutoMigrate
+	extraColumns []ColumnSpec
+	// namespacesEnabled scopes the records to the namespace of the context
+	namespacesEnabled bool
+
+	// readThroughCache holds the decrypted values of ReadThrough
+	readThroughCache *readThroughCache
+
+	// deleteBatchSize and deleteBatchPause pace the bulk deletes
+	deleteBatchSize  int
+	deleteBatchPause time.Duration
+
+	// expiresAtPastAllowed accepts token expirations in the past
+	expiresAtPastAllowed bool
+
+	// redactor builds the redacted token identifiers
+	redactor Redactor
+
+	// logger receives the logs of the store (nil = discarded)
+	logger atomic.Pointer[slog.Logger]
+
+	// logLevel is the minimum level of the logs, lowered to debug when debug is enabled
+	logLevel slog.LevelVar
 }
 
 var _ StoreInterface = (*storeImplementation)(nil) // verify it extends the interface
 
 // AutoMigrate auto migrate
 func (store *storeImplementation) AutoMigrate() error {
+	// Never migrate a vault written by a newer format version
+	version, err := store.vaultVersionGate(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if store.migrationBackupEnabled {
+		err = store.migrationBackup(context.Background(), version)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = store.autoMigrateVaultTable(store.vaultTableName)
+	if err != nil {
+		return err
+	}
+
+	// Always migrate the meta table
+	err = store.gormDB.Table(store.vaultMetaTableName).AutoMigrate(&gormVaultMeta{})
+	if err != nil {
+		return err
+	}
+
+	err = store.ensureMetaUniqueIndex()
+	if err != nil {
+		return err
+	}
+
+	store.logInfo(context.Background(), "tables migrated", "vault_table", store.vaultTableName, "meta_table", store.vaultMetaTableName)
+
+	return nil
+}
+
+// autoMigrateVaultTable migrates the schema of the given vault table
+func (store *storeImplementation) autoMigrateVaultTable(tableName string) error {
 	// Clean up existing records with empty tokens before creating unique index
-	err := store.cleanupEmptyTokenRecords()
+	err := store.cleanupEmptyTokenRecords(tableName)
 	if err != nil {
 		return err
 	}
 
 	// Clean up existing NULL datetime fields before adding NOT NULL constraints
-	err = store.cleanupNullDatetimeFields()
+	err = store.cleanupNullDatetimeFields(tableName)
 	if err != nil {
 		return err
 	}
 
 	// Use GORM's AutoMigrate with dynamic table name for vault records
-	err = store.gormDB.Table(store.vaultTableName).AutoMigrate(&gormVaultRecord{})
+	err = store.gormDB.Table(tableName).AutoMigrate(store.vaultRecordMigrationModel())
 	if err != nil {
 		return err
 	}
 
-	// Always migrate the meta table
-	return store.gormDB.Table(store.vaultMetaTableName).AutoMigrate(&gormVaultMeta{})
+	err = store.extraColumnsMigrate(tableName)
+	if err != nil {
+		return err
+	}
+
+	err = store.namespaceColumnMigrate(tableName)
+	if err != nil {
+		return err
+	}
+
+	err = store.tokenPathIndexMigrate(tableName)
+	if err != nil {
+		return err
+	}
+
+	if !store.isValueChunkingEnabled() {
+		return nil
+	}
+
+	return store.gormDB.Table(tableName + VALUE_CHUNK_TABLE_SUFFIX).AutoMigrate(store.valueChunkMigrationModel())
 }
 
 // cleanupEmptyTokenRecords removes or updates records with empty tokens to prevent unique index violations
-func (store *storeImplementation) cleanupEmptyTokenRecords() error {
+func (store *storeImplementation) cleanupEmptyTokenRecords(tableName string) error {
 	// Check if the table exists first
-	hasTable := store.gormDB.Migrator().HasTable(store.vaultTableName)
+	hasTable := store.gormDB.Migrator().HasTable(tableName)
 	if !hasTable {
 		return nil
 	}
 
 	// Find all records with empty tokens
 	var records []gormVaultRecord
-	err := store.gormDB.Table(store.vaultTableName).
+	err := store.gormDB.Table(tableName).
 		Where(COLUMN_VAULT_TOKEN + " = ''").
 		Find(&records).Error
 
@@ -81,15 +220,15 @@ func (store *storeImplementation) cleanupEmptyTokenRecords() error {
 
 	// Delete records with empty tokens since they violate the unique constraint
 	// and are likely test data or improperly created records
-	return store.gormDB.Table(store.vaultTableName).
+	return store.gormDB.Table(tableName).
 		Where(COLUMN_VAULT_TOKEN + " = ''").
 		Delete(&gormVaultRecord{}).Error
 }
 
 // cleanupNullDatetimeFields updates NULL datetime fields to default values to prevent NOT NULL constraint violations
-func (store *storeImplementation) cleanupNullDatetimeFields() error {
+func (store *storeImplementation) cleanupNullDatetimeFields(tableName string) error {
 	// Check if the table exists first
-	hasTable := store.gormDB.Migrator().HasTable(store.vaultTableName)
+	hasTable := store.gormDB.Migrator().HasTable(tableName)
 	if !hasTable {
 		return nil
 	}
@@ -97,7 +236,7 @@ func (store *storeImplementation) cleanupNullDatetimeFields() error {
 	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
 
 	// Update NULL datetime fields to default values
-	return store.gormDB.Table(store.vaultTableName).
+	return store.gormDB.Table(tableName).
 		Where(COLUMN_CREATED_AT + " IS NULL OR " +
 			COLUMN_UPDATED_AT + " IS NULL OR " +
 			COLUMN_EXPIRES_AT + " IS NULL OR " +
@@ -110,9 +249,9 @@ func (store *storeImplementation) cleanupNullDatetimeFields() error {
 		}).Error
 }
 
-// EnableDebug - enables the debug option
+// EnableDebug - enables the debug option. Safe to call while the store is in use.
 func (store *storeImplementation) EnableDebug(debug bool) {
-	store.debugEnabled = debug
+	store.debugEnabled.Store(debug)
 }
 
 func (store *storeImplementation) GetDbDriverName() string {
@@ -127,12 +266,62 @@ func (store *storeImplementation) GetMetaTableName() string {
 	return store.vaultMetaTableName
 }
 
-func (store *storeImplementation) toQuerableContext(context context.Context) database.QueryableContext {
-	if database.IsQueryableContext(context) {
-		return context.(database.QueryableContext)
+// contextWithValue is context.WithValue keeping the transaction of a database.QueryableContext
+func contextWithValue(ctx context.Context, key, value any) context.Context {
+	valueCtx := context.WithValue(ctx, key, value)
+
+	if queryableContext, ok := ctx.(database.QueryableContext); ok {
+		return database.Context(valueCtx, queryableContext.Queryable())
+	}
+
+	return valueCtx
+}
+
+// gormDBFromContext returns a GORM session for the context. When the context is a
+// database.QueryableContext holding a *sql.Tx or *sql.Conn, the statements run on it,
+// so callers can include the store operations in their own transactions:
+//
+//	tx, _ := db.BeginTx(ctx, nil)
+//	token, err := store.TokenCreate(database.Context(ctx, tx), value, password, 32)
+//	// ... other statements on tx, then tx.Commit() or tx.Rollback()
+func (store *storeImplementation) gormDBFromContext(ctx context.Context) *gorm.DB {
+	db := store.gormDB.WithContext(ctx)
+
+	queryableContext, ok := ctx.(database.QueryableContext)
+	if !ok || queryableContext.Queryable() == nil || queryableContext.IsDB() {
+		return db
 	}
 
-	return database.Context(context, store.db)
+	db.Statement.ConnPool = queryableContext.Queryable()
+
+	return db
+}
+
+// transaction runs fn in a transaction. The caller's transaction is used if the context
+// holds one, otherwise a new one is started, committed if fn succeeds and rolled back if not.
+func (store *storeImplementation) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	queryableContext, ok := ctx.(database.QueryableContext)
+	if ok && queryableContext.IsTx() {
+		return fn(ctx)
+	}
+
+	var tx *sql.Tx
+	var err error
+	if ok && queryableContext.IsConn() {
+		tx, err = queryableContext.Queryable().(*sql.Conn).BeginTx(ctx, nil)
+	} else {
+		tx, err = store.db.BeginTx(ctx, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := fn(database.Context(ctx, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // TokensReadToResolvedMap accepts a map of key token pairs and returns a map of key value pairs
@@ -161,6 +350,14 @@ func (store *storeImplementation) toQuerableContext(context context.Context) dat
 // - resolvedMap (map[string]string): A map of key value pairs
 // - err (error): An error if one occurred
 func (store *storeImplementation) TokensReadToResolvedMap(ctx context.Context, keyTokenMap map[string]string, password string) (map[string]string, error) {
+	ctx, span := store.traceStart(ctx, "TokensReadToResolvedMap", traceRecordCount(len(keyTokenMap)))
+	defer span.End()
+
+	if err := store.operationAllowTokens(ctx, "TokensReadToResolvedMap", lo.Values(keyTokenMap)); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	// Handle empty input map
 	if len(keyTokenMap) == 0 {
 		return map[string]string{}, nil
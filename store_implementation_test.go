@@ -45,6 +45,23 @@ func initStore() (StoreInterface, error) {
 	return store, nil
 }
 
+// initStoreExpiresAtPastAllowed creates a store accepting expirations in the past,
+// for tests of expired tokens
+func initStoreExpiresAtPastAllowed() (StoreInterface, error) {
+	db, err := initDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(NewStoreOptions{
+		VaultTableName:       "vault_token",
+		VaultMetaTableName:   "vault_meta",
+		DB:                   db,
+		AutomigrateEnabled:   true,
+		ExpiresAtPastAllowed: true,
+	})
+}
+
 func TestWithAutoMigrateFalse(t *testing.T) {
 	db, err := initDB()
 
@@ -266,20 +283,20 @@ func Test_Store_EnableDebug(t *testing.T) {
 	}
 
 	// Verify initial debug state
-	if store.debugEnabled != false {
-		t.Fatalf("Expected debugEnabled to be false initially, got %v", store.debugEnabled)
+	if store.debugEnabled.Load() != false {
+		t.Fatalf("Expected debugEnabled to be false initially, got %v", store.debugEnabled.Load())
 	}
 
 	// Enable debug
 	store.EnableDebug(true)
-	if store.debugEnabled != true {
-		t.Fatalf("Expected debugEnabled to be true after enabling, got %v", store.debugEnabled)
+	if store.debugEnabled.Load() != true {
+		t.Fatalf("Expected debugEnabled to be true after enabling, got %v", store.debugEnabled.Load())
 	}
 
 	// Disable debug
 	store.EnableDebug(false)
-	if store.debugEnabled != false {
-		t.Fatalf("Expected debugEnabled to be false after disabling, got %v", store.debugEnabled)
+	if store.debugEnabled.Load() != false {
+		t.Fatalf("Expected debugEnabled to be false after disabling, got %v", store.debugEnabled.Load())
 	}
 }
 
@@ -325,29 +342,115 @@ func Test_Store_DbDriverName(t *testing.T) {
 	}
 }
 
-func Test_Store_toQuerableContext(t *testing.T) {
+func Test_Store_QueryableContext(t *testing.T) {
 	db, err := initDB()
 	if err != nil {
 		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
 	}
+	db.SetMaxOpenConns(1)
 
 	store, err := NewStore(NewStoreOptions{
 		VaultTableName:     "vault_context_test",
 		VaultMetaTableName: "vault_meta",
 		DB:                 db,
-		AutomigrateEnabled: false,
+		AutomigrateEnabled: true,
+	})
+
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// Rolled back with the caller's transaction
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	token, err := store.TokenCreate(database.Context(ctx, tx), "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	exists, err := store.TokenExists(ctx, token)
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if exists {
+		t.Fatal("Expected the token to be rolled back with the transaction")
+	}
+
+	// Committed with the caller's transaction
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	token, err = store.TokenCreate(database.Context(ctx, tx), "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "value" {
+		t.Fatalf("Expected [value] received [%v]", value)
+	}
+}
+
+func Test_Store_PrepareStatements(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_prepare_test",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		PrepareStatements:  true,
 	})
 
 	if err != nil {
 		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
 	}
 
-	// Test with regular context
+	if !store.gormDB.PrepareStmt {
+		t.Fatal("Expected PrepareStmt to be enabled")
+	}
+
 	ctx := context.Background()
-	qctx := store.toQuerableContext(ctx)
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "prepared_value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Read twice to exercise the cached statement
+	for i := 0; i < 2; i++ {
+		value, err := store.TokenRead(ctx, token, password)
+		if err != nil {
+			t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+		}
 
-	// Verify that the returned context is recognized as a QueryableContext
-	if !database.IsQueryableContext(qctx) {
-		t.Fatal("Expected QueryableContext from toQuerableContext")
+		if value != "prepared_value" {
+			t.Fatalf("Expected [prepared_value] received [%v]", value)
+		}
 	}
 }
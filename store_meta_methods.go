@@ -0,0 +1,281 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// metaDB returns a GORM session scoped to the meta table
+func (store *storeImplementation) metaDB(ctx context.Context) *gorm.DB {
+	return store.gormDBFromContext(ctx).Table(store.vaultMetaTableName)
+}
+
+// metaUniqueIndexName returns the name of the unique (object_type, object_id, meta_key) index.
+// The table name is included, as index names are shared across tables on some databases.
+func (store *storeImplementation) metaUniqueIndexName() string {
+	return "idx_" + store.vaultMetaTableName + "_object_key"
+}
+
+// ensureMetaUniqueIndex creates the unique index on (object_type, object_id, meta_key),
+// removing duplicate rows first (the most recent row wins)
+func (store *storeImplementation) ensureMetaUniqueIndex() error {
+	indexName := store.metaUniqueIndexName()
+
+	if store.gormDB.Migrator().HasIndex(store.vaultMetaTableName, indexName) {
+		return nil
+	}
+
+	// The derived table is required by MySQL, which cannot select from the table being deleted from
+	err := store.gormDB.Exec(
+		"DELETE FROM ? WHERE ? NOT IN (SELECT id FROM (SELECT MAX(?) AS id FROM ? GROUP BY ?, ?, ?) AS keep_ids)",
+		clause.Table{Name: store.vaultMetaTableName},
+		clause.Column{Name: "id"},
+		clause.Column{Name: "id"},
+		clause.Table{Name: store.vaultMetaTableName},
+		clause.Column{Name: COLUMN_OBJECT_TYPE},
+		clause.Column{Name: COLUMN_OBJECT_ID},
+		clause.Column{Name: COLUMN_META_KEY},
+	).Error
+	if err != nil {
+		return err
+	}
+
+	return store.gormDB.Exec(
+		"CREATE UNIQUE INDEX ? ON ? (?, ?, ?)",
+		clause.Column{Name: indexName},
+		clause.Table{Name: store.vaultMetaTableName},
+		clause.Column{Name: COLUMN_OBJECT_TYPE},
+		clause.Column{Name: COLUMN_OBJECT_ID},
+		clause.Column{Name: COLUMN_META_KEY},
+	).Error
+}
+
+// metaFind returns the meta row for the object and key, or nil if it does not exist
+func (store *storeImplementation) metaFind(ctx context.Context, objectType, objectID, key string) (*gormVaultMeta, error) {
+	var meta gormVaultMeta
+	err := store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ? AND "+COLUMN_META_KEY+" = ?", objectType, objectID, key).
+		First(&meta).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := store.metaValueDecrypt(&meta); err != nil {
+		return nil, err
+	}
+
+	return &meta, nil
+}
+
+// metaCreate inserts a new meta row. It fails if the object already has the key.
+func (store *storeImplementation) metaCreate(ctx context.Context, objectType, objectID, key, value string) error {
+	value, err := store.metaValueEncrypt(objectType, key, value)
+	if err != nil {
+		return err
+	}
+
+	return store.metaDB(ctx).Create(&gormVaultMeta{
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		Key:        key,
+		Value:      value,
+	}).Error
+}
+
+// metaSet creates or updates the meta row for the object and key
+func (store *storeImplementation) metaSet(ctx context.Context, objectType, objectID, key, value string) error {
+	existing, err := store.metaFind(ctx, objectType, objectID, key)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		existing.Value, err = store.metaValueEncrypt(objectType, key, value)
+		if err != nil {
+			return err
+		}
+		return store.metaDB(ctx).Save(existing).Error
+	}
+
+	return store.metaCreate(ctx, objectType, objectID, key, value)
+}
+
+// metaScopedObjectID returns the meta object ID for a key chosen by the application,
+// such as an idempotency key or a token alias. The key is hashed to fit the object_id
+// column and scoped to the vault table and namespace of the context.
+func (store *storeImplementation) metaScopedObjectID(ctx context.Context, key string) (string, error) {
+	tableName, err := store.vaultTableNameFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	namespace, err := store.namespaceFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// The keys of the default namespace keep their object ID
+	if namespace != "" {
+		tableName += "/" + namespace
+	}
+
+	return strToSHA256Hash(tableName + ":" + key), nil
+}
+
+// metaDelete removes the meta row for the object and key
+func (store *storeImplementation) metaDelete(ctx context.Context, objectType, objectID, key string) error {
+	return store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ? AND "+COLUMN_META_KEY+" = ?", objectType, objectID, key).
+		Delete(&gormVaultMeta{}).Error
+}
+
+// softDeletedMetaContextKey is the context key for including the metadata of soft deleted records
+type softDeletedMetaContextKey struct{}
+
+// WithSoftDeletedMeta returns a context in which the metadata of soft deleted
+// records (content type, revocation, etc.) keeps resolving, e.g. for audit views.
+// By default the metadata of a soft deleted record is hidden along with the record.
+func WithSoftDeletedMeta(ctx context.Context) context.Context {
+	return contextWithValue(ctx, softDeletedMetaContextKey{}, true)
+}
+
+// IsSoftDeletedMetaIncluded checks whether the context was created by WithSoftDeletedMeta
+func IsSoftDeletedMetaIncluded(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	included, _ := ctx.Value(softDeletedMetaContextKey{}).(bool)
+	return included
+}
+
+// recordMetaFind returns the meta row of the record for the key, or nil if it
+// does not exist or the record is soft deleted and the context does not include it
+func (store *storeImplementation) recordMetaFind(ctx context.Context, record RecordInterface, key string) (*gormVaultMeta, error) {
+	if isRecordSoftDeleted(record) && !IsSoftDeletedMetaIncluded(ctx) {
+		return nil, nil
+	}
+
+	return store.metaFind(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(record.GetID()), key)
+}
+
+// recordMetaFindKeys returns the meta rows of the record for the keys with a single query,
+// keyed by meta key. The rows are missing if the record is soft deleted and the context
+// does not include its metadata.
+func (store *storeImplementation) recordMetaFindKeys(ctx context.Context, record RecordInterface, keys ...string) (map[string]*gormVaultMeta, error) {
+	metas := map[string]*gormVaultMeta{}
+	if isRecordSoftDeleted(record) && !IsSoftDeletedMetaIncluded(ctx) {
+		return metas, nil
+	}
+
+	var rows []gormVaultMeta
+	err := store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ?", OBJECT_TYPE_RECORD, recordMetaObjectID(record.GetID())).
+		Where(COLUMN_META_KEY+" IN ?", keys).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		if err := store.metaValueDecrypt(&rows[i]); err != nil {
+			return nil, err
+		}
+		metas[rows[i].Key] = &rows[i]
+	}
+
+	return metas, nil
+}
+
+// recordMetaObjectID returns the meta object ID for metadata belonging to a record
+func recordMetaObjectID(recordID string) string {
+	return RECORD_META_ID_PREFIX + recordID
+}
+
+// recordMetaDelete removes all metadata belonging to the records
+func (store *storeImplementation) recordMetaDelete(ctx context.Context, recordIDs []string) error {
+	if len(recordIDs) == 0 {
+		return nil
+	}
+
+	objectIDs := make([]string, len(recordIDs))
+	for i, recordID := range recordIDs {
+		objectIDs[i] = recordMetaObjectID(recordID)
+	}
+
+	return store.metaDB(ctx).
+		Where(COLUMN_OBJECT_ID+" IN ?", objectIDs).
+		Delete(&gormVaultMeta{}).Error
+}
+
+// recordsMetaFind returns the meta rows of the records, with decrypted values,
+// for copying them along with the records
+func (store *storeImplementation) recordsMetaFind(ctx context.Context, gormRecords []gormVaultRecord) ([]gormVaultMeta, error) {
+	objectIDs := make([]string, len(gormRecords))
+	for i := range gormRecords {
+		objectIDs[i] = recordMetaObjectID(gormRecords[i].ID)
+	}
+
+	metas := []gormVaultMeta{}
+	var lastMetaID uint
+	for {
+		var batch []gormVaultMeta
+		err := store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" IN ?", recordMetaObjectTypes).
+			Where(COLUMN_OBJECT_ID+" IN ?", objectIDs).
+			Where(COLUMN_ID+" > ?", lastMetaID).
+			Order(COLUMN_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&batch).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if len(batch) == 0 {
+			return metas, nil
+		}
+		lastMetaID = batch[len(batch)-1].ID
+
+		for i := range batch {
+			if err := store.metaValueDecrypt(&batch[i]); err != nil {
+				return nil, err
+			}
+		}
+
+		metas = append(metas, batch...)
+	}
+}
+
+// recordIDsWithMeta returns the IDs of the records among the given records having the meta key
+func (store *storeImplementation) recordIDsWithMeta(ctx context.Context, records []RecordInterface, key string) (map[string]bool, error) {
+	found := map[string]bool{}
+
+	for _, batch := range lo.Chunk(records, maxRecordsInMemory) {
+		objectIDs := lo.Map(batch, func(record RecordInterface, _ int) string {
+			return recordMetaObjectID(record.GetID())
+		})
+
+		var objectIDsWithMeta []string
+		err := store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_RECORD, key).
+			Where(COLUMN_OBJECT_ID+" IN ?", objectIDs).
+			Pluck(COLUMN_OBJECT_ID, &objectIDsWithMeta).Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, objectID := range objectIDsWithMeta {
+			found[strings.TrimPrefix(objectID, RECORD_META_ID_PREFIX)] = true
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,152 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_Store_MetaMethods(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_meta_methods",
+		VaultMetaTableName: "vault_meta_methods_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+
+	meta, err := store.metaFind(ctx, OBJECT_TYPE_RECORD, "r_1", "owner")
+	if err != nil {
+		t.Fatalf("metaFind: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if meta != nil {
+		t.Fatal("Expected meta to not exist")
+	}
+
+	if err := store.metaSet(ctx, OBJECT_TYPE_RECORD, "r_1", "owner", "alice"); err != nil {
+		t.Fatalf("metaSet: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.metaSet(ctx, OBJECT_TYPE_RECORD, "r_1", "owner", "bob"); err != nil {
+		t.Fatalf("metaSet: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	meta, err = store.metaFind(ctx, OBJECT_TYPE_RECORD, "r_1", "owner")
+	if err != nil {
+		t.Fatalf("metaFind: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if meta == nil || meta.Value != "bob" {
+		t.Fatalf("Expected meta value [bob] received [%v]", meta)
+	}
+
+	// The unique index rejects a second row for the same object and key
+	if err := store.metaCreate(ctx, OBJECT_TYPE_RECORD, "r_1", "owner", "carol"); err == nil {
+		t.Fatal("Expected unique index violation for duplicate meta key")
+	}
+
+	if err := store.metaDelete(ctx, OBJECT_TYPE_RECORD, "r_1", "owner"); err != nil {
+		t.Fatalf("metaDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	meta, err = store.metaFind(ctx, OBJECT_TYPE_RECORD, "r_1", "owner")
+	if err != nil {
+		t.Fatalf("metaFind: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if meta != nil {
+		t.Fatal("Expected meta to be deleted")
+	}
+
+	// Migrating again keeps the existing index
+	if err := store.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate: Expected [err] to be nil received [%v]", err.Error())
+	}
+}
+
+func Test_Store_RecordMetaFindKeys(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "test_value", password, 20, TokenCreateOptions{MaxReads: 2, ContentType: "text/plain"})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenRevoke(ctx, token, "compromised"); err != nil {
+		t.Fatalf("TokenRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil || record == nil {
+		t.Fatalf("RecordFindByToken: Expected record received [%v] [%v]", record, err)
+	}
+
+	metas, err := store.(*storeImplementation).recordMetaFindKeys(ctx, record, tokenReadableMetaKeys...)
+	if err != nil {
+		t.Fatalf("recordMetaFindKeys: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Only the requested keys are loaded
+	if len(metas) != 2 || metas[META_KEY_REVOCATION] == nil || metas[META_KEY_READS_REMAINING] == nil {
+		t.Fatalf("Expected the revocation and read limit meta rows received %v", metas)
+	}
+
+	if metas[META_KEY_READS_REMAINING].Value != "2" {
+		t.Fatalf("Expected [2] remaining reads received [%v]", metas[META_KEY_READS_REMAINING].Value)
+	}
+}
+
+func Test_Store_SoftDeletedRecordMeta(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	token, err := store.TokenCreate(ctx, "test_value", "test_password_that_is_long_enough_for_security_32chars", 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenRevoke(ctx, token, "compromised"); err != nil {
+		t.Fatalf("TokenRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenSoftDelete(ctx, token); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The metadata of the soft deleted record is hidden by default
+	revocations, err := store.RevokedList(ctx)
+	if err != nil {
+		t.Fatalf("RevokedList: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if len(revocations) != 0 {
+		t.Fatalf("Expected no revocations received %v", revocations)
+	}
+
+	// Audit views include it
+	revocations, err = store.RevokedList(WithSoftDeletedMeta(ctx))
+	if err != nil {
+		t.Fatalf("RevokedList: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if len(revocations) != 1 || revocations[0].Token != token {
+		t.Fatalf("Expected revocation of [%s] received %v", token, revocations)
+	}
+
+	if IsSoftDeletedMetaIncluded(ctx) || !IsSoftDeletedMetaIncluded(WithSoftDeletedMeta(ctx)) {
+		t.Fatal("Expected IsSoftDeletedMetaIncluded to reflect WithSoftDeletedMeta")
+	}
+}
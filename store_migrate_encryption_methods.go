@@ -0,0 +1,139 @@
+package vaultstore
+
+import (
+	"context"
+	"fmt"
+)
+
+// MigrateOptions configures MigrateEncryptionV1ToV2
+type MigrateOptions struct {
+	// BatchSize is the number of records read per batch, defaults to 1000
+	BatchSize int
+
+	// DryRun counts the records that would be migrated without changing them
+	DryRun bool
+
+	// Progress is called after each batch with the totals so far
+	Progress func(progress MigrateProgress)
+}
+
+// MigrateProgress reports the progress of MigrateEncryptionV1ToV2
+type MigrateProgress struct {
+	// Scanned is the number of legacy v1 records read so far
+	Scanned int
+
+	// Migrated is the number of records re-encrypted so far (or that would be, on a dry run)
+	Migrated int
+}
+
+// MigrateEncryptionV1ToV2 re-encrypts the values stored in the legacy v1 (XOR) format
+// with the current AES-GCM format. Only the records that can be decrypted with the
+// given password are migrated, the others are left untouched, so the migration is
+// run once per password in use. Soft deleted records are migrated too.
+//
+// The write is conditional on the value not having changed since it was read,
+// a record updated concurrently is already in the current format and is skipped.
+//
+// Parameters:
+// - ctx: The context
+// - password: The password the legacy values were encrypted with
+// - opts: The batch size, progress callback and dry run mode
+//
+// Returns:
+// - count: The number of records migrated (or that would be, on a dry run)
+// - err: An error if something went wrong
+func (store *storeImplementation) MigrateEncryptionV1ToV2(ctx context.Context, password string, opts MigrateOptions) (count int, err error) {
+	ctx, span := store.traceStart(ctx, "MigrateEncryptionV1ToV2")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "MigrateEncryptionV1ToV2", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := store.validatePassword(password); err != nil {
+		return 0, err
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = maxRecordsInMemory
+	}
+
+	progress := MigrateProgress{}
+	lastID := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress.Migrated, err
+		}
+
+		// Chunked values are always written in the current format
+		var gormRecords []gormVaultRecord
+		err := store.vaultDB(ctx).
+			Select(COLUMN_ID, COLUMN_VAULT_VALUE).
+			Where(COLUMN_ID+" > ?", lastID).
+			Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", ENCRYPTION_PREFIX_V2+"%").
+			Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", ENCRYPTION_PREFIX_V3+"%").
+			Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", ENCRYPTION_PREFIX_PEPPER+"%").
+			Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", ENCRYPTION_PREFIX_ENVELOPE+"%").
+			Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", CHUNKED_VALUE_PREFIX+"%").
+			Order(COLUMN_ID + " ASC").
+			Limit(batchSize).
+			Find(&gormRecords).Error
+		if err != nil {
+			return progress.Migrated, err
+		}
+
+		if len(gormRecords) == 0 {
+			store.logInfo(ctx, "encryption migration finished", "scanned", progress.Scanned, "migrated", progress.Migrated, "dry_run", opts.DryRun)
+			return progress.Migrated, nil
+		}
+		lastID = gormRecords[len(gormRecords)-1].ID
+
+		for _, gormRecord := range gormRecords {
+			progress.Scanned++
+
+			migrated, err := store.recordMigrateV1ToV2(ctx, gormRecord, password, opts.DryRun)
+			if err != nil {
+				return progress.Migrated, err
+			}
+
+			if migrated {
+				progress.Migrated++
+			}
+		}
+
+		store.logInfo(ctx, "encryption migration progress", "scanned", progress.Scanned, "migrated", progress.Migrated, "dry_run", opts.DryRun)
+
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+}
+
+// recordMigrateV1ToV2 re-encrypts a legacy v1 record value with the current format.
+// Returns false if the value belongs to another password or was changed concurrently.
+func (store *storeImplementation) recordMigrateV1ToV2(ctx context.Context, gormRecord gormVaultRecord, password string, dryRun bool) (bool, error) {
+	decryptedValue, err := decodeV1(gormRecord.Value, password)
+	if err != nil {
+		// Encrypted with another password, skip it
+		return false, nil
+	}
+
+	if dryRun {
+		return true, nil
+	}
+
+	encodedValue, err := encode(ctx, decryptedValue, password, store.cryptoConfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode value for record %s: %w", gormRecord.ID, err)
+	}
+
+	swapped, err := store.recordValueSwap(ctx, gormRecord.ID, gormRecord.Value, encodedValue)
+	if err != nil {
+		return false, fmt.Errorf("failed to update record %s: %w", gormRecord.ID, err)
+	}
+
+	return swapped, nil
+}
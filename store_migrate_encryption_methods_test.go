@@ -0,0 +1,124 @@
+package vaultstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func Test_Store_MigrateEncryptionV1ToV2(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	otherPassword := "another_password_that_is_long_enough_32chars"
+
+	legacy := map[string]string{
+		"tk_migrate_v1_record_000001": "value1",
+		"tk_migrate_v1_record_000002": "value2",
+		"tk_migrate_v1_record_000003": "value3",
+	}
+	for token, value := range legacy {
+		record := NewRecord().SetToken(token).SetValue(encodeV1(value, password))
+		if err := store.RecordCreate(ctx, record); err != nil {
+			t.Fatalf("RecordCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	otherRecord := NewRecord().SetToken("tk_migrate_v1_record_other1").SetValue(encodeV1("other", otherPassword))
+	if err := store.RecordCreate(ctx, otherRecord); err != nil {
+		t.Fatalf("RecordCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	current, err := store.TokenCreate(ctx, "current", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// A dry run only counts
+	count, err := store.MigrateEncryptionV1ToV2(ctx, password, MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("MigrateEncryptionV1ToV2: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected dry run count 3 received [%v]", count)
+	}
+
+	implementation := store.(*storeImplementation)
+	pending, err := implementation.v1CiphertextCount(ctx)
+	if err != nil {
+		t.Fatalf("v1CiphertextCount: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if pending != 4 {
+		t.Fatalf("Expected 4 pending v1 values after the dry run received [%v]", pending)
+	}
+
+	progress := []MigrateProgress{}
+	count, err = store.MigrateEncryptionV1ToV2(ctx, password, MigrateOptions{
+		BatchSize: 2,
+		Progress: func(p MigrateProgress) {
+			progress = append(progress, p)
+		},
+	})
+	if err != nil {
+		t.Fatalf("MigrateEncryptionV1ToV2: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected 3 migrated records received [%v]", count)
+	}
+
+	if len(progress) != 2 || progress[1].Scanned != 4 || progress[1].Migrated != 3 {
+		t.Fatalf("Unexpected progress [%+v]", progress)
+	}
+
+	for token, value := range legacy {
+		record, err := store.RecordFindByToken(ctx, token)
+		if err != nil {
+			t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if !strings.HasPrefix(record.GetValue(), ENCRYPTION_PREFIX_V2) {
+			t.Fatalf("Expected token [%v] to be migrated received [%v]", token, record.GetValue())
+		}
+
+		read, err := store.TokenRead(ctx, token, password)
+		if err != nil {
+			t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if read != value {
+			t.Fatalf("Expected [%v] received [%v]", value, read)
+		}
+	}
+
+	// Values of another password and current values are untouched
+	read, err := store.TokenRead(ctx, current, password)
+	if err != nil || read != "current" {
+		t.Fatalf("TokenRead: Expected [current] received [%v] [%v]", read, err)
+	}
+
+	pending, err = implementation.v1CiphertextCount(ctx)
+	if err != nil {
+		t.Fatalf("v1CiphertextCount: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if pending != 1 {
+		t.Fatalf("Expected 1 pending v1 value received [%v]", pending)
+	}
+
+	// Running again is a no-op
+	count, err = store.MigrateEncryptionV1ToV2(ctx, password, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("MigrateEncryptionV1ToV2: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 0 {
+		t.Fatalf("Expected 0 migrated records received [%v]", count)
+	}
+}
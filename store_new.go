@@ -1,29 +1,19 @@
 package vaultstore
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"slices"
 
 	"github.com/dracory/database"
 
-	"gorm.io/driver/mysql"
-	"gorm.io/driver/postgres"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 // NewStore creates a new entity store
 func NewStore(opts NewStoreOptions) (*storeImplementation, error) {
-	if opts.VaultTableName == "" {
-		return nil, errors.New("vault store: vaultTableName is required")
-	}
-
-	if opts.VaultMetaTableName == "" {
-		return nil, errors.New("vault store: vaultMetaTableName is required")
-	}
-
-	if opts.DB == nil {
-		return nil, errors.New("vault store: DB is required")
+	if err := opts.Validate(); err != nil {
+		return nil, err
 	}
 
 	dbDriverName := opts.DbDriverName
@@ -32,34 +22,65 @@ func NewStore(opts NewStoreOptions) (*storeImplementation, error) {
 	}
 
 	// Set crypto config with secure defaults
-	cryptoConfig := opts.CryptoConfig
-	if cryptoConfig == nil {
-		cryptoConfig = DefaultCryptoConfig()
+	cryptoConfig := DefaultCryptoConfig()
+	if opts.CryptoConfig != nil {
+		// Copied, the key derivation statistics belong to this store
+		configCopy := *opts.CryptoConfig
+		cryptoConfig = &configCopy
+	}
+	cryptoConfig.kdfStats = newKDFStats(opts.KDFObserveFunc)
+	cryptoConfig.metrics = opts.MetricsCollector
+
+	pepper, err := pepperLoad(opts)
+	if err != nil {
+		return nil, err
 	}
+	cryptoConfig.pepper = pepper
+
+	keyProvider := opts.KeyProvider
+	if len(opts.MasterKey) > 0 {
+		if keyProvider != nil {
+			return nil, errors.New("vault store: MasterKey and KeyProvider are mutually exclusive")
+		}
 
-	var dialector gorm.Dialector
+		var err error
+		keyProvider, err = NewMasterKeyProvider(opts.MasterKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cryptoConfig.envelope = newEnvelopeKeys(keyProvider, opts.KeyProviderPrevious)
 
-	dbType := database.DatabaseType(opts.DB)
-	switch dbType {
-	case "sqlite":
-		dialector = sqlite.New(sqlite.Config{Conn: opts.DB})
-	case "mysql":
-		dialector = mysql.New(mysql.Config{Conn: opts.DB})
-	case "postgres", "postgresql":
-		dialector = postgres.New(postgres.Config{Conn: opts.DB})
-	default:
-		return nil, fmt.Errorf("unsupported database connection: %s", dbType)
+	dialector := opts.Dialector
+	if dialector == nil {
+		var err error
+		dialector, err = dialectorNew(opts.DbDriverName, database.DatabaseType(opts.DB), opts.DB)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Initialize GORM DB from existing *sql.DB using glebarez/sqlite (pure Go)
 	// gormDB, err := gorm.Open(&sqlite.Dialector{
 	// 	Conn: opts.DB,
 	// }, &gorm.Config{})
-	gormDB, err := gorm.Open(dialector, &gorm.Config{})
+	// The GORM output goes to the logger of the store, set once the store is created
+	gormLogger := &gormLogger{}
+
+	gormDB, err := gorm.Open(dialector, &gorm.Config{
+		PrepareStmt: opts.PrepareStatements,
+		Logger:      gormLogger,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if opts.TracerProvider != nil {
+		if err := traceCallbacksRegister(gormDB); err != nil {
+			return nil, err
+		}
+	}
+
 	store := &storeImplementation{
 		vaultTableName:           opts.VaultTableName,
 		vaultMetaTableName:       opts.VaultMetaTableName,
@@ -67,7 +88,6 @@ func NewStore(opts NewStoreOptions) (*storeImplementation, error) {
 		db:                       opts.DB,
 		gormDB:                   gormDB,
 		dbDriverName:             dbDriverName,
-		debugEnabled:             opts.DebugEnabled,
 		cryptoConfig:             cryptoConfig,
 		parallelThreshold:        opts.ParallelThreshold,
 		passwordAllowEmpty:       opts.PasswordAllowEmpty,
@@ -76,6 +96,68 @@ func NewStore(opts NewStoreOptions) (*storeImplementation, error) {
 		passwordRequireUppercase: opts.PasswordRequireUppercase,
 		passwordRequireNumbers:   opts.PasswordRequireNumbers,
 		passwordRequireSymbols:   opts.PasswordRequireSymbols,
+		decryptWorkers:           opts.DecryptWorkers,
+		eventHooks:               opts.EventHooks,
+		operationGuard:           opts.OperationGuard,
+		tracer:                   tracerNew(opts.TracerProvider),
+		quotaThresholds:          opts.QuotaThresholds,
+		valueChunkThreshold:      opts.ValueChunkThreshold,
+		databaseTimestamps:       opts.DatabaseTimestamps,
+		namespacesEnabled:        opts.NamespacesEnabled,
+		fastRecordsEnabled:       opts.FastRecordsEnabled,
+		recordIDFunc:             opts.RecordIDFunc,
+		versionAutoUpgrade:       opts.VersionAutoUpgrade,
+		migrationBackupEnabled:   opts.MigrationBackupEnabled,
+		valueValidateFunc:        opts.ValueValidateFunc,
+		valueQuarantineEnabled:   opts.ValueQuarantineEnabled,
+		tokenVersioningEnabled:   opts.TokenVersioningEnabled,
+		archivePassword:          opts.ArchivePassword,
+		readThroughCache:         newReadThroughCache(opts.ReadThroughCacheSize, opts.ReadThroughCacheTTL),
+		deleteBatchSize:          opts.DeleteBatchSize,
+		deleteBatchPause:         opts.DeleteBatchPause,
+		expiresAtPastAllowed:     opts.ExpiresAtPastAllowed,
+		redactor:                 opts.Redactor,
+	}
+
+	store.debugEnabled.Store(opts.DebugEnabled)
+	store.quotaCheckInterval.Store(int64(opts.QuotaCheckInterval))
+	store.logger.Store(opts.Logger)
+	store.logLevel.Set(opts.LogLevel)
+	gormLogger.store = store
+
+	if opts.RecordFactory != nil {
+		store.recordFactory = opts.RecordFactory
+		store.recordExtraColumns = recordFactoryExtraColumns(opts.RecordFactory)
+	}
+
+	if len(opts.ExtraColumns) > 0 {
+		if err := validateColumnSpecs(opts.ExtraColumns); err != nil {
+			return nil, err
+		}
+
+		store.extraColumns = opts.ExtraColumns
+		for _, spec := range opts.ExtraColumns {
+			if !slices.Contains(store.recordExtraColumns, spec.Name) {
+				store.recordExtraColumns = append(store.recordExtraColumns, spec.Name)
+			}
+		}
+	}
+
+	if opts.MetaEncryptionKey != "" {
+		store.metaAEAD, err = newMetaAEAD(opts.MetaEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.TokenBloomFilterEnabled {
+		store.tokenBloomFilter = newTokenBloomFilter(opts.TokenBloomFilterCapacity, opts.TokenBloomFilterRefreshInterval)
+	}
+
+	// Refuse to start, before migrating, on a vault written by a newer format version
+	version, err := store.vaultVersionGate(context.Background())
+	if err != nil {
+		return nil, err
 	}
 
 	if store.automigrateEnabled {
@@ -83,6 +165,23 @@ func NewStore(opts NewStoreOptions) (*storeImplementation, error) {
 		if err != nil {
 			return nil, err
 		}
+
+		err = store.PolicyReload(context.Background())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The bloom filter answers misses without the database, refuse a second writer
+	if store.tokenBloomFilter != nil {
+		if err := store.tokenBloomFilterLease(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	err = store.vaultVersionStamp(context.Background(), version)
+	if err != nil {
+		return nil, err
 	}
 
 	return store, nil
@@ -0,0 +1,171 @@
+package vaultstore
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// StoreOption sets options of the store created by New
+type StoreOption func(opts *NewStoreOptions)
+
+// New creates a new store on the database, configured with functional options.
+// It is equivalent to NewStore with the NewStoreOptions the options set, which
+// remains supported. The options are validated before the store is created.
+//
+//	store, err := vaultstore.New(db,
+//	    vaultstore.WithTableNames("vault", "vault_meta"),
+//	    vaultstore.WithAutomigrate(),
+//	    vaultstore.WithPasswordPolicy(vaultstore.PasswordPolicy{MinLength: 24}),
+//	)
+//
+// Parameters:
+// - db: The database
+// - options: The options, applied in order
+//
+// Returns:
+// - store: The store
+// - err: ErrOptionsInvalid for invalid options, or an error if something went wrong
+func New(db *sql.DB, options ...StoreOption) (StoreInterface, error) {
+	opts := NewStoreOptions{DB: db}
+	for _, option := range options {
+		if option != nil {
+			option(&opts)
+		}
+	}
+
+	store, err := NewStore(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// WithTableNames sets the names of the vault and meta tables, required
+func WithTableNames(vaultTableName string, metaTableName string) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.VaultTableName = vaultTableName
+		opts.VaultMetaTableName = metaTableName
+	}
+}
+
+// WithAutomigrate creates and migrates the tables on start
+func WithAutomigrate() StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.AutomigrateEnabled = true
+	}
+}
+
+// WithDriverName sets the database driver name, when it cannot be detected from the connection
+func WithDriverName(driverName string) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.DbDriverName = driverName
+	}
+}
+
+// WithDialector sets the GORM dialector, see NewStoreOptions.Dialector
+func WithDialector(dialector gorm.Dialector) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.Dialector = dialector
+	}
+}
+
+// WithDebug enables the debug output, written to the logger
+func WithDebug(logger *slog.Logger) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.DebugEnabled = true
+		opts.Logger = logger
+	}
+}
+
+// WithCryptoConfig sets the encryption parameters, see CryptoConfig
+func WithCryptoConfig(config *CryptoConfig) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.CryptoConfig = config
+	}
+}
+
+// WithPasswordPolicy sets the requirements for the passwords, replacing the Password* options
+func WithPasswordPolicy(policy PasswordPolicy) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.PasswordAllowEmpty = policy.AllowEmpty
+		opts.PasswordMinLength = policy.MinLength
+		opts.PasswordRequireLowercase = policy.RequireLowercase
+		opts.PasswordRequireUppercase = policy.RequireUppercase
+		opts.PasswordRequireNumbers = policy.RequireNumbers
+		opts.PasswordRequireSymbols = policy.RequireSymbols
+	}
+}
+
+// WithPepper sets the pepper mixed into the key derivation, see NewStoreOptions.Pepper
+func WithPepper(pepper []byte) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.Pepper = pepper
+	}
+}
+
+// WithKeyProvider enables envelope encryption, see NewStoreOptions.KeyProvider
+func WithKeyProvider(provider KeyProvider) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.KeyProvider = provider
+	}
+}
+
+// WithOperationGuard authorizes every operation of the store, see OperationGuard
+func WithOperationGuard(guard OperationGuard) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.OperationGuard = guard
+	}
+}
+
+// WithEventHooks adds hooks receiving the events of the store
+func WithEventHooks(hooks ...EventHook) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.EventHooks = append(opts.EventHooks, hooks...)
+	}
+}
+
+// WithTracerProvider enables OpenTelemetry spans for the store methods, see NewStoreOptions.TracerProvider
+func WithTracerProvider(provider trace.TracerProvider) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.TracerProvider = provider
+	}
+}
+
+// WithMetricsCollector sets the collector of the metrics of the store, see MetricsCollector
+func WithMetricsCollector(collector MetricsCollector) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.MetricsCollector = collector
+	}
+}
+
+// WithParallelThreshold sets the number of records above which bulk operations run in parallel
+func WithParallelThreshold(threshold int) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.ParallelThreshold = threshold
+	}
+}
+
+// WithDecryptWorkers sets the number of parallel decrypt workers of batch reads
+func WithDecryptWorkers(workers int) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.DecryptWorkers = workers
+	}
+}
+
+// WithDeleteBatch sets the batch size and the pause between the batches of bulk deletes
+func WithDeleteBatch(size int, pause time.Duration) StoreOption {
+	return func(opts *NewStoreOptions) {
+		opts.DeleteBatchSize = size
+		opts.DeleteBatchPause = pause
+	}
+}
+
+// WithOptions sets any of the NewStoreOptions, for those without a dedicated option
+func WithOptions(fn func(opts *NewStoreOptions)) StoreOption {
+	return StoreOption(fn)
+}
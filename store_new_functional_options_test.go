@@ -0,0 +1,73 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_NewStoreOptions_Validate(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	valid := NewStoreOptions{
+		VaultTableName:     "vault",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+	}
+
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	cases := map[string]func(opts *NewStoreOptions){
+		"VaultTableName":    func(opts *NewStoreOptions) { opts.VaultTableName = "" },
+		"DB":                func(opts *NewStoreOptions) { opts.DB = nil },
+		"Cipher":            func(opts *NewStoreOptions) { opts.CryptoConfig = &CryptoConfig{Cipher: "des"} },
+		"ParallelThreshold": func(opts *NewStoreOptions) { opts.ParallelThreshold = -1 },
+		"PasswordMinLength": func(opts *NewStoreOptions) { opts.PasswordMinLength = -1 },
+		"DeleteBatchPause":  func(opts *NewStoreOptions) { opts.DeleteBatchPause = -time.Second },
+	}
+
+	for name, invalidate := range cases {
+		opts := valid
+		invalidate(&opts)
+
+		if err := opts.Validate(); !errors.Is(err, ErrOptionsInvalid) {
+			t.Fatalf("Validate(%s): Expected [ErrOptionsInvalid] received [%v]", name, err)
+		}
+	}
+}
+
+func Test_New(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := New(db, WithParallelThreshold(-1)); !errors.Is(err, ErrOptionsInvalid) {
+		t.Fatalf("New: Expected [ErrOptionsInvalid] received [%v]", err)
+	}
+
+	store, err := New(db,
+		WithTableNames("vault_functional", "vault_functional_meta"),
+		WithAutomigrate(),
+		WithPasswordPolicy(PasswordPolicy{MinLength: 24}),
+	)
+	if err != nil {
+		t.Fatalf("New: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+
+	if _, err := store.TokenCreate(ctx, "value", "twenty_chars_password", 20); err == nil {
+		t.Fatal("TokenCreate: Expected an error for a password shorter than the policy")
+	}
+
+	if _, err := store.TokenCreate(ctx, "value", "password_of_twenty_four_", 20); err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+}
@@ -2,6 +2,13 @@ package vaultstore
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 )
 
 // NewStoreOptions define the options for creating a new session store
@@ -20,4 +27,220 @@ type NewStoreOptions struct {
 	PasswordRequireUppercase bool // Require at least one uppercase letter (default: false)
 	PasswordRequireNumbers   bool // Require at least one number (default: false)
 	PasswordRequireSymbols   bool // Require at least one symbol (default: false)
+	PrepareStatements        bool // Cache prepared statements for repeated queries (default: false)
+	DecryptWorkers           int  // Parallel decrypt workers for batch reads (0 = use default min(NumCPU, 4))
+
+	// TokenBloomFilterEnabled keeps an in-memory bloom filter of existing tokens, so reads
+	// of tokens that definitely do not exist are answered without querying the database.
+	// It requires a single store instance writing the vault tables: the instance takes a
+	// writer lease in the vault settings, and NewStore returns ErrTokenBloomFilterWriter
+	// while another instance holds it. The lease is renewed on each refresh and lapses
+	// after three refresh intervals without reads; once it is lost, reads fall back to
+	// the database. Tokens written by ApplyChanges or Import reload the filter on its next refresh.
+	TokenBloomFilterEnabled         bool
+	TokenBloomFilterCapacity        int           // Expected number of tokens (0 = use default 100000)
+	TokenBloomFilterRefreshInterval time.Duration // How often tokens created elsewhere are loaded (0 = use default 1 minute)
+
+	// EventHooks receive events emitted by the store, such as quota alarms
+	EventHooks []EventHook
+
+	// OperationGuard authorizes every operation of the store before it runs, e.g. against
+	// the caller set in the context (default: all allowed). See OperationGuardFunc.
+	OperationGuard OperationGuard
+
+	// TracerProvider enables OpenTelemetry spans for the store methods called by the
+	// embedder, with their database statements as events (default: no tracing).
+	// The spans never carry values, passwords or tokens.
+	TracerProvider trace.TracerProvider
+
+	// QuotaThresholds are soft limits that emit quota events when exceeded
+	QuotaThresholds QuotaThresholds
+	// QuotaCheckInterval runs QuotaCheck automatically after writes, at most once
+	// per interval (0 = disabled, call QuotaCheck manually)
+	QuotaCheckInterval time.Duration
+
+	// ValueChunkThreshold is the ciphertext length in bytes above which values are
+	// split into a "<vault table>_chunk" table, keeping the vault table rows small
+	// (0 = disabled). Keep it set while chunked values exist.
+	ValueChunkThreshold int
+
+	// DatabaseTimestamps lets the database clock set created_at/updated_at,
+	// avoiding timestamp skew between application servers (default: false)
+	DatabaseTimestamps bool
+
+	// FastRecordsEnabled returns struct-backed records (see NewFastRecord) from reads instead
+	// of map-backed ones, reducing allocations for latency-sensitive workloads (default: false)
+	FastRecordsEnabled bool
+
+	// RecordIDFunc generates the IDs of records created by the store, e.g. UUIDv7 or
+	// snowflake IDs (max 40 chars). Defaults to uid.HumanUid.
+	RecordIDFunc func() string
+
+	// VersionAutoUpgrade stamps a vault written by an older supported format version
+	// with VAULT_VERSION_CURRENT on start, so older binaries can no longer open it.
+	// Vaults older than VAULT_VERSION_MIN_SUPPORTED are refused without it (default: false)
+	VersionAutoUpgrade bool
+
+	// MigrationBackupEnabled snapshots the store tables (CREATE TABLE ... AS SELECT) before
+	// AutoMigrate migrates a vault written by an older format version, and records the
+	// rollback script in the vault settings, see MigrationRollbackScript (default: false)
+	MigrationBackupEnabled bool
+
+	// ValueValidateFunc is called with every decrypted value read, e.g. ValidateJSON for
+	// stores of JSON documents. A failure is returned as ErrValueInvalid with its details.
+	ValueValidateFunc func(token string, value string) error
+	// ValueQuarantineEnabled flags records failing ValueValidateFunc as quarantined
+	// in the meta table, for later investigation (default: false)
+	ValueQuarantineEnabled bool
+
+	// TokenVersioningEnabled keeps the previous encrypted value of a token on TokenUpdate and
+	// TokenCompareAndSwap, see TokenVersions and TokenDiff (default: false)
+	TokenVersioningEnabled bool
+
+	// ArchivePassword encrypts the archives written by ArchiveExpired. Keep it apart
+	// from the token passwords, it is required to read the archives back.
+	ArchivePassword string
+
+	// MetaEncryptionKey is the store master key encrypting the sensitive meta values
+	// (vault settings, password identities, idempotency keys, token aliases), min 32 characters.
+	// Use a random key, not a password. Existing rows are encrypted with MetaEncryptionMigrate.
+	MetaEncryptionKey string
+
+	// RecordFactory replaces the records returned by the store, e.g. with code-generated
+	// structs. Keys of their Data() beyond the vault columns are stored in custom columns
+	// of the vault table, which must exist. Takes precedence over FastRecordsEnabled.
+	RecordFactory RecordFactory
+
+	// ExtraColumns are custom columns of the vault table, e.g. owner_id or app_id, created by
+	// AutoMigrate. They are read and written with RecordInterface GetExtra/SetExtra and
+	// filtered with RecordQuery().SetExtraEquals, avoiding forks for simple schema additions.
+	ExtraColumns []ColumnSpec
+
+	// NamespacesEnabled adds a namespace column to the vault table, created by AutoMigrate,
+	// so one store isolates the tokens of many tenants: the operations are scoped to the
+	// namespace set with WithNamespace (default: false)
+	NamespacesEnabled bool
+
+	// KDFObserveFunc receives the duration of every Argon2id key derivation, e.g. to feed
+	// a metrics histogram. Called synchronously, it should return quickly. See also KDFStats.
+	KDFObserveFunc func(operation string, duration time.Duration)
+
+	// MetricsCollector receives the counts of the values read, written and failing to
+	// decrypt, and the encryption, decryption and key derivation latencies (default: none).
+	// See the vaultprometheus package for Prometheus.
+	MetricsCollector MetricsCollector
+
+	// ReadThroughCacheSize is the number of decrypted values kept by ReadThrough (default: 10000)
+	ReadThroughCacheSize int
+	// ReadThroughCacheTTL is the time a decrypted value is kept by ReadThrough (default: 1 minute)
+	ReadThroughCacheTTL time.Duration
+
+	// DeleteBatchSize is the number of records removed per statement by bulk deletes such as
+	// TokensExpiredDelete (default: 5000). Smaller batches keep locks and replication lag short.
+	DeleteBatchSize int
+	// DeleteBatchPause is the pause between the batches of bulk deletes (default: none)
+	DeleteBatchPause time.Duration
+
+	// ExpiresAtPastAllowed accepts TokenCreateOptions.ExpiresAt in the past, creating tokens
+	// that are already expired. By default ErrExpiresAtInPast is returned (default: false)
+	ExpiresAtPastAllowed bool
+
+	// Redactor builds the redacted token identifiers set on events (Event.TokenRedacted)
+	// and returned by the Redact method. Set a Key to keep them unguessable (default: SHA-256)
+	Redactor Redactor
+
+	// Dialector is the GORM dialector used for DB, e.g. sqlserver.New(sqlserver.Config{Conn: db})
+	// for SQL Server, whose driver is not a dependency of the store. Defaults to the SQLite,
+	// MySQL or PostgreSQL dialector, chosen by DbDriverName or the detected database type.
+	Dialector gorm.Dialector
+
+	// Logger receives the logs of the store: the janitor runs and the migration progress
+	// at info level, the failures at error level, and with debug enabled (see DebugEnabled
+	// and EnableDebug) the queries without their parameters. Values and passwords are never
+	// logged, tokens are redacted with Redactor. Can be changed with Reconfigure (default: none)
+	Logger *slog.Logger
+
+	// LogLevel is the minimum level of the logs of the store (default: slog.LevelInfo).
+	// The debug logs are also written when debug is enabled. Can be changed with Reconfigure.
+	LogLevel slog.Level
+
+	// Pepper is a secret of at least 32 bytes mixed into every password before the Argon2id
+	// derivation, kept outside of the database (e.g. in a secret manager), so a database dump
+	// alone is not enough to brute-force weak passwords. Values encrypted before stay readable,
+	// values encrypted with it cannot be read without it. Envelope encrypted values use it too.
+	Pepper []byte
+	// PepperFilePath reads the Pepper from a key file. Mutually exclusive with Pepper.
+	PepperFilePath string
+
+	// MasterKey enables envelope encryption with a 32 bytes master key held in memory,
+	// see NewMasterKeyProvider. Mutually exclusive with KeyProvider.
+	MasterKey []byte
+	// KeyProvider enables envelope encryption: each new value is encrypted with a random data key,
+	// wrapped by the provider, and a key derived from the password with Argon2id, so reading a
+	// value still costs a key derivation. The master key is rotated with EnvelopeRewrap.
+	// Values encrypted before stay readable.
+	KeyProvider KeyProvider
+	// KeyProviderPrevious reads the values not yet rewrapped by an interrupted EnvelopeRewrap
+	KeyProviderPrevious KeyProvider
+}
+
+// ErrOptionsInvalid is returned by NewStore for missing or inconsistent options
+var ErrOptionsInvalid = errors.New("vault store: invalid options")
+
+// Validate checks the options are complete and consistent, NewStore calls it first
+//
+// Returns:
+// - err: ErrOptionsInvalid wrapped with the invalid option, or nil
+func (opts NewStoreOptions) Validate() error {
+	if opts.VaultTableName == "" {
+		return fmt.Errorf("%w: VaultTableName is required", ErrOptionsInvalid)
+	}
+
+	if opts.VaultMetaTableName == "" {
+		return fmt.Errorf("%w: VaultMetaTableName is required", ErrOptionsInvalid)
+	}
+
+	if opts.DB == nil {
+		return fmt.Errorf("%w: DB is required", ErrOptionsInvalid)
+	}
+
+	if opts.CryptoConfig != nil && opts.CryptoConfig.Cipher != "" &&
+		opts.CryptoConfig.Cipher != CIPHER_AES_GCM && opts.CryptoConfig.Cipher != CIPHER_XCHACHA20_POLY1305 {
+		return fmt.Errorf("%w: unsupported CryptoConfig.Cipher %s", ErrOptionsInvalid, opts.CryptoConfig.Cipher)
+	}
+
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"ParallelThreshold", opts.ParallelThreshold},
+		{"PasswordMinLength", opts.PasswordMinLength},
+		{"DecryptWorkers", opts.DecryptWorkers},
+		{"TokenBloomFilterCapacity", opts.TokenBloomFilterCapacity},
+		{"ValueChunkThreshold", opts.ValueChunkThreshold},
+		{"ReadThroughCacheSize", opts.ReadThroughCacheSize},
+		{"DeleteBatchSize", opts.DeleteBatchSize},
+	}
+	for _, count := range counts {
+		if count.value < 0 {
+			return fmt.Errorf("%w: %s cannot be negative", ErrOptionsInvalid, count.name)
+		}
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"TokenBloomFilterRefreshInterval", opts.TokenBloomFilterRefreshInterval},
+		{"QuotaCheckInterval", opts.QuotaCheckInterval},
+		{"ReadThroughCacheTTL", opts.ReadThroughCacheTTL},
+		{"DeleteBatchPause", opts.DeleteBatchPause},
+	}
+	for _, duration := range durations {
+		if duration.value < 0 {
+			return fmt.Errorf("%w: %s cannot be negative", ErrOptionsInvalid, duration.name)
+		}
+	}
+
+	return nil
 }
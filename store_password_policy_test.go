@@ -0,0 +1,94 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_validatePasswordPolicy(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:        12,
+		RequireLowercase: true,
+		RequireUppercase: true,
+		RequireNumbers:   true,
+		RequireSymbols:   true,
+	}
+
+	tests := []struct {
+		password string
+		expected error
+	}{
+		{"Ab1!", ErrPasswordTooShort},
+		{"ABCDEFGHIJ1!", ErrPasswordMissingLowercase},
+		{"abcdefghij1!", ErrPasswordMissingUppercase},
+		{"Abcdefghijk!", ErrPasswordMissingNumber},
+		{"Abcdefghijk1", ErrPasswordMissingSymbol},
+		{"Abcdefghij1!", nil},
+	}
+
+	for _, test := range tests {
+		err := validatePasswordPolicy(test.password, policy)
+		if !errors.Is(err, test.expected) {
+			t.Fatalf("%s: Expected [%v] received [%v]", test.password, test.expected, err)
+		}
+		if test.expected != nil && !errors.Is(err, ErrPasswordInvalid) {
+			t.Fatalf("%s: Expected [ErrPasswordInvalid] received [%v]", test.password, err)
+		}
+	}
+
+	// Empty passwords are allowed only when the policy says so
+	if err := validatePasswordPolicy("", PasswordPolicy{AllowEmpty: true, MinLength: 12}); err != nil {
+		t.Fatalf("AllowEmpty: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if err := validatePasswordPolicy("", PasswordPolicy{}); !errors.Is(err, ErrPasswordTooShort) {
+		t.Fatalf("Empty: Expected [ErrPasswordTooShort] received [%v]", err)
+	}
+}
+
+func Test_Store_PasswordPolicy_Enforced(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:         "vault_password_policy",
+		VaultMetaTableName:     "vault_meta",
+		DB:                     db,
+		AutomigrateEnabled:     true,
+		PasswordMinLength:      20,
+		PasswordRequireSymbols: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "password_long_enough_with_symbols"
+
+	if _, err := store.TokenCreate(ctx, "value", "short_password!", 20); !errors.Is(err, ErrPasswordTooShort) {
+		t.Fatalf("TokenCreate: Expected [ErrPasswordTooShort] received [%v]", err)
+	}
+
+	if _, err := store.TokenCreate(ctx, "value", "passwordlongenoughwithoutsymbols", 20); !errors.Is(err, ErrPasswordMissingSymbol) {
+		t.Fatalf("TokenCreate: Expected [ErrPasswordMissingSymbol] received [%v]", err)
+	}
+
+	token, err := store.TokenCreate(ctx, "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenUpdate(ctx, token, "new_value", "short_password!"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Fatalf("TokenUpdate: Expected [ErrPasswordTooShort] received [%v]", err)
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != "value" {
+		t.Fatalf("TokenRead: Expected [value] received [%v]", value)
+	}
+}
@@ -0,0 +1,265 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Names of the checks run by Preflight
+const (
+	PREFLIGHT_CHECK_TABLES           = "tables"
+	PREFLIGHT_CHECK_INDEXES          = "indexes"
+	PREFLIGHT_CHECK_VAULT_VERSION    = "vault_version"
+	PREFLIGHT_CHECK_CRYPTO_SELF_TEST = "crypto_self_test"
+)
+
+// preflightSelfTestValue is the value encrypted and decrypted by the crypto self-test
+const preflightSelfTestValue = "vaultstore preflight"
+
+// PreflightCheck is the result of a single Preflight check
+type PreflightCheck struct {
+	// Name is the name of the check, one of the PREFLIGHT_CHECK_* constants
+	Name string
+	// Passed reports whether the check passed
+	Passed bool
+	// Details describes the failure, or what was verified
+	Details string
+}
+
+// PreflightReport is the result of Preflight
+type PreflightReport struct {
+	// VaultVersion is the persisted vault version (empty for a vault never stamped)
+	VaultVersion string
+	// V1CiphertextCount is the number of records still encrypted with the legacy v1 format,
+	// pending a format migration. They are readable, so they do not fail the preflight.
+	V1CiphertextCount int64
+	// Checks lists the checks in the order they ran
+	Checks []PreflightCheck
+}
+
+// Passed reports whether all checks passed
+func (report PreflightReport) Passed() bool {
+	for _, check := range report.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures returns the checks that did not pass
+func (report PreflightReport) Failures() []PreflightCheck {
+	failures := []PreflightCheck{}
+	for _, check := range report.Checks {
+		if !check.Passed {
+			failures = append(failures, check)
+		}
+	}
+	return failures
+}
+
+// Preflight verifies the store is ready to serve, intended to run at service boot:
+// the tables and indexes exist, the vault version is supported, and a value survives an
+// encrypt/decrypt roundtrip with the configured crypto settings. It also counts the
+// records pending a format migration, and warms up the token bloom filter if enabled.
+//
+// Failed checks are reported, not returned as an error, so all problems are seen at once.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - report: The preflight report, see PreflightReport.Passed
+// - err: An error if the preflight could not be run
+func (store *storeImplementation) Preflight(ctx context.Context) (PreflightReport, error) {
+	ctx, span := store.traceStart(ctx, "Preflight")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "Preflight", ""); err != nil {
+		return PreflightReport{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	report := PreflightReport{Checks: []PreflightCheck{}}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	tablesCheck := store.preflightTables(ctx)
+	report.Checks = append(report.Checks, tablesCheck)
+
+	// The remaining database checks need the tables
+	if tablesCheck.Passed {
+		report.Checks = append(report.Checks, store.preflightIndexes(ctx))
+
+		versionCheck, version := store.preflightVaultVersion(ctx)
+		report.VaultVersion = version
+		report.Checks = append(report.Checks, versionCheck)
+
+		count, err := store.v1CiphertextCount(ctx)
+		if err != nil {
+			return report, err
+		}
+		report.V1CiphertextCount = count
+	}
+
+	report.Checks = append(report.Checks, store.preflightCryptoSelfTest(ctx))
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	if tablesCheck.Passed {
+		// Warm-up, the first reads do not pay for loading the bloom filter
+		if _, err := store.tokenMayExist(ctx, ""); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// preflightTables checks that the store tables exist
+func (store *storeImplementation) preflightTables(ctx context.Context) PreflightCheck {
+	tableNames := []string{store.vaultTableName, store.vaultMetaTableName}
+	if store.isValueChunkingEnabled() {
+		tableNames = append(tableNames, store.vaultTableName+VALUE_CHUNK_TABLE_SUFFIX)
+	}
+
+	migrator := store.gormDB.WithContext(ctx).Migrator()
+
+	missing := []string{}
+	for _, tableName := range tableNames {
+		if !migrator.HasTable(tableName) {
+			missing = append(missing, tableName)
+		}
+	}
+
+	if len(missing) > 0 {
+		return PreflightCheck{
+			Name:    PREFLIGHT_CHECK_TABLES,
+			Details: "missing tables: " + strings.Join(missing, ", ") + ", run AutoMigrate",
+		}
+	}
+
+	return PreflightCheck{
+		Name:    PREFLIGHT_CHECK_TABLES,
+		Passed:  true,
+		Details: strings.Join(tableNames, ", "),
+	}
+}
+
+// preflightIndexes checks that the indexes created by AutoMigrate exist
+func (store *storeImplementation) preflightIndexes(ctx context.Context) PreflightCheck {
+	// Table name and index name pairs
+	indexes := [][2]string{
+		{store.vaultTableName, tokenIndexName(store.vaultTableName)},
+		{store.vaultMetaTableName, store.metaUniqueIndexName()},
+	}
+
+	if store.gormDB.Dialector.Name() == DIALECT_POSTGRES {
+		indexes = append(indexes, [2]string{store.vaultTableName, tokenPathIndexName(store.vaultTableName)})
+	}
+
+	for _, spec := range store.extraColumns {
+		if spec.Index {
+			indexes = append(indexes, [2]string{store.vaultTableName, "idx_" + store.vaultTableName + "_" + spec.Name})
+		}
+	}
+
+	migrator := store.gormDB.WithContext(ctx).Migrator()
+
+	missing := []string{}
+	for _, index := range indexes {
+		if !migrator.HasIndex(index[0], index[1]) {
+			missing = append(missing, index[1])
+		}
+	}
+
+	if store.namespacesEnabled {
+		namespaced, err := store.tokenIndexNamespaced(store.vaultTableName)
+		if err != nil {
+			return PreflightCheck{Name: PREFLIGHT_CHECK_INDEXES, Details: err.Error()}
+		}
+
+		if !namespaced {
+			missing = append(missing, tokenIndexName(store.vaultTableName)+" ("+COLUMN_NAMESPACE+", "+COLUMN_VAULT_TOKEN+")")
+		}
+	}
+
+	if len(missing) > 0 {
+		return PreflightCheck{
+			Name:    PREFLIGHT_CHECK_INDEXES,
+			Details: "missing indexes: " + strings.Join(missing, ", ") + ", run AutoMigrate",
+		}
+	}
+
+	return PreflightCheck{
+		Name:    PREFLIGHT_CHECK_INDEXES,
+		Passed:  true,
+		Details: fmt.Sprintf("%d indexes", len(indexes)),
+	}
+}
+
+// preflightVaultVersion checks that the persisted vault version is supported
+func (store *storeImplementation) preflightVaultVersion(ctx context.Context) (PreflightCheck, string) {
+	version, err := store.vaultVersionGate(ctx)
+	if err != nil {
+		return PreflightCheck{Name: PREFLIGHT_CHECK_VAULT_VERSION, Details: err.Error()}, version
+	}
+
+	if version == "" {
+		return PreflightCheck{
+			Name:    PREFLIGHT_CHECK_VAULT_VERSION,
+			Details: "vault version not set, run AutoMigrate",
+		}, version
+	}
+
+	return PreflightCheck{
+		Name:    PREFLIGHT_CHECK_VAULT_VERSION,
+		Passed:  true,
+		Details: "vault version " + version,
+	}, version
+}
+
+// preflightCryptoSelfTest encrypts and decrypts a value with the store crypto settings
+func (store *storeImplementation) preflightCryptoSelfTest(ctx context.Context) PreflightCheck {
+	password, err := generateToken(TOKEN_MAX_TOTAL_LENGTH)
+	if err == nil {
+		var encrypted, decrypted string
+		encrypted, err = encode(ctx, preflightSelfTestValue, password, store.cryptoConfig)
+		if err == nil {
+			decrypted, err = decode(ctx, encrypted, password, store.cryptoConfig)
+		}
+		if err == nil && decrypted != preflightSelfTestValue {
+			err = errors.New("decrypted value does not match the encrypted one")
+		}
+	}
+
+	if err != nil {
+		return PreflightCheck{Name: PREFLIGHT_CHECK_CRYPTO_SELF_TEST, Details: err.Error()}
+	}
+
+	return PreflightCheck{
+		Name:    PREFLIGHT_CHECK_CRYPTO_SELF_TEST,
+		Passed:  true,
+		Details: "encrypt/decrypt roundtrip",
+	}
+}
+
+// v1CiphertextCount returns the number of records with a value in the legacy v1 format,
+// including soft deleted ones. Chunked values are always written in the current format.
+func (store *storeImplementation) v1CiphertextCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := store.vaultDB(ctx).
+		Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", ENCRYPTION_PREFIX_V2+"%").
+		Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", ENCRYPTION_PREFIX_V3+"%").
+		Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", ENCRYPTION_PREFIX_PEPPER+"%").
+		Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", ENCRYPTION_PREFIX_ENVELOPE+"%").
+		Where(COLUMN_VAULT_VALUE+" NOT LIKE ?", CHUNKED_VALUE_PREFIX+"%").
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,86 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_Store_Preflight(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+
+	report, err := store.Preflight(ctx)
+	if err != nil {
+		t.Fatalf("Preflight: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !report.Passed() {
+		t.Fatalf("Expected the preflight to pass received [%+v]", report.Failures())
+	}
+
+	if len(report.Checks) != 4 {
+		t.Fatalf("Expected 4 checks received [%v]", len(report.Checks))
+	}
+
+	if report.VaultVersion != VAULT_VERSION_CURRENT {
+		t.Fatalf("Expected [%v] received [%v]", VAULT_VERSION_CURRENT, report.VaultVersion)
+	}
+
+	if report.V1CiphertextCount != 0 {
+		t.Fatalf("Expected no v1 ciphertexts received [%v]", report.V1CiphertextCount)
+	}
+
+	// A legacy v1 record is counted as pending a format migration
+	record := NewRecord().SetToken("tk_preflight_v1_record_1234").SetValue(xorEncrypt("value", "password"))
+	if err := store.RecordCreate(ctx, record); err != nil {
+		t.Fatalf("RecordCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	report, err = store.Preflight(ctx)
+	if err != nil {
+		t.Fatalf("Preflight: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if report.V1CiphertextCount != 1 {
+		t.Fatalf("Expected 1 v1 ciphertext received [%v]", report.V1CiphertextCount)
+	}
+
+	if !report.Passed() {
+		t.Fatalf("Expected v1 ciphertexts not to fail the preflight received [%+v]", report.Failures())
+	}
+}
+
+func Test_Store_Preflight_NotMigrated(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_preflight",
+		VaultMetaTableName: "vault_preflight_meta",
+		DB:                 db,
+		AutomigrateEnabled: false,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	report, err := store.Preflight(context.Background())
+	if err != nil {
+		t.Fatalf("Preflight: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if report.Passed() {
+		t.Fatal("Expected the preflight to fail without the tables")
+	}
+
+	failures := report.Failures()
+	if len(failures) != 1 || failures[0].Name != PREFLIGHT_CHECK_TABLES {
+		t.Fatalf("Expected the tables check to fail received [%+v]", failures)
+	}
+}
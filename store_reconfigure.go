@@ -0,0 +1,78 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ErrReconfigureInvalid is returned by Reconfigure for negative sizes or durations
+var ErrReconfigureInvalid = errors.New("reconfigure: sizes and durations must not be negative")
+
+// ReconfigureOptions are the settings that can be changed while the store is in use.
+// Fields left at their zero value (nil for the pointers) are unchanged.
+type ReconfigureOptions struct {
+	// DebugEnabled enables or disables the debug output, see EnableDebug
+	DebugEnabled *bool
+	// Logger replaces the logger receiving the logs of the store
+	Logger *slog.Logger
+	// LogLevel changes the minimum level of the logs of the store, see NewStoreOptions.LogLevel
+	LogLevel *slog.Level
+	// ReadThroughCacheSize is the number of decrypted values kept by ReadThrough.
+	// Shrinking the cache evicts the entries above the new size.
+	ReadThroughCacheSize int
+	// ReadThroughCacheTTL is the time a newly cached value is kept by ReadThrough
+	ReadThroughCacheTTL time.Duration
+	// TokenBloomFilterRefreshInterval is how often the token bloom filter loads the tokens
+	// created elsewhere. Ignored if the bloom filter is disabled.
+	TokenBloomFilterRefreshInterval time.Duration
+	// QuotaCheckInterval is how often QuotaCheck runs after writes (0 = disabled)
+	QuotaCheckInterval *time.Duration
+}
+
+// Reconfigure changes runtime settings of the store. It is safe to call while
+// the store is in use, the new settings apply to the operations that follow.
+//
+// Parameters:
+// - opts: The settings to change, zero fields are left unchanged
+//
+// Returns:
+// - err: ErrReconfigureInvalid if a size or duration is negative, nothing is changed then
+func (store *storeImplementation) Reconfigure(opts ReconfigureOptions) error {
+	if opts.ReadThroughCacheSize < 0 || opts.ReadThroughCacheTTL < 0 || opts.TokenBloomFilterRefreshInterval < 0 {
+		return ErrReconfigureInvalid
+	}
+
+	if opts.QuotaCheckInterval != nil && *opts.QuotaCheckInterval < 0 {
+		return ErrReconfigureInvalid
+	}
+
+	if opts.Logger != nil {
+		store.logger.Store(opts.Logger)
+	}
+
+	if opts.LogLevel != nil {
+		store.logLevel.Set(*opts.LogLevel)
+	}
+
+	if opts.DebugEnabled != nil {
+		store.EnableDebug(*opts.DebugEnabled)
+	}
+
+	if opts.ReadThroughCacheSize > 0 || opts.ReadThroughCacheTTL > 0 {
+		store.readThroughCache.resize(opts.ReadThroughCacheSize, opts.ReadThroughCacheTTL)
+	}
+
+	if opts.TokenBloomFilterRefreshInterval > 0 && store.tokenBloomFilter != nil {
+		store.tokenBloomFilter.setRefreshInterval(opts.TokenBloomFilterRefreshInterval)
+	}
+
+	if opts.QuotaCheckInterval != nil {
+		store.quotaCheckInterval.Store(int64(*opts.QuotaCheckInterval))
+	}
+
+	store.logDebug(context.Background(), "store reconfigured")
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package vaultstore
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Store_Reconfigure(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	implementation := store.(*storeImplementation)
+
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	debug := true
+	quotaCheckInterval := time.Minute
+
+	err = store.Reconfigure(ReconfigureOptions{
+		DebugEnabled:         &debug,
+		Logger:               logger,
+		ReadThroughCacheSize: 2,
+		ReadThroughCacheTTL:  time.Hour,
+		QuotaCheckInterval:   &quotaCheckInterval,
+	})
+	if err != nil {
+		t.Fatalf("Reconfigure: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !implementation.debugEnabled.Load() {
+		t.Fatal("Expected debug to be enabled")
+	}
+
+	if !strings.Contains(buffer.String(), "store reconfigured") {
+		t.Fatalf("Expected the debug output to be logged received [%v]", buffer.String())
+	}
+
+	if implementation.readThroughCache.size != 2 || implementation.readThroughCache.ttl != time.Hour {
+		t.Fatalf("Expected size 2 and ttl 1h received [%v] [%v]", implementation.readThroughCache.size, implementation.readThroughCache.ttl)
+	}
+
+	if implementation.quotaCheckInterval.Load() != int64(time.Minute) {
+		t.Fatalf("Expected the quota check interval to be 1m received [%v]", time.Duration(implementation.quotaCheckInterval.Load()))
+	}
+
+	// Zero fields are left unchanged
+	if err := store.Reconfigure(ReconfigureOptions{}); err != nil {
+		t.Fatalf("Reconfigure: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !implementation.debugEnabled.Load() || implementation.readThroughCache.size != 2 || implementation.logger.Load() != logger {
+		t.Fatal("Expected the settings to be unchanged")
+	}
+
+	if err := store.Reconfigure(ReconfigureOptions{ReadThroughCacheSize: -1}); !errors.Is(err, ErrReconfigureInvalid) {
+		t.Fatalf("Expected ErrReconfigureInvalid received [%v]", err)
+	}
+}
+
+func Test_ReadThroughCache_Resize(t *testing.T) {
+	cache := newReadThroughCache(10, time.Minute)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		cache.set(key, "ciphertext", "value")
+	}
+
+	cache.resize(2, 0)
+
+	if len(cache.entries) != 2 {
+		t.Fatalf("Expected 2 entries received [%v]", len(cache.entries))
+	}
+
+	if cache.ttl != time.Minute {
+		t.Fatalf("Expected the ttl to be unchanged received [%v]", cache.ttl)
+	}
+}
+
+func Test_Store_EnableDebug_Concurrent(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.EnableDebug(i%2 == 0)
+			_ = store.Reconfigure(ReconfigureOptions{ReadThroughCacheSize: i + 1})
+		}()
+	}
+	wg.Wait()
+}
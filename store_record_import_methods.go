@@ -0,0 +1,92 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dracory/vaultstore/vaultcrypt"
+)
+
+// ErrCiphertextInvalid is returned when importing a value that is not a well-formed v2 or v3 ciphertext
+var ErrCiphertextInvalid = errors.New("ciphertext is not a valid v2 or v3 payload")
+
+// RecordImportCiphertext stores a value encrypted offline, e.g. on an air-gapped
+// machine, under the token. The store never sees the plaintext nor the password:
+// the ciphertext is checked for its format only and stored as is.
+//
+// The ciphertext must use the v2 (AES-GCM) or v3 (XChaCha20-Poly1305) format, as
+// produced by vaultcrypt.Encode with the parameters of the store's CryptoConfig
+// (CryptoConfig.Params), otherwise it imports fine but cannot be read. Legacy v1 and envelope values are rejected.
+// The value is not decrypted, so the value validation of TokenCreate is skipped.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to store the value under, like TokenCreateCustom
+// - ciphertext: The encrypted value, starting with "v2:" or "v3:", or "pep:" if encrypted with the store pepper
+// - options: The expiration, content type and read limit, the idempotency key is not used
+//
+// Returns:
+// - err: ErrCiphertextInvalid, or an error if something went wrong
+func (store *storeImplementation) RecordImportCiphertext(ctx context.Context, token string, ciphertext string, options ...TokenCreateOptions) (err error) {
+	ctx, span := store.traceStart(ctx, "RecordImportCiphertext")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordImportCiphertext", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := store.ciphertextValidate(ciphertext); err != nil {
+		return err
+	}
+
+	if err := validateTokenCreateOptions(options); err != nil {
+		return err
+	}
+
+	options, err = store.tokenCreateOptionsNormalize(options)
+	if err != nil {
+		return err
+	}
+
+	options, err = store.tokenCreateOptionsWithRetention(options)
+	if err != nil {
+		return err
+	}
+
+	return store.tokenCreateCustomEncoded(ctx, token, options, func() (string, error) {
+		return ciphertext, nil
+	})
+}
+
+// ciphertextValidate checks that the value is a v2 or v3 ciphertext, peppered only if the
+// store has a pepper, long enough to hold the salt, nonce and tag of the store's crypto config
+func (store *storeImplementation) ciphertextValidate(ciphertext string) error {
+	config := store.cryptoConfig
+	if config == nil {
+		config = DefaultCryptoConfig()
+	}
+
+	if strings.HasPrefix(ciphertext, ENCRYPTION_PREFIX_PEPPER) {
+		if len(config.pepper) == 0 {
+			return fmt.Errorf("%w: %s", ErrCiphertextInvalid, ErrPepperMissing.Error())
+		}
+		ciphertext = strings.TrimPrefix(ciphertext, ENCRYPTION_PREFIX_PEPPER)
+	}
+
+	if !strings.HasPrefix(ciphertext, ENCRYPTION_PREFIX_V2) && !strings.HasPrefix(ciphertext, ENCRYPTION_PREFIX_V3) {
+		return ErrCiphertextInvalid
+	}
+
+	if _, err := vaultcrypt.Unpack(ciphertext, config.Params()); err != nil {
+		return fmt.Errorf("%w: %s", ErrCiphertextInvalid, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_Store_RecordImportCiphertext(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// Encrypted offline with the parameters of the store
+	ciphertext, err := encodeV2("offline secret", password, store.(*storeImplementation).cryptoConfig)
+	if err != nil {
+		t.Fatalf("encodeV2: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.RecordImportCiphertext(ctx, "imported_token", ciphertext, TokenCreateOptions{ContentType: CONTENT_TYPE_TEXT})
+	if err != nil {
+		t.Fatalf("RecordImportCiphertext: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, info, err := store.TokenReadWithInfo(ctx, "imported_token", password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "offline secret" || info.ContentType != CONTENT_TYPE_TEXT {
+		t.Fatalf("Expected [offline secret] [%v] received [%v] [%v]", CONTENT_TYPE_TEXT, value, info.ContentType)
+	}
+
+	record, err := store.RecordFindByToken(ctx, "imported_token")
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if record.GetValue() != ciphertext {
+		t.Fatal("Expected the ciphertext to be stored as is")
+	}
+
+	if err := store.RecordImportCiphertext(ctx, "imported_token", ciphertext); err == nil {
+		t.Fatal("Expected an error for an existing token")
+	}
+
+	for _, invalid := range []string{"", "plaintext", ENCRYPTION_PREFIX_V1 + "abc", ENCRYPTION_PREFIX_V2 + "not base64!", ENCRYPTION_PREFIX_V2 + base64Encode([]byte("short"))} {
+		if err := store.RecordImportCiphertext(ctx, "invalid_token", invalid); !errors.Is(err, ErrCiphertextInvalid) {
+			t.Fatalf("Expected [ErrCiphertextInvalid] for [%v] received [%v]", invalid, err)
+		}
+	}
+}
@@ -3,21 +3,60 @@ package vaultstore
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/dromara/carbon/v2"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-func (store *storeImplementation) RecordCount(ctx context.Context, query RecordQueryInterface) (int64, error) {
-	if err := ctx.Err(); err != nil {
-		return -1, err
+// recordCreateBatchSize is the number of rows inserted per statement by RecordCreateMany
+const recordCreateBatchSize = 100
+
+// recordIDMaxLength is the size of the id column
+const recordIDMaxLength = 40
+
+// ErrRecordNotFound is returned when the record to update does not exist
+var ErrRecordNotFound = errors.New("record not found")
+
+// ErrRetentionWindowNegative is returned by RecordsSoftDeletedPurge for a negative window
+var ErrRetentionWindowNegative = errors.New("retention window cannot be negative")
+
+// recordUpdatableColumns are the vault table columns that can be updated by RecordUpdateByToken
+var recordUpdatableColumns = map[string]bool{
+	COLUMN_CREATED_AT:      true,
+	COLUMN_EXPIRES_AT:      true,
+	COLUMN_SOFT_DELETED_AT: true,
+	COLUMN_UPDATED_AT:      true,
+	COLUMN_VAULT_TOKEN:     true,
+	COLUMN_VAULT_VALUE:     true,
+}
+
+// newRecord creates a record for the store, using the configured
+// record implementation and record ID function
+func (store *storeImplementation) newRecord() RecordInterface {
+	var record RecordInterface
+	switch {
+	case store.recordFactory != nil:
+		record = store.recordFactory.NewRecord()
+	case store.fastRecordsEnabled:
+		record = NewFastRecord()
+	default:
+		record = NewRecord()
 	}
 
-	var count int64
+	if store.recordIDFunc != nil {
+		record.SetID(store.recordIDFunc())
+	}
 
-	db := store.gormDB.WithContext(ctx).Table(store.vaultTableName)
+	return record
+}
 
-	// Apply filters from query
+// recordQueryFilter applies the filters of the query to the vault table session
+func (store *storeImplementation) recordQueryFilter(db *gorm.DB, query RecordQueryInterface) *gorm.DB {
 	if query.IsIDSet() && query.GetID() != "" {
 		db = db.Where(COLUMN_ID+" = ?", query.GetID())
 	}
@@ -34,11 +73,51 @@ func (store *storeImplementation) RecordCount(ctx context.Context, query RecordQ
 		db = db.Where(COLUMN_VAULT_TOKEN+" IN ?", query.GetTokenIn())
 	}
 
+	if query.IsCreatedAtBeforeSet() && query.GetCreatedAtBefore() != "" {
+		db = db.Where(COLUMN_CREATED_AT+" < ?", query.GetCreatedAtBefore())
+	}
+
+	if query.GetExpiredExclude() {
+		db = db.Where(COLUMN_EXPIRES_AT+" > ?", carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	extraEquals := query.GetExtraEquals()
+	for _, key := range slices.Sorted(maps.Keys(extraEquals)) {
+		db = db.Where(clause.Eq{Column: clause.Column{Name: key}, Value: extraEquals[key]})
+	}
+
+	metaIn := query.GetMetaIn()
+	for _, key := range slices.Sorted(maps.Keys(metaIn)) {
+		db = db.Where(COLUMN_ID+" IN (?)", store.recordIDsTaggedQuery(db, key, metaIn[key]))
+	}
+
 	// Handle soft delete filtering
-	if !query.IsSoftDeletedIncludeSet() {
+	if query.GetSoftDeletedOnly() {
+		db = db.Where(COLUMN_SOFT_DELETED_AT+" <= ?", carbon.Now(carbon.UTC).ToDateTimeString())
+	} else if !query.IsSoftDeletedIncludeSet() {
 		db = db.Where(COLUMN_SOFT_DELETED_AT+" > ?", carbon.Now(carbon.UTC).ToDateTimeString())
 	}
 
+	return db
+}
+
+func (store *storeImplementation) RecordCount(ctx context.Context, query RecordQueryInterface) (int64, error) {
+	ctx, span := store.traceStart(ctx, "RecordCount")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordCount", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
+	var count int64
+
+	db := store.recordQueryFilter(store.vaultDB(ctx), query)
+
 	err := db.Count(&count).Error
 	if err != nil {
 		return -1, err
@@ -48,6 +127,14 @@ func (store *storeImplementation) RecordCount(ctx context.Context, query RecordQ
 }
 
 func (store *storeImplementation) RecordCreate(ctx context.Context, record RecordInterface) error {
+	ctx, span := store.traceStart(ctx, "RecordCreate")
+	defer span.End()
+
+	if err := store.operationAllowRecords(ctx, "RecordCreate", record); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -57,20 +144,136 @@ func (store *storeImplementation) RecordCreate(ctx context.Context, record Recor
 		return errors.New("record token cannot be empty")
 	}
 
+	if len(record.GetID()) > recordIDMaxLength {
+		return errors.New("record id is too long")
+	}
+
 	record.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 	record.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 
-	gormRecord := fromRecordInterface(record)
+	gormRecord := store.gormRecordFromRecord(record)
+	if err := normalizeRecordTimestamps(gormRecord); err != nil {
+		return err
+	}
+
+	// Large values are moved to the chunk table, the record keeps a marker
+	storedValue, err := store.valueChunksWrite(ctx, gormRecord.ID, gormRecord.Value)
+	if err != nil {
+		return err
+	}
+	gormRecord.Value = storedValue
+
+	err = store.vaultDB(ctx).Create(store.recordInsertValue(ctx, gormRecord)).Error
+	if err != nil {
+		if isChunkedValue(storedValue) {
+			_ = store.valueChunksDelete(ctx, []string{gormRecord.ID})
+		}
+		return err
+	}
 
-	err := store.gormDB.WithContext(ctx).Table(store.vaultTableName).Create(gormRecord).Error
+	err = store.recordTimestampsLoad(ctx, []RecordInterface{record})
 	if err != nil {
 		return err
 	}
 
+	store.tokenBloomFilterAdd(ctx, record.GetToken())
+	store.quotaCheckAfterWrite(ctx)
+
+	return nil
+}
+
+// RecordCreateMany creates the records using multi-row INSERT statements
+// of up to recordCreateBatchSize rows each
+//
+// Parameters:
+// - ctx: The context
+// - records: The records to create
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) RecordCreateMany(ctx context.Context, records []RecordInterface) error {
+	ctx, span := store.traceStart(ctx, "RecordCreateMany", traceRecordCount(len(records)))
+	defer span.End()
+
+	if err := store.operationAllowRecords(ctx, "RecordCreateMany", records...); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	gormRecords := make([]*gormVaultRecord, len(records))
+	recordIDs := make([]string, len(records))
+
+	for i, record := range records {
+		if record == nil {
+			return errors.New("record is nil")
+		}
+
+		// Validate that token is not empty to prevent unique index violations
+		if record.GetToken() == "" {
+			return errors.New("record token cannot be empty")
+		}
+
+		if len(record.GetID()) > recordIDMaxLength {
+			return errors.New("record id is too long")
+		}
+
+		record.SetCreatedAt(now)
+		record.SetUpdatedAt(now)
+
+		gormRecords[i] = store.gormRecordFromRecord(record)
+		if err := normalizeRecordTimestamps(gormRecords[i]); err != nil {
+			return err
+		}
+		recordIDs[i] = record.GetID()
+	}
+
+	// Large values are moved to the chunk table, the records keep a marker
+	for _, gormRecord := range gormRecords {
+		storedValue, err := store.valueChunksWrite(ctx, gormRecord.ID, gormRecord.Value)
+		if err != nil {
+			_ = store.valueChunksDelete(ctx, recordIDs)
+			return err
+		}
+		gormRecord.Value = storedValue
+	}
+
+	err := store.vaultDB(ctx).CreateInBatches(store.recordInsertValues(ctx, gormRecords), recordCreateBatchSize).Error
+	if err != nil {
+		_ = store.valueChunksDelete(ctx, recordIDs)
+		return err
+	}
+
+	err = store.recordTimestampsLoad(ctx, records)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		store.tokenBloomFilterAdd(ctx, record.GetToken())
+	}
+	store.quotaCheckAfterWrite(ctx)
+
 	return nil
 }
 
 func (store *storeImplementation) RecordDeleteByID(ctx context.Context, recordID string) error {
+	ctx, span := store.traceStart(ctx, "RecordDeleteByID")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordDeleteByID", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -79,7 +282,7 @@ func (store *storeImplementation) RecordDeleteByID(ctx context.Context, recordID
 		return errors.New("record id is empty")
 	}
 
-	err := store.gormDB.WithContext(ctx).Table(store.vaultTableName).
+	err := store.vaultDB(ctx).
 		Where(COLUMN_ID+" = ?", recordID).
 		Delete(&gormVaultRecord{}).Error
 
@@ -87,10 +290,23 @@ func (store *storeImplementation) RecordDeleteByID(ctx context.Context, recordID
 		return err
 	}
 
-	return nil
+	err = store.valueChunksDelete(ctx, []string{recordID})
+	if err != nil {
+		return err
+	}
+
+	return store.recordMetaDelete(ctx, []string{recordID})
 }
 
 func (store *storeImplementation) RecordDeleteByToken(ctx context.Context, token string) error {
+	ctx, span := store.traceStart(ctx, "RecordDeleteByToken")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordDeleteByToken", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -99,7 +315,17 @@ func (store *storeImplementation) RecordDeleteByToken(ctx context.Context, token
 		return errors.New("token is empty")
 	}
 
-	err := store.gormDB.WithContext(ctx).Table(store.vaultTableName).
+	// Collect the record IDs (including soft deleted) to remove their metadata too
+	var recordIDs []string
+	err := store.vaultDB(ctx).
+		Where(COLUMN_VAULT_TOKEN+" = ?", token).
+		Pluck(COLUMN_ID, &recordIDs).Error
+
+	if err != nil {
+		return err
+	}
+
+	err = store.vaultDB(ctx).
 		Where(COLUMN_VAULT_TOKEN+" = ?", token).
 		Delete(&gormVaultRecord{}).Error
 
@@ -107,11 +333,24 @@ func (store *storeImplementation) RecordDeleteByToken(ctx context.Context, token
 		return err
 	}
 
-	return nil
+	err = store.valueChunksDelete(ctx, recordIDs)
+	if err != nil {
+		return err
+	}
+
+	return store.recordMetaDelete(ctx, recordIDs)
 }
 
 // RecordFindByID finds an entry by ID
 func (store *storeImplementation) RecordFindByID(ctx context.Context, id string) (RecordInterface, error) {
+	ctx, span := store.traceStart(ctx, "RecordFindByID")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordFindByID", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -146,6 +385,14 @@ func (store *storeImplementation) RecordFindByID(ctx context.Context, id string)
 // - record: The record found
 // - err: An error if something went wrong
 func (store *storeImplementation) RecordFindByToken(ctx context.Context, token string) (RecordInterface, error) {
+	ctx, span := store.traceStart(ctx, "RecordFindByToken")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordFindByToken", token); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -155,7 +402,7 @@ func (store *storeImplementation) RecordFindByToken(ctx context.Context, token s
 	}
 
 	// Use the query interface to properly handle soft deletion
-	records, err := store.RecordList(ctx, RecordQuery().SetToken(token).SetLimit(1))
+	records, err := store.RecordList(ctx, RecordQueryByToken(token).SetLimit(1))
 	if err != nil {
 		return nil, err
 	}
@@ -167,19 +414,10 @@ func (store *storeImplementation) RecordFindByToken(ctx context.Context, token s
 	return records[0], nil
 }
 
-func (store *storeImplementation) RecordList(ctx context.Context, query RecordQueryInterface) ([]RecordInterface, error) {
-	if err := ctx.Err(); err != nil {
-		return []RecordInterface{}, err
-	}
-
-	err := query.Validate()
-	if err != nil {
-		return []RecordInterface{}, err
-	}
-
-	var gormRecords []gormVaultRecord
-
-	db := store.gormDB.WithContext(ctx).Table(store.vaultTableName)
+// recordListDB returns the vault table session for listing the records
+// matching the query, with columns, filters, ordering and paging applied
+func (store *storeImplementation) recordListDB(ctx context.Context, query RecordQueryInterface) *gorm.DB {
+	db := store.vaultDB(ctx)
 
 	// Select specific columns if set
 	if query.IsColumnsSet() && len(query.GetColumns()) > 0 {
@@ -187,26 +425,7 @@ func (store *storeImplementation) RecordList(ctx context.Context, query RecordQu
 	}
 
 	// Apply filters
-	if query.IsIDSet() && query.GetID() != "" {
-		db = db.Where(COLUMN_ID+" = ?", query.GetID())
-	}
-
-	if query.IsTokenSet() && query.GetToken() != "" {
-		db = db.Where(COLUMN_VAULT_TOKEN+" = ?", query.GetToken())
-	}
-
-	if query.IsIDInSet() && len(query.GetIDIn()) > 0 {
-		db = db.Where(COLUMN_ID+" IN ?", query.GetIDIn())
-	}
-
-	if query.IsTokenInSet() && len(query.GetTokenIn()) > 0 {
-		db = db.Where(COLUMN_VAULT_TOKEN+" IN ?", query.GetTokenIn())
-	}
-
-	// Handle soft delete filtering
-	if !query.IsSoftDeletedIncludeSet() {
-		db = db.Where(COLUMN_SOFT_DELETED_AT+" > ?", carbon.Now(carbon.UTC).ToDateTimeString())
-	}
+	db = store.recordQueryFilter(db, query)
 
 	// Apply ordering
 	if query.IsOrderBySet() && query.GetOrderBy() != "" {
@@ -230,21 +449,146 @@ func (store *storeImplementation) RecordList(ctx context.Context, query RecordQu
 		db = db.Offset(query.GetOffset())
 	}
 
-	err = db.Find(&gormRecords).Error
+	return db
+}
+
+func (store *storeImplementation) RecordList(ctx context.Context, query RecordQueryInterface) ([]RecordInterface, error) {
+	ctx, span := store.traceStart(ctx, "RecordList")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordList", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return []RecordInterface{}, err
+	}
+
+	err := query.Validate()
+	if err != nil {
+		return []RecordInterface{}, err
+	}
+
+	db := store.recordListDB(ctx, query)
+
+	gormRecords, err := store.recordsFind(db)
+	if err != nil {
+		return []RecordInterface{}, err
+	}
+
+	err = store.valueChunksResolve(ctx, gormRecords)
 	if err != nil {
 		return []RecordInterface{}, err
 	}
 
 	list := make([]RecordInterface, len(gormRecords))
-	for i, gr := range gormRecords {
-		list[i] = gr.toRecordInterface()
+	for i := range gormRecords {
+		list[i] = store.recordFromGorm(&gormRecords[i])
 	}
 
 	return list, nil
 }
 
+// RecordListStream calls fn for each record matching the query, scanning the
+// rows one at a time so memory stays flat for very large result sets
+//
+// The scan stops at the first error returned by fn, or as soon as the context
+// is cancelled. The result set stays open while fn runs, so on databases
+// limited to a single connection fn must not query the store.
+//
+// Parameters:
+// - ctx: The context
+// - query: The query to filter the records
+// - fn: The function called for each record
+//
+// Returns:
+// - err: The error returned by fn, the context error or a database error
+func (store *storeImplementation) RecordListStream(ctx context.Context, query RecordQueryInterface, fn func(RecordInterface) error) error {
+	ctx, span := store.traceStart(ctx, "RecordListStream")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordListStream", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if fn == nil {
+		return errors.New("stream function is nil")
+	}
+
+	err := query.Validate()
+	if err != nil {
+		return err
+	}
+
+	db := store.recordListDB(ctx, query)
+
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var gormRecord gormVaultRecord
+		if len(store.recordExtraColumns) == 0 {
+			if err := db.ScanRows(rows, &gormRecord); err != nil {
+				return err
+			}
+		} else {
+			row := map[string]interface{}{}
+			if err := db.ScanRows(rows, &row); err != nil {
+				return err
+			}
+			gormRecord = store.gormRecordFromRow(row)
+		}
+
+		if isChunkedValue(gormRecord.Value) {
+			gormRecords := []gormVaultRecord{gormRecord}
+			if err := store.valueChunksResolve(ctx, gormRecords); err != nil {
+				return err
+			}
+			gormRecord = gormRecords[0]
+		}
+
+		if err := fn(store.recordFromGorm(&gormRecord)); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// recordFromGorm converts the GORM model to the record type configured for the store
+func (store *storeImplementation) recordFromGorm(gormRecord *gormVaultRecord) RecordInterface {
+	if store.recordFactory != nil {
+		return gormRecord.toFactoryRecord(store.recordFactory)
+	}
+	if store.fastRecordsEnabled {
+		return gormRecord.toFastRecord()
+	}
+	return gormRecord.toRecordInterface()
+}
+
 // RecordSoftDelete soft deletes a record by setting the soft_deleted_at column to the current time
 func (store *storeImplementation) RecordSoftDelete(ctx context.Context, record RecordInterface) error {
+	ctx, span := store.traceStart(ctx, "RecordSoftDelete")
+	defer span.End()
+
+	if err := store.operationAllowRecords(ctx, "RecordSoftDelete", record); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -261,6 +605,14 @@ func (store *storeImplementation) RecordSoftDelete(ctx context.Context, record R
 
 // RecordSoftDeleteByID soft deletes a record by ID by setting the soft_deleted_at column to the current time
 func (store *storeImplementation) RecordSoftDeleteByID(ctx context.Context, recordID string) error {
+	ctx, span := store.traceStart(ctx, "RecordSoftDeleteByID")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordSoftDeleteByID", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -284,6 +636,14 @@ func (store *storeImplementation) RecordSoftDeleteByID(ctx context.Context, reco
 
 // RecordSoftDeleteByToken soft deletes a record by token by setting the soft_deleted_at column to the current time
 func (store *storeImplementation) RecordSoftDeleteByToken(ctx context.Context, token string) error {
+	ctx, span := store.traceStart(ctx, "RecordSoftDeleteByToken")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordSoftDeleteByToken", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -292,20 +652,119 @@ func (store *storeImplementation) RecordSoftDeleteByToken(ctx context.Context, t
 		return errors.New("token is empty")
 	}
 
-	// Find the record first
-	record, err := store.RecordFindByToken(ctx, token)
-	if err != nil {
+	return store.RecordUpdateByToken(ctx, token, map[string]string{
+		COLUMN_SOFT_DELETED_AT: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+	})
+}
+
+// RecordRestore undoes the soft delete of a record by resetting its soft_deleted_at
+// column. Restoring a record that is not soft deleted does nothing.
+//
+// Parameters:
+// - ctx: The context
+// - record: The record to restore, e.g. found with RecordQuery().SetSoftDeletedOnly(true)
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) RecordRestore(ctx context.Context, record RecordInterface) error {
+	ctx, span := store.traceStart(ctx, "RecordRestore")
+	defer span.End()
+
+	if err := store.operationAllowRecords(ctx, "RecordRestore", record); err != nil {
 		return err
 	}
+	ctx = store.operationAllowedContext(ctx)
 
 	if record == nil {
-		return errors.New("record not found")
+		return errors.New("record is nil")
 	}
 
-	return store.RecordSoftDelete(ctx, record)
+	if record.GetSoftDeletedAt() == MAX_DATETIME {
+		return nil
+	}
+
+	record.SetSoftDeletedAt(MAX_DATETIME)
+
+	return store.RecordUpdate(ctx, record)
+}
+
+// RecordRestoreByID undoes the soft delete of a record by its ID, see RecordRestore
+//
+// Parameters:
+// - ctx: The context
+// - recordID: The ID of the record to restore
+//
+// Returns:
+// - err: ErrRecordNotFound if the record does not exist, e.g. was permanently deleted,
+// or an error if something went wrong
+func (store *storeImplementation) RecordRestoreByID(ctx context.Context, recordID string) error {
+	ctx, span := store.traceStart(ctx, "RecordRestoreByID")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordRestoreByID", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if recordID == "" {
+		return errors.New("record id is empty")
+	}
+
+	records, err := store.RecordList(ctx, RecordQuery().
+		SetID(recordID).
+		SetSoftDeletedInclude(true).
+		SetLimit(1))
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		return ErrRecordNotFound
+	}
+
+	return store.RecordRestore(ctx, records[0])
+}
+
+// RecordsSoftDeletedPurge permanently deletes the records soft deleted more than olderThan
+// ago, with their metadata, enforcing a retention window on the soft deleted records.
+// The Scheduler runs it daily with SchedulerOptions.RetentionPeriod.
+//
+// Parameters:
+// - ctx: The context
+// - olderThan: The retention window, 0 purges all the soft deleted records
+//
+// Returns:
+// - count: The number of purged records
+// - err: ErrRetentionWindowNegative, or an error if something went wrong
+func (store *storeImplementation) RecordsSoftDeletedPurge(ctx context.Context, olderThan time.Duration) (count int64, err error) {
+	ctx, span := store.traceStart(ctx, "RecordsSoftDeletedPurge")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordsSoftDeletedPurge", ""); err != nil {
+		return 0, err
+	}
+	ctx = withNamespacesAll(store.operationAllowedContext(ctx))
+
+	if olderThan < 0 {
+		return 0, ErrRetentionWindowNegative
+	}
+
+	cutoff := carbon.CreateFromStdTime(time.Now().Add(-olderThan), carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	return store.recordsDeleteBatched(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where(COLUMN_SOFT_DELETED_AT+" <= ?", cutoff)
+	})
 }
 
 func (store *storeImplementation) RecordUpdate(ctx context.Context, record RecordInterface) error {
+	ctx, span := store.traceStart(ctx, "RecordUpdate")
+	defer span.End()
+
+	if err := store.operationAllowRecords(ctx, "RecordUpdate", record); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -334,7 +793,23 @@ func (store *storeImplementation) RecordUpdate(ctx context.Context, record Recor
 		updates[key] = value
 	}
 
-	err := store.gormDB.WithContext(ctx).Table(store.vaultTableName).
+	updates[COLUMN_UPDATED_AT] = store.timestampValue(record.GetUpdatedAt())
+
+	if err := normalizeTimestampUpdates(updates); err != nil {
+		return err
+	}
+
+	// Large values are moved to the chunk table, the record keeps a marker
+	value, valueChanged := dataChanged[COLUMN_VAULT_VALUE]
+	if valueChanged {
+		storedValue, err := store.valueChunksWrite(ctx, record.GetID(), value)
+		if err != nil {
+			return err
+		}
+		updates[COLUMN_VAULT_VALUE] = storedValue
+	}
+
+	err := store.vaultDB(ctx).
 		Where(COLUMN_ID+" = ?", record.GetID()).
 		Updates(updates).Error
 
@@ -342,5 +817,104 @@ func (store *storeImplementation) RecordUpdate(ctx context.Context, record Recor
 		return err
 	}
 
+	if valueChanged {
+		// Remove the chunks of the previous value
+		_, err = store.valueChunksCollect(ctx, record.GetID(), updates[COLUMN_VAULT_VALUE].(string))
+		if err != nil {
+			return err
+		}
+	}
+
+	if token, tokenChanged := dataChanged[COLUMN_VAULT_TOKEN]; tokenChanged {
+		store.tokenBloomFilterAdd(ctx, token)
+		if err := store.tokenBloomFilterBackfilled(ctx); err != nil {
+			return err
+		}
+	}
+
+	return store.recordTimestampsLoad(ctx, []RecordInterface{record})
+}
+
+// RecordUpdateByToken updates the columns of the non soft deleted record with the
+// given token using a single UPDATE statement, without fetching the record first
+//
+// The updated_at column is set automatically.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token of the record to update
+// - updates: The new column values, keyed by column name
+//
+// Returns:
+// - err: ErrRecordNotFound if no record has the token, or an error if something went wrong
+func (store *storeImplementation) RecordUpdateByToken(ctx context.Context, token string, updates map[string]string) error {
+	ctx, span := store.traceStart(ctx, "RecordUpdateByToken")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RecordUpdateByToken", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if token == "" {
+		return errors.New("token is empty")
+	}
+
+	columns := make(map[string]interface{}, len(updates)+1)
+	for column, value := range updates {
+		if !recordUpdatableColumns[column] && !slices.Contains(store.recordExtraColumns, column) {
+			return fmt.Errorf("column %q cannot be updated", column)
+		}
+		columns[column] = value
+	}
+
+	// Chunked values need the record ID, which is not known without fetching the record
+	if _, ok := columns[COLUMN_VAULT_VALUE]; ok && store.isValueChunkingEnabled() {
+		return errors.New("vault_value cannot be updated by token when value chunking is enabled, use RecordUpdate")
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	if _, ok := columns[COLUMN_UPDATED_AT]; !ok {
+		columns[COLUMN_UPDATED_AT] = store.timestampValue(now)
+	}
+
+	if err := normalizeTimestampUpdates(columns); err != nil {
+		return err
+	}
+
+	result := store.vaultDB(ctx).
+		Where(COLUMN_VAULT_TOKEN+" = ?", token).
+		Where(COLUMN_SOFT_DELETED_AT+" > ?", now).
+		Updates(columns)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if newToken, tokenChanged := updates[COLUMN_VAULT_TOKEN]; tokenChanged && result.RowsAffected > 0 {
+		store.tokenBloomFilterAdd(ctx, newToken)
+		if err := store.tokenBloomFilterBackfilled(ctx); err != nil {
+			return err
+		}
+	}
+
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// Some drivers (e.g. MySQL) report 0 affected rows when the values did not change
+	count, err := store.RecordCount(ctx, RecordQueryByToken(token))
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrRecordNotFound
+	}
+
 	return nil
 }
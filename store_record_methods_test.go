@@ -2,7 +2,13 @@ package vaultstore
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/dromara/carbon/v2"
 )
 
 func Test_Store_RecordCount(t *testing.T) {
@@ -72,6 +78,40 @@ func Test_Store_RecordCreate(t *testing.T) {
 	}
 }
 
+func Test_Store_RecordCreateMany(t *testing.T) {
+	store, err := initStore()
+
+	if err != nil {
+		t.Fatalf("Test_Store_RecordCreateMany: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	records := []RecordInterface{}
+	for i := 0; i < 250; i++ {
+		records = append(records, NewRecord().SetToken(fmt.Sprintf("test_token_many_%d", i)).SetValue("test_value"))
+	}
+
+	err = store.RecordCreateMany(ctx, records)
+	if err != nil {
+		t.Fatalf("Test_Store_RecordCreateMany Failure: [%v]", err.Error())
+	}
+
+	count, err := store.RecordCount(ctx, RecordQuery())
+	if err != nil {
+		t.Fatalf("Test_Store_RecordCreateMany: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 250 {
+		t.Fatalf("Test_Store_RecordCreateMany: Expected [count] to be 250 received [%v]", count)
+	}
+
+	// Records with empty tokens are rejected before inserting
+	err = store.RecordCreateMany(ctx, []RecordInterface{NewRecord().SetValue("test_value")})
+	if err == nil {
+		t.Fatal("Test_Store_RecordCreateMany: Expected error for empty token")
+	}
+}
+
 func Test_Store_RecordFindByID(t *testing.T) {
 	store, err := initStore()
 	if err != nil {
@@ -316,6 +356,54 @@ func Test_Store_RecordUpdate(t *testing.T) {
 	}
 }
 
+func Test_Store_RecordUpdateByToken(t *testing.T) {
+	store, err := initStore()
+
+	if err != nil {
+		t.Fatalf("Test_Store_RecordUpdateByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	record := NewRecord().SetToken("test_token_update").SetValue("test_value")
+
+	err = store.RecordCreate(ctx, record)
+	if err != nil {
+		t.Fatalf("Test_Store_RecordUpdateByToken Failure: [%v]", err.Error())
+	}
+
+	err = store.RecordUpdateByToken(ctx, "test_token_update", map[string]string{
+		COLUMN_EXPIRES_AT: "2099-01-01 00:00:00",
+	})
+	if err != nil {
+		t.Fatalf("Test_Store_RecordUpdateByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	updated, err := store.RecordFindByToken(ctx, "test_token_update")
+	if err != nil {
+		t.Fatalf("Test_Store_RecordUpdateByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if updated == nil {
+		t.Fatal("Test_Store_RecordUpdateByToken: Expected record to be found")
+	}
+
+	if !strings.HasPrefix(updated.GetExpiresAt(), "2099-01-01") {
+		t.Fatalf("Test_Store_RecordUpdateByToken: Expected [expires_at] to be updated received [%v]", updated.GetExpiresAt())
+	}
+
+	// Unknown columns are rejected
+	err = store.RecordUpdateByToken(ctx, "test_token_update", map[string]string{COLUMN_ID: "other"})
+	if err == nil {
+		t.Fatal("Test_Store_RecordUpdateByToken: Expected error for non updatable column")
+	}
+
+	// Missing tokens return ErrRecordNotFound
+	err = store.RecordUpdateByToken(ctx, "missing_token", map[string]string{COLUMN_EXPIRES_AT: "2099-01-01 00:00:00"})
+	if !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("Test_Store_RecordUpdateByToken: Expected [ErrRecordNotFound] received [%v]", err)
+	}
+}
+
 func Test_Store_RecordDeleteByID(t *testing.T) {
 	store, err := initStore()
 	if err != nil {
@@ -663,3 +751,165 @@ func Test_Store_RecordSoftDeleteByToken(t *testing.T) {
 		t.Fatal("Test_Store_RecordSoftDeleteByToken: Expected error for non-existent token but got nil")
 	}
 }
+
+func Test_Store_RecordIDFunc(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	sequence := 0
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_record_id_func",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		RecordIDFunc: func() string {
+			sequence++
+			return fmt.Sprintf("custom-id-%d", sequence)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	token, err := store.TokenCreate(ctx, "test_value", "test_password_that_is_long_enough_for_security_32chars", 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if record == nil || record.GetID() != "custom-id-1" {
+		t.Fatalf("Expected record ID [custom-id-1] received [%v]", record)
+	}
+
+	// IDs longer than the id column are rejected
+	err = store.RecordCreate(ctx, NewRecord().SetID(strings.Repeat("x", 41)).SetToken("too_long_id"))
+	if err == nil {
+		t.Fatal("Expected error for too long record ID")
+	}
+}
+
+func Test_Store_RecordListStream(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		record := NewRecord().SetToken(fmt.Sprintf("stream_token_%d", i)).SetValue(fmt.Sprintf("stream_value_%d", i))
+		if err := store.RecordCreate(ctx, record); err != nil {
+			t.Fatalf("RecordCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	tokens := []string{}
+	err = store.RecordListStream(ctx, RecordQuery().SetOrderBy(COLUMN_VAULT_TOKEN).SetSortOrder(ASC), func(record RecordInterface) error {
+		tokens = append(tokens, record.GetToken())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RecordListStream: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(tokens) != 5 || tokens[0] != "stream_token_0" || tokens[4] != "stream_token_4" {
+		t.Fatalf("Expected 5 ordered tokens received %v", tokens)
+	}
+
+	// An error from the function stops the scan
+	errStop := errors.New("stop")
+	visited := 0
+	err = store.RecordListStream(ctx, RecordQuery(), func(record RecordInterface) error {
+		visited++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("Expected [errStop] received [%v]", err)
+	}
+	if visited != 1 {
+		t.Fatalf("Expected 1 visited record received %d", visited)
+	}
+
+	// Cancelling the context stops the scan
+	cancelCtx, cancel := context.WithCancel(ctx)
+	visited = 0
+	err = store.RecordListStream(cancelCtx, RecordQuery(), func(record RecordInterface) error {
+		visited++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected [context.Canceled] received [%v]", err)
+	}
+	if visited != 1 {
+		t.Fatalf("Expected 1 visited record received %d", visited)
+	}
+}
+
+func Test_Store_RecordsSoftDeletedPurge(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	tokens := []string{}
+	for _, value := range []string{"old", "recent", "kept"} {
+		token, err := store.TokenCreate(ctx, value, password, 20)
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := store.TokenSoftDelete(ctx, tokens[1]); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The first token is soft deleted two days ago
+	err = store.RecordUpdateByToken(ctx, tokens[0], map[string]string{
+		COLUMN_SOFT_DELETED_AT: carbon.Now(carbon.UTC).SubDays(2).ToDateTimeString(carbon.UTC),
+	})
+	if err != nil {
+		t.Fatalf("RecordUpdateByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.RecordsSoftDeletedPurge(ctx, -time.Hour); !errors.Is(err, ErrRetentionWindowNegative) {
+		t.Fatalf("RecordsSoftDeletedPurge: Expected [ErrRetentionWindowNegative] received [%v]", err)
+	}
+
+	count, err := store.RecordsSoftDeletedPurge(ctx, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("RecordsSoftDeletedPurge: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("RecordsSoftDeletedPurge: Expected [1] received [%v]", count)
+	}
+
+	count, err = store.RecordsSoftDeletedPurge(ctx, 0)
+	if err != nil {
+		t.Fatalf("RecordsSoftDeletedPurge: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("RecordsSoftDeletedPurge: Expected [1] received [%v]", count)
+	}
+
+	exists, err := store.TokenExists(ctx, tokens[2])
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !exists {
+		t.Fatal("TokenExists: Expected the token not soft deleted to be kept")
+	}
+}
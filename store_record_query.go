@@ -2,7 +2,11 @@ package vaultstore
 
 import (
 	"errors"
+	"maps"
+	"slices"
 	"strings"
+
+	"github.com/dromara/carbon/v2"
 )
 
 // ============================================================================//
@@ -16,6 +20,17 @@ func RecordQuery() RecordQueryInterface {
 	}
 }
 
+// RecordQueryByToken creates a record query selecting the record of the token
+func RecordQueryByToken(token string) RecordQueryInterface {
+	return RecordQuery().SetToken(token)
+}
+
+// RecordQueryActive creates a record query selecting the records
+// that are neither soft deleted nor expired
+func RecordQueryActive() RecordQueryInterface {
+	return RecordQuery().SetExpiredExclude(true)
+}
+
 // ============================================================================//
 // TYPE recordQueryImpl
 // ============================================================================//
@@ -46,6 +61,9 @@ func (q *recordQueryImpl) Validate() error {
 	if q.IsTokenInSet() && len(q.GetTokenIn()) == 0 {
 		return errors.New("tokenIn cannot be empty")
 	}
+	if q.IsCreatedAtBeforeSet() && carbon.Parse(q.GetCreatedAtBefore(), carbon.UTC).IsZero() {
+		return errors.New("createdAtBefore must be a valid datetime")
+	}
 	if q.IsLimitSet() && q.GetLimit() < 0 {
 		return errors.New("limit cannot be negative")
 	}
@@ -56,6 +74,21 @@ func (q *recordQueryImpl) Validate() error {
 		return errors.New("sortOrder must be 'asc' or 'desc'")
 	}
 
+	for key := range q.GetExtraEquals() {
+		if err := validateExtraColumnName(key); err != nil {
+			return err
+		}
+	}
+
+	for key, values := range q.GetMetaIn() {
+		if err := validateTokenMetaKey(key); err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			return errors.New("metaIn values cannot be empty")
+		}
+	}
+
 	if q.IsCountOnlySet() && (q.IsLimitSet() || q.IsOffsetSet()) {
 		return errors.New("countOnly cannot be used with limit or offset")
 	}
@@ -238,7 +271,121 @@ func (q *recordQueryImpl) SetLimit(limit int) RecordQueryInterface {
 	return q
 }
 
+// Clone returns a copy of the query, changing the copy leaves the query unchanged
+func (q *recordQueryImpl) Clone() RecordQueryInterface {
+	clone := &recordQueryImpl{
+		properties: make(map[string]interface{}, len(q.properties)),
+	}
+
+	for key, value := range q.properties {
+		switch typed := value.(type) {
+		case []string:
+			clone.properties[key] = slices.Clone(typed)
+		case map[string]string:
+			clone.properties[key] = maps.Clone(typed)
+		case map[string][]string:
+			cloned := make(map[string][]string, len(typed))
+			for metaKey, values := range typed {
+				cloned[metaKey] = slices.Clone(values)
+			}
+			clone.properties[key] = cloned
+		default:
+			clone.properties[key] = value
+		}
+	}
+
+	return clone
+}
+
 func (q *recordQueryImpl) hasProperty(key string) bool {
 	_, ok := q.properties[key]
 	return ok
 }
+
+func (q *recordQueryImpl) IsCreatedAtBeforeSet() bool {
+	return q.hasProperty("createdAtBefore")
+}
+
+func (q *recordQueryImpl) GetCreatedAtBefore() string {
+	if q.IsCreatedAtBeforeSet() {
+		return q.properties["createdAtBefore"].(string)
+	}
+	return ""
+}
+
+func (q *recordQueryImpl) SetCreatedAtBefore(createdAtBefore string) RecordQueryInterface {
+	q.properties["createdAtBefore"] = createdAtBefore
+	return q
+}
+
+func (q *recordQueryImpl) IsSoftDeletedOnlySet() bool {
+	return q.hasProperty("softDeletedOnly")
+}
+
+func (q *recordQueryImpl) GetSoftDeletedOnly() bool {
+	if q.IsSoftDeletedOnlySet() {
+		return q.properties["softDeletedOnly"].(bool)
+	}
+	return false
+}
+
+func (q *recordQueryImpl) SetSoftDeletedOnly(softDeletedOnly bool) RecordQueryInterface {
+	q.properties["softDeletedOnly"] = softDeletedOnly
+	return q
+}
+
+func (q *recordQueryImpl) IsExtraEqualsSet() bool {
+	return q.hasProperty("extraEquals")
+}
+
+func (q *recordQueryImpl) GetExtraEquals() map[string]string {
+	if q.IsExtraEqualsSet() {
+		return q.properties["extraEquals"].(map[string]string)
+	}
+	return map[string]string{}
+}
+
+func (q *recordQueryImpl) SetExtraEquals(key string, value string) RecordQueryInterface {
+	extraEquals := q.GetExtraEquals()
+	extraEquals[key] = value
+	q.properties["extraEquals"] = extraEquals
+	return q
+}
+
+func (q *recordQueryImpl) IsMetaInSet() bool {
+	return q.hasProperty("metaIn")
+}
+
+func (q *recordQueryImpl) GetMetaIn() map[string][]string {
+	if q.IsMetaInSet() {
+		return q.properties["metaIn"].(map[string][]string)
+	}
+	return map[string][]string{}
+}
+
+func (q *recordQueryImpl) SetMetaEquals(key string, value string) RecordQueryInterface {
+	return q.SetMetaIn(key, []string{value})
+}
+
+func (q *recordQueryImpl) SetMetaIn(key string, values []string) RecordQueryInterface {
+	metaIn := q.GetMetaIn()
+	metaIn[key] = values
+	q.properties["metaIn"] = metaIn
+	return q
+}
+
+func (q *recordQueryImpl) IsExpiredExcludeSet() bool {
+	return q.hasProperty("expiredExclude")
+}
+
+func (q *recordQueryImpl) GetExpiredExclude() bool {
+	if q.IsExpiredExcludeSet() {
+		return q.properties["expiredExclude"].(bool)
+	}
+	return false
+}
+
+func (q *recordQueryImpl) SetExpiredExclude(expiredExclude bool) RecordQueryInterface {
+	q.properties["expiredExclude"] = expiredExclude
+	return q
+}
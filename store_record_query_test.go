@@ -0,0 +1,55 @@
+package vaultstore
+
+import (
+	"testing"
+)
+
+func Test_RecordQuery_Clone(t *testing.T) {
+	query := RecordQuery().
+		SetTokenIn([]string{"tk_1", "tk_2"}).
+		SetExtraEquals("owner_id", "user_1").
+		SetMetaIn("environment", []string{"staging", "production"}).
+		SetLimit(10)
+
+	clone := query.Clone()
+	clone.GetTokenIn()[0] = "tk_changed"
+	clone.SetExtraEquals("owner_id", "user_2")
+	clone.GetMetaIn()["environment"][0] = "development"
+	clone.SetLimit(20)
+
+	if query.GetTokenIn()[0] != "tk_1" {
+		t.Fatalf("Expected [tk_1] received [%v]", query.GetTokenIn()[0])
+	}
+
+	if query.GetExtraEquals()["owner_id"] != "user_1" {
+		t.Fatalf("Expected [user_1] received [%v]", query.GetExtraEquals()["owner_id"])
+	}
+
+	if query.GetMetaIn()["environment"][0] != "staging" {
+		t.Fatalf("Expected [staging] received [%v]", query.GetMetaIn()["environment"][0])
+	}
+
+	if query.GetLimit() != 10 || clone.GetLimit() != 20 {
+		t.Fatalf("Expected limits [10 20] received [%v %v]", query.GetLimit(), clone.GetLimit())
+	}
+}
+
+func Test_RecordQuery_Constructors(t *testing.T) {
+	byToken := RecordQueryByToken("tk_1")
+	if !byToken.IsTokenSet() || byToken.GetToken() != "tk_1" {
+		t.Fatalf("Expected [tk_1] received [%v]", byToken.GetToken())
+	}
+
+	if err := RecordQueryByToken("").Validate(); err == nil {
+		t.Fatal("Expected an empty token to be rejected")
+	}
+
+	active := RecordQueryActive()
+	if !active.GetExpiredExclude() {
+		t.Fatal("Expected the active query to exclude expired records")
+	}
+
+	if active.IsSoftDeletedIncludeSet() || active.GetSoftDeletedOnly() {
+		t.Fatal("Expected the active query to exclude soft deleted records")
+	}
+}
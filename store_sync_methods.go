@@ -0,0 +1,303 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+	"gorm.io/gorm"
+)
+
+// syncBatchSize is the maximum number of changes returned by ChangesSince
+const syncBatchSize = 1000
+
+// ErrSyncCursorInvalid is returned when a cursor was not produced by ChangesSince
+var ErrSyncCursorInvalid = errors.New("sync cursor is invalid")
+
+// Change is a record as replicated between two stores. The value stays encrypted.
+type Change struct {
+	ID            string `json:"id"`
+	Token         string `json:"token"`
+	Value         string `json:"value"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+	ExpiresAt     string `json:"expires_at"`
+	SoftDeletedAt string `json:"soft_deleted_at"`
+}
+
+// ChangeBatch is a page of changes returned by ChangesSince
+type ChangeBatch struct {
+	// Changes are ordered by update time, oldest first
+	Changes []Change `json:"changes"`
+	// Cursor is passed to the next ChangesSince call
+	Cursor string `json:"cursor"`
+	// HasMore reports whether more changes are available right away
+	HasMore bool `json:"has_more"`
+}
+
+// SyncConflict is a change that was not applied because the local record
+// was modified at the same time or later
+type SyncConflict struct {
+	ID              string
+	Token           string
+	LocalUpdatedAt  string
+	RemoteUpdatedAt string
+}
+
+// ApplyResult is the outcome of ApplyChanges
+type ApplyResult struct {
+	// Applied is the number of created or updated records
+	Applied int
+	// Unchanged is the number of changes already present locally
+	Unchanged int
+	// Conflicts lists the changes that were skipped
+	Conflicts []SyncConflict
+}
+
+// ChangesSince returns the records created or updated after the cursor, for
+// replicating a store to a passive deployment with ApplyChanges. Pass an empty
+// cursor for a full sync, then the cursor of the previous batch.
+//
+// Soft deletes are replicated as changes. Hard deletes are not, replicas
+// should purge with the same retention jobs as the primary. Changes of the
+// current second are held back until it has passed, so none are skipped.
+//
+// Parameters:
+// - ctx: The context
+// - cursor: The cursor of the previous batch, or empty to start from the beginning
+//
+// Returns:
+// - batch: Up to 1000 changes and the cursor for the next call
+// - err: An error if something went wrong
+func (store *storeImplementation) ChangesSince(ctx context.Context, cursor string) (ChangeBatch, error) {
+	ctx, span := store.traceStart(ctx, "ChangesSince")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "ChangesSince", ""); err != nil {
+		return ChangeBatch{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	batch := ChangeBatch{Changes: []Change{}, Cursor: cursor}
+
+	if err := ctx.Err(); err != nil {
+		return batch, err
+	}
+
+	db := store.vaultDB(ctx).
+		Where(COLUMN_UPDATED_AT+" < ?", carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	if cursor != "" {
+		updatedAt, id, ok := strings.Cut(cursor, "|")
+		if !ok || carbon.Parse(updatedAt, carbon.UTC).IsZero() {
+			return batch, ErrSyncCursorInvalid
+		}
+		db = db.Where("("+COLUMN_UPDATED_AT+" > ? OR ("+COLUMN_UPDATED_AT+" = ? AND "+COLUMN_ID+" > ?))", updatedAt, updatedAt, id)
+	}
+
+	var gormRecords []gormVaultRecord
+	err := db.
+		Order(COLUMN_UPDATED_AT + " ASC").
+		Order(COLUMN_ID + " ASC").
+		Limit(syncBatchSize + 1).
+		Find(&gormRecords).Error
+	if err != nil {
+		return batch, err
+	}
+
+	if len(gormRecords) > syncBatchSize {
+		batch.HasMore = true
+		gormRecords = gormRecords[:syncBatchSize]
+	}
+
+	err = store.valueChunksResolve(ctx, gormRecords)
+	if err != nil {
+		return batch, err
+	}
+
+	for i := range gormRecords {
+		batch.Changes = append(batch.Changes, changeFromGorm(&gormRecords[i]))
+	}
+
+	if len(batch.Changes) > 0 {
+		last := batch.Changes[len(batch.Changes)-1]
+		batch.Cursor = last.UpdatedAt + "|" + last.ID
+	}
+
+	return batch, nil
+}
+
+// ApplyChanges applies a batch returned by ChangesSince of another store.
+// A change is skipped as a conflict if the local record was updated later,
+// or in the same second with a different content, or if its token is used
+// by another local record.
+//
+// Parameters:
+// - ctx: The context
+// - batch: The changes to apply
+//
+// Returns:
+// - result: The number of applied and unchanged records, and the conflicts
+// - err: An error if something went wrong
+func (store *storeImplementation) ApplyChanges(ctx context.Context, batch ChangeBatch) (ApplyResult, error) {
+	ctx, span := store.traceStart(ctx, "ApplyChanges")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "ApplyChanges", ""); err != nil {
+		return ApplyResult{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	result := ApplyResult{Conflicts: []SyncConflict{}}
+
+	for _, change := range batch.Changes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		applied, conflict, err := store.applyChange(ctx, change)
+		if err != nil {
+			return result, err
+		}
+
+		switch {
+		case conflict != nil:
+			result.Conflicts = append(result.Conflicts, *conflict)
+		case applied:
+			result.Applied++
+		default:
+			result.Unchanged++
+		}
+	}
+
+	// The applied records keep their timestamps, the other instances reload their token filters
+	if result.Applied > 0 {
+		if err := store.tokenBloomFilterBackfilled(ctx); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// applyChange creates or updates the local record of the change
+func (store *storeImplementation) applyChange(ctx context.Context, change Change) (bool, *SyncConflict, error) {
+	if change.ID == "" || change.Token == "" {
+		return false, nil, errors.New("change id and token are required")
+	}
+
+	var local gormVaultRecord
+	err := store.vaultDB(ctx).Where(COLUMN_ID+" = ?", change.ID).First(&local).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil, err
+	}
+	exists := err == nil
+
+	if !exists {
+		owner, err := store.tokenFindIncludingSoftDeleted(ctx, change.Token)
+		if err != nil {
+			return false, nil, err
+		}
+		if owner != nil {
+			return false, &SyncConflict{ID: change.ID, Token: change.Token, LocalUpdatedAt: syncDatetime(owner.GetUpdatedAt()), RemoteUpdatedAt: change.UpdatedAt}, nil
+		}
+
+		storedValue, err := store.valueChunksWrite(ctx, change.ID, change.Value)
+		if err != nil {
+			return false, nil, err
+		}
+
+		err = store.vaultDB(ctx).Create(store.recordInsertValue(ctx, &gormVaultRecord{
+			ID:            change.ID,
+			Token:         change.Token,
+			Value:         storedValue,
+			CreatedAt:     change.CreatedAt,
+			UpdatedAt:     change.UpdatedAt,
+			ExpiresAt:     change.ExpiresAt,
+			SoftDeletedAt: change.SoftDeletedAt,
+		})).Error
+		if err != nil {
+			return false, nil, err
+		}
+
+		store.tokenBloomFilterAdd(ctx, change.Token)
+		return true, nil, nil
+	}
+
+	locals := []gormVaultRecord{local}
+	if err := store.valueChunksResolve(ctx, locals); err != nil {
+		return false, nil, err
+	}
+	local = locals[0]
+
+	_, localUpdatedAt, localExpiresAt, localSoftDeletedAt := local.datetimes()
+	localUpdatedAt = syncDatetime(localUpdatedAt)
+	conflict := &SyncConflict{ID: change.ID, Token: change.Token, LocalUpdatedAt: localUpdatedAt, RemoteUpdatedAt: change.UpdatedAt}
+
+	remoteTime := carbon.Parse(change.UpdatedAt, carbon.UTC)
+	localTime := carbon.Parse(localUpdatedAt, carbon.UTC)
+
+	if localTime.Gt(remoteTime) {
+		return false, conflict, nil
+	}
+
+	if localTime.Eq(remoteTime) {
+		same := local.Token == change.Token &&
+			local.Value == change.Value &&
+			syncDatetime(localExpiresAt) == change.ExpiresAt &&
+			syncDatetime(localSoftDeletedAt) == change.SoftDeletedAt
+		if same {
+			return false, nil, nil
+		}
+		return false, conflict, nil
+	}
+
+	storedValue, err := store.valueChunksWrite(ctx, change.ID, change.Value)
+	if err != nil {
+		return false, nil, err
+	}
+
+	err = store.vaultDB(ctx).
+		Where(COLUMN_ID+" = ?", change.ID).
+		Updates(map[string]interface{}{
+			COLUMN_VAULT_TOKEN:     change.Token,
+			COLUMN_VAULT_VALUE:     storedValue,
+			COLUMN_UPDATED_AT:      change.UpdatedAt,
+			COLUMN_EXPIRES_AT:      change.ExpiresAt,
+			COLUMN_SOFT_DELETED_AT: change.SoftDeletedAt,
+		}).Error
+	if err != nil {
+		return false, nil, err
+	}
+
+	if _, err := store.valueChunksCollect(ctx, change.ID, storedValue); err != nil {
+		return false, nil, err
+	}
+
+	store.tokenBloomFilterAdd(ctx, change.Token)
+	return true, nil, nil
+}
+
+// changeFromGorm converts a record with a resolved value to a Change
+func changeFromGorm(record *gormVaultRecord) Change {
+	createdAt, updatedAt, expiresAt, softDeletedAt := record.datetimes()
+	return Change{
+		ID:            record.ID,
+		Token:         record.Token,
+		Value:         record.Value,
+		CreatedAt:     syncDatetime(createdAt),
+		UpdatedAt:     syncDatetime(updatedAt),
+		ExpiresAt:     syncDatetime(expiresAt),
+		SoftDeletedAt: syncDatetime(softDeletedAt),
+	}
+}
+
+// syncDatetime normalizes a datetime read from the database to "YYYY-MM-DD HH:MM:SS"
+func syncDatetime(datetime string) string {
+	parsed := carbon.Parse(datetime, carbon.UTC)
+	if !parsed.IsValid() {
+		return datetime
+	}
+	return parsed.ToDateTimeString(carbon.UTC)
+}
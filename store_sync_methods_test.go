@@ -0,0 +1,95 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func Test_Store_ChangesSinceApplyChanges(t *testing.T) {
+	primary, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	replica, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// Changes of the current second are held back, so write them in the past
+	past := carbon.Now(carbon.UTC).SubSeconds(10).ToDateTimeString(carbon.UTC)
+	for _, token := range []string{"sync_token_1", "sync_token_2"} {
+		if err := primary.TokenCreateCustom(ctx, token, "value_"+token, password); err != nil {
+			t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+	if err := primary.(*storeImplementation).gormDB.Table("vault_token").Where("1 = 1").Update(COLUMN_UPDATED_AT, past).Error; err != nil {
+		t.Fatalf("Update: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	batch, err := primary.ChangesSince(ctx, "")
+	if err != nil {
+		t.Fatalf("ChangesSince: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if len(batch.Changes) != 2 || batch.HasMore || batch.Cursor == "" {
+		t.Fatalf("Expected 2 changes and a cursor received %+v", batch)
+	}
+
+	result, err := replica.ApplyChanges(ctx, batch)
+	if err != nil {
+		t.Fatalf("ApplyChanges: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if result.Applied != 2 || len(result.Conflicts) != 0 {
+		t.Fatalf("Expected 2 applied changes received %+v", result)
+	}
+
+	value, err := replica.TokenRead(ctx, "sync_token_1", password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != "value_sync_token_1" {
+		t.Fatalf("Expected [value_sync_token_1] received [%s]", value)
+	}
+
+	// Applying the same batch again changes nothing
+	result, err = replica.ApplyChanges(ctx, batch)
+	if err != nil {
+		t.Fatalf("ApplyChanges: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if result.Unchanged != 2 {
+		t.Fatalf("Expected 2 unchanged changes received %+v", result)
+	}
+
+	// The cursor only returns later changes
+	next, err := primary.ChangesSince(ctx, batch.Cursor)
+	if err != nil {
+		t.Fatalf("ChangesSince: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if len(next.Changes) != 0 {
+		t.Fatalf("Expected no changes received %+v", next.Changes)
+	}
+
+	// A replica record updated later than the change is a conflict
+	if err := replica.TokenUpdate(ctx, "sync_token_1", "replica_value", password); err != nil {
+		t.Fatalf("TokenUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	result, err = replica.ApplyChanges(ctx, batch)
+	if err != nil {
+		t.Fatalf("ApplyChanges: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Token != "sync_token_1" {
+		t.Fatalf("Expected a conflict for sync_token_1 received %+v", result)
+	}
+
+	_, err = primary.ChangesSince(ctx, "invalid")
+	if !errors.Is(err, ErrSyncCursorInvalid) {
+		t.Fatalf("Expected [ErrSyncCursorInvalid] received [%v]", err)
+	}
+}
@@ -0,0 +1,234 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+var (
+	// ErrTokenAliasInvalid is returned for an alias with unsupported characters or longer than 255 characters
+	ErrTokenAliasInvalid = errors.New("token alias must be 1 to 255 letters, digits or . _ - / : @ characters")
+	// ErrTokenAliasExists is returned by TokenAliasAdd when the alias points to another token
+	ErrTokenAliasExists = errors.New("token alias already exists")
+	// ErrTokenAliasNotFound is returned by TokenAliasResolve for an unknown alias
+	ErrTokenAliasNotFound = errors.New("token alias not found")
+)
+
+// tokenAliasRegex limits aliases to human-friendly names such as "prod/stripe/api-key"
+var tokenAliasRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-/:@]{1,255}$`)
+
+// TokenAliasAdd attaches an alias to a token, a human-friendly name such as
+// "prod/stripe/api-key" resolved to the token with TokenAliasResolve. A token can
+// have several aliases, an alias points to a single token. Adding the alias again
+// to the same token is not an error.
+//
+// Aliases are stored in the meta table, scoped to the vault table and namespace,
+// and encrypted with the meta encryption key if configured, with the ID of the record
+// of the token. The alias of a deleted token is released, it can be added to another
+// token, and does not resolve to a custom token created again with the same value.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token
+// - alias: The alias, 1 to 255 letters, digits or . _ - / : @ characters
+//
+// Returns:
+// - err: ErrTokenNotFound, ErrTokenAliasInvalid, ErrTokenAliasExists, or an error if something went wrong
+func (store *storeImplementation) TokenAliasAdd(ctx context.Context, token string, alias string) error {
+	ctx, span := store.traceStart(ctx, "TokenAliasAdd")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenAliasAdd", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if !tokenAliasRegex.MatchString(alias) {
+		return ErrTokenAliasInvalid
+	}
+
+	record, err := store.tokenMetaRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := store.metaScopedObjectID(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	existing, err := store.tokenAliasFind(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if existing == "" {
+		errClaim := store.metaCreate(ctx, OBJECT_TYPE_TOKEN_ALIAS, objectID, META_KEY_TOKEN, token)
+		if errClaim == nil {
+			return store.metaSet(ctx, OBJECT_TYPE_TOKEN_ALIAS, objectID, META_KEY_RECORD_ID, record.GetID())
+		}
+
+		// A concurrent call may have claimed the alias first, the unique index rejects the insert
+		existing, err = store.tokenAliasFind(ctx, objectID)
+		if err != nil {
+			return err
+		}
+
+		if existing == "" {
+			return errClaim
+		}
+	}
+
+	if existing != token {
+		return ErrTokenAliasExists
+	}
+
+	return nil
+}
+
+// TokenAliasRemove detaches an alias from its token, removing a missing alias is not an error
+//
+// Parameters:
+// - ctx: The context
+// - alias: The alias
+//
+// Returns:
+// - err: ErrTokenAliasInvalid, or an error if something went wrong
+func (store *storeImplementation) TokenAliasRemove(ctx context.Context, alias string) error {
+	ctx, span := store.traceStart(ctx, "TokenAliasRemove")
+	defer span.End()
+
+	if !tokenAliasRegex.MatchString(alias) {
+		return ErrTokenAliasInvalid
+	}
+
+	objectID, meta, err := store.tokenAliasMetaFind(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	// The operation is authorized for the token of the alias
+	if err := store.operationAllow(ctx, "TokenAliasRemove", tokenAliasMetaToken(meta)); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	return store.tokenAliasDelete(ctx, objectID)
+}
+
+// TokenAliasResolve returns the token the alias points to, so the secret is read by its name:
+//
+//	token, err := store.TokenAliasResolve(ctx, "prod/stripe/api-key")
+//	value, err := store.TokenRead(ctx, token, password)
+//
+// Parameters:
+// - ctx: The context
+// - alias: The alias
+//
+// Returns:
+// - token: The token
+// - err: ErrTokenAliasInvalid, ErrTokenAliasNotFound, or an error if something went wrong
+func (store *storeImplementation) TokenAliasResolve(ctx context.Context, alias string) (string, error) {
+	ctx, span := store.traceStart(ctx, "TokenAliasResolve")
+	defer span.End()
+
+	if !tokenAliasRegex.MatchString(alias) {
+		return "", ErrTokenAliasInvalid
+	}
+
+	objectID, meta, err := store.tokenAliasMetaFind(ctx, alias)
+	if err != nil {
+		return "", err
+	}
+
+	// The operation is authorized for the token of the alias
+	if err := store.operationAllow(ctx, "TokenAliasResolve", tokenAliasMetaToken(meta)); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	token, err := store.tokenAliasCheck(ctx, objectID, meta)
+	if err != nil {
+		return "", err
+	}
+
+	if token == "" {
+		return "", ErrTokenAliasNotFound
+	}
+
+	return token, nil
+}
+
+// tokenAliasMetaFind returns the meta object ID of the alias and its token meta row,
+// nil if the alias does not exist
+func (store *storeImplementation) tokenAliasMetaFind(ctx context.Context, alias string) (string, *gormVaultMeta, error) {
+	objectID, err := store.metaScopedObjectID(ctx, alias)
+	if err != nil {
+		return "", nil, err
+	}
+
+	meta, err := store.metaFind(ctx, OBJECT_TYPE_TOKEN_ALIAS, objectID, META_KEY_TOKEN)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return objectID, meta, nil
+}
+
+// tokenAliasMetaToken returns the token of the alias meta row, empty if there is none
+func tokenAliasMetaToken(meta *gormVaultMeta) string {
+	if meta == nil {
+		return ""
+	}
+	return meta.Value
+}
+
+// tokenAliasFind returns the token of the alias object, or an empty string if there is none
+func (store *storeImplementation) tokenAliasFind(ctx context.Context, objectID string) (string, error) {
+	meta, err := store.metaFind(ctx, OBJECT_TYPE_TOKEN_ALIAS, objectID, META_KEY_TOKEN)
+	if err != nil {
+		return "", err
+	}
+
+	return store.tokenAliasCheck(ctx, objectID, meta)
+}
+
+// tokenAliasCheck returns the token of the alias meta row if the alias still points to
+// the record it was added to, or an empty string. The alias of a deleted token is
+// released, soft deleted tokens keep theirs. Aliases added before the record ID was
+// stored point to the token of any record.
+func (store *storeImplementation) tokenAliasCheck(ctx context.Context, objectID string, meta *gormVaultMeta) (string, error) {
+	if meta == nil {
+		return "", nil
+	}
+
+	record, err := store.tokenFindIncludingSoftDeleted(ctx, meta.Value)
+	if err != nil {
+		return "", err
+	}
+
+	if record != nil {
+		recordID, err := store.metaFind(ctx, OBJECT_TYPE_TOKEN_ALIAS, objectID, META_KEY_RECORD_ID)
+		if err != nil {
+			return "", err
+		}
+
+		if recordID == nil || recordID.Value == record.GetID() {
+			return meta.Value, nil
+		}
+	}
+
+	if err := store.tokenAliasDelete(ctx, objectID); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+// tokenAliasDelete removes the meta rows of the alias object
+func (store *storeImplementation) tokenAliasDelete(ctx context.Context, objectID string) error {
+	return store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ?", OBJECT_TYPE_TOKEN_ALIAS, objectID).
+		Delete(&gormVaultMeta{}).Error
+}
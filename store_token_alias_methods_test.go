@@ -0,0 +1,211 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_Store_TokenAlias(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "sk_live_123", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenAliasAdd(ctx, token, "prod/stripe/api-key"); err != nil {
+		t.Fatalf("TokenAliasAdd: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Adding the alias again to the same token is not an error
+	if err := store.TokenAliasAdd(ctx, token, "prod/stripe/api-key"); err != nil {
+		t.Fatalf("TokenAliasAdd: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	resolved, err := store.TokenAliasResolve(ctx, "prod/stripe/api-key")
+	if err != nil {
+		t.Fatalf("TokenAliasResolve: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if resolved != token {
+		t.Fatalf("Expected [%v] received [%v]", token, resolved)
+	}
+
+	value, err := store.TokenRead(ctx, resolved, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "sk_live_123" {
+		t.Fatalf("Expected [sk_live_123] received [%v]", value)
+	}
+
+	other, err := store.TokenCreate(ctx, "other", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenAliasAdd(ctx, other, "prod/stripe/api-key"); !errors.Is(err, ErrTokenAliasExists) {
+		t.Fatalf("Expected [ErrTokenAliasExists] received [%v]", err)
+	}
+
+	if err := store.TokenAliasRemove(ctx, "prod/stripe/api-key"); err != nil {
+		t.Fatalf("TokenAliasRemove: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenAliasResolve(ctx, "prod/stripe/api-key"); !errors.Is(err, ErrTokenAliasNotFound) {
+		t.Fatalf("Expected [ErrTokenAliasNotFound] received [%v]", err)
+	}
+
+	// Removing a missing alias is not an error
+	if err := store.TokenAliasRemove(ctx, "prod/stripe/api-key"); err != nil {
+		t.Fatalf("TokenAliasRemove: Expected [err] to be nil received [%v]", err.Error())
+	}
+}
+
+func Test_Store_TokenAliasDeletedToken(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenAliasAdd(ctx, token, "db-password"); err != nil {
+		t.Fatalf("TokenAliasAdd: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenDelete(ctx, token); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenAliasResolve(ctx, "db-password"); !errors.Is(err, ErrTokenAliasNotFound) {
+		t.Fatalf("Expected [ErrTokenAliasNotFound] received [%v]", err)
+	}
+
+	// The alias of the deleted token is released
+	replacement, err := store.TokenCreate(ctx, "rotated", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenAliasAdd(ctx, replacement, "db-password"); err != nil {
+		t.Fatalf("TokenAliasAdd: Expected [err] to be nil received [%v]", err.Error())
+	}
+}
+
+func Test_Store_TokenAliasErrors(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for _, alias := range []string{"", "has space", "semi;colon"} {
+		if err := store.TokenAliasAdd(ctx, token, alias); !errors.Is(err, ErrTokenAliasInvalid) {
+			t.Fatalf("Expected [ErrTokenAliasInvalid] for [%v] received [%v]", alias, err)
+		}
+	}
+
+	if err := store.TokenAliasAdd(ctx, "tk_missing", "missing"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Expected [ErrTokenNotFound] received [%v]", err)
+	}
+}
+
+func Test_Store_TokenAliasRecreatedToken(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	if err := store.TokenCreateCustom(ctx, "db_password", "secret", password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenAliasAdd(ctx, "db_password", "prod/db"); err != nil {
+		t.Fatalf("TokenAliasAdd: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenDelete(ctx, "db_password"); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenCreateCustom(ctx, "db_password", "other secret", password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The token created again does not inherit the alias of the deleted one
+	if _, err := store.TokenAliasResolve(ctx, "prod/db"); !errors.Is(err, ErrTokenAliasNotFound) {
+		t.Fatalf("Expected [ErrTokenAliasNotFound] received [%v]", err)
+	}
+}
+
+func Test_Store_TokenAliasOperationGuard(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	deniedToken := ""
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_token",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		OperationGuard: OperationGuardFunc(func(ctx context.Context, operation string, token string) error {
+			if token != "" && token == deniedToken && (operation == "TokenAliasResolve" || operation == "TokenAliasRemove") {
+				return ErrOperationDenied
+			}
+			return nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenAliasAdd(ctx, token, "prod/api-key"); err != nil {
+		t.Fatalf("TokenAliasAdd: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	deniedToken = token
+
+	// The guard checks the token the alias points to
+	if _, err := store.TokenAliasResolve(ctx, "prod/api-key"); !errors.Is(err, ErrOperationDenied) {
+		t.Fatalf("TokenAliasResolve: Expected [ErrOperationDenied] received [%v]", err)
+	}
+
+	if err := store.TokenAliasRemove(ctx, "prod/api-key"); !errors.Is(err, ErrOperationDenied) {
+		t.Fatalf("TokenAliasRemove: Expected [ErrOperationDenied] received [%v]", err)
+	}
+}
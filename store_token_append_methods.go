@@ -0,0 +1,202 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// tokenAppendMaxAttempts is how often TokenAppend retries when a concurrent
+// append claimed the same sequence number
+const tokenAppendMaxAttempts = 5
+
+// appendedChunkKey returns the meta key for the chunk sequence number.
+// Zero padding keeps the keys sortable as strings.
+func appendedChunkKey(sequence int) string {
+	return fmt.Sprintf("%010d", sequence)
+}
+
+// TokenAppend appends an encrypted chunk to the token, without rewriting the existing value
+//
+// Each chunk is encrypted separately and stored in the meta table,
+// which makes tokens usable as encrypted append-only logs (e.g. audit trails).
+// TokenRead keeps returning the initial value, use TokenReadAll to read all chunks.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to append to
+// - chunk: The value to append
+// - password: The password of the token, also used to encrypt the chunk
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenAppend(ctx context.Context, token string, chunk string, password string) error {
+	ctx, span := store.traceStart(ctx, "TokenAppend")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenAppend", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := store.validatePassword(password); err != nil {
+		return err
+	}
+
+	if token == "" {
+		return errors.New("token is empty")
+	}
+
+	entry, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if entry == nil {
+		return ErrTokenNotFound
+	}
+
+	if isRecordExpired(entry) {
+		return ErrTokenExpired
+	}
+
+	if err := store.tokenRevocationCheck(ctx, entry); err != nil {
+		return err
+	}
+
+	if err := store.tokenQuarantineCheck(ctx, entry); err != nil {
+		return err
+	}
+
+	if err := store.tokenCheckoutCheck(ctx, entry); err != nil {
+		return err
+	}
+
+	// Verify the password, so all chunks of a token share it
+	if _, err := decode(ctx, entry.GetValue(), password, store.cryptoConfig); err != nil {
+		return err
+	}
+
+	encodedChunk, err := encode(ctx, chunk, password, store.cryptoConfig)
+	if err != nil {
+		return fmt.Errorf("failed to encode chunk: %w", err)
+	}
+
+	objectID := recordMetaObjectID(entry.GetID())
+
+	for attempt := 0; attempt < tokenAppendMaxAttempts; attempt++ {
+		var count int64
+		err = store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ?", OBJECT_TYPE_RECORD_CHUNK, objectID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+
+		// The unique meta index rejects a sequence number taken by a concurrent append
+		err = store.metaCreate(ctx, OBJECT_TYPE_RECORD_CHUNK, objectID, appendedChunkKey(int(count)+1), encodedChunk)
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to append chunk: %w", err)
+}
+
+// TokenReadAll reads the initial value of the token followed by all appended chunks, in order
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read
+// - password: The password to use for decryption
+//
+// Returns:
+// - values: The initial value and the appended chunks
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenReadAll(ctx context.Context, token string, password string) ([]string, error) {
+	ctx, span := store.traceStart(ctx, "TokenReadAll")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenReadAll", token); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, value, err := store.tokenReadRecord(ctx, token, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []gormVaultMeta
+	err = store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ?", OBJECT_TYPE_RECORD_CHUNK, recordMetaObjectID(entry.GetID())).
+		Find(&chunks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].Key < chunks[j].Key
+	})
+
+	values := make([]string, 0, len(chunks)+1)
+	values = append(values, value)
+
+	for _, chunk := range chunks {
+		decoded, err := decode(ctx, chunk.Value, password, store.cryptoConfig)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, decoded)
+	}
+
+	return values, nil
+}
+
+// recordChunksRekey re-encrypts the appended chunks and the kept versions of the record
+// that can be decrypted with the old password. Both are independent ciphertexts, so they
+// are tested the same way as records, in batches ordered by ID.
+func (store *storeImplementation) recordChunksRekey(ctx context.Context, recordID string, oldPassword, newPassword string) error {
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var chunks []gormVaultMeta
+		err := store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" IN ?", []string{OBJECT_TYPE_RECORD_CHUNK, OBJECT_TYPE_RECORD_VERSION}).
+			Where(COLUMN_OBJECT_ID+" = ?", recordMetaObjectID(recordID)).
+			Where(COLUMN_ID+" > ?", lastID).
+			Order(COLUMN_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&chunks).Error
+		if err != nil {
+			return err
+		}
+
+		if len(chunks) == 0 {
+			return nil
+		}
+		lastID = chunks[len(chunks)-1].ID
+
+		for _, chunk := range chunks {
+			decoded, err := decode(ctx, chunk.Value, oldPassword, store.cryptoConfig)
+			if err != nil {
+				// Chunk doesn't use old password, skip it
+				continue
+			}
+
+			encoded, err := encode(ctx, decoded, newPassword, store.cryptoConfig)
+			if err != nil {
+				return fmt.Errorf("failed to encode chunk %d: %w", chunk.ID, err)
+			}
+
+			chunk.Value = encoded
+			if err := store.metaDB(ctx).Save(&chunk).Error; err != nil {
+				return fmt.Errorf("failed to update chunk %d: %w", chunk.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package vaultstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func Test_Store_TokenAppend(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_append",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "entry0", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for _, chunk := range []string{"entry1", "entry2"} {
+		if err := store.TokenAppend(ctx, token, chunk, password); err != nil {
+			t.Fatalf("TokenAppend: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	values, err := store.TokenReadAll(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadAll: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if strings.Join(values, ",") != "entry0,entry1,entry2" {
+		t.Fatalf("Expected [entry0,entry1,entry2] received [%v]", values)
+	}
+
+	// TokenRead keeps returning the initial value
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != "entry0" {
+		t.Fatalf("Expected [entry0] received [%v]", value)
+	}
+
+	// Appending with a different password is rejected
+	if err := store.TokenAppend(ctx, token, "entry3", "another_password_that_is_long_enough_for_security"); err == nil {
+		t.Fatal("Expected error appending with the wrong password")
+	}
+
+	// Changing the password re-encrypts the chunks too
+	newPassword := "new_password_that_is_long_enough_for_security_32chars"
+	if _, err := store.TokensChangePassword(ctx, password, newPassword); err != nil {
+		t.Fatalf("TokensChangePassword: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	values, err = store.TokenReadAll(ctx, token, newPassword)
+	if err != nil {
+		t.Fatalf("TokenReadAll: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if strings.Join(values, ",") != "entry0,entry1,entry2" {
+		t.Fatalf("Expected [entry0,entry1,entry2] received [%v]", values)
+	}
+
+	// Deleting the token removes its chunks
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil || record == nil {
+		t.Fatalf("RecordFindByToken: Expected record received [%v] [%v]", record, err)
+	}
+
+	if err := store.TokenDelete(ctx, token); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var count int64
+	err = store.metaDB(ctx).
+		Where(COLUMN_OBJECT_ID+" = ?", recordMetaObjectID(record.GetID())).
+		Count(&count).Error
+	if err != nil {
+		t.Fatalf("Count: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if count != 0 {
+		t.Fatalf("Expected chunks to be deleted, [%d] remaining", count)
+	}
+}
+
+func Test_Store_TokenAppendChangePasswordScope(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.AutoMigrateTableSuffix("tenantA"); err != nil {
+		t.Fatalf("AutoMigrateTableSuffix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	ctxA := WithTableSuffix(ctx, "tenantA")
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	newPassword := "new_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "entry0", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	tokenA, err := store.TokenCreate(ctxA, "entryA0", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenAppend(ctx, token, "entry1", password); err != nil {
+		t.Fatalf("TokenAppend: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenAppend(ctxA, tokenA, "entryA1", password); err != nil {
+		t.Fatalf("TokenAppend: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokensChangePassword(ctx, password, newPassword); err != nil {
+		t.Fatalf("TokensChangePassword: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	values, err := store.TokenReadAll(ctx, token, newPassword)
+	if err != nil {
+		t.Fatalf("TokenReadAll: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if strings.Join(values, ",") != "entry0,entry1" {
+		t.Fatalf("Expected [entry0,entry1] received [%v]", values)
+	}
+
+	// The chunks of the other table keep the old password, along with their record
+	values, err = store.TokenReadAll(ctxA, tokenA, password)
+	if err != nil {
+		t.Fatalf("TokenReadAll: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if strings.Join(values, ",") != "entryA0,entryA1" {
+		t.Fatalf("Expected [entryA0,entryA1] received [%v]", values)
+	}
+}
+
+func Test_appendedChunkKey(t *testing.T) {
+	if appendedChunkKey(2) >= appendedChunkKey(10) {
+		t.Fatal("Expected chunk keys to sort numerically")
+	}
+}
@@ -0,0 +1,235 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// tokenCreateBatchMaxAttempts is the number of times colliding generated tokens are regenerated
+const tokenCreateBatchMaxAttempts = 3
+
+// TokenCreateBatch creates a token for each value in a single transaction, with multi-row
+// INSERT statements. The values are encrypted in parallel (see NewStoreOptions.DecryptWorkers),
+// which makes it much faster than calling TokenCreate for each value.
+//
+// Either all the tokens are created or none. If the context holds a transaction
+// (see database.Context), the records are created in it.
+//
+// Parameters:
+// - ctx: The context
+// - values: The values to store
+// - password: The password to encrypt the values with
+// - tokenLength: The length of the generated tokens
+// - options: Optional settings applied to all tokens (IdempotencyKey is not used)
+//
+// Returns:
+// - tokens: The created tokens, in the order of the values
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenCreateBatch(ctx context.Context, values []string, password string, tokenLength int, options ...TokenCreateOptions) (tokens []string, err error) {
+	ctx, span := store.traceStart(ctx, "TokenCreateBatch")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCreateBatch", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := store.validatePassword(password); err != nil {
+		return nil, err
+	}
+
+	if err := validateTokenCreateOptions(options); err != nil {
+		return nil, err
+	}
+
+	options, err = store.tokenCreateOptionsNormalize(options)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err = store.tokenCreateOptionsWithRetention(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return []string{}, nil
+	}
+
+	encodedValues, err := store.encodeValues(ctx, values, password)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := ""
+	contentType := ""
+	maxReads := 0
+	if len(options) > 0 {
+		if !options[0].ExpiresAt.IsZero() {
+			expiresAt = carbon.CreateFromStdTime(options[0].ExpiresAt).ToDateTimeString(carbon.UTC)
+		}
+		contentType = options[0].ContentType
+		maxReads = options[0].MaxReads
+	}
+
+	err = store.transaction(ctx, func(ctx context.Context) error {
+		tokens, err = store.tokensGenerateUnique(ctx, len(values), tokenLength)
+		if err != nil {
+			return err
+		}
+
+		now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+		records := make([]RecordInterface, len(values))
+		for i, encodedValue := range encodedValues {
+			record := store.newRecord().
+				SetToken(tokens[i]).
+				SetValue(encodedValue).
+				SetCreatedAt(now).
+				SetUpdatedAt(now)
+
+			if expiresAt != "" {
+				record.SetExpiresAt(expiresAt)
+			}
+
+			records[i] = record
+		}
+
+		if err := store.RecordCreateMany(ctx, records); err != nil {
+			return err
+		}
+
+		metas := []*gormVaultMeta{}
+		for _, record := range records {
+			if contentType != "" {
+				value, err := store.metaValueEncrypt(OBJECT_TYPE_RECORD, META_KEY_CONTENT_TYPE, contentType)
+				if err != nil {
+					return err
+				}
+
+				metas = append(metas, &gormVaultMeta{
+					ObjectType: OBJECT_TYPE_RECORD,
+					ObjectID:   recordMetaObjectID(record.GetID()),
+					Key:        META_KEY_CONTENT_TYPE,
+					Value:      value,
+				})
+			}
+
+			if maxReads > 0 {
+				metas = append(metas, &gormVaultMeta{
+					ObjectType: OBJECT_TYPE_RECORD,
+					ObjectID:   recordMetaObjectID(record.GetID()),
+					Key:        META_KEY_READS_REMAINING,
+					Value:      strconv.Itoa(maxReads),
+				})
+			}
+		}
+
+		if len(metas) == 0 {
+			return nil
+		}
+
+		return store.metaDB(ctx).CreateInBatches(metas, recordCreateBatchSize).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// tokensGenerateUnique generates count distinct tokens not used by any record, soft deleted included
+func (store *storeImplementation) tokensGenerateUnique(ctx context.Context, count int, tokenLength int) ([]string, error) {
+	tokens := make([]string, 0, count)
+	seen := make(map[string]struct{}, count)
+
+	for attempt := 0; attempt < tokenCreateBatchMaxAttempts; attempt++ {
+		candidates := []string{}
+		for len(tokens)+len(candidates) < count {
+			token, err := generateToken(tokenLength)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := seen[token]; ok {
+				continue
+			}
+			seen[token] = struct{}{}
+			candidates = append(candidates, token)
+		}
+
+		existing := []string{}
+		for _, chunk := range lo.Chunk(candidates, maxRecordsInMemory) {
+			var found []string
+			err := store.vaultDB(ctx).
+				Where(COLUMN_VAULT_TOKEN+" IN ?", chunk).
+				Pluck(COLUMN_VAULT_TOKEN, &found).Error
+			if err != nil {
+				return nil, err
+			}
+			existing = append(existing, found...)
+		}
+
+		taken := make(map[string]struct{}, len(existing))
+		for _, token := range existing {
+			taken[token] = struct{}{}
+		}
+
+		for _, token := range candidates {
+			if _, ok := taken[token]; !ok {
+				tokens = append(tokens, token)
+			}
+		}
+
+		if len(tokens) == count {
+			return tokens, nil
+		}
+	}
+
+	return nil, errors.New("failed to create tokens")
+}
+
+// encodeValues encrypts the values with a bounded worker pool, keeping their order
+func (store *storeImplementation) encodeValues(ctx context.Context, values []string, password string) ([]string, error) {
+	encoded := make([]string, len(values))
+	errs := make([]error, len(values))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for i := 0; i < min(store.getDecryptWorkers(), len(values)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				encoded[index], errs[index] = encode(ctx, values[index], password, store.cryptoConfig)
+			}
+		}()
+	}
+
+	for index := range values {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode data: %w", err)
+		}
+	}
+
+	return encoded, nil
+}
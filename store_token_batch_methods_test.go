@@ -0,0 +1,126 @@
+package vaultstore
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dracory/database"
+)
+
+func Test_Store_TokenCreateBatch(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	values := []string{}
+	for i := range 25 {
+		values = append(values, "value "+strconv.Itoa(i))
+	}
+
+	tokens, err := store.TokenCreateBatch(ctx, values, password, 20, TokenCreateOptions{
+		ExpiresAt:   time.Now().Add(time.Hour),
+		ContentType: CONTENT_TYPE_JSON,
+	})
+	if err != nil {
+		t.Fatalf("TokenCreateBatch: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(tokens) != len(values) {
+		t.Fatalf("Expected %d tokens received [%v]", len(values), len(tokens))
+	}
+
+	seen := map[string]bool{}
+	for i, token := range tokens {
+		if seen[token] {
+			t.Fatalf("Expected unique tokens, [%v] is repeated", token)
+		}
+		seen[token] = true
+
+		value, err := store.TokenRead(ctx, token, password)
+		if err != nil {
+			t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if value != values[i] {
+			t.Fatalf("Expected [%v] received [%v]", values[i], value)
+		}
+	}
+
+	_, info, err := store.TokenReadWithInfo(ctx, tokens[0], password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if info.ContentType != CONTENT_TYPE_JSON {
+		t.Fatalf("Expected [%v] received [%v]", CONTENT_TYPE_JSON, info.ContentType)
+	}
+
+	if info.ExpiresAt == MAX_DATETIME {
+		t.Fatal("Expected the expiration to be set")
+	}
+
+	empty, err := store.TokenCreateBatch(ctx, []string{}, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreateBatch: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(empty) != 0 {
+		t.Fatalf("Expected no tokens received [%v]", len(empty))
+	}
+
+	if _, err := store.TokenCreateBatch(ctx, values, "short", 20); err == nil {
+		t.Fatal("Expected the weak password to be rejected")
+	}
+}
+
+func Test_Store_TokenCreateBatch_CallerTransaction(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+	db.SetMaxOpenConns(1)
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_batch_tx",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	tokens, err := store.TokenCreateBatch(database.Context(ctx, tx), []string{"a", "b"}, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreateBatch: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for _, token := range tokens {
+		exists, err := store.TokenExists(ctx, token)
+		if err != nil {
+			t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if exists {
+			t.Fatal("Expected the tokens to be rolled back with the transaction")
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package vaultstore
+
+import (
+	"context"
+)
+
+// The bytes methods store binary values, e.g. keys or images, without the caller
+// encoding them as text first. Go strings hold any bytes, so the value is encrypted
+// as is and its ciphertext is encoded once, the same as a text value. Tokens created
+// with either variant can be read with the other.
+//
+// They are token level conveniences only: the records keep their text value column,
+// there are no record methods taking bytes, and the ciphertext is stored base64
+// encoded, about a third larger than a BLOB/bytea column would hold it.
+
+// TokenCreateBytes creates a new token holding a binary value, see TokenCreate
+//
+// Parameters:
+// - ctx: The context
+// - value: The binary value to store
+// - password: The password to use for encryption
+// - tokenLength: The length of the token to generate
+// - options: The token create options
+//
+// Returns:
+// - token: The created token
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenCreateBytes(ctx context.Context, value []byte, password string, tokenLength int, options ...TokenCreateOptions) (token string, err error) {
+	ctx, span := store.traceStart(ctx, "TokenCreateBytes")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCreateBytes", ""); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	return store.TokenCreate(ctx, string(value), password, tokenLength, options...)
+}
+
+// TokenCreateCustomBytes creates a token with a custom token string holding a binary
+// value, see TokenCreateCustom
+//
+// Parameters:
+// - ctx: The context
+// - token: The custom token string
+// - value: The binary value to store
+// - password: The password to use for encryption
+// - options: The token create options
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenCreateCustomBytes(ctx context.Context, token string, value []byte, password string, options ...TokenCreateOptions) error {
+	ctx, span := store.traceStart(ctx, "TokenCreateCustomBytes")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCreateCustomBytes", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	return store.TokenCreateCustom(ctx, token, string(value), password, options...)
+}
+
+// TokenReadBytes reads the value of a token as bytes, see TokenRead
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read
+// - password: The password to use for decryption
+//
+// Returns:
+// - value: The binary value of the token
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenReadBytes(ctx context.Context, token string, password string) ([]byte, error) {
+	ctx, span := store.traceStart(ctx, "TokenReadBytes")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenReadBytes", token); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(value), nil
+}
+
+// TokenUpdateBytes updates the value of a token with a binary value, see TokenUpdate
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to update
+// - value: The new binary value
+// - password: The password to use for encryption
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenUpdateBytes(ctx context.Context, token string, value []byte, password string) error {
+	ctx, span := store.traceStart(ctx, "TokenUpdateBytes")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenUpdateBytes", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	return store.TokenUpdate(ctx, token, string(value), password)
+}
+
+// TokensReadBytes reads the values of several tokens as bytes, see TokensRead
+//
+// Parameters:
+// - ctx: The context
+// - tokens: The tokens to read
+// - password: The password to use for decryption
+//
+// Returns:
+// - values: The binary values by token
+// - err: An error if something went wrong
+func (store *storeImplementation) TokensReadBytes(ctx context.Context, tokens []string, password string) (map[string][]byte, error) {
+	ctx, span := store.traceStart(ctx, "TokensReadBytes", traceRecordCount(len(tokens)))
+	defer span.End()
+
+	if err := store.operationAllowTokens(ctx, "TokensReadBytes", tokens); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	values, err := store.TokensRead(ctx, tokens, password)
+	if err != nil {
+		return nil, err
+	}
+
+	valuesBytes := make(map[string][]byte, len(values))
+	for token, value := range values {
+		valuesBytes[token] = []byte(value)
+	}
+
+	return valuesBytes, nil
+}
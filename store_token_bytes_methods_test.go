@@ -0,0 +1,85 @@
+package vaultstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func Test_Store_TokenCreateBytes(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// Not valid UTF-8, with zero bytes
+	value := []byte{0x00, 0xff, 0xfe, 0x80, 0x00, 0x01}
+
+	token, err := store.TokenCreateBytes(ctx, value, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreateBytes: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	read, err := store.TokenReadBytes(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadBytes: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !bytes.Equal(read, value) {
+		t.Fatalf("TokenReadBytes: Expected [%x] received [%x]", value, read)
+	}
+
+	updated := []byte{0xde, 0xad, 0xbe, 0xef}
+	if err := store.TokenUpdateBytes(ctx, token, updated, password); err != nil {
+		t.Fatalf("TokenUpdateBytes: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenCreateCustomBytes(ctx, "custom_bytes_token", value, password); err != nil {
+		t.Fatalf("TokenCreateCustomBytes: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	values, err := store.TokensReadBytes(ctx, []string{token, "custom_bytes_token"}, password)
+	if err != nil {
+		t.Fatalf("TokensReadBytes: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !bytes.Equal(values[token], updated) {
+		t.Fatalf("TokensReadBytes: Expected [%x] received [%x]", updated, values[token])
+	}
+
+	if !bytes.Equal(values["custom_bytes_token"], value) {
+		t.Fatalf("TokensReadBytes: Expected [%x] received [%x]", value, values["custom_bytes_token"])
+	}
+
+	// Binary and text values are interchangeable
+	text, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if text != string(updated) {
+		t.Fatalf("TokenRead: Expected [%x] received [%x]", updated, text)
+	}
+}
+
+func Test_encode_BinaryValue(t *testing.T) {
+	value := string([]byte{0x00, 0xff, 0xfe, 0x80})
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	encoded, err := encode(context.Background(), value, password, DefaultCryptoConfig())
+	if err != nil {
+		t.Fatalf("encode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	decoded, err := decode(context.Background(), encoded, password, DefaultCryptoConfig())
+	if err != nil {
+		t.Fatalf("decode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if decoded != value {
+		t.Fatalf("decode: Expected [%x] received [%x]", value, decoded)
+	}
+}
@@ -0,0 +1,259 @@
+package vaultstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// ErrCheckedOut is returned when a token is checked out by another holder.
+// The returned error wraps it and includes the holder and the lease expiration.
+var ErrCheckedOut = errors.New("token is checked out")
+
+// ErrLeaseNotHeld is returned when checking in a token not checked out by the holder
+var ErrLeaseNotHeld = errors.New("token is not checked out by the holder")
+
+// tokenCheckoutMaxAttempts is the number of times TokenCheckout retries after a concurrent checkout
+const tokenCheckoutMaxAttempts = 3
+
+// TokenLease describes the checkout of a token
+type TokenLease struct {
+	Token        string `json:"-"`
+	Holder       string `json:"holder"`
+	CheckedOutAt string `json:"checked_out_at"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+// isActive returns true if the lease has not expired
+func (lease TokenLease) isActive() bool {
+	expiresAt := carbon.Parse(lease.ExpiresAt, carbon.UTC)
+	return expiresAt.IsValid() && carbon.Now(carbon.UTC).Lt(expiresAt)
+}
+
+// leaseHolderContextKey is the context key for the holder of token checkouts
+type leaseHolderContextKey struct{}
+
+// WithLeaseHolder returns a context acting as the holder of token checkouts.
+// Tokens checked out by another holder cannot be read or updated, they return ErrCheckedOut.
+func WithLeaseHolder(ctx context.Context, holder string) context.Context {
+	return contextWithValue(ctx, leaseHolderContextKey{}, holder)
+}
+
+// LeaseHolderFromContext returns the holder set via WithLeaseHolder, if any
+func LeaseHolderFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	holder, ok := ctx.Value(leaseHolderContextKey{}).(string)
+	return holder, ok
+}
+
+// TokenCheckout grants the holder exclusive access to a token for the ttl, e.g. for a
+// shared admin account that must not be used concurrently. Until checked in or expired,
+// the token can only be read and updated with a context from WithLeaseHolder(ctx, holder),
+// other callers get ErrCheckedOut. Checking out again as the same holder renews the lease.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to check out
+// - holder: The holder of the lease, e.g. a user ID
+// - ttl: The duration of the lease
+//
+// Returns:
+// - lease: The granted lease
+// - err: An error wrapping ErrCheckedOut if another holder has the token, or an error if something went wrong
+func (store *storeImplementation) TokenCheckout(ctx context.Context, token string, holder string, ttl time.Duration) (TokenLease, error) {
+	ctx, span := store.traceStart(ctx, "TokenCheckout")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCheckout", token); err != nil {
+		return TokenLease{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if holder == "" {
+		return TokenLease{}, errors.New("holder is empty")
+	}
+
+	if ttl <= 0 {
+		return TokenLease{}, errors.New("ttl must be positive")
+	}
+
+	entry, err := store.tokenRevocationRecord(ctx, token)
+	if err != nil {
+		return TokenLease{}, err
+	}
+
+	if isRecordExpired(entry) {
+		return TokenLease{}, ErrTokenExpired
+	}
+
+	now := time.Now().UTC()
+	lease := TokenLease{
+		Token:        token,
+		Holder:       holder,
+		CheckedOutAt: carbon.CreateFromStdTime(now).ToDateTimeString(carbon.UTC),
+		ExpiresAt:    carbon.CreateFromStdTime(now.Add(ttl)).ToDateTimeString(carbon.UTC),
+	}
+
+	leaseJSON, err := json.Marshal(lease)
+	if err != nil {
+		return TokenLease{}, err
+	}
+
+	objectID := recordMetaObjectID(entry.GetID())
+
+	for attempt := 0; attempt < tokenCheckoutMaxAttempts; attempt++ {
+		existing, current, err := store.tokenLeaseFind(ctx, entry)
+		if err != nil {
+			return TokenLease{}, err
+		}
+
+		if existing == nil {
+			// The unique meta index lets a single concurrent checkout create the lease
+			if err := store.metaCreate(ctx, OBJECT_TYPE_RECORD, objectID, META_KEY_CHECKOUT, string(leaseJSON)); err != nil {
+				continue
+			}
+		} else {
+			if current.isActive() && current.Holder != holder {
+				return TokenLease{}, tokenCheckedOutError(current)
+			}
+
+			// Replaces the expired or own lease, unless it changed since it was read
+			result := store.metaDB(ctx).
+				Where("id = ? AND "+COLUMN_META_VALUE+" = ?", existing.ID, existing.Value).
+				Update(COLUMN_META_VALUE, string(leaseJSON))
+			if result.Error != nil {
+				return TokenLease{}, result.Error
+			}
+			if result.RowsAffected != 1 {
+				continue
+			}
+		}
+
+		store.emitEvent(ctx, EVENT_TYPE_TOKEN_CHECKED_OUT, token, map[string]string{
+			"holder":     holder,
+			"expires_at": lease.ExpiresAt,
+		})
+
+		return lease, nil
+	}
+
+	return TokenLease{}, ErrCheckedOut
+}
+
+// TokenCheckin releases the checkout of a token by the holder
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to check in
+// - holder: The holder of the lease
+//
+// Returns:
+// - err: ErrLeaseNotHeld if the token is not checked out by the holder, or an error if something went wrong
+func (store *storeImplementation) TokenCheckin(ctx context.Context, token string, holder string) error {
+	ctx, span := store.traceStart(ctx, "TokenCheckin")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCheckin", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, err := store.tokenRevocationRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	existing, current, err := store.tokenLeaseFind(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil || current.Holder != holder {
+		return ErrLeaseNotHeld
+	}
+
+	result := store.metaDB(ctx).
+		Where("id = ? AND "+COLUMN_META_VALUE+" = ?", existing.ID, existing.Value).
+		Delete(&gormVaultMeta{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// Taken over by another holder after the lease expired
+	if result.RowsAffected != 1 {
+		return ErrLeaseNotHeld
+	}
+
+	store.emitEvent(ctx, EVENT_TYPE_TOKEN_CHECKED_IN, token, map[string]string{
+		"holder": holder,
+	})
+
+	return nil
+}
+
+// tokenLeaseFind returns the lease meta of the record and its parsed lease, nil if not checked out
+func (store *storeImplementation) tokenLeaseFind(ctx context.Context, record RecordInterface) (*gormVaultMeta, TokenLease, error) {
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_CHECKOUT)
+	if err != nil || meta == nil {
+		return nil, TokenLease{}, err
+	}
+
+	lease, err := tokenLeaseParse(record, meta)
+	if err != nil {
+		return nil, TokenLease{}, err
+	}
+
+	return meta, lease, nil
+}
+
+// tokenLeaseParse parses the lease meta row of the record
+func tokenLeaseParse(record RecordInterface, meta *gormVaultMeta) (TokenLease, error) {
+	var lease TokenLease
+	if err := json.Unmarshal([]byte(meta.Value), &lease); err != nil {
+		return TokenLease{}, err
+	}
+	lease.Token = record.GetToken()
+
+	return lease, nil
+}
+
+// tokenCheckoutCheck returns an error wrapping ErrCheckedOut if the record is
+// checked out by another holder than the one of the context
+func (store *storeImplementation) tokenCheckoutCheck(ctx context.Context, record RecordInterface) error {
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_CHECKOUT)
+	if err != nil {
+		return err
+	}
+
+	return tokenCheckoutMetaCheck(ctx, record, meta)
+}
+
+// tokenCheckoutMetaCheck returns an error wrapping ErrCheckedOut if the lease meta row
+// of the record is active for another holder than the one of the context
+func tokenCheckoutMetaCheck(ctx context.Context, record RecordInterface, meta *gormVaultMeta) error {
+	if meta == nil {
+		return nil
+	}
+
+	lease, err := tokenLeaseParse(record, meta)
+	if err != nil || !lease.isActive() {
+		return err
+	}
+
+	if holder, ok := LeaseHolderFromContext(ctx); ok && holder == lease.Holder {
+		return nil
+	}
+
+	return tokenCheckedOutError(lease)
+}
+
+// tokenCheckedOutError returns the error wrapping ErrCheckedOut for the lease
+func tokenCheckedOutError(lease TokenLease) error {
+	return fmt.Errorf("%w by %s until %s", ErrCheckedOut, lease.Holder, lease.ExpiresAt)
+}
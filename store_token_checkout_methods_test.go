@@ -0,0 +1,114 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Store_TokenCheckout(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "admin account", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	lease, err := store.TokenCheckout(ctx, token, "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("TokenCheckout: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if lease.Holder != "alice" || lease.Token != token {
+		t.Fatalf("Expected the lease of alice received [%+v]", lease)
+	}
+
+	if _, err := store.TokenCheckout(ctx, token, "bob", time.Hour); !errors.Is(err, ErrCheckedOut) {
+		t.Fatalf("Expected ErrCheckedOut received [%v]", err)
+	}
+
+	if _, err := store.TokenRead(WithLeaseHolder(ctx, "bob"), token, password); !errors.Is(err, ErrCheckedOut) {
+		t.Fatalf("Expected ErrCheckedOut received [%v]", err)
+	}
+
+	if _, err := store.TokenRead(ctx, token, password); !errors.Is(err, ErrCheckedOut) {
+		t.Fatalf("Expected ErrCheckedOut received [%v]", err)
+	}
+
+	if err := store.TokenUpdate(ctx, token, "changed", password); !errors.Is(err, ErrCheckedOut) {
+		t.Fatalf("Expected ErrCheckedOut received [%v]", err)
+	}
+
+	err = store.TokensReadFunc(ctx, []string{token}, password, func(token string, value string) error {
+		return nil
+	})
+	if !errors.Is(err, ErrCheckedOut) {
+		t.Fatalf("Expected ErrCheckedOut received [%v]", err)
+	}
+
+	value, err := store.TokenRead(WithLeaseHolder(ctx, "alice"), token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "admin account" {
+		t.Fatalf("Expected [admin account] received [%v]", value)
+	}
+
+	// Renewed by the holder
+	if _, err := store.TokenCheckout(ctx, token, "alice", 2*time.Hour); err != nil {
+		t.Fatalf("TokenCheckout: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenCheckin(ctx, token, "bob"); !errors.Is(err, ErrLeaseNotHeld) {
+		t.Fatalf("Expected ErrLeaseNotHeld received [%v]", err)
+	}
+
+	if err := store.TokenCheckin(ctx, token, "alice"); err != nil {
+		t.Fatalf("TokenCheckin: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenRead(ctx, token, password); err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenCheckout(ctx, token, "bob", time.Hour); err != nil {
+		t.Fatalf("TokenCheckout: Expected [err] to be nil received [%v]", err.Error())
+	}
+}
+
+func Test_Store_TokenCheckout_Expired(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "admin account", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenCheckout(ctx, token, "alice", time.Second); err != nil {
+		t.Fatalf("TokenCheckout: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if _, err := store.TokenRead(ctx, token, password); err != nil {
+		t.Fatalf("TokenRead: Expected the expired lease to be ignored received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenCheckout(ctx, token, "bob", time.Hour); err != nil {
+		t.Fatalf("TokenCheckout: Expected the expired lease to be taken over received [%v]", err.Error())
+	}
+}
@@ -0,0 +1,88 @@
+package vaultstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// TokenCloneOptions contains optional parameters for cloning a token
+type TokenCloneOptions struct {
+	// NewPassword encrypts the clone with a different password.
+	// If empty, the source password is used.
+	NewPassword string
+
+	// ExpiresAt is the expiration time of the clone.
+	// If zero value, the expiration of the source token is copied.
+	ExpiresAt time.Time
+
+	// TokenLength is the total length of the new token.
+	// If zero, the length of the source token is used when valid, otherwise TOKEN_MAX_TOTAL_LENGTH.
+	TokenLength int
+}
+
+// TokenClone copies the decrypted value of a token into a new token,
+// e.g. to template credentials across environments
+//
+// The content type of the source token is copied to the clone.
+//
+// Parameters:
+// - ctx: The context
+// - srcToken: The token to clone
+// - password: The password of the source token
+// - opts: The clone options
+//
+// Returns:
+// - token: The new token
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenClone(ctx context.Context, srcToken string, password string, opts TokenCloneOptions) (string, error) {
+	ctx, span := store.traceStart(ctx, "TokenClone")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenClone", srcToken); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	newPassword := opts.NewPassword
+	if newPassword == "" {
+		newPassword = password
+	}
+
+	if err := store.validatePassword(newPassword); err != nil {
+		return "", err
+	}
+
+	entry, value, err := store.tokenReadRecord(ctx, srcToken, password)
+	if err != nil {
+		return "", err
+	}
+
+	createOptions := TokenCreateOptions{
+		ExpiresAt: opts.ExpiresAt,
+	}
+
+	if createOptions.ExpiresAt.IsZero() && internMaxDatetime(entry.GetExpiresAt()) != MAX_DATETIME {
+		createOptions.ExpiresAt = carbon.Parse(entry.GetExpiresAt(), carbon.UTC).StdTime()
+	}
+
+	contentType, err := store.recordMetaFind(ctx, entry, META_KEY_CONTENT_TYPE)
+	if err != nil {
+		return "", err
+	}
+
+	if contentType != nil {
+		createOptions.ContentType = contentType.Value
+	}
+
+	tokenLength := opts.TokenLength
+	if tokenLength == 0 {
+		tokenLength = len(srcToken)
+		if tokenLength < TOKEN_MIN_TOTAL_LENGTH || tokenLength > TOKEN_MAX_TOTAL_LENGTH {
+			tokenLength = TOKEN_MAX_TOTAL_LENGTH
+		}
+	}
+
+	return store.TokenCreate(ctx, value, newPassword, tokenLength, createOptions)
+}
@@ -0,0 +1,78 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_Store_TokenClone(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	newPassword := "another_password_that_is_long_enough_for_security"
+	expiresAt := time.Now().UTC().Add(time.Hour)
+
+	srcToken, err := store.TokenCreate(ctx, "credential", password, 24, TokenCreateOptions{
+		ContentType: CONTENT_TYPE_TEXT,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Clone with the same password, copying expiration and content type
+	cloneToken, err := store.TokenClone(ctx, srcToken, password, TokenCloneOptions{})
+	if err != nil {
+		t.Fatalf("TokenClone: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if cloneToken == srcToken || len(cloneToken) != 24 {
+		t.Fatalf("Expected a new token of length 24 received [%v]", cloneToken)
+	}
+
+	value, info, err := store.TokenReadWithInfo(ctx, cloneToken, password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "credential" {
+		t.Fatalf("Expected [credential] received [%v]", value)
+	}
+
+	if info.ContentType != CONTENT_TYPE_TEXT {
+		t.Fatalf("Expected content type [%v] received [%v]", CONTENT_TYPE_TEXT, info.ContentType)
+	}
+
+	if info.ExpiresAt == MAX_DATETIME {
+		t.Fatal("Expected the expiration to be copied")
+	}
+
+	// Clone with a different password
+	cloneToken, err = store.TokenClone(ctx, srcToken, password, TokenCloneOptions{NewPassword: newPassword})
+	if err != nil {
+		t.Fatalf("TokenClone: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenRead(ctx, cloneToken, password); err == nil {
+		t.Fatal("Expected the clone not to decrypt with the source password")
+	}
+
+	value, err = store.TokenRead(ctx, cloneToken, newPassword)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "credential" {
+		t.Fatalf("Expected [credential] received [%v]", value)
+	}
+
+	// Cloning requires the source password
+	if _, err := store.TokenClone(ctx, srcToken, newPassword, TokenCloneOptions{}); err == nil {
+		t.Fatal("Expected error cloning with the wrong password")
+	}
+}
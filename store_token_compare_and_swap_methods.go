@@ -0,0 +1,126 @@
+package vaultstore
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// ErrValueMismatch is returned when the current value of a token does not match the expected value
+var ErrValueMismatch = errors.New("token value does not match the expected value")
+
+// TokenCompareAndSwap updates the value of a token only if its current
+// decrypted value matches the expected value
+//
+// The update is conditional on the stored ciphertext not having changed since it was read,
+// so concurrent writers cannot overwrite each other's changes. The same checks as
+// TokenRead apply, and the comparison counts against the read limit of the token.
+//
+// # If the current value differs, or the token was modified concurrently, ErrValueMismatch is returned
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to update
+// - expectedValue: The value the token is expected to currently hold
+// - newValue: The new value
+// - password: The password to use for decryption and encryption
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenCompareAndSwap(ctx context.Context, token string, expectedValue string, newValue string, password string) error {
+	ctx, span := store.traceStart(ctx, "TokenCompareAndSwap")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCompareAndSwap", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := store.validatePassword(password); err != nil {
+		return err
+	}
+
+	entry, metas, err := store.tokenReadableRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	currentCiphertext := entry.GetValue()
+
+	currentValue, err := decode(ctx, currentCiphertext, password, store.cryptoConfig)
+	if err != nil {
+		return err
+	}
+
+	if tokenStreamed(metas[META_KEY_STREAM], currentValue) {
+		return ErrTokenStreamed
+	}
+
+	// The comparison reveals the value, it counts as a read
+	if err := store.tokenReadConsumeMeta(ctx, entry, metas[META_KEY_READS_REMAINING]); err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(currentValue), []byte(expectedValue)) != 1 {
+		return ErrValueMismatch
+	}
+
+	encodedValue, err := encode(ctx, newValue, password, store.cryptoConfig)
+	if err != nil {
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	swapped, err := store.recordValueSwap(ctx, entry.GetID(), currentCiphertext, encodedValue)
+	if err != nil {
+		return err
+	}
+
+	if !swapped {
+		return ErrValueMismatch
+	}
+
+	return store.tokenVersionArchive(ctx, entry)
+}
+
+// recordValueSwap stores the new encoded value of a record only if its stored value
+// still matches the ciphertext read before. Returns false if another writer changed it.
+func (store *storeImplementation) recordValueSwap(ctx context.Context, recordID string, currentCiphertext string, encodedValue string) (bool, error) {
+	// Chunked values are stored as a marker derived from the ciphertext
+	currentStoredValues := []string{currentCiphertext}
+	if store.isValueChunkingEnabled() && len(currentCiphertext) > store.valueChunkThreshold {
+		currentStoredValues = append(currentStoredValues, chunkedValueMarker(currentCiphertext, store.valueChunkThreshold))
+	}
+
+	storedValue, err := store.valueChunksWrite(ctx, recordID, encodedValue)
+	if err != nil {
+		return false, err
+	}
+
+	result := store.vaultDB(ctx).
+		Where(COLUMN_ID+" = ? AND "+COLUMN_VAULT_VALUE+" IN ?", recordID, currentStoredValues).
+		Updates(map[string]interface{}{
+			COLUMN_VAULT_VALUE: storedValue,
+			COLUMN_UPDATED_AT:  store.timestampValue(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+		})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	// Another writer changed the value between our read and update
+	if result.RowsAffected == 0 {
+		if isChunkedValue(storedValue) {
+			_ = store.valueChunkDB(ctx).
+				Where("record_id = ? AND value_hash = ?", recordID, valueChunkHash(encodedValue)).
+				Delete(&gormVaultChunk{}).Error
+		}
+		return false, nil
+	}
+
+	// Remove the chunks of the previous value
+	_, err = store.valueChunksCollect(ctx, recordID, storedValue)
+	return err == nil, err
+}
@@ -0,0 +1,117 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Store_TokenCompareAndSwap(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "v1", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Wrong expected value
+	err = store.TokenCompareAndSwap(ctx, token, "v0", "v2", password)
+	if !errors.Is(err, ErrValueMismatch) {
+		t.Fatalf("Expected ErrValueMismatch received [%v]", err)
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != "v1" {
+		t.Fatalf("Expected value to be unchanged [v1] received [%v]", value)
+	}
+
+	// Matching expected value
+	err = store.TokenCompareAndSwap(ctx, token, "v1", "v2", password)
+	if err != nil {
+		t.Fatalf("TokenCompareAndSwap: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err = store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != "v2" {
+		t.Fatalf("Expected [v2] received [%v]", value)
+	}
+
+	// The old expected value no longer matches
+	err = store.TokenCompareAndSwap(ctx, token, "v1", "v3", password)
+	if !errors.Is(err, ErrValueMismatch) {
+		t.Fatalf("Expected ErrValueMismatch received [%v]", err)
+	}
+}
+
+func Test_Store_TokenCompareAndSwap_Errors(t *testing.T) {
+	store, err := initStoreExpiresAtPastAllowed()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	if err := store.TokenCompareAndSwap(ctx, "", "a", "b", password); err == nil {
+		t.Fatal("Expected error for empty token")
+	}
+
+	if err := store.TokenCompareAndSwap(ctx, "tk_missing_token_x", "a", "b", password); err == nil {
+		t.Fatal("Expected error for missing token")
+	}
+
+	token, err := store.TokenCreate(ctx, "v1", password, 20, TokenCreateOptions{
+		ExpiresAt: time.Now().UTC().Add(-1 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenCompareAndSwap(ctx, token, "v1", "v2", password)
+	if !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("Expected ErrTokenExpired received [%v]", err)
+	}
+
+	// The comparison counts against the read limit
+	limited, err := store.TokenCreate(ctx, "v1", password, 20, TokenCreateOptions{MaxReads: 1})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenCompareAndSwap(ctx, limited, "v1", "v2", password); err != nil {
+		t.Fatalf("TokenCompareAndSwap: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenCompareAndSwap(ctx, limited, "v2", "v3", password)
+	if !errors.Is(err, ErrTokenConsumed) {
+		t.Fatalf("Expected ErrTokenConsumed received [%v]", err)
+	}
+
+	// High-security tokens require a break-glass grant
+	guarded, err := store.TokenCreate(ctx, "v1", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenBreakGlassRequire(ctx, guarded, true); err != nil {
+		t.Fatalf("TokenBreakGlassRequire: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenCompareAndSwap(ctx, guarded, "v1", "v2", password)
+	if !errors.Is(err, ErrBreakGlassRequired) {
+		t.Fatalf("Expected ErrBreakGlassRequired received [%v]", err)
+	}
+}
@@ -0,0 +1,155 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/samber/lo"
+)
+
+// Common content types for TokenCreateOptions.ContentType
+const (
+	CONTENT_TYPE_BINARY_BASE64 = "application/octet-stream;base64"
+	CONTENT_TYPE_JSON          = "application/json"
+	CONTENT_TYPE_PEM           = "application/x-pem-file"
+	CONTENT_TYPE_TEXT          = "text/plain"
+)
+
+// contentTypeMaxLength is the maximum length of a content type
+const contentTypeMaxLength = 255
+
+// TokenInfo describes a token, without its value
+type TokenInfo struct {
+	Token       string
+	ContentType string // Empty if no content type was set
+	CreatedAt   string
+	UpdatedAt   string
+	ExpiresAt   string
+}
+
+// TokenReadWithInfo reads the value of a token together with its info,
+// such as the content type set on creation
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read
+// - password: The password to use for decryption
+//
+// Returns:
+// - value: The decrypted value
+// - info: The token info
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenReadWithInfo(ctx context.Context, token string, password string) (string, TokenInfo, error) {
+	ctx, span := store.traceStart(ctx, "TokenReadWithInfo")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenReadWithInfo", token); err != nil {
+		return "", TokenInfo{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, value, err := store.tokenReadRecord(ctx, token, password)
+	if err != nil {
+		return "", TokenInfo{}, err
+	}
+
+	meta, err := store.recordMetaFind(ctx, entry, META_KEY_CONTENT_TYPE)
+	if err != nil {
+		return "", TokenInfo{}, err
+	}
+
+	info := TokenInfo{
+		Token:     entry.GetToken(),
+		CreatedAt: entry.GetCreatedAt(),
+		UpdatedAt: entry.GetUpdatedAt(),
+		ExpiresAt: entry.GetExpiresAt(),
+	}
+
+	if meta != nil {
+		info.ContentType = meta.Value
+	}
+
+	return value, info, nil
+}
+
+// TokenValueInfo is a value read by TokensReadWithInfo, together with its timestamps
+type TokenValueInfo struct {
+	Value     string
+	CreatedAt string
+	ExpiresAt string // MAX_DATETIME if the token never expires
+}
+
+// TokensReadWithInfo reads multiple tokens at once like TokensRead, returning the
+// creation and expiration time alongside each value, e.g. for session sweeps.
+// Expired tokens are skipped.
+//
+// Parameters:
+// - ctx: The context
+// - tokens: The list of tokens to read
+// - password: The password to use for decryption
+//
+// Returns:
+// - values: The values with their timestamps, by token
+// - err: An error if something went wrong
+func (store *storeImplementation) TokensReadWithInfo(ctx context.Context, tokens []string, password string) (map[string]TokenValueInfo, error) {
+	ctx, span := store.traceStart(ctx, "TokensReadWithInfo", traceRecordCount(len(tokens)))
+	defer span.End()
+
+	if err := store.operationAllowTokens(ctx, "TokensReadWithInfo", tokens); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entries, err := store.tokensReadableRecords(ctx, tokens)
+	if err != nil {
+		return map[string]TokenValueInfo{}, err
+	}
+
+	entriesByToken := lo.KeyBy(entries, func(entry RecordInterface) string {
+		return entry.GetToken()
+	})
+
+	values := make(map[string]TokenValueInfo, len(entries))
+	err = store.tokensDecode(ctx, entries, password, func(token string, value string) error {
+		entry := entriesByToken[token]
+		values[token] = TokenValueInfo{
+			Value:     value,
+			CreatedAt: entry.GetCreatedAt(),
+			ExpiresAt: entry.GetExpiresAt(),
+		}
+		return nil
+	})
+	if err != nil {
+		return map[string]TokenValueInfo{}, err
+	}
+
+	return values, nil
+}
+
+// validateTokenCreateOptions checks the options before a token is created
+func validateTokenCreateOptions(options []TokenCreateOptions) error {
+	if len(options) > 0 && options[0].MaxReads < 0 {
+		return ErrMaxReadsInvalid
+	}
+
+	if len(options) > 0 && len(options[0].ContentType) > contentTypeMaxLength {
+		return errors.New("content type is too long")
+	}
+	return nil
+}
+
+// tokenContentTypeCreate stores the content type of a newly created record.
+// If it cannot be stored, the record is removed so the token is not left half created.
+func (store *storeImplementation) tokenContentTypeCreate(ctx context.Context, record RecordInterface, contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	err := store.metaCreate(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(record.GetID()), META_KEY_CONTENT_TYPE, contentType)
+	if err != nil {
+		_ = store.RecordDeleteByID(ctx, record.GetID())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package vaultstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Store_TokenReadWithInfo(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, `{"key":"value"}`, password, 20, TokenCreateOptions{
+		ContentType: CONTENT_TYPE_JSON,
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, info, err := store.TokenReadWithInfo(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != `{"key":"value"}` {
+		t.Fatalf("Expected value [{\"key\":\"value\"}] received [%v]", value)
+	}
+
+	if info.Token != token {
+		t.Fatalf("Expected token [%v] received [%v]", token, info.Token)
+	}
+
+	if info.ContentType != CONTENT_TYPE_JSON {
+		t.Fatalf("Expected content type [%v] received [%v]", CONTENT_TYPE_JSON, info.ContentType)
+	}
+
+	// Tokens created without a content type return an empty one
+	err = store.TokenCreateCustom(ctx, "custom_token_info", "plain", password)
+	if err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, info, err = store.TokenReadWithInfo(ctx, "custom_token_info", password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if info.ContentType != "" {
+		t.Fatalf("Expected empty content type received [%v]", info.ContentType)
+	}
+
+	// Too long content types are rejected before the token is created
+	_, err = store.TokenCreate(ctx, "value", password, 20, TokenCreateOptions{
+		ContentType: strings.Repeat("x", contentTypeMaxLength+1),
+	})
+	if err == nil {
+		t.Fatal("Expected error for too long content type")
+	}
+}
+
+func Test_Store_TokensReadWithInfo(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token1, err := store.TokenCreate(ctx, "value1", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	token2, err := store.TokenCreate(ctx, "value2", password, 20, TokenCreateOptions{
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	values, err := store.TokensReadWithInfo(ctx, []string{token1, token2}, password)
+	if err != nil {
+		t.Fatalf("TokensReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values received [%v]", len(values))
+	}
+
+	if values[token1].Value != "value1" || values[token2].Value != "value2" {
+		t.Fatalf("Unexpected values [%v]", values)
+	}
+
+	if values[token1].CreatedAt == "" || values[token2].CreatedAt == "" {
+		t.Fatalf("Expected CreatedAt to be set received [%v]", values)
+	}
+
+	if !strings.HasPrefix(strings.ReplaceAll(values[token2].ExpiresAt, "T", " "), expiresAt.Format("2006-01-02 15:04")) {
+		t.Fatalf("Expected ExpiresAt [%v] received [%v]", expiresAt, values[token2].ExpiresAt)
+	}
+
+	if values[token1].ExpiresAt == values[token2].ExpiresAt {
+		t.Fatalf("Expected token without expiry to differ, received [%v]", values[token1].ExpiresAt)
+	}
+}
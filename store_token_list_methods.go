@@ -0,0 +1,191 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// TOKEN_LIST_LIMIT_DEFAULT is the page size of TokenList when no limit is set
+const TOKEN_LIST_LIMIT_DEFAULT = 100
+
+// TOKEN_LIST_LIMIT_MAX is the largest page size of TokenList
+const TOKEN_LIST_LIMIT_MAX = 1000
+
+// ErrTokenQueryInvalid is returned by TokenList for invalid query options
+var ErrTokenQueryInvalid = errors.New("token query options are invalid")
+
+// TokenQueryOptions selects and paginates the tokens listed by TokenList.
+// Zero values do not filter.
+type TokenQueryOptions struct {
+	// Prefix selects the tokens starting with the prefix, matched as by TokensCountByPrefix
+	Prefix string
+
+	// CreatedAfter and CreatedBefore select the tokens created in the range, bounds excluded
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	// ExpiresAfter and ExpiresBefore select the tokens expiring in the range, bounds excluded.
+	// Tokens that never expire are after any time.
+	ExpiresAfter  time.Time
+	ExpiresBefore time.Time
+
+	// ExpiredInclude includes the expired tokens
+	ExpiredInclude bool
+
+	// SoftDeletedInclude includes the soft deleted tokens
+	SoftDeletedInclude bool
+
+	// SortOrder orders the tokens by creation time, ASC (default) or DESC
+	SortOrder string
+
+	// Limit is the page size, defaults to TOKEN_LIST_LIMIT_DEFAULT, at most TOKEN_LIST_LIMIT_MAX
+	Limit int
+
+	// Offset is the number of tokens skipped
+	Offset int
+}
+
+// TokenListItem is a token listed by TokenList, with its non-sensitive timestamps and size
+type TokenListItem struct {
+	Token         string
+	CreatedAt     string
+	UpdatedAt     string
+	ExpiresAt     string // MAX_DATETIME if the token never expires
+	SoftDeletedAt string // MAX_DATETIME if the token is not soft deleted
+	ValueSize     int64  // size of the ciphertext in bytes, see RecordSizeHistogram
+}
+
+// TokenList lists the tokens matching the options a page at a time, e.g. for
+// admin interfaces. The values are neither read nor decrypted, so no password is needed.
+//
+// Example:
+//
+//	page, err := store.TokenList(ctx, vaultstore.TokenQueryOptions{Prefix: "sess_", Limit: 50, Offset: 100})
+//
+// Parameters:
+// - ctx: The context
+// - options: The filters and the page to list
+//
+// Returns:
+// - items: The tokens of the page, ordered by creation time
+// - err: ErrTokenQueryInvalid, or an error if something went wrong
+func (store *storeImplementation) TokenList(ctx context.Context, options TokenQueryOptions) ([]TokenListItem, error) {
+	ctx, span := store.traceStart(ctx, "TokenList")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenList", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return []TokenListItem{}, err
+	}
+
+	if err := validateTokenQueryOptions(options); err != nil {
+		return []TokenListItem{}, err
+	}
+
+	limit := options.Limit
+	if limit == 0 {
+		limit = TOKEN_LIST_LIMIT_DEFAULT
+	}
+
+	sortOrder := ASC
+	if strings.EqualFold(options.SortOrder, DESC) {
+		sortOrder = DESC
+	}
+
+	query := RecordQuery().
+		SetColumns([]string{COLUMN_ID, COLUMN_VAULT_TOKEN, COLUMN_CREATED_AT, COLUMN_UPDATED_AT, COLUMN_EXPIRES_AT, COLUMN_SOFT_DELETED_AT}).
+		SetExpiredExclude(!options.ExpiredInclude)
+	if options.SoftDeletedInclude {
+		query.SetSoftDeletedInclude(true)
+	}
+
+	db := store.recordQueryFilter(store.vaultDB(ctx), query).
+		Select(query.GetColumns())
+
+	if options.Prefix != "" {
+		db = db.Where(COLUMN_VAULT_TOKEN+" LIKE ? ESCAPE '"+likeEscapeChar+"'", likePrefixPattern(options.Prefix))
+	}
+
+	if !options.CreatedAfter.IsZero() {
+		db = db.Where(COLUMN_CREATED_AT+" > ?", tokenQueryTime(options.CreatedAfter))
+	}
+
+	if !options.CreatedBefore.IsZero() {
+		db = db.Where(COLUMN_CREATED_AT+" < ?", tokenQueryTime(options.CreatedBefore))
+	}
+
+	if !options.ExpiresAfter.IsZero() {
+		db = db.Where(COLUMN_EXPIRES_AT+" > ?", tokenQueryTime(options.ExpiresAfter))
+	}
+
+	if !options.ExpiresBefore.IsZero() {
+		db = db.Where(COLUMN_EXPIRES_AT+" < ?", tokenQueryTime(options.ExpiresBefore))
+	}
+
+	// The ID breaks the ties of tokens created in the same second, keeping the pages stable
+	db = db.Order(COLUMN_CREATED_AT + " " + sortOrder).
+		Order(COLUMN_ID + " " + sortOrder).
+		Limit(limit).
+		Offset(options.Offset)
+
+	gormRecords, err := store.recordsFind(db)
+	if err != nil {
+		return []TokenListItem{}, err
+	}
+
+	recordIDs := make([]string, len(gormRecords))
+	for i := range gormRecords {
+		recordIDs[i] = gormRecords[i].ID
+	}
+
+	sizes, err := store.recordValueSizes(ctx, recordIDs)
+	if err != nil {
+		return []TokenListItem{}, err
+	}
+
+	items := make([]TokenListItem, len(gormRecords))
+	for i := range gormRecords {
+		record := store.recordFromGorm(&gormRecords[i])
+		items[i] = TokenListItem{
+			Token:         record.GetToken(),
+			CreatedAt:     record.GetCreatedAt(),
+			UpdatedAt:     record.GetUpdatedAt(),
+			ExpiresAt:     record.GetExpiresAt(),
+			SoftDeletedAt: record.GetSoftDeletedAt(),
+			ValueSize:     sizes[record.GetID()],
+		}
+	}
+
+	return items, nil
+}
+
+// validateTokenQueryOptions checks the page and sort order of the token query options
+func validateTokenQueryOptions(options TokenQueryOptions) error {
+	if options.Limit < 0 || options.Limit > TOKEN_LIST_LIMIT_MAX {
+		return fmt.Errorf("%w: limit must be between 0 and %d", ErrTokenQueryInvalid, TOKEN_LIST_LIMIT_MAX)
+	}
+
+	if options.Offset < 0 {
+		return fmt.Errorf("%w: offset cannot be negative", ErrTokenQueryInvalid)
+	}
+
+	if options.SortOrder != "" && !strings.EqualFold(options.SortOrder, ASC) && !strings.EqualFold(options.SortOrder, DESC) {
+		return fmt.Errorf("%w: sort order must be 'asc' or 'desc'", ErrTokenQueryInvalid)
+	}
+
+	return nil
+}
+
+// tokenQueryTime formats a time of the token query options as stored in the vault table
+func tokenQueryTime(t time.Time) string {
+	return carbon.CreateFromStdTime(t).ToDateTimeString(carbon.UTC)
+}
@@ -0,0 +1,91 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Store_TokenList(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	tokens := []string{"sess_a", "sess_b", "sess_c"}
+	for _, token := range tokens {
+		if err := store.TokenCreateCustom(ctx, token, "value", password); err != nil {
+			t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	if err := store.TokenCreateCustom(ctx, "api_a", "value", password, TokenCreateOptions{ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenSoftDelete(ctx, "sess_c"); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	items, err := store.TokenList(ctx, TokenQueryOptions{Prefix: "sess_"})
+	if err != nil {
+		t.Fatalf("TokenList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Tokens created in the same second are ordered by ID, not by token
+	if len(items) != 2 || items[0].Token == "sess_c" || items[1].Token == "sess_c" {
+		t.Fatalf("Expected [sess_a sess_b] received [%+v]", items)
+	}
+
+	if items[0].ExpiresAt != MAX_DATETIME || items[0].SoftDeletedAt != MAX_DATETIME || items[0].CreatedAt == "" {
+		t.Fatalf("Expected the timestamps of a live token received [%+v]", items[0])
+	}
+
+	// Pages
+	page, err := store.TokenList(ctx, TokenQueryOptions{Prefix: "sess_", SoftDeletedInclude: true, Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("TokenList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(page) != 1 {
+		t.Fatalf("Expected [1] token on the last page received [%+v]", page)
+	}
+
+	all, err := store.TokenList(ctx, TokenQueryOptions{Prefix: "sess_", SoftDeletedInclude: true})
+	if err != nil {
+		t.Fatalf("TokenList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(all) != 3 || all[2] != page[0] {
+		t.Fatalf("Expected the last page to hold the third token received [%+v] [%+v]", all, page)
+	}
+
+	// Date ranges
+	expiring, err := store.TokenList(ctx, TokenQueryOptions{ExpiresBefore: time.Now().Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("TokenList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(expiring) != 1 || expiring[0].Token != "api_a" {
+		t.Fatalf("Expected [api_a] received [%+v]", expiring)
+	}
+
+	future, err := store.TokenList(ctx, TokenQueryOptions{CreatedAfter: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("TokenList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(future) != 0 {
+		t.Fatalf("Expected no tokens received [%+v]", future)
+	}
+
+	for _, options := range []TokenQueryOptions{{Limit: -1}, {Limit: TOKEN_LIST_LIMIT_MAX + 1}, {Offset: -1}, {SortOrder: "random"}} {
+		if _, err := store.TokenList(ctx, options); !errors.Is(err, ErrTokenQueryInvalid) {
+			t.Fatalf("Expected [ErrTokenQueryInvalid] for [%+v] received [%v]", options, err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package vaultstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrTokenNotMap is returned when a map operation is used on a token not holding a map
+var ErrTokenNotMap = errors.New("token value is not a map")
+
+// ErrTokenMapKeyNotFound is returned when the map of a token does not have the field
+var ErrTokenMapKeyNotFound = errors.New("token map does not have the field")
+
+// ErrTokenPatchConflict is returned when TokenPatchKey keeps losing to concurrent updates
+var ErrTokenPatchConflict = errors.New("token was modified concurrently too many times")
+
+// tokenPatchMaxAttempts is the number of times TokenPatchKey retries after a concurrent update
+const tokenPatchMaxAttempts = 5
+
+// TokenCreateMap creates a token holding several values, e.g. the username, password and
+// host of a credential. The map is encrypted as a single JSON document, its fields are
+// read and updated with TokenReadKey and TokenPatchKey.
+//
+// Parameters:
+// - ctx: The context
+// - values: The values to store
+// - password: The password to use for encryption
+// - options: The create options, the content type defaults to CONTENT_TYPE_JSON
+//
+// Returns:
+// - token: The new token
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenCreateMap(ctx context.Context, values map[string]string, password string, options ...TokenCreateOptions) (string, error) {
+	ctx, span := store.traceStart(ctx, "TokenCreateMap")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCreateMap", ""); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	value, err := tokenMapEncode(values)
+	if err != nil {
+		return "", err
+	}
+
+	option := TokenCreateOptions{}
+	if len(options) > 0 {
+		option = options[0]
+	}
+
+	if option.ContentType == "" {
+		option.ContentType = CONTENT_TYPE_JSON
+	}
+
+	return store.TokenCreate(ctx, value, password, TOKEN_MAX_TOTAL_LENGTH, option)
+}
+
+// TokenReadMap reads all the fields of a token created with TokenCreateMap
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read
+// - password: The password to use for decryption
+//
+// Returns:
+// - values: The fields of the token
+// - err: ErrTokenNotMap if the token does not hold a map, or an error if something went wrong
+func (store *storeImplementation) TokenReadMap(ctx context.Context, token string, password string) (map[string]string, error) {
+	ctx, span := store.traceStart(ctx, "TokenReadMap")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenReadMap", token); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	_, decoded, err := store.tokenReadRecord(ctx, token, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenMapDecode(decoded)
+}
+
+// TokenReadKey reads a single field of a token created with TokenCreateMap
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read
+// - field: The field to read
+// - password: The password to use for decryption
+//
+// Returns:
+// - value: The value of the field
+// - err: ErrTokenMapKeyNotFound if the field does not exist, or an error if something went wrong
+func (store *storeImplementation) TokenReadKey(ctx context.Context, token string, field string, password string) (string, error) {
+	ctx, span := store.traceStart(ctx, "TokenReadKey")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenReadKey", token); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	values, err := store.TokenReadMap(ctx, token, password)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := values[field]
+	if !ok {
+		return "", ErrTokenMapKeyNotFound
+	}
+
+	return value, nil
+}
+
+// TokenPatchKey sets a single field of a token created with TokenCreateMap,
+// keeping the other fields. Concurrent patches of different fields are not lost:
+// the write is conditional on the value not having changed since it was read,
+// and retried on conflict.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to update
+// - field: The field to set
+// - value: The new value of the field
+// - password: The password to use for decryption and encryption
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenPatchKey(ctx context.Context, token string, field string, value string, password string) error {
+	ctx, span := store.traceStart(ctx, "TokenPatchKey")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenPatchKey", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := store.validatePassword(password); err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt < tokenPatchMaxAttempts; attempt++ {
+		entry, decoded, err := store.tokenReadRecord(ctx, token, password)
+		if err != nil {
+			return err
+		}
+
+		values, err := tokenMapDecode(decoded)
+		if err != nil {
+			return err
+		}
+
+		values[field] = value
+
+		newValue, err := tokenMapEncode(values)
+		if err != nil {
+			return err
+		}
+
+		encodedValue, err := encode(ctx, newValue, password, store.cryptoConfig)
+		if err != nil {
+			return fmt.Errorf("failed to encode value: %w", err)
+		}
+
+		swapped, err := store.recordValueSwap(ctx, entry.GetID(), entry.GetValue(), encodedValue)
+		if err != nil {
+			return err
+		}
+
+		if swapped {
+			return store.tokenVersionArchive(ctx, entry)
+		}
+	}
+
+	return ErrTokenPatchConflict
+}
+
+// tokenMapEncode serializes the fields of a map token
+func tokenMapEncode(values map[string]string) (string, error) {
+	if values == nil {
+		values = map[string]string{}
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encoded), nil
+}
+
+// tokenMapDecode parses the fields of a map token
+func tokenMapDecode(value string) (map[string]string, error) {
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return nil, ErrTokenNotMap
+	}
+
+	return values, nil
+}
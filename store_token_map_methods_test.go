@@ -0,0 +1,129 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func Test_Store_TokenMap(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreateMap(ctx, map[string]string{
+		"username": "admin",
+		"password": "secret",
+	}, password)
+	if err != nil {
+		t.Fatalf("TokenCreateMap: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	username, err := store.TokenReadKey(ctx, token, "username", password)
+	if err != nil {
+		t.Fatalf("TokenReadKey: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if username != "admin" {
+		t.Fatalf("Expected [admin] received [%v]", username)
+	}
+
+	if _, err := store.TokenReadKey(ctx, token, "host", password); !errors.Is(err, ErrTokenMapKeyNotFound) {
+		t.Fatalf("Expected ErrTokenMapKeyNotFound received [%v]", err)
+	}
+
+	_, info, err := store.TokenReadWithInfo(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadWithInfo: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if info.ContentType != CONTENT_TYPE_JSON {
+		t.Fatalf("Expected [%v] received [%v]", CONTENT_TYPE_JSON, info.ContentType)
+	}
+
+	if err := store.TokenPatchKey(ctx, token, "password", "rotated", password); err != nil {
+		t.Fatalf("TokenPatchKey: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	values, err := store.TokenReadMap(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadMap: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if values["username"] != "admin" || values["password"] != "rotated" {
+		t.Fatalf("Expected the patched map received [%v]", values)
+	}
+
+	plainToken, err := store.TokenCreate(ctx, "plain value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenReadKey(ctx, plainToken, "username", password); !errors.Is(err, ErrTokenNotMap) {
+		t.Fatalf("Expected ErrTokenNotMap received [%v]", err)
+	}
+
+	if err := store.TokenPatchKey(ctx, plainToken, "username", "admin", password); !errors.Is(err, ErrTokenNotMap) {
+		t.Fatalf("Expected ErrTokenNotMap received [%v]", err)
+	}
+}
+
+func Test_Store_TokenPatchKey_Concurrent(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+	db.SetMaxOpenConns(1)
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_token_map",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreateMap(ctx, map[string]string{}, password)
+	if err != nil {
+		t.Fatalf("TokenCreateMap: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	fields := []string{"a", "b", "c"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(fields))
+	for _, field := range fields {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- store.TokenPatchKey(ctx, token, field, "value_"+field, password)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("TokenPatchKey: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	values, err := store.TokenReadMap(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadMap: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(values) != len(fields) {
+		t.Fatalf("Expected no patch to be lost received [%v]", values)
+	}
+}
@@ -0,0 +1,81 @@
+package vaultstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+)
+
+// TOKEN_MASK replaces the masked fields of TokenReadMasked
+const TOKEN_MASK = "****"
+
+// TokenReadMasked reads a map or JSON token with the values of the given fields replaced
+// by TOKEN_MASK, e.g. for support tooling that must not display the full secrets.
+// Fields are matched by name at any depth of the document, whatever their value.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read
+// - password: The password to use for decryption
+// - maskFields: The names of the fields to mask
+//
+// Returns:
+// - value: The JSON document with the fields masked
+// - err: ErrTokenNotMap if the token does not hold a JSON object, or an error if something went wrong
+func (store *storeImplementation) TokenReadMasked(ctx context.Context, token string, password string, maskFields []string) (string, error) {
+	ctx, span := store.traceStart(ctx, "TokenReadMasked")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenReadMasked", token); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	_, decoded, err := store.tokenReadRecord(ctx, token, password)
+	if err != nil {
+		return "", err
+	}
+
+	return jsonMaskFields(decoded, maskFields)
+}
+
+// jsonMaskFields replaces the values of the fields of a JSON object with TOKEN_MASK
+func jsonMaskFields(value string, maskFields []string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.UseNumber()
+
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil || document == nil {
+		return "", ErrTokenNotMap
+	}
+
+	masked, err := json.Marshal(jsonMaskValue(document, maskFields))
+	if err != nil {
+		return "", err
+	}
+
+	return string(masked), nil
+}
+
+// jsonMaskValue masks the fields of the objects nested in a decoded JSON value
+func jsonMaskValue(value interface{}, maskFields []string) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range typed {
+			if slices.Contains(maskFields, key) {
+				typed[key] = TOKEN_MASK
+				continue
+			}
+			typed[key] = jsonMaskValue(nested, maskFields)
+		}
+		return typed
+	case []interface{}:
+		for i, nested := range typed {
+			typed[i] = jsonMaskValue(nested, maskFields)
+		}
+		return typed
+	default:
+		return value
+	}
+}
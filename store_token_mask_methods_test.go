@@ -0,0 +1,59 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_jsonMaskFields(t *testing.T) {
+	masked, err := jsonMaskFields(`{"user":"admin","password":"secret","port":5432,"replicas":[{"host":"db2","password":"other"}]}`, []string{"password"})
+	if err != nil {
+		t.Fatalf("jsonMaskFields: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	expected := `{"password":"****","port":5432,"replicas":[{"host":"db2","password":"****"}],"user":"admin"}`
+	if masked != expected {
+		t.Fatalf("Expected [%v] received [%v]", expected, masked)
+	}
+
+	if _, err := jsonMaskFields(`["password"]`, []string{"password"}); !errors.Is(err, ErrTokenNotMap) {
+		t.Fatalf("Expected ErrTokenNotMap received [%v]", err)
+	}
+}
+
+func Test_Store_TokenReadMasked(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreateMap(ctx, map[string]string{
+		"username": "admin",
+		"password": "secret",
+	}, password)
+	if err != nil {
+		t.Fatalf("TokenCreateMap: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	masked, err := store.TokenReadMasked(ctx, token, password, []string{"password"})
+	if err != nil {
+		t.Fatalf("TokenReadMasked: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if masked != `{"password":"****","username":"admin"}` {
+		t.Fatalf("Expected the password to be masked received [%v]", masked)
+	}
+
+	plainToken, err := store.TokenCreate(ctx, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenReadMasked(ctx, plainToken, password, []string{"password"}); !errors.Is(err, ErrTokenNotMap) {
+		t.Fatalf("Expected ErrTokenNotMap received [%v]", err)
+	}
+}
@@ -0,0 +1,254 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dracory/sb"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTokenConsumed is returned when reading a token whose read limit (TokenCreateOptions.MaxReads) is exhausted
+	ErrTokenConsumed = errors.New("token has been consumed")
+	// ErrMaxReadsInvalid is returned when creating a token with a negative MaxReads
+	ErrMaxReadsInvalid = errors.New("max reads cannot be negative")
+)
+
+// tokenReadsRemainingCreate stores the read limit of a newly created record, if any
+func (store *storeImplementation) tokenReadsRemainingCreate(ctx context.Context, record RecordInterface, maxReads int) error {
+	if maxReads <= 0 {
+		return nil
+	}
+
+	return store.metaCreate(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(record.GetID()), META_KEY_READS_REMAINING, strconv.Itoa(maxReads))
+}
+
+// tokenReadConsume counts a read of a limited-use record, returning ErrTokenConsumed
+// if no read is left. Records without a read limit are not affected.
+//
+// The counter is decremented with a compare-and-swap on the stored value, so
+// concurrent readers never share a read. A lost race means another reader
+// consumed a read, so the loop ends at the latest once the counter reaches zero.
+func (store *storeImplementation) tokenReadConsume(ctx context.Context, record RecordInterface) error {
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_READS_REMAINING)
+	if err != nil {
+		return err
+	}
+
+	return store.tokenReadConsumeMeta(ctx, record, meta)
+}
+
+// tokenReadConsumeMeta counts a read as tokenReadConsume, starting from the read counter
+// meta row already loaded, which is read again after a lost race
+func (store *storeImplementation) tokenReadConsumeMeta(ctx context.Context, record RecordInterface, meta *gormVaultMeta) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if meta == nil {
+			return nil
+		}
+
+		remaining, err := strconv.Atoi(meta.Value)
+		if err != nil {
+			return fmt.Errorf("invalid remaining reads of record %s: %w", record.GetID(), err)
+		}
+
+		if remaining <= 0 {
+			return ErrTokenConsumed
+		}
+
+		result := store.metaDB(ctx).
+			Where("id = ? AND "+COLUMN_META_VALUE+" = ?", meta.ID, meta.Value).
+			Update(COLUMN_META_VALUE, strconv.Itoa(remaining-1))
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected > 0 {
+			return nil
+		}
+
+		meta, err = store.recordMetaFind(ctx, record, META_KEY_READS_REMAINING)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// LimitedUseStats counts the limited-use tokens (TokenCreateOptions.MaxReads) by state
+type LimitedUseStats struct {
+	// Active is the number of tokens with reads left, not expired
+	Active int64
+	// Consumed is the number of tokens without reads left, not yet deleted
+	Consumed int64
+	// Expired is the number of expired tokens with reads left, not yet deleted
+	Expired int64
+}
+
+// TokensConsumedDelete permanently deletes the limited-use tokens (TokenCreateOptions.MaxReads)
+// that were consumed or expired, with their chunks and metadata. Unlike the soft delete
+// of expired tokens, no ciphertext of a one-time secret is kept.
+//
+// The records are deleted before their read counters, so a cleanup interrupted
+// halfway never leaves a record without its read limit.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - count: The number of records deleted
+// - err: An error if something went wrong
+func (store *storeImplementation) TokensConsumedDelete(ctx context.Context) (count int64, err error) {
+	ctx, span := store.traceStart(ctx, "TokensConsumedDelete")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokensConsumedDelete", ""); err != nil {
+		return 0, err
+	}
+	ctx = withNamespacesAll(store.operationAllowedContext(ctx))
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	lastObjectID := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		var objectIDs []string
+		err := store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_RECORD, META_KEY_READS_REMAINING).
+			Where(COLUMN_OBJECT_ID+" > ?", lastObjectID).
+			Order(COLUMN_OBJECT_ID+" ASC").
+			Limit(maxRecordsInMemory).
+			Pluck(COLUMN_OBJECT_ID, &objectIDs).Error
+		if err != nil {
+			return count, err
+		}
+
+		if len(objectIDs) == 0 {
+			return count, nil
+		}
+		lastObjectID = objectIDs[len(objectIDs)-1]
+
+		var consumedObjectIDs []string
+		err = store.metaDB(ctx).
+			Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_RECORD, META_KEY_READS_REMAINING).
+			Where(COLUMN_OBJECT_ID+" IN ? AND "+COLUMN_META_VALUE+" = ?", objectIDs, "0").
+			Pluck(COLUMN_OBJECT_ID, &consumedObjectIDs).Error
+		if err != nil {
+			return count, err
+		}
+
+		recordIDs := recordIDsFromMetaObjectIDs(objectIDs)
+		consumedIDs := recordIDsFromMetaObjectIDs(consumedObjectIDs)
+
+		deleted, err := store.recordsDeleteBatched(ctx, func(db *gorm.DB) *gorm.DB {
+			return db.Where(COLUMN_ID+" IN ?", recordIDs).
+				Where(db.Session(&gorm.Session{NewDB: true}).
+					Where(COLUMN_ID+" IN ?", consumedIDs).
+					Or(COLUMN_EXPIRES_AT+" < ? AND "+COLUMN_EXPIRES_AT+" <> ?", now, sb.MAX_DATETIME))
+		})
+		count += deleted
+		if err != nil {
+			return count, err
+		}
+
+		// Counters left by records deleted by other means
+		if err := store.recordMetaDelete(ctx, consumedIDs); err != nil {
+			return count, err
+		}
+	}
+}
+
+// LimitedUseStats counts the limited-use tokens by state, soft deleted ones included,
+// e.g. to monitor that TokensConsumedDelete keeps up
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - stats: The number of active, consumed and expired limited-use tokens
+// - err: An error if something went wrong
+func (store *storeImplementation) LimitedUseStats(ctx context.Context) (stats LimitedUseStats, err error) {
+	ctx, span := store.traceStart(ctx, "LimitedUseStats")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "LimitedUseStats", ""); err != nil {
+		return LimitedUseStats{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	lastObjectID := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		var metas []gormVaultMeta
+		err := store.metaDB(ctx).
+			Select(COLUMN_OBJECT_ID, COLUMN_META_VALUE).
+			Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_RECORD, META_KEY_READS_REMAINING).
+			Where(COLUMN_OBJECT_ID+" > ?", lastObjectID).
+			Order(COLUMN_OBJECT_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&metas).Error
+		if err != nil {
+			return stats, err
+		}
+
+		if len(metas) == 0 {
+			return stats, nil
+		}
+		lastObjectID = metas[len(metas)-1].ObjectID
+
+		withReadsLeft := []string{}
+		for _, meta := range metas {
+			if meta.Value == "0" {
+				stats.Consumed++
+				continue
+			}
+			withReadsLeft = append(withReadsLeft, strings.TrimPrefix(meta.ObjectID, RECORD_META_ID_PREFIX))
+		}
+
+		if len(withReadsLeft) == 0 {
+			continue
+		}
+
+		var active, expired int64
+		err = store.vaultDB(ctx).
+			Where(COLUMN_ID+" IN ?", withReadsLeft).
+			Where(COLUMN_EXPIRES_AT+" < ? AND "+COLUMN_EXPIRES_AT+" <> ?", now, sb.MAX_DATETIME).
+			Count(&expired).Error
+		if err != nil {
+			return stats, err
+		}
+
+		err = store.vaultDB(ctx).
+			Where(COLUMN_ID+" IN ?", withReadsLeft).
+			Count(&active).Error
+		if err != nil {
+			return stats, err
+		}
+
+		stats.Expired += expired
+		stats.Active += active - expired
+	}
+}
+
+// recordIDsFromMetaObjectIDs returns the record IDs of record meta object IDs
+func recordIDsFromMetaObjectIDs(objectIDs []string) []string {
+	return lo.Map(objectIDs, func(objectID string, _ int) string {
+		return strings.TrimPrefix(objectID, RECORD_META_ID_PREFIX)
+	})
+}
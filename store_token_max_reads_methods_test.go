@@ -0,0 +1,246 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func Test_Store_TokenMaxReads(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	_, err = store.TokenCreate(ctx, "value", password, 20, TokenCreateOptions{MaxReads: -1})
+	if !errors.Is(err, ErrMaxReadsInvalid) {
+		t.Fatalf("Expected [ErrMaxReadsInvalid] received [%v]", err)
+	}
+
+	token, err := store.TokenCreate(ctx, "invite", password, 20, TokenCreateOptions{MaxReads: 2})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// A wrong password does not count as a read
+	if _, err := store.TokenRead(ctx, token, "wrong_password_that_is_long_enough_32chars"); err == nil {
+		t.Fatal("TokenRead: Expected an error for a wrong password")
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := store.TokenRead(ctx, token, password)
+		if err != nil {
+			t.Fatalf("TokenRead %d: Expected [err] to be nil received [%v]", i, err.Error())
+		}
+
+		if value != "invite" {
+			t.Fatalf("Expected [invite] received [%v]", value)
+		}
+	}
+
+	if _, err := store.TokenRead(ctx, token, password); !errors.Is(err, ErrTokenConsumed) {
+		t.Fatalf("Expected [ErrTokenConsumed] received [%v]", err)
+	}
+
+	if _, err := store.TokensRead(ctx, []string{token}, password); !errors.Is(err, ErrTokenConsumed) {
+		t.Fatalf("TokensRead: Expected [ErrTokenConsumed] received [%v]", err)
+	}
+
+	// Tokens without a limit are read as usual
+	unlimited, err := store.TokenCreate(ctx, "unlimited", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.TokenRead(ctx, unlimited, password); err != nil {
+			t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+}
+
+func Test_Store_TokenMaxReads_ReadPaths(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	// Batch reads count too
+	tokens, err := store.TokenCreateBatch(ctx, []string{"a", "b"}, password, 20, TokenCreateOptions{MaxReads: 1})
+	if err != nil {
+		t.Fatalf("TokenCreateBatch: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	values, err := store.TokensRead(ctx, tokens, password)
+	if err != nil {
+		t.Fatalf("TokensRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if values[tokens[0]] != "a" || values[tokens[1]] != "b" {
+		t.Fatalf("Unexpected values [%v]", values)
+	}
+
+	if _, err := store.TokenRead(ctx, tokens[0], password); !errors.Is(err, ErrTokenConsumed) {
+		t.Fatalf("Expected [ErrTokenConsumed] received [%v]", err)
+	}
+
+	// ReadThrough never serves a limited-use token from the cache
+	err = store.TokenCreateCustom(ctx, "tk_max_reads_read_through", "once", password, TokenCreateOptions{MaxReads: 1})
+	if err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.ReadThrough(ctx, "tk_max_reads_read_through", password)
+	if err != nil {
+		t.Fatalf("ReadThrough: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "once" {
+		t.Fatalf("Expected [once] received [%v]", value)
+	}
+
+	if _, err := store.ReadThrough(ctx, "tk_max_reads_read_through", password); !errors.Is(err, ErrTokenConsumed) {
+		t.Fatalf("ReadThrough: Expected [ErrTokenConsumed] received [%v]", err)
+	}
+}
+
+func Test_Store_TokenMaxReads_Concurrent(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+	db.SetMaxOpenConns(1)
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_max_reads",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "reset link", password, 20, TokenCreateOptions{MaxReads: 3})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reads := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.TokenRead(ctx, token, password)
+			if err == nil {
+				mu.Lock()
+				reads++
+				mu.Unlock()
+			} else if !errors.Is(err, ErrTokenConsumed) {
+				t.Errorf("TokenRead: Expected [ErrTokenConsumed] received [%v]", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reads != 3 {
+		t.Fatalf("Expected 3 successful reads received [%v]", reads)
+	}
+}
+
+func Test_Store_TokensConsumedDelete(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	consumed, err := store.TokenCreate(ctx, "consumed", password, 20, TokenCreateOptions{MaxReads: 1})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenRead(ctx, consumed, password); err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	expired, err := store.TokenCreate(ctx, "expired", password, 20, TokenCreateOptions{MaxReads: 3})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.(*storeImplementation).gormDB.Exec("UPDATE vault_token SET expires_at = ? WHERE vault_token = ?", "2020-01-01 00:00:00", expired).Error
+	if err != nil {
+		t.Fatalf("Exec: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	active, err := store.TokenCreate(ctx, "active", password, 20, TokenCreateOptions{MaxReads: 3})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	unlimited, err := store.TokenCreate(ctx, "unlimited", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	stats, err := store.LimitedUseStats(ctx)
+	if err != nil {
+		t.Fatalf("LimitedUseStats: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if stats != (LimitedUseStats{Active: 1, Consumed: 1, Expired: 1}) {
+		t.Fatalf("Expected stats [1 active, 1 consumed, 1 expired] received [%+v]", stats)
+	}
+
+	count, err := store.TokensConsumedDelete(ctx)
+	if err != nil {
+		t.Fatalf("TokensConsumedDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 2 {
+		t.Fatalf("Expected [2] deleted records received [%v]", count)
+	}
+
+	// The records are gone, soft deleted ones included
+	for _, token := range []string{consumed, expired} {
+		var rows int64
+		err = store.(*storeImplementation).gormDB.Table("vault_token").Where("vault_token = ?", token).Count(&rows).Error
+		if err != nil {
+			t.Fatalf("Count: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if rows != 0 {
+			t.Fatalf("Expected token [%v] to be deleted", token)
+		}
+	}
+
+	for _, token := range []string{active, unlimited} {
+		if _, err := store.TokenRead(ctx, token, password); err != nil {
+			t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	stats, err = store.LimitedUseStats(ctx)
+	if err != nil {
+		t.Fatalf("LimitedUseStats: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if stats != (LimitedUseStats{Active: 1}) {
+		t.Fatalf("Expected stats [1 active] received [%+v]", stats)
+	}
+}
@@ -0,0 +1,262 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// tokenMetaKeyMaxLength is the maximum length of a token meta key, the size of the meta key column
+const tokenMetaKeyMaxLength = 50
+
+var (
+	// ErrTokenMetaKeyInvalid is returned for an empty token meta key or one longer than 50 characters
+	ErrTokenMetaKeyInvalid = errors.New("token meta key must be 1 to 50 characters")
+	// ErrTokenMetaNotFound is returned by TokenMetaGet when the token has no value for the key
+	ErrTokenMetaNotFound = errors.New("token meta not found")
+)
+
+// TokenMetaSet tags a token with a key/value pair, e.g. owner, purpose or environment,
+// replacing the previous value of the key. The tags live in the meta table next to
+// the internal metadata, in their own namespace, and are deleted with the token.
+//
+// The values are stored in plaintext so tokens can be found by them, do not put secrets in tags.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to tag
+// - key: The tag key, 1 to 50 characters
+// - value: The tag value
+//
+// Returns:
+// - err: ErrTokenNotFound, ErrTokenMetaKeyInvalid, or an error if something went wrong
+func (store *storeImplementation) TokenMetaSet(ctx context.Context, token string, key string, value string) error {
+	ctx, span := store.traceStart(ctx, "TokenMetaSet")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenMetaSet", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := validateTokenMetaKey(key); err != nil {
+		return err
+	}
+
+	entry, err := store.tokenMetaRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	return store.metaSet(ctx, OBJECT_TYPE_RECORD_TAG, recordMetaObjectID(entry.GetID()), key, value)
+}
+
+// TokenMetaGet returns the value of a tag of a token
+//
+// Parameters:
+// - ctx: The context
+// - token: The token
+// - key: The tag key
+//
+// Returns:
+// - value: The tag value
+// - err: ErrTokenNotFound, ErrTokenMetaNotFound, or an error if something went wrong
+func (store *storeImplementation) TokenMetaGet(ctx context.Context, token string, key string) (string, error) {
+	ctx, span := store.traceStart(ctx, "TokenMetaGet")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenMetaGet", token); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := validateTokenMetaKey(key); err != nil {
+		return "", err
+	}
+
+	entry, err := store.tokenMetaRecord(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	meta, err := store.metaFind(ctx, OBJECT_TYPE_RECORD_TAG, recordMetaObjectID(entry.GetID()), key)
+	if err != nil {
+		return "", err
+	}
+
+	if meta == nil {
+		return "", ErrTokenMetaNotFound
+	}
+
+	return meta.Value, nil
+}
+
+// TokenMetaList returns all the tags of a token
+//
+// Parameters:
+// - ctx: The context
+// - token: The token
+//
+// Returns:
+// - tags: The tags by key, empty if the token has none
+// - err: ErrTokenNotFound, or an error if something went wrong
+func (store *storeImplementation) TokenMetaList(ctx context.Context, token string) (map[string]string, error) {
+	ctx, span := store.traceStart(ctx, "TokenMetaList")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenMetaList", token); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, err := store.tokenMetaRecord(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var metas []gormVaultMeta
+	err = store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ?", OBJECT_TYPE_RECORD_TAG, recordMetaObjectID(entry.GetID())).
+		Find(&metas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(metas))
+	for _, meta := range metas {
+		tags[meta.Key] = meta.Value
+	}
+
+	return tags, nil
+}
+
+// TokenMetaDelete removes a tag of a token, removing a missing tag is not an error
+//
+// Parameters:
+// - ctx: The context
+// - token: The token
+// - key: The tag key
+//
+// Returns:
+// - err: ErrTokenNotFound, or an error if something went wrong
+func (store *storeImplementation) TokenMetaDelete(ctx context.Context, token string, key string) error {
+	ctx, span := store.traceStart(ctx, "TokenMetaDelete")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenMetaDelete", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := validateTokenMetaKey(key); err != nil {
+		return err
+	}
+
+	entry, err := store.tokenMetaRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	return store.metaDelete(ctx, OBJECT_TYPE_RECORD_TAG, recordMetaObjectID(entry.GetID()), key)
+}
+
+// TokensFindByMeta returns the tokens tagged with the key and value, excluding
+// soft deleted tokens. Tokens of other vault tables sharing the meta table are not returned.
+//
+// Parameters:
+// - ctx: The context
+// - key: The tag key
+// - value: The tag value
+//
+// Returns:
+// - tokens: The matching tokens, in no particular order
+// - err: ErrTokenMetaKeyInvalid, or an error if something went wrong
+func (store *storeImplementation) TokensFindByMeta(ctx context.Context, key string, value string) ([]string, error) {
+	ctx, span := store.traceStart(ctx, "TokensFindByMeta")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokensFindByMeta", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := validateTokenMetaKey(key); err != nil {
+		return nil, err
+	}
+
+	var objectIDs []string
+	err := store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ? AND "+COLUMN_META_VALUE+" = ?", OBJECT_TYPE_RECORD_TAG, key, value).
+		Pluck(COLUMN_OBJECT_ID, &objectIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []string{}
+	for _, batch := range lo.Chunk(objectIDs, maxRecordsInMemory) {
+		recordIDs := lo.Map(batch, func(objectID string, _ int) string {
+			return strings.TrimPrefix(objectID, RECORD_META_ID_PREFIX)
+		})
+
+		records, err := store.RecordList(ctx, RecordQuery().
+			SetIDIn(recordIDs).
+			SetColumns([]string{COLUMN_ID, COLUMN_VAULT_TOKEN}))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			tokens = append(tokens, record.GetToken())
+		}
+	}
+
+	return tokens, nil
+}
+
+// recordIDsTaggedQuery returns the subquery selecting the IDs of the records tagged
+// with the key and one of the values, the record ID follows the "r_" object ID prefix
+func (store *storeImplementation) recordIDsTaggedQuery(db *gorm.DB, key string, values []string) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Table(store.vaultMetaTableName).
+		Select(store.metaRecordIDExpr()).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_RECORD_TAG, key).
+		Where(COLUMN_META_VALUE+" IN ?", values)
+}
+
+// tokenMetaRecord finds the record of the token whose tags are read or changed
+func (store *storeImplementation) tokenMetaRecord(ctx context.Context, token string) (RecordInterface, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if token == "" {
+		return nil, errors.New("token is empty")
+	}
+
+	entry, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return entry, nil
+}
+
+// validateTokenMetaKey checks the length of a token meta key
+func validateTokenMetaKey(key string) error {
+	if key == "" || len(key) > tokenMetaKeyMaxLength {
+		return ErrTokenMetaKeyInvalid
+	}
+
+	return nil
+}
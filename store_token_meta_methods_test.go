@@ -0,0 +1,180 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Store_TokenMeta(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "value", password, 20, TokenCreateOptions{ContentType: CONTENT_TYPE_TEXT})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	other, err := store.TokenCreate(ctx, "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	for key, value := range map[string]string{"owner": "billing", "environment": "staging"} {
+		if err := store.TokenMetaSet(ctx, token, key, value); err != nil {
+			t.Fatalf("TokenMetaSet: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	if err := store.TokenMetaSet(ctx, token, "environment", "production"); err != nil {
+		t.Fatalf("TokenMetaSet: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenMetaSet(ctx, other, "environment", "production"); err != nil {
+		t.Fatalf("TokenMetaSet: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenMetaGet(ctx, token, "environment")
+	if err != nil {
+		t.Fatalf("TokenMetaGet: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "production" {
+		t.Fatalf("Expected [production] received [%v]", value)
+	}
+
+	// The internal metadata, e.g. the content type, is not listed
+	tags, err := store.TokenMetaList(ctx, token)
+	if err != nil {
+		t.Fatalf("TokenMetaList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !reflect.DeepEqual(tags, map[string]string{"owner": "billing", "environment": "production"}) {
+		t.Fatalf("Expected the owner and environment tags received [%v]", tags)
+	}
+
+	tokens, err := store.TokensFindByMeta(ctx, "environment", "production")
+	if err != nil {
+		t.Fatalf("TokensFindByMeta: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(tokens) != 2 {
+		t.Fatalf("Expected [2] tokens received [%v]", tokens)
+	}
+
+	if err := store.TokenMetaDelete(ctx, token, "owner"); err != nil {
+		t.Fatalf("TokenMetaDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokenMetaGet(ctx, token, "owner"); !errors.Is(err, ErrTokenMetaNotFound) {
+		t.Fatalf("Expected [ErrTokenMetaNotFound] received [%v]", err)
+	}
+
+	// Soft deleted tokens are not found
+	if err := store.TokenSoftDelete(ctx, other); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	tokens, err = store.TokensFindByMeta(ctx, "environment", "production")
+	if err != nil {
+		t.Fatalf("TokensFindByMeta: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !reflect.DeepEqual(tokens, []string{token}) {
+		t.Fatalf("Expected [%v] received [%v]", token, tokens)
+	}
+
+	if err := store.TokenMetaSet(ctx, token, strings.Repeat("k", 51), "value"); !errors.Is(err, ErrTokenMetaKeyInvalid) {
+		t.Fatalf("Expected [ErrTokenMetaKeyInvalid] received [%v]", err)
+	}
+
+	if err := store.TokenMetaSet(ctx, "tk_missing_token_1234", "owner", "value"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Expected [ErrTokenNotFound] received [%v]", err)
+	}
+
+	// The tags are deleted with the token
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenDelete(ctx, token); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var count int64
+	err = store.(*storeImplementation).metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ?", OBJECT_TYPE_RECORD_TAG, recordMetaObjectID(record.GetID())).
+		Count(&count).Error
+	if err != nil {
+		t.Fatalf("Count: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 0 {
+		t.Fatalf("Expected the tags of the deleted token to be deleted, [%v] left", count)
+	}
+}
+
+func Test_Store_RecordQuery_MetaIn(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	tags := map[string]map[string]string{
+		"user_123_api":   {"object_type": "user", "object_id": "123"},
+		"user_123_oauth": {"object_type": "user", "object_id": "123"},
+		"user_456_api":   {"object_type": "user", "object_id": "456"},
+		"team_123_api":   {"object_type": "team", "object_id": "123"},
+	}
+
+	for token, tokenTags := range tags {
+		if err := store.TokenCreateCustom(ctx, token, "value", password); err != nil {
+			t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		for key, value := range tokenTags {
+			if err := store.TokenMetaSet(ctx, token, key, value); err != nil {
+				t.Fatalf("TokenMetaSet: Expected [err] to be nil received [%v]", err.Error())
+			}
+		}
+	}
+
+	records, err := store.RecordList(ctx, RecordQuery().
+		SetMetaEquals("object_type", "user").
+		SetMetaEquals("object_id", "123").
+		SetOrderBy(COLUMN_VAULT_TOKEN).
+		SetSortOrder(ASC))
+	if err != nil {
+		t.Fatalf("RecordList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(records) != 2 || records[0].GetToken() != "user_123_api" || records[1].GetToken() != "user_123_oauth" {
+		t.Fatalf("Expected the tokens of user 123 received [%v]", records)
+	}
+
+	count, err := store.RecordCount(ctx, RecordQuery().
+		SetMetaEquals("object_type", "user").
+		SetMetaIn("object_id", []string{"123", "456"}))
+	if err != nil {
+		t.Fatalf("RecordCount: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected [3] tokens of users received [%v]", count)
+	}
+
+	if _, err := store.RecordList(ctx, RecordQuery().SetMetaIn("object_id", []string{})); err == nil {
+		t.Fatal("Expected an error for empty meta values")
+	}
+}
@@ -4,40 +4,78 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/dracory/sb"
 	"github.com/dromara/carbon/v2"
 	"github.com/samber/lo"
+	"gorm.io/gorm"
 )
 
+// ErrTokenNotFound is returned when a token does not exist
+var ErrTokenNotFound = errors.New("token does not exist")
+
 // ErrTokenExpired is returned when a token has expired
 var ErrTokenExpired = errors.New("token has expired")
 
+// ErrExpiresAtInPast is returned when a token is created with an expiration in the past,
+// unless NewStoreOptions.ExpiresAtPastAllowed is set
+var ErrExpiresAtInPast = errors.New("token expiration is in the past")
+
+// ErrExpiresAtOutOfRange is returned when a token expiration cannot be stored
+var ErrExpiresAtOutOfRange = errors.New("token expiration is out of range")
+
+// ErrTokenAlreadyExists is returned when creating a custom token that already exists
+var ErrTokenAlreadyExists = errors.New("token already exists")
+
+// ErrTokenSoftDeleted is returned when creating a custom token that exists as a soft deleted record.
+// Delete the token with TokenDelete first to reuse it.
+var ErrTokenSoftDeleted = errors.New("token exists as a soft deleted record, delete it with TokenDelete to reuse it")
+
 // ErrPasswordInvalid is returned when password does not meet requirements
 var ErrPasswordInvalid = errors.New("password does not meet requirements")
 
-// validatePassword checks password against store configuration
+// Password policy errors, each wraps ErrPasswordInvalid so both can be checked with errors.Is
+var (
+	// ErrPasswordTooShort is returned when the password is shorter than the minimum length
+	ErrPasswordTooShort = fmt.Errorf("%w: too short", ErrPasswordInvalid)
+	// ErrPasswordMissingLowercase is returned when a lowercase letter is required but missing
+	ErrPasswordMissingLowercase = fmt.Errorf("%w: missing a lowercase letter", ErrPasswordInvalid)
+	// ErrPasswordMissingUppercase is returned when an uppercase letter is required but missing
+	ErrPasswordMissingUppercase = fmt.Errorf("%w: missing an uppercase letter", ErrPasswordInvalid)
+	// ErrPasswordMissingNumber is returned when a number is required but missing
+	ErrPasswordMissingNumber = fmt.Errorf("%w: missing a number", ErrPasswordInvalid)
+	// ErrPasswordMissingSymbol is returned when a symbol is required but missing
+	ErrPasswordMissingSymbol = fmt.Errorf("%w: missing a symbol", ErrPasswordInvalid)
+)
+
+// validatePassword checks password against the loaded policy, or the store configuration
 func (store *storeImplementation) validatePassword(password string) error {
+	return validatePasswordPolicy(password, store.passwordPolicy())
+}
+
+// validatePasswordPolicy checks password against the password policy. It runs on the
+// operations writing a value, reads are not validated so that tightening the policy
+// does not lock out the tokens created before.
+func validatePasswordPolicy(password string, policy PasswordPolicy) error {
 	// If empty passwords are allowed, skip validation
-	if store.passwordAllowEmpty && password == "" {
+	if policy.AllowEmpty && password == "" {
 		return nil
 	}
 
 	// Get minimum length (default 16 if not set)
-	minLength := store.passwordMinLength
+	minLength := policy.MinLength
 	if minLength <= 0 {
 		minLength = 16
 	}
 
 	if len(password) < minLength {
-		return ErrPasswordInvalid
+		return fmt.Errorf("%w: at least %d characters are required", ErrPasswordTooShort, minLength)
 	}
 
 	// Skip character type checking if none are required
-	if !store.passwordRequireLowercase && !store.passwordRequireUppercase &&
-		!store.passwordRequireNumbers && !store.passwordRequireSymbols {
+	if !policy.RequireLowercase && !policy.RequireUppercase &&
+		!policy.RequireNumbers && !policy.RequireSymbols {
 		return nil
 	}
 
@@ -50,60 +88,174 @@ func (store *storeImplementation) validatePassword(password string) error {
 	for _, char := range password {
 		switch {
 		case char >= 'a' && char <= 'z':
-			if store.passwordRequireLowercase {
+			if policy.RequireLowercase {
 				hasLower = true
 			}
 		case char >= 'A' && char <= 'Z':
-			if store.passwordRequireUppercase {
+			if policy.RequireUppercase {
 				hasUpper = true
 			}
 		case char >= '0' && char <= '9':
-			if store.passwordRequireNumbers {
+			if policy.RequireNumbers {
 				hasNumber = true
 			}
 		default:
-			if store.passwordRequireSymbols {
+			if policy.RequireSymbols {
 				hasSymbol = true
 			}
 		}
 
 		// Early exit: check if all required types are found
-		if (!store.passwordRequireLowercase || hasLower) &&
-			(!store.passwordRequireUppercase || hasUpper) &&
-			(!store.passwordRequireNumbers || hasNumber) &&
-			(!store.passwordRequireSymbols || hasSymbol) {
+		if (!policy.RequireLowercase || hasLower) &&
+			(!policy.RequireUppercase || hasUpper) &&
+			(!policy.RequireNumbers || hasNumber) &&
+			(!policy.RequireSymbols || hasSymbol) {
 			break
 		}
 	}
 
-	if store.passwordRequireLowercase && !hasLower {
-		return ErrPasswordInvalid
+	if policy.RequireLowercase && !hasLower {
+		return ErrPasswordMissingLowercase
 	}
-	if store.passwordRequireUppercase && !hasUpper {
-		return ErrPasswordInvalid
+	if policy.RequireUppercase && !hasUpper {
+		return ErrPasswordMissingUppercase
 	}
-	if store.passwordRequireNumbers && !hasNumber {
-		return ErrPasswordInvalid
+	if policy.RequireNumbers && !hasNumber {
+		return ErrPasswordMissingNumber
 	}
-	if store.passwordRequireSymbols && !hasSymbol {
-		return ErrPasswordInvalid
+	if policy.RequireSymbols && !hasSymbol {
+		return ErrPasswordMissingSymbol
 	}
 
 	return nil
 }
 
+// isRecordExpired returns true if the record has an expiration time in the past
+func isRecordExpired(record RecordInterface) bool {
+	expiresAt := record.GetExpiresAt()
+	if expiresAt == "" || expiresAt == sb.MAX_DATETIME {
+		return false
+	}
+
+	expiryTime, ok := recordTimeParse(expiresAt)
+	return ok && time.Now().After(expiryTime)
+}
+
+// isRecordSoftDeleted returns true if the record has been soft deleted
+func isRecordSoftDeleted(record RecordInterface) bool {
+	softDeletedAt := record.GetSoftDeletedAt()
+	if softDeletedAt == "" || softDeletedAt == sb.MAX_DATETIME {
+		return false
+	}
+
+	softDeletedTime, ok := recordTimeParse(softDeletedAt)
+	return ok && !time.Now().Before(softDeletedTime)
+}
+
+// tokenFindIncludingSoftDeleted finds the record of the token, including soft deleted records,
+// which still occupy the token in the unique index
+func (store *storeImplementation) tokenFindIncludingSoftDeleted(ctx context.Context, token string) (RecordInterface, error) {
+	records, err := store.RecordList(ctx, RecordQuery().
+		SetToken(token).
+		SetSoftDeletedInclude(true).
+		SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	return records[0], nil
+}
+
 // TokenCreateOptions contains optional parameters for token creation
 type TokenCreateOptions struct {
 	// ExpiresAt is the expiration time for the token
 	// If zero value, token never expires
 	ExpiresAt time.Time
+
+	// IdempotencyKey makes TokenCreate safe to retry. A repeated call with the same key
+	// returns the previously created token instead of creating a duplicate record.
+	// Only used by TokenCreate.
+	IdempotencyKey string
+
+	// ContentType describes the format of the plaintext (e.g. CONTENT_TYPE_JSON),
+	// it is returned by TokenReadWithInfo
+	ContentType string
+
+	// MaxReads limits the number of times the value can be read, e.g. for password
+	// reset and invite links. Once exhausted, reads return ErrTokenConsumed.
+	// Zero means unlimited.
+	MaxReads int
+}
+
+// tokenCreateOptionsNormalize validates the expiration of the create options and
+// normalizes it to UTC, truncated to the second precision of the stored timestamps
+func (store *storeImplementation) tokenCreateOptionsNormalize(options []TokenCreateOptions) ([]TokenCreateOptions, error) {
+	if len(options) == 0 || options[0].ExpiresAt.IsZero() {
+		return options, nil
+	}
+
+	option := options[0]
+	option.ExpiresAt = option.ExpiresAt.UTC().Truncate(time.Second)
+
+	if option.ExpiresAt.Year() < 1 || option.ExpiresAt.Year() > 9999 {
+		return nil, ErrExpiresAtOutOfRange
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if option.ExpiresAt.Before(now) && !store.expiresAtPastAllowed {
+		return nil, ErrExpiresAtInPast
+	}
+
+	return []TokenCreateOptions{option}, nil
 }
 
 // TokenCreate creates a new record and returns the token
 func (store *storeImplementation) TokenCreate(ctx context.Context, data string, password string, tokenLength int, options ...TokenCreateOptions) (token string, err error) {
+	ctx, span := store.traceStart(ctx, "TokenCreate")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCreate", ""); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := store.validatePassword(password); err != nil {
 		return "", err
 	}
+
+	if err := validateTokenCreateOptions(options); err != nil {
+		return "", err
+	}
+
+	options, err = store.tokenCreateOptionsNormalize(options)
+	if err != nil {
+		return "", err
+	}
+
+	options, err = store.tokenCreateOptionsWithRetention(options)
+	if err != nil {
+		return "", err
+	}
+
+	idempotencyKey := ""
+	if len(options) > 0 {
+		idempotencyKey = options[0].IdempotencyKey
+	}
+
+	if idempotencyKey != "" {
+		existingToken, err := store.idempotentTokenFind(ctx, idempotencyKey)
+		if err != nil {
+			return "", err
+		}
+		if existingToken != "" {
+			return existingToken, nil
+		}
+	}
+
 	maxAttempts := 3
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
@@ -112,8 +264,8 @@ func (store *storeImplementation) TokenCreate(ctx context.Context, data string,
 			return "", err
 		}
 
-		// Check if token already exists
-		existing, err := store.RecordFindByToken(ctx, token)
+		// Check if token already exists, soft deleted tokens cannot be reused
+		existing, err := store.tokenFindIncludingSoftDeleted(ctx, token)
 		if err != nil {
 			return "", err
 		}
@@ -121,12 +273,12 @@ func (store *storeImplementation) TokenCreate(ctx context.Context, data string,
 			continue // Try again with a new token
 		}
 
-		encodedData, err := encode(data, password, store.cryptoConfig)
+		encodedData, err := encode(ctx, data, password, store.cryptoConfig)
 		if err != nil {
 			return "", fmt.Errorf("failed to encode data: %w", err)
 		}
 
-		var newEntry = NewRecord().
+		var newEntry = store.newRecord().
 			SetToken(token).
 			SetValue(encodedData).
 			SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
@@ -142,6 +294,22 @@ func (store *storeImplementation) TokenCreate(ctx context.Context, data string,
 			continue // Try again
 		}
 
+		if len(options) > 0 {
+			err = store.tokenContentTypeCreate(ctx, newEntry, options[0].ContentType)
+			if err != nil {
+				return "", err
+			}
+
+			err = store.tokenReadsRemainingCreate(ctx, newEntry, options[0].MaxReads)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if idempotencyKey != "" {
+			return store.idempotentTokenClaim(ctx, idempotencyKey, token)
+		}
+
 		return token, nil
 	}
 
@@ -149,29 +317,68 @@ func (store *storeImplementation) TokenCreate(ctx context.Context, data string,
 }
 
 func (store *storeImplementation) TokenCreateCustom(ctx context.Context, token string, data string, password string, options ...TokenCreateOptions) (err error) {
+	ctx, span := store.traceStart(ctx, "TokenCreateCustom")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCreateCustom", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := store.validatePassword(password); err != nil {
 		return err
 	}
+	if err := validateTokenCreateOptions(options); err != nil {
+		return err
+	}
+	options, err = store.tokenCreateOptionsNormalize(options)
+	if err != nil {
+		return err
+	}
+	options, err = store.tokenCreateOptionsWithRetention(options)
+	if err != nil {
+		return err
+	}
+
+	return store.tokenCreateCustomEncoded(ctx, token, options, func() (string, error) {
+		encodedData, err := encode(ctx, data, password, store.cryptoConfig)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode data: %w", err)
+		}
+		return encodedData, nil
+	})
+}
+
+// tokenCreateCustomEncoded creates the record of a custom token with the value
+// returned by encodeFn, called once the token is known to be available
+func (store *storeImplementation) tokenCreateCustomEncoded(ctx context.Context, token string, options []TokenCreateOptions, encodeFn func() (string, error)) error {
 	// Validate token is not empty (custom tokens can have any format)
 	if token == "" {
 		return errors.New("token is empty")
 	}
 
-	// Check if token already exists
-	existing, err := store.RecordFindByToken(ctx, token)
+	if err := validateTokenPath(token); err != nil {
+		return err
+	}
+
+	// Check if token already exists, soft deleted tokens still hold the unique index
+	existing, err := store.tokenFindIncludingSoftDeleted(ctx, token)
 	if err != nil {
 		return err
 	}
+	if existing != nil && isRecordSoftDeleted(existing) {
+		return ErrTokenSoftDeleted
+	}
 	if existing != nil {
-		return errors.New("token already exists")
+		return ErrTokenAlreadyExists
 	}
 
-	encodedData, err := encode(data, password, store.cryptoConfig)
+	encodedData, err := encodeFn()
 	if err != nil {
-		return fmt.Errorf("failed to encode data: %w", err)
+		return err
 	}
 
-	var newEntry = NewRecord().
+	var newEntry = store.newRecord().
 		SetToken(token).
 		SetValue(encodedData).
 		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
@@ -187,6 +394,14 @@ func (store *storeImplementation) TokenCreateCustom(ctx context.Context, token s
 		return err
 	}
 
+	if len(options) > 0 {
+		if err := store.tokenContentTypeCreate(ctx, newEntry, options[0].ContentType); err != nil {
+			return err
+		}
+
+		return store.tokenReadsRemainingCreate(ctx, newEntry, options[0].MaxReads)
+	}
+
 	return nil
 }
 
@@ -201,6 +416,14 @@ func (store *storeImplementation) TokenCreateCustom(ctx context.Context, token s
 // Returns:
 // - err: An error if something went wrong
 func (store *storeImplementation) TokenDelete(ctx context.Context, token string) error {
+	ctx, span := store.traceStart(ctx, "TokenDelete")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenDelete", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if token == "" {
 		return errors.New("token is empty")
 	}
@@ -220,11 +443,28 @@ func (store *storeImplementation) TokenDelete(ctx context.Context, token string)
 // - exists: A boolean indicating if the token exists
 // - err: An error if something went wrong
 func (store *storeImplementation) TokenExists(ctx context.Context, token string) (bool, error) {
+	ctx, span := store.traceStart(ctx, "TokenExists")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenExists", token); err != nil {
+		return false, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if token == "" {
 		return false, errors.New("token is empty")
 	}
 
-	count, err := store.RecordCount(ctx, RecordQuery().SetToken(token))
+	mayExist, err := store.tokenMayExist(ctx, token)
+	if err != nil {
+		return false, err
+	}
+
+	if !mayExist {
+		return false, nil
+	}
+
+	count, err := store.RecordCount(ctx, RecordQueryByToken(token))
 
 	if err != nil {
 		return false, err
@@ -246,117 +486,261 @@ func (store *storeImplementation) TokenExists(ctx context.Context, token string)
 // - value: The value of the token
 // - err: An error if something went wrong
 func (store *storeImplementation) TokenRead(ctx context.Context, token string, password string) (value string, err error) {
+	ctx, span := store.traceStart(ctx, "TokenRead")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenRead", token); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	_, decoded, err := store.tokenReadRecord(ctx, token, password)
+	if err != nil {
+		return "", err
+	}
+
+	return decoded, nil
+}
+
+// tokenReadRecord finds the unexpired record of the token and decrypts its value,
+// returning ErrTokenStreamed for the tokens created with TokenCreateFromReader
+func (store *storeImplementation) tokenReadRecord(ctx context.Context, token string, password string) (RecordInterface, string, error) {
+	entry, decoded, _, err := store.tokenReadRecordValue(ctx, token, password, false)
+	return entry, decoded, err
+}
+
+// tokenReadRecordValue finds the unexpired record of the token and decrypts its value,
+// returning whether it is the stream key of a streamed token. Streamed tokens return
+// ErrTokenStreamed unless streamAllowed is set.
+func (store *storeImplementation) tokenReadRecordValue(ctx context.Context, token string, password string, streamAllowed bool) (RecordInterface, string, bool, error) {
+	entry, metas, err := store.tokenReadableRecord(ctx, token)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	decoded, err := decode(ctx, entry.GetValue(), password, store.cryptoConfig)
+
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	if err := store.valueValidate(ctx, entry, decoded); err != nil {
+		return nil, "", false, err
+	}
+
+	streamed := tokenStreamed(metas[META_KEY_STREAM], decoded)
+	if streamed && !streamAllowed {
+		return nil, "", false, ErrTokenStreamed
+	}
+
+	// Only successful reads count against the read limit
+	if err := store.tokenReadConsumeMeta(ctx, entry, metas[META_KEY_READS_REMAINING]); err != nil {
+		return nil, "", false, err
+	}
+
+	return entry, decoded, streamed, nil
+}
+
+// tokenReadableMetaKeys are the meta keys of a record checked on each read
+var tokenReadableMetaKeys = []string{
+	META_KEY_BREAK_GLASS,
+	META_KEY_CHECKOUT,
+	META_KEY_QUARANTINE,
+	META_KEY_READS_REMAINING,
+	META_KEY_REVOCATION,
+	META_KEY_STREAM,
+}
+
+// tokenReadableRecord finds the record of the token, if it can be read: not expired,
+// revoked, quarantined, checked out, nor requiring a break-glass grant. The meta rows
+// of the checks are loaded with a single query, and returned with the record for the
+// read limit.
+func (store *storeImplementation) tokenReadableRecord(ctx context.Context, token string) (RecordInterface, map[string]*gormVaultMeta, error) {
 	if token == "" {
-		return "", errors.New("token is empty")
+		return nil, nil, errors.New("token is empty")
+	}
+
+	mayExist, err := store.tokenMayExist(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !mayExist {
+		return nil, nil, ErrTokenNotFound
 	}
 
 	entry, err := store.RecordFindByToken(ctx, token)
 
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 
 	if entry == nil {
-		return "", errors.New("token does not exist")
+		return nil, nil, ErrTokenNotFound
 	}
 
 	// Check if token has expired
-	expiresAt := entry.GetExpiresAt()
-	if expiresAt != "" && expiresAt != sb.MAX_DATETIME {
-		expiryTime := carbon.Parse(expiresAt, carbon.UTC)
-		if !expiryTime.IsZero() && carbon.Now(carbon.UTC).Gt(expiryTime) {
-			return "", ErrTokenExpired
-		}
+	if isRecordExpired(entry) {
+		return nil, nil, ErrTokenExpired
 	}
 
-	decoded, err := decode(entry.GetValue(), password, store.cryptoConfig)
-
+	metas, err := store.recordMetaFindKeys(ctx, entry, tokenReadableMetaKeys...)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 
-	return decoded, nil
+	if err := tokenRevocationMetaCheck(metas[META_KEY_REVOCATION]); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tokenQuarantineMetaCheck(metas[META_KEY_QUARANTINE]); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tokenCheckoutMetaCheck(ctx, entry, metas[META_KEY_CHECKOUT]); err != nil {
+		return nil, nil, err
+	}
+
+	if err := store.tokenBreakGlassMetaCheck(ctx, entry, metas[META_KEY_BREAK_GLASS]); err != nil {
+		return nil, nil, err
+	}
+
+	return entry, metas, nil
 }
 
 // TokenRenew extends the expiration time of an existing token
 func (store *storeImplementation) TokenRenew(ctx context.Context, token string, expiresAt time.Time) error {
+	ctx, span := store.traceStart(ctx, "TokenRenew")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenRenew", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if token == "" {
 		return errors.New("token is empty")
 	}
 
-	entry, err := store.RecordFindByToken(ctx, token)
-
+	expiresAt, err := store.retentionExpiresAt(expiresAt)
 	if err != nil {
 		return err
 	}
 
-	if entry == nil {
-		return errors.New("token does not exist")
+	newExpiresAt := sb.MAX_DATETIME
+	if !expiresAt.IsZero() {
+		newExpiresAt = carbon.CreateFromStdTime(expiresAt).ToDateTimeString(carbon.UTC)
 	}
 
-	if expiresAt.IsZero() {
-		entry.SetExpiresAt(sb.MAX_DATETIME)
-	} else {
-		entry.SetExpiresAt(carbon.CreateFromStdTime(expiresAt).ToDateTimeString(carbon.UTC))
+	err = store.RecordUpdateByToken(ctx, token, map[string]string{
+		COLUMN_EXPIRES_AT: newExpiresAt,
+	})
+
+	if errors.Is(err, ErrRecordNotFound) {
+		return ErrTokenNotFound
 	}
 
-	return store.RecordUpdate(ctx, entry)
+	return err
 }
 
-// TokensExpiredSoftDelete soft-deletes all expired tokens
-func (store *storeImplementation) TokensExpiredSoftDelete(ctx context.Context) (count int64, err error) {
-	records, err := store.RecordList(ctx, RecordQuery())
-	if err != nil {
+// TokensExpireWhere sets the expiration of every token matching the query in a single UPDATE,
+// e.g. to force-expire all tokens created before a breach date
+//
+// # The query must have at least one filter, limit and offset are not supported
+//
+// Parameters:
+// - ctx: The context
+// - query: The query selecting the tokens
+// - expiresAt: The new expiration time, zero value means never expires
+//
+// Returns:
+// - count: The number of updated tokens
+// - err: An error if something went wrong
+func (store *storeImplementation) TokensExpireWhere(ctx context.Context, query RecordQueryInterface, expiresAt time.Time) (count int64, err error) {
+	ctx, span := store.traceStart(ctx, "TokensExpireWhere")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokensExpireWhere", ""); err != nil {
 		return 0, err
 	}
+	ctx = store.operationAllowedContext(ctx)
 
-	for _, record := range records {
-		expiresAt := record.GetExpiresAt()
-		if expiresAt == "" || expiresAt == sb.MAX_DATETIME {
-			continue
-		}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
 
-		expiryTime := carbon.Parse(expiresAt, carbon.UTC)
-		if expiryTime.IsZero() || carbon.Now(carbon.UTC).Lte(expiryTime) {
-			continue
-		}
+	if query == nil {
+		return 0, errors.New("query is nil")
+	}
 
-		err = store.RecordSoftDelete(ctx, record)
-		if err != nil {
-			return count, err
-		}
-		count++
+	if err := query.Validate(); err != nil {
+		return 0, err
+	}
+
+	if query.IsLimitSet() || query.IsOffsetSet() {
+		return 0, errors.New("limit and offset are not supported when expiring tokens")
+	}
+
+	if !query.IsIDSet() && !query.IsIDInSet() && !query.IsTokenSet() && !query.IsTokenInSet() && !query.IsCreatedAtBeforeSet() {
+		return 0, errors.New("query must have at least one filter")
+	}
+
+	newExpiresAt := sb.MAX_DATETIME
+	if !expiresAt.IsZero() {
+		newExpiresAt = carbon.CreateFromStdTime(expiresAt).ToDateTimeString(carbon.UTC)
+	}
+
+	result := store.recordQueryFilter(store.vaultDB(ctx), query).
+		Updates(map[string]interface{}{
+			COLUMN_EXPIRES_AT: newExpiresAt,
+			COLUMN_UPDATED_AT: store.timestampValue(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+		})
+
+	if result.Error != nil {
+		return 0, result.Error
 	}
 
-	return count, nil
+	return result.RowsAffected, nil
 }
 
-// TokensExpiredDelete permanently deletes all expired tokens
-func (store *storeImplementation) TokensExpiredDelete(ctx context.Context) (count int64, err error) {
-	records, err := store.RecordList(ctx, RecordQuery())
-	if err != nil {
+// TokensExpiredSoftDelete soft-deletes all expired tokens.
+//
+// The expired records are selected in SQL and updated by ID in batches of
+// NewStoreOptions.DeleteBatchSize, without loading or decrypting them, so that each
+// statement holds its locks briefly however many tokens expired.
+func (store *storeImplementation) TokensExpiredSoftDelete(ctx context.Context) (count int64, err error) {
+	ctx, span := store.traceStart(ctx, "TokensExpiredSoftDelete")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokensExpiredSoftDelete", ""); err != nil {
 		return 0, err
 	}
+	ctx = withNamespacesAll(store.operationAllowedContext(ctx))
 
-	for _, record := range records {
-		expiresAt := record.GetExpiresAt()
-		if expiresAt == "" || expiresAt == sb.MAX_DATETIME {
-			continue
-		}
+	return store.recordsSoftDeleteBatched(ctx, store.tokensExpiredFilter)
+}
 
-		expiryTime := carbon.Parse(expiresAt, carbon.UTC)
-		if expiryTime.IsZero() || carbon.Now(carbon.UTC).Lte(expiryTime) {
-			continue
-		}
+// TokensExpiredDelete permanently deletes all expired tokens, in batches like
+// TokensExpiredSoftDelete. The meta and value chunks of each batch are deleted with it.
+func (store *storeImplementation) TokensExpiredDelete(ctx context.Context) (count int64, err error) {
+	ctx, span := store.traceStart(ctx, "TokensExpiredDelete")
+	defer span.End()
 
-		err = store.RecordDeleteByID(ctx, record.GetID())
-		if err != nil {
-			return count, err
-		}
-		count++
+	if err := store.operationAllow(ctx, "TokensExpiredDelete", ""); err != nil {
+		return 0, err
 	}
+	ctx = withNamespacesAll(store.operationAllowedContext(ctx))
 
-	return count, nil
+	return store.recordsDeleteBatched(ctx, store.tokensExpiredFilter)
+}
+
+// tokensExpiredFilter selects the expired, not soft deleted, records
+func (store *storeImplementation) tokensExpiredFilter(db *gorm.DB) *gorm.DB {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	return store.recordQueryFilter(db, RecordQuery()).
+		Where(COLUMN_EXPIRES_AT+" < ?", now).
+		Where(COLUMN_EXPIRES_AT+" <> ?", sb.MAX_DATETIME)
 }
 
 // TokenSoftDelete soft deletes a token from the store
@@ -373,6 +757,14 @@ func (store *storeImplementation) TokensExpiredDelete(ctx context.Context) (coun
 // Returns:
 // - err: An error if something went wrong
 func (store *storeImplementation) TokenSoftDelete(ctx context.Context, token string) error {
+	ctx, span := store.traceStart(ctx, "TokenSoftDelete")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenSoftDelete", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if token == "" {
 		return errors.New("token is empty")
 	}
@@ -380,6 +772,41 @@ func (store *storeImplementation) TokenSoftDelete(ctx context.Context, token str
 	return store.RecordSoftDeleteByToken(ctx, token)
 }
 
+// TokenRestore undoes the soft delete of a token, making it readable again.
+// Restoring a token that is not soft deleted does nothing.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to restore
+//
+// Returns:
+// - err: ErrTokenNotFound if the token does not exist, e.g. was permanently deleted,
+// or an error if something went wrong
+func (store *storeImplementation) TokenRestore(ctx context.Context, token string) error {
+	ctx, span := store.traceStart(ctx, "TokenRestore")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenRestore", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if token == "" {
+		return errors.New("token is empty")
+	}
+
+	record, err := store.tokenFindIncludingSoftDeleted(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		return ErrTokenNotFound
+	}
+
+	return store.RecordRestore(ctx, record)
+}
+
 // TokenUpdate updates the value of a token
 //
 // # If the token does not exist, an error is returned
@@ -393,6 +820,14 @@ func (store *storeImplementation) TokenSoftDelete(ctx context.Context, token str
 // Returns:
 // - err: An error if something went wrong
 func (store *storeImplementation) TokenUpdate(ctx context.Context, token string, value string, password string) (err error) {
+	ctx, span := store.traceStart(ctx, "TokenUpdate")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenUpdate", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := store.validatePassword(password); err != nil {
 		return err
 	}
@@ -407,14 +842,30 @@ func (store *storeImplementation) TokenUpdate(ctx context.Context, token string,
 	}
 
 	if entry == nil {
-		return errors.New("token does not exist")
+		return ErrTokenNotFound
+	}
+
+	if err := store.tokenRevocationCheck(ctx, entry); err != nil {
+		return err
+	}
+
+	if err := store.tokenQuarantineCheck(ctx, entry); err != nil {
+		return err
 	}
 
-	encodedValue, err := encode(value, password, store.cryptoConfig)
+	if err := store.tokenCheckoutCheck(ctx, entry); err != nil {
+		return err
+	}
+
+	encodedValue, err := encode(ctx, value, password, store.cryptoConfig)
 	if err != nil {
 		return fmt.Errorf("failed to encode value: %w", err)
 	}
 
+	if err := store.tokenVersionArchive(ctx, entry); err != nil {
+		return err
+	}
+
 	entry.SetValue(encodedValue)
 
 	err = store.RecordUpdate(ctx, entry)
@@ -438,19 +889,78 @@ func (store *storeImplementation) TokenUpdate(ctx context.Context, token string,
 // - values: A map of token to value
 // - err: An error if something went wrong
 func (store *storeImplementation) TokensRead(ctx context.Context, tokens []string, password string) (values map[string]string, err error) {
+	ctx, span := store.traceStart(ctx, "TokensRead", traceRecordCount(len(tokens)))
+	defer span.End()
+
+	if err := store.operationAllowTokens(ctx, "TokensRead", tokens); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	values = map[string]string{}
 
+	err = store.TokensReadFunc(ctx, tokens, password, func(token string, value string) error {
+		values[token] = value
+		return nil
+	})
+
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	return values, nil
+}
+
+// TokensReadFunc reads a list of tokens and streams each decrypted value to the callback,
+// instead of building the full result map in memory
+//
+// Values are decrypted in parallel by a bounded worker pool (see NewStoreOptions.DecryptWorkers),
+// the callback is always invoked from a single goroutine, in no particular order.
+// Expired tokens are skipped. Returning an error from the callback stops the read.
+//
+// Parameters:
+// - ctx: The context
+// - tokens: The list of tokens to read
+// - password: The password to use for decryption
+// - fn: The callback receiving each token and its decrypted value
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokensReadFunc(ctx context.Context, tokens []string, password string, fn func(token string, value string) error) error {
+	ctx, span := store.traceStart(ctx, "TokensReadFunc", traceRecordCount(len(tokens)))
+	defer span.End()
+
+	if err := store.operationAllowTokens(ctx, "TokensReadFunc", tokens); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if fn == nil {
+		return errors.New("callback is nil")
+	}
+
+	entries, err := store.tokensReadableRecords(ctx, tokens)
+	if err != nil {
+		return err
+	}
+
+	return store.tokensDecode(ctx, entries, password, fn)
+}
+
+// tokensReadableRecords finds the records of the tokens, skipping the expired, revoked and
+// quarantined ones. Returns an error if a token does not exist or cannot be read by the caller.
+func (store *storeImplementation) tokensReadableRecords(ctx context.Context, tokens []string) ([]RecordInterface, error) {
 	// Validate all tokens are not empty
 	for _, token := range tokens {
 		if token == "" {
-			return values, errors.New("token cannot be empty")
+			return nil, errors.New("token cannot be empty")
 		}
 	}
 
 	entries, err := store.RecordList(ctx, RecordQuery().SetTokenIn(tokens))
 
 	if err != nil {
-		return values, err
+		return nil, err
 	}
 
 	if len(entries) != len(tokens) {
@@ -460,29 +970,101 @@ func (store *storeImplementation) TokensRead(ctx context.Context, tokens []strin
 
 		_, missingTokens := lo.Difference(tokens, entryTokens)
 
-		return values, errors.New("missing tokens: " + strings.Join(missingTokens, ", "))
+		// The tokens are not listed, the error may reach the clients of a server
+		return nil, fmt.Errorf("%w: %d missing tokens", ErrTokenNotFound, len(missingTokens))
+	}
+
+	// Skip expired tokens
+	entries = lo.Filter(entries, func(entry RecordInterface, _ int) bool {
+		return !isRecordExpired(entry)
+	})
+
+	// Skip revoked and quarantined tokens
+	revoked, err := store.recordIDsWithMeta(ctx, entries, META_KEY_REVOCATION)
+	if err != nil {
+		return nil, err
+	}
+
+	quarantined, err := store.recordIDsWithMeta(ctx, entries, META_KEY_QUARANTINE)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = lo.Filter(entries, func(entry RecordInterface, _ int) bool {
+		return !revoked[entry.GetID()] && !quarantined[entry.GetID()]
+	})
+
+	// Tokens checked out by another holder cannot be read
+	checkedOut, err := store.recordIDsWithMeta(ctx, entries, META_KEY_CHECKOUT)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, entry := range entries {
-		// Check if token has expired
-		expiresAt := entry.GetExpiresAt()
-		if expiresAt != "" && expiresAt != sb.MAX_DATETIME {
-			expiryTime := carbon.Parse(expiresAt, carbon.UTC)
-			if !expiryTime.IsZero() && carbon.Now(carbon.UTC).Gt(expiryTime) {
-				continue // Skip expired tokens
-			}
+		if !checkedOut[entry.GetID()] {
+			continue
+		}
+		if err := store.tokenCheckoutCheck(ctx, entry); err != nil {
+			return nil, err
 		}
+	}
 
-		decoded, err := decode(entry.GetValue(), password, store.cryptoConfig)
+	// High-security tokens require a break-glass grant
+	breakGlass, err := store.recordIDsWithMeta(ctx, entries, META_KEY_BREAK_GLASS)
+	if err != nil {
+		return nil, err
+	}
 
+	if len(breakGlass) > 0 {
+		err := store.breakGlassAccess(ctx, lo.Filter(entries, func(entry RecordInterface, _ int) bool {
+			return breakGlass[entry.GetID()]
+		}))
 		if err != nil {
-			return map[string]string{}, errors.New("decryption failed for one or more tokens")
+			return nil, err
 		}
+	}
+
+	return entries, nil
+}
 
-		values[entry.GetToken()] = decoded
+// tokensDecode decrypts the records, validating the values if a ValueValidateFunc is set
+// and counting the reads of limited-use records
+func (store *storeImplementation) tokensDecode(ctx context.Context, entries []RecordInterface, password string, fn func(token string, value string) error) error {
+	limited, err := store.recordIDsWithMeta(ctx, entries, META_KEY_READS_REMAINING)
+	if err != nil {
+		return err
 	}
 
-	return values, nil
+	streamed, err := store.recordIDsWithMeta(ctx, entries, META_KEY_STREAM)
+	if err != nil {
+		return err
+	}
+
+	if store.valueValidateFunc == nil && len(limited) == 0 && len(streamed) == 0 {
+		return store.decodeRecords(ctx, entries, password, fn)
+	}
+
+	entriesByToken := lo.KeyBy(entries, func(entry RecordInterface) string {
+		return entry.GetToken()
+	})
+
+	return store.decodeRecords(ctx, entries, password, func(token string, value string) error {
+		entry := entriesByToken[token]
+		if err := store.valueValidate(ctx, entry, value); err != nil {
+			return err
+		}
+		if streamed[entry.GetID()] {
+			if err := store.tokenStreamedCheck(ctx, entry, value); err != nil {
+				return err
+			}
+		}
+		if limited[entry.GetID()] {
+			if err := store.tokenReadConsume(ctx, entry); err != nil {
+				return err
+			}
+		}
+		return fn(token, value)
+	})
 }
 
 // TokenUpsert updates or creates a token for a given value
@@ -501,6 +1083,14 @@ func (store *storeImplementation) TokensRead(ctx context.Context, tokens []strin
 // - newToken: The new token if created, or the existing token if updated
 // - error: An error if something went wrong
 func (store *storeImplementation) TokenUpsert(ctx context.Context, existingToken string, value string, password string) (newToken string, err error) {
+	ctx, span := store.traceStart(ctx, "TokenUpsert")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenUpsert", existingToken); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if existingToken == "" {
 		token, err := store.TokenCreate(ctx, value, password, 20)
 		if err != nil {
@@ -2,6 +2,7 @@ package vaultstore
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -59,6 +60,52 @@ func Test_Store_TokenCreateCustom(t *testing.T) {
 	}
 }
 
+func Test_Store_TokenCreateCustom_SoftDeleted(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	err = store.TokenCreateCustom(ctx, "token_soft_deleted", "test_val", password)
+	if err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenSoftDelete(ctx, "token_soft_deleted")
+	if err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Re-creating a soft deleted token is rejected with a typed error
+	err = store.TokenCreateCustom(ctx, "token_soft_deleted", "new_val", password)
+	if !errors.Is(err, ErrTokenSoftDeleted) {
+		t.Fatalf("Expected [ErrTokenSoftDeleted] received [%v]", err)
+	}
+
+	// Deleting the soft deleted token frees it
+	err = store.TokenDelete(ctx, "token_soft_deleted")
+	if err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenCreateCustom(ctx, "token_soft_deleted", "new_val", password)
+	if err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, "token_soft_deleted", password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "new_val" {
+		t.Fatalf("Expected [new_val] received [%v]", value)
+	}
+}
+
 func Test_Store_TokenDelete(t *testing.T) {
 	store, err := initStore()
 
@@ -459,7 +506,7 @@ func Test_Store_TokenCreateWithExpiration(t *testing.T) {
 }
 
 func Test_Store_TokenCreateWithExpiration_Expired(t *testing.T) {
-	store, err := initStore()
+	store, err := initStoreExpiresAtPastAllowed()
 
 	if err != nil {
 		t.Fatalf("Test_Store_TokenCreateWithExpiration_Expired: Expected [err] to be nil received [%v]", err.Error())
@@ -484,6 +531,64 @@ func Test_Store_TokenCreateWithExpiration_Expired(t *testing.T) {
 	}
 }
 
+func Test_Store_TokenCreate_ExpiresAtValidation(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	_, err = store.TokenCreate(ctx, "value", password, 20, TokenCreateOptions{
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if !errors.Is(err, ErrExpiresAtInPast) {
+		t.Fatalf("Expected ErrExpiresAtInPast received [%v]", err)
+	}
+
+	err = store.TokenCreateCustom(ctx, "tk_custom_past_expiry", "value", password, TokenCreateOptions{
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if !errors.Is(err, ErrExpiresAtInPast) {
+		t.Fatalf("Expected ErrExpiresAtInPast received [%v]", err)
+	}
+
+	_, err = store.TokenCreate(ctx, "value", password, 20, TokenCreateOptions{
+		ExpiresAt: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if !errors.Is(err, ErrExpiresAtOutOfRange) {
+		t.Fatalf("Expected ErrExpiresAtOutOfRange received [%v]", err)
+	}
+
+	// A local time is stored in UTC
+	location := time.FixedZone("UTC+5", 5*60*60)
+	expiresAt := time.Now().Add(time.Hour).In(location)
+
+	token, err := store.TokenCreate(ctx, "value", password, 20, TokenCreateOptions{
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Drivers may read the datetime column in another format
+	stored, err := normalizeTimestamp(record.GetExpiresAt())
+	if err != nil {
+		t.Fatalf("normalizeTimestamp: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	expected := expiresAt.UTC().Format("2006-01-02 15:04:05")
+	if stored != expected {
+		t.Fatalf("Expected [%v] received [%v]", expected, stored)
+	}
+}
+
 func Test_Store_TokenCreateCustomWithExpiration(t *testing.T) {
 	store, err := initStore()
 
@@ -515,7 +620,7 @@ func Test_Store_TokenCreateCustomWithExpiration(t *testing.T) {
 }
 
 func Test_Store_TokenRead_Expired(t *testing.T) {
-	store, err := initStore()
+	store, err := initStoreExpiresAtPastAllowed()
 
 	if err != nil {
 		t.Fatalf("Test_Store_TokenRead_Expired: Expected [err] to be nil received [%v]", err.Error())
@@ -541,7 +646,7 @@ func Test_Store_TokenRead_Expired(t *testing.T) {
 }
 
 func Test_TokensRead_SkipsExpired(t *testing.T) {
-	store, err := initStore()
+	store, err := initStoreExpiresAtPastAllowed()
 
 	if err != nil {
 		t.Fatalf("Test_TokensRead_SkipsExpired: Expected [err] to be nil received [%v]", err.Error())
@@ -662,7 +767,7 @@ func Test_Store_TokenRenew_NonExistent(t *testing.T) {
 }
 
 func Test_Store_TokensExpiredSoftDelete(t *testing.T) {
-	store, err := initStore()
+	store, err := initStoreExpiresAtPastAllowed()
 
 	if err != nil {
 		t.Fatalf("Test_Store_TokensExpiredSoftDelete: Expected [err] to be nil received [%v]", err.Error())
@@ -722,7 +827,7 @@ func Test_Store_TokensExpiredSoftDelete(t *testing.T) {
 }
 
 func Test_Store_TokensExpiredDelete(t *testing.T) {
-	store, err := initStore()
+	store, err := initStoreExpiresAtPastAllowed()
 
 	if err != nil {
 		t.Fatalf("Test_Store_TokensExpiredDelete: Expected [err] to be nil received [%v]", err.Error())
@@ -887,8 +992,8 @@ func Test_Store_TokensReadToResolvedMap(t *testing.T) {
 	if err == nil {
 		t.Fatal("Expected error for non-existent token, got nil")
 	}
-	if !strings.Contains(err.Error(), "missing tokens") {
-		t.Fatalf("Expected 'missing tokens' error, got: [%v]", err.Error())
+	if !errors.Is(err, ErrTokenNotFound) || strings.Contains(err.Error(), "non_existent_token") {
+		t.Fatalf("Expected ErrTokenNotFound without the token, got: [%v]", err.Error())
 	}
 
 	// Test with wrong password
@@ -984,3 +1089,105 @@ func Test_Store_TokensReadToResolvedMap_ExpiredToken(t *testing.T) {
 		t.Fatalf("Expected 1 item in result (expired token skipped), got %d", len(resolved))
 	}
 }
+
+func Test_Store_TokensExpireWhere(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	tokens := []string{}
+	for i := 0; i < 3; i++ {
+		token, err := store.TokenCreate(ctx, "test_val", password, 20)
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+		tokens = append(tokens, token)
+	}
+
+	// Queries without filters are rejected to avoid expiring everything by accident
+	_, err = store.TokensExpireWhere(ctx, RecordQuery(), time.Now().UTC())
+	if err == nil {
+		t.Fatal("Expected error for query without filters")
+	}
+
+	// Force-expire all tokens created before a future "breach date"
+	breachDate := time.Now().UTC().Add(time.Hour).Format("2006-01-02 15:04:05")
+	count, err := store.TokensExpireWhere(ctx, RecordQuery().SetCreatedAtBefore(breachDate), time.Now().UTC().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("TokensExpireWhere: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected [3] expired tokens received [%d]", count)
+	}
+
+	for _, token := range tokens {
+		_, err := store.TokenRead(ctx, token, password)
+		if !errors.Is(err, ErrTokenExpired) {
+			t.Fatalf("Expected [ErrTokenExpired] received [%v]", err)
+		}
+	}
+
+	// Tokens created after the date are not matched
+	count, err = store.TokensExpireWhere(ctx, RecordQuery().SetCreatedAtBefore("2000-01-01 00:00:00"), time.Time{})
+	if err != nil {
+		t.Fatalf("TokensExpireWhere: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 0 {
+		t.Fatalf("Expected [0] updated tokens received [%d]", count)
+	}
+}
+
+func Test_Store_TokenRestore(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "restored", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenSoftDelete(ctx, token); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenRestore(ctx, token); err != nil {
+		t.Fatalf("TokenRestore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "restored" {
+		t.Fatalf("TokenRead: Expected [restored] received [%v]", value)
+	}
+
+	// Restoring a token that is not soft deleted does nothing
+	if err := store.TokenRestore(ctx, token); err != nil {
+		t.Fatalf("TokenRestore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenDelete(ctx, token); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenRestore(ctx, token); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("TokenRestore: Expected [ErrTokenNotFound] for a deleted token received [%v]", err)
+	}
+
+	if err := store.RecordRestoreByID(ctx, "unknown"); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("RecordRestoreByID: Expected [ErrRecordNotFound] received [%v]", err)
+	}
+}
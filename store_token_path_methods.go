@@ -0,0 +1,166 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TOKEN_PATH_SEPARATOR separates the segments of path-style custom tokens, e.g. "app/prod/db/password"
+const TOKEN_PATH_SEPARATOR = "/"
+
+// ErrTokenPathInvalid is returned for a path-style token or prefix with empty, "." or ".."
+// segments, unsupported characters, or longer than TOKEN_MAX_TOTAL_LENGTH
+var ErrTokenPathInvalid = errors.New("token path must be segments of letters, digits, dots, dashes and underscores separated by '/' (max 40 chars)")
+
+// tokenPathRegex matches the paths of one or more segments separated by slashes
+var tokenPathRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]+(/[A-Za-z0-9_.\-]+)*$`)
+
+// IsTokenPath checks that the token is a valid path, e.g. "app/prod/db/password".
+// Custom tokens containing a slash must be valid paths.
+func IsTokenPath(token string) bool {
+	if len(token) > TOKEN_MAX_TOTAL_LENGTH || !tokenPathRegex.MatchString(token) {
+		return false
+	}
+
+	for _, segment := range strings.Split(token, TOKEN_PATH_SEPARATOR) {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateTokenPath checks the custom tokens containing a slash, other custom tokens can have any format
+func validateTokenPath(token string) error {
+	if strings.Contains(token, TOKEN_PATH_SEPARATOR) && !IsTokenPath(token) {
+		return ErrTokenPathInvalid
+	}
+	return nil
+}
+
+// TokenListByPrefix lists the children of a path, like the LIST operation of
+// HashiCorp Vault KV. The prefix is a path ending with a slash, e.g. "app/prod/".
+// Children holding a secret are returned by name, e.g. "api-key", children with
+// paths below them end with a slash, e.g. "db/". Soft deleted and expired tokens are
+// excluded, like the tokens TokenRead does not return.
+//
+// Unlike TokensCountByPrefix the prefix is matched case-sensitively on all databases,
+// with a query using the index of the token column.
+//
+// Example:
+//
+//	err := store.TokenCreateCustom(ctx, "app/prod/db/password", value, password)
+//	children, err := store.TokenListByPrefix(ctx, "app/prod/") // [db/]
+//
+// Parameters:
+// - ctx: The context
+// - prefix: The path prefix, ending with a slash
+//
+// Returns:
+// - children: The names of the children, sorted
+// - err: ErrTokenPathInvalid, or an error if something went wrong
+func (store *storeImplementation) TokenListByPrefix(ctx context.Context, prefix string) ([]string, error) {
+	ctx, span := store.traceStart(ctx, "TokenListByPrefix")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenListByPrefix", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if !strings.HasSuffix(prefix, TOKEN_PATH_SEPARATOR) || !IsTokenPath(strings.TrimSuffix(prefix, TOKEN_PATH_SEPARATOR)) {
+		return []string{}, ErrTokenPathInvalid
+	}
+
+	seen := map[string]bool{}
+	children := []string{}
+
+	// The subtree is read in pages ordered by token, along the token index
+	cursor := ""
+	for {
+		tokens := []string{}
+		err := store.tokensPathPrefixFilter(prefix)(store.vaultDB(ctx)).
+			Where(COLUMN_VAULT_TOKEN+" > ?", cursor).
+			Order(COLUMN_VAULT_TOKEN+" ASC").
+			Limit(maxRecordsInMemory).
+			Pluck(COLUMN_VAULT_TOKEN, &tokens).Error
+		if err != nil {
+			return []string{}, err
+		}
+
+		if len(tokens) == 0 {
+			break
+		}
+		cursor = tokens[len(tokens)-1]
+
+		for _, token := range tokens {
+			// Case-insensitive collations match other cases too
+			if !strings.HasPrefix(token, prefix) {
+				continue
+			}
+
+			child := strings.TrimPrefix(token, prefix)
+			if i := strings.Index(child, TOKEN_PATH_SEPARATOR); i >= 0 {
+				child = child[:i+1]
+			}
+
+			if child == "" || seen[child] {
+				continue
+			}
+
+			seen[child] = true
+			children = append(children, child)
+		}
+	}
+
+	sort.Strings(children)
+	return children, nil
+}
+
+// tokensPathPrefixFilter returns the filter selecting the records neither soft deleted nor
+// expired with a token starting with the path prefix. The prefix is a valid path, so has no wildcards of
+// GLOB. SQLite uses the token index for GLOB, not for the case-insensitive LIKE, the
+// other databases for LIKE, PostgreSQL with the index of tokenPathIndexMigrate.
+func (store *storeImplementation) tokensPathPrefixFilter(prefix string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = store.recordQueryFilter(db, RecordQueryActive())
+
+		if store.gormDB.Dialector.Name() == DIALECT_SQLITE {
+			return db.Where(COLUMN_VAULT_TOKEN+" GLOB ?", prefix+"*")
+		}
+
+		return db.Where(COLUMN_VAULT_TOKEN+" LIKE ? ESCAPE '"+likeEscapeChar+"'", likePrefixPattern(prefix))
+	}
+}
+
+// tokenPathIndexMigrate adds the index used by the prefix queries of PostgreSQL to the
+// vault table. The unique token index serves LIKE only with the C collation.
+func (store *storeImplementation) tokenPathIndexMigrate(tableName string) error {
+	if store.gormDB.Dialector.Name() != DIALECT_POSTGRES {
+		return nil
+	}
+
+	indexName := tokenPathIndexName(tableName)
+	if store.gormDB.Migrator().HasIndex(tableName, indexName) {
+		return nil
+	}
+
+	return store.gormDB.Exec(
+		"CREATE INDEX ? ON ? (? text_pattern_ops)",
+		clause.Column{Name: indexName},
+		clause.Table{Name: tableName},
+		clause.Column{Name: COLUMN_VAULT_TOKEN},
+	).Error
+}
+
+// tokenPathIndexName returns the name of the PostgreSQL index of the token prefix queries
+func tokenPathIndexName(tableName string) string {
+	return "idx_" + tableName + "_" + COLUMN_VAULT_TOKEN + "_pattern"
+}
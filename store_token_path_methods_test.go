@@ -0,0 +1,96 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Store_TokenListByPrefix(t *testing.T) {
+	store, err := initStoreExpiresAtPastAllowed()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	paths := []string{
+		"app/prod/db/password",
+		"app/prod/db/username",
+		"app/prod/api-key",
+		"app/prod_old/api-key",
+		"app/staging/api-key",
+		"App/prod/other",
+	}
+	for _, path := range paths {
+		if err := store.TokenCreateCustom(ctx, path, "secret", password); err != nil {
+			t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	if err := store.TokenSoftDelete(ctx, "app/staging/api-key"); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	expired := TokenCreateOptions{ExpiresAt: time.Now().Add(-time.Minute)}
+	if err := store.TokenCreateCustom(ctx, "app/prod/cache/key", "secret", password, expired); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	children, err := store.TokenListByPrefix(ctx, "app/prod/")
+	if err != nil {
+		t.Fatalf("TokenListByPrefix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !reflect.DeepEqual(children, []string{"api-key", "db/"}) {
+		t.Fatalf("Expected [api-key db/] received %v", children)
+	}
+
+	children, err = store.TokenListByPrefix(ctx, "app/")
+	if err != nil {
+		t.Fatalf("TokenListByPrefix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !reflect.DeepEqual(children, []string{"prod/", "prod_old/"}) {
+		t.Fatalf("Expected [prod/ prod_old/] received %v", children)
+	}
+
+	children, err = store.TokenListByPrefix(ctx, "app/missing/")
+	if err != nil {
+		t.Fatalf("TokenListByPrefix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(children) != 0 {
+		t.Fatalf("Expected no children received %v", children)
+	}
+}
+
+func Test_Store_TokenPathInvalid(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	for _, path := range []string{"/app", "app/", "app//db", "app/../db", "app/d b", "app/prod/this/path/is/longer/than/the/column"} {
+		if err := store.TokenCreateCustom(ctx, path, "secret", password); !errors.Is(err, ErrTokenPathInvalid) {
+			t.Fatalf("Expected [ErrTokenPathInvalid] for [%v] received [%v]", path, err)
+		}
+	}
+
+	for _, prefix := range []string{"", "app", "/", "app//", "app/%/"} {
+		if _, err := store.TokenListByPrefix(ctx, prefix); !errors.Is(err, ErrTokenPathInvalid) {
+			t.Fatalf("Expected [ErrTokenPathInvalid] for [%v] received [%v]", prefix, err)
+		}
+	}
+
+	// Custom tokens without a slash keep any format
+	if err := store.TokenCreateCustom(ctx, "custom token", "secret", password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+}
@@ -0,0 +1,226 @@
+package vaultstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// ErrTokenQuarantined is returned when reading or updating a quarantined token.
+// The returned error wraps it and includes the quarantine reason.
+var ErrTokenQuarantined = errors.New("token is quarantined")
+
+// ErrTokenNotQuarantined is returned when repairing a token that is not quarantined
+var ErrTokenNotQuarantined = errors.New("token is not quarantined")
+
+// QuarantinedToken describes a quarantined token
+type QuarantinedToken struct {
+	Token         string `json:"-"`
+	Reason        string `json:"reason"`
+	QuarantinedAt string `json:"quarantined_at"`
+}
+
+// TokenQuarantine isolates a token that fails decryption or integrity checks.
+// The record is kept untouched as evidence, but reading or updating the token
+// returns ErrTokenQuarantined with the reason until it is repaired with TokenRepair.
+// Quarantining an already quarantined token replaces the reason.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to quarantine
+// - reason: The quarantine reason, e.g. "checksum mismatch"
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenQuarantine(ctx context.Context, token string, reason string) error {
+	ctx, span := store.traceStart(ctx, "TokenQuarantine")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenQuarantine", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, err := store.tokenQuarantineRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	return store.recordQuarantine(ctx, entry, reason)
+}
+
+// QuarantinedList returns the quarantined tokens of the vault table, oldest quarantine first
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - quarantined: The quarantined tokens with their reason
+// - err: An error if something went wrong
+func (store *storeImplementation) QuarantinedList(ctx context.Context) ([]QuarantinedToken, error) {
+	ctx, span := store.traceStart(ctx, "QuarantinedList")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "QuarantinedList", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var metas []gormVaultMeta
+	err := store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_RECORD, META_KEY_QUARANTINE).
+		Find(&metas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	quarantinedByRecordID := map[string]QuarantinedToken{}
+	for _, meta := range metas {
+		var quarantine QuarantinedToken
+		if err := json.Unmarshal([]byte(meta.Value), &quarantine); err != nil {
+			return nil, err
+		}
+		quarantinedByRecordID[strings.TrimPrefix(meta.ObjectID, RECORD_META_ID_PREFIX)] = quarantine
+	}
+
+	quarantined := []QuarantinedToken{}
+	for _, recordIDs := range lo.Chunk(lo.Keys(quarantinedByRecordID), maxRecordsInMemory) {
+		// Records of other vault tables sharing the meta table are not found and skipped
+		records, err := store.RecordList(ctx, RecordQuery().
+			SetIDIn(recordIDs).
+			SetColumns([]string{COLUMN_ID, COLUMN_VAULT_TOKEN}).
+			SetSoftDeletedInclude(IsSoftDeletedMetaIncluded(ctx)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			quarantine := quarantinedByRecordID[record.GetID()]
+			quarantine.Token = record.GetToken()
+			quarantined = append(quarantined, quarantine)
+		}
+	}
+
+	sort.Slice(quarantined, func(i, j int) bool {
+		return quarantined[i].QuarantinedAt < quarantined[j].QuarantinedAt
+	})
+
+	return quarantined, nil
+}
+
+// TokenRepair replaces the stored ciphertext of a quarantined token, e.g. with the
+// value restored from a backup or re-encrypted offline, and lifts the quarantine.
+// The ciphertext is stored as is, it is not decrypted or re-encrypted.
+//
+// # If the value changed since it was read, ErrValueMismatch is returned
+//
+// Parameters:
+// - ctx: The context
+// - token: The quarantined token to repair
+// - newCiphertext: The encrypted value to store
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenRepair(ctx context.Context, token string, newCiphertext string) error {
+	ctx, span := store.traceStart(ctx, "TokenRepair")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenRepair", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if newCiphertext == "" {
+		return errors.New("ciphertext is empty")
+	}
+
+	entry, err := store.tokenQuarantineRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	meta, err := store.metaFind(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(entry.GetID()), META_KEY_QUARANTINE)
+	if err != nil {
+		return err
+	}
+
+	if meta == nil {
+		return ErrTokenNotQuarantined
+	}
+
+	swapped, err := store.recordValueSwap(ctx, entry.GetID(), entry.GetValue(), newCiphertext)
+	if err != nil {
+		return err
+	}
+
+	if !swapped {
+		return ErrValueMismatch
+	}
+
+	return store.metaDelete(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(entry.GetID()), META_KEY_QUARANTINE)
+}
+
+// tokenQuarantineRecord finds the record of the token to quarantine or repair
+func (store *storeImplementation) tokenQuarantineRecord(ctx context.Context, token string) (RecordInterface, error) {
+	if token == "" {
+		return nil, errors.New("token is empty")
+	}
+
+	entry, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return entry, nil
+}
+
+// recordQuarantine flags the record as quarantined in the meta table
+func (store *storeImplementation) recordQuarantine(ctx context.Context, record RecordInterface, reason string) error {
+	quarantine, err := json.Marshal(QuarantinedToken{
+		Reason:        reason,
+		QuarantinedAt: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+	})
+	if err != nil {
+		return err
+	}
+
+	return store.metaSet(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(record.GetID()), META_KEY_QUARANTINE, string(quarantine))
+}
+
+// tokenQuarantineCheck returns an error wrapping ErrTokenQuarantined if the record is quarantined
+func (store *storeImplementation) tokenQuarantineCheck(ctx context.Context, record RecordInterface) error {
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_QUARANTINE)
+	if err != nil {
+		return err
+	}
+
+	return tokenQuarantineMetaCheck(meta)
+}
+
+// tokenQuarantineMetaCheck returns an error wrapping ErrTokenQuarantined if the quarantine meta row is set
+func tokenQuarantineMetaCheck(meta *gormVaultMeta) error {
+	if meta == nil {
+		return nil
+	}
+
+	var quarantine QuarantinedToken
+	if err := json.Unmarshal([]byte(meta.Value), &quarantine); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", ErrTokenQuarantined, quarantine.Reason)
+}
@@ -0,0 +1,101 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Store_TokenQuarantine(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	otherToken, err := store.TokenCreate(ctx, "other", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenQuarantine(ctx, token, "checksum mismatch")
+	if err != nil {
+		t.Fatalf("TokenQuarantine: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Reads and updates fail with the reason, the record is kept
+	_, err = store.TokenRead(ctx, token, password)
+	if !errors.Is(err, ErrTokenQuarantined) {
+		t.Fatalf("Expected [ErrTokenQuarantined] received [%v]", err)
+	}
+
+	if !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected the error to contain the reason received [%v]", err.Error())
+	}
+
+	err = store.TokenUpdate(ctx, token, "new secret", password)
+	if !errors.Is(err, ErrTokenQuarantined) {
+		t.Fatalf("Expected [ErrTokenQuarantined] received [%v]", err)
+	}
+
+	// Batch reads skip quarantined tokens
+	values, err := store.TokensRead(ctx, []string{token, otherToken}, password)
+	if err != nil {
+		t.Fatalf("TokensRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(values) != 1 || values[otherToken] != "other" {
+		t.Fatalf("Expected only the other token received [%v]", values)
+	}
+
+	quarantined, err := store.QuarantinedList(ctx)
+	if err != nil {
+		t.Fatalf("QuarantinedList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(quarantined) != 1 || quarantined[0].Token != token || quarantined[0].Reason != "checksum mismatch" {
+		t.Fatalf("Expected the quarantined token received [%v]", quarantined)
+	}
+
+	// Repair with a ciphertext restored from elsewhere
+	otherRecord, err := store.RecordFindByToken(ctx, otherToken)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenRepair(ctx, token, otherRecord.GetValue())
+	if err != nil {
+		t.Fatalf("TokenRepair: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "other" {
+		t.Fatalf("Expected the repaired value received [%v]", value)
+	}
+
+	quarantined, err = store.QuarantinedList(ctx)
+	if err != nil {
+		t.Fatalf("QuarantinedList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(quarantined) != 0 {
+		t.Fatalf("Expected no quarantined tokens received [%v]", quarantined)
+	}
+
+	err = store.TokenRepair(ctx, token, otherRecord.GetValue())
+	if !errors.Is(err, ErrTokenNotQuarantined) {
+		t.Fatalf("Expected [ErrTokenNotQuarantined] received [%v]", err)
+	}
+}
@@ -0,0 +1,66 @@
+package vaultstore
+
+import (
+	"context"
+)
+
+// TokenReadAndDelete reads the value of a token and permanently deletes it in a
+// single transaction, for one-time secrets. When several callers race for the
+// same token, exactly one receives the value, the others get ErrTokenNotFound.
+//
+// The token is only deleted once its value is decrypted, so a wrong password
+// does not consume it.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read and delete
+// - password: The password to use for decryption
+//
+// Returns:
+// - value: The value of the token
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenReadAndDelete(ctx context.Context, token string, password string) (value string, err error) {
+	ctx, span := store.traceStart(ctx, "TokenReadAndDelete")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenReadAndDelete", token); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	err = store.transaction(ctx, func(ctx context.Context) error {
+		entry, decoded, err := store.tokenReadRecord(ctx, token, password)
+		if err != nil {
+			return err
+		}
+
+		// The delete is conditional on the record still existing, so only one
+		// of concurrent readers of the same token succeeds
+		result := store.vaultDB(ctx).
+			Where(COLUMN_ID+" = ?", entry.GetID()).
+			Delete(&gormVaultRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrTokenNotFound
+		}
+
+		if err := store.valueChunksDelete(ctx, []string{entry.GetID()}); err != nil {
+			return err
+		}
+
+		if err := store.recordMetaDelete(ctx, []string{entry.GetID()}); err != nil {
+			return err
+		}
+
+		value = decoded
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
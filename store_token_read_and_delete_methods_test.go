@@ -0,0 +1,115 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func Test_Store_TokenReadAndDelete(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "one-time secret", password, 20, TokenCreateOptions{
+		ContentType: CONTENT_TYPE_TEXT,
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// A wrong password does not consume the token
+	_, err = store.TokenReadAndDelete(ctx, token, "wrong_password_that_is_long_enough_32chars")
+	if err == nil {
+		t.Fatal("TokenReadAndDelete: Expected an error for a wrong password")
+	}
+
+	value, err := store.TokenReadAndDelete(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenReadAndDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "one-time secret" {
+		t.Fatalf("Expected [one-time secret] received [%v]", value)
+	}
+
+	exists, err := store.TokenExists(ctx, token)
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if exists {
+		t.Fatal("Expected the token to be deleted")
+	}
+
+	if _, err := store.TokenReadAndDelete(ctx, token, password); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Expected [ErrTokenNotFound] received [%v]", err)
+	}
+
+	// The metadata is deleted with the record
+	var metaCount int64
+	err = store.(*storeImplementation).metaDB(ctx).
+		Where(COLUMN_META_KEY+" = ?", META_KEY_CONTENT_TYPE).
+		Count(&metaCount).Error
+	if err != nil {
+		t.Fatalf("Count: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if metaCount != 0 {
+		t.Fatalf("Expected no meta left received [%v]", metaCount)
+	}
+}
+
+func Test_Store_TokenReadAndDelete_Concurrent(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+	db.SetMaxOpenConns(1)
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_read_and_delete",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "shared once", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	values := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := store.TokenReadAndDelete(ctx, token, password)
+			if err == nil {
+				values <- value
+			}
+		}()
+	}
+	wg.Wait()
+	close(values)
+
+	if len(values) != 1 {
+		t.Fatalf("Expected exactly 1 reader to receive the value received [%v]", len(values))
+	}
+
+	if value := <-values; value != "shared once" {
+		t.Fatalf("Expected [shared once] received [%v]", value)
+	}
+}
@@ -0,0 +1,192 @@
+package vaultstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// ErrTokenRevoked is returned when reading or updating a revoked token.
+// The returned error wraps it and includes the revocation reason.
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// TokenRevocation describes a revoked token
+type TokenRevocation struct {
+	Token     string `json:"-"`
+	Reason    string `json:"reason"`
+	RevokedAt string `json:"revoked_at"`
+}
+
+// TokenRevoke revokes a token. Unlike deleting, the record is kept for audit,
+// but reading or updating the token returns ErrTokenRevoked with the reason.
+// Revoking an already revoked token replaces the reason.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to revoke
+// - reason: The revocation reason, e.g. "compromised"
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenRevoke(ctx context.Context, token string, reason string) error {
+	ctx, span := store.traceStart(ctx, "TokenRevoke")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenRevoke", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, err := store.tokenRevocationRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	revocation, err := json.Marshal(TokenRevocation{
+		Reason:    reason,
+		RevokedAt: carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+	})
+	if err != nil {
+		return err
+	}
+
+	return store.metaSet(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(entry.GetID()), META_KEY_REVOCATION, string(revocation))
+}
+
+// TokenUnrevoke removes the revocation of a token, e.g. to undo a mistaken TokenRevoke
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to unrevoke
+//
+// Returns:
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenUnrevoke(ctx context.Context, token string) error {
+	ctx, span := store.traceStart(ctx, "TokenUnrevoke")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenUnrevoke", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, err := store.tokenRevocationRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	return store.metaDelete(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(entry.GetID()), META_KEY_REVOCATION)
+}
+
+// RevokedList returns the revoked tokens of the vault table, oldest revocation first.
+// Revoked tokens of soft deleted records are only listed with WithSoftDeletedMeta.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - revocations: The revoked tokens with their reason
+// - err: An error if something went wrong
+func (store *storeImplementation) RevokedList(ctx context.Context) ([]TokenRevocation, error) {
+	ctx, span := store.traceStart(ctx, "RevokedList")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "RevokedList", ""); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var metas []gormVaultMeta
+	err := store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_RECORD, META_KEY_REVOCATION).
+		Find(&metas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	revocationsByRecordID := map[string]TokenRevocation{}
+	for _, meta := range metas {
+		var revocation TokenRevocation
+		if err := json.Unmarshal([]byte(meta.Value), &revocation); err != nil {
+			return nil, err
+		}
+		revocationsByRecordID[strings.TrimPrefix(meta.ObjectID, RECORD_META_ID_PREFIX)] = revocation
+	}
+
+	revocations := []TokenRevocation{}
+	for _, recordIDs := range lo.Chunk(lo.Keys(revocationsByRecordID), maxRecordsInMemory) {
+		// Soft deleted records are only listed with WithSoftDeletedMeta, e.g. for audit views.
+		// Records of other vault tables sharing the meta table are not found and skipped.
+		records, err := store.RecordList(ctx, RecordQuery().
+			SetIDIn(recordIDs).
+			SetColumns([]string{COLUMN_ID, COLUMN_VAULT_TOKEN}).
+			SetSoftDeletedInclude(IsSoftDeletedMetaIncluded(ctx)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			revocation := revocationsByRecordID[record.GetID()]
+			revocation.Token = record.GetToken()
+			revocations = append(revocations, revocation)
+		}
+	}
+
+	sort.Slice(revocations, func(i, j int) bool {
+		return revocations[i].RevokedAt < revocations[j].RevokedAt
+	})
+
+	return revocations, nil
+}
+
+// tokenRevocationRecord finds the record of the token to revoke or unrevoke
+func (store *storeImplementation) tokenRevocationRecord(ctx context.Context, token string) (RecordInterface, error) {
+	if token == "" {
+		return nil, errors.New("token is empty")
+	}
+
+	entry, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return entry, nil
+}
+
+// tokenRevocationCheck returns an error wrapping ErrTokenRevoked if the record is revoked
+func (store *storeImplementation) tokenRevocationCheck(ctx context.Context, record RecordInterface) error {
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_REVOCATION)
+	if err != nil {
+		return err
+	}
+
+	return tokenRevocationMetaCheck(meta)
+}
+
+// tokenRevocationMetaCheck returns an error wrapping ErrTokenRevoked if the revocation meta row is set
+func tokenRevocationMetaCheck(meta *gormVaultMeta) error {
+	if meta == nil {
+		return nil
+	}
+
+	var revocation TokenRevocation
+	if err := json.Unmarshal([]byte(meta.Value), &revocation); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", ErrTokenRevoked, revocation.Reason)
+}
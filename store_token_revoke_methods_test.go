@@ -0,0 +1,97 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Store_TokenRevoke(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "secret", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	otherToken, err := store.TokenCreate(ctx, "other", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenRevoke(ctx, token, "compromised")
+	if err != nil {
+		t.Fatalf("TokenRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Reads fail with the reason, the record is kept
+	_, err = store.TokenRead(ctx, token, password)
+	if !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("Expected [ErrTokenRevoked] received [%v]", err)
+	}
+
+	if !strings.Contains(err.Error(), "compromised") {
+		t.Fatalf("Expected the error to contain the reason received [%v]", err.Error())
+	}
+
+	exists, err := store.TokenExists(ctx, token)
+	if err != nil || !exists {
+		t.Fatalf("Expected the revoked token to still exist received [%v] [%v]", exists, err)
+	}
+
+	// Updates are rejected too
+	err = store.TokenUpdate(ctx, token, "new secret", password)
+	if !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("Expected [ErrTokenRevoked] received [%v]", err)
+	}
+
+	// Batch reads skip revoked tokens
+	values, err := store.TokensRead(ctx, []string{token, otherToken}, password)
+	if err != nil {
+		t.Fatalf("TokensRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(values) != 1 || values[otherToken] != "other" {
+		t.Fatalf("Expected only the non revoked token received [%v]", values)
+	}
+
+	revocations, err := store.RevokedList(ctx)
+	if err != nil {
+		t.Fatalf("RevokedList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(revocations) != 1 || revocations[0].Token != token || revocations[0].Reason != "compromised" {
+		t.Fatalf("Expected one revocation of [%v] received [%v]", token, revocations)
+	}
+
+	// Undo the revocation
+	err = store.TokenUnrevoke(ctx, token)
+	if err != nil {
+		t.Fatalf("TokenUnrevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret" {
+		t.Fatalf("Expected [secret] received [%v]", value)
+	}
+
+	revocations, err = store.RevokedList(ctx)
+	if err != nil {
+		t.Fatalf("RevokedList: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(revocations) != 0 {
+		t.Fatalf("Expected no revocations received [%v]", revocations)
+	}
+}
@@ -0,0 +1,460 @@
+package vaultstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// STREAM_CHUNK_SIZE is the size in bytes of the plaintext chunks of streamed values,
+// stored in the value chunk table encrypted and base64 encoded
+const STREAM_CHUNK_SIZE = 32 * 1024
+
+// streamKeySize is the size in bytes of the random AES-256 key of each stream
+const streamKeySize = 32
+
+// streamChunksPerQuery is the number of chunks read per query by TokenReadToWriter
+const streamChunksPerQuery = 32
+
+// Chunk flags, authenticated with the chunk so that a truncated stream is detected
+const (
+	streamChunkMore  byte = 0
+	streamChunkFinal byte = 1
+)
+
+// ErrTokenStreamed is returned when reading a token created with TokenCreateFromReader
+// other than with TokenReadToWriter, instead of its stream key
+var ErrTokenStreamed = errors.New("token is streamed, read it with TokenReadToWriter")
+
+// ErrTokenStreamChunkingDisabled is returned by TokenCreateFromReader when the store has
+// no value chunk table, see NewStoreOptions.ValueChunkThreshold
+var ErrTokenStreamChunkingDisabled = errors.New("streamed values require the value chunk table, set ValueChunkThreshold")
+
+// ErrTokenStreamIncomplete is returned when reading a streamed token whose chunks are
+// missing, e.g. while it is being written or after an interrupted write
+var ErrTokenStreamIncomplete = errors.New("token stream is incomplete")
+
+// TokenCreateFromReader creates a token holding the content read from r, encrypted chunk
+// by chunk, for large values such as file attachments or backups. Only one chunk is held
+// in memory, and the key is derived from the password once.
+//
+// Each stream has a random key, stored as the token value encrypted with the password,
+// encrypting its chunks with AES-GCM. The chunks are stored in the value chunk table,
+// which requires NewStoreOptions.ValueChunkThreshold. The record is marked as streamed
+// by a META_KEY_STREAM meta row holding the hash of the key, so no value written with
+// TokenCreate can pass for a stream key. Read the content back with TokenReadToWriter.
+// Changing the password re-encrypts the key only, replacing the value with TokenUpdate
+// makes it a regular token.
+//
+// The token exists from the first chunk on, reading it before the end of the write
+// returns ErrTokenStreamIncomplete. A failed write deletes the token.
+//
+// Parameters:
+// - ctx: The context
+// - r: The reader of the content
+// - password: The password to use for encryption
+// - tokenLength: The length of the token to generate
+// - options: The expiration, content type and read limit, the idempotency key is not used
+//
+// Returns:
+// - token: The created token
+// - err: ErrTokenStreamChunkingDisabled, or an error if something went wrong
+func (store *storeImplementation) TokenCreateFromReader(ctx context.Context, r io.Reader, password string, tokenLength int, options ...TokenCreateOptions) (token string, err error) {
+	ctx, span := store.traceStart(ctx, "TokenCreateFromReader")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenCreateFromReader", ""); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if !store.isValueChunkingEnabled() {
+		return "", ErrTokenStreamChunkingDisabled
+	}
+
+	if err := store.validatePassword(password); err != nil {
+		return "", err
+	}
+
+	if err := validateTokenCreateOptions(options); err != nil {
+		return "", err
+	}
+
+	options, err = store.tokenCreateOptionsNormalize(options)
+	if err != nil {
+		return "", err
+	}
+
+	options, err = store.tokenCreateOptionsWithRetention(options)
+	if err != nil {
+		return "", err
+	}
+
+	key := make([]byte, streamKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", fmt.Errorf("failed to generate stream key: %w", err)
+	}
+
+	aead, err := streamAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	encodedKey := base64Encode(key)
+
+	entry, err := store.streamRecordCreate(ctx, encodedKey, password, tokenLength, options)
+	if err != nil {
+		return "", err
+	}
+
+	if err := store.streamChunksWrite(ctx, entry, streamHash(encodedKey), aead, r); err != nil {
+		// The chunks written so far are deleted with the record
+		if deleteErr := store.RecordDeleteByID(context.WithoutCancel(ctx), entry.GetID()); deleteErr != nil {
+			return "", fmt.Errorf("%w (cleanup failed: %s)", err, deleteErr.Error())
+		}
+		return "", err
+	}
+
+	return entry.GetToken(), nil
+}
+
+// TokenReadToWriter writes the value of a token to w, decrypting the chunks of tokens
+// created with TokenCreateFromReader one at a time. Other tokens are written whole.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token to read
+// - password: The password to use for decryption
+// - w: The writer of the value
+//
+// Returns:
+// - err: ErrTokenStreamIncomplete, or an error if something went wrong. Part of the
+// value may have been written when a chunk fails.
+func (store *storeImplementation) TokenReadToWriter(ctx context.Context, token string, password string, w io.Writer) error {
+	ctx, span := store.traceStart(ctx, "TokenReadToWriter")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenReadToWriter", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, decoded, streamed, err := store.tokenReadRecordValue(ctx, token, password, true)
+	if err != nil {
+		return err
+	}
+
+	if !streamed {
+		_, err := io.WriteString(w, decoded)
+		return err
+	}
+
+	key, err := base64Decode(decoded)
+	if err != nil {
+		return fmt.Errorf("invalid stream key of token: %w", err)
+	}
+
+	aead, err := streamAEAD([]byte(key))
+	if err != nil {
+		return err
+	}
+
+	return store.streamChunksRead(ctx, entry, streamHash(decoded), aead, w)
+}
+
+// streamHash returns the hash of the encoded stream key, the value of the META_KEY_STREAM
+// meta row and the value hash of the stream chunks
+func streamHash(encodedKey string) string {
+	return valueChunkHash(encodedKey)
+}
+
+// tokenStreamed checks whether the decrypted value is the stream key of a streamed token.
+// The value must match the META_KEY_STREAM meta row, a value replaced after the stream
+// was written makes the token a regular one.
+func tokenStreamed(meta *gormVaultMeta, value string) bool {
+	return meta != nil && meta.Value == streamHash(value)
+}
+
+// tokenStreamedCheck returns ErrTokenStreamed if the decrypted value of the record is its stream key
+func (store *storeImplementation) tokenStreamedCheck(ctx context.Context, record RecordInterface, value string) error {
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_STREAM)
+	if err != nil {
+		return err
+	}
+
+	if tokenStreamed(meta, value) {
+		return ErrTokenStreamed
+	}
+
+	return nil
+}
+
+// streamRecordCreate creates the record of a streamed token under a new token, with its stream marker
+func (store *storeImplementation) streamRecordCreate(ctx context.Context, encodedKey string, password string, tokenLength int, options []TokenCreateOptions) (RecordInterface, error) {
+	encryptedKey, err := encode(ctx, encodedKey, password, store.cryptoConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode stream key: %w", err)
+	}
+
+	maxAttempts := 3
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		token, err := generateToken(tokenLength)
+		if err != nil {
+			return nil, err
+		}
+
+		// Soft deleted tokens cannot be reused
+		existing, err := store.tokenFindIncludingSoftDeleted(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			continue
+		}
+
+		entry := store.newRecord().
+			SetToken(token).
+			SetValue(encryptedKey).
+			SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+			SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		if len(options) > 0 && !options[0].ExpiresAt.IsZero() {
+			entry.SetExpiresAt(carbon.CreateFromStdTime(options[0].ExpiresAt).ToDateTimeString(carbon.UTC))
+		}
+
+		if err := store.RecordCreate(ctx, entry); err != nil {
+			continue
+		}
+
+		if err := store.metaCreate(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(entry.GetID()), META_KEY_STREAM, streamHash(encodedKey)); err != nil {
+			return nil, err
+		}
+
+		if len(options) > 0 {
+			if err := store.tokenContentTypeCreate(ctx, entry, options[0].ContentType); err != nil {
+				return nil, err
+			}
+
+			if err := store.tokenReadsRemainingCreate(ctx, entry, options[0].MaxReads); err != nil {
+				return nil, err
+			}
+		}
+
+		return entry, nil
+	}
+
+	return nil, errors.New("failed to create token")
+}
+
+// streamChunksWrite encrypts and stores the content of r chunk by chunk. The next chunk
+// is read before storing the current one, to flag the final chunk.
+func (store *storeImplementation) streamChunksWrite(ctx context.Context, entry RecordInterface, hash string, aead cipher.AEAD, r io.Reader) error {
+	current := make([]byte, STREAM_CHUNK_SIZE)
+	next := make([]byte, STREAM_CHUNK_SIZE)
+
+	n, err := io.ReadFull(r, current)
+	final := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	if err != nil && !final {
+		return err
+	}
+
+	for sequence := uint64(0); ; sequence++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		nextN := 0
+		if !final {
+			nextN, err = io.ReadFull(r, next)
+			if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return err
+			}
+			// A full chunk followed by nothing is the final one
+			final = nextN == 0
+		}
+
+		flag := streamChunkMore
+		if final {
+			flag = streamChunkFinal
+		}
+
+		sealed := aead.Seal([]byte{flag}, streamNonce(aead, sequence), current[:n], streamChunkAAD(sequence, flag))
+		err = store.valueChunkDB(ctx).Create(&gormVaultChunk{
+			RecordID:  entry.GetID(),
+			ValueHash: hash,
+			Sequence:  int(sequence),
+			Data:      base64Encode(sealed),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if final {
+			return nil
+		}
+
+		current, next = next, current
+		n = nextN
+		final = nextN < STREAM_CHUNK_SIZE
+	}
+}
+
+// streamChunksRead decrypts the chunks of a streamed record in order and writes them to w
+func (store *storeImplementation) streamChunksRead(ctx context.Context, entry RecordInterface, hash string, aead cipher.AEAD, w io.Writer) error {
+	sequence := uint64(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var rows []gormVaultChunk
+		err := store.valueChunkDB(ctx).
+			Where("record_id = ? AND value_hash = ? AND sequence >= ?", entry.GetID(), hash, sequence).
+			Order("sequence ASC").
+			Limit(streamChunksPerQuery).
+			Find(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			return ErrTokenStreamIncomplete
+		}
+
+		for _, row := range rows {
+			if row.Sequence != int(sequence) {
+				return ErrTokenStreamIncomplete
+			}
+
+			sealed, err := base64Decode(row.Data)
+			if err != nil || len(sealed) < 1 {
+				return fmt.Errorf("invalid stream chunk %d: %w", sequence, ErrDecryptionFailed)
+			}
+
+			flag := sealed[0]
+			plaintext, err := aead.Open(nil, streamNonce(aead, sequence), []byte(sealed[1:]), streamChunkAAD(sequence, flag))
+			if err != nil {
+				return fmt.Errorf("stream chunk %d: %w", sequence, ErrDecryptionFailed)
+			}
+
+			if _, err := w.Write(plaintext); err != nil {
+				return err
+			}
+
+			if flag == streamChunkFinal {
+				return nil
+			}
+
+			sequence++
+		}
+	}
+}
+
+// streamChunksEach hands the chunks of the streamed records among the meta rows
+// to fn, a page of streamChunksPerQuery chunks at a time, for exports and copies
+func (store *storeImplementation) streamChunksEach(ctx context.Context, metas []gormVaultMeta, fn func(rows []gormVaultChunk) error) error {
+	if !store.isValueChunkingEnabled() {
+		return nil
+	}
+
+	for _, meta := range metas {
+		if meta.ObjectType != OBJECT_TYPE_RECORD || meta.Key != META_KEY_STREAM {
+			continue
+		}
+
+		recordID := strings.TrimPrefix(meta.ObjectID, RECORD_META_ID_PREFIX)
+		sequence := 0
+
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			var rows []gormVaultChunk
+			err := store.valueChunkDB(ctx).
+				Where("record_id = ? AND value_hash = ? AND sequence >= ?", recordID, meta.Value, sequence).
+				Order("sequence ASC").
+				Limit(streamChunksPerQuery).
+				Find(&rows).Error
+			if err != nil {
+				return err
+			}
+
+			if len(rows) == 0 {
+				break
+			}
+
+			if err := fn(rows); err != nil {
+				return err
+			}
+
+			sequence = rows[len(rows)-1].Sequence + 1
+		}
+	}
+
+	return nil
+}
+
+// streamChunksImport writes exported or copied chunks of streamed records, replacing
+// the chunks at the same position, so repeated imports do not duplicate them
+func (store *storeImplementation) streamChunksImport(ctx context.Context, rows []gormVaultChunk) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if !store.isValueChunkingEnabled() {
+		return ErrTokenStreamChunkingDisabled
+	}
+
+	for _, row := range rows {
+		err := store.valueChunkDB(ctx).
+			Where("record_id = ? AND value_hash = ? AND sequence = ?", row.RecordID, row.ValueHash, row.Sequence).
+			Delete(&gormVaultChunk{}).Error
+		if err != nil {
+			return err
+		}
+
+		row.ID = 0
+		if err := store.valueChunkDB(ctx).Create(&row).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// streamAEAD returns the AES-GCM cipher of a stream key
+func streamAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// streamNonce returns the nonce of a chunk, its sequence number. The key is random
+// per stream, so the nonces are never reused with the same key.
+func streamNonce(aead cipher.AEAD, sequence uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], sequence)
+	return nonce
+}
+
+// streamChunkAAD returns the additional data of a chunk, binding its position and flag
+func streamChunkAAD(sequence uint64, flag byte) []byte {
+	aad := make([]byte, 9)
+	binary.BigEndian.PutUint64(aad, sequence)
+	aad[8] = flag
+	return aad
+}
@@ -0,0 +1,233 @@
+package vaultstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// initStoreWithValueChunks returns a store with the value chunk table, which holds the streamed values
+func initStoreWithValueChunks(t *testing.T) *storeImplementation {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:      "vault_stream",
+		VaultMetaTableName:  "vault_meta",
+		DB:                  db,
+		AutomigrateEnabled:  true,
+		ValueChunkThreshold: 64 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	return store
+}
+
+func Test_Store_TokenCreateFromReader(t *testing.T) {
+	store := initStoreWithValueChunks(t)
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	sizes := []int{0, 10, STREAM_CHUNK_SIZE, 2*STREAM_CHUNK_SIZE + 10}
+
+	for _, size := range sizes {
+		content := make([]byte, size)
+		if _, err := rand.Read(content); err != nil {
+			t.Fatalf("rand.Read: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		token, err := store.TokenCreateFromReader(ctx, bytes.NewReader(content), password, 20)
+		if err != nil {
+			t.Fatalf("TokenCreateFromReader(%d): Expected [err] to be nil received [%v]", size, err.Error())
+		}
+
+		var buffer bytes.Buffer
+		if err := store.TokenReadToWriter(ctx, token, password, &buffer); err != nil {
+			t.Fatalf("TokenReadToWriter(%d): Expected [err] to be nil received [%v]", size, err.Error())
+		}
+
+		if !bytes.Equal(buffer.Bytes(), content) {
+			t.Fatalf("TokenReadToWriter(%d): Expected content of %d bytes received %d bytes", size, size, buffer.Len())
+		}
+
+		if _, err := store.TokenRead(ctx, token, password); !errors.Is(err, ErrTokenStreamed) {
+			t.Fatalf("TokenRead: Expected [ErrTokenStreamed] received [%v]", err)
+		}
+
+		if _, err := store.TokensRead(ctx, []string{token}, password); !errors.Is(err, ErrTokenStreamed) {
+			t.Fatalf("TokensRead: Expected [ErrTokenStreamed] received [%v]", err)
+		}
+	}
+}
+
+func Test_Store_TokenCreateFromReader_ChunkingDisabled(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	_, err = store.TokenCreateFromReader(context.Background(), strings.NewReader("content"), password, 20)
+	if !errors.Is(err, ErrTokenStreamChunkingDisabled) {
+		t.Fatalf("Expected [ErrTokenStreamChunkingDisabled] received [%v]", err)
+	}
+}
+
+func Test_Store_TokenReadToWriter_NotStreamed(t *testing.T) {
+	store := initStoreWithValueChunks(t)
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "plain value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var buffer bytes.Buffer
+	if err := store.TokenReadToWriter(ctx, token, password, &buffer); err != nil {
+		t.Fatalf("TokenReadToWriter: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if buffer.String() != "plain value" {
+		t.Fatalf("TokenReadToWriter: Expected [plain value] received [%v]", buffer.String())
+	}
+
+	// The stream marker is not part of the value, any value can be stored
+	token, err = store.TokenCreate(ctx, "vaultstore:stream:AAAA", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "vaultstore:stream:AAAA" {
+		t.Fatalf("TokenRead: Expected [vaultstore:stream:AAAA] received [%v]", value)
+	}
+}
+
+func Test_Store_TokenReadToWriter_ValueChanges(t *testing.T) {
+	store := initStoreWithValueChunks(t)
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	newPassword := "new_password_that_is_long_enough_for_security_32chars"
+
+	content := bytes.Repeat([]byte("b"), STREAM_CHUNK_SIZE+10)
+	token, err := store.TokenCreateFromReader(ctx, bytes.NewReader(content), password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreateFromReader: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Changing the password keeps the chunks
+	if err := store.TokenChangePassword(ctx, token, password, newPassword); err != nil {
+		t.Fatalf("TokenChangePassword: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var buffer bytes.Buffer
+	if err := store.TokenReadToWriter(ctx, token, newPassword, &buffer); err != nil {
+		t.Fatalf("TokenReadToWriter: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !bytes.Equal(buffer.Bytes(), content) {
+		t.Fatalf("TokenReadToWriter: Expected content of %d bytes received %d bytes", len(content), buffer.Len())
+	}
+
+	// A replaced value makes it a regular token
+	if err := store.TokenUpdate(ctx, token, "replaced", newPassword); err != nil {
+		t.Fatalf("TokenUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, newPassword)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "replaced" {
+		t.Fatalf("TokenRead: Expected [replaced] received [%v]", value)
+	}
+}
+
+func Test_Store_TokenReadToWriter_Incomplete(t *testing.T) {
+	store := initStoreWithValueChunks(t)
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	content := bytes.Repeat([]byte("a"), 2*STREAM_CHUNK_SIZE+10)
+	token, err := store.TokenCreateFromReader(ctx, bytes.NewReader(content), password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreateFromReader: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Truncate the stream by deleting its final chunk
+	err = store.valueChunkDB(ctx).
+		Where("record_id = ? AND sequence = ?", record.GetID(), 2).
+		Delete(&gormVaultChunk{}).Error
+	if err != nil {
+		t.Fatalf("Delete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var buffer bytes.Buffer
+	err = store.TokenReadToWriter(ctx, token, password, &buffer)
+	if !errors.Is(err, ErrTokenStreamIncomplete) {
+		t.Fatalf("TokenReadToWriter: Expected [ErrTokenStreamIncomplete] received [%v]", err)
+	}
+}
+
+func Test_Store_TokenReadToWriter_WrongPassword(t *testing.T) {
+	store := initStoreWithValueChunks(t)
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreateFromReader(ctx, strings.NewReader("content"), password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreateFromReader: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var buffer bytes.Buffer
+	if err := store.TokenReadToWriter(ctx, token, "another_password_that_is_long_enough_32chars", &buffer); err == nil {
+		t.Fatal("TokenReadToWriter: Expected an error with the wrong password")
+	}
+
+	if buffer.Len() != 0 {
+		t.Fatalf("TokenReadToWriter: Expected nothing written received %d bytes", buffer.Len())
+	}
+}
+
+func Test_streamChunk_Tampered(t *testing.T) {
+	key := make([]byte, streamKeySize)
+	aead, err := streamAEAD(key)
+	if err != nil {
+		t.Fatalf("streamAEAD: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	sealed := aead.Seal(nil, streamNonce(aead, 0), []byte("chunk"), streamChunkAAD(0, streamChunkMore))
+
+	// A chunk flagged as final, or moved to another position, fails authentication
+	if _, err := aead.Open(nil, streamNonce(aead, 0), sealed, streamChunkAAD(0, streamChunkFinal)); err == nil {
+		t.Fatal("Open: Expected an error for a changed flag")
+	}
+
+	if _, err := aead.Open(nil, streamNonce(aead, 1), sealed, streamChunkAAD(1, streamChunkMore)); err == nil {
+		t.Fatal("Open: Expected an error for a changed position")
+	}
+}
@@ -0,0 +1,284 @@
+package vaultstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// TOKEN_VERSION_CURRENT selects the current value of a token in TokenDiff
+const TOKEN_VERSION_CURRENT = 0
+
+// Change types of a TokenDiffChange
+const (
+	TOKEN_DIFF_ADDED   = "added"
+	TOKEN_DIFF_REMOVED = "removed"
+	TOKEN_DIFF_CHANGED = "changed"
+)
+
+// ErrTokenVersionNotFound is returned when a token has no such version
+var ErrTokenVersionNotFound = errors.New("token version does not exist")
+
+// TokenDiffResult is the difference between two versions of a token value.
+// It never contains the values themselves.
+type TokenDiffResult struct {
+	// Changed reports whether the two values differ
+	Changed bool
+	// JSON reports whether both values are JSON, so Changes is set
+	JSON bool
+	// Changes lists the changed JSON paths (e.g. "$.db.password", "$.hosts[1]"), sorted by path
+	Changes []TokenDiffChange
+}
+
+// TokenDiffChange is a changed JSON path
+type TokenDiffChange struct {
+	Path string
+	Type string // TOKEN_DIFF_ADDED, TOKEN_DIFF_REMOVED or TOKEN_DIFF_CHANGED
+}
+
+// TokenVersions returns the versions of a token, oldest first. The last version is the
+// current value. Previous versions are only kept with NewStoreOptions.TokenVersioningEnabled.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token
+//
+// Returns:
+// - versions: The version numbers, starting at 1
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenVersions(ctx context.Context, token string) ([]int, error) {
+	ctx, span := store.traceStart(ctx, "TokenVersions")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenVersions", token); err != nil {
+		return nil, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, err := store.tokenVersionRecord(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := store.tokenVersionCount(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0, count+1)
+	for version := 1; version <= int(count)+1; version++ {
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// TokenDiff compares two versions of a token value, e.g. for reviewing a change.
+// JSON values are compared structurally and the changed paths are listed,
+// other values are only reported as changed or unchanged.
+//
+// Parameters:
+// - ctx: The context
+// - token: The token
+// - versionA: The first version (see TokenVersions), or TOKEN_VERSION_CURRENT
+// - versionB: The second version, or TOKEN_VERSION_CURRENT
+// - password: The password to use for decryption
+//
+// Returns:
+// - diff: The difference between the versions
+// - err: An error if something went wrong
+func (store *storeImplementation) TokenDiff(ctx context.Context, token string, versionA int, versionB int, password string) (TokenDiffResult, error) {
+	ctx, span := store.traceStart(ctx, "TokenDiff")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenDiff", token); err != nil {
+		return TokenDiffResult{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	entry, err := store.tokenVersionRecord(ctx, token)
+	if err != nil {
+		return TokenDiffResult{}, err
+	}
+
+	if err := store.tokenRevocationCheck(ctx, entry); err != nil {
+		return TokenDiffResult{}, err
+	}
+
+	if err := store.tokenQuarantineCheck(ctx, entry); err != nil {
+		return TokenDiffResult{}, err
+	}
+
+	if err := store.tokenCheckoutCheck(ctx, entry); err != nil {
+		return TokenDiffResult{}, err
+	}
+
+	if err := store.tokenBreakGlassCheck(ctx, entry); err != nil {
+		return TokenDiffResult{}, err
+	}
+
+	valueA, err := store.tokenVersionValue(ctx, entry, versionA, password)
+	if err != nil {
+		return TokenDiffResult{}, err
+	}
+
+	valueB, err := store.tokenVersionValue(ctx, entry, versionB, password)
+	if err != nil {
+		return TokenDiffResult{}, err
+	}
+
+	diff := TokenDiffResult{Changed: valueA != valueB, Changes: []TokenDiffChange{}}
+
+	var jsonA, jsonB any
+	if json.Unmarshal([]byte(valueA), &jsonA) != nil || json.Unmarshal([]byte(valueB), &jsonB) != nil {
+		return diff, nil
+	}
+
+	diff.JSON = true
+	diff.Changes = jsonDiff("$", jsonA, jsonB, diff.Changes)
+	// Formatting only changes are not changes of the document
+	diff.Changed = len(diff.Changes) > 0
+
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].Path < diff.Changes[j].Path
+	})
+
+	return diff, nil
+}
+
+// tokenVersionRecord finds the record of the token
+func (store *storeImplementation) tokenVersionRecord(ctx context.Context, token string) (RecordInterface, error) {
+	if token == "" {
+		return nil, errors.New("token is empty")
+	}
+
+	entry, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return entry, nil
+}
+
+// tokenVersionCount returns the number of previous versions kept for the record
+func (store *storeImplementation) tokenVersionCount(ctx context.Context, record RecordInterface) (int64, error) {
+	var count int64
+	err := store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ?", OBJECT_TYPE_RECORD_VERSION, recordMetaObjectID(record.GetID())).
+		Count(&count).Error
+	return count, err
+}
+
+// tokenVersionValue returns the decrypted value of a version of the record
+func (store *storeImplementation) tokenVersionValue(ctx context.Context, record RecordInterface, version int, password string) (string, error) {
+	count, err := store.tokenVersionCount(ctx, record)
+	if err != nil {
+		return "", err
+	}
+
+	if version < 0 || version > int(count)+1 {
+		return "", fmt.Errorf("%w: %d", ErrTokenVersionNotFound, version)
+	}
+
+	ciphertext := record.GetValue()
+	if version != TOKEN_VERSION_CURRENT && version <= int(count) {
+		meta, err := store.metaFind(ctx, OBJECT_TYPE_RECORD_VERSION, recordMetaObjectID(record.GetID()), appendedChunkKey(version))
+		if err != nil {
+			return "", err
+		}
+
+		if meta == nil {
+			return "", fmt.Errorf("%w: %d", ErrTokenVersionNotFound, version)
+		}
+
+		ciphertext = meta.Value
+	}
+
+	return decode(ctx, ciphertext, password, store.cryptoConfig)
+}
+
+// tokenVersionArchive keeps the current ciphertext of the record as its latest
+// previous version, before the value is updated
+func (store *storeImplementation) tokenVersionArchive(ctx context.Context, record RecordInterface) error {
+	if !store.tokenVersioningEnabled {
+		return nil
+	}
+
+	var err error
+	for attempt := 0; attempt < tokenAppendMaxAttempts; attempt++ {
+		var count int64
+		count, err = store.tokenVersionCount(ctx, record)
+		if err != nil {
+			return err
+		}
+
+		// The unique meta index rejects a version number taken by a concurrent update
+		err = store.metaCreate(ctx, OBJECT_TYPE_RECORD_VERSION, recordMetaObjectID(record.GetID()), appendedChunkKey(int(count)+1), record.GetValue())
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to keep token version: %w", err)
+}
+
+// jsonDiff appends the changed paths between two decoded JSON values
+func jsonDiff(path string, a any, b any, changes []TokenDiffChange) []TokenDiffChange {
+	switch typedA := a.(type) {
+	case map[string]any:
+		typedB, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+
+		for key, valueA := range typedA {
+			valueB, exists := typedB[key]
+			if !exists {
+				changes = append(changes, TokenDiffChange{Path: path + "." + key, Type: TOKEN_DIFF_REMOVED})
+				continue
+			}
+			changes = jsonDiff(path+"."+key, valueA, valueB, changes)
+		}
+
+		for key := range typedB {
+			if _, exists := typedA[key]; !exists {
+				changes = append(changes, TokenDiffChange{Path: path + "." + key, Type: TOKEN_DIFF_ADDED})
+			}
+		}
+
+		return changes
+	case []any:
+		typedB, ok := b.([]any)
+		if !ok {
+			break
+		}
+
+		for i := 0; i < max(len(typedA), len(typedB)); i++ {
+			elementPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(typedB):
+				changes = append(changes, TokenDiffChange{Path: elementPath, Type: TOKEN_DIFF_REMOVED})
+			case i >= len(typedA):
+				changes = append(changes, TokenDiffChange{Path: elementPath, Type: TOKEN_DIFF_ADDED})
+			default:
+				changes = jsonDiff(elementPath, typedA[i], typedB[i], changes)
+			}
+		}
+
+		return changes
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		changes = append(changes, TokenDiffChange{Path: path, Type: TOKEN_DIFF_CHANGED})
+	}
+
+	return changes
+}
@@ -0,0 +1,92 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Store_TokenDiff(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:         "vault_versions",
+		VaultMetaTableName:     "vault_meta",
+		DB:                     db,
+		AutomigrateEnabled:     true,
+		TokenVersioningEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, `{"db":{"user":"app","password":"one"},"hosts":["a","b"]}`, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.TokenUpdate(ctx, token, `{"db":{"user":"app","password":"two"},"hosts":["a"],"port":5432}`, password)
+	if err != nil {
+		t.Fatalf("TokenUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	versions, err := store.TokenVersions(ctx, token)
+	if err != nil {
+		t.Fatalf("TokenVersions: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !reflect.DeepEqual(versions, []int{1, 2}) {
+		t.Fatalf("Expected versions [1 2] received [%v]", versions)
+	}
+
+	diff, err := store.TokenDiff(ctx, token, 1, TOKEN_VERSION_CURRENT, password)
+	if err != nil {
+		t.Fatalf("TokenDiff: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	expected := []TokenDiffChange{
+		{Path: "$.db.password", Type: TOKEN_DIFF_CHANGED},
+		{Path: "$.hosts[1]", Type: TOKEN_DIFF_REMOVED},
+		{Path: "$.port", Type: TOKEN_DIFF_ADDED},
+	}
+
+	if !diff.Changed || !diff.JSON || !reflect.DeepEqual(diff.Changes, expected) {
+		t.Fatalf("Expected changes %v received [%+v]", expected, diff)
+	}
+
+	diff, err = store.TokenDiff(ctx, token, 2, TOKEN_VERSION_CURRENT, password)
+	if err != nil {
+		t.Fatalf("TokenDiff: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if diff.Changed || len(diff.Changes) != 0 {
+		t.Fatalf("Expected no changes received [%+v]", diff)
+	}
+
+	_, err = store.TokenDiff(ctx, token, 1, 3, password)
+	if !errors.Is(err, ErrTokenVersionNotFound) {
+		t.Fatalf("Expected [ErrTokenVersionNotFound] received [%v]", err)
+	}
+
+	// Non JSON values are only flagged as changed
+	err = store.TokenUpdate(ctx, token, "plain text", password)
+	if err != nil {
+		t.Fatalf("TokenUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	diff, err = store.TokenDiff(ctx, token, 2, 3, password)
+	if err != nil {
+		t.Fatalf("TokenDiff: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !diff.Changed || diff.JSON {
+		t.Fatalf("Expected a changed non JSON value received [%+v]", diff)
+	}
+}
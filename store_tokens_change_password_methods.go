@@ -2,6 +2,7 @@ package vaultstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 )
@@ -11,6 +12,13 @@ import (
 // Be conservative, some records can be large
 const maxRecordsInMemory = 1000
 
+// rekeyMaxAttempts is the number of times a record is re-read and rekeyed
+// when its value keeps being changed concurrently
+const rekeyMaxAttempts = 5
+
+// ErrRekeyConflict is returned when a record kept being changed concurrently during a password change
+var ErrRekeyConflict = errors.New("record was changed concurrently too many times during password change")
+
 // getParallelThreshold returns the configured threshold for parallel processing
 // Returns 10000 if not configured (default)
 func (store *storeImplementation) getParallelThreshold() int {
@@ -54,6 +62,14 @@ func (store *storeImplementation) getParallelThreshold() int {
 //   - Context cancellation: Returns number processed so far, context error
 //   - Mixed password records: Only changes password for records matching old password
 func (store *storeImplementation) TokensChangePassword(ctx context.Context, oldPassword, newPassword string) (int, error) {
+	ctx, span := store.traceStart(ctx, "TokensChangePassword")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokensChangePassword", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
 	if err := store.validatePassword(oldPassword); err != nil {
 		return 0, err
 	}
@@ -61,6 +77,64 @@ func (store *storeImplementation) TokensChangePassword(ctx context.Context, oldP
 		return 0, err
 	}
 
+	return store.tokensChangePasswordRecords(ctx, oldPassword, newPassword)
+}
+
+// TokenChangePassword re-encrypts the value of a single token with a new password.
+//
+// The token must be readable, the same checks as TokenRead apply, but the change does
+// not count against the read limit. The write is conditional on the value not having
+// changed since it was read, so a concurrent TokenUpdate is never overwritten.
+//
+// Parameters:
+//   - ctx: The context
+//   - token: The token to change the password of
+//   - oldPassword: The current password of the token
+//   - newPassword: The new password
+//
+// Returns:
+//   - error: ErrDecryptionFailed if the old password is wrong, or an error if something went wrong
+func (store *storeImplementation) TokenChangePassword(ctx context.Context, token string, oldPassword, newPassword string) error {
+	ctx, span := store.traceStart(ctx, "TokenChangePassword")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokenChangePassword", token); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := store.validatePassword(oldPassword); err != nil {
+		return err
+	}
+	if err := store.validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	entry, _, err := store.tokenReadableRecord(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if _, err := decode(ctx, entry.GetValue(), oldPassword, store.cryptoConfig); err != nil {
+		return err
+	}
+
+	rekeyed, err := store.recordRekey(ctx, entry, oldPassword, newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Changed to another password or deleted concurrently
+	if !rekeyed {
+		return ErrDecryptionFailed
+	}
+
+	return nil
+}
+
+// tokensChangePasswordRecords changes the password of the record values,
+// choosing the processing strategy based on the dataset size
+func (store *storeImplementation) tokensChangePasswordRecords(ctx context.Context, oldPassword, newPassword string) (int, error) {
 	// Get total count first to determine strategy
 	totalCount, err := store.RecordCount(ctx, RecordQuery())
 	if err != nil {
@@ -102,26 +176,14 @@ func (store *storeImplementation) tokensChangePasswordSequential(ctx context.Con
 		default:
 		}
 
-		// Try to decrypt with old password
-		decryptedValue, err := decode(rec.GetValue(), oldPassword, store.cryptoConfig)
+		rekeyed, err := store.recordRekey(ctx, rec, oldPassword, newPassword)
 		if err != nil {
-			// Record doesn't use old password, skip it
-			continue
+			return changed, err
 		}
 
-		// Re-encrypt with new password
-		encodedValue, err := encode(decryptedValue, newPassword, store.cryptoConfig)
-		if err != nil {
-			return changed, fmt.Errorf("failed to encode value for record %s: %w", rec.GetID(), err)
-		}
-
-		// Update record
-		rec.SetValue(encodedValue)
-		if err := store.RecordUpdate(ctx, rec); err != nil {
-			return changed, fmt.Errorf("failed to update record %s: %w", rec.GetID(), err)
+		if rekeyed {
+			changed++
 		}
-
-		changed++
 	}
 
 	return changed, nil
@@ -232,33 +294,21 @@ func (store *storeImplementation) processBatchPasswordChange(ctx context.Context
 		default:
 		}
 
-		// Try to decrypt with old password
-		decryptedValue, err := decode(rec.GetValue(), oldPassword, store.cryptoConfig)
+		rekeyed, err := store.recordRekey(ctx, rec, oldPassword, newPassword)
 		if err != nil {
-			// Record doesn't use old password, skip it
-			continue
+			return changed, err
 		}
 
-		// Re-encrypt with new password
-		encodedValue, err := encode(decryptedValue, newPassword, store.cryptoConfig)
-		if err != nil {
-			return changed, fmt.Errorf("failed to encode value for record %s: %w", rec.GetID(), err)
+		if rekeyed {
+			changed++
 		}
-
-		// Update record value
-		rec.SetValue(encodedValue)
-		if err := store.RecordUpdate(ctx, rec); err != nil {
-			return changed, fmt.Errorf("failed to update record %s: %w", rec.GetID(), err)
-		}
-
-		changed++
 	}
 
 	return changed, nil
 }
 
-// tokensChangePasswordWithCursor processes large datasets using cursor-based pagination
-// to avoid loading all records into memory at once
+// tokensChangePasswordWithCursor processes large datasets page by page, so only
+// the records of the current page are held in memory
 // Returns partial count on context cancellation - caller must check error to determine if complete
 func (store *storeImplementation) tokensChangePasswordWithCursor(ctx context.Context, oldPassword, newPassword string) (int, error) {
 	const cursorBatchSize = 1000
@@ -272,33 +322,105 @@ func (store *storeImplementation) tokensChangePasswordWithCursor(ctx context.Con
 		default:
 		}
 
-		// Fetch batch of records using pagination
-		query := RecordQuery().SetLimit(cursorBatchSize).SetOffset(offset)
-		records, err := store.RecordList(ctx, query)
+		// Order by ID so the pages stay stable while the values are rewritten
+		query := RecordQuery().
+			SetOrderBy(COLUMN_ID).
+			SetSortOrder(ASC).
+			SetLimit(cursorBatchSize).
+			SetOffset(offset)
+
+		// Collect the page first, the records are rekeyed once the result
+		// set is closed so a single connection database is not blocked
+		scanned := 0
+		pageRecords := []RecordInterface{}
+		err := store.RecordListStream(ctx, query, func(rec RecordInterface) error {
+			scanned++
+			pageRecords = append(pageRecords, rec)
+			return nil
+		})
 		if err != nil {
-			return totalChanged, fmt.Errorf("failed to list records at offset %d: %w", offset, err)
+			if ctx.Err() != nil {
+				return totalChanged, fmt.Errorf("partial password change completed %d records: %w", totalChanged, ctx.Err())
+			}
+			return totalChanged, fmt.Errorf("failed to stream records at offset %d: %w", offset, err)
 		}
 
-		// No more records to process
-		if len(records) == 0 {
-			break
-		}
+		for _, rec := range pageRecords {
+			select {
+			case <-ctx.Done():
+				return totalChanged, fmt.Errorf("partial password change completed %d records: %w", totalChanged, ctx.Err())
+			default:
+			}
 
-		// Process this batch
-		changed, err := store.tokensChangePasswordSequential(ctx, records, oldPassword, newPassword)
-		if err != nil {
-			return totalChanged, err
+			rekeyed, err := store.recordRekey(ctx, rec, oldPassword, newPassword)
+			if err != nil {
+				return totalChanged, err
+			}
+
+			if rekeyed {
+				totalChanged++
+			}
 		}
-		totalChanged += changed
 
-		// Move to next batch
-		offset += len(records)
+		// Move to next page
+		offset += scanned
 
 		// If we got fewer records than batch size, we've processed all records
-		if len(records) < cursorBatchSize {
+		if scanned < cursorBatchSize {
 			break
 		}
 	}
 
 	return totalChanged, nil
 }
+
+// recordRekey re-encrypts the value of a record with the new password if it can be
+// decrypted with the old one. The write is conditional on the value not having changed
+// since it was read, so a concurrent TokenUpdate is never overwritten with the stale value.
+// On conflict the record is read again and retried. Returns whether the record was rekeyed.
+func (store *storeImplementation) recordRekey(ctx context.Context, rec RecordInterface, oldPassword, newPassword string) (bool, error) {
+	for attempt := 0; attempt < rekeyMaxAttempts; attempt++ {
+		// Try to decrypt with old password, the records of other passwords are not decrypt failures
+		decryptedValue, err := decodeValue(ctx, rec.GetValue(), oldPassword, store.cryptoConfig)
+		if err != nil {
+			// Record doesn't use old password (anymore), skip it
+			return false, nil
+		}
+
+		// Chunks appended via TokenAppend and kept versions are encrypted separately.
+		// They are rekeyed first, so an interrupted run finds the record with the old
+		// password and rekeys the remaining ones.
+		if err := store.recordChunksRekey(ctx, rec.GetID(), oldPassword, newPassword); err != nil {
+			return false, fmt.Errorf("failed to change password of appended chunks: %w", err)
+		}
+
+		// Re-encrypt with new password
+		encodedValue, err := encode(ctx, decryptedValue, newPassword, store.cryptoConfig)
+		if err != nil {
+			return false, fmt.Errorf("failed to encode value for record %s: %w", rec.GetID(), err)
+		}
+
+		swapped, err := store.recordValueSwap(ctx, rec.GetID(), rec.GetValue(), encodedValue)
+		if err != nil {
+			return false, fmt.Errorf("failed to update record %s: %w", rec.GetID(), err)
+		}
+
+		if swapped {
+			rec.SetValue(encodedValue)
+			return true, nil
+		}
+
+		// The value was changed concurrently, rekey the current one
+		rec, err = store.RecordFindByID(ctx, rec.GetID())
+		if err != nil {
+			return false, fmt.Errorf("failed to reload record: %w", err)
+		}
+
+		if rec == nil {
+			// Deleted concurrently, nothing to rekey
+			return false, nil
+		}
+	}
+
+	return false, ErrRekeyConflict
+}
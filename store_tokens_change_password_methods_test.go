@@ -2,6 +2,9 @@ package vaultstore
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -298,3 +301,141 @@ func TestTokensChangePassword_ContextCancellation(t *testing.T) {
 	// Count should be 0 or partial
 	t.Logf("Context cancellation test: rekeyed %d records, error: %v", count, err)
 }
+
+// Run with -race: rekey while tokens are read and updated concurrently
+func TestTokensChangePassword_ConcurrentReadsAndUpdates(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// In memory sqlite databases are per connection
+	db.SetMaxOpenConns(1)
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_rekey_concurrent_test",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	oldPassword := "old-password-that-is-long-enough-32-chars"
+	newPassword := "new-password-that-is-long-enough-32-chars"
+
+	tokens := []string{}
+	for i := 0; i < 20; i++ {
+		token, err := store.TokenCreate(ctx, fmt.Sprintf("value-%d", i), oldPassword, 32)
+		if err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	// Every other token is updated during the rekey, already with the new password
+	updated := map[string]string{}
+	for i := 0; i < len(tokens); i += 2 {
+		updated[tokens[i]] = fmt.Sprintf("updated-%d", i)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(tokens)*2+1)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := store.TokensChangePassword(ctx, oldPassword, newPassword); err != nil {
+			errs <- fmt.Errorf("rekey: %w", err)
+		}
+	}()
+
+	for _, token := range tokens {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			// Once the old password fails, the value is encrypted with the new one
+			_, errOld := store.TokenRead(ctx, token, oldPassword)
+			if errOld == nil {
+				return
+			}
+			if _, errNew := store.TokenRead(ctx, token, newPassword); errNew != nil {
+				errs <- fmt.Errorf("read %s: %v, %v", token, errOld, errNew)
+			}
+		}(token)
+	}
+
+	for token, value := range updated {
+		wg.Add(1)
+		go func(token, value string) {
+			defer wg.Done()
+			if err := store.TokenUpdate(ctx, token, value, newPassword); err != nil {
+				errs <- fmt.Errorf("update %s: %w", token, err)
+			}
+		}(token, value)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// No update was lost and every token uses the new password
+	for i, token := range tokens {
+		value, err := store.TokenRead(ctx, token, newPassword)
+		if err != nil {
+			t.Fatalf("failed to read token with new password: %v", err)
+		}
+
+		expected := fmt.Sprintf("value-%d", i)
+		if updatedValue, ok := updated[token]; ok {
+			expected = updatedValue
+		}
+
+		if value != expected {
+			t.Errorf("token %s: expected [%s] received [%s]", token, expected, value)
+		}
+	}
+}
+
+func TestTokenChangePassword(t *testing.T) {
+	store := setupTestStoreForRekey(t)
+	ctx := context.Background()
+
+	oldPassword := "old-password-that-is-long-enough-32-chars"
+	newPassword := "new-password-that-is-long-enough-32-chars"
+
+	token, err := store.TokenCreate(ctx, "invite", oldPassword, 32, TokenCreateOptions{MaxReads: 1})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// A wrong password does not change it
+	err = store.TokenChangePassword(ctx, token, newPassword, oldPassword)
+	if !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", err)
+	}
+
+	if err := store.TokenChangePassword(ctx, token, oldPassword, newPassword); err != nil {
+		t.Fatalf("TokenChangePassword: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The change does not count against the read limit
+	value, err := store.TokenRead(ctx, token, newPassword)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "invite" {
+		t.Fatalf("Expected [invite] received [%v]", value)
+	}
+
+	err = store.TokenChangePassword(ctx, "missing_token", oldPassword, newPassword)
+	if !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Expected [ErrTokenNotFound] received [%v]", err)
+	}
+}
@@ -0,0 +1,123 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTokenPrefixEmpty is returned for an empty token prefix, which would select all tokens
+	ErrTokenPrefixEmpty = errors.New("token prefix is empty")
+	// ErrDeleteNotConfirmed is returned by TokensDeleteByPrefix without TokensDeleteByPrefixOptions.Confirm
+	ErrDeleteNotConfirmed = errors.New("delete not confirmed, set Confirm to delete the matching tokens")
+)
+
+// likeEscapeChar escapes the wildcards of LIKE patterns, the same on all supported databases
+const likeEscapeChar = "!"
+
+// likePrefixPattern returns the LIKE pattern matching the strings starting with prefix
+func likePrefixPattern(prefix string) string {
+	escaper := strings.NewReplacer(likeEscapeChar, likeEscapeChar+likeEscapeChar, "%", likeEscapeChar+"%", "_", likeEscapeChar+"_")
+	return escaper.Replace(prefix) + "%"
+}
+
+// TokensDeleteByPrefixOptions are the options of TokensDeleteByPrefix
+type TokensDeleteByPrefixOptions struct {
+	// Confirm must be set to delete the tokens. Without it the matching tokens
+	// are only counted, and ErrDeleteNotConfirmed is returned.
+	Confirm bool
+	// SoftDelete soft deletes the tokens instead of removing them permanently
+	SoftDelete bool
+}
+
+// TokensCountByPrefix counts the tokens starting with the prefix, e.g. "sess_",
+// excluding soft deleted tokens
+//
+// The prefix is matched with LIKE, so it follows the case sensitivity of the database:
+// it is case-insensitive on SQLite and on the default MySQL collations.
+//
+// Parameters:
+// - ctx: The context
+// - prefix: The token prefix, the LIKE wildcards % and _ are matched literally
+//
+// Returns:
+// - count: The number of matching tokens
+// - err: ErrTokenPrefixEmpty for an empty prefix, or an error if something went wrong
+func (store *storeImplementation) TokensCountByPrefix(ctx context.Context, prefix string) (int64, error) {
+	ctx, span := store.traceStart(ctx, "TokensCountByPrefix")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokensCountByPrefix", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if prefix == "" {
+		return 0, ErrTokenPrefixEmpty
+	}
+
+	var count int64
+	err := store.tokensPrefixFilter(prefix)(store.vaultDB(ctx)).Count(&count).Error
+	return count, err
+}
+
+// TokensDeleteByPrefix deletes the tokens starting with the prefix, excluding already
+// soft deleted tokens, in batches like TokensExpiredDelete. The prefix is matched as
+// by TokensCountByPrefix.
+//
+// Example:
+//
+//	count, err := store.TokensDeleteByPrefix(ctx, "sess_")  // counts, returns ErrDeleteNotConfirmed
+//	count, err = store.TokensDeleteByPrefix(ctx, "sess_", vaultstore.TokensDeleteByPrefixOptions{Confirm: true})
+//
+// Parameters:
+// - ctx: The context
+// - prefix: The token prefix
+// - options: Confirm must be set to delete, see TokensDeleteByPrefixOptions
+//
+// Returns:
+// - count: The number of deleted tokens, or the number of matching tokens if not confirmed
+// - err: ErrTokenPrefixEmpty, ErrDeleteNotConfirmed, or an error if something went wrong
+func (store *storeImplementation) TokensDeleteByPrefix(ctx context.Context, prefix string, options ...TokensDeleteByPrefixOptions) (int64, error) {
+	ctx, span := store.traceStart(ctx, "TokensDeleteByPrefix")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "TokensDeleteByPrefix", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if prefix == "" {
+		return 0, ErrTokenPrefixEmpty
+	}
+
+	option := TokensDeleteByPrefixOptions{}
+	if len(options) > 0 {
+		option = options[0]
+	}
+
+	if !option.Confirm {
+		count, err := store.TokensCountByPrefix(ctx, prefix)
+		if err != nil {
+			return 0, err
+		}
+		return count, ErrDeleteNotConfirmed
+	}
+
+	if option.SoftDelete {
+		return store.recordsSoftDeleteBatched(ctx, store.tokensPrefixFilter(prefix))
+	}
+
+	return store.recordsDeleteBatched(ctx, store.tokensPrefixFilter(prefix))
+}
+
+// tokensPrefixFilter returns the filter selecting the not soft deleted records with a token starting with prefix
+func (store *storeImplementation) tokensPrefixFilter(prefix string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return store.recordQueryFilter(db, RecordQuery()).
+			Where(COLUMN_VAULT_TOKEN+" LIKE ? ESCAPE '"+likeEscapeChar+"'", likePrefixPattern(prefix))
+	}
+}
@@ -0,0 +1,108 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_LikePrefixPattern(t *testing.T) {
+	cases := map[string]string{
+		"sess_":   "sess!_%",
+		"50%_off": "50!%!_off%",
+		"a!b":     "a!!b%",
+		"apikey":  "apikey%",
+	}
+
+	for prefix, expected := range cases {
+		if pattern := likePrefixPattern(prefix); pattern != expected {
+			t.Fatalf("%s: Expected [%v] received [%v]", prefix, expected, pattern)
+		}
+	}
+}
+
+func Test_Store_TokensByPrefix(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	for _, token := range []string{"sess_1", "sess_2", "sess_3", "sessX4", "apikey_1"} {
+		if err := store.TokenCreateCustom(ctx, token, "value", password); err != nil {
+			t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	if err := store.TokenSoftDelete(ctx, "sess_3"); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The underscore is matched literally and soft deleted tokens are excluded
+	count, err := store.TokensCountByPrefix(ctx, "sess_")
+	if err != nil {
+		t.Fatalf("TokensCountByPrefix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 2 {
+		t.Fatalf("Expected 2 tokens received [%v]", count)
+	}
+
+	if _, err := store.TokensCountByPrefix(ctx, ""); !errors.Is(err, ErrTokenPrefixEmpty) {
+		t.Fatalf("Expected ErrTokenPrefixEmpty received [%v]", err)
+	}
+
+	// Not confirmed, only counted
+	count, err = store.TokensDeleteByPrefix(ctx, "sess_")
+	if !errors.Is(err, ErrDeleteNotConfirmed) {
+		t.Fatalf("Expected ErrDeleteNotConfirmed received [%v]", err)
+	}
+
+	if count != 2 {
+		t.Fatalf("Expected 2 matching tokens received [%v]", count)
+	}
+
+	if exists, _ := store.TokenExists(ctx, "sess_1"); !exists {
+		t.Fatal("Expected the tokens to be kept without confirmation")
+	}
+
+	count, err = store.TokensDeleteByPrefix(ctx, "sess_", TokensDeleteByPrefixOptions{Confirm: true})
+	if err != nil {
+		t.Fatalf("TokensDeleteByPrefix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 2 {
+		t.Fatalf("Expected 2 deleted tokens received [%v]", count)
+	}
+
+	for token, expected := range map[string]bool{"sess_1": false, "sess_2": false, "sessX4": true, "apikey_1": true} {
+		exists, err := store.TokenExists(ctx, token)
+		if err != nil {
+			t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if exists != expected {
+			t.Fatalf("%s: Expected exists [%v] received [%v]", token, expected, exists)
+		}
+	}
+
+	count, err = store.TokensDeleteByPrefix(ctx, "apikey_", TokensDeleteByPrefixOptions{Confirm: true, SoftDelete: true})
+	if err != nil {
+		t.Fatalf("TokensDeleteByPrefix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected 1 soft deleted token received [%v]", count)
+	}
+
+	record, err := store.RecordFindByToken(ctx, "apikey_1")
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if record != nil {
+		t.Fatal("Expected the token to be soft deleted")
+	}
+}
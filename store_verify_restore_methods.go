@@ -0,0 +1,112 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+)
+
+// ErrSamplePercentInvalid is returned when the sample percentage is not in (0, 100]
+var ErrSamplePercentInvalid = errors.New("sample percent must be greater than 0 and at most 100")
+
+// VerifyRestoreFailure describes a sampled record that failed verification
+type VerifyRestoreFailure struct {
+	// Token is the token of the record
+	Token string
+	// Reason describes why the verification failed
+	Reason string
+}
+
+// VerifyRestoreReport is the result of VerifyRestore
+type VerifyRestoreReport struct {
+	// Scanned is the number of records considered for sampling
+	Scanned int
+	// Sampled is the number of records verified
+	Sampled int
+	// Verified is the number of sampled records that were read and decrypted
+	Verified int
+	// Failures lists the sampled records that failed verification
+	Failures []VerifyRestoreFailure
+}
+
+// Passed reports whether all sampled records were verified
+func (report VerifyRestoreReport) Passed() bool {
+	return len(report.Failures) == 0
+}
+
+// VerifyRestore samples the records of the vault, e.g. after restoring a backup,
+// and checks that each sampled record can be read and decrypted with the password.
+// Chunked values are validated against their checksum, and the AES-GCM
+// authentication of v2 values detects tampered or truncated ciphertexts.
+//
+// Parameters:
+// - ctx: The context
+// - samplePercent: The percentage of records to sample, greater than 0 and at most 100
+// - password: The password the sampled records are expected to be encrypted with
+//
+// Returns:
+// - report: The verification report
+// - err: An error if the verification could not be run
+func (store *storeImplementation) VerifyRestore(ctx context.Context, samplePercent float64, password string) (VerifyRestoreReport, error) {
+	ctx, span := store.traceStart(ctx, "VerifyRestore")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "VerifyRestore", ""); err != nil {
+		return VerifyRestoreReport{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	report := VerifyRestoreReport{Failures: []VerifyRestoreFailure{}}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	if samplePercent <= 0 || samplePercent > 100 {
+		return report, ErrSamplePercentInvalid
+	}
+
+	// Only select the keys while streaming, the values are read once the
+	// result set is closed so a single connection database is not blocked
+	sampled := []RecordInterface{}
+	query := RecordQuery().SetColumns([]string{COLUMN_ID, COLUMN_VAULT_TOKEN})
+	err := store.RecordListStream(ctx, query, func(record RecordInterface) error {
+		report.Scanned++
+		if rand.Float64()*100 < samplePercent {
+			sampled = append(sampled, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for _, sample := range sampled {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		report.Sampled++
+
+		record, err := store.RecordFindByID(ctx, sample.GetID())
+		if err != nil {
+			report.Failures = append(report.Failures, VerifyRestoreFailure{Token: sample.GetToken(), Reason: err.Error()})
+			continue
+		}
+
+		if record == nil {
+			report.Failures = append(report.Failures, VerifyRestoreFailure{Token: sample.GetToken(), Reason: "record not found"})
+			continue
+		}
+
+		_, err = decode(ctx, record.GetValue(), password, store.cryptoConfig)
+		if err != nil {
+			report.Failures = append(report.Failures, VerifyRestoreFailure{Token: sample.GetToken(), Reason: "decryption failed: " + err.Error()})
+			continue
+		}
+
+		report.Verified++
+	}
+
+	return report, nil
+}
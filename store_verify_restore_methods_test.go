@@ -0,0 +1,53 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_Store_VerifyRestore(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	for i := 0; i < 3; i++ {
+		_, err := store.TokenCreate(ctx, "test_value", password, 20)
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	report, err := store.VerifyRestore(ctx, 100, password)
+	if err != nil {
+		t.Fatalf("VerifyRestore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !report.Passed() || report.Scanned != 3 || report.Sampled != 3 || report.Verified != 3 {
+		t.Fatalf("Expected passing report for 3 records received %+v", report)
+	}
+
+	// A corrupted value fails verification
+	corrupted := NewRecord().SetToken("corrupted_token").SetValue("v2:corrupted")
+	if err := store.RecordCreate(ctx, corrupted); err != nil {
+		t.Fatalf("RecordCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	report, err = store.VerifyRestore(ctx, 100, password)
+	if err != nil {
+		t.Fatalf("VerifyRestore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if report.Passed() || len(report.Failures) != 1 || report.Failures[0].Token != "corrupted_token" {
+		t.Fatalf("Expected one failure for corrupted_token received %+v", report)
+	}
+
+	_, err = store.VerifyRestore(ctx, 0, password)
+	if !errors.Is(err, ErrSamplePercentInvalid) {
+		t.Fatalf("Expected [ErrSamplePercentInvalid] received [%v]", err)
+	}
+}
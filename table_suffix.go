@@ -0,0 +1,90 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// ErrTableSuffixInvalid is returned when a table suffix contains unsupported characters
+var ErrTableSuffixInvalid = errors.New("table suffix must contain only letters, digits and underscores (max 24 chars)")
+
+// tableSuffixContextKey is the context key for the per-request table suffix
+type tableSuffixContextKey struct{}
+
+// tableSuffixRegex limits suffixes to identifiers that are safe to use in table names
+var tableSuffixRegex = regexp.MustCompile(`^[A-Za-z0-9_]{1,24}$`)
+
+// WithTableSuffix returns a context that routes store operations to the
+// vault table suffixed with the given value (e.g. "vault" + "_tenantA").
+//
+// The suffixed table must exist before use, see AutoMigrateTableSuffix.
+// Vault settings and metadata remain in the shared meta table.
+//
+// Example:
+//
+//	ctx := vaultstore.WithTableSuffix(r.Context(), "tenantA")
+//	token, err := store.TokenCreate(ctx, "value", password, 20) // stored in vault_tenantA
+func WithTableSuffix(ctx context.Context, suffix string) context.Context {
+	return contextWithValue(ctx, tableSuffixContextKey{}, suffix)
+}
+
+// TableSuffixFromContext returns the table suffix set via WithTableSuffix, if any
+func TableSuffixFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	suffix, ok := ctx.Value(tableSuffixContextKey{}).(string)
+	return suffix, ok
+}
+
+// IsTableSuffixValid checks that the suffix is safe to append to a table name
+func IsTableSuffixValid(suffix string) bool {
+	return tableSuffixRegex.MatchString(suffix)
+}
+
+// suffixedTableName appends the suffix to the base table name
+func suffixedTableName(tableName string, suffix string) string {
+	return tableName + "_" + suffix
+}
+
+// vaultTableNameFromContext resolves the vault table name for the context
+func (store *storeImplementation) vaultTableNameFromContext(ctx context.Context) (string, error) {
+	suffix, ok := TableSuffixFromContext(ctx)
+	if !ok {
+		return store.vaultTableName, nil
+	}
+
+	if !IsTableSuffixValid(suffix) {
+		return "", ErrTableSuffixInvalid
+	}
+
+	return suffixedTableName(store.vaultTableName, suffix), nil
+}
+
+// vaultDB returns a GORM session scoped to the vault table and namespace resolved from
+// the context. An invalid table suffix or namespace is attached as an error, so the
+// chained operation fails.
+func (store *storeImplementation) vaultDB(ctx context.Context) *gorm.DB {
+	db := store.gormDBFromContext(ctx)
+
+	tableName, err := store.vaultTableNameFromContext(ctx)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+
+	return store.namespaceScope(ctx, db.Table(tableName))
+}
+
+// AutoMigrateTableSuffix creates or migrates the vault table for the given suffix,
+// so a new tenant can be provisioned at runtime
+func (store *storeImplementation) AutoMigrateTableSuffix(suffix string) error {
+	if !IsTableSuffixValid(suffix) {
+		return ErrTableSuffixInvalid
+	}
+
+	return store.autoMigrateVaultTable(suffixedTableName(store.vaultTableName, suffix))
+}
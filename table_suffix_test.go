@@ -0,0 +1,111 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_IsTableSuffixValid(t *testing.T) {
+	valid := []string{"tenantA", "tenant_b", "123", "A"}
+	for _, suffix := range valid {
+		if !IsTableSuffixValid(suffix) {
+			t.Fatalf("Expected suffix [%s] to be valid", suffix)
+		}
+	}
+
+	invalid := []string{"", "tenant-a", "tenant a", "x; DROP TABLE vault", "abcdefghijklmnopqrstuvwxyz"}
+	for _, suffix := range invalid {
+		if IsTableSuffixValid(suffix) {
+			t.Fatalf("Expected suffix [%s] to be invalid", suffix)
+		}
+	}
+}
+
+func Test_WithTableSuffix(t *testing.T) {
+	ctx := context.Background()
+
+	if _, ok := TableSuffixFromContext(ctx); ok {
+		t.Fatal("Expected no suffix on a plain context")
+	}
+
+	suffix, ok := TableSuffixFromContext(WithTableSuffix(ctx, "tenantA"))
+	if !ok || suffix != "tenantA" {
+		t.Fatalf("Expected suffix [tenantA] received [%v]", suffix)
+	}
+}
+
+func Test_Store_TableSuffixRouting(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.AutoMigrateTableSuffix("tenantA"); err != nil {
+		t.Fatalf("AutoMigrateTableSuffix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.AutoMigrateTableSuffix("tenantB"); err != nil {
+		t.Fatalf("AutoMigrateTableSuffix: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	ctxA := WithTableSuffix(ctx, "tenantA")
+	ctxB := WithTableSuffix(ctx, "tenantB")
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctxA, "tenant_a_value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	existsA, err := store.TokenExists(ctxA, token)
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if !existsA {
+		t.Fatal("Expected token to exist in tenantA table")
+	}
+
+	existsB, err := store.TokenExists(ctxB, token)
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if existsB {
+		t.Fatal("Expected token to not exist in tenantB table")
+	}
+
+	existsDefault, err := store.TokenExists(ctx, token)
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if existsDefault {
+		t.Fatal("Expected token to not exist in default table")
+	}
+
+	value, err := store.TokenRead(ctxA, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != "tenant_a_value" {
+		t.Fatalf("Expected [tenant_a_value] received [%v]", value)
+	}
+}
+
+func Test_Store_TableSuffixInvalid(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.AutoMigrateTableSuffix("bad-suffix"); !errors.Is(err, ErrTableSuffixInvalid) {
+		t.Fatalf("Expected ErrTableSuffixInvalid received [%v]", err)
+	}
+
+	ctx := WithTableSuffix(context.Background(), "bad-suffix")
+
+	_, err = store.TokenExists(ctx, "tk_any_token_value")
+	if !errors.Is(err, ErrTableSuffixInvalid) {
+		t.Fatalf("Expected ErrTableSuffixInvalid received [%v]", err)
+	}
+}
@@ -0,0 +1,247 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// ErrTimestampInvalid is returned when a timestamp column value is not a valid datetime
+var ErrTimestampInvalid = errors.New("invalid timestamp")
+
+// timestampColumns are the datetime columns of the vault table
+var timestampColumns = []string{
+	COLUMN_CREATED_AT,
+	COLUMN_UPDATED_AT,
+	COLUMN_EXPIRES_AT,
+	COLUMN_SOFT_DELETED_AT,
+}
+
+// isTimestampColumn returns true for the datetime columns of the vault table
+func isTimestampColumn(column string) bool {
+	for _, timestampColumn := range timestampColumns {
+		if column == timestampColumn {
+			return true
+		}
+	}
+	return false
+}
+
+// isNormalizedTimestamp returns true for the stored format, YYYY-MM-DD HH:MM:SS (UTC)
+func isNormalizedTimestamp(value string) bool {
+	if len(value) != len("2006-01-02 15:04:05") {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch i {
+		case 4, 7:
+			if value[i] != '-' {
+				return false
+			}
+		case 10:
+			if value[i] != ' ' {
+				return false
+			}
+		case 13, 16:
+			if value[i] != ':' {
+				return false
+			}
+		default:
+			if value[i] < '0' || value[i] > '9' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// recordTimeParse parses a datetime of a record, false if it is invalid or zero. The
+// stored format is parsed without carbon, which allocates on every read.
+func recordTimeParse(value string) (time.Time, bool) {
+	if isNormalizedTimestamp(value) {
+		parsed, err := time.Parse(time.DateTime, value)
+		return parsed, err == nil && !parsed.IsZero()
+	}
+
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	parsed := carbon.Parse(value, carbon.UTC)
+	if parsed.Error != nil || !parsed.IsValid() || parsed.IsZero() {
+		return time.Time{}, false
+	}
+
+	return parsed.StdTime(), true
+}
+
+// normalizeTimestamp converts a datetime in another format, e.g. RFC 3339 with an
+// offset, to the stored format in UTC. Values without a zone are taken as UTC.
+// Empty values are kept, they are read as the defaults.
+func normalizeTimestamp(value string) (string, error) {
+	if value == "" || isNormalizedTimestamp(value) {
+		return value, nil
+	}
+
+	parsed := carbon.Parse(value, carbon.UTC)
+	if parsed.Error != nil || !parsed.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrTimestampInvalid, value)
+	}
+
+	return parsed.ToDateTimeString(carbon.UTC), nil
+}
+
+// normalizeRecordTimestamps normalizes the datetime fields of a record before it is written
+func normalizeRecordTimestamps(gormRecord *gormVaultRecord) error {
+	for _, field := range []*string{&gormRecord.CreatedAt, &gormRecord.UpdatedAt, &gormRecord.ExpiresAt, &gormRecord.SoftDeletedAt} {
+		normalized, err := normalizeTimestamp(*field)
+		if err != nil {
+			return err
+		}
+		*field = normalized
+	}
+
+	return nil
+}
+
+// normalizeTimestampUpdates normalizes the datetime columns of an update.
+// Database expressions, used for database timestamps, are kept.
+func normalizeTimestampUpdates(updates map[string]interface{}) error {
+	for column, value := range updates {
+		text, ok := value.(string)
+		if !ok || !isTimestampColumn(column) {
+			continue
+		}
+
+		normalized, err := normalizeTimestamp(text)
+		if err != nil {
+			return err
+		}
+		updates[column] = normalized
+	}
+
+	return nil
+}
+
+// timestampColumnsRawSelect selects the ID and the timestamps cast to text, so the values
+// are read as stored rather than converted by the driver, e.g. to RFC 3339 by SQLite
+func (store *storeImplementation) timestampColumnsRawSelect() string {
+	textType := "TEXT"
+	switch store.gormDB.Dialector.Name() {
+	case DIALECT_MYSQL:
+		textType = "CHAR"
+	case DIALECT_SQLSERVER:
+		textType = "NVARCHAR(64)"
+	}
+
+	selectSQL := COLUMN_ID
+	for _, column := range timestampColumns {
+		selectSQL += ", CAST(" + column + " AS " + textType + ") AS " + column
+	}
+
+	return selectSQL
+}
+
+// TimestampsNormalizeReport is the result of NormalizeTimestamps
+type TimestampsNormalizeReport struct {
+	// Scanned is the number of records checked
+	Scanned int64
+	// Normalized is the number of records whose timestamps were rewritten
+	Normalized int64
+	// InvalidIDs are the IDs of the records with timestamps that cannot be parsed,
+	// they are left unchanged
+	InvalidIDs []string
+}
+
+// NormalizeTimestamps rewrites the timestamps of all records, including soft deleted
+// ones, to the stored format YYYY-MM-DD HH:MM:SS in UTC. Run it once after rows were
+// written by external tools in other formats (e.g. RFC 3339 or with a time zone
+// offset), which break the expiry and soft delete comparisons. New writes of the
+// store are normalized on the fly.
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - report: The number of scanned and normalized records, and the records left unchanged
+// - err: An error if something went wrong
+func (store *storeImplementation) NormalizeTimestamps(ctx context.Context) (TimestampsNormalizeReport, error) {
+	ctx, span := store.traceStart(ctx, "NormalizeTimestamps")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "NormalizeTimestamps", ""); err != nil {
+		return TimestampsNormalizeReport{}, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	report := TimestampsNormalizeReport{InvalidIDs: []string{}}
+	lastID := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		var gormRecords []gormVaultRecord
+		err := store.vaultDB(ctx).
+			Select(store.timestampColumnsRawSelect()).
+			Where(COLUMN_ID+" > ?", lastID).
+			Order(COLUMN_ID + " ASC").
+			Limit(maxRecordsInMemory).
+			Find(&gormRecords).Error
+		if err != nil {
+			return report, err
+		}
+
+		if len(gormRecords) == 0 {
+			return report, nil
+		}
+		lastID = gormRecords[len(gormRecords)-1].ID
+
+		for _, gormRecord := range gormRecords {
+			report.Scanned++
+
+			values := map[string]string{
+				COLUMN_CREATED_AT:      gormRecord.CreatedAt,
+				COLUMN_UPDATED_AT:      gormRecord.UpdatedAt,
+				COLUMN_EXPIRES_AT:      gormRecord.ExpiresAt,
+				COLUMN_SOFT_DELETED_AT: gormRecord.SoftDeletedAt,
+			}
+
+			updates := map[string]interface{}{}
+			invalid := false
+			for column, value := range values {
+				normalized, err := normalizeTimestamp(value)
+				if err != nil {
+					invalid = true
+					break
+				}
+				if normalized != value {
+					updates[column] = normalized
+				}
+			}
+
+			if invalid {
+				report.InvalidIDs = append(report.InvalidIDs, gormRecord.ID)
+				continue
+			}
+
+			if len(updates) == 0 {
+				continue
+			}
+
+			err := store.vaultDB(ctx).
+				Where(COLUMN_ID+" = ?", gormRecord.ID).
+				Updates(updates).Error
+			if err != nil {
+				return report, err
+			}
+			report.Normalized++
+		}
+	}
+}
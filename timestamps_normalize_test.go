@@ -0,0 +1,130 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_normalizeTimestamp(t *testing.T) {
+	cases := map[string]string{
+		"":                          "",
+		"2024-01-02 03:04:05":       "2024-01-02 03:04:05",
+		"2024-01-02T03:04:05Z":      "2024-01-02 03:04:05",
+		"2024-01-02T05:04:05+02:00": "2024-01-02 03:04:05",
+		MAX_DATETIME:                MAX_DATETIME,
+	}
+
+	for value, expected := range cases {
+		normalized, err := normalizeTimestamp(value)
+		if err != nil {
+			t.Fatalf("normalizeTimestamp(%q): Expected [err] to be nil received [%v]", value, err.Error())
+		}
+		if normalized != expected {
+			t.Fatalf("normalizeTimestamp(%q): Expected [%v] received [%v]", value, expected, normalized)
+		}
+	}
+
+	if _, err := normalizeTimestamp("not a date"); !errors.Is(err, ErrTimestampInvalid) {
+		t.Fatalf("Expected ErrTimestampInvalid received [%v]", err)
+	}
+}
+
+func Test_recordTimeParse(t *testing.T) {
+	cases := map[string]string{
+		"2024-01-02 03:04:05":       "2024-01-02 03:04:05",
+		"2024-01-02T03:04:05Z":      "2024-01-02 03:04:05",
+		"2024-01-02T05:04:05+02:00": "2024-01-02 03:04:05",
+	}
+
+	for value, expected := range cases {
+		parsed, ok := recordTimeParse(value)
+		if !ok || parsed.UTC().Format("2006-01-02 15:04:05") != expected {
+			t.Fatalf("recordTimeParse(%q): Expected [%v] received [%v] [%v]", value, expected, parsed, ok)
+		}
+	}
+
+	for _, value := range []string{"", "0000-00-00 00:00:00", "not a date"} {
+		if _, ok := recordTimeParse(value); ok {
+			t.Fatalf("recordTimeParse(%q): Expected an invalid datetime", value)
+		}
+	}
+}
+
+func Test_Store_NormalizeTimestamps(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Written by an external tool with a time zone offset, already expired in UTC
+	err = store.(*storeImplementation).gormDB.Exec("UPDATE vault_token SET expires_at = ? WHERE vault_token = ?", "2020-01-01T05:00:00+02:00", token).Error
+	if err != nil {
+		t.Fatalf("Exec: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	invalidToken, err := store.TokenCreate(ctx, "value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.(*storeImplementation).gormDB.Exec("UPDATE vault_token SET expires_at = ? WHERE vault_token = ?", "someday", invalidToken).Error
+	if err != nil {
+		t.Fatalf("Exec: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	report, err := store.NormalizeTimestamps(ctx)
+	if err != nil {
+		t.Fatalf("NormalizeTimestamps: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if report.Scanned != 2 || report.Normalized != 1 || len(report.InvalidIDs) != 1 {
+		t.Fatalf("Expected 2 scanned, 1 normalized and 1 invalid received [%+v]", report)
+	}
+
+	var expiresAt string
+	err = store.(*storeImplementation).gormDB.Raw("SELECT CAST(expires_at AS TEXT) FROM vault_token WHERE vault_token = ?", token).Scan(&expiresAt).Error
+	if err != nil {
+		t.Fatalf("Raw: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if expiresAt != "2020-01-01 03:00:00" {
+		t.Fatalf("Expected [2020-01-01 03:00:00] received [%v]", expiresAt)
+	}
+
+	if _, err := store.TokenRead(ctx, token, password); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("Expected ErrTokenExpired received [%v]", err)
+	}
+
+	// Writes in other formats are normalized
+	err = store.RecordUpdateByToken(ctx, invalidToken, map[string]string{
+		COLUMN_EXPIRES_AT: "2099-01-01T02:00:00+02:00",
+	})
+	if err != nil {
+		t.Fatalf("RecordUpdateByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	err = store.RecordUpdateByToken(ctx, invalidToken, map[string]string{
+		COLUMN_EXPIRES_AT: "someday",
+	})
+	if !errors.Is(err, ErrTimestampInvalid) {
+		t.Fatalf("Expected ErrTimestampInvalid received [%v]", err)
+	}
+
+	report, err = store.NormalizeTimestamps(ctx)
+	if err != nil {
+		t.Fatalf("NormalizeTimestamps: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if report.Normalized != 0 || len(report.InvalidIDs) != 0 {
+		t.Fatalf("Expected nothing left to normalize received [%+v]", report)
+	}
+}
@@ -0,0 +1,428 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dracory/uid"
+	"github.com/dromara/carbon/v2"
+	"gorm.io/gorm"
+)
+
+// Token bloom filter defaults
+const (
+	TOKEN_BLOOM_FILTER_DEFAULT_CAPACITY         = 100000
+	TOKEN_BLOOM_FILTER_DEFAULT_REFRESH_INTERVAL = time.Minute
+	TOKEN_BLOOM_FILTER_FALSE_POSITIVE_RATE      = 0.01
+)
+
+// tokenBloomFilterRefreshOverlap re-reads a window of already seen rows on each
+// incremental refresh to tolerate clock skew between application servers
+const tokenBloomFilterRefreshOverlap = time.Minute
+
+// == BLOOM FILTER ===========================================================
+
+// bloomFilter is a fixed size probabilistic set. It may report false positives,
+// but never false negatives for items that were added.
+type bloomFilter struct {
+	bits     []uint64
+	m        uint64 // number of bits
+	k        uint64 // number of hash functions
+	seed     maphash.Seed
+	count    int // approximate number of distinct items added
+	capacity int // expected number of items the filter was sized for
+}
+
+// newBloomFilter sizes a bloom filter for the expected number of items and false positive rate
+func newBloomFilter(expectedItems int, falsePositiveRate float64) *bloomFilter {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+
+	m := uint64(math.Ceil(-float64(expectedItems) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Max(1, math.Round(float64(m)/float64(expectedItems)*math.Ln2)))
+
+	return &bloomFilter{
+		bits:     make([]uint64, (m+63)/64),
+		m:        m,
+		k:        k,
+		seed:     maphash.MakeSeed(),
+		capacity: expectedItems,
+	}
+}
+
+// locations returns the two base hashes used for double hashing
+func (b *bloomFilter) locations(item string) (uint64, uint64) {
+	h := maphash.String(b.seed, item)
+	return h & 0xffffffff, h >> 32
+}
+
+// Add adds an item to the filter
+func (b *bloomFilter) Add(item string) {
+	if !b.MayContain(item) {
+		b.count++
+	}
+
+	h1, h2 := b.locations(item)
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % b.m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// MayContain returns false if the item was definitely never added
+func (b *bloomFilter) MayContain(item string) bool {
+	h1, h2 := b.locations(item)
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % b.m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsOverCapacity returns true when more items were added than the filter was sized for
+func (b *bloomFilter) IsOverCapacity() bool {
+	return b.count > b.capacity
+}
+
+// == TOKEN EXISTENCE FILTER =================================================
+
+// VAULT_SETTING_KEY_TOKENS_BACKFILLED is the vault setting changed by the writes adding
+// tokens the incremental refresh of the token bloom filter may miss (imports, token
+// changes), so the other store instances reload their filters
+const VAULT_SETTING_KEY_TOKENS_BACKFILLED = "tokens_backfilled"
+
+// VAULT_SETTING_KEY_TOKEN_BLOOM_FILTER_WRITER is the vault setting holding the writer
+// lease of the store instance using the token bloom filter, as "<instance> <expires at>"
+const VAULT_SETTING_KEY_TOKEN_BLOOM_FILTER_WRITER = "token_bloom_filter_writer"
+
+// ErrTokenBloomFilterWriter is returned by NewStore when the token bloom filter is enabled
+// while another store instance holds the writer lease
+var ErrTokenBloomFilterWriter = errors.New("token bloom filter requires a single writer, another store instance holds the lease")
+
+// tokenBloomFilterLeaseIntervals is the number of refresh intervals a writer lease lasts.
+// The lease is renewed on each refresh, so it lapses only if the store stops reading.
+const tokenBloomFilterLeaseIntervals = 3
+
+// tokenBloomFilter keeps one bloom filter per vault table, refreshed incrementally
+type tokenBloomFilter struct {
+	mu              sync.Mutex
+	capacity        int
+	refreshInterval time.Duration
+	tables          map[string]*tableBloomFilter
+	instance        string // identifies the store instance in the writer lease
+	leaseHeld       bool   // misses are trusted only while the writer lease is held
+}
+
+// tableBloomFilter is the bloom filter state for a single vault table
+type tableBloomFilter struct {
+	filter      *bloomFilter // nil until loaded
+	cursor      string       // created_at of the last refresh, minus overlap
+	refreshedAt time.Time    // wall clock time of the last refresh
+	backfilled  string       // VAULT_SETTING_KEY_TOKENS_BACKFILLED at the last refresh
+	refreshing  bool         // a refresh is querying the database
+	pending     []string     // items added during the refresh, kept by a full reload
+}
+
+func newTokenBloomFilter(capacity int, refreshInterval time.Duration) *tokenBloomFilter {
+	if capacity <= 0 {
+		capacity = TOKEN_BLOOM_FILTER_DEFAULT_CAPACITY
+	}
+
+	if refreshInterval <= 0 {
+		refreshInterval = TOKEN_BLOOM_FILTER_DEFAULT_REFRESH_INTERVAL
+	}
+
+	return &tokenBloomFilter{
+		capacity:        capacity,
+		refreshInterval: refreshInterval,
+		tables:          map[string]*tableBloomFilter{},
+		instance:        uid.HumanUid(),
+	}
+}
+
+// setRefreshInterval changes how often tokens created elsewhere are loaded
+func (f *tokenBloomFilter) setRefreshInterval(refreshInterval time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.refreshInterval = refreshInterval
+}
+
+// tokenMayExist checks the bloom filter for the token.
+//
+// Returns true if the bloom filter is disabled, not loaded yet, or the token may exist,
+// false only if the token is definitely not in the vault table.
+//
+// Misses are trusted only while the store holds the writer lease, see
+// tokenBloomFilterLease. Once another instance took it, every token may exist,
+// and lookups fall back to the database.
+// The database is queried without holding the filter mutex, lookups
+// during a refresh use the filter as loaded by the previous one.
+func (store *storeImplementation) tokenMayExist(ctx context.Context, token string) (bool, error) {
+	if store.tokenBloomFilter == nil {
+		return true, nil
+	}
+
+	tableName, err := store.vaultTableNameFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	namespace, err := store.namespaceFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	f := store.tokenBloomFilter
+	f.mu.Lock()
+	state, ok := f.tables[tableName]
+	if !ok {
+		state = &tableBloomFilter{}
+		f.tables[tableName] = state
+	}
+
+	refresh := !state.refreshing && time.Since(state.refreshedAt) >= f.refreshInterval
+	if refresh {
+		state.refreshing = true
+	}
+	f.mu.Unlock()
+
+	if refresh {
+		if err := store.tokenBloomFilterRefresh(ctx, tableName, state); err != nil {
+			return false, err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if state.filter == nil || !f.leaseHeld {
+		return true, nil
+	}
+
+	return state.filter.MayContain(tokenBloomFilterItem(namespace, token)), nil
+}
+
+// tokenBloomFilterAdd adds a created token, or the new token of a record, to the bloom filter
+func (store *storeImplementation) tokenBloomFilterAdd(ctx context.Context, token string) {
+	if store.tokenBloomFilter == nil {
+		return
+	}
+
+	tableName, err := store.vaultTableNameFromContext(ctx)
+	if err != nil {
+		return
+	}
+
+	namespace, err := store.namespaceFromContext(ctx)
+	if err != nil {
+		return
+	}
+
+	f := store.tokenBloomFilter
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state, ok := f.tables[tableName]
+	if !ok {
+		return
+	}
+
+	item := tokenBloomFilterItem(namespace, token)
+	if state.filter != nil {
+		state.filter.Add(item)
+	}
+
+	if state.refreshing {
+		state.pending = append(state.pending, item)
+	}
+}
+
+// tokenBloomFilterBackfilled changes the VAULT_SETTING_KEY_TOKENS_BACKFILLED vault setting
+// after writing tokens with past timestamps or changing tokens, which the incremental
+// refresh by created_at misses, so all store instances reload their filters
+func (store *storeImplementation) tokenBloomFilterBackfilled(ctx context.Context) error {
+	return store.metaSet(ctx, OBJECT_TYPE_VAULT_SETTINGS, VAULT_SETTINGS_ID, VAULT_SETTING_KEY_TOKENS_BACKFILLED, uid.HumanUid())
+}
+
+// tokenBloomFilterItem returns the bloom filter item of a token, scoped to its namespace
+func tokenBloomFilterItem(namespace string, token string) string {
+	return namespace + "\x00" + token
+}
+
+// tokenBloomFilterRow is a token loaded into the bloom filter, with its namespace if enabled
+type tokenBloomFilterRow struct {
+	Token     string `gorm:"column:vault_token"`
+	Namespace string `gorm:"column:namespace"`
+}
+
+// tokenBloomFilterRefresh loads or incrementally refreshes the filter of a table.
+// Must be called with state.refreshing set, the filter mutex is taken to update the state.
+// A changed VAULT_SETTING_KEY_TOKENS_BACKFILLED setting reloads the whole table, as the
+// created_at cursor cannot be trusted.
+func (store *storeImplementation) tokenBloomFilterRefresh(ctx context.Context, tableName string, state *tableBloomFilter) (err error) {
+	f := store.tokenBloomFilter
+
+	f.mu.Lock()
+	fullReload := state.filter == nil || state.filter.IsOverCapacity()
+	cursor := state.cursor
+	backfilled := state.backfilled
+	f.mu.Unlock()
+
+	defer func() {
+		if err != nil {
+			f.mu.Lock()
+			state.refreshing = false
+			state.pending = nil
+			f.mu.Unlock()
+		}
+	}()
+
+	refreshStartedAt := time.Now().UTC()
+
+	// A lost lease is not an error, the lookups fall back to the database
+	leaseErr := store.tokenBloomFilterLease(ctx)
+	if leaseErr != nil && !errors.Is(leaseErr, ErrTokenBloomFilterWriter) {
+		return leaseErr
+	}
+
+	backfilledMeta, err := store.metaFind(ctx, OBJECT_TYPE_VAULT_SETTINGS, VAULT_SETTINGS_ID, VAULT_SETTING_KEY_TOKENS_BACKFILLED)
+	if err != nil {
+		return err
+	}
+
+	// The raw value is compared, it changes on each write even if encrypted
+	backfilledNow := ""
+	if backfilledMeta != nil {
+		backfilledNow = backfilledMeta.Value
+	}
+
+	if backfilledNow != backfilled {
+		fullReload = true
+	}
+
+	db := store.gormDBFromContext(ctx).Table(tableName)
+
+	if !fullReload {
+		db = db.Where(COLUMN_CREATED_AT+" >= ?", cursor)
+	}
+
+	columns := []string{COLUMN_VAULT_TOKEN}
+	if store.namespacesEnabled {
+		columns = append(columns, COLUMN_NAMESPACE)
+	}
+
+	var tokens []tokenBloomFilterRow
+	if err := db.Select(columns).Find(&tokens).Error; err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	filter := state.filter
+	if fullReload {
+		filter = newBloomFilter(max(f.capacity, len(tokens)*2), TOKEN_BLOOM_FILTER_FALSE_POSITIVE_RATE)
+
+		// Tokens added during the query may be missing from its result
+		for _, item := range state.pending {
+			filter.Add(item)
+		}
+	}
+
+	for _, token := range tokens {
+		filter.Add(tokenBloomFilterItem(token.Namespace, token.Token))
+	}
+
+	f.leaseHeld = leaseErr == nil
+	state.filter = filter
+	state.refreshedAt = refreshStartedAt
+	state.cursor = carbon.CreateFromStdTime(refreshStartedAt.Add(-tokenBloomFilterRefreshOverlap)).ToDateTimeString(carbon.UTC)
+	state.backfilled = backfilledNow
+	state.refreshing = false
+	state.pending = nil
+
+	return nil
+}
+
+// tokenBloomFilterLease acquires or renews the writer lease of the store instance.
+// The bloom filter answers misses without querying the database, which is only safe
+// while no other store instance creates tokens. Returns ErrTokenBloomFilterWriter if
+// another instance holds an unexpired lease. The lease is a single vault setting row
+// updated with a compare-and-swap on its previous value.
+func (store *storeImplementation) tokenBloomFilterLease(ctx context.Context) error {
+	f := store.tokenBloomFilter
+
+	f.mu.Lock()
+	instance := f.instance
+	leaseDuration := f.refreshInterval * tokenBloomFilterLeaseIntervals
+	f.mu.Unlock()
+
+	now := time.Now().UTC()
+	lease, err := store.metaValueEncrypt(OBJECT_TYPE_VAULT_SETTINGS, VAULT_SETTING_KEY_TOKEN_BLOOM_FILTER_WRITER, instance+" "+now.Add(leaseDuration).Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	// The raw row is kept, an encrypted value changes on each write
+	var meta gormVaultMeta
+	err = store.metaDB(ctx).
+		Where(COLUMN_OBJECT_TYPE+" = ? AND "+COLUMN_OBJECT_ID+" = ? AND "+COLUMN_META_KEY+" = ?", OBJECT_TYPE_VAULT_SETTINGS, VAULT_SETTINGS_ID, VAULT_SETTING_KEY_TOKEN_BLOOM_FILTER_WRITER).
+		First(&meta).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err := store.metaDB(ctx).Create(&gormVaultMeta{
+			ObjectType: OBJECT_TYPE_VAULT_SETTINGS,
+			ObjectID:   VAULT_SETTINGS_ID,
+			Key:        VAULT_SETTING_KEY_TOKEN_BLOOM_FILTER_WRITER,
+			Value:      lease,
+		}).Error
+		if err == nil {
+			return nil
+		}
+
+		// The unique meta index lets a single instance create the lease
+		existing, findErr := store.metaFind(ctx, OBJECT_TYPE_VAULT_SETTINGS, VAULT_SETTINGS_ID, VAULT_SETTING_KEY_TOKEN_BLOOM_FILTER_WRITER)
+		if findErr == nil && existing != nil {
+			return ErrTokenBloomFilterWriter
+		}
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	current := meta
+	if err := store.metaValueDecrypt(&current); err != nil {
+		return err
+	}
+
+	owner, expiresAt, _ := strings.Cut(current.Value, " ")
+	leaseExpiresAt, err := time.Parse(time.RFC3339, expiresAt)
+	if owner != instance && err == nil && leaseExpiresAt.After(now) {
+		return ErrTokenBloomFilterWriter
+	}
+
+	result := store.metaDB(ctx).
+		Where("id = ? AND "+COLUMN_META_VALUE+" = ?", meta.ID, meta.Value).
+		Update(COLUMN_META_VALUE, lease)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// Another instance renewed or took the lease concurrently
+	if result.RowsAffected == 0 {
+		return ErrTokenBloomFilterWriter
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_bloomFilter(t *testing.T) {
+	filter := newBloomFilter(1000, TOKEN_BLOOM_FILTER_FALSE_POSITIVE_RATE)
+
+	for i := 0; i < 1000; i++ {
+		filter.Add(fmt.Sprintf("tk_added_%d", i))
+	}
+
+	// No false negatives
+	for i := 0; i < 1000; i++ {
+		if !filter.MayContain(fmt.Sprintf("tk_added_%d", i)) {
+			t.Fatalf("Expected filter to contain [tk_added_%d]", i)
+		}
+	}
+
+	// False positives stay close to the configured rate
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if filter.MayContain(fmt.Sprintf("tk_missing_%d", i)) {
+			falsePositives++
+		}
+	}
+
+	if falsePositives > 300 {
+		t.Fatalf("Expected at most 300 false positives out of 10000 received [%d]", falsePositives)
+	}
+
+	if filter.IsOverCapacity() {
+		t.Fatal("Expected filter to not be over capacity")
+	}
+
+	// Items that collide with existing bits are not counted, so add a margin
+	for i := 0; i < 100; i++ {
+		filter.Add(fmt.Sprintf("tk_over_capacity_%d", i))
+	}
+
+	if !filter.IsOverCapacity() {
+		t.Fatal("Expected filter to be over capacity")
+	}
+}
+
+func Test_Store_TokenBloomFilter(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:                  "vault_bloom",
+		VaultMetaTableName:              "vault_meta",
+		DB:                              db,
+		AutomigrateEnabled:              true,
+		TokenBloomFilterEnabled:         true,
+		TokenBloomFilterRefreshInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// A second store on the same table simulates another application server
+	otherStore, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_bloom",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	exists, err := store.TokenExists(ctx, "tk_definitely_missing")
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if exists {
+		t.Fatal("Expected token to not exist")
+	}
+
+	_, err = store.TokenRead(ctx, "tk_definitely_missing", password)
+	if err == nil {
+		t.Fatal("Expected error reading a missing token")
+	}
+
+	// Tokens created through the store are added to the filter immediately
+	token, err := store.TokenCreate(ctx, "bloom_value", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != "bloom_value" {
+		t.Fatalf("Expected [bloom_value] received [%v]", value)
+	}
+
+	// A second writer using the filter is refused while the lease is held
+	_, err = NewStore(NewStoreOptions{
+		VaultTableName:          "vault_bloom",
+		VaultMetaTableName:      "vault_meta",
+		DB:                      db,
+		TokenBloomFilterEnabled: true,
+	})
+	if !errors.Is(err, ErrTokenBloomFilterWriter) {
+		t.Fatalf("Expected [ErrTokenBloomFilterWriter] received [%v]", err)
+	}
+
+	// Another instance took the lease, e.g. after this one stopped reading
+	lease := "other_instance " + time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	if err := store.metaSet(ctx, OBJECT_TYPE_VAULT_SETTINGS, VAULT_SETTINGS_ID, VAULT_SETTING_KEY_TOKEN_BLOOM_FILTER_WRITER, lease); err != nil {
+		t.Fatalf("metaSet: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Force the refresh interval to elapse
+	store.tokenBloomFilter.mu.Lock()
+	store.tokenBloomFilter.tables["vault_bloom"].refreshedAt = time.Time{}
+	store.tokenBloomFilter.mu.Unlock()
+
+	if _, err := store.TokenExists(ctx, "tk_definitely_missing"); err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Without the lease, tokens created elsewhere are found in the database right away
+	err = otherStore.TokenCreateCustom(ctx, "tk_created_elsewhere", "other_value", password)
+	if err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	exists, err = store.TokenExists(ctx, "tk_created_elsewhere")
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if !exists {
+		t.Fatal("Expected token created elsewhere to be found without the lease")
+	}
+}
+
+func Test_Store_TokenBloomFilterBackfill(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:                  "vault_bloom",
+		VaultMetaTableName:              "vault_meta",
+		DB:                              db,
+		AutomigrateEnabled:              true,
+		TokenBloomFilterEnabled:         true,
+		TokenBloomFilterRefreshInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	otherStore, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_bloom",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	if err := store.TokenCreateCustom(ctx, "tk_original", "value", password); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// A changed token is added to the filter immediately
+	if err := store.RecordUpdateByToken(ctx, "tk_original", map[string]string{COLUMN_VAULT_TOKEN: "tk_renamed"}); err != nil {
+		t.Fatalf("RecordUpdateByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	exists, err := store.TokenExists(ctx, "tk_renamed")
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if !exists {
+		t.Fatal("Expected the renamed token to be visible")
+	}
+
+	// A record applied elsewhere with a past created_at is missed by the incremental refresh
+	_, err = otherStore.ApplyChanges(ctx, ChangeBatch{Changes: []Change{{
+		ID:            "imported_record",
+		Token:         "tk_imported",
+		Value:         "value",
+		CreatedAt:     "2020-01-01 00:00:00",
+		UpdatedAt:     "2020-01-01 00:00:00",
+		ExpiresAt:     MAX_DATETIME,
+		SoftDeletedAt: MAX_DATETIME,
+	}}})
+	if err != nil {
+		t.Fatalf("ApplyChanges: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Force the refresh interval to elapse
+	store.tokenBloomFilter.mu.Lock()
+	store.tokenBloomFilter.tables["vault_bloom"].refreshedAt = time.Time{}
+	store.tokenBloomFilter.mu.Unlock()
+
+	exists, err = store.TokenExists(ctx, "tk_imported")
+	if err != nil {
+		t.Fatalf("TokenExists: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if !exists {
+		t.Fatal("Expected the imported token to be visible after the refresh")
+	}
+}
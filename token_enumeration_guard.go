@@ -0,0 +1,109 @@
+package vaultstore
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTokenAccessDenied is the uniform error returned to remote clients for a
+// token that does not exist, cannot be read, or was read with a wrong password
+var ErrTokenAccessDenied = errors.New("token not found or password invalid")
+
+// ErrTooManyAttempts is returned to remote clients that exceeded their attempt limit
+var ErrTooManyAttempts = errors.New("too many attempts, try again later")
+
+// UniformTokenError maps the errors that would reveal whether a token exists
+// (not found, expired, revoked, consumed, quarantined, soft deleted, checked out,
+// break-glass required, wrong password) to ErrTokenAccessDenied, so network-facing
+// servers cannot be used to probe for tokens, nor learn the revocation or quarantine
+// reasons. The checkout and break-glass checks run before the password is checked.
+// Other errors, e.g. database errors, are returned unchanged.
+func UniformTokenError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrTokenNotFound) ||
+		errors.Is(err, ErrTokenExpired) ||
+		errors.Is(err, ErrTokenRevoked) ||
+		errors.Is(err, ErrTokenConsumed) ||
+		errors.Is(err, ErrTokenQuarantined) ||
+		errors.Is(err, ErrTokenSoftDeleted) ||
+		errors.Is(err, ErrCheckedOut) ||
+		errors.Is(err, ErrBreakGlassRequired) ||
+		errors.Is(err, ErrDecryptionFailed) {
+		return ErrTokenAccessDenied
+	}
+
+	return err
+}
+
+// attemptWindow holds the attempts of a key in the current window
+type attemptWindow struct {
+	start    time.Time
+	attempts int
+}
+
+// AttemptLimiter limits the number of attempts per key (e.g. client IP or actor)
+// in a fixed time window, for throttling token reads in network-facing servers
+type AttemptLimiter struct {
+	mu          sync.Mutex
+	maxAttempts int
+	window      time.Duration
+	windows     map[string]*attemptWindow
+	lastSweep   time.Time
+	now         func() time.Time
+}
+
+// NewAttemptLimiter creates a limiter allowing maxAttempts per key in each window
+func NewAttemptLimiter(maxAttempts int, window time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		maxAttempts: maxAttempts,
+		window:      window,
+		windows:     map[string]*attemptWindow{},
+		now:         time.Now,
+	}
+}
+
+// Allow records an attempt for the key and reports whether it is within the limit
+func (limiter *AttemptLimiter) Allow(key string) bool {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	limiter.sweep(now)
+
+	current, ok := limiter.windows[key]
+	if !ok || now.Sub(current.start) >= limiter.window {
+		current = &attemptWindow{start: now}
+		limiter.windows[key] = current
+	}
+
+	current.attempts++
+	return current.attempts <= limiter.maxAttempts
+}
+
+// Reset forgets the attempts of the key, e.g. after a successful read
+func (limiter *AttemptLimiter) Reset(key string) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	delete(limiter.windows, key)
+}
+
+// sweep removes expired windows once per window, so memory stays bounded
+// by the number of keys active in the last window
+func (limiter *AttemptLimiter) sweep(now time.Time) {
+	if now.Sub(limiter.lastSweep) < limiter.window {
+		return
+	}
+
+	for key, current := range limiter.windows {
+		if now.Sub(current.start) >= limiter.window {
+			delete(limiter.windows, key)
+		}
+	}
+
+	limiter.lastSweep = now
+}
@@ -0,0 +1,74 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_UniformTokenError(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	token, err := store.TokenCreate(ctx, "test_value", "test_password_that_is_long_enough_for_security_32chars", 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// Not found and wrong password are indistinguishable
+	_, errNotFound := store.TokenRead(ctx, "tk_does_not_exist", "test_password_that_is_long_enough_for_security_32chars")
+	_, errWrongPassword := store.TokenRead(ctx, token, "wrong_password_that_is_long_enough_for_security_32chars")
+
+	if !errors.Is(errNotFound, ErrTokenNotFound) {
+		t.Fatalf("Expected [ErrTokenNotFound] received [%v]", errNotFound)
+	}
+	if !errors.Is(errWrongPassword, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", errWrongPassword)
+	}
+
+	if UniformTokenError(errNotFound) != ErrTokenAccessDenied || UniformTokenError(errWrongPassword) != ErrTokenAccessDenied {
+		t.Fatal("Expected both errors to map to [ErrTokenAccessDenied]")
+	}
+
+	// Checked out and break-glass tokens are not revealed either
+	if UniformTokenError(ErrCheckedOut) != ErrTokenAccessDenied || UniformTokenError(fmt.Errorf("read: %w", ErrBreakGlassRequired)) != ErrTokenAccessDenied {
+		t.Fatal("Expected checked out and break-glass errors to map to [ErrTokenAccessDenied]")
+	}
+
+	other := errors.New("database is locked")
+	if UniformTokenError(other) != other || UniformTokenError(nil) != nil {
+		t.Fatal("Expected other errors to be returned unchanged")
+	}
+}
+
+func Test_AttemptLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	if !limiter.Allow("1.2.3.4") || !limiter.Allow("1.2.3.4") {
+		t.Fatal("Expected the first 2 attempts to be allowed")
+	}
+	if limiter.Allow("1.2.3.4") {
+		t.Fatal("Expected the 3rd attempt to be throttled")
+	}
+	if !limiter.Allow("5.6.7.8") {
+		t.Fatal("Expected other keys to be allowed")
+	}
+
+	now = now.Add(time.Minute)
+	if !limiter.Allow("1.2.3.4") {
+		t.Fatal("Expected attempts to be allowed in the next window")
+	}
+
+	limiter.Allow("1.2.3.4")
+	limiter.Reset("1.2.3.4")
+	if !limiter.Allow("1.2.3.4") {
+		t.Fatal("Expected attempts to be allowed after reset")
+	}
+}
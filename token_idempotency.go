@@ -0,0 +1,73 @@
+package vaultstore
+
+import "context"
+
+// idempotencyObjectID returns the meta object ID for an idempotency key
+func (store *storeImplementation) idempotencyObjectID(ctx context.Context, idempotencyKey string) (string, error) {
+	return store.metaScopedObjectID(ctx, idempotencyKey)
+}
+
+// idempotentTokenFind returns the token previously created with the idempotency key,
+// or an empty string if there is none (or the token no longer exists)
+func (store *storeImplementation) idempotentTokenFind(ctx context.Context, idempotencyKey string) (string, error) {
+	objectID, err := store.idempotencyObjectID(ctx, idempotencyKey)
+	if err != nil {
+		return "", err
+	}
+
+	meta, err := store.metaFind(ctx, OBJECT_TYPE_IDEMPOTENCY_KEY, objectID, META_KEY_TOKEN)
+	if err != nil {
+		return "", err
+	}
+
+	if meta == nil {
+		return "", nil
+	}
+
+	record, err := store.RecordFindByToken(ctx, meta.Value)
+	if err != nil {
+		return "", err
+	}
+
+	if record != nil {
+		return meta.Value, nil
+	}
+
+	// The token was deleted since, release the key so it can be reused
+	if err := store.metaDelete(ctx, OBJECT_TYPE_IDEMPOTENCY_KEY, objectID, META_KEY_TOKEN); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+// idempotentTokenClaim binds the idempotency key to the newly created token.
+//
+// If a concurrent call claimed the key first, the unique index rejects the insert,
+// the newly created token is removed and the winning token is returned instead.
+func (store *storeImplementation) idempotentTokenClaim(ctx context.Context, idempotencyKey string, token string) (string, error) {
+	objectID, err := store.idempotencyObjectID(ctx, idempotencyKey)
+	if err != nil {
+		return "", err
+	}
+
+	errClaim := store.metaCreate(ctx, OBJECT_TYPE_IDEMPOTENCY_KEY, objectID, META_KEY_TOKEN, token)
+	if errClaim == nil {
+		return token, nil
+	}
+
+	if err := store.RecordDeleteByToken(ctx, token); err != nil {
+		return "", err
+	}
+
+	winner, err := store.idempotentTokenFind(ctx, idempotencyKey)
+	if err != nil {
+		return "", err
+	}
+
+	if winner == "" {
+		return "", errClaim
+	}
+
+	return winner, nil
+}
@@ -0,0 +1,64 @@
+package vaultstore
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_Store_TokenCreate_IdempotencyKey(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatalf("initStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	options := TokenCreateOptions{IdempotencyKey: "order-123"}
+
+	token1, err := store.TokenCreate(ctx, "value", password, 20, options)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	token2, err := store.TokenCreate(ctx, "value", password, 20, options)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if token1 != token2 {
+		t.Fatalf("Expected retried create to return [%s] received [%s]", token1, token2)
+	}
+
+	count, err := store.RecordCount(ctx, RecordQuery())
+	if err != nil {
+		t.Fatalf("RecordCount: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected [1] record received [%d]", count)
+	}
+
+	// A different key creates a different token
+	token3, err := store.TokenCreate(ctx, "value", password, 20, TokenCreateOptions{IdempotencyKey: "order-456"})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if token3 == token1 {
+		t.Fatal("Expected a new token for a different idempotency key")
+	}
+
+	// Once the token is deleted, the key can be used again
+	if err := store.TokenDelete(ctx, token1); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	token4, err := store.TokenCreate(ctx, "value", password, 20, options)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if token4 == token1 {
+		t.Fatal("Expected a new token after the original was deleted")
+	}
+}
@@ -0,0 +1,123 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dracory/database"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+// TRACER_NAME is the instrumentation name of the tracer of the store
+const TRACER_NAME = "github.com/dracory/vaultstore"
+
+// Attributes of the spans of the store. The spans never carry values, passwords or tokens.
+const (
+	// TRACE_ATTRIBUTE_OPERATION is the name of the store method, e.g. "TokenRead"
+	TRACE_ATTRIBUTE_OPERATION = "vaultstore.operation"
+	// TRACE_ATTRIBUTE_TABLE is the name of the vault table, or of the table of a database statement
+	TRACE_ATTRIBUTE_TABLE = "vaultstore.table"
+	// TRACE_ATTRIBUTE_RECORD_COUNT is the number of tokens of the operation, or of rows of a database statement
+	TRACE_ATTRIBUTE_RECORD_COUNT = "vaultstore.record_count"
+)
+
+// tracedContextKey marks a context inside the span of a store method
+type tracedContextKey struct{}
+
+// tracerNew returns the tracer of the store, a no-op one without a provider
+func tracerNew(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		return noop.NewTracerProvider().Tracer(TRACER_NAME)
+	}
+
+	return provider.Tracer(TRACER_NAME)
+}
+
+// traceStart starts the span of a store method, named "vaultstore.<operation>". Like the
+// operation guard, only the method called by the embedder is traced: the store methods it
+// uses itself get a no-op span, their database statements are added to the outer span.
+func (store *storeImplementation) traceStart(ctx context.Context, operation string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if traced, _ := ctx.Value(tracedContextKey{}).(bool); traced {
+		return ctx, noop.Span{}
+	}
+
+	tableName, err := store.vaultTableNameFromContext(ctx)
+	if err != nil {
+		tableName = store.vaultTableName
+	}
+
+	attributes = append([]attribute.KeyValue{
+		attribute.String(TRACE_ATTRIBUTE_OPERATION, operation),
+		attribute.String(TRACE_ATTRIBUTE_TABLE, tableName),
+	}, attributes...)
+
+	spanCtx, span := store.tracer.Start(ctx, "vaultstore."+operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attributes...),
+	)
+
+	// The context of a transaction stays one, see contextWithValue
+	if queryableContext, ok := ctx.(database.QueryableContext); ok {
+		spanCtx = database.Context(spanCtx, queryableContext.Queryable())
+	}
+
+	return contextWithValue(spanCtx, tracedContextKey{}, true), span
+}
+
+// traceRecordCount returns the record count attribute of a span
+func traceRecordCount(count int) attribute.KeyValue {
+	return attribute.Int(TRACE_ATTRIBUTE_RECORD_COUNT, count)
+}
+
+// traceError marks the span of the context as failed. The description is fixed by the
+// caller, errors of the database can hold tokens.
+func traceError(ctx context.Context, description string) {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.SetStatus(codes.Error, description)
+	}
+}
+
+// traceCallbacksRegister adds each database statement to the span of the store method as
+// an event, with its table and the number of rows, and marks the span failed on errors
+func traceCallbacksRegister(gormDB *gorm.DB) error {
+	callbacks := gormDB.Callback()
+
+	register := []error{
+		callbacks.Create().After("gorm:create").Register("vaultstore:trace", traceStatement("create")),
+		callbacks.Query().After("gorm:query").Register("vaultstore:trace", traceStatement("query")),
+		callbacks.Update().After("gorm:update").Register("vaultstore:trace", traceStatement("update")),
+		callbacks.Delete().After("gorm:delete").Register("vaultstore:trace", traceStatement("delete")),
+		callbacks.Row().After("gorm:row").Register("vaultstore:trace", traceStatement("row")),
+		callbacks.Raw().After("gorm:raw").Register("vaultstore:trace", traceStatement("raw")),
+	}
+
+	return errors.Join(register...)
+}
+
+// traceStatement returns the GORM callback tracing the statements of the kind
+func traceStatement(kind string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+
+		span := trace.SpanFromContext(db.Statement.Context)
+		if !span.IsRecording() {
+			return
+		}
+
+		span.AddEvent("db."+kind, trace.WithAttributes(
+			attribute.String(TRACE_ATTRIBUTE_TABLE, db.Statement.Table),
+			attribute.Int64(TRACE_ATTRIBUTE_RECORD_COUNT, db.RowsAffected),
+		))
+
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			span.SetStatus(codes.Error, "database "+kind+" failed")
+		}
+	}
+}
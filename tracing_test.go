@@ -0,0 +1,161 @@
+package vaultstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingTracerProvider records the names and attributes of the started spans
+type recordingTracerProvider struct {
+	noop.TracerProvider
+
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+func (provider *recordingTracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return &recordingTracer{provider: provider}
+}
+
+func (provider *recordingTracerProvider) names() []string {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	names := []string{}
+	for _, span := range provider.spans {
+		names = append(names, span.name)
+	}
+	return names
+}
+
+type recordingTracer struct {
+	noop.Tracer
+	provider *recordingTracerProvider
+}
+
+func (tracer *recordingTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	span := &recordingSpan{name: name, attributes: config.Attributes()}
+
+	tracer.provider.mu.Lock()
+	tracer.provider.spans = append(tracer.provider.spans, span)
+	tracer.provider.mu.Unlock()
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type recordingSpan struct {
+	noop.Span
+
+	name       string
+	attributes []attribute.KeyValue
+	events     []string
+	ended      bool
+}
+
+func (span *recordingSpan) IsRecording() bool { return true }
+
+func (span *recordingSpan) AddEvent(name string, options ...trace.EventOption) {
+	config := trace.NewEventConfig(options...)
+	span.attributes = append(span.attributes, config.Attributes()...)
+	span.events = append(span.events, name)
+}
+
+func (span *recordingSpan) End(options ...trace.SpanEndOption) { span.ended = true }
+
+func Test_Store_Tracing(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	provider := &recordingTracerProvider{}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:     "vault_traced",
+		VaultMetaTableName: "vault_meta_traced",
+		DB:                 db,
+		AutomigrateEnabled: true,
+		TracerProvider:     provider,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	value := "traced_secret_value"
+
+	// TokenCreateBytes uses TokenCreate itself, only the outer method is traced
+	token, err := store.TokenCreateBytes(ctx, []byte(value), password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreateBytes: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := store.TokensRead(ctx, []string{token}, password); err != nil {
+		t.Fatalf("TokensRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	names := provider.names()
+	if strings.Join(names, ",") != "vaultstore.TokenCreateBytes,vaultstore.TokensRead" {
+		t.Fatalf("Expected the spans of the called methods received [%v]", names)
+	}
+
+	for _, span := range provider.spans {
+		if !span.ended {
+			t.Fatalf("%s: Expected the span to be ended", span.name)
+		}
+		if len(span.events) == 0 {
+			t.Fatalf("%s: Expected the database statements as events", span.name)
+		}
+
+		for _, kv := range span.attributes {
+			emitted := kv.Value.Emit()
+			if strings.Contains(emitted, value) || strings.Contains(emitted, password) || strings.Contains(emitted, token) {
+				t.Fatalf("%s: Expected no sensitive attributes received [%s=%s]", span.name, kv.Key, emitted)
+			}
+		}
+	}
+
+	attributes := map[attribute.Key]string{}
+	for _, kv := range provider.spans[1].attributes {
+		if _, ok := attributes[kv.Key]; !ok {
+			attributes[kv.Key] = kv.Value.Emit()
+		}
+	}
+	if attributes[TRACE_ATTRIBUTE_OPERATION] != "TokensRead" {
+		t.Fatalf("Expected operation [TokensRead] received [%v]", attributes[TRACE_ATTRIBUTE_OPERATION])
+	}
+	if attributes[TRACE_ATTRIBUTE_TABLE] != "vault_traced" {
+		t.Fatalf("Expected table [vault_traced] received [%v]", attributes[TRACE_ATTRIBUTE_TABLE])
+	}
+	if attributes[TRACE_ATTRIBUTE_RECORD_COUNT] != "1" {
+		t.Fatalf("Expected record count [1] received [%v]", attributes[TRACE_ATTRIBUTE_RECORD_COUNT])
+	}
+}
+
+func Test_traceStart_NoProvider(t *testing.T) {
+	store := &storeImplementation{
+		vaultTableName: "vault",
+		tracer:         tracerNew(nil),
+	}
+
+	ctx, span := store.traceStart(context.Background(), "TokenRead")
+	defer span.End()
+
+	if span.IsRecording() {
+		t.Fatal("Expected a no-op span without a TracerProvider")
+	}
+
+	// Nested store methods get a no-op span
+	_, inner := store.traceStart(ctx, "TokenRead")
+	if _, ok := inner.(noop.Span); !ok {
+		t.Fatalf("Expected a no-op span for a nested method received [%T]", inner)
+	}
+}
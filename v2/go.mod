@@ -0,0 +1,10 @@
+module github.com/dracory/vaultstore/v2
+
+go 1.26
+
+require (
+	github.com/dracory/vaultstore v0.0.0-00010101000000-000000000000
+	github.com/glebarez/sqlite v1.11.0
+)
+
+replace github.com/dracory/vaultstore => ../
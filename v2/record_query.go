@@ -0,0 +1,131 @@
+package vaultstore
+
+import (
+	"errors"
+
+	v1 "github.com/dracory/vaultstore"
+)
+
+// SoftDeletedMode selects which records a query returns with regard to soft deletion
+type SoftDeletedMode int
+
+const (
+	// SOFT_DELETED_EXCLUDE returns only records that are not soft deleted (default)
+	SOFT_DELETED_EXCLUDE SoftDeletedMode = iota
+	// SOFT_DELETED_INCLUDE returns all records
+	SOFT_DELETED_INCLUDE
+	// SOFT_DELETED_ONLY returns only soft deleted records
+	SOFT_DELETED_ONLY
+)
+
+// RecordQuery selects records. Empty fields do not filter.
+type RecordQuery struct {
+	ID      string
+	IDIn    []string
+	Token   string
+	TokenIn []string
+
+	// CreatedAtBefore filters records created before the datetime (YYYY-MM-DD HH:MM:SS, UTC)
+	CreatedAtBefore string
+
+	// ExtraEquals filters records by custom column values, see v1.NewStoreOptions.ExtraColumns
+	ExtraEquals map[string]string
+
+	// MetaIn filters records tagged with each key and one of its values, see v1 TokenMetaSet
+	MetaIn map[string][]string
+
+	SoftDeleted SoftDeletedMode
+
+	OrderBy   string
+	SortOrder string // v1.ASC or v1.DESC (default)
+	Limit     int
+	Offset    int
+}
+
+// ToV1 converts the query to a v1 record query
+func (query RecordQuery) ToV1() (v1.RecordQueryInterface, error) {
+	v1Query := v1.RecordQuery()
+
+	if query.ID != "" {
+		v1Query.SetID(query.ID)
+	}
+	if len(query.IDIn) > 0 {
+		v1Query.SetIDIn(query.IDIn)
+	}
+	if query.Token != "" {
+		v1Query.SetToken(query.Token)
+	}
+	if len(query.TokenIn) > 0 {
+		v1Query.SetTokenIn(query.TokenIn)
+	}
+	if query.CreatedAtBefore != "" {
+		v1Query.SetCreatedAtBefore(query.CreatedAtBefore)
+	}
+	for key, value := range query.ExtraEquals {
+		v1Query.SetExtraEquals(key, value)
+	}
+	for key, values := range query.MetaIn {
+		v1Query.SetMetaIn(key, values)
+	}
+
+	switch query.SoftDeleted {
+	case SOFT_DELETED_EXCLUDE:
+	case SOFT_DELETED_INCLUDE:
+		v1Query.SetSoftDeletedInclude(true)
+	case SOFT_DELETED_ONLY:
+		v1Query.SetSoftDeletedOnly(true)
+	default:
+		return nil, errors.New("invalid soft deleted mode")
+	}
+
+	if query.OrderBy != "" {
+		v1Query.SetOrderBy(query.OrderBy)
+	}
+	if query.SortOrder != "" {
+		v1Query.SetSortOrder(query.SortOrder)
+	}
+	if query.Limit != 0 {
+		v1Query.SetLimit(query.Limit)
+	}
+	if query.Offset != 0 {
+		v1Query.SetOffset(query.Offset)
+	}
+
+	if err := v1Query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return v1Query, nil
+}
+
+// RecordQueryFromV1 converts a v1 record query, for incremental migrations
+func RecordQueryFromV1(v1Query v1.RecordQueryInterface) RecordQuery {
+	query := RecordQuery{
+		ID:              v1Query.GetID(),
+		IDIn:            v1Query.GetIDIn(),
+		Token:           v1Query.GetToken(),
+		TokenIn:         v1Query.GetTokenIn(),
+		CreatedAtBefore: v1Query.GetCreatedAtBefore(),
+		OrderBy:         v1Query.GetOrderBy(),
+		SortOrder:       v1Query.GetSortOrder(),
+		Limit:           v1Query.GetLimit(),
+		Offset:          v1Query.GetOffset(),
+	}
+
+	if v1Query.IsExtraEqualsSet() {
+		query.ExtraEquals = v1Query.GetExtraEquals()
+	}
+
+	if v1Query.IsMetaInSet() {
+		query.MetaIn = v1Query.GetMetaIn()
+	}
+
+	// In v1 setting the include flag, even to false, includes soft deleted records
+	if v1Query.GetSoftDeletedOnly() {
+		query.SoftDeleted = SOFT_DELETED_ONLY
+	} else if v1Query.IsSoftDeletedIncludeSet() {
+		query.SoftDeleted = SOFT_DELETED_INCLUDE
+	}
+
+	return query
+}
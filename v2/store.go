@@ -0,0 +1,183 @@
+// Package vaultstore (v2) is the next major version of the vault store API.
+//
+// It wraps the v1 store, so both versions read and write the same tables:
+//   - token creation takes a single options struct instead of positional and variadic parameters
+//   - record queries are plain structs with a tri-state soft deleted filter
+//   - every operation returns errors, there are no silent fallbacks
+//   - envelope encryption is required unless explicitly disabled, and password
+//     derived values are encrypted in the v3 format (XChaCha20-Poly1305)
+//
+// v1 users can migrate incrementally: wrap an existing v1 store with Wrap,
+// and get the v1 store back with V1 for code that is not migrated yet.
+//
+// The module path is github.com/dracory/vaultstore/v2, so v1 and v2 can be
+// imported side by side during a migration.
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "github.com/dracory/vaultstore"
+)
+
+// TOKEN_LENGTH_DEFAULT is the total token length used when TokenCreateOptions.TokenLength is zero
+const TOKEN_LENGTH_DEFAULT = 32
+
+// NewStoreOptions are the options to create a store, the v1 options with the v2 defaults
+type NewStoreOptions struct {
+	v1.NewStoreOptions
+
+	// EnvelopeDisabled allows a store without MasterKey or KeyProvider,
+	// encrypting values with a key derived from the password only (default: false)
+	EnvelopeDisabled bool
+}
+
+// toV1 returns the v1 options with the v2 defaults applied
+func (opts NewStoreOptions) toV1() (v1.NewStoreOptions, error) {
+	v1Opts := opts.NewStoreOptions
+
+	if !opts.EnvelopeDisabled && len(v1Opts.MasterKey) == 0 && v1Opts.KeyProvider == nil {
+		return v1Opts, fmt.Errorf("%w: MasterKey or KeyProvider is required unless EnvelopeDisabled is set", v1.ErrOptionsInvalid)
+	}
+
+	if v1Opts.CryptoConfig == nil {
+		v1Opts.CryptoConfig = v1.DefaultCryptoConfig()
+	} else {
+		cryptoConfig := *v1Opts.CryptoConfig
+		v1Opts.CryptoConfig = &cryptoConfig
+	}
+
+	if v1Opts.CryptoConfig.Cipher == "" {
+		v1Opts.CryptoConfig.Cipher = v1.CIPHER_XCHACHA20_POLY1305
+	}
+
+	return v1Opts, nil
+}
+
+// RecordInterface is a vault record, shared with v1
+type RecordInterface = v1.RecordInterface
+
+// TokenInfo describes a token without its value, shared with v1
+type TokenInfo = v1.TokenInfo
+
+// Store is the v2 vault store
+type Store struct {
+	v1 v1.StoreInterface
+}
+
+// NewStore creates a new v2 store.
+// Envelope encryption is required unless opts.EnvelopeDisabled is set, and an
+// unset CryptoConfig.Cipher defaults to v1.CIPHER_XCHACHA20_POLY1305.
+func NewStore(opts NewStoreOptions) (*Store, error) {
+	v1Opts, err := opts.toV1()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := v1.NewStore(v1Opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{v1: store}, nil
+}
+
+// Wrap returns a v2 store using an existing v1 store
+func Wrap(store v1.StoreInterface) (*Store, error) {
+	if store == nil {
+		return nil, errors.New("vault store: store is required")
+	}
+
+	return &Store{v1: store}, nil
+}
+
+// V1 returns the wrapped v1 store, for code that is not migrated yet
+func (store *Store) V1() v1.StoreInterface {
+	return store.v1
+}
+
+// TokenCreate creates a token for the value in the options and returns it.
+// If options.Token is set, the custom token is used instead of a generated one.
+func (store *Store) TokenCreate(ctx context.Context, options TokenCreateOptions) (string, error) {
+	if err := options.Validate(); err != nil {
+		return "", err
+	}
+
+	createOptions := v1.TokenCreateOptions{
+		ExpiresAt:      options.ExpiresAt,
+		IdempotencyKey: options.IdempotencyKey,
+		ContentType:    options.ContentType,
+	}
+
+	if options.Token != "" {
+		err := store.v1.TokenCreateCustom(ctx, options.Token, options.Value, options.Password, createOptions)
+		if err != nil {
+			return "", err
+		}
+		return options.Token, nil
+	}
+
+	tokenLength := options.TokenLength
+	if tokenLength == 0 {
+		tokenLength = TOKEN_LENGTH_DEFAULT
+	}
+
+	return store.v1.TokenCreate(ctx, options.Value, options.Password, tokenLength, createOptions)
+}
+
+// TokenDelete permanently deletes a token
+func (store *Store) TokenDelete(ctx context.Context, token string) error {
+	return store.v1.TokenDelete(ctx, token)
+}
+
+// TokenExists checks if a token exists
+func (store *Store) TokenExists(ctx context.Context, token string) (bool, error) {
+	return store.v1.TokenExists(ctx, token)
+}
+
+// TokenRead reads the value of a token
+func (store *Store) TokenRead(ctx context.Context, token string, password string) (string, error) {
+	return store.v1.TokenRead(ctx, token, password)
+}
+
+// TokenReadWithInfo reads the value of a token together with its info
+func (store *Store) TokenReadWithInfo(ctx context.Context, token string, password string) (string, TokenInfo, error) {
+	return store.v1.TokenReadWithInfo(ctx, token, password)
+}
+
+// TokenSoftDelete soft deletes a token
+func (store *Store) TokenSoftDelete(ctx context.Context, token string) error {
+	return store.v1.TokenSoftDelete(ctx, token)
+}
+
+// TokenUpdate updates the value of a token
+func (store *Store) TokenUpdate(ctx context.Context, token string, value string, password string) error {
+	return store.v1.TokenUpdate(ctx, token, value, password)
+}
+
+// TokensRead reads multiple tokens, returning a map of token to value
+func (store *Store) TokensRead(ctx context.Context, tokens []string, password string) (map[string]string, error) {
+	return store.v1.TokensRead(ctx, tokens, password)
+}
+
+// RecordCount counts the records matching the query
+func (store *Store) RecordCount(ctx context.Context, query RecordQuery) (int64, error) {
+	v1Query, err := query.ToV1()
+	if err != nil {
+		return 0, err
+	}
+
+	return store.v1.RecordCount(ctx, v1Query)
+}
+
+// RecordList lists the records matching the query
+func (store *Store) RecordList(ctx context.Context, query RecordQuery) ([]RecordInterface, error) {
+	v1Query, err := query.ToV1()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.v1.RecordList(ctx, v1Query)
+}
@@ -0,0 +1,216 @@
+package vaultstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	_ "github.com/glebarez/sqlite"
+
+	v1 "github.com/dracory/vaultstore"
+)
+
+const testPassword = "test_password_that_is_long_enough_for_security_32chars"
+
+func initDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:?parseTime=true")
+	if err != nil {
+		t.Fatalf("sql.Open: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	return db
+}
+
+func initStore(t *testing.T) *Store {
+	store, err := NewStore(NewStoreOptions{
+		NewStoreOptions: v1.NewStoreOptions{
+			VaultTableName:     "vault_v2",
+			VaultMetaTableName: "vault_meta",
+			DB:                 initDB(t),
+			AutomigrateEnabled: true,
+			MasterKey:          bytes.Repeat([]byte{1}, 32),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	return store
+}
+
+func Test_NewStore_Defaults(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewStore(NewStoreOptions{
+		NewStoreOptions: v1.NewStoreOptions{
+			VaultTableName:     "vault_v2",
+			VaultMetaTableName: "vault_meta",
+			DB:                 initDB(t),
+			AutomigrateEnabled: true,
+		},
+	})
+	if !errors.Is(err, v1.ErrOptionsInvalid) {
+		t.Fatalf("Expected [ErrOptionsInvalid] without MasterKey or KeyProvider received [%v]", err)
+	}
+
+	store := initStore(t)
+
+	token, err := store.TokenCreate(ctx, TokenCreateOptions{Value: "value", Password: testPassword})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err := store.V1().RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.HasPrefix(record.GetValue(), v1.ENCRYPTION_PREFIX_ENVELOPE) {
+		t.Fatalf("Expected an envelope value received [%v]", record.GetValue()[:10])
+	}
+
+	// Without envelope encryption new values are in the v3 format
+	store, err = NewStore(NewStoreOptions{
+		NewStoreOptions: v1.NewStoreOptions{
+			VaultTableName:     "vault_v2",
+			VaultMetaTableName: "vault_meta",
+			DB:                 initDB(t),
+			AutomigrateEnabled: true,
+			PasswordAllowEmpty: true,
+		},
+		EnvelopeDisabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The store password policy decides whether empty passwords are allowed
+	token, err = store.TokenCreate(ctx, TokenCreateOptions{Value: "value"})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	record, err = store.V1().RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.HasPrefix(record.GetValue(), v1.ENCRYPTION_PREFIX_V3) {
+		t.Fatalf("Expected a v3 value received [%v]", record.GetValue()[:10])
+	}
+}
+
+func Test_Store_TokenCreate(t *testing.T) {
+	store := initStore(t)
+	ctx := context.Background()
+
+	token, err := store.TokenCreate(ctx, TokenCreateOptions{
+		Value:       `{"key":"value"}`,
+		Password:    testPassword,
+		ContentType: v1.CONTENT_TYPE_JSON,
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(token) != TOKEN_LENGTH_DEFAULT {
+		t.Fatalf("Expected token length [%d] received [%d]", TOKEN_LENGTH_DEFAULT, len(token))
+	}
+
+	// The v1 store reads tokens created through v2
+	value, err := store.V1().TokenRead(ctx, token, testPassword)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != `{"key":"value"}` {
+		t.Fatalf("Expected [{\"key\":\"value\"}] received [%v]", value)
+	}
+
+	customToken, err := store.TokenCreate(ctx, TokenCreateOptions{
+		Value:    "custom",
+		Password: testPassword,
+		Token:    "custom_v2_token",
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if customToken != "custom_v2_token" {
+		t.Fatalf("Expected [custom_v2_token] received [%v]", customToken)
+	}
+
+	_, err = store.TokenCreate(ctx, TokenCreateOptions{Value: "value"})
+	if err == nil {
+		t.Fatal("Expected error for missing password")
+	}
+}
+
+func Test_Store_RecordList_SoftDeleted(t *testing.T) {
+	store := initStore(t)
+	ctx := context.Background()
+
+	active, err := store.TokenCreate(ctx, TokenCreateOptions{Value: "active", Password: testPassword})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	deleted, err := store.TokenCreate(ctx, TokenCreateOptions{Value: "deleted", Password: testPassword})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenSoftDelete(ctx, deleted); err != nil {
+		t.Fatalf("TokenSoftDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	tests := []struct {
+		mode     SoftDeletedMode
+		expected []string
+	}{
+		{SOFT_DELETED_EXCLUDE, []string{active}},
+		{SOFT_DELETED_INCLUDE, []string{active, deleted}},
+		{SOFT_DELETED_ONLY, []string{deleted}},
+	}
+
+	for _, test := range tests {
+		records, err := store.RecordList(ctx, RecordQuery{SoftDeleted: test.mode})
+		if err != nil {
+			t.Fatalf("RecordList: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if len(records) != len(test.expected) {
+			t.Fatalf("Mode [%d]: Expected [%d] records received [%d]", test.mode, len(test.expected), len(records))
+		}
+
+		for _, token := range test.expected {
+			found := false
+			for _, record := range records {
+				found = found || record.GetToken() == token
+			}
+			if !found {
+				t.Fatalf("Mode [%d]: Expected token [%v] in the result", test.mode, token)
+			}
+		}
+	}
+}
+
+func Test_RecordQueryFromV1(t *testing.T) {
+	query := RecordQueryFromV1(v1.RecordQuery().SetToken("token").SetSoftDeletedOnly(true).SetLimit(5))
+
+	if query.Token != "token" || query.Limit != 5 || query.SoftDeleted != SOFT_DELETED_ONLY {
+		t.Fatalf("Expected the v1 query to be converted received [%+v]", query)
+	}
+
+	v1Query, err := query.ToV1()
+	if err != nil {
+		t.Fatalf("ToV1: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if v1Query.GetToken() != "token" || !v1Query.GetSoftDeletedOnly() {
+		t.Fatal("Expected the query to convert back to v1")
+	}
+}
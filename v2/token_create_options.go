@@ -0,0 +1,39 @@
+package vaultstore
+
+import (
+	"errors"
+	"time"
+)
+
+// TokenCreateOptions are the options of Store.TokenCreate
+type TokenCreateOptions struct {
+	// Value is the plaintext value to encrypt
+	Value string
+	// Password encrypts the value, it must satisfy the store password policy
+	Password string
+
+	// Token is a custom token to use instead of a generated one (optional)
+	Token string
+	// TokenLength is the total length of a generated token (0 = TOKEN_LENGTH_DEFAULT)
+	TokenLength int
+
+	// ExpiresAt is the expiration time (zero value = never expires)
+	ExpiresAt time.Time
+	// ContentType describes the format of the value (optional)
+	ContentType string
+	// IdempotencyKey makes retries return the token created first (optional, generated tokens only)
+	IdempotencyKey string
+}
+
+// Validate checks the options before creating a token
+func (options TokenCreateOptions) Validate() error {
+	if options.Token != "" && options.TokenLength != 0 {
+		return errors.New("token length cannot be used with a custom token")
+	}
+
+	if options.Token != "" && options.IdempotencyKey != "" {
+		return errors.New("idempotency key cannot be used with a custom token")
+	}
+
+	return nil
+}
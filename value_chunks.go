@@ -0,0 +1,308 @@
+package vaultstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CHUNKED_VALUE_PREFIX marks a vault value whose ciphertext is stored in the chunk table.
+// The full marker is "chunked:<chunk count>:<sha256 of the ciphertext>".
+const CHUNKED_VALUE_PREFIX = "chunked:"
+
+// VALUE_CHUNK_TABLE_SUFFIX is appended to the vault table name to name its chunk table
+const VALUE_CHUNK_TABLE_SUFFIX = "_chunk"
+
+// valueChunkBatchSize is the number of chunk rows inserted per statement
+const valueChunkBatchSize = 100
+
+// gormVaultChunk is the internal GORM model for the chunks of large vault values
+// This struct is used internally for database operations only
+type gormVaultChunk struct {
+	ID        uint   `gorm:"primaryKey;column:id"`
+	RecordID  string `gorm:"index;size:40;column:record_id;not null"`
+	ValueHash string `gorm:"size:64;column:value_hash;not null"`
+	Sequence  int    `gorm:"column:sequence;not null"`
+	Data      string `gorm:"type:longtext;column:chunk_data;not null"`
+}
+
+// TableName returns the table name for the GORM model
+func (gormVaultChunk) TableName() string {
+	return "" // Will be set dynamically via the vault table name
+}
+
+// isChunkedValue checks whether the stored vault value is a chunk marker
+func isChunkedValue(value string) bool {
+	return strings.HasPrefix(value, CHUNKED_VALUE_PREFIX)
+}
+
+// valueChunkHash returns the hash identifying a chunked ciphertext
+func valueChunkHash(ciphertext string) string {
+	sum := sha256.Sum256([]byte(ciphertext))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseChunkedValue returns the chunk count and ciphertext hash of a chunk marker
+func parseChunkedValue(marker string) (int, string, error) {
+	parts := strings.Split(strings.TrimPrefix(marker, CHUNKED_VALUE_PREFIX), ":")
+	if len(parts) != 2 {
+		return 0, "", errors.New("invalid chunked value marker")
+	}
+
+	count, err := strconv.Atoi(parts[0])
+	if err != nil || count < 1 {
+		return 0, "", errors.New("invalid chunked value marker")
+	}
+
+	return count, parts[1], nil
+}
+
+// chunkedValueMarker returns the marker stored in the vault table for a chunked ciphertext
+func chunkedValueMarker(ciphertext string, chunkSize int) string {
+	count := (len(ciphertext) + chunkSize - 1) / chunkSize
+	return CHUNKED_VALUE_PREFIX + strconv.Itoa(count) + ":" + valueChunkHash(ciphertext)
+}
+
+// splitValueChunks splits the ciphertext into chunks of at most chunkSize bytes
+func splitValueChunks(ciphertext string, chunkSize int) []string {
+	chunks := make([]string, 0, len(ciphertext)/chunkSize+1)
+	for start := 0; start < len(ciphertext); start += chunkSize {
+		end := min(start+chunkSize, len(ciphertext))
+		chunks = append(chunks, ciphertext[start:end])
+	}
+	return chunks
+}
+
+// isValueChunkingEnabled checks whether large values are split into chunks
+func (store *storeImplementation) isValueChunkingEnabled() bool {
+	return store.valueChunkThreshold > 0
+}
+
+// valueChunkDB returns a GORM session scoped to the chunk table of the
+// vault table resolved from the context
+func (store *storeImplementation) valueChunkDB(ctx context.Context) *gorm.DB {
+	db := store.gormDBFromContext(ctx)
+
+	tableName, err := store.vaultTableNameFromContext(ctx)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+
+	return db.Table(tableName + VALUE_CHUNK_TABLE_SUFFIX)
+}
+
+// valueChunksWrite stores a ciphertext larger than the chunk threshold in the
+// chunk table and returns the marker to store in the vault table instead.
+// Smaller ciphertexts are returned unchanged.
+func (store *storeImplementation) valueChunksWrite(ctx context.Context, recordID string, ciphertext string) (string, error) {
+	if !store.isValueChunkingEnabled() || len(ciphertext) <= store.valueChunkThreshold {
+		return ciphertext, nil
+	}
+
+	if recordID == "" {
+		return "", errors.New("record id is empty")
+	}
+
+	hash := valueChunkHash(ciphertext)
+	chunks := splitValueChunks(ciphertext, store.valueChunkThreshold)
+
+	rows := make([]gormVaultChunk, len(chunks))
+	for i, chunk := range chunks {
+		rows[i] = gormVaultChunk{
+			RecordID:  recordID,
+			ValueHash: hash,
+			Sequence:  i,
+			Data:      chunk,
+		}
+	}
+
+	err := store.valueChunkDB(ctx).CreateInBatches(&rows, valueChunkBatchSize).Error
+	if err != nil {
+		return "", err
+	}
+
+	return chunkedValueMarker(ciphertext, store.valueChunkThreshold), nil
+}
+
+// valueChunksResolve replaces the chunk markers of the records with the reassembled ciphertexts
+func (store *storeImplementation) valueChunksResolve(ctx context.Context, records []gormVaultRecord) error {
+	recordIDs := []string{}
+	for _, record := range records {
+		if isChunkedValue(record.Value) {
+			recordIDs = append(recordIDs, record.ID)
+		}
+	}
+
+	if len(recordIDs) == 0 {
+		return nil
+	}
+
+	var rows []gormVaultChunk
+	err := store.valueChunkDB(ctx).
+		Where("record_id IN ?", recordIDs).
+		Order("record_id ASC").
+		Order("sequence ASC").
+		Find(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	chunksByRecord := map[string][]gormVaultChunk{}
+	for _, row := range rows {
+		chunksByRecord[row.RecordID] = append(chunksByRecord[row.RecordID], row)
+	}
+
+	for i := range records {
+		if !isChunkedValue(records[i].Value) {
+			continue
+		}
+
+		count, hash, err := parseChunkedValue(records[i].Value)
+		if err != nil {
+			return err
+		}
+
+		var builder strings.Builder
+		found := 0
+		for _, row := range chunksByRecord[records[i].ID] {
+			if row.ValueHash != hash || row.Sequence != found {
+				continue
+			}
+			builder.WriteString(row.Data)
+			found++
+		}
+
+		if found != count || valueChunkHash(builder.String()) != hash {
+			return errors.New("chunked value of record " + records[i].ID + " is incomplete")
+		}
+
+		records[i].Value = builder.String()
+	}
+
+	return nil
+}
+
+// valueChunksCollect deletes the chunks of the record that do not belong to
+// the stored value, i.e. chunks left over from previous values. The chunks of
+// a streamed token belong to its stream marker, they are kept while the record exists.
+func (store *storeImplementation) valueChunksCollect(ctx context.Context, recordID string, storedValue string) (int64, error) {
+	if !store.isValueChunkingEnabled() {
+		return 0, nil
+	}
+
+	keepHashes := []string{}
+
+	if isChunkedValue(storedValue) {
+		_, hash, err := parseChunkedValue(storedValue)
+		if err != nil {
+			return 0, err
+		}
+		keepHashes = append(keepHashes, hash)
+	}
+
+	if storedValue != "" {
+		stream, err := store.metaFind(ctx, OBJECT_TYPE_RECORD, recordMetaObjectID(recordID), META_KEY_STREAM)
+		if err != nil {
+			return 0, err
+		}
+		if stream != nil {
+			keepHashes = append(keepHashes, stream.Value)
+		}
+	}
+
+	db := store.valueChunkDB(ctx).Where("record_id = ?", recordID)
+	if len(keepHashes) > 0 {
+		db = db.Where("value_hash NOT IN ?", keepHashes)
+	}
+
+	result := db.Delete(&gormVaultChunk{})
+	return result.RowsAffected, result.Error
+}
+
+// valueChunksDelete deletes all chunks of the given records
+func (store *storeImplementation) valueChunksDelete(ctx context.Context, recordIDs []string) error {
+	if !store.isValueChunkingEnabled() || len(recordIDs) == 0 {
+		return nil
+	}
+
+	return store.valueChunkDB(ctx).
+		Where("record_id IN ?", recordIDs).
+		Delete(&gormVaultChunk{}).Error
+}
+
+// ValueChunksGarbageCollect deletes chunks that are no longer referenced by a
+// vault record, e.g. left behind by a failed write
+//
+// Parameters:
+// - ctx: The context
+//
+// Returns:
+// - deleted: The number of deleted chunk rows
+// - err: An error if something went wrong
+func (store *storeImplementation) ValueChunksGarbageCollect(ctx context.Context) (int64, error) {
+	ctx, span := store.traceStart(ctx, "ValueChunksGarbageCollect")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "ValueChunksGarbageCollect", ""); err != nil {
+		return 0, err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if !store.isValueChunkingEnabled() {
+		return 0, nil
+	}
+
+	var recordIDs []string
+	err := store.valueChunkDB(ctx).
+		Distinct("record_id").
+		Pluck("record_id", &recordIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for start := 0; start < len(recordIDs); start += maxRecordsInMemory {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		batch := recordIDs[start:min(start+maxRecordsInMemory, len(recordIDs))]
+
+		// Include soft deleted records, their values can still be restored. The chunk table
+		// is shared by the namespaces, so are the records looked up.
+		var records []gormVaultRecord
+		err := store.vaultDB(withNamespacesAll(ctx)).
+			Select(COLUMN_ID, COLUMN_VAULT_VALUE).
+			Where(COLUMN_ID+" IN ?", batch).
+			Find(&records).Error
+		if err != nil {
+			return deleted, err
+		}
+
+		storedValues := map[string]string{}
+		for _, record := range records {
+			storedValues[record.ID] = record.Value
+		}
+
+		// Chunks of missing records have no stored value, so all of them are collected
+		for _, recordID := range batch {
+			count, err := store.valueChunksCollect(ctx, recordID, storedValues[recordID])
+			if err != nil {
+				return deleted, err
+			}
+			deleted += count
+		}
+	}
+
+	return deleted, nil
+}
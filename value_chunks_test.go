@@ -0,0 +1,108 @@
+package vaultstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func Test_splitValueChunks(t *testing.T) {
+	chunks := splitValueChunks("abcdefghij", 4)
+	if strings.Join(chunks, ",") != "abcd,efgh,ij" {
+		t.Fatalf("Expected [abcd,efgh,ij] received [%v]", chunks)
+	}
+
+	marker := chunkedValueMarker("abcdefghij", 4)
+	count, hash, err := parseChunkedValue(marker)
+	if err != nil {
+		t.Fatalf("parseChunkedValue: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if count != 3 {
+		t.Fatalf("Expected [3] chunks received [%d]", count)
+	}
+	if hash != valueChunkHash("abcdefghij") {
+		t.Fatalf("Expected hash of the ciphertext received [%v]", hash)
+	}
+}
+
+func Test_Store_ValueChunks(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:      "vault_chunked",
+		VaultMetaTableName:  "vault_meta",
+		DB:                  db,
+		AutomigrateEnabled:  true,
+		ValueChunkThreshold: 64,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+	largeValue := strings.Repeat("large value ", 100)
+
+	token, err := store.TokenCreate(ctx, largeValue, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	// The vault table only holds the marker
+	var storedValue string
+	err = store.vaultDB(ctx).
+		Where(COLUMN_VAULT_TOKEN+" = ?", token).
+		Pluck(COLUMN_VAULT_VALUE, &storedValue).Error
+	if err != nil {
+		t.Fatalf("Pluck: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if !isChunkedValue(storedValue) {
+		t.Fatalf("Expected chunked value marker received [%v]", storedValue)
+	}
+
+	value, err := store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != largeValue {
+		t.Fatal("Expected the reassembled value to match the original")
+	}
+
+	// Updating replaces the chunks of the previous value
+	updatedValue := strings.Repeat("updated value ", 100)
+	if err := store.TokenUpdate(ctx, token, updatedValue, password); err != nil {
+		t.Fatalf("TokenUpdate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err = store.TokenRead(ctx, token, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != updatedValue {
+		t.Fatal("Expected the reassembled value to match the updated value")
+	}
+
+	deleted, err := store.ValueChunksGarbageCollect(ctx)
+	if err != nil {
+		t.Fatalf("ValueChunksGarbageCollect: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if deleted != 0 {
+		t.Fatalf("Expected no orphaned chunks received [%d]", deleted)
+	}
+
+	// Deleting the token cascades to its chunks
+	if err := store.TokenDelete(ctx, token); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	var count int64
+	if err := store.valueChunkDB(ctx).Count(&count).Error; err != nil {
+		t.Fatalf("Count: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if count != 0 {
+		t.Fatalf("Expected chunks to be deleted, [%d] remaining", count)
+	}
+}
@@ -0,0 +1,41 @@
+package vaultstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrValueInvalid is returned when a value was decrypted but rejected by
+// NewStoreOptions.ValueValidateFunc. The returned error wraps it and the validation error.
+var ErrValueInvalid = errors.New("token value is invalid")
+
+// ValidateJSON is a ValueValidateFunc accepting only valid JSON values
+func ValidateJSON(_ string, value string) error {
+	if !json.Valid([]byte(value)) {
+		return errors.New("value is not valid JSON")
+	}
+	return nil
+}
+
+// valueValidate runs the ValueValidateFunc on a decrypted value. Invalid records
+// are quarantined if ValueQuarantineEnabled is set.
+func (store *storeImplementation) valueValidate(ctx context.Context, record RecordInterface, value string) error {
+	if store.valueValidateFunc == nil {
+		return nil
+	}
+
+	validationErr := store.valueValidateFunc(record.GetToken(), value)
+	if validationErr == nil {
+		return nil
+	}
+
+	if store.valueQuarantineEnabled {
+		if err := store.recordQuarantine(ctx, record, validationErr.Error()); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrValueInvalid, validationErr)
+}
@@ -0,0 +1,109 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func initStoreWithValidation(t *testing.T, quarantine bool) *storeImplementation {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		VaultTableName:         "vault_validation",
+		VaultMetaTableName:     "vault_meta",
+		DB:                     db,
+		AutomigrateEnabled:     true,
+		ValueValidateFunc:      ValidateJSON,
+		ValueQuarantineEnabled: quarantine,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	return store
+}
+
+func Test_Store_ValueValidateFunc(t *testing.T) {
+	store := initStoreWithValidation(t, false)
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	validToken, err := store.TokenCreate(ctx, `{"user":"alice"}`, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	invalidToken, err := store.TokenCreate(ctx, `{"user":`, password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	value, err := store.TokenRead(ctx, validToken, password)
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if value != `{"user":"alice"}` {
+		t.Fatalf("Expected the valid value received [%v]", value)
+	}
+
+	_, err = store.TokenRead(ctx, invalidToken, password)
+	if !errors.Is(err, ErrValueInvalid) {
+		t.Fatalf("Expected [ErrValueInvalid] received [%v]", err)
+	}
+	if !strings.Contains(err.Error(), "not valid JSON") {
+		t.Fatalf("Expected the error to contain the details received [%v]", err.Error())
+	}
+
+	_, err = store.TokensRead(ctx, []string{validToken, invalidToken}, password)
+	if !errors.Is(err, ErrValueInvalid) {
+		t.Fatalf("TokensRead: Expected [ErrValueInvalid] received [%v]", err)
+	}
+
+	// Without the quarantine option the record is not flagged
+	record, err := store.RecordFindByToken(ctx, invalidToken)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_QUARANTINE)
+	if err != nil {
+		t.Fatalf("recordMetaFind: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if meta != nil {
+		t.Fatalf("Expected no quarantine flag received [%v]", meta.Value)
+	}
+}
+
+func Test_Store_ValueQuarantineEnabled(t *testing.T) {
+	store := initStoreWithValidation(t, true)
+	ctx := context.Background()
+	password := "test_password_that_is_long_enough_for_security_32chars"
+
+	token, err := store.TokenCreate(ctx, "not json", password, 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = store.TokenRead(ctx, token, password)
+	if !errors.Is(err, ErrValueInvalid) {
+		t.Fatalf("Expected [ErrValueInvalid] received [%v]", err)
+	}
+
+	record, err := store.RecordFindByToken(ctx, token)
+	if err != nil {
+		t.Fatalf("RecordFindByToken: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	meta, err := store.recordMetaFind(ctx, record, META_KEY_QUARANTINE)
+	if err != nil {
+		t.Fatalf("recordMetaFind: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if meta == nil || !strings.Contains(meta.Value, "not valid JSON") {
+		t.Fatalf("Expected the record to be quarantined with the reason received [%v]", meta)
+	}
+}
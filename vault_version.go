@@ -0,0 +1,141 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrVaultVersionUnsupported is returned by NewStore when the persisted vault version
+// is newer than VAULT_VERSION_CURRENT, or older than VAULT_VERSION_MIN_SUPPORTED
+// without VersionAutoUpgrade
+var ErrVaultVersionUnsupported = errors.New("vault version is not supported by this library version")
+
+// GetVaultVersion returns the persisted vault version, or an empty string if not set
+func (store *storeImplementation) GetVaultVersion(ctx context.Context) (string, error) {
+	ctx, span := store.traceStart(ctx, "GetVaultVersion")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "GetVaultVersion", ""); err != nil {
+		return "", err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	version, err := store.GetVaultSetting(ctx, META_KEY_VERSION)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return version, err
+}
+
+// SetVaultVersion persists the vault version
+func (store *storeImplementation) SetVaultVersion(ctx context.Context, version string) error {
+	ctx, span := store.traceStart(ctx, "SetVaultVersion")
+	defer span.End()
+
+	if err := store.operationAllow(ctx, "SetVaultVersion", ""); err != nil {
+		return err
+	}
+	ctx = store.operationAllowedContext(ctx)
+
+	if _, err := parseVaultVersion(version); err != nil {
+		return err
+	}
+	return store.SetVaultSetting(ctx, META_KEY_VERSION, version)
+}
+
+// vaultVersionGate returns the persisted vault version, and an error if it is outside
+// the supported range. It is read-only, so it runs before any schema migration.
+func (store *storeImplementation) vaultVersionGate(ctx context.Context) (string, error) {
+	// Nothing is persisted before the meta table is migrated
+	if !store.gormDB.Migrator().HasTable(store.vaultMetaTableName) {
+		return "", nil
+	}
+
+	version, err := store.GetVaultVersion(ctx)
+	if err != nil || version == "" {
+		return version, err
+	}
+
+	newer, err := compareVaultVersions(version, VAULT_VERSION_CURRENT)
+	if err != nil {
+		return "", err
+	}
+
+	if newer > 0 {
+		return "", fmt.Errorf("%w: vault version %s is newer than %s", ErrVaultVersionUnsupported, version, VAULT_VERSION_CURRENT)
+	}
+
+	older, err := compareVaultVersions(version, VAULT_VERSION_MIN_SUPPORTED)
+	if err != nil {
+		return "", err
+	}
+
+	if older < 0 && !store.versionAutoUpgrade {
+		return "", fmt.Errorf("%w: vault version %s is older than %s, enable VersionAutoUpgrade", ErrVaultVersionUnsupported, version, VAULT_VERSION_MIN_SUPPORTED)
+	}
+
+	return version, nil
+}
+
+// vaultVersionStamp persists the current version for a new vault, or for an older
+// one if versionAutoUpgrade is set. Without it older supported vaults keep their
+// version, so older binaries keep working.
+func (store *storeImplementation) vaultVersionStamp(ctx context.Context, version string) error {
+	if !store.gormDB.Migrator().HasTable(store.vaultMetaTableName) {
+		return nil
+	}
+
+	if version == VAULT_VERSION_CURRENT || (version != "" && !store.versionAutoUpgrade) {
+		return nil
+	}
+
+	return store.SetVaultVersion(ctx, VAULT_VERSION_CURRENT)
+}
+
+// parseVaultVersion parses a "major.minor[.patch]" version
+func parseVaultVersion(version string) ([]int, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid vault version: %q", version)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("invalid vault version: %q", version)
+		}
+		numbers[i] = number
+	}
+
+	return numbers, nil
+}
+
+// compareVaultVersions returns -1, 0 or 1 if version a is older, equal or newer than b
+func compareVaultVersions(a string, b string) (int, error) {
+	numbersA, err := parseVaultVersion(a)
+	if err != nil {
+		return 0, err
+	}
+
+	numbersB, err := parseVaultVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range numbersA {
+		if numbersA[i] != numbersB[i] {
+			if numbersA[i] < numbersB[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,94 @@
+package vaultstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_Store_VaultVersionCheck(t *testing.T) {
+	db, err := initDB()
+	if err != nil {
+		t.Fatalf("initDB: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	options := NewStoreOptions{
+		VaultTableName:     "vault_version",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	}
+
+	store, err := NewStore(options)
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	ctx := context.Background()
+
+	// A new vault is stamped with the current version
+	version, err := store.GetVaultVersion(ctx)
+	if err != nil {
+		t.Fatalf("GetVaultVersion: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if version != VAULT_VERSION_CURRENT {
+		t.Fatalf("Expected version [%s] received [%s]", VAULT_VERSION_CURRENT, version)
+	}
+
+	// A vault written by a newer library is refused
+	if err := store.SetVaultVersion(ctx, "99.0"); err != nil {
+		t.Fatalf("SetVaultVersion: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = NewStore(options)
+	if !errors.Is(err, ErrVaultVersionUnsupported) {
+		t.Fatalf("Expected [ErrVaultVersionUnsupported] received [%v]", err)
+	}
+
+	// A vault older than the supported range requires VersionAutoUpgrade
+	if err := store.SetVaultVersion(ctx, "0.26.0"); err != nil {
+		t.Fatalf("SetVaultVersion: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = NewStore(options)
+	if !errors.Is(err, ErrVaultVersionUnsupported) {
+		t.Fatalf("Expected [ErrVaultVersionUnsupported] received [%v]", err)
+	}
+
+	options.VersionAutoUpgrade = true
+	_, err = NewStore(options)
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	version, _ = store.GetVaultVersion(ctx)
+	if version != VAULT_VERSION_CURRENT {
+		t.Fatalf("Expected upgraded version [%s] received [%s]", VAULT_VERSION_CURRENT, version)
+	}
+
+	if err := store.SetVaultVersion(ctx, "invalid"); err == nil {
+		t.Fatal("Expected error for invalid version")
+	}
+}
+
+func Test_CompareVaultVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0", "1.1", -1},
+		{"1.1", "1.1.0", 0},
+		{"1.10", "1.9", 1},
+		{"0.26.0", "1.0", -1},
+	}
+
+	for _, test := range tests {
+		result, err := compareVaultVersions(test.a, test.b)
+		if err != nil {
+			t.Fatalf("compareVaultVersions: Expected [err] to be nil received [%v]", err.Error())
+		}
+		if result != test.expected {
+			t.Fatalf("compareVaultVersions(%s, %s): Expected [%d] received [%d]", test.a, test.b, test.expected, result)
+		}
+	}
+}
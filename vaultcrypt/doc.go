@@ -0,0 +1,20 @@
+// Package vaultcrypt encrypts and decrypts vault values in the v2 (AES-GCM) and
+// v3 (XChaCha20-Poly1305) formats, with an Argon2id key, without a store or a
+// database, so producers can encrypt secrets on their own machines and submit
+// only the ciphertext,
+// e.g. with RecordImportCiphertext. The plaintext and the password never reach
+// the service holding the vault.
+//
+// The package only depends on the standard library and golang.org/x/crypto.
+// The vault store encrypts through it, so both always produce the same format.
+//
+// Usage:
+//
+//	// params must match the CryptoConfig of the store, see CryptoConfig.Params
+//	ciphertext, err := vaultcrypt.Encode("secret", password, vaultcrypt.DefaultParams())
+//
+//	err = store.RecordImportCiphertext(ctx, token, ciphertext)
+//
+// For stores with a pepper (NewStoreOptions.Pepper), producers set the same
+// Params.Pepper, or submit values without it, which the store still reads.
+package vaultcrypt
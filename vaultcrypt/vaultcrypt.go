@@ -0,0 +1,340 @@
+package vaultcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Prefixes of the ciphertext formats
+const (
+	// PREFIX_V2 starts the AES-GCM ciphertexts
+	PREFIX_V2 = "v2:"
+	// PREFIX_V3 starts the XChaCha20-Poly1305 ciphertexts
+	PREFIX_V3 = "v3:"
+	// PREFIX_PEPPER starts the ciphertexts encrypted with a pepper, followed by a v2 or v3 ciphertext
+	PREFIX_PEPPER = "pep:"
+)
+
+// Ciphers selectable with Params.Cipher
+const (
+	// CIPHER_AES_GCM encrypts in the v2 format, the default
+	CIPHER_AES_GCM = "aes-gcm"
+	// CIPHER_XCHACHA20_POLY1305 encrypts in the v3 format, faster without AES hardware acceleration
+	CIPHER_XCHACHA20_POLY1305 = "xchacha20-poly1305"
+)
+
+// ErrCipherUnsupported is returned for an unknown Params.Cipher
+var ErrCipherUnsupported = errors.New("unsupported cipher")
+
+// ErrPepperMissing is returned when decrypting a value encrypted with a pepper without Params.Pepper
+var ErrPepperMissing = errors.New("value is encrypted with a pepper, none is configured")
+
+// ErrDecryptionFailed is returned when a value cannot be decrypted,
+// typically because the password is wrong
+var ErrDecryptionFailed = errors.New("decryption failed")
+
+// Params are the Argon2id and AES-GCM parameters of the v2 format.
+// They are not stored in the ciphertext, decrypting needs the parameters it was encrypted with.
+type Params struct {
+	// Argon2id parameters
+	Iterations  int
+	Memory      int // in KiB
+	Parallelism int
+	KeyLength   int // in bytes
+
+	// AES-GCM parameters, the XChaCha20-Poly1305 nonce and tag sizes are fixed
+	SaltSize  int // in bytes
+	NonceSize int // in bytes
+	TagSize   int // in bytes
+
+	// Cipher selects the format written by Encode, empty for CIPHER_AES_GCM
+	Cipher string
+
+	// Pepper is a secret mixed into the password by Encode and Decode, see PepperPassword.
+	// Without it, a copy of the ciphertexts is not enough to guess weak passwords.
+	Pepper []byte
+}
+
+// DefaultParams returns the parameters of the default vault store crypto config
+func DefaultParams() Params {
+	return Params{
+		Iterations:  3,
+		Memory:      64 * 1024, // 64MB
+		Parallelism: 4,
+		KeyLength:   32,
+		SaltSize:    16,
+		NonceSize:   12,
+		TagSize:     16,
+	}
+}
+
+// Encode encrypts the value with the cipher of the params, see EncodeV2 and EncodeV3.
+// With a pepper, the value is encrypted with the peppered password and prefixed with PREFIX_PEPPER.
+func Encode(value string, password string, params Params) (string, error) {
+	if len(params.Pepper) > 0 {
+		unpeppered := params
+		unpeppered.Pepper = nil
+
+		ciphertext, err := Encode(value, PepperPassword(password, params.Pepper), unpeppered)
+		if err != nil {
+			return "", err
+		}
+		return PREFIX_PEPPER + ciphertext, nil
+	}
+
+	switch params.Cipher {
+	case "", CIPHER_AES_GCM:
+		return EncodeV2(value, password, params)
+	case CIPHER_XCHACHA20_POLY1305:
+		return EncodeV3(value, password, params)
+	default:
+		return "", fmt.Errorf("%w: %s", ErrCipherUnsupported, params.Cipher)
+	}
+}
+
+// Decode decrypts a value encrypted by Encode, the format is read from the prefix
+func Decode(ciphertext string, password string, params Params) (string, error) {
+	if strings.HasPrefix(ciphertext, PREFIX_PEPPER) {
+		if len(params.Pepper) == 0 {
+			return "", ErrPepperMissing
+		}
+		ciphertext = strings.TrimPrefix(ciphertext, PREFIX_PEPPER)
+		password = PepperPassword(password, params.Pepper)
+	}
+
+	if strings.HasPrefix(ciphertext, PREFIX_V3) {
+		return DecodeV3(ciphertext, password, params)
+	}
+	return DecodeV2(ciphertext, password, params)
+}
+
+// EncodeV2 encrypts the value with AES-GCM, using a key derived from the password
+// and a random salt with Argon2id, and returns it as "v2:" followed by the
+// URL-safe base64 of salt, nonce and sealed value
+//
+// Parameters:
+// - value: The plaintext
+// - password: The password the key is derived from
+// - params: The Argon2id and AES-GCM parameters
+//
+// Returns:
+// - ciphertext: The encrypted value
+// - err: An error if something went wrong
+func EncodeV2(value string, password string, params Params) (string, error) {
+	// Generate random salt, with room for the nonce and sealed value
+	salt := make([]byte, params.SaltSize, params.SaltSize+params.NonceSize+len(value)+params.TagSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := DeriveKey(password, salt, params)
+
+	// Create AES cipher
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	// Create GCM
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	// Generate nonce
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// Encrypt after salt + nonce, the sealed value includes the tag
+	combined := gcm.Seal(append(salt, nonce...), nonce, []byte(value), nil)
+
+	// Encode and add prefix
+	return encodePrefixed(PREFIX_V2, combined), nil
+}
+
+// DecodeV2 decrypts a value encrypted by EncodeV2
+//
+// Parameters:
+// - ciphertext: The encrypted value, starting with "v2:"
+// - password: The password the value was encrypted with
+// - params: The parameters the value was encrypted with
+//
+// Returns:
+// - value: The plaintext
+// - err: ErrDecryptionFailed for a wrong password, or an error for a malformed ciphertext
+func DecodeV2(ciphertext string, password string, params Params) (string, error) {
+	data, err := Unpack(ciphertext, params)
+	if err != nil {
+		return "", err
+	}
+
+	// Extract salt, nonce, and ciphertext
+	salt := data[:params.SaltSize]
+	nonce := data[params.SaltSize : params.SaltSize+params.NonceSize]
+	sealed := data[params.SaltSize+params.NonceSize:]
+
+	key := DeriveKey(password, salt, params)
+
+	// Create AES cipher
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", errors.New("aes cipher: " + err.Error())
+	}
+
+	// Create GCM
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", errors.New("gcm: " + err.Error())
+	}
+
+	// Decrypt in place, the decoded bytes are not used afterwards
+	plaintext, err := gcm.Open(sealed[:0], nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrDecryptionFailed, err.Error())
+	}
+
+	return string(plaintext), nil
+}
+
+// EncodeV3 encrypts the value with XChaCha20-Poly1305, using a key derived from
+// the password and a random salt with Argon2id, and returns it as "v3:" followed
+// by the URL-safe base64 of salt, nonce and sealed value. The 24 byte nonce is
+// random, Params.NonceSize and Params.TagSize do not apply.
+//
+// Parameters:
+// - value: The plaintext
+// - password: The password the key is derived from
+// - params: The Argon2id parameters and salt size, the key length must be 32
+//
+// Returns:
+// - ciphertext: The encrypted value
+// - err: An error if something went wrong
+func EncodeV3(value string, password string, params Params) (string, error) {
+	salt := make([]byte, params.SaltSize, params.SaltSize+chacha20poly1305.NonceSizeX+len(value)+chacha20poly1305.Overhead)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := chacha20poly1305.NewX(DeriveKey(password, salt, params))
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	combined := aead.Seal(append(salt, nonce...), nonce, []byte(value), nil)
+
+	return encodePrefixed(PREFIX_V3, combined), nil
+}
+
+// DecodeV3 decrypts a value encrypted by EncodeV3
+//
+// Parameters:
+// - ciphertext: The encrypted value, starting with "v3:"
+// - password: The password the value was encrypted with
+// - params: The parameters the value was encrypted with
+//
+// Returns:
+// - value: The plaintext
+// - err: ErrDecryptionFailed for a wrong password, or an error for a malformed ciphertext
+func DecodeV3(ciphertext string, password string, params Params) (string, error) {
+	data, err := Unpack(ciphertext, params)
+	if err != nil {
+		return "", err
+	}
+
+	salt := data[:params.SaltSize]
+	nonce := data[params.SaltSize : params.SaltSize+chacha20poly1305.NonceSizeX]
+	sealed := data[params.SaltSize+chacha20poly1305.NonceSizeX:]
+
+	aead, err := chacha20poly1305.NewX(DeriveKey(password, salt, params))
+	if err != nil {
+		return "", errors.New("xchacha20-poly1305: " + err.Error())
+	}
+
+	plaintext, err := aead.Open(sealed[:0], nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrDecryptionFailed, err.Error())
+	}
+
+	return string(plaintext), nil
+}
+
+// Unpack decodes a v2 or v3 ciphertext to its salt, nonce and sealed value bytes,
+// checking it is long enough for the parameters, without decrypting it.
+// A value without prefix is read as v2, the pepper prefix is skipped.
+//
+// Parameters:
+// - ciphertext: The encrypted value
+// - params: The parameters the value was encrypted with
+//
+// Returns:
+// - data: The salt, nonce and sealed value
+// - err: An error for a malformed ciphertext
+func Unpack(ciphertext string, params Params) ([]byte, error) {
+	ciphertext = strings.TrimPrefix(ciphertext, PREFIX_PEPPER)
+	minLength := params.SaltSize + params.NonceSize + params.TagSize
+	encoded := strings.TrimPrefix(ciphertext, PREFIX_V2)
+
+	if strings.HasPrefix(ciphertext, PREFIX_V3) {
+		minLength = params.SaltSize + chacha20poly1305.NonceSizeX + chacha20poly1305.Overhead
+		encoded = strings.TrimPrefix(ciphertext, PREFIX_V3)
+	}
+
+	// Decode base64
+	data, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("base64 decode: " + err.Error())
+	}
+
+	// Check minimum length (salt + nonce + tag)
+	if len(data) < minLength {
+		return nil, errors.New("invalid ciphertext length")
+	}
+
+	return data, nil
+}
+
+// encodePrefixed returns the prefix followed by the URL-safe base64 of data,
+// encoded into a single buffer
+func encodePrefixed(prefix string, data []byte) string {
+	encoded := make([]byte, len(prefix)+base64.URLEncoding.EncodedLen(len(data)))
+	copy(encoded, prefix)
+	base64.URLEncoding.Encode(encoded[len(prefix):], data)
+	return string(encoded)
+}
+
+// PepperPassword returns the password mixed with the pepper, the HMAC-SHA256 of the
+// password keyed by the pepper, hex encoded. It is the password the key is derived
+// from for values encrypted with a pepper.
+func PepperPassword(password string, pepper []byte) string {
+	mac := hmac.New(sha256.New, pepper)
+	mac.Write([]byte(password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeriveKey derives the AES key from the password and salt with Argon2id
+func DeriveKey(password string, salt []byte, params Params) []byte {
+	return argon2.IDKey([]byte(password), salt,
+		uint32(params.Iterations),
+		uint32(params.Memory),
+		uint8(params.Parallelism),
+		uint32(params.KeyLength))
+}
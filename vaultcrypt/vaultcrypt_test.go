@@ -0,0 +1,160 @@
+package vaultcrypt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testParams are lightweight parameters keeping the tests fast
+func testParams() Params {
+	params := DefaultParams()
+	params.Iterations = 1
+	params.Memory = 8 * 1024
+	params.Parallelism = 1
+	return params
+}
+
+func TestEncodeDecodeV2(t *testing.T) {
+	params := testParams()
+
+	ciphertext, err := EncodeV2("secret value", "password", params)
+	if err != nil {
+		t.Fatalf("EncodeV2: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.HasPrefix(ciphertext, PREFIX_V2) {
+		t.Fatalf("Expected the [%v] prefix received [%v]", PREFIX_V2, ciphertext)
+	}
+
+	value, err := DecodeV2(ciphertext, "password", params)
+	if err != nil {
+		t.Fatalf("DecodeV2: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret value" {
+		t.Fatalf("Expected [secret value] received [%v]", value)
+	}
+
+	// Random salt and nonce
+	again, err := EncodeV2("secret value", "password", params)
+	if err != nil {
+		t.Fatalf("EncodeV2: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if again == ciphertext {
+		t.Fatal("Expected two encryptions of the same value to differ")
+	}
+}
+
+func TestDecodeV2_Errors(t *testing.T) {
+	params := testParams()
+
+	ciphertext, err := EncodeV2("secret value", "password", params)
+	if err != nil {
+		t.Fatalf("EncodeV2: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := DecodeV2(ciphertext, "wrong password", params); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", err)
+	}
+
+	// The parameters are not stored in the ciphertext
+	otherParams := params
+	otherParams.Iterations = 2
+	if _, err := DecodeV2(ciphertext, "password", otherParams); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] for other parameters received [%v]", err)
+	}
+
+	for _, malformed := range []string{PREFIX_V2 + "not base64!", PREFIX_V2 + "c2hvcnQ="} {
+		_, err := DecodeV2(malformed, "password", params)
+		if err == nil || errors.Is(err, ErrDecryptionFailed) {
+			t.Fatalf("Expected a malformed ciphertext error for [%v] received [%v]", malformed, err)
+		}
+	}
+}
+
+func TestEncodeDecodeV3(t *testing.T) {
+	params := testParams()
+	params.Cipher = CIPHER_XCHACHA20_POLY1305
+
+	ciphertext, err := Encode("secret value", "password", params)
+	if err != nil {
+		t.Fatalf("Encode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.HasPrefix(ciphertext, PREFIX_V3) {
+		t.Fatalf("Expected the [%v] prefix received [%v]", PREFIX_V3, ciphertext)
+	}
+
+	value, err := Decode(ciphertext, "password", params)
+	if err != nil {
+		t.Fatalf("Decode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret value" {
+		t.Fatalf("Expected [secret value] received [%v]", value)
+	}
+
+	if _, err := Decode(ciphertext, "wrong password", params); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", err)
+	}
+
+	params.Cipher = "rot13"
+	if _, err := Encode("secret value", "password", params); !errors.Is(err, ErrCipherUnsupported) {
+		t.Fatalf("Expected [ErrCipherUnsupported] received [%v]", err)
+	}
+}
+
+func TestEncodeDecode_Pepper(t *testing.T) {
+	params := testParams()
+	params.Pepper = []byte(strings.Repeat("p", 32))
+
+	ciphertext, err := Encode("secret value", "password", params)
+	if err != nil {
+		t.Fatalf("Encode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if !strings.HasPrefix(ciphertext, PREFIX_PEPPER+PREFIX_V2) {
+		t.Fatalf("Expected the [%v] prefix received [%v]", PREFIX_PEPPER+PREFIX_V2, ciphertext)
+	}
+
+	value, err := Decode(ciphertext, "password", params)
+	if err != nil {
+		t.Fatalf("Decode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret value" {
+		t.Fatalf("Expected [secret value] received [%v]", value)
+	}
+
+	// The peppered value is the one of the peppered password
+	inner, err := DecodeV2(strings.TrimPrefix(ciphertext, PREFIX_PEPPER), PepperPassword("password", params.Pepper), testParams())
+	if err != nil || inner != "secret value" {
+		t.Fatalf("DecodeV2: Expected [secret value] received [%v] [%v]", inner, err)
+	}
+
+	if _, err := Decode(ciphertext, "password", testParams()); !errors.Is(err, ErrPepperMissing) {
+		t.Fatalf("Expected [ErrPepperMissing] received [%v]", err)
+	}
+
+	otherPepper := params
+	otherPepper.Pepper = []byte(strings.Repeat("q", 32))
+	if _, err := Decode(ciphertext, "password", otherPepper); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", err)
+	}
+
+	// Values encrypted without the pepper stay readable
+	unpeppered, err := Encode("secret value", "password", testParams())
+	if err != nil {
+		t.Fatalf("Encode: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value, err := Decode(unpeppered, "password", params); err != nil || value != "secret value" {
+		t.Fatalf("Decode: Expected [secret value] received [%v] [%v]", value, err)
+	}
+
+	if _, err := Unpack(ciphertext, params); err != nil {
+		t.Fatalf("Unpack: Expected [err] to be nil received [%v]", err.Error())
+	}
+}
@@ -0,0 +1,32 @@
+// Package vaultgrpc serves the vault store over gRPC, so that services written in
+// other languages can share one vault. The service is defined in vaultstore.proto.
+//
+// The package is a module of its own, so gRPC is not a dependency of the vault store:
+//
+//	go get github.com/dracory/vaultstore/vaultgrpc
+//
+// Usage:
+//
+//	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+//	vaultgrpc.Register(server, store)
+//	err := server.Serve(listener)
+//
+// The errors of the store are returned with a status code. A missing, expired, revoked,
+// consumed or quarantined token and a wrong password all return codes.NotFound with the
+// same message, so the server cannot be used to probe for tokens. TokensRead streams
+// the values as they are decrypted.
+//
+// The server does not authenticate the clients, configure it with interceptors or
+// mutual TLS. The attempt limiter interceptors throttle the clients per peer and
+// per credential, with codes.ResourceExhausted:
+//
+//	limiter := vaultstore.NewAttemptLimiter(100, time.Minute)
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(vaultgrpc.UnaryAttemptLimiter(limiter)),
+//		grpc.StreamInterceptor(vaultgrpc.StreamAttemptLimiter(limiter)),
+//	)
+//
+// Clients of other languages generate their stubs from vaultstore.proto.
+package vaultgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative vaultstore.proto
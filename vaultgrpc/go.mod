@@ -0,0 +1,11 @@
+module github.com/dracory/vaultstore/vaultgrpc
+
+go 1.26
+
+require (
+	github.com/dracory/vaultstore v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.83.1
+	google.golang.org/protobuf v1.36.11
+)
+
+replace github.com/dracory/vaultstore => ../
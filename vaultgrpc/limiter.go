@@ -0,0 +1,82 @@
+package vaultgrpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+
+	"github.com/dracory/vaultstore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// UnaryAttemptLimiter returns an interceptor limiting the calls of the token methods,
+// all but TokenCreate, per peer IP and per credential. Throttled calls return
+// codes.ResourceExhausted. Size the limit for the call rate of the legitimate clients.
+func UnaryAttemptLimiter(limiter *vaultstore.AttemptLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod != VaultStore_TokenCreate_FullMethodName && !attemptAllow(ctx, limiter) {
+			return nil, statusError(vaultstore.ErrTooManyAttempts)
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// StreamAttemptLimiter returns an interceptor limiting the streaming calls, such as
+// TokensRead, like UnaryAttemptLimiter
+func StreamAttemptLimiter(limiter *vaultstore.AttemptLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !attemptAllow(stream.Context(), limiter) {
+			return statusError(vaultstore.ErrTooManyAttempts)
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// attemptAllow records the attempt of the peer and of its credential, reporting
+// whether both are within the limit
+func attemptAllow(ctx context.Context, limiter *vaultstore.AttemptLimiter) bool {
+	allowed := limiter.Allow("ip:" + peerIP(ctx))
+	if credential := credentialKey(ctx); credential != "" {
+		allowed = limiter.Allow("credential:"+credential) && allowed
+	}
+
+	return allowed
+}
+
+// peerIP returns the IP address of the peer, without the port
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// credentialKey returns the key of the credentials of the call, the common name of the
+// client certificate or the hash of the authorization metadata, or an empty string
+func credentialKey(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 && len(tlsInfo.State.VerifiedChains[0]) > 0 {
+			return "cert:" + tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+		}
+	}
+
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 && values[0] != "" {
+		sum := sha256.Sum256([]byte(values[0]))
+		return "token:" + hex.EncodeToString(sum[:])
+	}
+
+	return ""
+}
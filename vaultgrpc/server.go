@@ -0,0 +1,192 @@
+package vaultgrpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dracory/vaultstore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TOKEN_LENGTH_DEFAULT is the length of the tokens created without a token_length
+const TOKEN_LENGTH_DEFAULT = 32
+
+// errorCodes maps the store errors to their status code, other errors answer codes.Internal.
+// The errors revealing whether a token exists are mapped to vaultstore.ErrTokenAccessDenied
+// first, see vaultstore.UniformTokenError.
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{vaultstore.ErrTokenAccessDenied, codes.NotFound},
+	{vaultstore.ErrTooManyAttempts, codes.ResourceExhausted},
+	{vaultstore.ErrOperationDenied, codes.PermissionDenied},
+	{vaultstore.ErrTokenAlreadyExists, codes.AlreadyExists},
+	{vaultstore.ErrTokenSoftDeleted, codes.AlreadyExists},
+	{vaultstore.ErrTokenStreamed, codes.FailedPrecondition},
+	{vaultstore.ErrPasswordInvalid, codes.InvalidArgument},
+	{vaultstore.ErrValueInvalid, codes.InvalidArgument},
+	{vaultstore.ErrExpiresAtInPast, codes.InvalidArgument},
+	{vaultstore.ErrExpiresAtOutOfRange, codes.InvalidArgument},
+	{vaultstore.ErrExpiresAtExceedsRetention, codes.InvalidArgument},
+}
+
+// Server implements VaultStoreServer by delegating to the store
+type Server struct {
+	UnimplementedVaultStoreServer
+
+	store vaultstore.TokenStoreInterface
+}
+
+var _ VaultStoreServer = (*Server)(nil)
+
+// NewServer creates a server delegating to the store
+func NewServer(store vaultstore.TokenStoreInterface) *Server {
+	return &Server{store: store}
+}
+
+// Register creates a server delegating to the store and registers it with the gRPC server
+func Register(registrar grpc.ServiceRegistrar, store vaultstore.TokenStoreInterface) {
+	RegisterVaultStoreServer(registrar, NewServer(store))
+}
+
+// TokenCreate stores a value under a new token
+func (server *Server) TokenCreate(ctx context.Context, request *TokenCreateRequest) (*TokenCreateResponse, error) {
+	expiresAt, err := timeParse(request.GetExpiresAt())
+	if err != nil {
+		return nil, err
+	}
+
+	tokenLength := int(request.GetTokenLength())
+	if tokenLength == 0 {
+		tokenLength = TOKEN_LENGTH_DEFAULT
+	}
+
+	token, err := server.store.TokenCreate(ctx, request.GetValue(), request.GetPassword(), tokenLength, vaultstore.TokenCreateOptions{
+		ExpiresAt:   expiresAt,
+		ContentType: request.GetContentType(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &TokenCreateResponse{Token: token}, nil
+}
+
+// TokenRead reads the value of a token
+func (server *Server) TokenRead(ctx context.Context, request *TokenReadRequest) (*TokenReadResponse, error) {
+	value, info, err := server.store.TokenReadWithInfo(ctx, request.GetToken(), request.GetPassword())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &TokenReadResponse{
+		Token:       request.GetToken(),
+		Value:       value,
+		ContentType: info.ContentType,
+		ExpiresAt:   timeFormat(info.ExpiresAt),
+	}, nil
+}
+
+// TokenUpdate replaces the value of a token, encrypted with the password
+func (server *Server) TokenUpdate(ctx context.Context, request *TokenUpdateRequest) (*TokenUpdateResponse, error) {
+	if err := server.store.TokenUpdate(ctx, request.GetToken(), request.GetValue(), request.GetPassword()); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &TokenUpdateResponse{}, nil
+}
+
+// TokenDelete deletes a token
+func (server *Server) TokenDelete(ctx context.Context, request *TokenDeleteRequest) (*TokenDeleteResponse, error) {
+	if err := server.store.TokenDelete(ctx, request.GetToken()); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &TokenDeleteResponse{}, nil
+}
+
+// TokenRenew sets the expiration of a token
+func (server *Server) TokenRenew(ctx context.Context, request *TokenRenewRequest) (*TokenRenewResponse, error) {
+	expiresAt, err := timeParse(request.GetExpiresAt())
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.IsZero() {
+		return nil, status.Error(codes.InvalidArgument, "expires_at is required")
+	}
+
+	if err := server.store.TokenRenew(ctx, request.GetToken(), expiresAt); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &TokenRenewResponse{}, nil
+}
+
+// TokensRead streams the values of the tokens readable with the password, as they
+// are decrypted, so that bulk reads do not build the full result in memory
+func (server *Server) TokensRead(request *TokensReadRequest, stream grpc.ServerStreamingServer[TokenReadResponse]) error {
+	err := server.store.TokensReadFunc(stream.Context(), request.GetTokens(), request.GetPassword(), func(token string, value string) error {
+		return stream.Send(&TokenReadResponse{Token: token, Value: value})
+	})
+	if err != nil {
+		return statusError(err)
+	}
+
+	return nil
+}
+
+// statusError converts an error of the store to a gRPC status with the message of the
+// matched error. The wrapped details, e.g. revocation reasons, and unknown errors are
+// not described, they may carry internal details.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	err = vaultstore.UniformTokenError(err)
+
+	for _, errorCode := range errorCodes {
+		if errors.Is(err, errorCode.err) {
+			return status.Error(errorCode.code, errorCode.err.Error())
+		}
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+// timeParse parses an RFC 3339 time, the zero time if empty
+func timeParse(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, status.Error(codes.InvalidArgument, "time must be in the RFC 3339 format")
+	}
+
+	return t, nil
+}
+
+// timeFormat formats a store datetime in RFC 3339, empty if it never expires
+func timeFormat(datetime string) string {
+	if datetime == "" || datetime == vaultstore.MAX_DATETIME {
+		return ""
+	}
+
+	t, err := time.ParseInLocation(time.DateTime, datetime, time.UTC)
+	if err != nil {
+		return datetime
+	}
+
+	return t.Format(time.RFC3339)
+}
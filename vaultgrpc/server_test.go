@@ -0,0 +1,152 @@
+package vaultgrpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dracory/vaultstore"
+	"github.com/dracory/vaultstore/vaultstoretest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// streamRecorder records the messages sent on a server stream
+type streamRecorder struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*TokenReadResponse
+}
+
+func (stream *streamRecorder) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *streamRecorder) Send(response *TokenReadResponse) error {
+	stream.sent = append(stream.sent, response)
+	return nil
+}
+
+func TestServer_TokenLifecycle(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(vaultstoretest.New())
+
+	created, err := server.TokenCreate(ctx, &TokenCreateRequest{Value: "secret", Password: "password"})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	read, err := server.TokenRead(ctx, &TokenReadRequest{Token: created.GetToken(), Password: "password"})
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if read.GetValue() != "secret" {
+		t.Fatalf("Expected [secret] received [%v]", read.GetValue())
+	}
+
+	if read.GetExpiresAt() != "" {
+		t.Fatalf("Expected no expiration received [%v]", read.GetExpiresAt())
+	}
+
+	// A wrong password answers like a missing token
+	_, errWrongPassword := server.TokenRead(ctx, &TokenReadRequest{Token: created.GetToken(), Password: "wrong"})
+	_, errNotFound := server.TokenRead(ctx, &TokenReadRequest{Token: "tk_unknown", Password: "password"})
+	if status.Code(errWrongPassword) != codes.NotFound || errWrongPassword.Error() != errNotFound.Error() {
+		t.Fatalf("Expected the same [NotFound] received [%v] [%v]", errWrongPassword, errNotFound)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if _, err := server.TokenRenew(ctx, &TokenRenewRequest{Token: created.GetToken(), ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("TokenRenew: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	read, err = server.TokenRead(ctx, &TokenReadRequest{Token: created.GetToken(), Password: "password"})
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if read.GetExpiresAt() != expiresAt {
+		t.Fatalf("Expected [%v] received [%v]", expiresAt, read.GetExpiresAt())
+	}
+
+	if _, err := server.TokenDelete(ctx, &TokenDeleteRequest{Token: created.GetToken()}); err != nil {
+		t.Fatalf("TokenDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	_, err = server.TokenRead(ctx, &TokenReadRequest{Token: created.GetToken(), Password: "password"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected [NotFound] received [%v]", err)
+	}
+}
+
+func TestServer_TokensRead(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(vaultstoretest.New())
+
+	tokens := []string{}
+	for _, value := range []string{"one", "two", "three"} {
+		created, err := server.TokenCreate(ctx, &TokenCreateRequest{Value: value, Password: "password"})
+		if err != nil {
+			t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+		}
+		tokens = append(tokens, created.GetToken())
+	}
+
+	stream := &streamRecorder{ctx: ctx}
+	if err := server.TokensRead(&TokensReadRequest{Tokens: tokens, Password: "password"}, stream); err != nil {
+		t.Fatalf("TokensRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(stream.sent) != 3 {
+		t.Fatalf("Expected [3] values received [%v]", len(stream.sent))
+	}
+}
+
+func TestServer_InvalidTime(t *testing.T) {
+	server := NewServer(vaultstoretest.New())
+
+	_, err := server.TokenCreate(context.Background(), &TokenCreateRequest{Value: "secret", Password: "password", ExpiresAt: "tomorrow"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected [InvalidArgument] received [%v]", err)
+	}
+}
+
+func TestUnaryAttemptLimiter(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	interceptor := UnaryAttemptLimiter(vaultstore.NewAttemptLimiter(2, time.Minute))
+
+	handler := func(ctx context.Context, request any) (any, error) {
+		return "ok", nil
+	}
+
+	readInfo := &grpc.UnaryServerInfo{FullMethod: VaultStore_TokenRead_FullMethodName}
+	for i := 0; i < 2; i++ {
+		if _, err := interceptor(ctx, nil, readInfo, handler); err != nil {
+			t.Fatalf("Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	if _, err := interceptor(ctx, nil, readInfo, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected [ResourceExhausted] received [%v]", err)
+	}
+
+	createInfo := &grpc.UnaryServerInfo{FullMethod: VaultStore_TokenCreate_FullMethodName}
+	if _, err := interceptor(ctx, nil, createInfo, handler); err != nil {
+		t.Fatalf("Expected TokenCreate not to be limited received [%v]", err)
+	}
+}
+
+func TestStatusError_MissingTokens(t *testing.T) {
+	missing := statusError(fmt.Errorf("%w: 1 missing tokens", vaultstore.ErrTokenNotFound))
+	wrongPassword := statusError(vaultstore.ErrDecryptionFailed)
+
+	// Missing tokens and a wrong password cannot be told apart
+	if status.Code(missing) != codes.NotFound || missing.Error() != wrongPassword.Error() {
+		t.Fatalf("Expected [%v] received [%v]", wrongPassword, missing)
+	}
+}
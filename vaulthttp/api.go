@@ -0,0 +1,331 @@
+package vaulthttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dracory/vaultstore"
+)
+
+// PATH_TOKENS is the path of the token endpoints
+const PATH_TOKENS = "/tokens"
+
+// HEADER_PASSWORD is the request header carrying the password of the token read
+const HEADER_PASSWORD = "X-Vault-Password"
+
+// TOKEN_LENGTH_DEFAULT is the length of the tokens created without a token_length
+const TOKEN_LENGTH_DEFAULT = 32
+
+// BODY_MAX_BYTES_DEFAULT is the default size limit of the request bodies
+const BODY_MAX_BYTES_DEFAULT = 1 << 20
+
+// APIOptions configures the HTTP API of the store
+type APIOptions struct {
+	// Store serves the token endpoints
+	Store vaultstore.StoreInterface
+
+	// Auth configures the authentication of the token endpoints.
+	// Auth.Settings defaults to the store.
+	Auth AuthOptions
+
+	// Observability configures the probe and metrics endpoints, served without
+	// authentication. Observability.Store defaults to the store.
+	Observability ObservabilityOptions
+
+	// BodyMaxBytes limits the size of the request bodies, defaults to 1 MiB
+	BodyMaxBytes int64
+
+	// AttemptLimiter, if set, limits the requests of the endpoints of a token,
+	// GET, DELETE, renew and rekey, per client IP and per credential. Throttled
+	// requests answer 429. Size the limit for the request rate of the legitimate clients.
+	AttemptLimiter *vaultstore.AttemptLimiter
+}
+
+// apiError is the JSON body of the error responses
+type apiError struct {
+	Error apiErrorDetail `json:"error"`
+}
+
+// apiErrorDetail describes an error with a stable code for the clients
+type apiErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// tokenCreateRequest is the JSON body of POST /tokens
+type tokenCreateRequest struct {
+	Value       string    `json:"value"`
+	Password    string    `json:"password"`
+	TokenLength int       `json:"token_length,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at,omitzero"`
+	ContentType string    `json:"content_type,omitempty"`
+}
+
+// tokenRenewRequest is the JSON body of POST /tokens/{token}/renew
+type tokenRenewRequest struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// tokenRekeyRequest is the JSON body of POST /tokens/{token}/rekey
+type tokenRekeyRequest struct {
+	Password    string `json:"password"`
+	NewPassword string `json:"new_password"`
+}
+
+// tokenResponse is the JSON body of the token responses
+type tokenResponse struct {
+	Token       string `json:"token"`
+	Value       string `json:"value,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+}
+
+// apiStatuses maps the store errors to their status and code, other errors answer 500.
+// The errors revealing whether a token exists are mapped to vaultstore.ErrTokenAccessDenied
+// first, see vaultstore.UniformTokenError.
+var apiStatuses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{vaultstore.ErrTokenAccessDenied, http.StatusNotFound, "token_not_found"},
+	{vaultstore.ErrTooManyAttempts, http.StatusTooManyRequests, "too_many_attempts"},
+	{vaultstore.ErrOperationDenied, http.StatusForbidden, "operation_denied"},
+	{vaultstore.ErrTokenAlreadyExists, http.StatusConflict, "token_exists"},
+	{vaultstore.ErrTokenSoftDeleted, http.StatusConflict, "token_exists"},
+	{vaultstore.ErrTokenStreamed, http.StatusConflict, "token_streamed"},
+	{vaultstore.ErrPasswordInvalid, http.StatusUnprocessableEntity, "password_invalid"},
+	{vaultstore.ErrValueInvalid, http.StatusUnprocessableEntity, "value_invalid"},
+	{vaultstore.ErrExpiresAtInPast, http.StatusUnprocessableEntity, "expires_at_invalid"},
+	{vaultstore.ErrExpiresAtOutOfRange, http.StatusUnprocessableEntity, "expires_at_invalid"},
+	{vaultstore.ErrExpiresAtExceedsRetention, http.StatusUnprocessableEntity, "expires_at_invalid"},
+}
+
+// APIHandler serves the store over HTTP for the services not written in Go:
+//
+//   - POST /tokens creates a token from {"value", "password", "token_length", "expires_at", "content_type"}
+//   - GET /tokens/{token} reads a token, with the password in the X-Vault-Password header
+//   - DELETE /tokens/{token} deletes a token
+//   - POST /tokens/{token}/renew sets the expiration of a token from {"expires_at"}
+//   - POST /tokens/{token}/rekey encrypts a token with a new password from {"password", "new_password"}
+//
+// The token endpoints require AuthMiddleware credentials, reads the read permission
+// and changes the admin permission. The ObservabilityHandler endpoints are served
+// without authentication. Errors answer a JSON body {"error": {"code", "message"}}.
+// A missing, expired, revoked, consumed, quarantined or checked out token, a token
+// requiring a break-glass grant and a wrong password all answer 404 token_not_found,
+// so the API cannot be used to probe for tokens. The rekey endpoint changes the password
+// with a conditional write and does not count against the read limit of the token.
+func APIHandler(options APIOptions) http.Handler {
+	if options.Auth.Settings == nil && options.Store != nil {
+		options.Auth.Settings = options.Store
+	}
+
+	if options.Observability.Store == nil && options.Store != nil {
+		options.Observability.Store = options.Store
+	}
+
+	if options.BodyMaxBytes <= 0 {
+		options.BodyMaxBytes = BODY_MAX_BYTES_DEFAULT
+	}
+
+	api := &apiHandler{options: options}
+
+	tokens := http.NewServeMux()
+	tokens.HandleFunc("POST "+PATH_TOKENS, api.tokenCreate)
+	tokens.HandleFunc("GET "+PATH_TOKENS+"/{token}", api.limit(api.tokenRead))
+	tokens.HandleFunc("DELETE "+PATH_TOKENS+"/{token}", api.limit(api.tokenDelete))
+	tokens.HandleFunc("POST "+PATH_TOKENS+"/{token}/renew", api.limit(api.tokenRenew))
+	tokens.HandleFunc("POST "+PATH_TOKENS+"/{token}/rekey", api.limit(api.tokenRekey))
+
+	authenticated := AuthMiddleware(options.Auth)(tokens)
+
+	mux := http.NewServeMux()
+	mux.Handle(PATH_TOKENS, authenticated)
+	mux.Handle(PATH_TOKENS+"/", authenticated)
+	mux.Handle("/", ObservabilityHandler(options.Observability))
+
+	return mux
+}
+
+// apiHandler serves the token endpoints
+type apiHandler struct {
+	options APIOptions
+}
+
+// limit answers 429 to the clients over the attempt limit, per client IP and per credential
+func (api *apiHandler) limit(next http.HandlerFunc) http.HandlerFunc {
+	limiter := api.options.AttemptLimiter
+	if limiter == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		allowed := limiter.Allow("ip:" + clientIP(r))
+		if credential := credentialKey(r); credential != "" {
+			allowed = limiter.Allow("credential:"+credential) && allowed
+		}
+
+		if !allowed {
+			writeError(w, vaultstore.ErrTooManyAttempts)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// clientIP returns the IP address of the client, without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// tokenCreate serves POST /tokens
+func (api *apiHandler) tokenCreate(w http.ResponseWriter, r *http.Request) {
+	var request tokenCreateRequest
+	if !api.decode(w, r, &request) {
+		return
+	}
+
+	tokenLength := request.TokenLength
+	if tokenLength == 0 {
+		tokenLength = TOKEN_LENGTH_DEFAULT
+	}
+
+	token, err := api.options.Store.TokenCreate(r.Context(), request.Value, request.Password, tokenLength, vaultstore.TokenCreateOptions{
+		ExpiresAt:   request.ExpiresAt,
+		ContentType: request.ContentType,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tokenResponse{Token: token})
+}
+
+// tokenRead serves GET /tokens/{token}
+func (api *apiHandler) tokenRead(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	value, info, err := api.options.Store.TokenReadWithInfo(r.Context(), token, r.Header.Get(HEADER_PASSWORD))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	expiresAt := info.ExpiresAt
+	if expiresAt == vaultstore.MAX_DATETIME {
+		expiresAt = ""
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, tokenResponse{
+		Token:       token,
+		Value:       value,
+		ContentType: info.ContentType,
+		ExpiresAt:   expiresAt,
+	})
+}
+
+// tokenDelete serves DELETE /tokens/{token}
+func (api *apiHandler) tokenDelete(w http.ResponseWriter, r *http.Request) {
+	if err := api.options.Store.TokenDelete(r.Context(), r.PathValue("token")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tokenRenew serves POST /tokens/{token}/renew
+func (api *apiHandler) tokenRenew(w http.ResponseWriter, r *http.Request) {
+	var request tokenRenewRequest
+	if !api.decode(w, r, &request) {
+		return
+	}
+
+	if request.ExpiresAt.IsZero() {
+		writeErrorCode(w, http.StatusBadRequest, "request_invalid", "expires_at is required")
+		return
+	}
+
+	if err := api.options.Store.TokenRenew(r.Context(), r.PathValue("token"), request.ExpiresAt); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tokenRekey serves POST /tokens/{token}/rekey. The value is read with the
+// current password and updated with the new one.
+func (api *apiHandler) tokenRekey(w http.ResponseWriter, r *http.Request) {
+	var request tokenRekeyRequest
+	if !api.decode(w, r, &request) {
+		return
+	}
+
+	token := r.PathValue("token")
+
+	if err := api.options.Store.TokenChangePassword(r.Context(), token, request.Password, request.NewPassword); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decode reads the JSON body of the request, answering 400 if it is invalid
+func (api *apiHandler) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, api.options.BodyMaxBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrorCode(w, http.StatusRequestEntityTooLarge, "request_too_large", "request body is too large")
+			return false
+		}
+
+		writeErrorCode(w, http.StatusBadRequest, "request_invalid", "request body is not valid JSON")
+		return false
+	}
+
+	return true
+}
+
+// writeError answers the error of the store with the message of the matched error.
+// The wrapped details, e.g. revocation reasons, and unknown errors are not described,
+// they may carry internal details.
+func writeError(w http.ResponseWriter, err error) {
+	err = vaultstore.UniformTokenError(err)
+
+	for _, status := range apiStatuses {
+		if errors.Is(err, status.err) {
+			writeErrorCode(w, status.status, status.code, status.err.Error())
+			return
+		}
+	}
+
+	writeErrorCode(w, http.StatusInternalServerError, "internal", http.StatusText(http.StatusInternalServerError))
+}
+
+// writeErrorCode answers a JSON error
+func writeErrorCode(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, apiError{Error: apiErrorDetail{Code: code, Message: message}})
+}
+
+// writeJSON answers a JSON body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
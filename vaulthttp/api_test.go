@@ -0,0 +1,204 @@
+package vaulthttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dracory/vaultstore"
+)
+
+// apiRequest serves a request with the admin API token and returns the response
+func apiRequest(t *testing.T, handler http.Handler, method string, path string, body string, password string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer admin_api_token")
+	if password != "" {
+		r.Header.Set(HEADER_PASSWORD, password)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// apiErrorCode returns the code of a JSON error response
+func apiErrorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	var response apiError
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Expected a JSON error received [%s]", w.Body.String())
+	}
+	return response.Error.Code
+}
+
+func Test_APIHandler_TokenLifecycle(t *testing.T) {
+	store := initStore(t)
+
+	if err := APITokenRegister(context.Background(), store, "ops", "admin_api_token", PERMISSION_ADMIN); err != nil {
+		t.Fatalf("APITokenRegister: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	handler := APIHandler(APIOptions{Store: store})
+
+	// Create
+	w := apiRequest(t, handler, http.MethodPost, "/tokens", `{"value":"secret","password":"password_1234567890"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create: Expected status [%d] received [%d] [%s]", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var created tokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.Token == "" {
+		t.Fatalf("Create: Expected a token received [%s]", w.Body.String())
+	}
+
+	path := "/tokens/" + created.Token
+
+	// Read
+	w = apiRequest(t, handler, http.MethodGet, path, "", "password_1234567890")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Read: Expected status [%d] received [%d] [%s]", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var read tokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &read); err != nil || read.Value != "secret" {
+		t.Fatalf("Read: Expected the value [secret] received [%s]", w.Body.String())
+	}
+
+	// A wrong password answers like a missing token
+	w = apiRequest(t, handler, http.MethodGet, path, "", "password_wrong_12345")
+	if w.Code != http.StatusNotFound || apiErrorCode(t, w) != "token_not_found" {
+		t.Fatalf("Read: Expected [token_not_found] received [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	wrongPasswordBody := w.Body.String()
+	w = apiRequest(t, handler, http.MethodGet, "/tokens/tk_unknown", "", "password_wrong_12345")
+	if w.Code != http.StatusNotFound || w.Body.String() != wrongPasswordBody {
+		t.Fatalf("Read: Expected the answer of a wrong password received [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	// Rekey
+	w = apiRequest(t, handler, http.MethodPost, path+"/rekey", `{"password":"password_1234567890","new_password":"password_next_1234567"}`, "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Rekey: Expected status [%d] received [%d] [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+
+	w = apiRequest(t, handler, http.MethodGet, path, "", "password_next_1234567")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Read after rekey: Expected status [%d] received [%d] [%s]", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Renew
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	w = apiRequest(t, handler, http.MethodPost, path+"/renew", `{"expires_at":"`+expiresAt+`"}`, "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Renew: Expected status [%d] received [%d] [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+
+	// Delete
+	w = apiRequest(t, handler, http.MethodDelete, path, "", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Delete: Expected status [%d] received [%d] [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+
+	w = apiRequest(t, handler, http.MethodGet, path, "", "password_next_1234567")
+	if w.Code != http.StatusNotFound || apiErrorCode(t, w) != "token_not_found" {
+		t.Fatalf("Read after delete: Expected [token_not_found] received [%d] [%s]", w.Code, w.Body.String())
+	}
+}
+
+func Test_APIHandler_Errors(t *testing.T) {
+	store := initStore(t)
+
+	if err := APITokenRegister(context.Background(), store, "ops", "admin_api_token", PERMISSION_ADMIN); err != nil {
+		t.Fatalf("APITokenRegister: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	handler := APIHandler(APIOptions{Store: store, BodyMaxBytes: 64})
+
+	// Unauthenticated
+	r := httptest.NewRequest(http.MethodGet, "/tokens/tk_unknown", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized || apiErrorCode(t, w) != "unauthorized" {
+		t.Fatalf("Expected [unauthorized] received [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	// Invalid JSON
+	w = apiRequest(t, handler, http.MethodPost, "/tokens", `{"value":`, "")
+	if w.Code != http.StatusBadRequest || apiErrorCode(t, w) != "request_invalid" {
+		t.Fatalf("Expected [request_invalid] received [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	// Too large
+	w = apiRequest(t, handler, http.MethodPost, "/tokens", `{"value":"`+strings.Repeat("x", 100)+`","password":"password_1234567890"}`, "")
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected status [%d] received [%d] [%s]", http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+	}
+
+	// Probes are served without credentials
+	r = httptest.NewRequest(http.MethodGet, PATH_HEALTHZ, nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status [%d] received [%d]", http.StatusOK, w.Code)
+	}
+}
+
+func Test_APIHandler_UniformErrors(t *testing.T) {
+	store := initStore(t)
+
+	if err := APITokenRegister(context.Background(), store, "ops", "admin_api_token", PERMISSION_ADMIN); err != nil {
+		t.Fatalf("APITokenRegister: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	handler := APIHandler(APIOptions{Store: store})
+
+	token, err := store.TokenCreate(context.Background(), "secret", "password_1234567890", 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := store.TokenRevoke(context.Background(), token, "leaked in incident 42"); err != nil {
+		t.Fatalf("TokenRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	w := apiRequest(t, handler, http.MethodGet, "/tokens/"+token, "", "password_1234567890")
+	if w.Code != http.StatusNotFound || apiErrorCode(t, w) != "token_not_found" {
+		t.Fatalf("Expected [token_not_found] received [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	if strings.Contains(w.Body.String(), "incident") {
+		t.Fatalf("Expected the revocation reason not to be answered received [%s]", w.Body.String())
+	}
+}
+
+func Test_APIHandler_AttemptLimiter(t *testing.T) {
+	store := initStore(t)
+
+	if err := APITokenRegister(context.Background(), store, "ops", "admin_api_token", PERMISSION_ADMIN); err != nil {
+		t.Fatalf("APITokenRegister: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	handler := APIHandler(APIOptions{Store: store, AttemptLimiter: vaultstore.NewAttemptLimiter(2, time.Minute)})
+
+	for i := 0; i < 2; i++ {
+		w := apiRequest(t, handler, http.MethodGet, "/tokens/tk_unknown", "", "password_1234567890")
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("Expected status [%d] received [%d] [%s]", http.StatusNotFound, w.Code, w.Body.String())
+		}
+	}
+
+	w := apiRequest(t, handler, http.MethodGet, "/tokens/tk_unknown", "", "password_1234567890")
+	if w.Code != http.StatusTooManyRequests || apiErrorCode(t, w) != "too_many_attempts" {
+		t.Fatalf("Expected [too_many_attempts] received [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	// Creating tokens is not limited
+	w = apiRequest(t, handler, http.MethodPost, "/tokens", `{"value":"secret","password":"password_1234567890"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create: Expected status [%d] received [%d] [%s]", http.StatusCreated, w.Code, w.Body.String())
+	}
+}
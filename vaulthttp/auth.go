@@ -0,0 +1,255 @@
+// Package vaulthttp contains the building blocks of the HTTP facade of the vault store
+package vaulthttp
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/dracory/vaultstore"
+	"gorm.io/gorm"
+)
+
+// PERMISSION_READ allows read-only requests
+const PERMISSION_READ = "read"
+
+// PERMISSION_ADMIN allows all requests
+const PERMISSION_ADMIN = "admin"
+
+// SETTING_KEY_API_TOKENS is the vault setting holding the hashed API tokens
+const SETTING_KEY_API_TOKENS = "http_api_tokens"
+
+// ErrPermissionInvalid is returned for a permission other than PERMISSION_READ or PERMISSION_ADMIN
+var ErrPermissionInvalid = errors.New("permission must be read or admin")
+
+// apiTokensMutex serializes the read-modify-write of the API token entries,
+// so concurrent registrations and revocations do not overwrite each other
+var apiTokensMutex sync.Mutex
+
+// apiTokenEntry is the persisted entry of an API token, keyed by the token hash
+type apiTokenEntry struct {
+	Name       string `json:"name"`
+	Permission string `json:"permission"`
+}
+
+// permissionContextKey is the context key for the permission of the authenticated client
+type permissionContextKey struct{}
+
+// AuthOptions configures the authentication middleware
+type AuthOptions struct {
+	// Settings stores the hashed API tokens, usually the vault store itself
+	Settings vaultstore.SettingsStoreInterface
+
+	// ClientCertPermissions maps the common name of verified client
+	// certificates (mutual TLS) to their permission
+	ClientCertPermissions map[string]string
+
+	// RoutePermissions maps path prefixes to the permission they require.
+	// The longest matching prefix wins. Routes without a match require
+	// PERMISSION_READ for GET and HEAD requests and PERMISSION_ADMIN otherwise.
+	RoutePermissions map[string]string
+}
+
+// MutualTLSConfig returns a TLS configuration requiring client certificates
+// signed by one of the given certificate authorities
+func MutualTLSConfig(clientCAs *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// APITokenRegister stores the hash of the API token with its permission in the vault settings.
+// The plain API token is never persisted. Registrations and revocations are serialized
+// within the process, manage API tokens from a single process.
+func APITokenRegister(ctx context.Context, settings vaultstore.SettingsStoreInterface, name string, apiToken string, permission string) error {
+	if apiToken == "" {
+		return errors.New("api token is empty")
+	}
+
+	if !isPermissionValid(permission) {
+		return ErrPermissionInvalid
+	}
+
+	apiTokensMutex.Lock()
+	defer apiTokensMutex.Unlock()
+
+	entries, err := apiTokensLoad(ctx, settings)
+	if err != nil {
+		return err
+	}
+
+	entries[apiTokenHash(apiToken)] = apiTokenEntry{Name: name, Permission: permission}
+
+	return apiTokensSave(ctx, settings, entries)
+}
+
+// APITokenRevoke removes the API token from the vault settings
+func APITokenRevoke(ctx context.Context, settings vaultstore.SettingsStoreInterface, apiToken string) error {
+	apiTokensMutex.Lock()
+	defer apiTokensMutex.Unlock()
+
+	entries, err := apiTokensLoad(ctx, settings)
+	if err != nil {
+		return err
+	}
+
+	delete(entries, apiTokenHash(apiToken))
+
+	return apiTokensSave(ctx, settings, entries)
+}
+
+// PermissionFromContext returns the permission of the client authenticated by AuthMiddleware
+func PermissionFromContext(ctx context.Context) (string, bool) {
+	permission, ok := ctx.Value(permissionContextKey{}).(string)
+	return permission, ok
+}
+
+// AuthMiddleware authenticates requests with a verified client certificate or
+// a bearer API token, and rejects requests the client has no permission for.
+// Unauthenticated requests receive 401, insufficient permissions 403, with a JSON error.
+func AuthMiddleware(options AuthOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			permission, err := authenticate(r, options)
+			if err != nil {
+				writeErrorCode(w, http.StatusInternalServerError, "internal", http.StatusText(http.StatusInternalServerError))
+				return
+			}
+
+			if permission == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeErrorCode(w, http.StatusUnauthorized, "unauthorized", http.StatusText(http.StatusUnauthorized))
+				return
+			}
+
+			if !isPermissionSufficient(permission, routePermission(r, options.RoutePermissions)) {
+				writeErrorCode(w, http.StatusForbidden, "forbidden", http.StatusText(http.StatusForbidden))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), permissionContextKey{}, permission)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authenticate returns the permission of the client, or an empty string if
+// the request carries no valid credentials
+func authenticate(r *http.Request, options AuthOptions) (string, error) {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		commonName := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		if permission, ok := options.ClientCertPermissions[commonName]; ok && isPermissionValid(permission) {
+			return permission, nil
+		}
+	}
+
+	apiToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || apiToken == "" || options.Settings == nil {
+		return "", nil
+	}
+
+	entries, err := apiTokensLoad(r.Context(), options.Settings)
+	if err != nil {
+		return "", err
+	}
+
+	hash := apiTokenHash(apiToken)
+	for entryHash, entry := range entries {
+		if subtle.ConstantTimeCompare([]byte(entryHash), []byte(hash)) == 1 {
+			return entry.Permission, nil
+		}
+	}
+
+	return "", nil
+}
+
+// credentialKey returns the key of the credentials of the request, the common name of
+// the client certificate or the hash of the API token, or an empty string if there are none
+func credentialKey(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		return "cert:" + r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+
+	if apiToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && apiToken != "" {
+		return "token:" + apiTokenHash(apiToken)
+	}
+
+	return ""
+}
+
+// routePermission returns the permission required for the request
+func routePermission(r *http.Request, routePermissions map[string]string) string {
+	required := ""
+	matchLength := -1
+	for prefix, permission := range routePermissions {
+		if strings.HasPrefix(r.URL.Path, prefix) && len(prefix) > matchLength {
+			required = permission
+			matchLength = len(prefix)
+		}
+	}
+
+	if required != "" {
+		return required
+	}
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return PERMISSION_READ
+	}
+
+	return PERMISSION_ADMIN
+}
+
+// isPermissionValid checks that the permission is known
+func isPermissionValid(permission string) bool {
+	return permission == PERMISSION_READ || permission == PERMISSION_ADMIN
+}
+
+// isPermissionSufficient checks whether the granted permission covers the required one
+func isPermissionSufficient(granted string, required string) bool {
+	return granted == PERMISSION_ADMIN || granted == required
+}
+
+// apiTokenHash returns the hash under which an API token is stored
+func apiTokenHash(apiToken string) string {
+	sum := sha256.Sum256([]byte(apiToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiTokensLoad reads the API token entries from the vault settings
+func apiTokensLoad(ctx context.Context, settings vaultstore.SettingsStoreInterface) (map[string]apiTokenEntry, error) {
+	entries := map[string]apiTokenEntry{}
+
+	value, err := settings.GetVaultSetting(ctx, SETTING_KEY_API_TOKENS)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(value), &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// apiTokensSave writes the API token entries to the vault settings
+func apiTokensSave(ctx context.Context, settings vaultstore.SettingsStoreInterface, entries map[string]apiTokenEntry) error {
+	value, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return settings.SetVaultSetting(ctx, SETTING_KEY_API_TOKENS, string(value))
+}
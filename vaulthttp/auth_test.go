@@ -0,0 +1,153 @@
+package vaulthttp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	_ "github.com/glebarez/sqlite"
+
+	"github.com/dracory/vaultstore"
+)
+
+func initStore(t *testing.T) vaultstore.StoreInterface {
+	db, err := sql.Open("sqlite", ":memory:?parseTime=true")
+	if err != nil {
+		t.Fatalf("sql.Open: Expected [err] to be nil received [%v]", err.Error())
+	}
+	db.SetMaxOpenConns(1)
+
+	store, err := vaultstore.NewStore(vaultstore.NewStoreOptions{
+		VaultTableName:     "vault_token",
+		VaultMetaTableName: "vault_meta",
+		DB:                 db,
+		AutomigrateEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	return store
+}
+
+func Test_AuthMiddleware_APIToken(t *testing.T) {
+	store := initStore(t)
+	ctx := context.Background()
+
+	if err := APITokenRegister(ctx, store, "dashboard", "read_api_token", PERMISSION_READ); err != nil {
+		t.Fatalf("APITokenRegister: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if err := APITokenRegister(ctx, store, "ops", "admin_api_token", PERMISSION_ADMIN); err != nil {
+		t.Fatalf("APITokenRegister: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	handler := AuthMiddleware(AuthOptions{Settings: store})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		method   string
+		apiToken string
+		expected int
+	}{
+		{http.MethodGet, "", http.StatusUnauthorized},
+		{http.MethodGet, "unknown_api_token", http.StatusUnauthorized},
+		{http.MethodGet, "read_api_token", http.StatusNoContent},
+		{http.MethodPost, "read_api_token", http.StatusForbidden},
+		{http.MethodPost, "admin_api_token", http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/tokens/tk_1", nil)
+		if test.apiToken != "" {
+			r.Header.Set("Authorization", "Bearer "+test.apiToken)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != test.expected {
+			t.Fatalf("%s with [%s]: Expected status [%d] received [%d]", test.method, test.apiToken, test.expected, w.Code)
+		}
+	}
+
+	// Revoked API tokens are rejected
+	if err := APITokenRevoke(ctx, store, "read_api_token"); err != nil {
+		t.Fatalf("APITokenRevoke: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/tokens/tk_1", nil)
+	r.Header.Set("Authorization", "Bearer read_api_token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status [401] received [%d]", w.Code)
+	}
+}
+
+func Test_APITokenRegister_Concurrent(t *testing.T) {
+	store := initStore(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- APITokenRegister(ctx, store, "client", "api_token_"+strconv.Itoa(i), PERMISSION_READ)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("APITokenRegister: Expected [err] to be nil received [%v]", err.Error())
+		}
+	}
+
+	entries, err := apiTokensLoad(ctx, store)
+	if err != nil {
+		t.Fatalf("apiTokensLoad: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(entries) != 10 {
+		t.Fatalf("Expected [10] API tokens received [%d]", len(entries))
+	}
+}
+
+func Test_AuthMiddleware_ClientCertificate(t *testing.T) {
+	handler := AuthMiddleware(AuthOptions{
+		ClientCertPermissions: map[string]string{"reporting": PERMISSION_READ},
+		RoutePermissions:      map[string]string{"/admin": PERMISSION_ADMIN},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		permission, _ := PermissionFromContext(r.Context())
+		_, _ = w.Write([]byte(permission))
+	}))
+
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: "reporting"}}
+	state := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}
+
+	r := httptest.NewRequest(http.MethodGet, "/tokens/tk_1", nil)
+	r.TLS = state
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != PERMISSION_READ {
+		t.Fatalf("Expected read access received [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+	r.TLS = state
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected status [403] received [%d]", w.Code)
+	}
+}
@@ -0,0 +1,190 @@
+package vaulthttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dracory/vaultstore"
+)
+
+// Paths of the observability endpoints
+const (
+	PATH_HEALTHZ = "/healthz"
+	PATH_READYZ  = "/readyz"
+	PATH_METRICS = "/metrics"
+)
+
+// READY_CACHE_TTL_DEFAULT is how long a readiness result is reused by default
+const READY_CACHE_TTL_DEFAULT = 30 * time.Second
+
+// metricsContentType is the content type of the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// ObservabilityStore is the part of the store the observability endpoints report on
+type ObservabilityStore interface {
+	Preflight(ctx context.Context) (vaultstore.PreflightReport, error)
+	KDFStats() map[string]vaultstore.KDFStat
+	RecordCountEstimate(ctx context.Context) (int64, error)
+}
+
+// ObservabilityOptions configures the observability endpoints
+type ObservabilityOptions struct {
+	// Store is the store reported on, usually the vault store itself
+	Store ObservabilityStore
+
+	// ReadyCacheTTL is how long a readiness result is reused, defaults to 30 seconds.
+	// The readiness check runs the store preflight, which includes a key derivation.
+	ReadyCacheTTL time.Duration
+}
+
+// readyState is a cached readiness result
+type readyState struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	ready     bool
+	failures  []string
+}
+
+// readyResponse is the JSON body of the readiness endpoint
+type readyResponse struct {
+	Status   string   `json:"status"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// ObservabilityHandler serves the endpoints used by orchestration probes and monitoring:
+//
+//   - GET /healthz answers 200 while the process serves requests (liveness)
+//   - GET /readyz answers 200 when the store preflight passes, 503 otherwise (readiness)
+//   - GET /metrics exposes the store metrics in the Prometheus text format
+//
+// Probes usually call without credentials, so mount the handler outside of
+// AuthMiddleware, or exempt these paths from it.
+func ObservabilityHandler(options ObservabilityOptions) http.Handler {
+	state := &readyState{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+PATH_HEALTHZ, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET "+PATH_READYZ, func(w http.ResponseWriter, r *http.Request) {
+		serveReady(w, r, options, state)
+	})
+	mux.HandleFunc("GET "+PATH_METRICS, func(w http.ResponseWriter, r *http.Request) {
+		serveMetrics(w, r, options.Store)
+	})
+
+	return mux
+}
+
+// serveReady answers the readiness probe from the cached preflight result
+func serveReady(w http.ResponseWriter, r *http.Request, options ObservabilityOptions, state *readyState) {
+	ready, failures := state.check(r.Context(), options)
+
+	response := readyResponse{Status: "ready"}
+	status := http.StatusOK
+	if !ready {
+		response = readyResponse{Status: "not_ready", Failures: failures}
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// check returns the readiness, running the preflight if the cached result is stale.
+// Only the names of the failed checks are reported, the probes are unauthenticated.
+func (state *readyState) check(ctx context.Context, options ObservabilityOptions) (bool, []string) {
+	ttl := options.ReadyCacheTTL
+	if ttl <= 0 {
+		ttl = READY_CACHE_TTL_DEFAULT
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if !state.checkedAt.IsZero() && time.Since(state.checkedAt) < ttl {
+		return state.ready, state.failures
+	}
+
+	if options.Store == nil {
+		return false, []string{"store"}
+	}
+
+	report, err := options.Store.Preflight(ctx)
+	if err != nil {
+		// Not cached, the request may have been canceled
+		return false, []string{"preflight"}
+	}
+
+	state.checkedAt = time.Now()
+	state.ready = report.Passed()
+	state.failures = nil
+	for _, failure := range report.Failures() {
+		state.failures = append(state.failures, failure.Name)
+	}
+
+	return state.ready, state.failures
+}
+
+// serveMetrics writes the store metrics in the Prometheus text format
+func serveMetrics(w http.ResponseWriter, r *http.Request, store ObservabilityStore) {
+	if store == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	var b strings.Builder
+
+	// The record count doubles as a database reachability check
+	up := 1
+	records, err := store.RecordCountEstimate(r.Context())
+	if err != nil {
+		up = 0
+	}
+
+	metricWrite(&b, "vaultstore_up", "gauge", "Whether the vault database answered the scrape.")
+	fmt.Fprintf(&b, "vaultstore_up %d\n", up)
+
+	if err == nil {
+		metricWrite(&b, "vaultstore_records_estimated", "gauge", "Estimated number of rows of the vault table, soft deleted and expired included.")
+		fmt.Fprintf(&b, "vaultstore_records_estimated %d\n", records)
+	}
+
+	stats := store.KDFStats()
+	operations := make([]string, 0, len(stats))
+	for operation := range stats {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	metricWrite(&b, "vaultstore_kdf_derivations_total", "counter", "Number of Argon2id key derivations.")
+	for _, operation := range operations {
+		fmt.Fprintf(&b, "vaultstore_kdf_derivations_total{operation=%q} %d\n", operation, stats[operation].Count)
+	}
+
+	metricWrite(&b, "vaultstore_kdf_duration_seconds_total", "counter", "Time spent in Argon2id key derivations.")
+	for _, operation := range operations {
+		fmt.Fprintf(&b, "vaultstore_kdf_duration_seconds_total{operation=%q} %g\n", operation, stats[operation].Total.Seconds())
+	}
+
+	metricWrite(&b, "vaultstore_kdf_duration_seconds_max", "gauge", "Slowest Argon2id key derivation.")
+	for _, operation := range operations {
+		fmt.Fprintf(&b, "vaultstore_kdf_duration_seconds_max{operation=%q} %g\n", operation, stats[operation].Max.Seconds())
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// metricWrite writes the HELP and TYPE lines of a metric
+func metricWrite(b *strings.Builder, name string, metricType string, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
@@ -0,0 +1,127 @@
+package vaulthttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dracory/vaultstore"
+)
+
+// failingPreflightStore reports a failed preflight and an unreachable database
+type failingPreflightStore struct {
+	preflights int
+}
+
+func (store *failingPreflightStore) Preflight(ctx context.Context) (vaultstore.PreflightReport, error) {
+	store.preflights++
+	return vaultstore.PreflightReport{Checks: []vaultstore.PreflightCheck{
+		{Name: vaultstore.PREFLIGHT_CHECK_TABLES, Passed: false, Details: "missing table vault_token"},
+		{Name: vaultstore.PREFLIGHT_CHECK_CRYPTO_SELF_TEST, Passed: true},
+	}}, nil
+}
+
+func (store *failingPreflightStore) KDFStats() map[string]vaultstore.KDFStat {
+	return map[string]vaultstore.KDFStat{}
+}
+
+func (store *failingPreflightStore) RecordCountEstimate(ctx context.Context) (int64, error) {
+	return -1, errors.New("database is down")
+}
+
+func Test_ObservabilityHandler(t *testing.T) {
+	store := initStore(t)
+	ctx := context.Background()
+
+	if _, err := store.TokenCreate(ctx, "value", "test_password_that_is_long_enough_for_security_32chars", 20); err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	handler := ObservabilityHandler(ObservabilityOptions{Store: store})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, PATH_HEALTHZ, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("healthz: Expected status [200] received [%v]", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, PATH_READYZ, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("readyz: Expected status [200] received [%v] [%v]", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, PATH_METRICS, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("metrics: Expected status [200] received [%v]", recorder.Code)
+	}
+
+	if recorder.Header().Get("Content-Type") != metricsContentType {
+		t.Fatalf("metrics: Expected content type [%v] received [%v]", metricsContentType, recorder.Header().Get("Content-Type"))
+	}
+
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		"vaultstore_up 1\n",
+		"vaultstore_records_estimated 1\n",
+		"# TYPE vaultstore_kdf_derivations_total counter\n",
+		`vaultstore_kdf_derivations_total{operation="encrypt"} `,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("metrics: Expected [%v] in [%v]", expected, body)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, PATH_METRICS, nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("metrics: Expected status [405] received [%v]", recorder.Code)
+	}
+}
+
+func Test_ObservabilityHandler_NotReady(t *testing.T) {
+	store := &failingPreflightStore{}
+	handler := ObservabilityHandler(ObservabilityOptions{Store: store, ReadyCacheTTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, PATH_READYZ, nil))
+		if recorder.Code != http.StatusServiceUnavailable {
+			t.Fatalf("readyz: Expected status [503] received [%v]", recorder.Code)
+		}
+
+		response := readyResponse{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Unmarshal: Expected [err] to be nil received [%v]", err.Error())
+		}
+
+		if response.Status != "not_ready" || len(response.Failures) != 1 || response.Failures[0] != vaultstore.PREFLIGHT_CHECK_TABLES {
+			t.Fatalf("readyz: Unexpected response [%+v]", response)
+		}
+
+		// The details of the failures are not exposed
+		if strings.Contains(recorder.Body.String(), "vault_token") {
+			t.Fatalf("readyz: Expected no failure details received [%v]", recorder.Body.String())
+		}
+	}
+
+	if store.preflights != 1 {
+		t.Fatalf("Expected the readiness to be cached, preflight ran [%v] times", store.preflights)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, PATH_METRICS, nil))
+	if !strings.Contains(recorder.Body.String(), "vaultstore_up 0\n") {
+		t.Fatalf("metrics: Expected [vaultstore_up 0] in [%v]", recorder.Body.String())
+	}
+
+	if strings.Contains(recorder.Body.String(), "vaultstore_records_estimated") {
+		t.Fatalf("metrics: Expected no record estimate received [%v]", recorder.Body.String())
+	}
+}
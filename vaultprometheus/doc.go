@@ -0,0 +1,26 @@
+// Package vaultprometheus provides a vaultstore.MetricsCollector exposing the metrics
+// of the store to Prometheus.
+//
+// The package is a module of its own, so the Prometheus client is not a dependency
+// of the vault store:
+//
+//	go get github.com/dracory/vaultstore/vaultprometheus
+//
+// Usage:
+//
+//	collector := vaultprometheus.New("myapp")
+//	prometheus.MustRegister(collector)
+//
+//	store, err := vaultstore.NewStore(vaultstore.NewStoreOptions{
+//		...
+//		MetricsCollector: collector,
+//	})
+//
+// The metrics, prefixed with the namespace:
+//   - vaultstore_reads_total: the values read
+//   - vaultstore_writes_total: the values written
+//   - vaultstore_decrypt_failures_total: the values that failed to decrypt
+//   - vaultstore_encrypt_duration_seconds: the encryption latency
+//   - vaultstore_decrypt_duration_seconds: the decryption latency
+//   - vaultstore_kdf_duration_seconds: the Argon2id key derivation latency, by operation
+package vaultprometheus
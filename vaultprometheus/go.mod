@@ -0,0 +1,10 @@
+module github.com/dracory/vaultstore/vaultprometheus
+
+go 1.26
+
+require (
+	github.com/dracory/vaultstore v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.24.1
+)
+
+replace github.com/dracory/vaultstore => ../
@@ -0,0 +1,117 @@
+package vaultprometheus
+
+import (
+	"time"
+
+	"github.com/dracory/vaultstore"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SUBSYSTEM is the subsystem of the metric names
+const SUBSYSTEM = "vaultstore"
+
+// DurationBuckets are the buckets of the latency histograms, from 1ms to about 4s,
+// around the time of the Argon2id key derivation
+var DurationBuckets = prometheus.ExponentialBuckets(0.001, 2, 13)
+
+// Collector records the metrics of the store. It is both a vaultstore.MetricsCollector,
+// set as NewStoreOptions.MetricsCollector, and a prometheus.Collector, registered with
+// the Prometheus registry.
+type Collector struct {
+	reads           prometheus.Counter
+	writes          prometheus.Counter
+	decryptFailures prometheus.Counter
+	encryptDuration prometheus.Histogram
+	decryptDuration prometheus.Histogram
+	kdfDuration     *prometheus.HistogramVec
+}
+
+var _ vaultstore.MetricsCollector = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+
+// New creates the collector
+//
+// Parameters:
+// - namespace: The prefix of the metric names, e.g. the application name (optional)
+//
+// Returns:
+// - *Collector: The collector, to register with the Prometheus registry
+func New(namespace string) *Collector {
+	counter := func(name string, help string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: SUBSYSTEM,
+			Name:      name,
+			Help:      help,
+		})
+	}
+
+	histogram := func(name string, help string) prometheus.HistogramOpts {
+		return prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: SUBSYSTEM,
+			Name:      name,
+			Help:      help,
+			Buckets:   DurationBuckets,
+		}
+	}
+
+	return &Collector{
+		reads:           counter("reads_total", "Number of values read."),
+		writes:          counter("writes_total", "Number of values written."),
+		decryptFailures: counter("decrypt_failures_total", "Number of values that failed to decrypt."),
+		encryptDuration: prometheus.NewHistogram(histogram("encrypt_duration_seconds", "Time taken to encrypt a value.")),
+		decryptDuration: prometheus.NewHistogram(histogram("decrypt_duration_seconds", "Time taken to decrypt a value.")),
+		kdfDuration:     prometheus.NewHistogramVec(histogram("kdf_duration_seconds", "Time taken by an Argon2id key derivation."), []string{"operation"}),
+	}
+}
+
+// ReadsInc counts a value read
+func (collector *Collector) ReadsInc() {
+	collector.reads.Inc()
+}
+
+// WritesInc counts a value written
+func (collector *Collector) WritesInc() {
+	collector.writes.Inc()
+}
+
+// DecryptFailuresInc counts a value that failed to decrypt
+func (collector *Collector) DecryptFailuresInc() {
+	collector.decryptFailures.Inc()
+}
+
+// EncryptDurationObserve records the time taken to encrypt a value
+func (collector *Collector) EncryptDurationObserve(duration time.Duration) {
+	collector.encryptDuration.Observe(duration.Seconds())
+}
+
+// DecryptDurationObserve records the time taken to decrypt a value
+func (collector *Collector) DecryptDurationObserve(duration time.Duration) {
+	collector.decryptDuration.Observe(duration.Seconds())
+}
+
+// KDFDurationObserve records the time taken by a key derivation
+func (collector *Collector) KDFDurationObserve(operation string, duration time.Duration) {
+	collector.kdfDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// Describe sends the descriptors of the metrics
+func (collector *Collector) Describe(ch chan<- *prometheus.Desc) {
+	collector.reads.Describe(ch)
+	collector.writes.Describe(ch)
+	collector.decryptFailures.Describe(ch)
+	collector.encryptDuration.Describe(ch)
+	collector.decryptDuration.Describe(ch)
+	collector.kdfDuration.Describe(ch)
+}
+
+// Collect sends the current values of the metrics
+func (collector *Collector) Collect(ch chan<- prometheus.Metric) {
+	collector.reads.Collect(ch)
+	collector.writes.Collect(ch)
+	collector.decryptFailures.Collect(ch)
+	collector.encryptDuration.Collect(ch)
+	collector.decryptDuration.Collect(ch)
+	collector.kdfDuration.Collect(ch)
+}
@@ -0,0 +1,46 @@
+package vaultprometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dracory/vaultstore"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	collector := New("test")
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("Register: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	collector.ReadsInc()
+	collector.ReadsInc()
+	collector.WritesInc()
+	collector.DecryptFailuresInc()
+	collector.EncryptDurationObserve(50 * time.Millisecond)
+	collector.DecryptDurationObserve(50 * time.Millisecond)
+	collector.KDFDurationObserve(vaultstore.KDF_OPERATION_DECRYPT, 40*time.Millisecond)
+
+	if value := testutil.ToFloat64(collector.reads); value != 2 {
+		t.Fatalf("reads: Expected [2] received [%v]", value)
+	}
+	if value := testutil.ToFloat64(collector.writes); value != 1 {
+		t.Fatalf("writes: Expected [1] received [%v]", value)
+	}
+	if value := testutil.ToFloat64(collector.decryptFailures); value != 1 {
+		t.Fatalf("decrypt failures: Expected [1] received [%v]", value)
+	}
+
+	// The key derivation histogram has a metric per observed operation
+	count, err := testutil.GatherAndCount(registry)
+	if err != nil {
+		t.Fatalf("GatherAndCount: Expected [err] to be nil received [%v]", err.Error())
+	}
+	if count != 6 {
+		t.Fatalf("GatherAndCount: Expected [6] received [%v]", count)
+	}
+}
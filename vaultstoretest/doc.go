@@ -0,0 +1,24 @@
+// Package vaultstoretest provides Fake, an in-memory vaultstore.StoreInterface for the
+// tests of the applications using the vault store, without a database.
+//
+// The token operations behave like the store: reading an unknown token returns
+// vaultstore.ErrTokenNotFound, a wrong password vaultstore.ErrDecryptionFailed and an
+// expired token vaultstore.ErrTokenExpired. Any method can be made to fail, and every
+// call is recorded:
+//
+//	fake := vaultstoretest.New()
+//	fake.SetError("TokenRead", vaultstore.ErrTokenExpired)
+//
+//	_, err := service.Detokenize(ctx, token) // calls fake.TokenRead
+//	if !errors.Is(err, ErrSessionExpired) {
+//		t.Fatal(...)
+//	}
+//
+//	if fake.CallCount("TokenRead") != 1 {
+//		t.Fatal(...)
+//	}
+//
+// Set Now to move the clock of the fake, e.g. to expire tokens without sleeping.
+// The maintenance, record and advanced token methods are not simulated: they record the
+// call and return the programmed error, if any, or their zero values.
+package vaultstoretest
@@ -0,0 +1,1036 @@
+package vaultstoretest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/dracory/vaultstore"
+)
+
+// DATETIME_FORMAT is the format of the timestamps returned by the fake, like the store
+const DATETIME_FORMAT = "2006-01-02 15:04:05"
+
+// TOKEN_LENGTH_DEFAULT is the length of the tokens created with a length of 0 or less
+const TOKEN_LENGTH_DEFAULT = 32
+
+// Call is a recorded call of a method of the fake
+type Call struct {
+	// Method is the name of the method, e.g. "TokenRead"
+	Method string
+	// Token is the token the method was called with, empty for methods without one
+	Token string
+}
+
+// Fake is an in-memory vaultstore.StoreInterface, see the package documentation.
+// It is safe for concurrent use.
+type Fake struct {
+	// Now returns the current time of the fake, used for the token expirations (default: time.Now)
+	Now func() time.Time
+
+	// ErrorFunc returns the error of a call, or nil to run it, for the errors that depend
+	// on the token or the call count. It is asked after the errors set with SetError.
+	ErrorFunc func(method string, token string) error
+
+	mu       sync.Mutex
+	calls    []Call
+	errors   map[string]error
+	tokens   map[string]*entry
+	settings map[string]string
+	aliases  map[string]string
+}
+
+// entry is a token held by the fake
+type entry struct {
+	value       string
+	password    string
+	contentType string
+	createdAt   time.Time
+	updatedAt   time.Time
+	expiresAt   time.Time // zero = never expires
+	softDeleted bool
+	revoked     bool
+	meta        map[string]string
+}
+
+var _ vaultstore.StoreInterface = (*Fake)(nil)
+
+// New creates an empty fake
+func New() *Fake {
+	return &Fake{
+		errors:   map[string]error{},
+		tokens:   map[string]*entry{},
+		settings: map[string]string{},
+		aliases:  map[string]string{},
+	}
+}
+
+// SetError makes every following call of the method return the error, or run again
+// for a nil error
+//
+// Parameters:
+// - method: The name of the method, e.g. "TokenRead"
+// - err: The error to return, nil to clear it
+func (fake *Fake) SetError(method string, err error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if err == nil {
+		delete(fake.errors, method)
+		return
+	}
+
+	fake.errors[method] = err
+}
+
+// Calls returns the calls recorded so far, in order
+func (fake *Fake) Calls() []Call {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return append([]Call{}, fake.calls...)
+}
+
+// CallCount returns the number of calls of the method
+func (fake *Fake) CallCount(method string) int {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	count := 0
+	for _, call := range fake.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Reset removes the tokens, the settings, the recorded calls and the programmed errors
+func (fake *Fake) Reset() {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.calls = nil
+	fake.errors = map[string]error{}
+	fake.tokens = map[string]*entry{}
+	fake.settings = map[string]string{}
+	fake.aliases = map[string]string{}
+}
+
+// call records the call and returns its programmed error, if any
+func (fake *Fake) call(method string, token string) error {
+	fake.mu.Lock()
+	fake.calls = append(fake.calls, Call{Method: method, Token: token})
+	err := fake.errors[method]
+	errorFunc := fake.ErrorFunc
+	fake.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if errorFunc != nil {
+		return errorFunc(method, token)
+	}
+
+	return nil
+}
+
+// now returns the current time of the fake
+func (fake *Fake) now() time.Time {
+	if fake.Now != nil {
+		return fake.Now().UTC()
+	}
+
+	return time.Now().UTC()
+}
+
+// tokenNew returns a new random token of the length
+func tokenNew(length int) string {
+	if length <= 0 {
+		length = TOKEN_LENGTH_DEFAULT
+	}
+
+	random := make([]byte, (length+1)/2)
+	_, _ = rand.Read(random)
+
+	return hex.EncodeToString(random)[:length]
+}
+
+// find returns the entry of a live token, or vaultstore.ErrTokenNotFound. The caller holds the lock.
+func (fake *Fake) find(token string) (*entry, error) {
+	entry, ok := fake.tokens[token]
+	if !ok || entry.softDeleted {
+		return nil, vaultstore.ErrTokenNotFound
+	}
+
+	return entry, nil
+}
+
+// readable returns the entry of a token that can be read with the password. The caller holds the lock.
+func (fake *Fake) readable(token string, password string) (*entry, error) {
+	entry, err := fake.find(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.expiresAt.IsZero() && !entry.expiresAt.After(fake.now()) {
+		return nil, vaultstore.ErrTokenExpired
+	}
+
+	if entry.revoked {
+		return nil, vaultstore.ErrTokenRevoked
+	}
+
+	if entry.password != password {
+		return nil, vaultstore.ErrDecryptionFailed
+	}
+
+	return entry, nil
+}
+
+// create stores a new token. The caller holds the lock.
+func (fake *Fake) create(token string, value string, password string, options []vaultstore.TokenCreateOptions) error {
+	if existing, ok := fake.tokens[token]; ok {
+		if existing.softDeleted {
+			return vaultstore.ErrTokenSoftDeleted
+		}
+		return vaultstore.ErrTokenAlreadyExists
+	}
+
+	now := fake.now()
+	entry := &entry{
+		value:     value,
+		password:  password,
+		createdAt: now,
+		updatedAt: now,
+		meta:      map[string]string{},
+	}
+
+	if len(options) > 0 {
+		entry.expiresAt = options[0].ExpiresAt.UTC()
+		entry.contentType = options[0].ContentType
+	}
+
+	fake.tokens[token] = entry
+	return nil
+}
+
+// datetime formats a time like the store, MAX_DATETIME for the zero time
+func datetime(t time.Time) string {
+	if t.IsZero() {
+		return vaultstore.MAX_DATETIME
+	}
+
+	return t.UTC().Format(DATETIME_FORMAT)
+}
+
+// TokenCreate stores the value under a new random token
+func (fake *Fake) TokenCreate(ctx context.Context, value string, password string, tokenLength int, options ...vaultstore.TokenCreateOptions) (string, error) {
+	if err := fake.call("TokenCreate", ""); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	token := tokenNew(tokenLength)
+	if err := fake.create(token, value, password, options); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// TokenCreateBytes stores the binary value under a new random token
+func (fake *Fake) TokenCreateBytes(ctx context.Context, value []byte, password string, tokenLength int, options ...vaultstore.TokenCreateOptions) (string, error) {
+	if err := fake.call("TokenCreateBytes", ""); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	token := tokenNew(tokenLength)
+	if err := fake.create(token, string(value), password, options); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// TokenCreateBatch stores each value under a new random token
+func (fake *Fake) TokenCreateBatch(ctx context.Context, values []string, password string, tokenLength int, options ...vaultstore.TokenCreateOptions) ([]string, error) {
+	if err := fake.call("TokenCreateBatch", ""); err != nil {
+		return nil, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	tokens := make([]string, 0, len(values))
+	for _, value := range values {
+		token := tokenNew(tokenLength)
+		if err := fake.create(token, value, password, options); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// TokenCreateCustom stores the value under the token
+func (fake *Fake) TokenCreateCustom(ctx context.Context, token string, value string, password string, options ...vaultstore.TokenCreateOptions) error {
+	if err := fake.call("TokenCreateCustom", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.create(token, value, password, options)
+}
+
+// TokenCreateCustomBytes stores the binary value under the token
+func (fake *Fake) TokenCreateCustomBytes(ctx context.Context, token string, value []byte, password string, options ...vaultstore.TokenCreateOptions) error {
+	if err := fake.call("TokenCreateCustomBytes", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.create(token, string(value), password, options)
+}
+
+// TokenDelete removes the token
+func (fake *Fake) TokenDelete(ctx context.Context, token string) error {
+	if err := fake.call("TokenDelete", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	delete(fake.tokens, token)
+	return nil
+}
+
+// TokenExists reports whether the token exists and is not soft deleted
+func (fake *Fake) TokenExists(ctx context.Context, token string) (bool, error) {
+	if err := fake.call("TokenExists", token); err != nil {
+		return false, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	_, err := fake.find(token)
+	return err == nil, nil
+}
+
+// ReadThrough reads the value of the token, see TokenRead
+func (fake *Fake) ReadThrough(ctx context.Context, token string, password string) (string, error) {
+	if err := fake.call("ReadThrough", token); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.readable(token, password)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.value, nil
+}
+
+// TokenRead reads the value of the token
+func (fake *Fake) TokenRead(ctx context.Context, token string, password string) (string, error) {
+	if err := fake.call("TokenRead", token); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.readable(token, password)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.value, nil
+}
+
+// TokenReadBytes reads the value of the token as bytes
+func (fake *Fake) TokenReadBytes(ctx context.Context, token string, password string) ([]byte, error) {
+	if err := fake.call("TokenReadBytes", token); err != nil {
+		return nil, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.readable(token, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(entry.value), nil
+}
+
+// TokenReadAndDelete reads the value of the token and removes it
+func (fake *Fake) TokenReadAndDelete(ctx context.Context, token string, password string) (string, error) {
+	if err := fake.call("TokenReadAndDelete", token); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.readable(token, password)
+	if err != nil {
+		return "", err
+	}
+
+	delete(fake.tokens, token)
+	return entry.value, nil
+}
+
+// TokenReadWithInfo reads the value of the token with its timestamps and content type
+func (fake *Fake) TokenReadWithInfo(ctx context.Context, token string, password string) (string, vaultstore.TokenInfo, error) {
+	if err := fake.call("TokenReadWithInfo", token); err != nil {
+		return "", vaultstore.TokenInfo{}, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.readable(token, password)
+	if err != nil {
+		return "", vaultstore.TokenInfo{}, err
+	}
+
+	return entry.value, vaultstore.TokenInfo{
+		Token:       token,
+		ContentType: entry.contentType,
+		CreatedAt:   datetime(entry.createdAt),
+		UpdatedAt:   datetime(entry.updatedAt),
+		ExpiresAt:   datetime(entry.expiresAt),
+	}, nil
+}
+
+// TokenRenew sets the expiration of the token
+func (fake *Fake) TokenRenew(ctx context.Context, token string, expiresAt time.Time) error {
+	if err := fake.call("TokenRenew", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.find(token)
+	if err != nil {
+		return err
+	}
+
+	entry.expiresAt = expiresAt.UTC()
+	entry.updatedAt = fake.now()
+	return nil
+}
+
+// TokenRestore undoes the soft delete of the token
+func (fake *Fake) TokenRestore(ctx context.Context, token string) error {
+	if err := fake.call("TokenRestore", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, ok := fake.tokens[token]
+	if !ok {
+		return vaultstore.ErrTokenNotFound
+	}
+
+	entry.softDeleted = false
+	return nil
+}
+
+// TokenRevoke revokes the token, reads return vaultstore.ErrTokenRevoked
+func (fake *Fake) TokenRevoke(ctx context.Context, token string, reason string) error {
+	if err := fake.call("TokenRevoke", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.find(token)
+	if err != nil {
+		return err
+	}
+
+	entry.revoked = true
+	return nil
+}
+
+// TokenUnrevoke removes the revocation of the token
+func (fake *Fake) TokenUnrevoke(ctx context.Context, token string) error {
+	if err := fake.call("TokenUnrevoke", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.find(token)
+	if err != nil {
+		return err
+	}
+
+	entry.revoked = false
+	return nil
+}
+
+// TokenSoftDelete soft deletes the token, it can be restored with TokenRestore
+func (fake *Fake) TokenSoftDelete(ctx context.Context, token string) error {
+	if err := fake.call("TokenSoftDelete", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.find(token)
+	if err != nil {
+		return err
+	}
+
+	entry.softDeleted = true
+	return nil
+}
+
+// TokenUpdate replaces the value of the token, encrypted with the password
+func (fake *Fake) TokenUpdate(ctx context.Context, token string, value string, password string) error {
+	if err := fake.call("TokenUpdate", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.update(token, value, password)
+}
+
+// TokenUpdateBytes replaces the value of the token with a binary value
+func (fake *Fake) TokenUpdateBytes(ctx context.Context, token string, value []byte, password string) error {
+	if err := fake.call("TokenUpdateBytes", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.update(token, string(value), password)
+}
+
+// TokenUpsert updates the existing token, or creates a new one if it is empty
+func (fake *Fake) TokenUpsert(ctx context.Context, existingToken string, value string, password string) (string, error) {
+	if err := fake.call("TokenUpsert", existingToken); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if existingToken == "" {
+		token := tokenNew(TOKEN_LENGTH_DEFAULT)
+		return token, fake.create(token, value, password, nil)
+	}
+
+	return existingToken, fake.update(existingToken, value, password)
+}
+
+// TokenCompareAndSwap replaces the value of the token if it holds the expected value,
+// otherwise returns vaultstore.ErrValueMismatch
+func (fake *Fake) TokenCompareAndSwap(ctx context.Context, token string, expectedValue string, newValue string, password string) error {
+	if err := fake.call("TokenCompareAndSwap", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.readable(token, password)
+	if err != nil {
+		return err
+	}
+
+	if entry.value != expectedValue {
+		return vaultstore.ErrValueMismatch
+	}
+
+	entry.value = newValue
+	entry.updatedAt = fake.now()
+	return nil
+}
+
+// update replaces the value and password of a token. The caller holds the lock.
+func (fake *Fake) update(token string, value string, password string) error {
+	entry, err := fake.find(token)
+	if err != nil {
+		return err
+	}
+
+	entry.value = value
+	entry.password = password
+	entry.updatedAt = fake.now()
+	return nil
+}
+
+// TokensRead reads the tokens readable with the password, skipping the others like the store
+func (fake *Fake) TokensRead(ctx context.Context, tokens []string, password string) (map[string]string, error) {
+	if err := fake.call("TokensRead", ""); err != nil {
+		return nil, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	values := map[string]string{}
+	for _, token := range tokens {
+		if entry, err := fake.readable(token, password); err == nil {
+			values[token] = entry.value
+		}
+	}
+
+	return values, nil
+}
+
+// TokensReadBytes reads the tokens readable with the password as bytes
+func (fake *Fake) TokensReadBytes(ctx context.Context, tokens []string, password string) (map[string][]byte, error) {
+	if err := fake.call("TokensReadBytes", ""); err != nil {
+		return nil, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	values := map[string][]byte{}
+	for _, token := range tokens {
+		if entry, err := fake.readable(token, password); err == nil {
+			values[token] = []byte(entry.value)
+		}
+	}
+
+	return values, nil
+}
+
+// TokensReadFunc calls the function with each token readable with the password
+func (fake *Fake) TokensReadFunc(ctx context.Context, tokens []string, password string, fn func(token string, value string) error) error {
+	if err := fake.call("TokensReadFunc", ""); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	values := map[string]string{}
+	for _, token := range tokens {
+		if entry, err := fake.readable(token, password); err == nil {
+			values[token] = entry.value
+		}
+	}
+	fake.mu.Unlock()
+
+	for _, token := range tokens {
+		value, ok := values[token]
+		if !ok {
+			continue
+		}
+		if err := fn(token, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TokensReadToResolvedMap reads the tokens of the map, keeping its keys
+func (fake *Fake) TokensReadToResolvedMap(ctx context.Context, keyTokenMap map[string]string, password string) (map[string]string, error) {
+	if err := fake.call("TokensReadToResolvedMap", ""); err != nil {
+		return nil, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	values := map[string]string{}
+	for key, token := range keyTokenMap {
+		entry, err := fake.readable(token, password)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = entry.value
+	}
+
+	return values, nil
+}
+
+// TokenChangePassword changes the password of the token
+func (fake *Fake) TokenChangePassword(ctx context.Context, token string, oldPassword, newPassword string) error {
+	if err := fake.call("TokenChangePassword", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.readable(token, oldPassword)
+	if err != nil {
+		return err
+	}
+
+	entry.password = newPassword
+	entry.updatedAt = fake.now()
+
+	return nil
+}
+
+// TokensChangePassword changes the password of the tokens of the old password
+func (fake *Fake) TokensChangePassword(ctx context.Context, oldPassword, newPassword string) (int, error) {
+	if err := fake.call("TokensChangePassword", ""); err != nil {
+		return 0, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	count := 0
+	for _, entry := range fake.tokens {
+		if !entry.softDeleted && entry.password == oldPassword {
+			entry.password = newPassword
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// TokensExpiredSoftDelete soft deletes the expired tokens
+func (fake *Fake) TokensExpiredSoftDelete(ctx context.Context) (int64, error) {
+	if err := fake.call("TokensExpiredSoftDelete", ""); err != nil {
+		return 0, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	var count int64
+	for _, entry := range fake.tokens {
+		if !entry.softDeleted && fake.expired(entry) {
+			entry.softDeleted = true
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// TokensExpiredDelete removes the expired tokens
+func (fake *Fake) TokensExpiredDelete(ctx context.Context) (int64, error) {
+	if err := fake.call("TokensExpiredDelete", ""); err != nil {
+		return 0, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	var count int64
+	for token, entry := range fake.tokens {
+		if fake.expired(entry) {
+			delete(fake.tokens, token)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// expired reports whether the entry has expired. The caller holds the lock.
+func (fake *Fake) expired(entry *entry) bool {
+	return !entry.expiresAt.IsZero() && !entry.expiresAt.After(fake.now())
+}
+
+// TokenMetaSet tags the token with the key and value
+func (fake *Fake) TokenMetaSet(ctx context.Context, token string, key string, value string) error {
+	if err := fake.call("TokenMetaSet", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.find(token)
+	if err != nil {
+		return err
+	}
+
+	entry.meta[key] = value
+	return nil
+}
+
+// TokenMetaGet returns the value of the tag, or vaultstore.ErrTokenMetaNotFound
+func (fake *Fake) TokenMetaGet(ctx context.Context, token string, key string) (string, error) {
+	if err := fake.call("TokenMetaGet", token); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.find(token)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := entry.meta[key]
+	if !ok {
+		return "", vaultstore.ErrTokenMetaNotFound
+	}
+
+	return value, nil
+}
+
+// TokenMetaList returns the tags of the token
+func (fake *Fake) TokenMetaList(ctx context.Context, token string) (map[string]string, error) {
+	if err := fake.call("TokenMetaList", token); err != nil {
+		return nil, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.find(token)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string]string, len(entry.meta))
+	for key, value := range entry.meta {
+		meta[key] = value
+	}
+
+	return meta, nil
+}
+
+// TokenMetaDelete removes the tag of the token
+func (fake *Fake) TokenMetaDelete(ctx context.Context, token string, key string) error {
+	if err := fake.call("TokenMetaDelete", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	entry, err := fake.find(token)
+	if err != nil {
+		return err
+	}
+
+	delete(entry.meta, key)
+	return nil
+}
+
+// TokenAliasAdd points the alias to the token, or returns vaultstore.ErrTokenAliasExists
+// if it points to another token
+func (fake *Fake) TokenAliasAdd(ctx context.Context, token string, alias string) error {
+	if err := fake.call("TokenAliasAdd", token); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if _, err := fake.find(token); err != nil {
+		return err
+	}
+
+	if existing := fake.aliasToken(alias); existing != "" && existing != token {
+		return vaultstore.ErrTokenAliasExists
+	}
+
+	fake.aliases[alias] = token
+	return nil
+}
+
+// TokenAliasRemove removes the alias
+func (fake *Fake) TokenAliasRemove(ctx context.Context, alias string) error {
+	if err := fake.call("TokenAliasRemove", ""); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	delete(fake.aliases, alias)
+	return nil
+}
+
+// TokenAliasResolve returns the token of the alias, or vaultstore.ErrTokenAliasNotFound
+func (fake *Fake) TokenAliasResolve(ctx context.Context, alias string) (string, error) {
+	if err := fake.call("TokenAliasResolve", ""); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	token := fake.aliasToken(alias)
+	if token == "" {
+		return "", vaultstore.ErrTokenAliasNotFound
+	}
+
+	return token, nil
+}
+
+// aliasToken returns the token of the alias, releasing the alias of a deleted token.
+// The caller holds the lock.
+func (fake *Fake) aliasToken(alias string) string {
+	token, ok := fake.aliases[alias]
+	if !ok {
+		return ""
+	}
+
+	if _, exists := fake.tokens[token]; !exists {
+		delete(fake.aliases, alias)
+		return ""
+	}
+
+	return token
+}
+
+// TokensFindByMeta returns the tokens tagged with the key and value
+func (fake *Fake) TokensFindByMeta(ctx context.Context, key string, value string) ([]string, error) {
+	if err := fake.call("TokensFindByMeta", ""); err != nil {
+		return nil, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	tokens := []string{}
+	for token, entry := range fake.tokens {
+		if !entry.softDeleted && entry.meta[key] == value {
+			if _, ok := entry.meta[key]; ok {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	return tokens, nil
+}
+
+// GetVaultSetting returns the value of the setting, empty if not set
+func (fake *Fake) GetVaultSetting(ctx context.Context, key string) (string, error) {
+	if err := fake.call("GetVaultSetting", ""); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.settings[key], nil
+}
+
+// SetVaultSetting sets the value of the setting
+func (fake *Fake) SetVaultSetting(ctx context.Context, key, value string) error {
+	if err := fake.call("SetVaultSetting", ""); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.settings[key] = value
+	return nil
+}
+
+// GetVaultVersion returns the vault version, empty if not set
+func (fake *Fake) GetVaultVersion(ctx context.Context) (string, error) {
+	if err := fake.call("GetVaultVersion", ""); err != nil {
+		return "", err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.settings[vaultstore.META_KEY_VERSION], nil
+}
+
+// SetVaultVersion sets the vault version
+func (fake *Fake) SetVaultVersion(ctx context.Context, version string) error {
+	if err := fake.call("SetVaultVersion", ""); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.settings[vaultstore.META_KEY_VERSION] = version
+	return nil
+}
+
+// FeatureEnable enables the feature flag
+func (fake *Fake) FeatureEnable(ctx context.Context, name string) error {
+	if err := fake.call("FeatureEnable", ""); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.settings[vaultstore.VAULT_SETTING_KEY_FEATURE_PREFIX+name] = "true"
+	return nil
+}
+
+// FeatureDisable disables the feature flag
+func (fake *Fake) FeatureDisable(ctx context.Context, name string) error {
+	if err := fake.call("FeatureDisable", ""); err != nil {
+		return err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.settings[vaultstore.VAULT_SETTING_KEY_FEATURE_PREFIX+name] = "false"
+	return nil
+}
+
+// FeatureIsEnabled reports whether the feature flag is enabled. Like the store,
+// vaultstore.FEATURE_JANITOR is enabled and the other flags disabled until set.
+func (fake *Fake) FeatureIsEnabled(ctx context.Context, name string) (bool, error) {
+	if err := fake.call("FeatureIsEnabled", ""); err != nil {
+		return false, err
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	value, ok := fake.settings[vaultstore.VAULT_SETTING_KEY_FEATURE_PREFIX+name]
+	if !ok {
+		return name == vaultstore.FEATURE_JANITOR, nil
+	}
+
+	return value == "true", nil
+}
+
+// Redact returns the redacted identifier of the token, see vaultstore.Redact
+func (fake *Fake) Redact(token string) string {
+	_ = fake.call("Redact", token)
+	return vaultstore.Redact(token)
+}
@@ -0,0 +1,159 @@
+package vaultstoretest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dracory/vaultstore"
+)
+
+func TestFake_TokenCreateRead(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+
+	token, err := fake.TokenCreate(ctx, "secret", "password", 20)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if len(token) != 20 {
+		t.Fatalf("Expected a token of 20 characters received [%v]", token)
+	}
+
+	value, err := fake.TokenRead(ctx, token, "password")
+	if err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if value != "secret" {
+		t.Fatalf("Expected [secret] received [%v]", value)
+	}
+
+	if _, err := fake.TokenRead(ctx, token, "wrong"); !errors.Is(err, vaultstore.ErrDecryptionFailed) {
+		t.Fatalf("Expected [ErrDecryptionFailed] received [%v]", err)
+	}
+
+	if _, err := fake.TokenRead(ctx, "unknown", "password"); !errors.Is(err, vaultstore.ErrTokenNotFound) {
+		t.Fatalf("Expected [ErrTokenNotFound] received [%v]", err)
+	}
+
+	if err := fake.TokenCreateCustom(ctx, token, "other", "password"); !errors.Is(err, vaultstore.ErrTokenAlreadyExists) {
+		t.Fatalf("Expected [ErrTokenAlreadyExists] received [%v]", err)
+	}
+}
+
+func TestFake_TokenExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	fake := New()
+	fake.Now = func() time.Time { return now }
+
+	token, err := fake.TokenCreate(ctx, "secret", "password", 0, vaultstore.TokenCreateOptions{
+		ExpiresAt: now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if _, err := fake.TokenRead(ctx, token, "password"); err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	now = now.Add(2 * time.Hour)
+
+	if _, err := fake.TokenRead(ctx, token, "password"); !errors.Is(err, vaultstore.ErrTokenExpired) {
+		t.Fatalf("Expected [ErrTokenExpired] received [%v]", err)
+	}
+
+	count, err := fake.TokensExpiredDelete(ctx)
+	if err != nil {
+		t.Fatalf("TokensExpiredDelete: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected [1] expired token received [%v]", count)
+	}
+}
+
+func TestFake_SetError(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+
+	token, err := fake.TokenCreate(ctx, "secret", "password", 0)
+	if err != nil {
+		t.Fatalf("TokenCreate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	fake.SetError("TokenRead", vaultstore.ErrTokenExpired)
+
+	if _, err := fake.TokenRead(ctx, token, "password"); !errors.Is(err, vaultstore.ErrTokenExpired) {
+		t.Fatalf("Expected [ErrTokenExpired] received [%v]", err)
+	}
+
+	fake.SetError("TokenRead", nil)
+
+	if _, err := fake.TokenRead(ctx, token, "password"); err != nil {
+		t.Fatalf("TokenRead: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if fake.CallCount("TokenRead") != 2 {
+		t.Fatalf("Expected [2] calls of TokenRead received [%v]", fake.CallCount("TokenRead"))
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("Expected [3] calls received [%v]", len(calls))
+	}
+
+	if calls[1].Method != "TokenRead" || calls[1].Token != token {
+		t.Fatalf("Expected the TokenRead call of the token received [%v]", calls[1])
+	}
+}
+
+func TestFake_ErrorFunc(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+
+	errDown := errors.New("database is down")
+	fake.ErrorFunc = func(method string, token string) error {
+		if token == "broken" {
+			return errDown
+		}
+		return nil
+	}
+
+	if err := fake.TokenCreateCustom(ctx, "working", "secret", "password"); err != nil {
+		t.Fatalf("TokenCreateCustom: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if err := fake.TokenCreateCustom(ctx, "broken", "secret", "password"); !errors.Is(err, errDown) {
+		t.Fatalf("Expected [%v] received [%v]", errDown, err)
+	}
+}
+
+func TestFake_Unsimulated(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+
+	if err := fake.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	errFailed := errors.New("migration failed")
+	fake.SetError("AutoMigrate", errFailed)
+
+	if err := fake.AutoMigrate(); !errors.Is(err, errFailed) {
+		t.Fatalf("Expected [%v] received [%v]", errFailed, err)
+	}
+
+	if _, err := fake.RecordCount(ctx, nil); err != nil {
+		t.Fatalf("RecordCount: Expected [err] to be nil received [%v]", err.Error())
+	}
+
+	if fake.CallCount("AutoMigrate") != 2 {
+		t.Fatalf("Expected [2] calls of AutoMigrate received [%v]", fake.CallCount("AutoMigrate"))
+	}
+}
@@ -0,0 +1,454 @@
+package vaultstoretest
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/dracory/vaultstore"
+)
+
+// AutoMigrate records the call and returns the programmed error
+func (fake *Fake) AutoMigrate() error {
+	return fake.call("AutoMigrate", "")
+}
+
+// MigrationRollbackScript records the call and returns the programmed error
+func (fake *Fake) MigrationRollbackScript(ctx context.Context) (string, error) {
+	err := fake.call("MigrationRollbackScript", "")
+	return "", err
+}
+
+// MetaEncryptionMigrate records the call and returns the programmed error
+func (fake *Fake) MetaEncryptionMigrate(ctx context.Context) (int64, error) {
+	err := fake.call("MetaEncryptionMigrate", "")
+	return 0, err
+}
+
+// ArchiveExpired records the call and returns the programmed error
+func (fake *Fake) ArchiveExpired(ctx context.Context, w io.Writer) (int64, error) {
+	err := fake.call("ArchiveExpired", "")
+	return 0, err
+}
+
+// ArchiveRead records the call and returns the programmed error
+func (fake *Fake) ArchiveRead(ctx context.Context, r io.Reader, fn func(batch vaultstore.ChangeBatch) error) error {
+	return fake.call("ArchiveRead", "")
+}
+
+// Export records the call and returns the programmed error
+func (fake *Fake) Export(ctx context.Context, w io.Writer, opts vaultstore.ExportOptions) (vaultstore.ExportResult, error) {
+	err := fake.call("Export", "")
+	return vaultstore.ExportResult{}, err
+}
+
+// Import records the call and returns the programmed error
+func (fake *Fake) Import(ctx context.Context, r io.Reader, opts vaultstore.ImportOptions) (vaultstore.ImportResult, error) {
+	err := fake.call("Import", "")
+	return vaultstore.ImportResult{}, err
+}
+
+// CopyTo records the call and returns the programmed error
+func (fake *Fake) CopyTo(ctx context.Context, dest vaultstore.StoreInterface, opts vaultstore.CopyOptions) (vaultstore.CopyResult, error) {
+	err := fake.call("CopyTo", "")
+	return vaultstore.CopyResult{}, err
+}
+
+// BreakGlassSetup records the call and returns the programmed error
+func (fake *Fake) BreakGlassSetup(ctx context.Context, shares int, threshold int) ([]string, error) {
+	err := fake.call("BreakGlassSetup", "")
+	return nil, err
+}
+
+// BreakGlassGrant records the call and returns the programmed error
+func (fake *Fake) BreakGlassGrant(ctx context.Context, adminShares []string, duration time.Duration) error {
+	return fake.call("BreakGlassGrant", "")
+}
+
+// BreakGlassRevoke records the call and returns the programmed error
+func (fake *Fake) BreakGlassRevoke(ctx context.Context) error {
+	return fake.call("BreakGlassRevoke", "")
+}
+
+// AutoMigrateTableSuffix records the call and returns the programmed error
+func (fake *Fake) AutoMigrateTableSuffix(suffix string) error {
+	return fake.call("AutoMigrateTableSuffix", "")
+}
+
+// EnableDebug records the call and returns the programmed error
+func (fake *Fake) EnableDebug(debug bool) {
+	_ = fake.call("EnableDebug", "")
+}
+
+// Reconfigure records the call and returns the programmed error
+func (fake *Fake) Reconfigure(opts vaultstore.ReconfigureOptions) error {
+	return fake.call("Reconfigure", "")
+}
+
+// GetDbDriverName records the call and returns the programmed error
+func (fake *Fake) GetDbDriverName() string {
+	_ = fake.call("GetDbDriverName", "")
+	return ""
+}
+
+// GetVaultTableName records the call and returns the programmed error
+func (fake *Fake) GetVaultTableName() string {
+	_ = fake.call("GetVaultTableName", "")
+	return ""
+}
+
+// GetMetaTableName records the call and returns the programmed error
+func (fake *Fake) GetMetaTableName() string {
+	_ = fake.call("GetMetaTableName", "")
+	return ""
+}
+
+// TokensConsumedDelete records the call and returns the programmed error
+func (fake *Fake) TokensConsumedDelete(ctx context.Context) (int64, error) {
+	err := fake.call("TokensConsumedDelete", "")
+	return 0, err
+}
+
+// LimitedUseStats records the call and returns the programmed error
+func (fake *Fake) LimitedUseStats(ctx context.Context) (vaultstore.LimitedUseStats, error) {
+	err := fake.call("LimitedUseStats", "")
+	return vaultstore.LimitedUseStats{}, err
+}
+
+// QuotaCheck records the call and returns the programmed error
+func (fake *Fake) QuotaCheck(ctx context.Context) (vaultstore.QuotaReport, error) {
+	err := fake.call("QuotaCheck", "")
+	return vaultstore.QuotaReport{}, err
+}
+
+// ValueChunksGarbageCollect records the call and returns the programmed error
+func (fake *Fake) ValueChunksGarbageCollect(ctx context.Context) (int64, error) {
+	err := fake.call("ValueChunksGarbageCollect", "")
+	return 0, err
+}
+
+// LoadPolicy records the call and returns the programmed error
+func (fake *Fake) LoadPolicy(ctx context.Context, r io.Reader) error {
+	return fake.call("LoadPolicy", "")
+}
+
+// PolicyReload records the call and returns the programmed error
+func (fake *Fake) PolicyReload(ctx context.Context) error {
+	return fake.call("PolicyReload", "")
+}
+
+// GetPolicy records the call and returns the programmed error
+func (fake *Fake) GetPolicy() *vaultstore.Policy {
+	_ = fake.call("GetPolicy", "")
+	return nil
+}
+
+// ChangesSince records the call and returns the programmed error
+func (fake *Fake) ChangesSince(ctx context.Context, cursor string) (vaultstore.ChangeBatch, error) {
+	err := fake.call("ChangesSince", "")
+	return vaultstore.ChangeBatch{}, err
+}
+
+// ApplyChanges records the call and returns the programmed error
+func (fake *Fake) ApplyChanges(ctx context.Context, batch vaultstore.ChangeBatch) (vaultstore.ApplyResult, error) {
+	err := fake.call("ApplyChanges", "")
+	return vaultstore.ApplyResult{}, err
+}
+
+// NormalizeTimestamps records the call and returns the programmed error
+func (fake *Fake) NormalizeTimestamps(ctx context.Context) (vaultstore.TimestampsNormalizeReport, error) {
+	err := fake.call("NormalizeTimestamps", "")
+	return vaultstore.TimestampsNormalizeReport{}, err
+}
+
+// KDFStats records the call and returns the programmed error
+func (fake *Fake) KDFStats() map[string]vaultstore.KDFStat {
+	_ = fake.call("KDFStats", "")
+	return nil
+}
+
+// Preflight records the call and returns the programmed error
+func (fake *Fake) Preflight(ctx context.Context) (vaultstore.PreflightReport, error) {
+	err := fake.call("Preflight", "")
+	return vaultstore.PreflightReport{}, err
+}
+
+// MigrateEncryptionV1ToV2 records the call and returns the programmed error
+func (fake *Fake) MigrateEncryptionV1ToV2(ctx context.Context, password string, opts vaultstore.MigrateOptions) (int, error) {
+	err := fake.call("MigrateEncryptionV1ToV2", "")
+	return 0, err
+}
+
+// EnvelopeRewrap records the call and returns the programmed error
+func (fake *Fake) EnvelopeRewrap(ctx context.Context, provider vaultstore.KeyProvider) (int64, error) {
+	err := fake.call("EnvelopeRewrap", "")
+	return 0, err
+}
+
+// Scheduler records the call and returns the programmed error
+func (fake *Fake) Scheduler(options ...vaultstore.SchedulerOptions) (*vaultstore.Scheduler, error) {
+	err := fake.call("Scheduler", "")
+	return nil, err
+}
+
+// StartExpirationWorker records the call and returns the programmed error
+func (fake *Fake) StartExpirationWorker(ctx context.Context, options vaultstore.ExpirationWorkerOptions) (*vaultstore.ExpirationWorker, error) {
+	err := fake.call("StartExpirationWorker", "")
+	return nil, err
+}
+
+// VerifyRestore records the call and returns the programmed error
+func (fake *Fake) VerifyRestore(ctx context.Context, samplePercent float64, password string) (vaultstore.VerifyRestoreReport, error) {
+	err := fake.call("VerifyRestore", "")
+	return vaultstore.VerifyRestoreReport{}, err
+}
+
+// RecordCount records the call and returns the programmed error
+func (fake *Fake) RecordCount(ctx context.Context, query vaultstore.RecordQueryInterface) (int64, error) {
+	err := fake.call("RecordCount", "")
+	return 0, err
+}
+
+// RecordCountEstimate records the call and returns the programmed error
+func (fake *Fake) RecordCountEstimate(ctx context.Context) (int64, error) {
+	err := fake.call("RecordCountEstimate", "")
+	return 0, err
+}
+
+// RecordSizeHistogram records the call and returns the programmed error
+func (fake *Fake) RecordSizeHistogram(ctx context.Context) (vaultstore.RecordSizeReport, error) {
+	err := fake.call("RecordSizeHistogram", "")
+	return vaultstore.RecordSizeReport{}, err
+}
+
+// RecordCreate records the call and returns the programmed error
+func (fake *Fake) RecordCreate(ctx context.Context, record vaultstore.RecordInterface) error {
+	return fake.call("RecordCreate", "")
+}
+
+// RecordCreateMany records the call and returns the programmed error
+func (fake *Fake) RecordCreateMany(ctx context.Context, records []vaultstore.RecordInterface) error {
+	return fake.call("RecordCreateMany", "")
+}
+
+// RecordDeleteByID records the call and returns the programmed error
+func (fake *Fake) RecordDeleteByID(ctx context.Context, recordID string) error {
+	return fake.call("RecordDeleteByID", "")
+}
+
+// RecordDeleteByToken records the call and returns the programmed error
+func (fake *Fake) RecordDeleteByToken(ctx context.Context, token string) error {
+	return fake.call("RecordDeleteByToken", token)
+}
+
+// RecordFindByID records the call and returns the programmed error
+func (fake *Fake) RecordFindByID(ctx context.Context, recordID string) (vaultstore.RecordInterface, error) {
+	err := fake.call("RecordFindByID", "")
+	return nil, err
+}
+
+// RecordFindByToken records the call and returns the programmed error
+func (fake *Fake) RecordFindByToken(ctx context.Context, token string) (vaultstore.RecordInterface, error) {
+	err := fake.call("RecordFindByToken", token)
+	return nil, err
+}
+
+// RecordImportCiphertext records the call and returns the programmed error
+func (fake *Fake) RecordImportCiphertext(ctx context.Context, token string, ciphertext string, options ...vaultstore.TokenCreateOptions) error {
+	return fake.call("RecordImportCiphertext", token)
+}
+
+// RecordList records the call and returns the programmed error
+func (fake *Fake) RecordList(ctx context.Context, query vaultstore.RecordQueryInterface) ([]vaultstore.RecordInterface, error) {
+	err := fake.call("RecordList", "")
+	return nil, err
+}
+
+// RecordListStream records the call and returns the programmed error
+func (fake *Fake) RecordListStream(ctx context.Context, query vaultstore.RecordQueryInterface, fn func(vaultstore.RecordInterface) error) error {
+	return fake.call("RecordListStream", "")
+}
+
+// RecordSoftDelete records the call and returns the programmed error
+func (fake *Fake) RecordSoftDelete(ctx context.Context, record vaultstore.RecordInterface) error {
+	return fake.call("RecordSoftDelete", "")
+}
+
+// RecordSoftDeleteByID records the call and returns the programmed error
+func (fake *Fake) RecordSoftDeleteByID(ctx context.Context, recordID string) error {
+	return fake.call("RecordSoftDeleteByID", "")
+}
+
+// RecordSoftDeleteByToken records the call and returns the programmed error
+func (fake *Fake) RecordSoftDeleteByToken(ctx context.Context, token string) error {
+	return fake.call("RecordSoftDeleteByToken", token)
+}
+
+// RecordRestore records the call and returns the programmed error
+func (fake *Fake) RecordRestore(ctx context.Context, record vaultstore.RecordInterface) error {
+	return fake.call("RecordRestore", "")
+}
+
+// RecordRestoreByID records the call and returns the programmed error
+func (fake *Fake) RecordRestoreByID(ctx context.Context, recordID string) error {
+	return fake.call("RecordRestoreByID", "")
+}
+
+// RecordsSoftDeletedPurge records the call and returns the programmed error
+func (fake *Fake) RecordsSoftDeletedPurge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	err := fake.call("RecordsSoftDeletedPurge", "")
+	return 0, err
+}
+
+// RecordUpdate records the call and returns the programmed error
+func (fake *Fake) RecordUpdate(ctx context.Context, record vaultstore.RecordInterface) error {
+	return fake.call("RecordUpdate", "")
+}
+
+// RecordUpdateByToken records the call and returns the programmed error
+func (fake *Fake) RecordUpdateByToken(ctx context.Context, token string, updates map[string]string) error {
+	return fake.call("RecordUpdateByToken", token)
+}
+
+// TokenAppend records the call and returns the programmed error
+func (fake *Fake) TokenAppend(ctx context.Context, token string, chunk string, password string) error {
+	return fake.call("TokenAppend", token)
+}
+
+// TokenCreateFromReader records the call and returns the programmed error
+func (fake *Fake) TokenCreateFromReader(ctx context.Context, r io.Reader, password string, tokenLength int, options ...vaultstore.TokenCreateOptions) (string, error) {
+	err := fake.call("TokenCreateFromReader", "")
+	return "", err
+}
+
+// TokenList records the call and returns the programmed error
+func (fake *Fake) TokenList(ctx context.Context, options vaultstore.TokenQueryOptions) ([]vaultstore.TokenListItem, error) {
+	err := fake.call("TokenList", "")
+	return nil, err
+}
+
+// TokenListByPrefix records the call and returns the programmed error
+func (fake *Fake) TokenListByPrefix(ctx context.Context, prefix string) ([]string, error) {
+	err := fake.call("TokenListByPrefix", "")
+	return nil, err
+}
+
+// TokenReadAll records the call and returns the programmed error
+func (fake *Fake) TokenReadAll(ctx context.Context, token string, password string) ([]string, error) {
+	err := fake.call("TokenReadAll", token)
+	return nil, err
+}
+
+// TokenReadToWriter records the call and returns the programmed error
+func (fake *Fake) TokenReadToWriter(ctx context.Context, token string, password string, w io.Writer) error {
+	return fake.call("TokenReadToWriter", token)
+}
+
+// TokenCreateMap records the call and returns the programmed error
+func (fake *Fake) TokenCreateMap(ctx context.Context, values map[string]string, password string, options ...vaultstore.TokenCreateOptions) (string, error) {
+	err := fake.call("TokenCreateMap", "")
+	return "", err
+}
+
+// TokenReadMap records the call and returns the programmed error
+func (fake *Fake) TokenReadMap(ctx context.Context, token string, password string) (map[string]string, error) {
+	err := fake.call("TokenReadMap", token)
+	return nil, err
+}
+
+// TokenReadKey records the call and returns the programmed error
+func (fake *Fake) TokenReadKey(ctx context.Context, token string, field string, password string) (string, error) {
+	err := fake.call("TokenReadKey", token)
+	return "", err
+}
+
+// TokenPatchKey records the call and returns the programmed error
+func (fake *Fake) TokenPatchKey(ctx context.Context, token string, field string, value string, password string) error {
+	return fake.call("TokenPatchKey", token)
+}
+
+// TokenReadMasked records the call and returns the programmed error
+func (fake *Fake) TokenReadMasked(ctx context.Context, token string, password string, maskFields []string) (string, error) {
+	err := fake.call("TokenReadMasked", token)
+	return "", err
+}
+
+// TokenCheckout records the call and returns the programmed error
+func (fake *Fake) TokenCheckout(ctx context.Context, token string, holder string, ttl time.Duration) (vaultstore.TokenLease, error) {
+	err := fake.call("TokenCheckout", token)
+	return vaultstore.TokenLease{}, err
+}
+
+// TokenCheckin records the call and returns the programmed error
+func (fake *Fake) TokenCheckin(ctx context.Context, token string, holder string) error {
+	return fake.call("TokenCheckin", token)
+}
+
+// TokenClone records the call and returns the programmed error
+func (fake *Fake) TokenClone(ctx context.Context, srcToken string, password string, opts vaultstore.TokenCloneOptions) (string, error) {
+	err := fake.call("TokenClone", srcToken)
+	return "", err
+}
+
+// RevokedList records the call and returns the programmed error
+func (fake *Fake) RevokedList(ctx context.Context) ([]vaultstore.TokenRevocation, error) {
+	err := fake.call("RevokedList", "")
+	return nil, err
+}
+
+// TokenBreakGlassRequire records the call and returns the programmed error
+func (fake *Fake) TokenBreakGlassRequire(ctx context.Context, token string, required bool) error {
+	return fake.call("TokenBreakGlassRequire", token)
+}
+
+// TokenVersions records the call and returns the programmed error
+func (fake *Fake) TokenVersions(ctx context.Context, token string) ([]int, error) {
+	err := fake.call("TokenVersions", token)
+	return nil, err
+}
+
+// TokenDiff records the call and returns the programmed error
+func (fake *Fake) TokenDiff(ctx context.Context, token string, versionA int, versionB int, password string) (vaultstore.TokenDiffResult, error) {
+	err := fake.call("TokenDiff", token)
+	return vaultstore.TokenDiffResult{}, err
+}
+
+// TokenQuarantine records the call and returns the programmed error
+func (fake *Fake) TokenQuarantine(ctx context.Context, token string, reason string) error {
+	return fake.call("TokenQuarantine", token)
+}
+
+// QuarantinedList records the call and returns the programmed error
+func (fake *Fake) QuarantinedList(ctx context.Context) ([]vaultstore.QuarantinedToken, error) {
+	err := fake.call("QuarantinedList", "")
+	return nil, err
+}
+
+// TokenRepair records the call and returns the programmed error
+func (fake *Fake) TokenRepair(ctx context.Context, token string, newCiphertext string) error {
+	return fake.call("TokenRepair", token)
+}
+
+// TokensCountByPrefix records the call and returns the programmed error
+func (fake *Fake) TokensCountByPrefix(ctx context.Context, prefix string) (int64, error) {
+	err := fake.call("TokensCountByPrefix", "")
+	return 0, err
+}
+
+// TokensDeleteByPrefix records the call and returns the programmed error
+func (fake *Fake) TokensDeleteByPrefix(ctx context.Context, prefix string, options ...vaultstore.TokensDeleteByPrefixOptions) (int64, error) {
+	err := fake.call("TokensDeleteByPrefix", "")
+	return 0, err
+}
+
+// TokensExpireWhere records the call and returns the programmed error
+func (fake *Fake) TokensExpireWhere(ctx context.Context, query vaultstore.RecordQueryInterface, expiresAt time.Time) (int64, error) {
+	err := fake.call("TokensExpireWhere", "")
+	return 0, err
+}
+
+// TokensReadWithInfo records the call and returns the programmed error
+func (fake *Fake) TokensReadWithInfo(ctx context.Context, tokens []string, password string) (map[string]vaultstore.TokenValueInfo, error) {
+	err := fake.call("TokensReadWithInfo", "")
+	return nil, err
+}